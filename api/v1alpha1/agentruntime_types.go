@@ -115,6 +115,11 @@ type AgentSessionStatus struct {
 
 	// +optional
 	SandboxName string `json:"sandboxName,omitempty"`
+
+	// ports lists the ports advertised by the resolved template, taken from
+	// spec.ports or the referenced AgentRuntime's spec.ports.
+	// +optional
+	Ports []Port `json:"ports,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -28,10 +30,98 @@ type SandboxTemplate struct {
 	// Typical values include "kata-qemu" or "gvisor" depending on cluster RuntimeClass setup
 	// +optional
 	RuntimeClassName string `json:"runtimeClassName,omitempty"`
+	// NodeSelector constrains the sandbox Pod to nodes with matching labels,
+	// e.g. GPU nodes or nodes that provide the configured runtime class.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations let the sandbox Pod schedule onto tainted nodes.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Affinity sets node and pod (anti-)affinity rules of the sandbox Pod.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
 	// +optional
 	Command []string `json:"command,omitempty"`
 	// +optional
 	Args []string `json:"args,omitempty"`
+	// Env lists environment variables set in the sandbox container.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// WorkingDir overrides the working directory of the sandbox container.
+	// +optional
+	WorkingDir string `json:"workingDir,omitempty"`
+	// Resources sets CPU/memory requests and limits of the sandbox container.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// SecurityContext is applied to the sandbox container.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+	// ReadinessProbe overrides the default TCP readiness probe on the korokd port.
+	// The Sandbox only reports Running once the pod is ready.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	ReadinessProbe *corev1.Probe `json:"readinessProbe,omitempty"`
+	// LivenessProbe overrides the default TCP liveness probe on the korokd port.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	LivenessProbe *corev1.Probe `json:"livenessProbe,omitempty"`
+	// Volumes are added to the sandbox Pod next to the built-in JWT and workspace volumes,
+	// e.g. configmaps with pip config or persistent volumes for stateful sandboxes.
+	// Names must not collide with the built-in volumes.
+	// +optional
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+	// VolumeMounts are added to the sandbox container next to the built-in mounts.
+	// Mounting onto the reserved workspace or JWT paths is rejected.
+	// +optional
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+	// WorkspacePersistence backs the /workspace mount with a PersistentVolumeClaim instead of
+	// an emptyDir so files survive pod restarts and sandbox recreation.
+	// +optional
+	WorkspacePersistence *WorkspacePersistence `json:"workspacePersistence,omitempty"`
+}
+
+// WorkspacePersistenceScope decides which sandboxes share a persistent workspace claim.
+type WorkspacePersistenceScope string
+
+const (
+	// WorkspacePersistenceScopeSession keeps one claim per session. The claim is reused when the
+	// session's sandbox is recreated and is garbage collected together with the session.
+	WorkspacePersistenceScopeSession WorkspacePersistenceScope = "Session"
+	// WorkspacePersistenceScopeOwner keeps one claim per owner shared by all of the owner's sessions.
+	// The claim outlives the sessions and has to be removed by an administrator.
+	WorkspacePersistenceScopeOwner WorkspacePersistenceScope = "Owner"
+)
+
+// WorkspacePersistence configures a PVC-backed sandbox workspace.
+// Sandboxes with a persistent workspace are always cold started; warm pool pods are not used.
+type WorkspacePersistence struct {
+	// Enabled turns on the PVC-backed workspace.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// Scope selects whether the claim belongs to a session or to the session owner. Defaults to Session.
+	// +kubebuilder:validation:Enum=Session;Owner
+	// +optional
+	Scope WorkspacePersistenceScope `json:"scope,omitempty"`
+	// Size is the requested storage of a newly created claim. Defaults to 1Gi.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+	// StorageClassName is used for newly created claims. The cluster default is used when empty.
+	// +optional
+	StorageClassName string `json:"storageClassName,omitempty"`
+	// AccessModes of newly created claims. Defaults to ReadWriteOnce; Owner scoped claims
+	// shared by concurrent sessions on different nodes need ReadWriteMany.
+	// +optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
 }
 
 // SandboxSpec defines the desired state of Sandbox.
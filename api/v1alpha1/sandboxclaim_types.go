@@ -42,6 +42,8 @@ type SandboxClaimSpec struct {
 	// +optional
 	Profile string `json:"profile,omitempty"`
 
+	// PoolRef names the warm pool to claim from. A comma-separated list spreads
+	// the claim across several pools; the pool with the most ready pods wins.
 	// +optional
 	PoolRef string `json:"poolRef,omitempty"`
 
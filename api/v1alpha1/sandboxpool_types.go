@@ -34,6 +34,7 @@ type ProvisioningSpec struct {
 	// +optional
 	Mode ProvisioningMode `json:"mode,omitempty"`
 
+	// PoolRef names the warm pool to use, or a comma-separated list of pools.
 	// +optional
 	PoolRef string `json:"poolRef,omitempty"`
 
@@ -53,6 +54,76 @@ type SandboxPoolSpec struct {
 
 	// +kubebuilder:validation:Required
 	Template *SandboxTemplate `json:"sandboxTemplate"`
+
+	// Autoscale enables scaling the pool between MinReplicas and MaxReplicas
+	// based on the recent warm-hit ratio of claims. When set, Replicas is only
+	// used as the initial size.
+	// +optional
+	Autoscale *SandboxPoolAutoscale `json:"autoscale,omitempty"`
+
+	// Warming selects how pool pods are kept warm. Defaults to running the full sandbox workload.
+	// +optional
+	Warming *SandboxPoolWarming `json:"warming,omitempty"`
+}
+
+// WarmingStrategy defines how pool pods are kept warm.
+// +kubebuilder:validation:Enum=Running;ImagePull
+type WarmingStrategy string
+
+const (
+	// WarmingStrategyRunning runs the full sandbox workload in every pool pod.
+	WarmingStrategyRunning WarmingStrategy = "Running"
+	// WarmingStrategyImagePull only pulls the sandbox image onto the node: an init container runs the
+	// sandbox image once and the main container runs a pause image. Adopting the pod swaps the main
+	// container to the sandbox image, which starts from the node's image cache.
+	WarmingStrategyImagePull WarmingStrategy = "ImagePull"
+)
+
+// SandboxPoolWarming configures the warming strategy of a SandboxPool.
+// With the ImagePull strategy the template must not set a command, because the command of the
+// main container cannot change when the image is swapped, and the liveness probe is omitted so the
+// pause container is not restarted.
+type SandboxPoolWarming struct {
+	// +kubebuilder:default=Running
+	// +optional
+	Strategy WarmingStrategy `json:"strategy,omitempty"`
+
+	// PauseImage is the image the main container runs until the pod is adopted.
+	// Defaults to registry.k8s.io/pause:3.10.
+	// +optional
+	PauseImage string `json:"pauseImage,omitempty"`
+
+	// PullCommand is executed by the init container in the sandbox image and must exit zero.
+	// Defaults to ["true"].
+	// +optional
+	PullCommand []string `json:"pullCommand,omitempty"`
+}
+
+// SandboxPoolAutoscale defines hit-rate based autoscaling for a SandboxPool.
+type SandboxPoolAutoscale struct {
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=0
+	// +optional
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Required
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetWarmHitPercent is the desired percentage of claims served by a warm pod.
+	// The pool grows when the observed ratio falls below it.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default=90
+	// +optional
+	TargetWarmHitPercent int32 `json:"targetWarmHitPercent,omitempty"`
+
+	// WindowSeconds is the length of the sliding window used to observe claims,
+	// and also the minimum interval between two scaling decisions.
+	// +kubebuilder:validation:Minimum=10
+	// +kubebuilder:default=300
+	// +optional
+	WindowSeconds int32 `json:"windowSeconds,omitempty"`
 }
 
 // SandboxPoolStatus defines the observed state of SandboxPool.
@@ -62,6 +133,14 @@ type SandboxPoolStatus struct {
 
 	// +optional
 	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// DesiredReplicas is the pool size chosen by the autoscaler.
+	// +optional
+	DesiredReplicas int32 `json:"desiredReplicas,omitempty"`
+
+	// LastScaleTime is the last time the autoscaler changed DesiredReplicas.
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
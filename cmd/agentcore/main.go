@@ -37,6 +37,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -45,6 +46,7 @@ import (
 	"github.com/Fl0rencess720/agentland/internal/controller"
 	"github.com/Fl0rencess720/agentland/pkg/agentcore"
 	"github.com/Fl0rencess720/agentland/pkg/agentcore/config"
+	"github.com/Fl0rencess720/agentland/pkg/agentcore/pkgs/db"
 	"github.com/Fl0rencess720/agentland/pkg/common/conf"
 	"github.com/Fl0rencess720/agentland/pkg/common/logging"
 	"github.com/Fl0rencess720/agentland/pkg/common/observability"
@@ -80,6 +82,17 @@ func parseImagePullPolicy(raw string) corev1.PullPolicy {
 	}
 }
 
+// parseKeyList 解析逗号分隔的键列表，忽略空白项
+func parseKeyList(raw string) []string {
+	var keys []string
+	for _, key := range strings.Split(raw, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
 // nolint:gocyclo
 func main() {
 	var metricsAddr string
@@ -125,9 +138,14 @@ func main() {
 	_ = viper.BindEnv("warm_pool.default_mode", "AL_WARMPOOL_DEFAULT_MODE")
 	_ = viper.BindEnv("warm_pool.pool_ref", "AL_WARMPOOL_POOL_REF")
 	_ = viper.BindEnv("warm_pool.profile", "AL_WARMPOOL_PROFILE")
+	_ = viper.BindEnv("warm_pool.cold_start_timeout", "AL_WARMPOOL_COLD_START_TIMEOUT")
 	_ = viper.BindEnv("korokd.image", "AL_KOROKD_IMAGE")
 	_ = viper.BindEnv("korokd.image_pull_policy", "AL_KOROKD_IMAGE_PULL_POLICY")
 	_ = viper.BindEnv("korokd.runtime_class_name", "AL_KOROKD_RUNTIME_CLASS_NAME")
+	_ = viper.BindEnv("korokd.pod_metadata.labels", "AL_KOROKD_POD_LABEL_ALLOWLIST")
+	_ = viper.BindEnv("korokd.pod_metadata.annotations", "AL_KOROKD_POD_ANNOTATION_ALLOWLIST")
+	_ = viper.BindEnv("agentcore.provisioning_timeout", "AL_AGENTCORE_PROVISIONING_TIMEOUT")
+	_ = viper.BindEnv("agentcore.max_sessions_per_owner", "AL_AGENTCORE_MAX_SESSIONS_PER_OWNER")
 	_ = viper.BindEnv("otel.enabled", "AL_OTEL_ENABLED")
 	_ = viper.BindEnv("otel.endpoint", "AL_OTEL_EXPORTER_OTLP_ENDPOINT")
 	_ = viper.BindEnv("otel.insecure", "AL_OTEL_EXPORTER_OTLP_INSECURE")
@@ -136,9 +154,20 @@ func main() {
 	viper.SetDefault("warm_pool.default_mode", "PoolPreferred")
 	viper.SetDefault("warm_pool.pool_ref", "")
 	viper.SetDefault("warm_pool.profile", "default")
+	viper.SetDefault("warm_pool.cold_start_timeout", "5m")
 	viper.SetDefault("korokd.image", "korokd:latest")
 	viper.SetDefault("korokd.image_pull_policy", string(corev1.PullAlways))
 	viper.SetDefault("korokd.runtime_class_name", "")
+	viper.SetDefault("korokd.pod_metadata.labels", "")
+	viper.SetDefault("korokd.pod_metadata.annotations", "")
+	viper.SetDefault("korokd.security_context.enabled", false)
+	viper.SetDefault("korokd.security_context.run_as_non_root", true)
+	viper.SetDefault("korokd.security_context.run_as_user", 1000)
+	viper.SetDefault("korokd.security_context.read_only_root_filesystem", true)
+	viper.SetDefault("korokd.security_context.drop_all_capabilities", true)
+	viper.SetDefault("korokd.security_context.seccomp_runtime_default", true)
+	viper.SetDefault("agentcore.provisioning_timeout", "60s")
+	viper.SetDefault("agentcore.max_sessions_per_owner", 0)
 	viper.SetDefault("otel.enabled", false)
 	viper.SetDefault("otel.endpoint", "otel-collector:4317")
 	viper.SetDefault("otel.insecure", true)
@@ -151,6 +180,19 @@ func main() {
 		setupLog.Info("invalid korokd image pull policy; fallback to Always", "value", korokdImagePullPolicyRaw)
 	}
 
+	sandboxSecurityContext := controller.SandboxSecurityDefaults{
+		Enabled:                viper.GetBool("korokd.security_context.enabled"),
+		RunAsNonRoot:           viper.GetBool("korokd.security_context.run_as_non_root"),
+		RunAsUser:              viper.GetInt64("korokd.security_context.run_as_user"),
+		ReadOnlyRootFilesystem: viper.GetBool("korokd.security_context.read_only_root_filesystem"),
+		DropAllCapabilities:    viper.GetBool("korokd.security_context.drop_all_capabilities"),
+		SeccompRuntimeDefault:  viper.GetBool("korokd.security_context.seccomp_runtime_default"),
+	}.SecurityContext()
+	sandboxPodMetadata := controller.SandboxPodMetadata{
+		Labels:      parseKeyList(viper.GetString("korokd.pod_metadata.labels")),
+		Annotations: parseKeyList(viper.GetString("korokd.pod_metadata.annotations")),
+	}
+
 	otelShutdown, err := observability.InitTracerProvider(context.Background(), observability.Config{
 		Enabled:        viper.GetBool("otel.enabled"),
 		ServiceName:    "agentland-agentcore",
@@ -171,6 +213,20 @@ func main() {
 		}
 	}()
 
+	// 控制器指标通过 OTel 记录，并由 manager 的 /metrics 端点暴露
+	meterShutdown, err := observability.InitMeterProvider(crmetrics.Registry)
+	if err != nil {
+		setupLog.Error(err, "unable to initialize metrics")
+		os.Exit(1)
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if shutdownErr := meterShutdown(shutdownCtx); shutdownErr != nil {
+			setupLog.Error(shutdownErr, "failed to shutdown meter provider")
+		}
+	}()
+
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
@@ -265,17 +321,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	sessionStore := db.NewSessionStore()
 	if err := (&controller.CodeInterpreterReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		SessionStore: sessionStore,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CodeInterpreter")
 		os.Exit(1)
 	}
 
 	if err := (&controller.AgentSessionReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		SessionStore: sessionStore,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AgentSession")
 		os.Exit(1)
@@ -290,26 +349,33 @@ func main() {
 	}
 
 	if err := (&controller.SandboxReconciler{
-		Client:          mgr.GetClient(),
-		Scheme:          mgr.GetScheme(),
-		ImagePullPolicy: korokdImagePullPolicy,
+		Client:                 mgr.GetClient(),
+		Scheme:                 mgr.GetScheme(),
+		ImagePullPolicy:        korokdImagePullPolicy,
+		DefaultSecurityContext: sandboxSecurityContext,
+		PodMetadata:            sandboxPodMetadata,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Sandbox")
 		os.Exit(1)
 	}
 
+	poolClaimTracker := controller.NewPoolClaimTracker()
 	if err := (&controller.SandboxPoolReconciler{
-		Client:          mgr.GetClient(),
-		Scheme:          mgr.GetScheme(),
-		ImagePullPolicy: korokdImagePullPolicy,
+		Client:                 mgr.GetClient(),
+		Scheme:                 mgr.GetScheme(),
+		ImagePullPolicy:        korokdImagePullPolicy,
+		DefaultSecurityContext: sandboxSecurityContext,
+		Tracker:                poolClaimTracker,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SandboxPool")
 		os.Exit(1)
 	}
 
 	if err := (&controller.SandboxClaimReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:           mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		Tracker:          poolClaimTracker,
+		ColdStartTimeout: viper.GetDuration("warm_pool.cold_start_timeout"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SandboxClaim")
 		os.Exit(1)
@@ -331,6 +397,8 @@ func main() {
 		WarmPoolDefaultMode:    viper.GetString("warm_pool.default_mode"),
 		WarmPoolPoolRef:        viper.GetString("warm_pool.pool_ref"),
 		WarmPoolProfile:        viper.GetString("warm_pool.profile"),
+		ProvisioningTimeout:    viper.GetDuration("agentcore.provisioning_timeout"),
+		MaxSessionsPerOwner:    viper.GetInt("agentcore.max_sessions_per_owner"),
 	}
 
 	// 创建 gRPC Server 实例
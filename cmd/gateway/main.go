@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -27,6 +28,7 @@ func init() {
 
 func main() {
 	port := flag.String("port", "8080", "Gateway server port")
+	rotateKey := flag.Bool("rotate-sandbox-jwt-key", false, "Rotate the sandbox JWT signing key and exit")
 	flag.Parse()
 
 	// 绑定环境变量
@@ -45,8 +47,36 @@ func main() {
 	_ = viper.BindEnv("sandbox.jwt.audience", "AL_SANDBOX_JWT_AUDIENCE")
 	_ = viper.BindEnv("sandbox.jwt.ttl", "AL_SANDBOX_JWT_TTL")
 	_ = viper.BindEnv("sandbox.jwt.kid", "AL_SANDBOX_JWT_KID")
+	_ = viper.BindEnv("sandbox.jwt.algorithm", "AL_SANDBOX_JWT_ALGORITHM")
+	_ = viper.BindEnv("sandbox.jwt.rotation_grace", "AL_SANDBOX_JWT_ROTATION_GRACE")
+	_ = viper.BindEnv("sandbox.jwt.key_refresh_interval", "AL_SANDBOX_JWT_KEY_REFRESH_INTERVAL")
 	_ = viper.BindEnv("agent_runtime.default_name", "AL_AGENT_RUNTIME_DEFAULT_NAME")
 	_ = viper.BindEnv("agent_runtime.default_namespace", "AL_AGENT_RUNTIME_DEFAULT_NAMESPACE")
+	_ = viper.BindEnv("rate_limit.rps", "AL_RATE_LIMIT_RPS")
+	_ = viper.BindEnv("rate_limit.burst", "AL_RATE_LIMIT_BURST")
+	_ = viper.BindEnv("gateway.drain_timeout", "AL_GATEWAY_DRAIN_TIMEOUT")
+	_ = viper.BindEnv("gateway.max_upload_bytes", "AL_GATEWAY_MAX_UPLOAD_BYTES")
+	_ = viper.BindEnv("gateway.execute.default_timeout", "AL_GATEWAY_EXECUTE_DEFAULT_TIMEOUT")
+	_ = viper.BindEnv("gateway.execute.max_timeout", "AL_GATEWAY_EXECUTE_MAX_TIMEOUT")
+	_ = viper.BindEnv("gateway.sandbox_http.max_idle_conns", "AL_GATEWAY_SANDBOX_HTTP_MAX_IDLE_CONNS")
+	_ = viper.BindEnv("gateway.sandbox_http.max_idle_conns_per_host", "AL_GATEWAY_SANDBOX_HTTP_MAX_IDLE_CONNS_PER_HOST")
+	_ = viper.BindEnv("gateway.sandbox_http.max_conns_per_host", "AL_GATEWAY_SANDBOX_HTTP_MAX_CONNS_PER_HOST")
+	_ = viper.BindEnv("gateway.sandbox_http.idle_conn_timeout", "AL_GATEWAY_SANDBOX_HTTP_IDLE_CONN_TIMEOUT")
+	_ = viper.BindEnv("gateway.sandbox_http.dial_timeout", "AL_GATEWAY_SANDBOX_HTTP_DIAL_TIMEOUT")
+	_ = viper.BindEnv("gateway.sandbox_http.response_header_timeout", "AL_GATEWAY_SANDBOX_HTTP_RESPONSE_HEADER_TIMEOUT")
+	_ = viper.BindEnv("gateway.sandbox_http.tls_ca_path", "AL_GATEWAY_SANDBOX_HTTP_TLS_CA_PATH")
+	_ = viper.BindEnv("gateway.auth.api_keys", "AL_GATEWAY_AUTH_API_KEYS")
+	_ = viper.BindEnv("gateway.auth.jwt.issuer", "AL_GATEWAY_AUTH_JWT_ISSUER")
+	_ = viper.BindEnv("gateway.auth.jwt.audience", "AL_GATEWAY_AUTH_JWT_AUDIENCE")
+	_ = viper.BindEnv("gateway.auth.jwt.public_key_path", "AL_GATEWAY_AUTH_JWT_PUBLIC_KEY_PATH")
+	_ = viper.BindEnv("gateway.auth.jwt.jwks_url", "AL_GATEWAY_AUTH_JWT_JWKS_URL")
+	_ = viper.BindEnv("gateway.cors.allowed_origins", "AL_GATEWAY_CORS_ALLOWED_ORIGINS")
+	_ = viper.BindEnv("gateway.cors.allowed_methods", "AL_GATEWAY_CORS_ALLOWED_METHODS")
+	_ = viper.BindEnv("gateway.cors.allowed_headers", "AL_GATEWAY_CORS_ALLOWED_HEADERS")
+	_ = viper.BindEnv("gateway.cors.allow_credentials", "AL_GATEWAY_CORS_ALLOW_CREDENTIALS")
+	_ = viper.BindEnv("gateway.cors.max_age", "AL_GATEWAY_CORS_MAX_AGE")
+	_ = viper.BindEnv("gateway.audit.log_path", "AL_GATEWAY_AUDIT_LOG_PATH")
+	_ = viper.BindEnv("gateway.audit.include_code", "AL_GATEWAY_AUDIT_INCLUDE_CODE")
 	_ = viper.BindEnv("otel.enabled", "AL_OTEL_ENABLED")
 	_ = viper.BindEnv("otel.endpoint", "AL_OTEL_EXPORTER_OTLP_ENDPOINT")
 	_ = viper.BindEnv("otel.insecure", "AL_OTEL_EXPORTER_OTLP_INSECURE")
@@ -61,8 +91,16 @@ func main() {
 	viper.SetDefault("sandbox.jwt.audience", "sandbox")
 	viper.SetDefault("sandbox.jwt.ttl", "5m")
 	viper.SetDefault("sandbox.jwt.kid", "default")
+	viper.SetDefault("sandbox.jwt.algorithm", "RS256")
+	viper.SetDefault("sandbox.jwt.rotation_grace", "30m")
+	viper.SetDefault("sandbox.jwt.key_refresh_interval", "30s")
 	viper.SetDefault("agent_runtime.default_name", "default-runtime")
 	viper.SetDefault("agent_runtime.default_namespace", "agentland-sandboxes")
+	viper.SetDefault("rate_limit.rps", 0)
+	viper.SetDefault("rate_limit.burst", 0)
+	viper.SetDefault("gateway.drain_timeout", "20s")
+	viper.SetDefault("gateway.max_upload_bytes", 104857600)
+	viper.SetDefault("gateway.cors.max_age", "10m")
 	viper.SetDefault("otel.enabled", false)
 	viper.SetDefault("otel.endpoint", "otel-collector:4317")
 	viper.SetDefault("otel.insecure", true)
@@ -88,13 +126,27 @@ func main() {
 		}
 	}()
 
-	privateKeyPath, err := sandboxjwt.EnsureGatewaySigningKey(context.Background(), sandboxjwt.BootstrapConfig{
+	keyCfg := sandboxjwt.BootstrapConfig{
 		IdentitySecretName:      viper.GetString("sandbox.jwt.identity_secret_name"),
 		IdentitySecretNamespace: viper.GetString("sandbox.jwt.identity_secret_namespace"),
 		PublicSecretName:        viper.GetString("sandbox.jwt.public_secret_name"),
 		PublicSecretNamespace:   viper.GetString("sandbox.jwt.public_secret_namespace"),
 		LocalPrivateKeyPath:     viper.GetString("sandbox.jwt.private_key_path"),
-	})
+		Algorithm:               viper.GetString("sandbox.jwt.algorithm"),
+		KID:                     viper.GetString("sandbox.jwt.kid"),
+	}
+
+	if *rotateKey {
+		kid, err := sandboxjwt.RotateSigningKey(context.Background(), keyCfg, viper.GetDuration("sandbox.jwt.rotation_grace"))
+		if err != nil {
+			zap.L().Fatal("Rotate gateway sandbox JWT key failed", zap.Error(err))
+			return
+		}
+		zap.L().Info("Rotated gateway sandbox JWT key", zap.String("kid", kid))
+		return
+	}
+
+	signingKey, err := sandboxjwt.EnsureGatewaySigningKey(context.Background(), keyCfg)
 	if err != nil {
 		zap.L().Fatal("Ensure gateway sandbox JWT key failed", zap.Error(err))
 		return
@@ -102,13 +154,44 @@ func main() {
 
 	config := &config.Config{
 		Port:                         *port,
-		SandboxJWTPrivatePath:        privateKeyPath,
+		SandboxJWTPrivatePath:        signingKey.PrivateKeyPath,
 		SandboxJWTIssuer:             viper.GetString("sandbox.jwt.issuer"),
 		SandboxJWTAudience:           viper.GetString("sandbox.jwt.audience"),
 		SandboxJWTTTL:                viper.GetDuration("sandbox.jwt.ttl"),
-		SandboxJWTKID:                viper.GetString("sandbox.jwt.kid"),
+		SandboxJWTKID:                signingKey.KID,
+		SandboxJWTAlgorithm:          viper.GetString("sandbox.jwt.algorithm"),
+		SandboxJWTKeyRefreshInterval: viper.GetDuration("sandbox.jwt.key_refresh_interval"),
 		DefaultAgentRuntimeName:      viper.GetString("agent_runtime.default_name"),
 		DefaultAgentRuntimeNamespace: viper.GetString("agent_runtime.default_namespace"),
+		RateLimitRPS:                 viper.GetFloat64("rate_limit.rps"),
+		RateLimitBurst:               viper.GetInt("rate_limit.burst"),
+		DrainTimeout:                 viper.GetDuration("gateway.drain_timeout"),
+		MaxUploadBytes:               viper.GetInt64("gateway.max_upload_bytes"),
+		ExecuteDefaultTimeout:        viper.GetDuration("gateway.execute.default_timeout"),
+		ExecuteMaxTimeout:            viper.GetDuration("gateway.execute.max_timeout"),
+
+		SandboxHTTPMaxIdleConns:          viper.GetInt("gateway.sandbox_http.max_idle_conns"),
+		SandboxHTTPMaxIdleConnsPerHost:   viper.GetInt("gateway.sandbox_http.max_idle_conns_per_host"),
+		SandboxHTTPMaxConnsPerHost:       viper.GetInt("gateway.sandbox_http.max_conns_per_host"),
+		SandboxHTTPIdleConnTimeout:       viper.GetDuration("gateway.sandbox_http.idle_conn_timeout"),
+		SandboxHTTPDialTimeout:           viper.GetDuration("gateway.sandbox_http.dial_timeout"),
+		SandboxHTTPResponseHeaderTimeout: viper.GetDuration("gateway.sandbox_http.response_header_timeout"),
+		SandboxHTTPTLSCAPath:             viper.GetString("gateway.sandbox_http.tls_ca_path"),
+
+		AuthAPIKeys:          splitNonEmpty(viper.GetString("gateway.auth.api_keys"), ","),
+		AuthJWTIssuer:        viper.GetString("gateway.auth.jwt.issuer"),
+		AuthJWTAudience:      viper.GetString("gateway.auth.jwt.audience"),
+		AuthJWTPublicKeyPath: viper.GetString("gateway.auth.jwt.public_key_path"),
+		AuthJWTJWKSURL:       viper.GetString("gateway.auth.jwt.jwks_url"),
+
+		CORSAllowedOrigins:   splitNonEmpty(viper.GetString("gateway.cors.allowed_origins"), ","),
+		CORSAllowedMethods:   splitNonEmpty(viper.GetString("gateway.cors.allowed_methods"), ","),
+		CORSAllowedHeaders:   splitNonEmpty(viper.GetString("gateway.cors.allowed_headers"), ","),
+		CORSAllowCredentials: viper.GetBool("gateway.cors.allow_credentials"),
+		CORSMaxAge:           viper.GetDuration("gateway.cors.max_age"),
+
+		AuditLogPath:     viper.GetString("gateway.audit.log_path"),
+		AuditIncludeCode: viper.GetBool("gateway.audit.include_code"),
 	}
 
 	server, err := gateway.NewServer(config)
@@ -121,6 +204,8 @@ func main() {
 	defer cancel()
 	defer logging.Sync(zap.L())
 
+	go refreshSigningKey(ctx, keyCfg, config.SandboxJWTKeyRefreshInterval)
+
 	errCh := make(chan error, 1)
 	go func() {
 		if err := server.Serve(ctx); err != nil {
@@ -144,3 +229,33 @@ func main() {
 		zap.L().Fatal("Server error", zap.Error(err))
 	}
 }
+
+// refreshSigningKey 定期重读密钥集合，使其他实例轮换密钥后本实例无需重启即切换到新的 active 密钥
+func refreshSigningKey(ctx context.Context, keyCfg sandboxjwt.BootstrapConfig, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := sandboxjwt.RefreshSigningKey(ctx, keyCfg); err != nil {
+				zap.L().Warn("Refresh sandbox JWT signing key failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// splitNonEmpty 按 sep 切分 s 并去掉空白项
+func splitNonEmpty(s, sep string) []string {
+	var parts []string
+	for _, part := range strings.Split(s, sep) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
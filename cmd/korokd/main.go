@@ -4,14 +4,17 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/Fl0rencess720/agentland/pkg/common/logging"
 	"github.com/Fl0rencess720/agentland/pkg/korokd"
 	"github.com/Fl0rencess720/agentland/pkg/korokd/config"
+	"github.com/Fl0rencess720/agentland/pkg/korokd/pkgs/jupyter"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
@@ -22,6 +25,7 @@ func init() {
 
 func main() {
 	port := flag.String("port", "1883", "korokd HTTP server port")
+	jupyterArgs := flag.Bool("jupyter-args", false, "validate kernel transport config, print Jupyter Server flags and exit")
 	flag.Parse()
 
 	viper.SetEnvPrefix("al")
@@ -29,24 +33,105 @@ func main() {
 	_ = viper.BindEnv("sandbox.jwt.issuer", "AL_SANDBOX_JWT_ISSUER")
 	_ = viper.BindEnv("sandbox.jwt.audience", "AL_SANDBOX_JWT_AUDIENCE")
 	_ = viper.BindEnv("sandbox.jwt.clock_skew", "AL_SANDBOX_JWT_CLOCK_SKEW")
+	_ = viper.BindEnv("sandbox.jwt.jwks_url", "AL_SANDBOX_JWT_JWKS_URL")
+	_ = viper.BindEnv("sandbox.jwt.jwks_cache_ttl", "AL_SANDBOX_JWT_JWKS_CACHE_TTL")
+	_ = viper.BindEnv("sandbox.jwt.key_reload_interval", "AL_SANDBOX_JWT_KEY_RELOAD_INTERVAL")
 	_ = viper.BindEnv("korokd.workspace_root", "AL_KOROKD_WORKSPACE_ROOT")
 	_ = viper.BindEnv("korokd.max_file_bytes", "AL_KOROKD_MAX_FILE_BYTES")
+	_ = viper.BindEnv("korokd.max_archive_bytes", "AL_KOROKD_MAX_ARCHIVE_BYTES")
+	_ = viper.BindEnv("korokd.max_workspace_bytes", "AL_KOROKD_MAX_WORKSPACE_BYTES")
+	_ = viper.BindEnv("korokd.max_upload_total_bytes", "AL_KOROKD_MAX_UPLOAD_TOTAL_BYTES")
+	_ = viper.BindEnv("korokd.max_rich_output_bytes", "AL_KOROKD_MAX_RICH_OUTPUT_BYTES")
+	_ = viper.BindEnv("korokd.max_output_bytes", "AL_KOROKD_MAX_OUTPUT_BYTES")
+	_ = viper.BindEnv("korokd.metrics_port", "AL_KOROKD_METRICS_PORT")
+	_ = viper.BindEnv("korokd.grpc_port", "AL_KOROKD_GRPC_PORT")
+	_ = viper.BindEnv("korokd.proxy.ready_timeout", "AL_KOROKD_PROXY_READY_TIMEOUT")
+	_ = viper.BindEnv("korokd.context.env_allowlist", "AL_KOROKD_CONTEXT_ENV_ALLOWLIST")
+	_ = viper.BindEnv("korokd.context.max_count", "AL_KOROKD_CONTEXT_MAX_COUNT")
+	_ = viper.BindEnv("korokd.context.idle_ttl", "AL_KOROKD_CONTEXT_IDLE_TTL")
+	_ = viper.BindEnv("korokd.context.gc_interval", "AL_KOROKD_CONTEXT_GC_INTERVAL")
+	_ = viper.BindEnv("korokd.context.default_timeout_ms", "AL_KOROKD_CONTEXT_DEFAULT_TIMEOUT_MS")
+	_ = viper.BindEnv("korokd.context.history_size", "AL_KOROKD_CONTEXT_HISTORY_SIZE")
+	_ = viper.BindEnv("korokd.audit.log_path", "AL_KOROKD_AUDIT_LOG_PATH")
+	_ = viper.BindEnv("korokd.audit.include_code", "AL_KOROKD_AUDIT_INCLUDE_CODE")
+	_ = viper.BindEnv("korokd.kernel.transport", "AL_KOROKD_KERNEL_TRANSPORT")
+	_ = viper.BindEnv("korokd.kernel.ipc_dir", "AL_KOROKD_KERNEL_IPC_DIR")
+	_ = viper.BindEnv("korokd.kernel.signature_scheme", "AL_KOROKD_KERNEL_SIGNATURE_SCHEME")
 
 	viper.SetDefault("sandbox.jwt.public_key_path", "/var/run/agentland/jwt/public.pem")
 	viper.SetDefault("sandbox.jwt.issuer", "agentland-gateway")
 	viper.SetDefault("sandbox.jwt.audience", "sandbox")
 	viper.SetDefault("sandbox.jwt.clock_skew", "30s")
+	viper.SetDefault("sandbox.jwt.jwks_cache_ttl", "5m")
+	viper.SetDefault("sandbox.jwt.key_reload_interval", "10s")
 	viper.SetDefault("korokd.workspace_root", "/workspace")
 	viper.SetDefault("korokd.max_file_bytes", 1048576)
+	viper.SetDefault("korokd.max_archive_bytes", 104857600)
+	viper.SetDefault("korokd.max_upload_total_bytes", 104857600)
+	viper.SetDefault("korokd.max_rich_output_bytes", 1048576)
+	viper.SetDefault("korokd.max_output_bytes", 1048576)
+	viper.SetDefault("korokd.metrics_port", "9464")
+	viper.SetDefault("korokd.proxy.ready_timeout", "3s")
+	viper.SetDefault("korokd.context.max_count", 32)
+	viper.SetDefault("korokd.context.idle_ttl", "15m")
+	viper.SetDefault("korokd.context.gc_interval", "30s")
+	viper.SetDefault("korokd.context.default_timeout_ms", 30000)
+	viper.SetDefault("korokd.kernel.transport", jupyter.TransportTCP)
+	viper.SetDefault("korokd.kernel.ipc_dir", jupyter.DefaultIPCDir)
+	viper.SetDefault("korokd.kernel.signature_scheme", jupyter.DefaultSignatureScheme)
 
 	cfg := &config.Config{
 		Port:                 *port,
+		MetricsPort:          viper.GetString("korokd.metrics_port"),
+		GRPCPort:             viper.GetString("korokd.grpc_port"),
 		SandboxJWTPublicPath: viper.GetString("sandbox.jwt.public_key_path"),
 		SandboxJWTIssuer:     viper.GetString("sandbox.jwt.issuer"),
 		SandboxJWTAudience:   viper.GetString("sandbox.jwt.audience"),
 		SandboxJWTClockSkew:  viper.GetDuration("sandbox.jwt.clock_skew"),
 		WorkspaceRoot:        viper.GetString("korokd.workspace_root"),
 		MaxFileBytes:         viper.GetInt64("korokd.max_file_bytes"),
+		MaxRichOutputBytes:   viper.GetInt64("korokd.max_rich_output_bytes"),
+		MaxOutputBytes:       viper.GetInt64("korokd.max_output_bytes"),
+		ProxyReadyTimeout:    viper.GetDuration("korokd.proxy.ready_timeout"),
+		ContextEnvAllowlist:  strings.Split(viper.GetString("korokd.context.env_allowlist"), ","),
+
+		SandboxJWTJWKSURL:           viper.GetString("sandbox.jwt.jwks_url"),
+		SandboxJWTJWKSCacheTTL:      viper.GetDuration("sandbox.jwt.jwks_cache_ttl"),
+		SandboxJWTKeyReloadInterval: viper.GetDuration("sandbox.jwt.key_reload_interval"),
+		MaxArchiveBytes:             viper.GetInt64("korokd.max_archive_bytes"),
+		MaxWorkspaceBytes:           viper.GetInt64("korokd.max_workspace_bytes"),
+		MaxUploadTotalBytes:         viper.GetInt64("korokd.max_upload_total_bytes"),
+
+		ContextMaxCount:         viper.GetInt("korokd.context.max_count"),
+		ContextIdleTTL:          viper.GetDuration("korokd.context.idle_ttl"),
+		ContextGCInterval:       viper.GetDuration("korokd.context.gc_interval"),
+		ContextDefaultTimeoutMs: viper.GetInt("korokd.context.default_timeout_ms"),
+		ContextHistorySize:      viper.GetInt("korokd.context.history_size"),
+
+		AuditLogPath:     viper.GetString("korokd.audit.log_path"),
+		AuditIncludeCode: viper.GetBool("korokd.audit.include_code"),
+
+		KernelTransport:       viper.GetString("korokd.kernel.transport"),
+		KernelIPCDir:          viper.GetString("korokd.kernel.ipc_dir"),
+		KernelSignatureScheme: viper.GetString("korokd.kernel.signature_scheme"),
+	}
+
+	// 入口脚本在启动 Jupyter Server 前调用，配置非法时以非零状态退出，容器直接启动失败
+	if *jupyterArgs {
+		kernelCfg := jupyter.KernelTransportConfig{
+			Transport:       cfg.KernelTransport,
+			IPCDir:          cfg.KernelIPCDir,
+			SignatureScheme: cfg.KernelSignatureScheme,
+		}
+		if err := kernelCfg.Prepare(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if dir := kernelCfg.EffectiveIPCDir(); strings.EqualFold(cfg.KernelTransport, jupyter.TransportIPC) && dir != cfg.KernelIPCDir {
+			fmt.Fprintf(os.Stderr, "kernel ipc dir %s is too long for unix sockets, using %s\n", cfg.KernelIPCDir, dir)
+		}
+		fmt.Println(strings.Join(kernelCfg.ServerArgs(), "\n"))
+		return
 	}
 	server, err := korokd.NewServer(cfg)
 	if err != nil {
@@ -22,6 +22,11 @@ export JUPYTER_TOKEN
 
 JUPYTER_LOG="${JUPYTER_LOG:-/tmp/jupyter.log}"
 
+# Kernel transport and signature scheme come from korokd config (AL_KOROKD_KERNEL_*);
+# korokd exits non-zero on invalid values, e.g. an ipc socket path that overflows sun_path.
+KERNEL_ARGS_OUTPUT="$(/app/korokd -jupyter-args)"
+mapfile -t KERNEL_ARGS <<<"${KERNEL_ARGS_OUTPUT}"
+
 cleanup() {
   set +e
   if [[ -n "${JUPYTER_PID:-}" ]]; then
@@ -42,6 +47,7 @@ jupyter notebook \
   --notebook-dir="${JUPYTER_NOTEBOOK_DIR}" \
   --NotebookApp.token="${JUPYTER_TOKEN}" \
   --ServerApp.token="${JUPYTER_TOKEN}" \
+  "${KERNEL_ARGS[@]}" \
   >"${JUPYTER_LOG}" 2>&1 &
 JUPYTER_PID=$!
 
@@ -81,5 +81,5 @@ func (r *AgentRuntimeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&agentlandv1alpha1.AgentRuntime{}).
 		Named("agentruntime").
-		Complete(r)
+		Complete(instrumentReconciler("agentruntime", r, defaultControllerMetrics()))
 }
@@ -19,6 +19,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -39,6 +40,8 @@ import (
 type AgentSessionReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// SessionStore 用于删除时清理会话存储，为 nil 时跳过
+	SessionStore SessionStore
 }
 
 // +kubebuilder:rbac:groups=agentland.fl0rencess720.app,resources=agentsessions,verbs=get;list;watch;create;update;patch;delete
@@ -59,13 +62,25 @@ func (r *AgentSessionReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	}
 
 	if !agentSession.DeletionTimestamp.IsZero() {
-		if !controllerutil.ContainsFinalizer(agentSession, "agentsession.finalizers.agentland.fl0rencess720.app") {
-			log.Info("AgentSession is being deleted")
-			return ctrl.Result{}, nil
-		}
 		log.Info("AgentSession is being deleted")
+		return finalizeSession(ctx, r.Client, r.SessionStore, agentSession, agentSessionFinalizer)
+	}
+	if _, err := ensureFinalizer(ctx, r.Client, agentSession, agentSessionFinalizer); err != nil {
+		if errors.IsConflict(err) {
+			return ctrl.Result{RequeueAfter: commonutils.ConflictRequeueInterval}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if agentSession.Status.Phase == agentSessionPhaseExpired {
 		return ctrl.Result{}, nil
 	}
+	now := time.Now()
+	reason, untilDeadline := sessionExpiry(agentSession, now)
+	if reason != "" {
+		log.Info("AgentSession expired", "reason", reason)
+		return ctrl.Result{}, r.expireAgentSession(ctx, agentSession, reason)
+	}
 
 	resolved, result, err := r.resolveSessionConfig(ctx, agentSession)
 	if err != nil {
@@ -80,15 +95,107 @@ func (r *AgentSessionReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		mode = resolved.Provisioning.Mode
 	}
 
+	var res ctrl.Result
 	if mode == agentlandv1alpha1.ProvisioningModeDirect {
-		return r.reconcileDirect(ctx, agentSession, resolved)
+		res, err = r.reconcileDirect(ctx, agentSession, resolved)
+	} else {
+		res, err = r.reconcileViaClaim(ctx, agentSession, resolved, mode)
+	}
+	if err != nil || untilDeadline <= 0 {
+		return res, err
+	}
+	if res.RequeueAfter == 0 || untilDeadline < res.RequeueAfter {
+		res.RequeueAfter = untilDeadline
+	}
+	return res, nil
+}
+
+const (
+	agentSessionPhaseExpired = "Expired"
+
+	sessionExpiredReasonIdle        = "IdleTimeout"
+	sessionExpiredReasonMaxDuration = "MaxSessionDurationExceeded"
+)
+
+// sessionExpiry 根据 SessionTimeout 与 MaxSessionDuration 判断会话是否过期；
+// 未过期时返回距离最近截止时间的间隔，两者均未配置时返回 0。
+// 最后活跃时间取自 LastActivityAnnotation，缺失或无法解析时按创建时间计算。
+func sessionExpiry(agentSession *agentlandv1alpha1.AgentSession, now time.Time) (reason string, untilDeadline time.Duration) {
+	created := agentSession.CreationTimestamp.Time
+	if created.IsZero() {
+		created = now
+	}
+
+	var deadlines []time.Duration
+	if d := agentSession.Spec.MaxSessionDuration; d != nil && d.Duration > 0 {
+		remaining := created.Add(d.Duration).Sub(now)
+		if remaining <= 0 {
+			return sessionExpiredReasonMaxDuration, 0
+		}
+		deadlines = append(deadlines, remaining)
+	}
+	if d := agentSession.Spec.SessionTimeout; d != nil && d.Duration > 0 {
+		lastActivity := created
+		if v := agentSession.Annotations[commonutils.LastActivityAnnotation]; v != "" {
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil && t.After(lastActivity) {
+				lastActivity = t
+			}
+		}
+		remaining := lastActivity.Add(d.Duration).Sub(now)
+		if remaining <= 0 {
+			return sessionExpiredReasonIdle, 0
+		}
+		deadlines = append(deadlines, remaining)
+	}
+
+	for _, d := range deadlines {
+		if untilDeadline == 0 || d < untilDeadline {
+			untilDeadline = d
+		}
 	}
-	return r.reconcileViaClaim(ctx, agentSession, resolved, mode)
+	return "", untilDeadline
+}
+
+// expireAgentSession 删除会话拥有的 SandboxClaim 与 Sandbox（其 Pod 随之级联删除），并将会话标记为 Expired。
+func (r *AgentSessionReconciler) expireAgentSession(ctx context.Context, agentSession *agentlandv1alpha1.AgentSession, reason string) error {
+	key := client.ObjectKey{Namespace: agentSession.Namespace, Name: agentSession.Name}
+	for _, obj := range []client.Object{&agentlandv1alpha1.SandboxClaim{}, &agentlandv1alpha1.Sandbox{}} {
+		if err := r.Get(ctx, key, obj); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if !metav1.IsControlledBy(obj, agentSession) {
+			continue
+		}
+		if err := r.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	oldStatus := agentSession.Status.DeepCopy()
+	agentSession.Status.Phase = agentSessionPhaseExpired
+	agentSession.Status.PodIP = ""
+	meta.SetStatusCondition(&agentSession.Status.Conditions, metav1.Condition{
+		Type:               "Expired",
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		Message:            "session sandbox has been released",
+		ObservedGeneration: agentSession.Generation,
+		LastTransitionTime: metav1.Now(),
+	})
+	if !equality.Semantic.DeepEqual(oldStatus, &agentSession.Status) {
+		return r.Status().Update(ctx, agentSession)
+	}
+	return nil
 }
 
 type resolvedSessionConfig struct {
 	Template     *agentlandv1alpha1.SandboxTemplate
 	Provisioning *agentlandv1alpha1.ProvisioningSpec
+	// Ports 为会话对外声明的端口，AgentSession 未声明时继承 AgentRuntime
+	Ports []agentlandv1alpha1.Port
 }
 
 func (r *AgentSessionReconciler) resolveSessionConfig(ctx context.Context, agentSession *agentlandv1alpha1.AgentSession) (*resolvedSessionConfig, *ctrl.Result, error) {
@@ -120,6 +227,7 @@ func (r *AgentSessionReconciler) resolveSessionConfig(ctx context.Context, agent
 			tmp := *runtimeObj.Spec.Provisioning
 			resolved.Provisioning = &tmp
 		}
+		resolved.Ports = append([]agentlandv1alpha1.Port(nil), runtimeObj.Spec.Ports...)
 	}
 
 	if agentSession.Spec.Template != nil {
@@ -129,6 +237,9 @@ func (r *AgentSessionReconciler) resolveSessionConfig(ctx context.Context, agent
 		tmp := *agentSession.Spec.Provisioning
 		resolved.Provisioning = &tmp
 	}
+	if len(agentSession.Spec.Ports) > 0 {
+		resolved.Ports = append([]agentlandv1alpha1.Port(nil), agentSession.Spec.Ports...)
+	}
 
 	if resolved.Template == nil || resolved.Template.Image == "" {
 		if err := r.markAgentSessionFailed(ctx, agentSession, "TemplateMissing",
@@ -179,6 +290,7 @@ func (r *AgentSessionReconciler) reconcileDirect(ctx context.Context, agentSessi
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      agentSession.Name,
 				Namespace: agentSession.Namespace,
+				Labels:    ownerLabels(agentSession),
 			},
 			Spec: agentlandv1alpha1.SandboxSpec{
 				Profile:  profile,
@@ -194,7 +306,7 @@ func (r *AgentSessionReconciler) reconcileDirect(ctx context.Context, agentSessi
 		}
 	}
 
-	return r.updateAgentSessionStatus(ctx, agentSession, "", agentSession.Name)
+	return r.updateAgentSessionStatus(ctx, agentSession, resolved, "", agentSession.Name)
 }
 
 func (r *AgentSessionReconciler) reconcileViaClaim(ctx context.Context, agentSession *agentlandv1alpha1.AgentSession, resolved *resolvedSessionConfig, mode agentlandv1alpha1.ProvisioningMode) (ctrl.Result, error) {
@@ -220,7 +332,11 @@ func (r *AgentSessionReconciler) reconcileViaClaim(ctx context.Context, agentSes
 
 	if errors.IsNotFound(err) {
 		claim = &agentlandv1alpha1.SandboxClaim{
-			ObjectMeta: metav1.ObjectMeta{Name: agentSession.Name, Namespace: agentSession.Namespace},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      agentSession.Name,
+				Namespace: agentSession.Namespace,
+				Labels:    ownerLabels(agentSession),
+			},
 			Spec: agentlandv1alpha1.SandboxClaimSpec{
 				Profile:        profile,
 				PoolRef:        poolRef,
@@ -258,12 +374,13 @@ func (r *AgentSessionReconciler) reconcileViaClaim(ctx context.Context, agentSes
 		return ctrl.Result{}, nil
 	}
 
-	return r.updateAgentSessionStatus(ctx, agentSession, agentSession.Name, agentSession.Name)
+	return r.updateAgentSessionStatus(ctx, agentSession, resolved, agentSession.Name, agentSession.Name)
 }
 
-func (r *AgentSessionReconciler) updateAgentSessionStatus(ctx context.Context, agentSession *agentlandv1alpha1.AgentSession, claimName, sandboxName string) (ctrl.Result, error) {
+func (r *AgentSessionReconciler) updateAgentSessionStatus(ctx context.Context, agentSession *agentlandv1alpha1.AgentSession, resolved *resolvedSessionConfig, claimName, sandboxName string) (ctrl.Result, error) {
 	oldStatus := agentSession.Status.DeepCopy()
 
+	agentSession.Status.Ports = resolved.Ports
 	agentSession.Status.ClaimName = claimName
 	agentSession.Status.SandboxName = sandboxName
 	agentSession.Status.Phase = "Pending"
@@ -306,5 +423,5 @@ func (r *AgentSessionReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&agentlandv1alpha1.SandboxClaim{}).
 		Owns(&agentlandv1alpha1.Sandbox{}).
 		Named("agentsession").
-		Complete(r)
+		Complete(instrumentReconciler("agentsession", r, defaultControllerMetrics()))
 }
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentlandv1alpha1 "github.com/Fl0rencess720/agentland/api/v1alpha1"
+)
+
+func TestAgentSessionStatusAdvertisesPorts(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add corev1 scheme: %v", err)
+	}
+	if err := agentlandv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add agentland scheme: %v", err)
+	}
+
+	agentRuntime := &agentlandv1alpha1.AgentRuntime{
+		ObjectMeta: metav1.ObjectMeta{Name: "runtime-1", Namespace: "agentland-sandboxes"},
+		Spec: agentlandv1alpha1.AgentRuntimeSpec{
+			Ports:    []agentlandv1alpha1.Port{{Port: 8080}, {Port: 9000}},
+			Template: &agentlandv1alpha1.SandboxTemplate{Image: "agent:test"},
+		},
+	}
+	inherited := &agentlandv1alpha1.AgentSession{
+		ObjectMeta: metav1.ObjectMeta{Name: "session-inherit", Namespace: "agentland-sandboxes"},
+		Spec: agentlandv1alpha1.AgentSessionSpec{
+			RuntimeRef: &agentlandv1alpha1.RuntimeReference{Name: agentRuntime.Name},
+		},
+	}
+	overridden := &agentlandv1alpha1.AgentSession{
+		ObjectMeta: metav1.ObjectMeta{Name: "session-override", Namespace: "agentland-sandboxes"},
+		Spec: agentlandv1alpha1.AgentSessionSpec{
+			Ports:      []agentlandv1alpha1.Port{{Port: 3000}},
+			RuntimeRef: &agentlandv1alpha1.RuntimeReference{Name: agentRuntime.Name},
+		},
+	}
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(agentRuntime, inherited, overridden).
+		WithStatusSubresource(&agentlandv1alpha1.AgentSession{}).
+		Build()
+
+	for _, tc := range []struct {
+		session *agentlandv1alpha1.AgentSession
+		want    []agentlandv1alpha1.Port
+	}{
+		{session: inherited, want: agentRuntime.Spec.Ports},
+		{session: overridden, want: overridden.Spec.Ports},
+	} {
+		_, got := reconcileAgentSession(t, cli, tc.session)
+		if !reflect.DeepEqual(got.Status.Ports, tc.want) {
+			t.Fatalf("%s: status ports = %+v, want %+v", tc.session.Name, got.Status.Ports, tc.want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentlandv1alpha1 "github.com/Fl0rencess720/agentland/api/v1alpha1"
+	commonutils "github.com/Fl0rencess720/agentland/pkg/common/utils"
+)
+
+func newAgentSessionTimeoutFixture(t *testing.T, created time.Time, annotations map[string]string) (client.Client, *agentlandv1alpha1.AgentSession) {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add corev1 scheme: %v", err)
+	}
+	if err := agentlandv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add agentland scheme: %v", err)
+	}
+
+	session := &agentlandv1alpha1.AgentSession{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "session-1",
+			Namespace:         "agentland-sandboxes",
+			UID:               types.UID("session-uid"),
+			CreationTimestamp: metav1.NewTime(created),
+			Annotations:       annotations,
+		},
+		Spec: agentlandv1alpha1.AgentSessionSpec{
+			Template:           &agentlandv1alpha1.SandboxTemplate{Image: "agent:test"},
+			SessionTimeout:     &metav1.Duration{Duration: 15 * time.Minute},
+			MaxSessionDuration: &metav1.Duration{Duration: 2 * time.Hour},
+		},
+	}
+	sandbox := &agentlandv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      session.Name,
+			Namespace: session.Namespace,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: agentlandv1alpha1.GroupVersion.String(),
+				Kind:       "AgentSession",
+				Name:       session.Name,
+				UID:        session.UID,
+				Controller: boolPtr(true),
+			}},
+		},
+		Spec: agentlandv1alpha1.SandboxSpec{Template: session.Spec.Template.DeepCopy()},
+	}
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(session.DeepCopy(), sandbox).
+		WithStatusSubresource(&agentlandv1alpha1.AgentSession{}).
+		Build()
+	return cli, session
+}
+
+func reconcileAgentSession(t *testing.T, cli client.Client, session *agentlandv1alpha1.AgentSession) (ctrl.Result, *agentlandv1alpha1.AgentSession) {
+	t.Helper()
+
+	r := &AgentSessionReconciler{Client: cli, Scheme: cli.Scheme()}
+	key := client.ObjectKeyFromObject(session)
+	res, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	got := &agentlandv1alpha1.AgentSession{}
+	if err := cli.Get(context.Background(), key, got); err != nil {
+		t.Fatalf("get agent session: %v", err)
+	}
+	return res, got
+}
+
+func assertSessionExpired(t *testing.T, cli client.Client, got *agentlandv1alpha1.AgentSession, reason string) {
+	t.Helper()
+
+	if got.Status.Phase != agentSessionPhaseExpired {
+		t.Fatalf("phase = %q, want %q", got.Status.Phase, agentSessionPhaseExpired)
+	}
+	if len(got.Status.Conditions) == 0 || got.Status.Conditions[0].Reason != reason {
+		t.Fatalf("expired condition reason mismatch: %+v", got.Status.Conditions)
+	}
+	err := cli.Get(context.Background(), client.ObjectKeyFromObject(got), &agentlandv1alpha1.Sandbox{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("owned sandbox must be deleted, got err=%v", err)
+	}
+}
+
+func TestAgentSessionExpiresAfterIdleTimeout(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	cli, session := newAgentSessionTimeoutFixture(t, now.Add(-time.Hour), map[string]string{
+		commonutils.LastActivityAnnotation: now.Add(-20 * time.Minute).UTC().Format(time.RFC3339Nano),
+	})
+
+	_, got := reconcileAgentSession(t, cli, session)
+	assertSessionExpired(t, cli, got, sessionExpiredReasonIdle)
+
+	// 过期后不会重新创建沙箱
+	_, got = reconcileAgentSession(t, cli, got)
+	assertSessionExpired(t, cli, got, sessionExpiredReasonIdle)
+}
+
+func TestAgentSessionExpiresAfterMaxDuration(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	cli, session := newAgentSessionTimeoutFixture(t, now.Add(-3*time.Hour), map[string]string{
+		commonutils.LastActivityAnnotation: now.Add(-time.Minute).UTC().Format(time.RFC3339Nano),
+	})
+
+	_, got := reconcileAgentSession(t, cli, session)
+	assertSessionExpired(t, cli, got, sessionExpiredReasonMaxDuration)
+}
+
+func TestAgentSessionRequeuesOnNearestDeadline(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	cli, session := newAgentSessionTimeoutFixture(t, now.Add(-time.Hour), map[string]string{
+		commonutils.LastActivityAnnotation: now.Add(-10 * time.Minute).UTC().Format(time.RFC3339Nano),
+	})
+
+	res, got := reconcileAgentSession(t, cli, session)
+	if got.Status.Phase == agentSessionPhaseExpired {
+		t.Fatalf("active session must not expire")
+	}
+	if res.RequeueAfter <= 0 || res.RequeueAfter > 5*time.Minute {
+		t.Fatalf("RequeueAfter = %s, want within idle deadline (<= 5m)", res.RequeueAfter)
+	}
+}
+
+func TestSessionExpiryFallsBackToCreationTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	session := &agentlandv1alpha1.AgentSession{
+		ObjectMeta: metav1.ObjectMeta{
+			CreationTimestamp: metav1.NewTime(now.Add(-20 * time.Minute)),
+			Annotations:       map[string]string{commonutils.LastActivityAnnotation: "not-a-time"},
+		},
+		Spec: agentlandv1alpha1.AgentSessionSpec{
+			SessionTimeout: &metav1.Duration{Duration: 15 * time.Minute},
+		},
+	}
+	if reason, _ := sessionExpiry(session, now); reason != sessionExpiredReasonIdle {
+		t.Fatalf("reason = %q, want %q", reason, sessionExpiredReasonIdle)
+	}
+
+	session.Spec.SessionTimeout = nil
+	if reason, until := sessionExpiry(session, now); reason != "" || until != 0 {
+		t.Fatalf("no timeouts configured: got (%q, %s)", reason, until)
+	}
+}
@@ -43,6 +43,8 @@ type CodeInterpreterReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 	Tracer trace.Tracer
+	// SessionStore 用于删除时清理会话存储，为 nil 时跳过
+	SessionStore SessionStore
 }
 
 func (r *CodeInterpreterReconciler) startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
@@ -82,12 +84,21 @@ func (r *CodeInterpreterReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	r.setBaseAttributes(span, ctx, ci)
 
 	if !ci.DeletionTimestamp.IsZero() {
-		if !controllerutil.ContainsFinalizer(ci, "codeinterpreter.finalizers.agentland.fl0rencess720.app") {
-			log.Info("CodeInterpreter is being deleted")
-			return ctrl.Result{}, nil
-		}
 		log.Info("CodeInterpreter is being deleted")
-		return ctrl.Result{}, nil
+		res, err := finalizeSession(ctx, r.Client, r.SessionStore, ci, codeInterpreterFinalizer)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "finalize code interpreter failed")
+		}
+		return res, err
+	}
+	if _, err := ensureFinalizer(ctx, r.Client, ci, codeInterpreterFinalizer); err != nil {
+		if errors.IsConflict(err) {
+			return ctrl.Result{RequeueAfter: commonutils.ConflictRequeueInterval}, nil
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "add finalizer failed")
+		return ctrl.Result{}, err
 	}
 
 	mode := agentlandv1alpha1.ProvisioningModeDirect
@@ -123,6 +134,7 @@ func (r *CodeInterpreterReconciler) reconcileDirect(ctx context.Context, ci *age
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      ci.Name,
 				Namespace: ci.Namespace,
+				Labels:    ownerLabels(ci),
 				Annotations: observability.PropagateTraceAnnotations(
 					nil,
 					ci.Annotations,
@@ -186,6 +198,7 @@ func (r *CodeInterpreterReconciler) reconcileViaClaim(ctx context.Context, ci *a
 			ObjectMeta: metav1.ObjectMeta{
 				Name:        ci.Name,
 				Namespace:   ci.Namespace,
+				Labels:      ownerLabels(ci),
 				Annotations: observability.PropagateTraceAnnotations(nil, ci.Annotations),
 			},
 			Spec: agentlandv1alpha1.SandboxClaimSpec{
@@ -298,5 +311,5 @@ func (r *CodeInterpreterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&agentlandv1alpha1.SandboxClaim{}).
 		Owns(&agentlandv1alpha1.Sandbox{}).
 		Named("codeinterpreter").
-		Complete(r)
+		Complete(instrumentReconciler("codeinterpreter", r, defaultControllerMetrics()))
 }
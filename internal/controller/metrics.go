@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const controllerMeterName = "agentland.controller"
+
+// ControllerMetrics 为控制器的 OTel 指标，nil 时不记录
+type ControllerMetrics struct {
+	reconciles        metric.Int64Counter
+	reconcileDuration metric.Float64Histogram
+	warmPodSelections metric.Int64Counter
+	coldStarts        metric.Int64Counter
+}
+
+// NewControllerMetrics 在 meter 上创建控制器指标
+func NewControllerMetrics(meter metric.Meter) (*ControllerMetrics, error) {
+	reconciles, err := meter.Int64Counter("agentland.controller.reconciles",
+		metric.WithDescription("Reconcile invocations by controller and result."))
+	if err != nil {
+		return nil, err
+	}
+	reconcileDuration, err := meter.Float64Histogram("agentland.controller.reconcile.duration",
+		metric.WithDescription("Reconcile duration by controller."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10))
+	if err != nil {
+		return nil, err
+	}
+	warmPodSelections, err := meter.Int64Counter("agentland.sandboxclaim.warm_pod.selections",
+		metric.WithDescription("Warm pod lookups for sandbox claims by profile, pool and result (hit or miss)."))
+	if err != nil {
+		return nil, err
+	}
+	coldStarts, err := meter.Int64Counter("agentland.sandboxclaim.cold_starts",
+		metric.WithDescription("Sandbox claims provisioned without a warm pod by profile and pool."))
+	if err != nil {
+		return nil, err
+	}
+	return &ControllerMetrics{
+		reconciles:        reconciles,
+		reconcileDuration: reconcileDuration,
+		warmPodSelections: warmPodSelections,
+		coldStarts:        coldStarts,
+	}, nil
+}
+
+// defaultControllerMetrics 基于全局 MeterProvider 创建，manager 启动前设置的 MeterProvider 同样生效
+var defaultControllerMetrics = sync.OnceValue(func() *ControllerMetrics {
+	m, err := NewControllerMetrics(otel.Meter(controllerMeterName))
+	if err != nil {
+		otel.Handle(err)
+		return nil
+	}
+	return m
+})
+
+func (m *ControllerMetrics) observeReconcile(ctx context.Context, controller string, start time.Time, err error) {
+	if m == nil {
+		return
+	}
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	m.reconciles.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("controller", controller),
+		attribute.String("result", result),
+	))
+	m.reconcileDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+		attribute.String("controller", controller),
+	))
+}
+
+func (m *ControllerMetrics) recordWarmPodSelection(ctx context.Context, profile, pool string, hit bool) {
+	if m == nil {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.warmPodSelections.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("profile", profile),
+		attribute.String("pool", pool),
+		attribute.String("result", result),
+	))
+}
+
+func (m *ControllerMetrics) recordColdStart(ctx context.Context, profile, pool string) {
+	if m == nil {
+		return
+	}
+	m.coldStarts.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("profile", profile),
+		attribute.String("pool", pool),
+	))
+}
+
+// instrumentReconciler 包装 Reconciler，记录每次 Reconcile 的次数、结果与耗时
+func instrumentReconciler(controller string, inner reconcile.Reconciler, m *ControllerMetrics) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+		start := time.Now()
+		result, err := inner.Reconcile(ctx, req)
+		m.observeReconcile(ctx, controller, start, err)
+		return result, err
+	})
+}
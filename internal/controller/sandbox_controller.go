@@ -7,6 +7,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -32,6 +33,10 @@ type SandboxReconciler struct {
 	Scheme          *runtime.Scheme
 	Tracer          trace.Tracer
 	ImagePullPolicy corev1.PullPolicy
+	// DefaultSecurityContext 为沙箱容器的默认安全上下文，模板中设置的字段优先；nil 表示不注入
+	DefaultSecurityContext *corev1.SecurityContext
+	// PodMetadata 为需要从 Sandbox 透传到 Pod 的标签与注解白名单
+	PodMetadata SandboxPodMetadata
 }
 
 func (r *SandboxReconciler) startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
@@ -45,6 +50,7 @@ func (r *SandboxReconciler) startSpan(ctx context.Context, name string) (context
 //+kubebuilder:rbac:groups=agentland.fl0rencess720.app,resources=sandboxes,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=agentland.fl0rencess720.app,resources=sandboxes/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create
 
 func (r *SandboxReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
@@ -76,6 +82,22 @@ func (r *SandboxReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 
 	sandbox.Status.Phase, sandbox.Status.PodIP = sandboxStatusFromPod(pod)
+	readyCondition := metav1.Condition{
+		Type:               sandboxConditionReady,
+		Status:             metav1.ConditionFalse,
+		Reason:             "PodPending",
+		Message:            "waiting for sandbox pod to become ready",
+		ObservedGeneration: sandbox.Generation,
+	}
+	if reason, message := sandboxFailureFromPod(pod); reason != "" {
+		sandbox.Status.Phase, sandbox.Status.PodIP = string(corev1.PodFailed), ""
+		readyCondition.Reason, readyCondition.Message = reason, message
+		span.SetAttributes(attribute.String("sandbox.failure_reason", reason))
+	} else if sandbox.Status.Phase == string(corev1.PodRunning) {
+		readyCondition.Status = metav1.ConditionTrue
+		readyCondition.Reason, readyCondition.Message = "PodReady", "sandbox pod is ready"
+	}
+	meta.SetStatusCondition(&sandbox.Status.Conditions, readyCondition)
 
 	if !equality.Semantic.DeepEqual(oldStatus, &sandbox.Status) {
 		if err := r.Status().Update(ctx, sandbox); err != nil {
@@ -98,6 +120,32 @@ func (r *SandboxReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	return ctrl.Result{}, nil
 }
 
+// sandboxFailureFromPod 检查 Pod 是否已失败或有容器卡在不会自行恢复的等待状态（如 CrashLoopBackOff），
+// 返回原因与说明；Pod 的 phase 在这些情况下仍为 Pending/Running，单看 phase 只会让调用方等到超时。
+func sandboxFailureFromPod(pod *corev1.Pod) (reason string, message string) {
+	if pod == nil {
+		return "", ""
+	}
+	if pod.Status.Phase == corev1.PodFailed {
+		reason = pod.Status.Reason
+		if reason == "" {
+			reason = "PodFailed"
+		}
+		return reason, pod.Status.Message
+	}
+	statuses := append(append([]corev1.ContainerStatus(nil), pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		waiting := cs.State.Waiting
+		if waiting == nil {
+			continue
+		}
+		if _, failed := sandboxFailureWaitingReasons[waiting.Reason]; failed {
+			return waiting.Reason, fmt.Sprintf("container %s: %s", cs.Name, waiting.Message)
+		}
+	}
+	return "", ""
+}
+
 func sandboxStatusFromPod(pod *corev1.Pod) (phase string, podIP string) {
 	if pod == nil {
 		return string(corev1.PodPending), ""
@@ -123,6 +171,7 @@ func (r *SandboxReconciler) reconcilePod(ctx context.Context, sandbox *agentland
 				adopted.Labels = map[string]string{}
 			}
 			adopted.Labels[commonutils.SandboxLabel] = commonutils.NameHash(sandbox.Name)
+			applyPodMetadata(adopted, sandbox, r.PodMetadata)
 			if controllerRef := metav1.GetControllerOf(adopted); controllerRef == nil {
 				if err := controllerutil.SetControllerReference(sandbox, adopted, r.Scheme); err != nil {
 					span.RecordError(err)
@@ -191,7 +240,7 @@ func (r *SandboxReconciler) reconcilePod(ctx context.Context, sandbox *agentland
 				Args:            sandbox.Spec.Template.Args,
 				VolumeMounts: []corev1.VolumeMount{{
 					Name:      sandboxJWTVolumeName,
-					MountPath: "/var/run/agentland/jwt",
+					MountPath: sandboxJWTMountPath,
 					ReadOnly:  true,
 				}, {
 					Name:      workspaceVolumeName,
@@ -213,10 +262,25 @@ func (r *SandboxReconciler) reconcilePod(ctx context.Context, sandbox *agentland
 			}},
 		},
 	}
-	if sandbox.Spec.Template.RuntimeClassName != "" {
-		runtimeClassName := sandbox.Spec.Template.RuntimeClassName
-		pod.Spec.RuntimeClassName = &runtimeClassName
+	applyTemplateContainerFields(&pod.Spec.Containers[0], sandbox.Spec.Template)
+	applySecurityDefaults(&pod.Spec.Containers[0], r.DefaultSecurityContext)
+	if err := applyTemplateVolumes(&pod.Spec, sandbox.Spec.Template); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "apply template volumes failed")
+		return nil, err
+	}
+	if workspacePersistenceEnabled(sandbox.Spec.Template) {
+		claimName, err := r.ensureWorkspaceClaim(ctx, sandbox)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "ensure workspace claim failed")
+			return nil, err
+		}
+		useWorkspaceClaim(&pod.Spec, claimName)
+		span.SetAttributes(attribute.String("workspace.claim", claimName))
 	}
+	applyTemplatePodFields(&pod.Spec, sandbox.Spec.Template)
+	applyPodMetadata(pod, sandbox, r.PodMetadata)
 
 	if err := controllerutil.SetControllerReference(sandbox, pod, r.Scheme); err != nil {
 		span.RecordError(err)
@@ -265,5 +329,5 @@ func (r *SandboxReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&agentlandv1alpha1.Sandbox{}).
 		Owns(&corev1.Pod{}, builder.WithPredicates(podLabelPredicate)).
 		Named("sandbox").
-		Complete(r)
+		Complete(instrumentReconciler("sandbox", r, defaultControllerMetrics()))
 }
@@ -1,9 +1,23 @@
 package controller
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	agentlandv1alpha1 "github.com/Fl0rencess720/agentland/api/v1alpha1"
+	commonutils "github.com/Fl0rencess720/agentland/pkg/common/utils"
 )
 
 func TestSandboxStatusFromPod(t *testing.T) {
@@ -59,3 +73,586 @@ func TestSandboxStatusFromPod(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcilePodAppliesTemplateContainerFields(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add corev1 scheme: %v", err)
+	}
+	if err := agentlandv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add agentland scheme: %v", err)
+	}
+
+	runAsNonRoot := true
+	template := &agentlandv1alpha1.SandboxTemplate{
+		Image:      "korokd:test",
+		Env:        []corev1.EnvVar{{Name: "FOO", Value: "bar"}},
+		WorkingDir: "/workspace/app",
+		Resources: &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("250m"),
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
+		SecurityContext: &corev1.SecurityContext{RunAsNonRoot: &runAsNonRoot},
+	}
+	sandbox := &agentlandv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "session-1",
+			Namespace: "agentland-sandboxes",
+			UID:       types.UID("sandbox-uid"),
+		},
+		Spec: agentlandv1alpha1.SandboxSpec{Template: template},
+	}
+	pool := &agentlandv1alpha1.SandboxPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pool-a",
+			Namespace: "agentland-sandboxes",
+			UID:       types.UID("pool-uid"),
+		},
+		Spec: agentlandv1alpha1.SandboxPoolSpec{Template: template.DeepCopy()},
+	}
+
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sandbox.DeepCopy()).Build()
+	if _, err := (&SandboxReconciler{Client: cli, Scheme: scheme}).reconcilePod(context.Background(), sandbox); err != nil {
+		t.Fatalf("reconcilePod: %v", err)
+	}
+	if err := (&SandboxPoolReconciler{Client: cli, Scheme: scheme}).createPoolPod(context.Background(), pool); err != nil {
+		t.Fatalf("createPoolPod: %v", err)
+	}
+
+	pods := &corev1.PodList{}
+	if err := cli.List(context.Background(), pods); err != nil {
+		t.Fatalf("list pods: %v", err)
+	}
+	if len(pods.Items) != 2 {
+		t.Fatalf("expected 2 pods, got %d", len(pods.Items))
+	}
+	for _, pod := range pods.Items {
+		container := pod.Spec.Containers[0]
+		if got := container.Resources.Limits.Cpu().String(); got != "1" {
+			t.Fatalf("pod %s cpu limit = %q, want 1", pod.Name, got)
+		}
+		if got := container.Resources.Limits.Memory().String(); got != "1Gi" {
+			t.Fatalf("pod %s memory limit = %q, want 1Gi", pod.Name, got)
+		}
+		if got := container.Resources.Requests.Cpu().String(); got != "250m" {
+			t.Fatalf("pod %s cpu request = %q, want 250m", pod.Name, got)
+		}
+		if len(container.Env) != 1 || container.Env[0].Name != "FOO" || container.Env[0].Value != "bar" {
+			t.Fatalf("pod %s env mismatch: %+v", pod.Name, container.Env)
+		}
+		if container.WorkingDir != "/workspace/app" {
+			t.Fatalf("pod %s workingDir = %q", pod.Name, container.WorkingDir)
+		}
+		if container.SecurityContext == nil || container.SecurityContext.RunAsNonRoot == nil || !*container.SecurityContext.RunAsNonRoot {
+			t.Fatalf("pod %s securityContext not applied", pod.Name)
+		}
+	}
+}
+
+func TestApplyTemplateContainerFieldsProbes(t *testing.T) {
+	t.Parallel()
+
+	container := corev1.Container{}
+	applyTemplateContainerFields(&container, &agentlandv1alpha1.SandboxTemplate{Image: "korokd:test"})
+	if container.ReadinessProbe == nil || container.ReadinessProbe.TCPSocket == nil ||
+		container.ReadinessProbe.TCPSocket.Port.IntValue() != korokdPort {
+		t.Fatalf("default readiness probe must dial korokd port, got %+v", container.ReadinessProbe)
+	}
+	if container.LivenessProbe == nil || container.LivenessProbe.TCPSocket == nil {
+		t.Fatalf("default liveness probe missing")
+	}
+
+	override := &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{Path: "/readyz", Port: intstr.FromInt32(korokdPort)},
+		},
+	}
+	container = corev1.Container{}
+	applyTemplateContainerFields(&container, &agentlandv1alpha1.SandboxTemplate{Image: "korokd:test", ReadinessProbe: override})
+	if container.ReadinessProbe.HTTPGet == nil || container.ReadinessProbe.HTTPGet.Path != "/readyz" {
+		t.Fatalf("readiness probe override not applied: %+v", container.ReadinessProbe)
+	}
+	if container.ReadinessProbe == override {
+		t.Fatalf("readiness probe must be copied from template")
+	}
+}
+
+func TestReconcilePodMountsTemplateVolumes(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add corev1 scheme: %v", err)
+	}
+	if err := agentlandv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add agentland scheme: %v", err)
+	}
+
+	newSandbox := func(name string, mountPath string) *agentlandv1alpha1.Sandbox {
+		return &agentlandv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "agentland-sandboxes", UID: types.UID(name + "-uid")},
+			Spec: agentlandv1alpha1.SandboxSpec{Template: &agentlandv1alpha1.SandboxTemplate{
+				Image: "korokd:test",
+				Volumes: []corev1.Volume{{
+					Name: "pip-config",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "pip-conf"},
+						},
+					},
+				}},
+				VolumeMounts: []corev1.VolumeMount{{Name: "pip-config", MountPath: mountPath, ReadOnly: true}},
+			}},
+		}
+	}
+
+	sandbox := newSandbox("session-volumes", "/etc/pip")
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sandbox.DeepCopy()).Build()
+	pod, err := (&SandboxReconciler{Client: cli, Scheme: scheme}).reconcilePod(context.Background(), sandbox)
+	if err != nil {
+		t.Fatalf("reconcilePod: %v", err)
+	}
+
+	var volume *corev1.Volume
+	for i := range pod.Spec.Volumes {
+		if pod.Spec.Volumes[i].Name == "pip-config" {
+			volume = &pod.Spec.Volumes[i]
+		}
+	}
+	if volume == nil || volume.ConfigMap == nil || volume.ConfigMap.Name != "pip-conf" {
+		t.Fatalf("configmap volume not added to pod: %+v", pod.Spec.Volumes)
+	}
+	if len(pod.Spec.Volumes) != 3 {
+		t.Fatalf("expected built-in volumes to be kept, got %+v", pod.Spec.Volumes)
+	}
+	mounts := pod.Spec.Containers[0].VolumeMounts
+	if len(mounts) != 3 || mounts[2].Name != "pip-config" || mounts[2].MountPath != "/etc/pip" || !mounts[2].ReadOnly {
+		t.Fatalf("configmap mount not added to container: %+v", mounts)
+	}
+
+	for _, reserved := range []string{"/workspace", "/workspace/", "/var/run/agentland/jwt/key"} {
+		sandbox := newSandbox("session-reserved", reserved)
+		cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sandbox.DeepCopy()).Build()
+		if _, err := (&SandboxReconciler{Client: cli, Scheme: scheme}).reconcilePod(context.Background(), sandbox); err == nil {
+			t.Fatalf("mount on reserved path %q must be rejected", reserved)
+		}
+	}
+
+	sandbox = newSandbox("session-dup", "/etc/pip")
+	sandbox.Spec.Template.Volumes[0].Name = workspaceVolumeName
+	sandbox.Spec.Template.VolumeMounts[0].Name = workspaceVolumeName
+	cli = fake.NewClientBuilder().WithScheme(scheme).WithObjects(sandbox.DeepCopy()).Build()
+	if _, err := (&SandboxReconciler{Client: cli, Scheme: scheme}).reconcilePod(context.Background(), sandbox); err == nil {
+		t.Fatalf("volume named %q must be rejected", workspaceVolumeName)
+	}
+}
+
+func TestReconcilePodUsesWorkspaceClaim(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add corev1 scheme: %v", err)
+	}
+	if err := agentlandv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add agentland scheme: %v", err)
+	}
+
+	session := &agentlandv1alpha1.CodeInterpreter{
+		ObjectMeta: metav1.ObjectMeta{Name: "session-pvc", Namespace: "agentland-sandboxes", UID: types.UID("ci-uid")},
+	}
+	newSandbox := func(uid string, scope agentlandv1alpha1.WorkspacePersistenceScope) *agentlandv1alpha1.Sandbox {
+		sandbox := &agentlandv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      session.Name,
+				Namespace: session.Namespace,
+				UID:       types.UID(uid),
+				Labels:    map[string]string{commonutils.OwnerLabel: "alice"},
+			},
+			Spec: agentlandv1alpha1.SandboxSpec{Template: &agentlandv1alpha1.SandboxTemplate{
+				Image:                "korokd:test",
+				WorkspacePersistence: &agentlandv1alpha1.WorkspacePersistence{Enabled: true, Scope: scope},
+			}},
+		}
+		if err := controllerutil.SetControllerReference(session, sandbox, scheme); err != nil {
+			t.Fatalf("set owner: %v", err)
+		}
+		return sandbox
+	}
+	workspaceClaimOf := func(pod *corev1.Pod) string {
+		for _, v := range pod.Spec.Volumes {
+			if v.Name == workspaceVolumeName {
+				if v.PersistentVolumeClaim == nil {
+					t.Fatalf("workspace volume is not backed by a claim: %+v", v)
+				}
+				return v.PersistentVolumeClaim.ClaimName
+			}
+		}
+		t.Fatalf("workspace volume missing: %+v", pod.Spec.Volumes)
+		return ""
+	}
+
+	sandbox := newSandbox("sandbox-uid-1", "")
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sandbox.DeepCopy()).Build()
+	reconciler := &SandboxReconciler{Client: cli, Scheme: scheme}
+	pod, err := reconciler.reconcilePod(context.Background(), sandbox)
+	if err != nil {
+		t.Fatalf("reconcilePod: %v", err)
+	}
+	if got := workspaceClaimOf(pod); got != "workspace-session-pvc" {
+		t.Fatalf("workspace claim = %q, want workspace-session-pvc", got)
+	}
+	claim := &corev1.PersistentVolumeClaim{}
+	if err := cli.Get(context.Background(), types.NamespacedName{Name: "workspace-session-pvc", Namespace: session.Namespace}, claim); err != nil {
+		t.Fatalf("get workspace claim: %v", err)
+	}
+	if got := claim.Spec.Resources.Requests.Storage().String(); got != "1Gi" {
+		t.Fatalf("claim size = %q, want 1Gi", got)
+	}
+	if len(claim.OwnerReferences) != 1 || claim.OwnerReferences[0].UID != session.UID {
+		t.Fatalf("session claim must be owned by the session, got %+v", claim.OwnerReferences)
+	}
+
+	// 沙箱删除后以同名重建，仍复用原 PVC
+	if err := cli.Delete(context.Background(), pod); err != nil {
+		t.Fatalf("delete pod: %v", err)
+	}
+	recreated := newSandbox("sandbox-uid-2", agentlandv1alpha1.WorkspacePersistenceScopeSession)
+	pod, err = reconciler.reconcilePod(context.Background(), recreated)
+	if err != nil {
+		t.Fatalf("reconcilePod after recreation: %v", err)
+	}
+	if got := workspaceClaimOf(pod); got != "workspace-session-pvc" {
+		t.Fatalf("recreated sandbox claim = %q, want workspace-session-pvc", got)
+	}
+	claims := &corev1.PersistentVolumeClaimList{}
+	if err := cli.List(context.Background(), claims); err != nil {
+		t.Fatalf("list claims: %v", err)
+	}
+	if len(claims.Items) != 1 {
+		t.Fatalf("expected the claim to be reused, got %d claims", len(claims.Items))
+	}
+
+	ownerSandbox := newSandbox("sandbox-uid-3", agentlandv1alpha1.WorkspacePersistenceScopeOwner)
+	ownerSandbox.Name = "session-owner"
+	cli = fake.NewClientBuilder().WithScheme(scheme).WithObjects(ownerSandbox.DeepCopy()).Build()
+	pod, err = (&SandboxReconciler{Client: cli, Scheme: scheme}).reconcilePod(context.Background(), ownerSandbox)
+	if err != nil {
+		t.Fatalf("reconcilePod owner scope: %v", err)
+	}
+	wantOwnerClaim := "workspace-owner-" + commonutils.NameHash("alice")
+	if got := workspaceClaimOf(pod); got != wantOwnerClaim {
+		t.Fatalf("owner claim = %q, want %q", got, wantOwnerClaim)
+	}
+	claim = &corev1.PersistentVolumeClaim{}
+	if err := cli.Get(context.Background(), types.NamespacedName{Name: wantOwnerClaim, Namespace: session.Namespace}, claim); err != nil {
+		t.Fatalf("get owner claim: %v", err)
+	}
+	if len(claim.OwnerReferences) != 0 {
+		t.Fatalf("owner claim must outlive sessions, got owner references %+v", claim.OwnerReferences)
+	}
+}
+
+func TestSandboxPodsApplySchedulingFields(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add corev1 scheme: %v", err)
+	}
+	if err := agentlandv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add agentland scheme: %v", err)
+	}
+
+	template := &agentlandv1alpha1.SandboxTemplate{
+		Image:            "korokd:test",
+		RuntimeClassName: "gvisor",
+		NodeSelector:     map[string]string{"agentland.io/sandbox": "true"},
+		Tolerations: []corev1.Toleration{{
+			Key:      "nvidia.com/gpu",
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		}},
+		Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{
+						Key:      "kubernetes.io/arch",
+						Operator: corev1.NodeSelectorOpIn,
+						Values:   []string{"amd64"},
+					}},
+				}},
+			},
+		}},
+	}
+	sandbox := &agentlandv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "session-gpu", Namespace: "agentland-sandboxes", UID: types.UID("sandbox-uid")},
+		Spec:       agentlandv1alpha1.SandboxSpec{Template: template},
+	}
+	pool := &agentlandv1alpha1.SandboxPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool-gpu", Namespace: "agentland-sandboxes", UID: types.UID("pool-uid")},
+		Spec:       agentlandv1alpha1.SandboxPoolSpec{Template: template.DeepCopy()},
+	}
+
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sandbox.DeepCopy()).Build()
+	if _, err := (&SandboxReconciler{Client: cli, Scheme: scheme}).reconcilePod(context.Background(), sandbox); err != nil {
+		t.Fatalf("reconcilePod: %v", err)
+	}
+	if err := (&SandboxPoolReconciler{Client: cli, Scheme: scheme}).createPoolPod(context.Background(), pool); err != nil {
+		t.Fatalf("createPoolPod: %v", err)
+	}
+
+	pods := &corev1.PodList{}
+	if err := cli.List(context.Background(), pods); err != nil {
+		t.Fatalf("list pods: %v", err)
+	}
+	if len(pods.Items) != 2 {
+		t.Fatalf("expected 2 pods, got %d", len(pods.Items))
+	}
+	for _, pod := range pods.Items {
+		if pod.Spec.RuntimeClassName == nil || *pod.Spec.RuntimeClassName != "gvisor" {
+			t.Fatalf("pod %s runtimeClassName = %v, want gvisor", pod.Name, pod.Spec.RuntimeClassName)
+		}
+		if len(pod.Spec.Tolerations) != 1 || pod.Spec.Tolerations[0].Key != "nvidia.com/gpu" ||
+			pod.Spec.Tolerations[0].Effect != corev1.TaintEffectNoSchedule {
+			t.Fatalf("pod %s tolerations = %+v", pod.Name, pod.Spec.Tolerations)
+		}
+		if pod.Spec.NodeSelector["agentland.io/sandbox"] != "true" {
+			t.Fatalf("pod %s nodeSelector = %+v", pod.Name, pod.Spec.NodeSelector)
+		}
+		if pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil {
+			t.Fatalf("pod %s affinity not applied", pod.Name)
+		}
+	}
+}
+
+func TestReconcilePodAppliesSecurityDefaults(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add corev1 scheme: %v", err)
+	}
+	if err := agentlandv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add agentland scheme: %v", err)
+	}
+
+	defaults := SandboxSecurityDefaults{
+		Enabled:                true,
+		RunAsNonRoot:           true,
+		RunAsUser:              1000,
+		ReadOnlyRootFilesystem: true,
+		DropAllCapabilities:    true,
+		SeccompRuntimeDefault:  true,
+	}.SecurityContext()
+	if (SandboxSecurityDefaults{RunAsNonRoot: true}).SecurityContext() != nil {
+		t.Fatalf("disabled defaults must not produce a security context")
+	}
+
+	reconcile := func(name string, override *corev1.SecurityContext) *corev1.SecurityContext {
+		sandbox := &agentlandv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "agentland-sandboxes", UID: types.UID(name + "-uid")},
+			Spec: agentlandv1alpha1.SandboxSpec{Template: &agentlandv1alpha1.SandboxTemplate{
+				Image:           "korokd:test",
+				SecurityContext: override,
+			}},
+		}
+		cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sandbox.DeepCopy()).Build()
+		reconciler := &SandboxReconciler{Client: cli, Scheme: scheme, DefaultSecurityContext: defaults}
+		pod, err := reconciler.reconcilePod(context.Background(), sandbox)
+		if err != nil {
+			t.Fatalf("reconcilePod: %v", err)
+		}
+		return pod.Spec.Containers[0].SecurityContext
+	}
+
+	sc := reconcile("session-hardened", nil)
+	if sc == nil || sc.RunAsNonRoot == nil || !*sc.RunAsNonRoot || sc.RunAsUser == nil || *sc.RunAsUser != 1000 {
+		t.Fatalf("non-root defaults not applied: %+v", sc)
+	}
+	if sc.ReadOnlyRootFilesystem == nil || !*sc.ReadOnlyRootFilesystem {
+		t.Fatalf("read-only root filesystem not applied: %+v", sc)
+	}
+	if sc.Capabilities == nil || len(sc.Capabilities.Drop) != 1 || sc.Capabilities.Drop[0] != "ALL" ||
+		sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+		t.Fatalf("capabilities not dropped: %+v", sc)
+	}
+	if sc.SeccompProfile == nil || sc.SeccompProfile.Type != corev1.SeccompProfileTypeRuntimeDefault {
+		t.Fatalf("seccomp profile not applied: %+v", sc.SeccompProfile)
+	}
+
+	writable := false
+	sc = reconcile("session-override", &corev1.SecurityContext{ReadOnlyRootFilesystem: &writable})
+	if sc.ReadOnlyRootFilesystem == nil || *sc.ReadOnlyRootFilesystem {
+		t.Fatalf("template override must win: %+v", sc)
+	}
+	if sc.RunAsNonRoot == nil || !*sc.RunAsNonRoot || sc.Capabilities == nil {
+		t.Fatalf("fields not set by the template must keep defaults: %+v", sc)
+	}
+	if defaults.ReadOnlyRootFilesystem == nil || !*defaults.ReadOnlyRootFilesystem {
+		t.Fatalf("defaults must not be mutated by overrides")
+	}
+}
+
+func TestReconcileReportsCrashLoopBackOffAsFailed(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add corev1 scheme: %v", err)
+	}
+	if err := agentlandv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add agentland scheme: %v", err)
+	}
+
+	sandbox := &agentlandv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "session-crash", Namespace: "agentland-sandboxes", UID: types.UID("sandbox-uid")},
+		Spec:       agentlandv1alpha1.SandboxSpec{Template: &agentlandv1alpha1.SandboxTemplate{Image: "korokd:test"}},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      sandbox.Name,
+			Namespace: sandbox.Namespace,
+			Labels:    map[string]string{commonutils.SandboxLabel: commonutils.NameHash(sandbox.Name)},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "korokd:test"}}},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			PodIP: "10.0.0.12",
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "main",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+					Reason:  "CrashLoopBackOff",
+					Message: "back-off 40s restarting failed container",
+				}},
+				RestartCount: 3,
+			}},
+		},
+	}
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(sandbox, pod).
+		WithStatusSubresource(&agentlandv1alpha1.Sandbox{}, &corev1.Pod{}).
+		Build()
+	reconciler := &SandboxReconciler{Client: cli, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: sandbox.Name, Namespace: sandbox.Namespace}}
+	if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	got := &agentlandv1alpha1.Sandbox{}
+	if err := cli.Get(context.Background(), req.NamespacedName, got); err != nil {
+		t.Fatalf("get sandbox: %v", err)
+	}
+	if got.Status.Phase != string(corev1.PodFailed) || got.Status.PodIP != "" {
+		t.Fatalf("status = (%q, %q), want Failed without pod IP", got.Status.Phase, got.Status.PodIP)
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, sandboxConditionReady)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "CrashLoopBackOff" {
+		t.Fatalf("ready condition = %+v, want False/CrashLoopBackOff", cond)
+	}
+	if !strings.Contains(cond.Message, "container main") || !strings.Contains(cond.Message, "back-off") {
+		t.Fatalf("condition message must name the container and carry the kubelet message, got %q", cond.Message)
+	}
+
+	// 容器恢复后重新上报 Running
+	pod.Status.ContainerStatuses[0].State = corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	if err := cli.Status().Update(context.Background(), pod); err != nil {
+		t.Fatalf("update pod status: %v", err)
+	}
+	if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile after recovery: %v", err)
+	}
+	if err := cli.Get(context.Background(), req.NamespacedName, got); err != nil {
+		t.Fatalf("get sandbox: %v", err)
+	}
+	if got.Status.Phase != string(corev1.PodRunning) || got.Status.PodIP != "10.0.0.12" {
+		t.Fatalf("status after recovery = (%q, %q)", got.Status.Phase, got.Status.PodIP)
+	}
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, sandboxConditionReady) {
+		t.Fatalf("ready condition must be True after recovery: %+v", got.Status.Conditions)
+	}
+}
+
+func TestReconcilePodPropagatesSessionMetadata(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add corev1 scheme: %v", err)
+	}
+	if err := agentlandv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add agentland scheme: %v", err)
+	}
+
+	sandbox := &agentlandv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "session-meta",
+			Namespace: "agentland-sandboxes",
+			UID:       types.UID("sandbox-uid"),
+			Labels: map[string]string{
+				commonutils.OwnerLabel:    "alice",
+				"cost-center":             "research",
+				"internal-only":           "secret",
+				commonutils.SandboxLabel:  "spoofed",
+				commonutils.ClaimUIDLabel: "spoofed",
+			},
+			Annotations: map[string]string{
+				"example.com/ticket": "OPS-42",
+				"traceparent":        "00-abc-def-01",
+			},
+		},
+		Spec: agentlandv1alpha1.SandboxSpec{
+			Profile:  "default",
+			Template: &agentlandv1alpha1.SandboxTemplate{Image: "korokd:test"},
+		},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sandbox.DeepCopy()).Build()
+	reconciler := &SandboxReconciler{
+		Client: cli,
+		Scheme: scheme,
+		PodMetadata: SandboxPodMetadata{
+			Labels:      []string{"cost-center", commonutils.SandboxLabel},
+			Annotations: []string{"example.com/ticket"},
+		},
+	}
+	pod, err := reconciler.reconcilePod(context.Background(), sandbox)
+	if err != nil {
+		t.Fatalf("reconcilePod: %v", err)
+	}
+
+	want := map[string]string{
+		commonutils.SessionIDLabel: "session-meta",
+		commonutils.OwnerLabel:     "alice",
+		commonutils.ProfileLabel:   "default",
+		commonutils.SandboxLabel:   commonutils.NameHash("session-meta"),
+		"cost-center":              "research",
+	}
+	for key, value := range want {
+		if pod.Labels[key] != value {
+			t.Fatalf("pod label %s = %q, want %q", key, pod.Labels[key], value)
+		}
+	}
+	if _, ok := pod.Labels["internal-only"]; ok {
+		t.Fatalf("labels outside the allowlist must not be propagated")
+	}
+	if _, ok := pod.Labels[commonutils.ClaimUIDLabel]; ok {
+		t.Fatalf("internal labels must not be propagated")
+	}
+	if pod.Annotations["example.com/ticket"] != "OPS-42" {
+		t.Fatalf("allowlisted annotation missing: %v", pod.Annotations)
+	}
+	if _, ok := pod.Annotations["traceparent"]; ok {
+		t.Fatalf("annotations outside the allowlist must not be propagated")
+	}
+}
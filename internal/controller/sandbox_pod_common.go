@@ -1,7 +1,238 @@
 package controller
 
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	agentlandv1alpha1 "github.com/Fl0rencess720/agentland/api/v1alpha1"
+)
+
 const (
 	sandboxJWTVolumeName = "sandbox-jwt-public-key"
 	workspaceVolumeName  = "workspace"
 	workspaceMountPath   = "/workspace"
+	sandboxJWTMountPath  = "/var/run/agentland/jwt"
+	// korokdPort 为沙箱内 korokd 的监听端口，网关通过该端口代理请求
+	korokdPort = 1883
 )
+
+// sandboxConditionReady 为 Sandbox 是否可用的 condition，失败时携带容器的等待原因
+const sandboxConditionReady = "Ready"
+
+// sandboxFailureWaitingReasons 为容器等待状态中视为沙箱启动失败的原因
+var sandboxFailureWaitingReasons = map[string]struct{}{
+	"CrashLoopBackOff":           {},
+	"ImagePullBackOff":           {},
+	"ErrImagePull":               {},
+	"InvalidImageName":           {},
+	"CreateContainerConfigError": {},
+}
+
+// applyTemplateContainerFields 将模板中的环境变量、工作目录、资源、安全上下文与探针应用到沙箱容器，
+// 未配置探针时使用默认的 korokd 端口 TCP 探针。
+func applyTemplateContainerFields(container *corev1.Container, tpl *agentlandv1alpha1.SandboxTemplate) {
+	if len(tpl.Env) > 0 {
+		container.Env = make([]corev1.EnvVar, len(tpl.Env))
+		for i := range tpl.Env {
+			tpl.Env[i].DeepCopyInto(&container.Env[i])
+		}
+	}
+	container.WorkingDir = tpl.WorkingDir
+	if tpl.Resources != nil {
+		container.Resources = *tpl.Resources.DeepCopy()
+	}
+	if tpl.SecurityContext != nil {
+		container.SecurityContext = tpl.SecurityContext.DeepCopy()
+	}
+	container.ReadinessProbe = defaultReadinessProbe()
+	if tpl.ReadinessProbe != nil {
+		container.ReadinessProbe = tpl.ReadinessProbe.DeepCopy()
+	}
+	container.LivenessProbe = defaultLivenessProbe()
+	if tpl.LivenessProbe != nil {
+		container.LivenessProbe = tpl.LivenessProbe.DeepCopy()
+	}
+}
+
+// SandboxSecurityDefaults 为沙箱容器的默认安全加固配置，由 agentcore 配置文件提供。
+type SandboxSecurityDefaults struct {
+	// Enabled 为 false 时不注入任何默认安全上下文
+	Enabled bool
+	// RunAsNonRoot 要求容器以非 root 用户运行，镜像默认用户为 root 时需同时设置 RunAsUser
+	RunAsNonRoot bool
+	// RunAsUser 为容器运行的 UID，0 表示沿用镜像设置
+	RunAsUser int64
+	// ReadOnlyRootFilesystem 将根文件系统设为只读，此时仅挂载的卷（如 /workspace）可写
+	ReadOnlyRootFilesystem bool
+	// DropAllCapabilities 丢弃全部 Linux capabilities 并禁止提权
+	DropAllCapabilities bool
+	// SeccompRuntimeDefault 使用容器运行时默认的 seccomp 配置
+	SeccompRuntimeDefault bool
+}
+
+// SecurityContext 根据配置生成默认的容器安全上下文，未启用时返回 nil。
+func (d SandboxSecurityDefaults) SecurityContext() *corev1.SecurityContext {
+	if !d.Enabled {
+		return nil
+	}
+	sc := &corev1.SecurityContext{}
+	if d.RunAsNonRoot {
+		runAsNonRoot := true
+		sc.RunAsNonRoot = &runAsNonRoot
+	}
+	if d.RunAsUser > 0 {
+		runAsUser := d.RunAsUser
+		sc.RunAsUser = &runAsUser
+	}
+	if d.ReadOnlyRootFilesystem {
+		readOnly := true
+		sc.ReadOnlyRootFilesystem = &readOnly
+	}
+	if d.DropAllCapabilities {
+		allowPrivilegeEscalation := false
+		sc.AllowPrivilegeEscalation = &allowPrivilegeEscalation
+		sc.Capabilities = &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}}
+	}
+	if d.SeccompRuntimeDefault {
+		sc.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	}
+	return sc
+}
+
+// applySecurityDefaults 以默认安全上下文为底，叠加容器上已由模板设置的字段，
+// 模板中显式设置的字段（包括显式设为 false 的字段）优先。
+func applySecurityDefaults(container *corev1.Container, defaults *corev1.SecurityContext) {
+	if defaults == nil {
+		return
+	}
+	merged := defaults.DeepCopy()
+	if override := container.SecurityContext; override != nil {
+		if override.Capabilities != nil {
+			merged.Capabilities = override.Capabilities
+		}
+		if override.Privileged != nil {
+			merged.Privileged = override.Privileged
+		}
+		if override.SELinuxOptions != nil {
+			merged.SELinuxOptions = override.SELinuxOptions
+		}
+		if override.WindowsOptions != nil {
+			merged.WindowsOptions = override.WindowsOptions
+		}
+		if override.RunAsUser != nil {
+			merged.RunAsUser = override.RunAsUser
+		}
+		if override.RunAsGroup != nil {
+			merged.RunAsGroup = override.RunAsGroup
+		}
+		if override.RunAsNonRoot != nil {
+			merged.RunAsNonRoot = override.RunAsNonRoot
+		}
+		if override.ReadOnlyRootFilesystem != nil {
+			merged.ReadOnlyRootFilesystem = override.ReadOnlyRootFilesystem
+		}
+		if override.AllowPrivilegeEscalation != nil {
+			merged.AllowPrivilegeEscalation = override.AllowPrivilegeEscalation
+		}
+		if override.ProcMount != nil {
+			merged.ProcMount = override.ProcMount
+		}
+		if override.SeccompProfile != nil {
+			merged.SeccompProfile = override.SeccompProfile
+		}
+		if override.AppArmorProfile != nil {
+			merged.AppArmorProfile = override.AppArmorProfile
+		}
+	}
+	container.SecurityContext = merged
+}
+
+// applyTemplatePodFields 将模板中的运行时类与调度约束（nodeSelector、tolerations、affinity）应用到沙箱 Pod。
+func applyTemplatePodFields(spec *corev1.PodSpec, tpl *agentlandv1alpha1.SandboxTemplate) {
+	if tpl.RuntimeClassName != "" {
+		runtimeClassName := tpl.RuntimeClassName
+		spec.RuntimeClassName = &runtimeClassName
+	}
+	if len(tpl.NodeSelector) > 0 {
+		spec.NodeSelector = make(map[string]string, len(tpl.NodeSelector))
+		for k, v := range tpl.NodeSelector {
+			spec.NodeSelector[k] = v
+		}
+	}
+	if len(tpl.Tolerations) > 0 {
+		spec.Tolerations = make([]corev1.Toleration, len(tpl.Tolerations))
+		for i := range tpl.Tolerations {
+			tpl.Tolerations[i].DeepCopyInto(&spec.Tolerations[i])
+		}
+	}
+	if tpl.Affinity != nil {
+		spec.Affinity = tpl.Affinity.DeepCopy()
+	}
+}
+
+// applyTemplateVolumes 将模板中声明的卷与挂载追加到沙箱 Pod，
+// 卷名与内置卷冲突、挂载到工作区或 JWT 目录（含其子路径）时返回错误。
+func applyTemplateVolumes(spec *corev1.PodSpec, tpl *agentlandv1alpha1.SandboxTemplate) error {
+	volumeNames := make(map[string]struct{}, len(spec.Volumes)+len(tpl.Volumes))
+	for _, v := range spec.Volumes {
+		volumeNames[v.Name] = struct{}{}
+	}
+	for _, v := range tpl.Volumes {
+		if _, exists := volumeNames[v.Name]; exists {
+			return fmt.Errorf("sandboxTemplate volume %q collides with an existing volume", v.Name)
+		}
+		volumeNames[v.Name] = struct{}{}
+	}
+
+	container := &spec.Containers[0]
+	for _, m := range tpl.VolumeMounts {
+		if _, exists := volumeNames[m.Name]; !exists {
+			return fmt.Errorf("sandboxTemplate volumeMount %q references an undeclared volume", m.Name)
+		}
+		mountPath := path.Clean(m.MountPath)
+		if mountPath == workspaceMountPath || mountPath == sandboxJWTMountPath ||
+			strings.HasPrefix(mountPath, sandboxJWTMountPath+"/") {
+			return fmt.Errorf("sandboxTemplate volumeMount %q uses reserved path %s", m.Name, m.MountPath)
+		}
+		for _, existing := range container.VolumeMounts {
+			if path.Clean(existing.MountPath) == mountPath {
+				return fmt.Errorf("sandboxTemplate volumeMount %q collides with mount path %s", m.Name, m.MountPath)
+			}
+		}
+		container.VolumeMounts = append(container.VolumeMounts, *m.DeepCopy())
+	}
+	for i := range tpl.Volumes {
+		spec.Volumes = append(spec.Volumes, *tpl.Volumes[i].DeepCopy())
+	}
+	return nil
+}
+
+// defaultReadinessProbe 在 korokd 端口可连接后才将 Pod 标记为 Ready，
+// 避免 Sandbox 在服务尚未监听时就上报 Running。
+func defaultReadinessProbe() *corev1.Probe {
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt32(korokdPort)},
+		},
+		PeriodSeconds:    2,
+		TimeoutSeconds:   1,
+		FailureThreshold: 3,
+	}
+}
+
+// defaultLivenessProbe 在 korokd 持续不可连接时重启容器。
+func defaultLivenessProbe() *corev1.Probe {
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt32(korokdPort)},
+		},
+		InitialDelaySeconds: 10,
+		PeriodSeconds:       10,
+		TimeoutSeconds:      1,
+		FailureThreshold:    3,
+	}
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	agentlandv1alpha1 "github.com/Fl0rencess720/agentland/api/v1alpha1"
+	commonutils "github.com/Fl0rencess720/agentland/pkg/common/utils"
+)
+
+// SandboxPodMetadata 配置需要从 Sandbox 复制到沙箱 Pod 的标签与注解键。
+// 仅复制白名单中的键，避免把 trace 上下文或其他敏感注解写到 Pod 上。
+type SandboxPodMetadata struct {
+	Labels      []string
+	Annotations []string
+}
+
+// reservedPodLabels 为控制器用于选择与认领 Pod 的内部标签，不允许被透传的值覆盖
+var reservedPodLabels = map[string]struct{}{
+	commonutils.PoolLabel:        {},
+	commonutils.ProfileHashLabel: {},
+	commonutils.SandboxLabel:     {},
+	commonutils.ClaimUIDLabel:    {},
+}
+
+// applyPodMetadata 将 owner、会话 ID、profile 以及白名单中的标签与注解从 Sandbox 写到 Pod 上，
+// 供成本归属与排障按会话检索 Pod。无法作为标签值的会话 ID 或 profile 会被跳过。
+func applyPodMetadata(pod *corev1.Pod, sandbox *agentlandv1alpha1.Sandbox, metadata SandboxPodMetadata) {
+	if pod.Labels == nil {
+		pod.Labels = map[string]string{}
+	}
+	for key, value := range ownerLabels(sandbox) {
+		pod.Labels[key] = value
+	}
+	setLabelIfValid(pod.Labels, commonutils.SessionIDLabel, sandbox.Name)
+	setLabelIfValid(pod.Labels, commonutils.ProfileLabel, sandbox.Spec.Profile)
+
+	for _, key := range metadata.Labels {
+		if _, reserved := reservedPodLabels[key]; reserved {
+			continue
+		}
+		if value, ok := sandbox.Labels[key]; ok {
+			pod.Labels[key] = value
+		}
+	}
+	for _, key := range metadata.Annotations {
+		value, ok := sandbox.Annotations[key]
+		if !ok {
+			continue
+		}
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[key] = value
+	}
+}
+
+func setLabelIfValid(labels map[string]string, key, value string) {
+	if value == "" || len(validation.IsValidLabelValue(value)) > 0 {
+		return
+	}
+	labels[key] = value
+}
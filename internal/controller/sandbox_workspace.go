@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	agentlandv1alpha1 "github.com/Fl0rencess720/agentland/api/v1alpha1"
+	commonutils "github.com/Fl0rencess720/agentland/pkg/common/utils"
+)
+
+const (
+	// defaultWorkspaceClaimSize 为未指定 size 时新建工作区 PVC 的容量
+	defaultWorkspaceClaimSize = "1Gi"
+	workspaceClaimPrefix      = "workspace-"
+	workspaceOwnerClaimPrefix = "workspace-owner-"
+)
+
+// workspacePersistenceEnabled 判断模板是否启用了持久化工作区
+func workspacePersistenceEnabled(tpl *agentlandv1alpha1.SandboxTemplate) bool {
+	return tpl != nil && tpl.WorkspacePersistence != nil && tpl.WorkspacePersistence.Enabled
+}
+
+// ownerLabels 返回对象上的 owner 标签，供创建下游资源时透传；未设置 owner 时返回 nil
+func ownerLabels(obj metav1.Object) map[string]string {
+	owner := obj.GetLabels()[commonutils.OwnerLabel]
+	if owner == "" {
+		return nil
+	}
+	return map[string]string{commonutils.OwnerLabel: owner}
+}
+
+// workspaceClaimName 返回沙箱工作区使用的 PVC 名称及其是否为 Owner 范围。会话范围按沙箱（即会话）名称命名，
+// 沙箱重建时得到同名 PVC；Owner 范围按 owner 哈希命名，沙箱缺少 owner 标签时回退为会话范围。
+func workspaceClaimName(sandbox *agentlandv1alpha1.Sandbox) (string, bool) {
+	if sandbox.Spec.Template.WorkspacePersistence.Scope == agentlandv1alpha1.WorkspacePersistenceScopeOwner {
+		if owner := sandbox.Labels[commonutils.OwnerLabel]; owner != "" {
+			return workspaceOwnerClaimPrefix + commonutils.NameHash(owner), true
+		}
+	}
+	return workspaceClaimPrefix + sandbox.Name, false
+}
+
+// ensureWorkspaceClaim 确保沙箱的工作区 PVC 存在并返回其名称，已存在时直接复用。
+// 会话范围的 PVC 挂在沙箱的上级对象（会话或 SandboxClaim）下而不是沙箱本身，
+// 因此沙箱被删除重建时数据仍然保留，会话删除后随之回收；Owner 范围的 PVC 不设置 ownerReference。
+func (r *SandboxReconciler) ensureWorkspaceClaim(ctx context.Context, sandbox *agentlandv1alpha1.Sandbox) (string, error) {
+	persistence := sandbox.Spec.Template.WorkspacePersistence
+	name, ownerScoped := workspaceClaimName(sandbox)
+
+	existing := &corev1.PersistentVolumeClaim{}
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: sandbox.Namespace}, existing)
+	if err == nil {
+		return name, nil
+	}
+	if !errors.IsNotFound(err) {
+		return "", err
+	}
+
+	size := resource.MustParse(defaultWorkspaceClaimSize)
+	if persistence.Size != nil {
+		size = persistence.Size.DeepCopy()
+	}
+	accessModes := persistence.AccessModes
+	if len(accessModes) == 0 {
+		accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	}
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: sandbox.Namespace,
+			Labels:    ownerLabels(sandbox),
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: append([]corev1.PersistentVolumeAccessMode(nil), accessModes...),
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			},
+		},
+	}
+	if persistence.StorageClassName != "" {
+		storageClassName := persistence.StorageClassName
+		claim.Spec.StorageClassName = &storageClassName
+	}
+	if !ownerScoped {
+		if controllerRef := metav1.GetControllerOf(sandbox); controllerRef != nil {
+			claim.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: controllerRef.APIVersion,
+				Kind:       controllerRef.Kind,
+				Name:       controllerRef.Name,
+				UID:        controllerRef.UID,
+			}}
+		}
+	}
+	if err := r.Create(ctx, claim); err != nil && !errors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("create workspace claim %s: %w", name, err)
+	}
+	return name, nil
+}
+
+// useWorkspaceClaim 将 Pod 的工作区卷由 emptyDir 替换为指定的 PVC
+func useWorkspaceClaim(spec *corev1.PodSpec, claimName string) {
+	for i := range spec.Volumes {
+		if spec.Volumes[i].Name == workspaceVolumeName {
+			spec.Volumes[i].VolumeSource = corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+			}
+		}
+	}
+}
@@ -2,13 +2,17 @@ package controller
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -25,11 +29,33 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	// maxWarmPodAdoptAttempts 为认领预热 Pod 时因并发冲突换 Pod 重试的最大次数
+	maxWarmPodAdoptAttempts = 3
+	// defaultColdStartTimeout 为未配置 ColdStartTimeout 时冷启动沙箱进入 Running 的最长等待时间
+	defaultColdStartTimeout = 5 * time.Minute
+
+	// sandboxClaimConditionWarmPod 记录 claim 是命中预热 Pod 还是走冷启动
+	sandboxClaimConditionWarmPod = "WarmPodAdopted"
+	claimReasonWarmPodAdopted    = "WarmPodAdopted"
+	claimReasonColdStart         = "ColdStart"
+	claimReasonColdStartTimeout  = "ColdStartTimeout"
+)
+
+// errWarmPodTaken 表示选中的预热 Pod 已被其他 claim 认领
+var errWarmPodTaken = stderrors.New("warm pod already adopted by another claim")
+
 // SandboxClaimReconciler reconciles a SandboxClaim object.
 type SandboxClaimReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 	Tracer trace.Tracer
+	// Tracker 记录预热池命中与冷启动，供 SandboxPool 自动扩缩容使用
+	Tracker *PoolClaimTracker
+	// Metrics 为空时使用基于全局 MeterProvider 的默认指标
+	Metrics *ControllerMetrics
+	// ColdStartTimeout 为冷启动沙箱进入 Running 的最长等待时间，超时后 claim 置为 Failed；为 0 时使用 defaultColdStartTimeout
+	ColdStartTimeout time.Duration
 }
 
 func (r *SandboxClaimReconciler) startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
@@ -40,6 +66,20 @@ func (r *SandboxClaimReconciler) startSpan(ctx context.Context, name string) (co
 	return tracer.Start(ctx, name)
 }
 
+func (r *SandboxClaimReconciler) coldStartTimeout() time.Duration {
+	if r.ColdStartTimeout > 0 {
+		return r.ColdStartTimeout
+	}
+	return defaultColdStartTimeout
+}
+
+func (r *SandboxClaimReconciler) metrics() *ControllerMetrics {
+	if r.Metrics != nil {
+		return r.Metrics
+	}
+	return defaultControllerMetrics()
+}
+
 //+kubebuilder:rbac:groups=agentland.fl0rencess720.app,resources=sandboxclaims,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=agentland.fl0rencess720.app,resources=sandboxclaims/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=agentland.fl0rencess720.app,resources=sandboxes,verbs=get;list;watch;create;update;patch;delete
@@ -80,9 +120,17 @@ func (r *SandboxClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	err := r.Get(ctx, client.ObjectKey{Namespace: claim.Namespace, Name: claim.Name}, sandbox)
 	if err == nil {
 		claim.Status.SandboxName = sandbox.Name
+		var requeueAfter time.Duration
 		if sandbox.Status.Phase == string(corev1.PodRunning) && sandbox.Status.PodIP != "" {
 			claim.Status.Phase = agentlandv1alpha1.SandboxClaimPhaseBound
 			claim.Status.Reason = "Bound"
+		} else if remaining, coldStart := r.coldStartRemaining(claim); coldStart && remaining <= 0 {
+			claim.Status.Phase = agentlandv1alpha1.SandboxClaimPhaseFailed
+			claim.Status.Reason = claimReasonColdStartTimeout
+		} else if coldStart {
+			claim.Status.Phase = agentlandv1alpha1.SandboxClaimPhasePending
+			claim.Status.Reason = claimReasonColdStart
+			requeueAfter = remaining
 		} else {
 			claim.Status.Phase = agentlandv1alpha1.SandboxClaimPhasePending
 			claim.Status.Reason = "SandboxPending"
@@ -97,7 +145,8 @@ func (r *SandboxClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 		if claim.Status.Phase != agentlandv1alpha1.SandboxClaimPhaseBound {
 			// Sandbox updates will trigger reconcile via Owns(&Sandbox{}).
-			return ctrl.Result{}, nil
+			// 冷启动仍在等待时到期后再检查一次超时
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
 		}
 		return ctrl.Result{}, nil
 	}
@@ -107,14 +156,23 @@ func (r *SandboxClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, err
 	}
 
-	pod, err := r.selectWarmPod(ctx, claim)
+	pod, err := r.acquireWarmPod(ctx, claim)
 	if err != nil {
 		span.RecordError(err)
-		span.SetStatus(codes.Error, "select warm pod failed")
+		span.SetStatus(codes.Error, "acquire warm pod failed")
 		return ctrl.Result{}, err
 	}
 	span.SetAttributes(attribute.Bool("warm.hit", pod != nil))
 
+	if pod == nil {
+		for _, poolName := range parsePoolRefs(claim.Spec.PoolRef) {
+			r.Tracker.RecordMiss(client.ObjectKey{Namespace: claim.Namespace, Name: poolName})
+			if err := r.touchSandboxPool(ctx, claim.Namespace, poolName); err != nil {
+				logger.V(1).Info("touch sandbox pool after cold start failed", "pool", poolName, "error", err.Error())
+			}
+		}
+	}
+
 	if pod == nil && claim.Spec.FallbackPolicy == agentlandv1alpha1.FallbackPolicyForbidColdStart {
 		claim.Status.Phase = agentlandv1alpha1.SandboxClaimPhaseFailed
 		claim.Status.Reason = "NoWarmPod"
@@ -128,23 +186,37 @@ func (r *SandboxClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 		return ctrl.Result{}, nil
 	}
-
+	claimReason := "SandboxCreating"
+	requeueAfter := commonutils.FallbackRequeueInterval
 	if pod != nil {
-		if err := r.adoptWarmPod(ctx, claim, pod); err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, "adopt warm pod failed")
-			return ctrl.Result{}, err
-		}
 		logger.V(1).Info("adopted warm pod", "claim", claim.Name, "pod", pod.Name)
 		span.AddEvent("warm.pod.selected", trace.WithAttributes(attribute.String("pod.name", pod.Name)))
+		meta.SetStatusCondition(&claim.Status.Conditions, metav1.Condition{
+			Type:    sandboxClaimConditionWarmPod,
+			Status:  metav1.ConditionTrue,
+			Reason:  claimReasonWarmPodAdopted,
+			Message: fmt.Sprintf("adopted warm pod %s", pod.Name),
+		})
 	} else {
+		// 冷启动：由 SandboxReconciler 按模板新建 Pod，需要拉取镜像并启动，耗时远高于预热命中
+		r.metrics().recordColdStart(ctx, claim.Spec.Profile, strings.Join(parsePoolRefs(claim.Spec.PoolRef), ","))
+		logger.V(1).Info("no warm pod available, cold starting sandbox", "claim", claim.Name, "timeout", r.coldStartTimeout().String())
 		span.AddEvent("warm.pod.not_found")
+		meta.SetStatusCondition(&claim.Status.Conditions, metav1.Condition{
+			Type:    sandboxClaimConditionWarmPod,
+			Status:  metav1.ConditionFalse,
+			Reason:  claimReasonColdStart,
+			Message: fmt.Sprintf("no warm pod available, sandbox pod is created on demand (timeout %s)", r.coldStartTimeout()),
+		})
+		claimReason = claimReasonColdStart
+		requeueAfter = min(requeueAfter, r.coldStartTimeout())
 	}
 
 	sandbox = &agentlandv1alpha1.Sandbox{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        claim.Name,
 			Namespace:   claim.Namespace,
+			Labels:      ownerLabels(claim),
 			Annotations: observability.PropagateTraceAnnotations(map[string]string{}, claim.Annotations),
 		},
 		Spec: agentlandv1alpha1.SandboxSpec{
@@ -169,7 +241,7 @@ func (r *SandboxClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request
 
 	claim.Status.Phase = agentlandv1alpha1.SandboxClaimPhasePending
 	claim.Status.SandboxName = claim.Name
-	claim.Status.Reason = "SandboxCreating"
+	claim.Status.Reason = claimReason
 	if err := r.updateClaimStatus(ctx, oldStatus, claim); err != nil {
 		if errors.IsConflict(err) {
 			return ctrl.Result{RequeueAfter: commonutils.ConflictRequeueInterval}, nil
@@ -178,13 +250,85 @@ func (r *SandboxClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		span.SetStatus(codes.Error, "update pending claim status failed")
 		return ctrl.Result{}, err
 	}
-	return ctrl.Result{RequeueAfter: commonutils.FallbackRequeueInterval}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// coldStartRemaining 返回冷启动 claim 距离超时的剩余时间，claim 命中预热 Pod 时第二个返回值为 false
+func (r *SandboxClaimReconciler) coldStartRemaining(claim *agentlandv1alpha1.SandboxClaim) (time.Duration, bool) {
+	cond := meta.FindStatusCondition(claim.Status.Conditions, sandboxClaimConditionWarmPod)
+	if cond == nil || cond.Reason != claimReasonColdStart {
+		return 0, false
+	}
+	return time.Until(cond.LastTransitionTime.Add(r.coldStartTimeout())), true
+}
+
+// acquireWarmPod 为 claim 选择并认领一个预热 Pod，没有可用 Pod 时返回 nil。
+// 并发的 claim 可能从缓存中选中同一个 Pod，认领以 resourceVersion 乐观锁提交，
+// 失败方排除该 Pod 后重新选择，最多重试 maxWarmPodAdoptAttempts 次；
+// 上一轮已认领但未能创建 Sandbox 时直接复用该 Pod，避免一个 claim 占用多个 Pod。
+func (r *SandboxClaimReconciler) acquireWarmPod(ctx context.Context, claim *agentlandv1alpha1.SandboxClaim) (*corev1.Pod, error) {
+	adopted, err := r.findAdoptedPod(ctx, claim)
+	if err != nil || adopted != nil {
+		return adopted, err
+	}
+
+	poolRefs := strings.Join(parsePoolRefs(claim.Spec.PoolRef), ",")
+	lost := map[string]struct{}{}
+	for attempt := 1; ; attempt++ {
+		pod, err := r.selectWarmPod(ctx, claim, lost)
+		if err != nil {
+			return nil, err
+		}
+		if pod == nil {
+			r.metrics().recordWarmPodSelection(ctx, claim.Spec.Profile, poolRefs, false)
+			return nil, nil
+		}
+		hitPool := ""
+		if controllerRef := metav1.GetControllerOf(pod); controllerRef != nil {
+			hitPool = controllerRef.Name
+		}
+		err = r.adoptWarmPod(ctx, claim, pod)
+		if err == nil {
+			r.metrics().recordWarmPodSelection(ctx, claim.Spec.Profile, hitPool, true)
+			return pod, nil
+		}
+		if !isWarmPodAdoptionLost(err) || attempt >= maxWarmPodAdoptAttempts {
+			return nil, err
+		}
+		log.FromContext(ctx).V(1).Info("warm pod taken by another claim, retrying", "claim", claim.Name, "pod", pod.Name)
+		lost[pod.Name] = struct{}{}
+	}
+}
+
+// findAdoptedPod 返回已被该 claim 认领的 Pod
+func (r *SandboxClaimReconciler) findAdoptedPod(ctx context.Context, claim *agentlandv1alpha1.SandboxClaim) (*corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(claim.Namespace), client.MatchingLabels{commonutils.ClaimUIDLabel: string(claim.UID)}); err != nil {
+		return nil, err
+	}
+	for i := range podList.Items {
+		if podList.Items[i].DeletionTimestamp.IsZero() {
+			return &podList.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// isWarmPodAdoptionLost 判断认领失败是否因为 Pod 已被其他 claim 认领或已被删除，此时应换一个 Pod 重试
+func isWarmPodAdoptionLost(err error) bool {
+	return errors.IsConflict(err) || errors.IsNotFound(err) || stderrors.Is(err, errWarmPodTaken)
 }
 
-func (r *SandboxClaimReconciler) selectWarmPod(ctx context.Context, claim *agentlandv1alpha1.SandboxClaim) (*corev1.Pod, error) {
+func (r *SandboxClaimReconciler) selectWarmPod(ctx context.Context, claim *agentlandv1alpha1.SandboxClaim, exclude map[string]struct{}) (*corev1.Pod, error) {
 	ctx, span := r.startSpan(ctx, "controller.sandboxclaim.select_warm_pod")
 	defer span.End()
 
+	// 预热 Pod 使用 emptyDir 工作区，无法挂载会话的持久化 PVC
+	if workspacePersistenceEnabled(claim.Spec.Template) {
+		span.SetAttributes(attribute.Bool("workspace.persistent", true))
+		return nil, nil
+	}
+
 	podList := &corev1.PodList{}
 	selector, err := commonutils.SelectorWithHashValue(commonutils.ProfileHashLabel, claim.Spec.Profile)
 	if err != nil {
@@ -192,8 +336,9 @@ func (r *SandboxClaimReconciler) selectWarmPod(ctx context.Context, claim *agent
 		span.SetStatus(codes.Error, "build profile selector failed")
 		return nil, err
 	}
-	if claim.Spec.PoolRef != "" {
-		selector, err = commonutils.AddHashValueRequirement(selector, commonutils.PoolLabel, claim.Spec.PoolRef)
+	poolRefs := parsePoolRefs(claim.Spec.PoolRef)
+	if len(poolRefs) > 0 {
+		selector, err = commonutils.AddHashValuesRequirement(selector, commonutils.PoolLabel, poolRefs)
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, "build pool selector failed")
@@ -217,6 +362,9 @@ func (r *SandboxClaimReconciler) selectWarmPod(ctx context.Context, claim *agent
 		if !pod.DeletionTimestamp.IsZero() {
 			continue
 		}
+		if _, skip := exclude[pod.Name]; skip {
+			continue
+		}
 		if controllerRef := metav1.GetControllerOf(pod); controllerRef != nil && controllerRef.Kind != "SandboxPool" {
 			continue
 		}
@@ -227,11 +375,22 @@ func (r *SandboxClaimReconciler) selectWarmPod(ctx context.Context, claim *agent
 		return nil, nil
 	}
 
+	// 多个候选池时优先选择就绪 Pod 最多的池，以均衡各池剩余容量
+	readyByPool := make(map[string]int, len(poolRefs))
+	for _, pod := range candidates {
+		if isWarmPodAvailable(pod) {
+			readyByPool[pod.Labels[commonutils.PoolLabel]]++
+		}
+	}
 	sort.Slice(candidates, func(i, j int) bool {
-		ir, jr := commonutils.IsPodReady(candidates[i]), commonutils.IsPodReady(candidates[j])
+		ir, jr := isWarmPodAvailable(candidates[i]), isWarmPodAvailable(candidates[j])
 		if ir != jr {
 			return ir
 		}
+		ic, jc := readyByPool[candidates[i].Labels[commonutils.PoolLabel]], readyByPool[candidates[j].Labels[commonutils.PoolLabel]]
+		if ic != jc {
+			return ic > jc
+		}
 		return candidates[i].CreationTimestamp.Before(&candidates[j].CreationTimestamp)
 	})
 
@@ -243,11 +402,19 @@ func (r *SandboxClaimReconciler) selectWarmPod(ctx context.Context, claim *agent
 }
 
 func (r *SandboxClaimReconciler) adoptWarmPod(ctx context.Context, claim *agentlandv1alpha1.SandboxClaim, pod *corev1.Pod) error {
-	poolName := claim.Spec.PoolRef
+	poolName := ""
+	if poolRefs := parsePoolRefs(claim.Spec.PoolRef); len(poolRefs) == 1 {
+		poolName = poolRefs[0]
+	}
 	if controllerRef := metav1.GetControllerOf(pod); controllerRef != nil && controllerRef.Kind == "SandboxPool" {
 		poolName = controllerRef.Name
 	}
 
+	if owner := pod.Labels[commonutils.ClaimUIDLabel]; owner != "" && owner != string(claim.UID) {
+		return errWarmPodTaken
+	}
+
+	base := pod.DeepCopy()
 	if pod.Labels == nil {
 		pod.Labels = map[string]string{}
 	}
@@ -256,9 +423,13 @@ func (r *SandboxClaimReconciler) adoptWarmPod(ctx context.Context, claim *agentl
 	pod.Labels[commonutils.SandboxLabel] = commonutils.NameHash(claim.Name)
 	pod.Labels[commonutils.ClaimUIDLabel] = string(claim.UID)
 	pod.OwnerReferences = nil
-	if err := r.Update(ctx, pod); err != nil {
+	// 仅预拉取镜像的 Pod 在此切换到沙箱镜像，镜像已在节点缓存中
+	activateWarmImage(pod)
+	// 以选中时的 resourceVersion 为前提提交，Pod 已被其他 claim 修改时返回 Conflict
+	if err := r.Patch(ctx, pod, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})); err != nil {
 		return err
 	}
+	r.Tracker.RecordHit(client.ObjectKey{Namespace: claim.Namespace, Name: poolName})
 	return r.touchSandboxPool(ctx, claim.Namespace, poolName)
 }
 
@@ -279,6 +450,18 @@ func (r *SandboxClaimReconciler) touchSandboxPool(ctx context.Context, namespace
 	return r.Patch(ctx, pool, client.MergeFrom(base))
 }
 
+// parsePoolRefs 解析逗号分隔的 PoolRef，去除空白与重复项。
+func parsePoolRefs(poolRef string) []string {
+	var refs []string
+	for _, ref := range strings.Split(poolRef, ",") {
+		ref = strings.TrimSpace(ref)
+		if ref != "" && !slices.Contains(refs, ref) {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
 func (r *SandboxClaimReconciler) updateClaimStatus(ctx context.Context, oldStatus *agentlandv1alpha1.SandboxClaimStatus, claim *agentlandv1alpha1.SandboxClaim) error {
 	if equality.Semantic.DeepEqual(oldStatus, &claim.Status) {
 		return nil
@@ -294,5 +477,5 @@ func (r *SandboxClaimReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&agentlandv1alpha1.SandboxClaim{}).
 		Owns(&agentlandv1alpha1.Sandbox{}).
-		Complete(r)
+		Complete(instrumentReconciler("sandboxclaim", r, r.metrics()))
 }
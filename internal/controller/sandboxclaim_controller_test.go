@@ -2,13 +2,21 @@ package controller
 
 import (
 	"context"
+	"sync"
 	"testing"
+	"time"
 
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	agentlandv1alpha1 "github.com/Fl0rencess720/agentland/api/v1alpha1"
 	commonutils "github.com/Fl0rencess720/agentland/pkg/common/utils"
@@ -102,3 +110,332 @@ func TestAdoptWarmPodTouchesPoolForBackfill(t *testing.T) {
 }
 
 func boolPtr(v bool) *bool { return &v }
+
+func TestSelectWarmPodPrefersPoolWithMostReadyPods(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add corev1 scheme: %v", err)
+	}
+	if err := agentlandv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add agentland scheme: %v", err)
+	}
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newPoolPod := func(name, pool string, created time.Time) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "agentland-sandboxes",
+				CreationTimestamp: metav1.NewTime(created),
+				Labels: map[string]string{
+					commonutils.PoolLabel:        commonutils.NameHash(pool),
+					commonutils.ProfileHashLabel: commonutils.NameHash("default"),
+				},
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			newPoolPod("pool-a-oldest", "pool-a", base),
+			newPoolPod("pool-b-1", "pool-b", base.Add(time.Minute)),
+			newPoolPod("pool-b-2", "pool-b", base.Add(2*time.Minute)),
+			newPoolPod("pool-c-1", "pool-c", base),
+			newPoolPod("pool-c-2", "pool-c", base),
+			newPoolPod("pool-c-3", "pool-c", base),
+		).
+		Build()
+	r := &SandboxClaimReconciler{Client: cli}
+	claim := &agentlandv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "session-1", Namespace: "agentland-sandboxes"},
+		Spec: agentlandv1alpha1.SandboxClaimSpec{
+			Profile: "default",
+			PoolRef: "pool-a, pool-b",
+		},
+	}
+
+	pod, err := r.selectWarmPod(context.Background(), claim, nil)
+	if err != nil {
+		t.Fatalf("selectWarmPod: %v", err)
+	}
+	if pod == nil || pod.Name != "pool-b-1" {
+		t.Fatalf("expected oldest pod of pool-b, got %+v", pod)
+	}
+}
+
+func TestParsePoolRefs(t *testing.T) {
+	t.Parallel()
+
+	got := parsePoolRefs(" pool-a,pool-b,,pool-a ")
+	if len(got) != 2 || got[0] != "pool-a" || got[1] != "pool-b" {
+		t.Fatalf("parsePoolRefs() = %v", got)
+	}
+	if got := parsePoolRefs(""); len(got) != 0 {
+		t.Fatalf("parsePoolRefs(\"\") = %v, want empty", got)
+	}
+}
+
+func TestAcquireWarmPodRecordsHitMetric(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add corev1 scheme: %v", err)
+	}
+	if err := agentlandv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add agentland scheme: %v", err)
+	}
+
+	reader := sdkmetric.NewManualReader()
+	metrics, err := NewControllerMetrics(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test"))
+	if err != nil {
+		t.Fatalf("new controller metrics: %v", err)
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pool-a-1",
+			Namespace: "agentland-sandboxes",
+			Labels: map[string]string{
+				commonutils.PoolLabel:        commonutils.NameHash("pool-a"),
+				commonutils.ProfileHashLabel: commonutils.NameHash("default"),
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: agentlandv1alpha1.GroupVersion.String(),
+				Kind:       "SandboxPool",
+				Name:       "pool-a",
+				UID:        types.UID("pool-uid"),
+				Controller: boolPtr(true),
+			}},
+		},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod).Build()
+	r := &SandboxClaimReconciler{Client: cli, Metrics: metrics}
+	claim := &agentlandv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "session-1", Namespace: "agentland-sandboxes", UID: types.UID("claim-uid")},
+		Spec:       agentlandv1alpha1.SandboxClaimSpec{Profile: "default", PoolRef: "pool-a"},
+	}
+
+	selected, err := r.acquireWarmPod(context.Background(), claim)
+	if err != nil {
+		t.Fatalf("acquireWarmPod: %v", err)
+	}
+	if selected == nil {
+		t.Fatalf("expected warm pod hit")
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect metrics: %v", err)
+	}
+	hits, misses := int64(0), int64(0)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "agentland.sandboxclaim.warm_pod.selections" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				result, _ := dp.Attributes.Value("result")
+				pool, _ := dp.Attributes.Value("pool")
+				profile, _ := dp.Attributes.Value("profile")
+				if pool.AsString() != "pool-a" || profile.AsString() != "default" {
+					t.Fatalf("unexpected attributes: %v", dp.Attributes.ToSlice())
+				}
+				switch result.AsString() {
+				case "hit":
+					hits += dp.Value
+				case "miss":
+					misses += dp.Value
+				}
+			}
+		}
+	}
+	if hits != 1 || misses != 0 {
+		t.Fatalf("expected 1 hit and 0 misses, got hits=%d misses=%d", hits, misses)
+	}
+}
+
+func TestAcquireWarmPodRetriesWhenPodTakenConcurrently(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add corev1 scheme: %v", err)
+	}
+	if err := agentlandv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add agentland scheme: %v", err)
+	}
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newPoolPod := func(name string, created time.Time) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "agentland-sandboxes",
+				CreationTimestamp: metav1.NewTime(created),
+				Labels: map[string]string{
+					commonutils.PoolLabel:        commonutils.NameHash("pool-a"),
+					commonutils.ProfileHashLabel: commonutils.NameHash("default"),
+				},
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+
+	// 模拟两个 claim 基于同一份缓存快照选择预热 Pod：第二个 claim 选择时读到第一个 claim 认领前的 Pod 列表
+	var (
+		staleMu sync.Mutex
+		stale   *corev1.PodList
+	)
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(newPoolPod("pod-a", base), newPoolPod("pod-b", base.Add(time.Minute))).
+		WithInterceptorFuncs(interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				staleMu.Lock()
+				defer staleMu.Unlock()
+				podList, ok := list.(*corev1.PodList)
+				if ok && stale != nil && !selectsAdoptedPods(opts) {
+					stale.DeepCopyInto(podList)
+					stale = nil
+					return nil
+				}
+				return c.List(ctx, list, opts...)
+			},
+		}).
+		Build()
+	snapshot := &corev1.PodList{}
+	if err := cli.List(context.Background(), snapshot); err != nil {
+		t.Fatalf("list pods: %v", err)
+	}
+	r := &SandboxClaimReconciler{Client: cli, Tracker: NewPoolClaimTracker()}
+
+	newClaim := func(name, uid string) *agentlandv1alpha1.SandboxClaim {
+		return &agentlandv1alpha1.SandboxClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "agentland-sandboxes", UID: types.UID(uid)},
+			Spec:       agentlandv1alpha1.SandboxClaimSpec{Profile: "default", PoolRef: "pool-a"},
+		}
+	}
+
+	first, err := r.acquireWarmPod(context.Background(), newClaim("session-1", "claim-1"))
+	if err != nil || first == nil {
+		t.Fatalf("acquireWarmPod(session-1) = %v, %v", first, err)
+	}
+	staleMu.Lock()
+	stale = snapshot
+	staleMu.Unlock()
+	second, err := r.acquireWarmPod(context.Background(), newClaim("session-2", "claim-2"))
+	if err != nil || second == nil {
+		t.Fatalf("acquireWarmPod(session-2) = %v, %v", second, err)
+	}
+	staleMu.Lock()
+	usedStale := stale == nil
+	staleMu.Unlock()
+	if !usedStale {
+		t.Fatalf("second claim must have selected from the stale snapshot")
+	}
+	if first.Name == second.Name {
+		t.Fatalf("both claims adopted pod %s", first.Name)
+	}
+
+	for name, wantUID := range map[string]string{first.Name: "claim-1", second.Name: "claim-2"} {
+		got := &corev1.Pod{}
+		if err := cli.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "agentland-sandboxes"}, got); err != nil {
+			t.Fatalf("get pod %s: %v", name, err)
+		}
+		if got.Labels[commonutils.ClaimUIDLabel] != wantUID {
+			t.Fatalf("pod %s claim uid label = %q, want %q", name, got.Labels[commonutils.ClaimUIDLabel], wantUID)
+		}
+	}
+
+	again, err := r.acquireWarmPod(context.Background(), newClaim("session-2", "claim-2"))
+	if err != nil || again == nil || again.Name != second.Name {
+		t.Fatalf("re-acquire must reuse the adopted pod %s, got %v, %v", second.Name, again, err)
+	}
+}
+
+// selectsAdoptedPods 判断 List 是否为 findAdoptedPod 按 claim UID 发起的查询
+func selectsAdoptedPods(opts []client.ListOption) bool {
+	listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+	if listOpts.LabelSelector == nil {
+		return false
+	}
+	_, ok := listOpts.LabelSelector.RequiresExactMatch(commonutils.ClaimUIDLabel)
+	return ok
+}
+
+func TestReconcileRecordsColdStartWithoutWarmPod(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add corev1 scheme: %v", err)
+	}
+	if err := agentlandv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add agentland scheme: %v", err)
+	}
+
+	claim := &agentlandv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "session-cold", Namespace: "agentland-sandboxes", UID: types.UID("claim-uid")},
+		Spec: agentlandv1alpha1.SandboxClaimSpec{
+			Profile:        "default",
+			PoolRef:        "pool-a",
+			FallbackPolicy: agentlandv1alpha1.FallbackPolicyAllowColdStart,
+			Template:       &agentlandv1alpha1.SandboxTemplate{Image: "korokd:test"},
+		},
+	}
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(claim).
+		WithStatusSubresource(&agentlandv1alpha1.SandboxClaim{}).
+		Build()
+	r := &SandboxClaimReconciler{Client: cli, Scheme: scheme, Tracker: NewPoolClaimTracker(), ColdStartTimeout: time.Minute}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: claim.Name, Namespace: claim.Namespace}}
+
+	result, err := r.Reconcile(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if result.RequeueAfter <= 0 || result.RequeueAfter > time.Minute {
+		t.Fatalf("cold start must requeue within the timeout, got %v", result.RequeueAfter)
+	}
+
+	got := &agentlandv1alpha1.SandboxClaim{}
+	if err := cli.Get(context.Background(), req.NamespacedName, got); err != nil {
+		t.Fatalf("get claim: %v", err)
+	}
+	if got.Status.Phase != agentlandv1alpha1.SandboxClaimPhasePending || got.Status.Reason != claimReasonColdStart {
+		t.Fatalf("claim status = %s/%s, want Pending/%s", got.Status.Phase, got.Status.Reason, claimReasonColdStart)
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, sandboxClaimConditionWarmPod)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != claimReasonColdStart {
+		t.Fatalf("expected %s condition with reason %s, got %+v", sandboxClaimConditionWarmPod, claimReasonColdStart, cond)
+	}
+	sandbox := &agentlandv1alpha1.Sandbox{}
+	if err := cli.Get(context.Background(), req.NamespacedName, sandbox); err != nil {
+		t.Fatalf("cold start must still create the sandbox: %v", err)
+	}
+	if _, ok := sandbox.Annotations[commonutils.PodNameAnnotation]; ok {
+		t.Fatalf("cold-started sandbox must not reference a warm pod")
+	}
+
+	// 沙箱仍未 Running 且超过冷启动超时
+	r.ColdStartTimeout = time.Nanosecond
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile after timeout: %v", err)
+	}
+	if err := cli.Get(context.Background(), req.NamespacedName, got); err != nil {
+		t.Fatalf("get claim: %v", err)
+	}
+	if got.Status.Phase != agentlandv1alpha1.SandboxClaimPhaseFailed || got.Status.Reason != claimReasonColdStartTimeout {
+		t.Fatalf("claim status = %s/%s, want Failed/%s", got.Status.Phase, got.Status.Reason, claimReasonColdStartTimeout)
+	}
+}
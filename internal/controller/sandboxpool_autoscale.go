@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	agentlandv1alpha1 "github.com/Fl0rencess720/agentland/api/v1alpha1"
+)
+
+const (
+	defaultAutoscaleTargetWarmHitPercent = 90
+	defaultAutoscaleWindow               = 5 * time.Minute
+	// maxPoolClaimEvents 限制单个池保留的事件数，避免突发流量下无限增长
+	maxPoolClaimEvents = 4096
+)
+
+type poolClaimEvent struct {
+	at  time.Time
+	hit bool
+}
+
+// PoolClaimTracker 以滑动窗口记录各 SandboxPool 最近的 claim 命中情况，
+// 由 SandboxClaimReconciler 写入、SandboxPoolReconciler 读取，nil 时所有方法均为空操作。
+type PoolClaimTracker struct {
+	mu     sync.Mutex
+	events map[types.NamespacedName][]poolClaimEvent
+	now    func() time.Time
+}
+
+func NewPoolClaimTracker() *PoolClaimTracker {
+	return &PoolClaimTracker{
+		events: make(map[types.NamespacedName][]poolClaimEvent),
+		now:    time.Now,
+	}
+}
+
+// RecordHit 记录一次由预热 Pod 满足的 claim。
+func (t *PoolClaimTracker) RecordHit(pool types.NamespacedName) {
+	t.record(pool, true)
+}
+
+// RecordMiss 记录一次因无可用预热 Pod 而冷启动的 claim。
+func (t *PoolClaimTracker) RecordMiss(pool types.NamespacedName) {
+	t.record(pool, false)
+}
+
+func (t *PoolClaimTracker) record(pool types.NamespacedName, hit bool) {
+	if t == nil || pool.Name == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	events := append(t.events[pool], poolClaimEvent{at: t.now(), hit: hit})
+	if len(events) > maxPoolClaimEvents {
+		events = events[len(events)-maxPoolClaimEvents:]
+	}
+	t.events[pool] = events
+}
+
+// Counts 返回窗口内的命中与未命中次数，并清理窗口外的事件。
+func (t *PoolClaimTracker) Counts(pool types.NamespacedName, window time.Duration) (hits, misses int32) {
+	if t == nil {
+		return 0, 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cutoff := t.now().Add(-window)
+	events := t.events[pool]
+	start := 0
+	for start < len(events) && events[start].at.Before(cutoff) {
+		start++
+	}
+	events = events[start:]
+	if len(events) == 0 {
+		delete(t.events, pool)
+		return 0, 0
+	}
+	t.events[pool] = events
+
+	for _, e := range events {
+		if e.hit {
+			hits++
+		} else {
+			misses++
+		}
+	}
+	return hits, misses
+}
+
+// autoscaleWindow 返回池配置的观测窗口。
+func autoscaleWindow(as *agentlandv1alpha1.SandboxPoolAutoscale) time.Duration {
+	if as.WindowSeconds <= 0 {
+		return defaultAutoscaleWindow
+	}
+	return time.Duration(as.WindowSeconds) * time.Second
+}
+
+// computeDesiredReplicas 根据窗口内的命中情况计算期望副本数：
+// 命中率低于目标时按未命中次数扩容，窗口内没有任何 claim 时缩容一个，结果始终落在 [min, max] 内。
+func computeDesiredReplicas(current int32, as *agentlandv1alpha1.SandboxPoolAutoscale, hits, misses int32) int32 {
+	minReplicas, maxReplicas := as.MinReplicas, as.MaxReplicas
+	if maxReplicas < minReplicas {
+		maxReplicas = minReplicas
+	}
+	target := as.TargetWarmHitPercent
+	if target <= 0 {
+		target = defaultAutoscaleTargetWarmHitPercent
+	}
+
+	desired := current
+	total := hits + misses
+	switch {
+	case total == 0:
+		desired = current - 1
+	case int64(hits)*100 < int64(target)*int64(total):
+		desired = current + max(misses, 1)
+	}
+	return min(max(desired, minReplicas), maxReplicas)
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	agentlandv1alpha1 "github.com/Fl0rencess720/agentland/api/v1alpha1"
+)
+
+func TestComputeDesiredReplicas(t *testing.T) {
+	t.Parallel()
+
+	as := &agentlandv1alpha1.SandboxPoolAutoscale{
+		MinReplicas:          1,
+		MaxReplicas:          10,
+		TargetWarmHitPercent: 80,
+	}
+	cases := []struct {
+		name    string
+		current int32
+		hits    int32
+		misses  int32
+		want    int32
+	}{
+		{name: "low hit rate grows by misses", current: 2, hits: 5, misses: 5, want: 7},
+		{name: "growth capped at max", current: 8, hits: 0, misses: 6, want: 10},
+		{name: "target reached keeps size", current: 4, hits: 8, misses: 2, want: 4},
+		{name: "idle shrinks by one", current: 4, want: 3},
+		{name: "idle never below min", current: 1, want: 1},
+		{name: "current below min is raised", current: 0, hits: 3, want: 1},
+	}
+	for _, tc := range cases {
+		if got := computeDesiredReplicas(tc.current, as, tc.hits, tc.misses); got != tc.want {
+			t.Errorf("%s: computeDesiredReplicas() = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestPoolClaimTrackerCountsSlidingWindow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewPoolClaimTracker()
+	tracker.now = func() time.Time { return now }
+	key := types.NamespacedName{Namespace: "agentland-sandboxes", Name: "pool-a"}
+
+	tracker.RecordHit(key)
+	tracker.RecordMiss(key)
+	now = now.Add(2 * time.Minute)
+	tracker.RecordMiss(key)
+
+	if hits, misses := tracker.Counts(key, 5*time.Minute); hits != 1 || misses != 2 {
+		t.Fatalf("counts = (%d, %d), want (1, 2)", hits, misses)
+	}
+	now = now.Add(4 * time.Minute)
+	if hits, misses := tracker.Counts(key, 5*time.Minute); hits != 0 || misses != 1 {
+		t.Fatalf("counts after window = (%d, %d), want (0, 1)", hits, misses)
+	}
+
+	var nilTracker *PoolClaimTracker
+	nilTracker.RecordHit(key)
+	if hits, misses := nilTracker.Counts(key, time.Minute); hits != 0 || misses != 0 {
+		t.Fatalf("nil tracker counts = (%d, %d), want (0, 0)", hits, misses)
+	}
+}
+
+func TestSandboxPoolDesiredReplicasScalesUpOnColdStarts(t *testing.T) {
+	t.Parallel()
+
+	tracker := NewPoolClaimTracker()
+	r := &SandboxPoolReconciler{Tracker: tracker}
+	pool := &agentlandv1alpha1.SandboxPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool-a", Namespace: "agentland-sandboxes"},
+		Spec: agentlandv1alpha1.SandboxPoolSpec{
+			Replicas: 2,
+			Autoscale: &agentlandv1alpha1.SandboxPoolAutoscale{
+				MinReplicas:          1,
+				MaxReplicas:          6,
+				TargetWarmHitPercent: 90,
+				WindowSeconds:        60,
+			},
+		},
+	}
+	key := types.NamespacedName{Namespace: pool.Namespace, Name: pool.Name}
+	for range 3 {
+		tracker.RecordHit(key)
+	}
+	for range 3 {
+		tracker.RecordMiss(key)
+	}
+
+	if got := r.desiredReplicas(pool); got != 5 {
+		t.Fatalf("desiredReplicas() = %d, want 5", got)
+	}
+	if pool.Status.DesiredReplicas != 5 || pool.Status.LastScaleTime == nil {
+		t.Fatalf("unexpected status: %+v", pool.Status)
+	}
+
+	// 冷却窗口内不再重复扩容
+	tracker.RecordMiss(key)
+	if got := r.desiredReplicas(pool); got != 5 {
+		t.Fatalf("desiredReplicas() within window = %d, want 5", got)
+	}
+
+	pool.Status.LastScaleTime = &metav1.Time{Time: time.Now().Add(-2 * time.Minute)}
+	tracker.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if got := r.desiredReplicas(pool); got != 4 {
+		t.Fatalf("desiredReplicas() after idle window = %d, want 4", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -25,6 +26,10 @@ type SandboxPoolReconciler struct {
 	client.Client
 	Scheme          *runtime.Scheme
 	ImagePullPolicy corev1.PullPolicy
+	// DefaultSecurityContext 为预热 Pod 容器的默认安全上下文，与 SandboxReconciler 保持一致
+	DefaultSecurityContext *corev1.SecurityContext
+	// Tracker 提供 claim 命中情况，供 Autoscale 计算期望副本数
+	Tracker *PoolClaimTracker
 }
 
 //+kubebuilder:rbac:groups=agentland.fl0rencess720.app,resources=sandboxpools,verbs=get;list;watch;create;update;patch;delete
@@ -52,13 +57,13 @@ func (r *SandboxPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
-	desired := pool.Spec.Replicas
+	desired := r.desiredReplicas(pool)
 	current := int32(len(activePods))
 
 	pool.Status.Replicas = current
 	ready := int32(0)
 	for i := range activePods {
-		if commonutils.IsPodReady(&activePods[i]) {
+		if isWarmPodAvailable(&activePods[i]) {
 			ready++
 		}
 	}
@@ -90,12 +95,44 @@ func (r *SandboxPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
-	if pool.Status.ReadyReplicas != pool.Spec.Replicas {
+	if pool.Status.ReadyReplicas != desired {
 		return ctrl.Result{RequeueAfter: commonutils.DefaultRequeueInterval}, nil
 	}
+	if pool.Spec.Autoscale != nil {
+		// 定期重新评估命中率，空闲时才能触发缩容
+		return ctrl.Result{RequeueAfter: autoscaleWindow(pool.Spec.Autoscale)}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
+// desiredReplicas 返回本轮的期望副本数；开启 Autoscale 时每个窗口最多调整一次，并记录到 status。
+func (r *SandboxPoolReconciler) desiredReplicas(pool *agentlandv1alpha1.SandboxPool) int32 {
+	as := pool.Spec.Autoscale
+	if as == nil {
+		pool.Status.DesiredReplicas = 0
+		pool.Status.LastScaleTime = nil
+		return pool.Spec.Replicas
+	}
+
+	current := pool.Status.DesiredReplicas
+	if pool.Status.LastScaleTime == nil && current == 0 {
+		current = pool.Spec.Replicas
+	}
+	window := autoscaleWindow(as)
+	now := time.Now()
+	if pool.Status.LastScaleTime == nil || now.Sub(pool.Status.LastScaleTime.Time) >= window {
+		hits, misses := r.Tracker.Counts(client.ObjectKeyFromObject(pool), window)
+		desired := computeDesiredReplicas(current, as, hits, misses)
+		if desired != current || pool.Status.LastScaleTime == nil {
+			pool.Status.LastScaleTime = &metav1.Time{Time: now}
+		}
+		current = desired
+	}
+	current = min(max(current, as.MinReplicas), max(as.MaxReplicas, as.MinReplicas))
+	pool.Status.DesiredReplicas = current
+	return current
+}
+
 func (r *SandboxPoolReconciler) listPoolPods(ctx context.Context, pool *agentlandv1alpha1.SandboxPool) ([]corev1.Pod, error) {
 	podList := &corev1.PodList{}
 	selector, err := commonutils.SelectorWithHashValue(commonutils.PoolLabel, pool.Name)
@@ -150,7 +187,7 @@ func (r *SandboxPoolReconciler) createPoolPod(ctx context.Context, pool *agentla
 				Args:            pool.Spec.Template.Args,
 				VolumeMounts: []corev1.VolumeMount{{
 					Name:      sandboxJWTVolumeName,
-					MountPath: "/var/run/agentland/jwt",
+					MountPath: sandboxJWTMountPath,
 					ReadOnly:  true,
 				}, {
 					Name:      workspaceVolumeName,
@@ -172,9 +209,16 @@ func (r *SandboxPoolReconciler) createPoolPod(ctx context.Context, pool *agentla
 			}},
 		},
 	}
-	if pool.Spec.Template.RuntimeClassName != "" {
-		runtimeClassName := pool.Spec.Template.RuntimeClassName
-		pod.Spec.RuntimeClassName = &runtimeClassName
+	applyTemplateContainerFields(&pod.Spec.Containers[0], pool.Spec.Template)
+	applySecurityDefaults(&pod.Spec.Containers[0], r.DefaultSecurityContext)
+	if err := applyTemplateVolumes(&pod.Spec, pool.Spec.Template); err != nil {
+		return err
+	}
+	applyTemplatePodFields(&pod.Spec, pool.Spec.Template)
+	if poolWarmingStrategy(pool) == agentlandv1alpha1.WarmingStrategyImagePull {
+		if err := applyImagePullWarming(pod, pool); err != nil {
+			return err
+		}
 	}
 	if err := controllerutil.SetControllerReference(pool, pod, r.Scheme); err != nil {
 		return err
@@ -207,5 +251,5 @@ func (r *SandboxPoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&agentlandv1alpha1.SandboxPool{}).
 		Owns(&corev1.Pod{}, builder.WithPredicates(podLabelPredicate)).
 		Named("sandboxpool").
-		Complete(r)
+		Complete(instrumentReconciler("sandboxpool", r, defaultControllerMetrics()))
 }
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	agentlandv1alpha1 "github.com/Fl0rencess720/agentland/api/v1alpha1"
+	commonutils "github.com/Fl0rencess720/agentland/pkg/common/utils"
+)
+
+const (
+	// defaultWarmPauseImage 为仅预拉取镜像时主容器在被认领前运行的镜像
+	defaultWarmPauseImage = "registry.k8s.io/pause:3.10"
+	// warmPullContainerName 为触发沙箱镜像拉取的 init 容器名称
+	warmPullContainerName = "image-warmup"
+)
+
+// defaultWarmPullCommand 为 init 容器在沙箱镜像中执行的命令，只要求镜像能启动并立即退出
+var defaultWarmPullCommand = []string{"true"}
+
+// poolWarmingStrategy 返回池的预热策略，未配置时为 Running
+func poolWarmingStrategy(pool *agentlandv1alpha1.SandboxPool) agentlandv1alpha1.WarmingStrategy {
+	if pool.Spec.Warming == nil || pool.Spec.Warming.Strategy == "" {
+		return agentlandv1alpha1.WarmingStrategyRunning
+	}
+	return pool.Spec.Warming.Strategy
+}
+
+// applyImagePullWarming 将池 Pod 改为仅预拉取镜像：init 容器以沙箱镜像执行一次性命令完成拉取，
+// 主容器先运行 pause 镜像，沙箱镜像记录在注解中，待认领时由 adoptWarmPod 切换。
+// 主容器的 command 在切换镜像后无法修改，因此模板设置了 command 时返回错误；
+// 存活探针会不断重启 pause 容器，因此不设置。
+func applyImagePullWarming(pod *corev1.Pod, pool *agentlandv1alpha1.SandboxPool) error {
+	if len(pool.Spec.Template.Command) > 0 {
+		return fmt.Errorf("sandboxPool %s: warming strategy %s does not support sandboxTemplate.command",
+			pool.Name, agentlandv1alpha1.WarmingStrategyImagePull)
+	}
+	warming := pool.Spec.Warming
+	pauseImage := warming.PauseImage
+	if pauseImage == "" {
+		pauseImage = defaultWarmPauseImage
+	}
+	pullCommand := warming.PullCommand
+	if len(pullCommand) == 0 {
+		pullCommand = defaultWarmPullCommand
+	}
+
+	main := &pod.Spec.Containers[0]
+	pod.Spec.InitContainers = append(pod.Spec.InitContainers, corev1.Container{
+		Name:            warmPullContainerName,
+		Image:           main.Image,
+		ImagePullPolicy: main.ImagePullPolicy,
+		Command:         append([]string(nil), pullCommand...),
+		SecurityContext: main.SecurityContext.DeepCopy(),
+	})
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[commonutils.WarmImageAnnotation] = main.Image
+	main.Image = pauseImage
+	main.LivenessProbe = nil
+	return nil
+}
+
+// isWarmPodAvailable 判断预热 Pod 是否可直接交付：常规预热 Pod 需要 Ready；
+// 仅预拉取镜像的 Pod 在 init 容器成功退出（镜像已在节点上）且主容器运行后即视为可用。
+func isWarmPodAvailable(pod *corev1.Pod) bool {
+	if pod.Annotations[commonutils.WarmImageAnnotation] == "" {
+		return commonutils.IsPodReady(pod)
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, cs := range pod.Status.InitContainerStatuses {
+		if cs.Name != warmPullContainerName {
+			continue
+		}
+		return cs.State.Terminated != nil && cs.State.Terminated.ExitCode == 0
+	}
+	return false
+}
+
+// activateWarmImage 在认领仅预拉取镜像的 Pod 时将主容器切换为沙箱镜像，常规预热 Pod 不做修改
+func activateWarmImage(pod *corev1.Pod) {
+	image := pod.Annotations[commonutils.WarmImageAnnotation]
+	if image == "" || len(pod.Spec.Containers) == 0 {
+		return
+	}
+	pod.Spec.Containers[0].Image = image
+	delete(pod.Annotations, commonutils.WarmImageAnnotation)
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentlandv1alpha1 "github.com/Fl0rencess720/agentland/api/v1alpha1"
+	commonutils "github.com/Fl0rencess720/agentland/pkg/common/utils"
+)
+
+func TestCreatePoolPodImagePullWarming(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add corev1 scheme: %v", err)
+	}
+	if err := agentlandv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add agentland scheme: %v", err)
+	}
+
+	pool := &agentlandv1alpha1.SandboxPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool-prepull", Namespace: "agentland-sandboxes", UID: types.UID("pool-uid")},
+		Spec: agentlandv1alpha1.SandboxPoolSpec{
+			Profile:  "default",
+			Template: &agentlandv1alpha1.SandboxTemplate{Image: "korokd:test", Args: []string{"--port=1883"}},
+			Warming: &agentlandv1alpha1.SandboxPoolWarming{
+				Strategy:   agentlandv1alpha1.WarmingStrategyImagePull,
+				PauseImage: "pause:test",
+			},
+		},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pool.DeepCopy()).Build()
+	if err := (&SandboxPoolReconciler{Client: cli, Scheme: scheme}).createPoolPod(context.Background(), pool); err != nil {
+		t.Fatalf("createPoolPod: %v", err)
+	}
+
+	pods := &corev1.PodList{}
+	if err := cli.List(context.Background(), pods); err != nil {
+		t.Fatalf("list pods: %v", err)
+	}
+	if len(pods.Items) != 1 {
+		t.Fatalf("expected 1 pod, got %d", len(pods.Items))
+	}
+	pod := &pods.Items[0]
+	if len(pod.Spec.InitContainers) != 1 {
+		t.Fatalf("expected the image warmup init container, got %+v", pod.Spec.InitContainers)
+	}
+	initContainer := pod.Spec.InitContainers[0]
+	if initContainer.Name != warmPullContainerName || initContainer.Image != "korokd:test" ||
+		len(initContainer.Command) != 1 || initContainer.Command[0] != "true" {
+		t.Fatalf("init container must pull the sandbox image, got %+v", initContainer)
+	}
+	main := pod.Spec.Containers[0]
+	if main.Image != "pause:test" {
+		t.Fatalf("main container image = %q, want pause:test", main.Image)
+	}
+	if got := pod.Annotations[commonutils.WarmImageAnnotation]; got != "korokd:test" {
+		t.Fatalf("warm image annotation = %q, want korokd:test", got)
+	}
+	if main.LivenessProbe != nil {
+		t.Fatalf("paused container must not carry a liveness probe")
+	}
+	if main.ReadinessProbe == nil {
+		t.Fatalf("readiness probe must be kept for the adopted workload")
+	}
+
+	if isWarmPodAvailable(pod) {
+		t.Fatalf("pod must not be available before the image is pulled")
+	}
+	pod.Status.Phase = corev1.PodRunning
+	pod.Status.InitContainerStatuses = []corev1.ContainerStatus{{
+		Name:  warmPullContainerName,
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}},
+	}}
+	if !isWarmPodAvailable(pod) {
+		t.Fatalf("pod must be available once the image is pulled")
+	}
+
+	claim := &agentlandv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "session-prepull", Namespace: pool.Namespace, UID: types.UID("claim-uid")},
+		Spec:       agentlandv1alpha1.SandboxClaimSpec{PoolRef: pool.Name},
+	}
+	claimReconciler := &SandboxClaimReconciler{Client: cli, Scheme: scheme, Tracker: NewPoolClaimTracker()}
+	if err := claimReconciler.adoptWarmPod(context.Background(), claim, pod); err != nil {
+		t.Fatalf("adoptWarmPod: %v", err)
+	}
+	adopted := &corev1.Pod{}
+	if err := cli.Get(context.Background(), types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, adopted); err != nil {
+		t.Fatalf("get adopted pod: %v", err)
+	}
+	if adopted.Spec.Containers[0].Image != "korokd:test" {
+		t.Fatalf("adopted pod must run the sandbox image, got %q", adopted.Spec.Containers[0].Image)
+	}
+	if _, ok := adopted.Annotations[commonutils.WarmImageAnnotation]; ok {
+		t.Fatalf("warm image annotation must be cleared on adoption")
+	}
+
+	pool.Spec.Template.Command = []string{"/app/entrypoint.sh"}
+	if err := (&SandboxPoolReconciler{Client: cli, Scheme: scheme}).createPoolPod(context.Background(), pool); err == nil {
+		t.Fatalf("image pull warming must reject a template command")
+	}
+}
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	agentlandv1alpha1 "github.com/Fl0rencess720/agentland/api/v1alpha1"
+	commonutils "github.com/Fl0rencess720/agentland/pkg/common/utils"
+)
+
+const (
+	agentSessionFinalizer    = "agentsession.finalizers.agentland.fl0rencess720.app"
+	codeInterpreterFinalizer = "codeinterpreter.finalizers.agentland.fl0rencess720.app"
+)
+
+// SessionStore 为会话删除时需要同步清理的会话存储（Redis 中的路由信息与活跃索引）。
+type SessionStore interface {
+	DeleteSession(ctx context.Context, sessionID string) error
+}
+
+// ensureFinalizer 为会话资源注册 finalizer，返回是否发生了更新。
+func ensureFinalizer(ctx context.Context, c client.Client, obj client.Object, finalizer string) (bool, error) {
+	if controllerutil.ContainsFinalizer(obj, finalizer) {
+		return false, nil
+	}
+	controllerutil.AddFinalizer(obj, finalizer)
+	return true, c.Update(ctx, obj)
+}
+
+// finalizeSession 删除会话拥有的 SandboxClaim 与 Sandbox，待其全部消失后清理会话存储，最后移除 finalizer。
+func finalizeSession(ctx context.Context, c client.Client, store SessionStore, obj client.Object, finalizer string) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(obj, finalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	pending := false
+	key := client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	for _, child := range []client.Object{&agentlandv1alpha1.SandboxClaim{}, &agentlandv1alpha1.Sandbox{}} {
+		if err := c.Get(ctx, key, child); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return ctrl.Result{}, err
+		}
+		if !metav1.IsControlledBy(child, obj) {
+			continue
+		}
+		pending = true
+		if !child.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		if err := c.Delete(ctx, child, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+	}
+	if pending {
+		return ctrl.Result{RequeueAfter: commonutils.FallbackRequeueInterval}, nil
+	}
+
+	if store != nil {
+		if err := store.DeleteSession(ctx, obj.GetName()); err != nil {
+			return ctrl.Result{}, fmt.Errorf("delete session %s from store failed: %w", obj.GetName(), err)
+		}
+	}
+
+	controllerutil.RemoveFinalizer(obj, finalizer)
+	if err := c.Update(ctx, obj); err != nil && !errors.IsNotFound(err) {
+		if errors.IsConflict(err) {
+			return ctrl.Result{RequeueAfter: commonutils.ConflictRequeueInterval}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
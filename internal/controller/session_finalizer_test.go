@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	agentlandv1alpha1 "github.com/Fl0rencess720/agentland/api/v1alpha1"
+)
+
+type fakeSessionStore struct {
+	err     error
+	deleted []string
+}
+
+func (f *fakeSessionStore) DeleteSession(_ context.Context, sessionID string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.deleted = append(f.deleted, sessionID)
+	return nil
+}
+
+func TestCodeInterpreterFinalizerBlocksDeletionUntilCleanup(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add corev1 scheme: %v", err)
+	}
+	if err := agentlandv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add agentland scheme: %v", err)
+	}
+
+	ci := &agentlandv1alpha1.CodeInterpreter{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "session-1",
+			Namespace: "agentland-sandboxes",
+			UID:       types.UID("ci-uid"),
+		},
+		Spec: agentlandv1alpha1.CodeInterpreterSpec{
+			Template: &agentlandv1alpha1.SandboxTemplate{Image: "korokd:test"},
+		},
+	}
+	cli := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(ci).
+		WithStatusSubresource(&agentlandv1alpha1.CodeInterpreter{}, &agentlandv1alpha1.Sandbox{}).
+		Build()
+	store := &fakeSessionStore{err: fmt.Errorf("redis unavailable")}
+	r := &CodeInterpreterReconciler{Client: cli, Scheme: scheme, SessionStore: store}
+	key := client.ObjectKeyFromObject(ci)
+	ctx := context.Background()
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	got := &agentlandv1alpha1.CodeInterpreter{}
+	if err := cli.Get(ctx, key, got); err != nil {
+		t.Fatalf("get code interpreter: %v", err)
+	}
+	if !controllerutil.ContainsFinalizer(got, codeInterpreterFinalizer) {
+		t.Fatalf("finalizer must be registered on create")
+	}
+	if err := cli.Get(ctx, key, &agentlandv1alpha1.Sandbox{}); err != nil {
+		t.Fatalf("sandbox must be created: %v", err)
+	}
+
+	if err := cli.Delete(ctx, got); err != nil {
+		t.Fatalf("delete code interpreter: %v", err)
+	}
+	// 第一轮删除下游 Sandbox，第二轮清理会话存储失败，finalizer 仍阻止删除
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile deleting: %v", err)
+	}
+	if err := cli.Get(ctx, key, &agentlandv1alpha1.Sandbox{}); !errors.IsNotFound(err) {
+		t.Fatalf("owned sandbox must be deleted, got err=%v", err)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err == nil {
+		t.Fatalf("Reconcile must fail while session store cleanup fails")
+	}
+	if err := cli.Get(ctx, key, got); err != nil {
+		t.Fatalf("code interpreter must still exist before cleanup completes: %v", err)
+	}
+	if got.DeletionTimestamp.IsZero() || !controllerutil.ContainsFinalizer(got, codeInterpreterFinalizer) {
+		t.Fatalf("code interpreter must be terminating with finalizer kept")
+	}
+
+	store.err = nil
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile after store recovered: %v", err)
+	}
+	if err := cli.Get(ctx, key, got); !errors.IsNotFound(err) {
+		t.Fatalf("code interpreter must be removed after cleanup, got err=%v", err)
+	}
+	if len(store.deleted) != 1 || store.deleted[0] != ci.Name {
+		t.Fatalf("session store cleanup mismatch: %v", store.deleted)
+	}
+}
+
+func TestAgentSessionFinalizerDeletesClaim(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add corev1 scheme: %v", err)
+	}
+	if err := agentlandv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add agentland scheme: %v", err)
+	}
+
+	session := &agentlandv1alpha1.AgentSession{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "session-2",
+			Namespace:  "agentland-sandboxes",
+			UID:        types.UID("session-uid"),
+			Finalizers: []string{agentSessionFinalizer},
+		},
+	}
+	claim := &agentlandv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      session.Name,
+			Namespace: session.Namespace,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: agentlandv1alpha1.GroupVersion.String(),
+				Kind:       "AgentSession",
+				Name:       session.Name,
+				UID:        session.UID,
+				Controller: boolPtr(true),
+			}},
+		},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(session, claim).Build()
+	store := &fakeSessionStore{}
+	r := &AgentSessionReconciler{Client: cli, Scheme: scheme, SessionStore: store}
+	key := client.ObjectKeyFromObject(session)
+	ctx := context.Background()
+
+	if err := cli.Delete(ctx, session.DeepCopy()); err != nil {
+		t.Fatalf("delete agent session: %v", err)
+	}
+	for range 2 {
+		if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("Reconcile: %v", err)
+		}
+	}
+
+	if err := cli.Get(ctx, key, &agentlandv1alpha1.SandboxClaim{}); !errors.IsNotFound(err) {
+		t.Fatalf("owned claim must be deleted, got err=%v", err)
+	}
+	if err := cli.Get(ctx, key, &agentlandv1alpha1.AgentSession{}); !errors.IsNotFound(err) {
+		t.Fatalf("agent session must be removed after cleanup, got err=%v", err)
+	}
+	if len(store.deleted) != 1 || store.deleted[0] != session.Name {
+		t.Fatalf("session store cleanup mismatch: %v", store.deleted)
+	}
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.35.1
+// 	protoc-gen-go v1.36.9
 // 	protoc        v5.28.3
 // source: idl/agentcore.proto
 
@@ -11,6 +11,7 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -21,9 +22,10 @@ const (
 )
 
 type CreateSandboxRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Owner         string                 `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateSandboxRequest) Reset() {
@@ -56,13 +58,19 @@ func (*CreateSandboxRequest) Descriptor() ([]byte, []int) {
 	return file_idl_agentcore_proto_rawDescGZIP(), []int{0}
 }
 
+func (x *CreateSandboxRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
 type CreateSandboxResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	GrpcEndpoint  string                 `protobuf:"bytes,2,opt,name=grpc_endpoint,json=grpcEndpoint,proto3" json:"grpc_endpoint,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	SandboxId    string `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
-	GrpcEndpoint string `protobuf:"bytes,2,opt,name=grpc_endpoint,json=grpcEndpoint,proto3" json:"grpc_endpoint,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateSandboxResponse) Reset() {
@@ -109,18 +117,235 @@ func (x *CreateSandboxResponse) GetGrpcEndpoint() string {
 	return ""
 }
 
-type CreateAgentSessionRequest struct {
-	state         protoimpl.MessageState
+type GetSandboxRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSandboxRequest) Reset() {
+	*x = GetSandboxRequest{}
+	mi := &file_idl_agentcore_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSandboxRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSandboxRequest) ProtoMessage() {}
+
+func (x *GetSandboxRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_idl_agentcore_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSandboxRequest.ProtoReflect.Descriptor instead.
+func (*GetSandboxRequest) Descriptor() ([]byte, []int) {
+	return file_idl_agentcore_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetSandboxRequest) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+type GetSandboxResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	Phase         string                 `protobuf:"bytes,2,opt,name=phase,proto3" json:"phase,omitempty"`
+	PodIp         string                 `protobuf:"bytes,3,opt,name=pod_ip,json=podIp,proto3" json:"pod_ip,omitempty"`
+	ClaimName     string                 `protobuf:"bytes,4,opt,name=claim_name,json=claimName,proto3" json:"claim_name,omitempty"`
+	SandboxName   string                 `protobuf:"bytes,5,opt,name=sandbox_name,json=sandboxName,proto3" json:"sandbox_name,omitempty"`
+	Reason        string                 `protobuf:"bytes,6,opt,name=reason,proto3" json:"reason,omitempty"`
+	Message       string                 `protobuf:"bytes,7,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSandboxResponse) Reset() {
+	*x = GetSandboxResponse{}
+	mi := &file_idl_agentcore_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSandboxResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSandboxResponse) ProtoMessage() {}
+
+func (x *GetSandboxResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_idl_agentcore_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSandboxResponse.ProtoReflect.Descriptor instead.
+func (*GetSandboxResponse) Descriptor() ([]byte, []int) {
+	return file_idl_agentcore_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetSandboxResponse) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+func (x *GetSandboxResponse) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+func (x *GetSandboxResponse) GetPodIp() string {
+	if x != nil {
+		return x.PodIp
+	}
+	return ""
+}
+
+func (x *GetSandboxResponse) GetClaimName() string {
+	if x != nil {
+		return x.ClaimName
+	}
+	return ""
+}
+
+func (x *GetSandboxResponse) GetSandboxName() string {
+	if x != nil {
+		return x.SandboxName
+	}
+	return ""
+}
+
+func (x *GetSandboxResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *GetSandboxResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type DeleteSandboxRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSandboxRequest) Reset() {
+	*x = DeleteSandboxRequest{}
+	mi := &file_idl_agentcore_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSandboxRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSandboxRequest) ProtoMessage() {}
+
+func (x *DeleteSandboxRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_idl_agentcore_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSandboxRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSandboxRequest) Descriptor() ([]byte, []int) {
+	return file_idl_agentcore_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DeleteSandboxRequest) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+type DeleteSandboxResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-	RuntimeName      string `protobuf:"bytes,1,opt,name=runtime_name,json=runtimeName,proto3" json:"runtime_name,omitempty"`
-	RuntimeNamespace string `protobuf:"bytes,2,opt,name=runtime_namespace,json=runtimeNamespace,proto3" json:"runtime_namespace,omitempty"`
+func (x *DeleteSandboxResponse) Reset() {
+	*x = DeleteSandboxResponse{}
+	mi := &file_idl_agentcore_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSandboxResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSandboxResponse) ProtoMessage() {}
+
+func (x *DeleteSandboxResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_idl_agentcore_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSandboxResponse.ProtoReflect.Descriptor instead.
+func (*DeleteSandboxResponse) Descriptor() ([]byte, []int) {
+	return file_idl_agentcore_proto_rawDescGZIP(), []int{5}
+}
+
+type CreateAgentSessionRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	RuntimeName      string                 `protobuf:"bytes,1,opt,name=runtime_name,json=runtimeName,proto3" json:"runtime_name,omitempty"`
+	RuntimeNamespace string                 `protobuf:"bytes,2,opt,name=runtime_namespace,json=runtimeNamespace,proto3" json:"runtime_namespace,omitempty"`
+	Owner            string                 `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`
+	IdempotencyKey   string                 `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *CreateAgentSessionRequest) Reset() {
 	*x = CreateAgentSessionRequest{}
-	mi := &file_idl_agentcore_proto_msgTypes[2]
+	mi := &file_idl_agentcore_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -132,7 +357,7 @@ func (x *CreateAgentSessionRequest) String() string {
 func (*CreateAgentSessionRequest) ProtoMessage() {}
 
 func (x *CreateAgentSessionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_idl_agentcore_proto_msgTypes[2]
+	mi := &file_idl_agentcore_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -145,7 +370,7 @@ func (x *CreateAgentSessionRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateAgentSessionRequest.ProtoReflect.Descriptor instead.
 func (*CreateAgentSessionRequest) Descriptor() ([]byte, []int) {
-	return file_idl_agentcore_proto_rawDescGZIP(), []int{2}
+	return file_idl_agentcore_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *CreateAgentSessionRequest) GetRuntimeName() string {
@@ -162,18 +387,31 @@ func (x *CreateAgentSessionRequest) GetRuntimeNamespace() string {
 	return ""
 }
 
+func (x *CreateAgentSessionRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *CreateAgentSessionRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
 type CreateAgentSessionResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	GrpcEndpoint  string                 `protobuf:"bytes,2,opt,name=grpc_endpoint,json=grpcEndpoint,proto3" json:"grpc_endpoint,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	SessionId    string `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	GrpcEndpoint string `protobuf:"bytes,2,opt,name=grpc_endpoint,json=grpcEndpoint,proto3" json:"grpc_endpoint,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateAgentSessionResponse) Reset() {
 	*x = CreateAgentSessionResponse{}
-	mi := &file_idl_agentcore_proto_msgTypes[3]
+	mi := &file_idl_agentcore_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -185,7 +423,7 @@ func (x *CreateAgentSessionResponse) String() string {
 func (*CreateAgentSessionResponse) ProtoMessage() {}
 
 func (x *CreateAgentSessionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_idl_agentcore_proto_msgTypes[3]
+	mi := &file_idl_agentcore_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -198,7 +436,7 @@ func (x *CreateAgentSessionResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateAgentSessionResponse.ProtoReflect.Descriptor instead.
 func (*CreateAgentSessionResponse) Descriptor() ([]byte, []int) {
-	return file_idl_agentcore_proto_rawDescGZIP(), []int{3}
+	return file_idl_agentcore_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *CreateAgentSessionResponse) GetSessionId() string {
@@ -216,16 +454,15 @@ func (x *CreateAgentSessionResponse) GetGrpcEndpoint() string {
 }
 
 type GetAgentSessionRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	SessionId string `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetAgentSessionRequest) Reset() {
 	*x = GetAgentSessionRequest{}
-	mi := &file_idl_agentcore_proto_msgTypes[4]
+	mi := &file_idl_agentcore_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -237,7 +474,7 @@ func (x *GetAgentSessionRequest) String() string {
 func (*GetAgentSessionRequest) ProtoMessage() {}
 
 func (x *GetAgentSessionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_idl_agentcore_proto_msgTypes[4]
+	mi := &file_idl_agentcore_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -250,7 +487,7 @@ func (x *GetAgentSessionRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetAgentSessionRequest.ProtoReflect.Descriptor instead.
 func (*GetAgentSessionRequest) Descriptor() ([]byte, []int) {
-	return file_idl_agentcore_proto_rawDescGZIP(), []int{4}
+	return file_idl_agentcore_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *GetAgentSessionRequest) GetSessionId() string {
@@ -261,17 +498,17 @@ func (x *GetAgentSessionRequest) GetSessionId() string {
 }
 
 type GetAgentSessionResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	GrpcEndpoint  string                 `protobuf:"bytes,2,opt,name=grpc_endpoint,json=grpcEndpoint,proto3" json:"grpc_endpoint,omitempty"`
+	Ports         []uint32               `protobuf:"varint,3,rep,packed,name=ports,proto3" json:"ports,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	SessionId    string `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	GrpcEndpoint string `protobuf:"bytes,2,opt,name=grpc_endpoint,json=grpcEndpoint,proto3" json:"grpc_endpoint,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetAgentSessionResponse) Reset() {
 	*x = GetAgentSessionResponse{}
-	mi := &file_idl_agentcore_proto_msgTypes[5]
+	mi := &file_idl_agentcore_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -283,7 +520,7 @@ func (x *GetAgentSessionResponse) String() string {
 func (*GetAgentSessionResponse) ProtoMessage() {}
 
 func (x *GetAgentSessionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_idl_agentcore_proto_msgTypes[5]
+	mi := &file_idl_agentcore_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -296,7 +533,7 @@ func (x *GetAgentSessionResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetAgentSessionResponse.ProtoReflect.Descriptor instead.
 func (*GetAgentSessionResponse) Descriptor() ([]byte, []int) {
-	return file_idl_agentcore_proto_rawDescGZIP(), []int{5}
+	return file_idl_agentcore_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *GetAgentSessionResponse) GetSessionId() string {
@@ -313,17 +550,23 @@ func (x *GetAgentSessionResponse) GetGrpcEndpoint() string {
 	return ""
 }
 
+func (x *GetAgentSessionResponse) GetPorts() []uint32 {
+	if x != nil {
+		return x.Ports
+	}
+	return nil
+}
+
 type DeleteAgentSessionRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	SessionId string `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DeleteAgentSessionRequest) Reset() {
 	*x = DeleteAgentSessionRequest{}
-	mi := &file_idl_agentcore_proto_msgTypes[6]
+	mi := &file_idl_agentcore_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -335,7 +578,7 @@ func (x *DeleteAgentSessionRequest) String() string {
 func (*DeleteAgentSessionRequest) ProtoMessage() {}
 
 func (x *DeleteAgentSessionRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_idl_agentcore_proto_msgTypes[6]
+	mi := &file_idl_agentcore_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -348,7 +591,7 @@ func (x *DeleteAgentSessionRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteAgentSessionRequest.ProtoReflect.Descriptor instead.
 func (*DeleteAgentSessionRequest) Descriptor() ([]byte, []int) {
-	return file_idl_agentcore_proto_rawDescGZIP(), []int{6}
+	return file_idl_agentcore_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *DeleteAgentSessionRequest) GetSessionId() string {
@@ -359,14 +602,14 @@ func (x *DeleteAgentSessionRequest) GetSessionId() string {
 }
 
 type DeleteAgentSessionResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DeleteAgentSessionResponse) Reset() {
 	*x = DeleteAgentSessionResponse{}
-	mi := &file_idl_agentcore_proto_msgTypes[7]
+	mi := &file_idl_agentcore_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -378,7 +621,7 @@ func (x *DeleteAgentSessionResponse) String() string {
 func (*DeleteAgentSessionResponse) ProtoMessage() {}
 
 func (x *DeleteAgentSessionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_idl_agentcore_proto_msgTypes[7]
+	mi := &file_idl_agentcore_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -391,21 +634,20 @@ func (x *DeleteAgentSessionResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteAgentSessionResponse.ProtoReflect.Descriptor instead.
 func (*DeleteAgentSessionResponse) Descriptor() ([]byte, []int) {
-	return file_idl_agentcore_proto_rawDescGZIP(), []int{7}
+	return file_idl_agentcore_proto_rawDescGZIP(), []int{11}
 }
 
 type ExecuteCodeRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Language      string                 `protobuf:"bytes,1,opt,name=language,proto3" json:"language,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Language string `protobuf:"bytes,1,opt,name=language,proto3" json:"language,omitempty"`
-	Code     string `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ExecuteCodeRequest) Reset() {
 	*x = ExecuteCodeRequest{}
-	mi := &file_idl_agentcore_proto_msgTypes[8]
+	mi := &file_idl_agentcore_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -417,7 +659,7 @@ func (x *ExecuteCodeRequest) String() string {
 func (*ExecuteCodeRequest) ProtoMessage() {}
 
 func (x *ExecuteCodeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_idl_agentcore_proto_msgTypes[8]
+	mi := &file_idl_agentcore_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -430,7 +672,7 @@ func (x *ExecuteCodeRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExecuteCodeRequest.ProtoReflect.Descriptor instead.
 func (*ExecuteCodeRequest) Descriptor() ([]byte, []int) {
-	return file_idl_agentcore_proto_rawDescGZIP(), []int{8}
+	return file_idl_agentcore_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *ExecuteCodeRequest) GetLanguage() string {
@@ -448,18 +690,17 @@ func (x *ExecuteCodeRequest) GetCode() string {
 }
 
 type ExecuteCodeResponse struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExitCode      int32                  `protobuf:"varint,1,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	Stdout        string                 `protobuf:"bytes,2,opt,name=stdout,proto3" json:"stdout,omitempty"`
+	Stderr        string                 `protobuf:"bytes,3,opt,name=stderr,proto3" json:"stderr,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	ExitCode int32  `protobuf:"varint,1,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
-	Stdout   string `protobuf:"bytes,2,opt,name=stdout,proto3" json:"stdout,omitempty"`
-	Stderr   string `protobuf:"bytes,3,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ExecuteCodeResponse) Reset() {
 	*x = ExecuteCodeResponse{}
-	mi := &file_idl_agentcore_proto_msgTypes[9]
+	mi := &file_idl_agentcore_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -471,7 +712,7 @@ func (x *ExecuteCodeResponse) String() string {
 func (*ExecuteCodeResponse) ProtoMessage() {}
 
 func (x *ExecuteCodeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_idl_agentcore_proto_msgTypes[9]
+	mi := &file_idl_agentcore_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -484,7 +725,7 @@ func (x *ExecuteCodeResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExecuteCodeResponse.ProtoReflect.Descriptor instead.
 func (*ExecuteCodeResponse) Descriptor() ([]byte, []int) {
-	return file_idl_agentcore_proto_rawDescGZIP(), []int{9}
+	return file_idl_agentcore_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *ExecuteCodeResponse) GetExitCode() int32 {
@@ -510,143 +751,119 @@ func (x *ExecuteCodeResponse) GetStderr() string {
 
 var File_idl_agentcore_proto protoreflect.FileDescriptor
 
-var file_idl_agentcore_proto_rawDesc = []byte{
-	0x0a, 0x13, 0x69, 0x64, 0x6c, 0x2f, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x63, 0x6f, 0x72, 0x65, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x16, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x6c, 0x61, 0x6e, 0x64,
-	0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x22, 0x16, 0x0a,
-	0x14, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x5b, 0x0a, 0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53,
-	0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d,
-	0x0a, 0x0a, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x64, 0x12, 0x23, 0x0a,
-	0x0d, 0x67, 0x72, 0x70, 0x63, 0x5f, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x67, 0x72, 0x70, 0x63, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69,
-	0x6e, 0x74, 0x22, 0x6b, 0x0a, 0x19, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x41, 0x67, 0x65, 0x6e,
-	0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x21, 0x0a, 0x0c, 0x72, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x72, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x4e, 0x61,
-	0x6d, 0x65, 0x12, 0x2b, 0x0a, 0x11, 0x72, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6e, 0x61,
-	0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x72,
-	0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x22,
-	0x60, 0x0a, 0x1a, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x53, 0x65,
-	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a,
-	0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x0d,
-	0x67, 0x72, 0x70, 0x63, 0x5f, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x0c, 0x67, 0x72, 0x70, 0x63, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e,
-	0x74, 0x22, 0x37, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x73,
-	0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73,
-	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x22, 0x5d, 0x0a, 0x17, 0x47, 0x65,
-	0x74, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
-	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69,
-	0x6f, 0x6e, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x67, 0x72, 0x70, 0x63, 0x5f, 0x65, 0x6e, 0x64,
-	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x67, 0x72, 0x70,
-	0x63, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x22, 0x3a, 0x0a, 0x19, 0x44, 0x65, 0x6c,
-	0x65, 0x74, 0x65, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f,
-	0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73,
-	0x69, 0x6f, 0x6e, 0x49, 0x64, 0x22, 0x1c, 0x0a, 0x1a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x41,
-	0x67, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x22, 0x44, 0x0a, 0x12, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x43, 0x6f,
-	0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x6e,
-	0x67, 0x75, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x61, 0x6e,
-	0x67, 0x75, 0x61, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x22, 0x62, 0x0a, 0x13, 0x45, 0x78, 0x65,
-	0x63, 0x75, 0x74, 0x65, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x1b, 0x0a, 0x09, 0x65, 0x78, 0x69, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x05, 0x52, 0x08, 0x65, 0x78, 0x69, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x16, 0x0a,
-	0x06, 0x73, 0x74, 0x64, 0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73,
-	0x74, 0x64, 0x6f, 0x75, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x64, 0x65, 0x72, 0x72, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x64, 0x65, 0x72, 0x72, 0x32, 0xf6, 0x03,
-	0x0a, 0x10, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x72, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69,
-	0x63, 0x65, 0x12, 0x74, 0x0a, 0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x64, 0x65,
-	0x49, 0x6e, 0x74, 0x65, 0x72, 0x70, 0x72, 0x65, 0x74, 0x65, 0x72, 0x12, 0x2c, 0x2e, 0x61, 0x67,
-	0x65, 0x6e, 0x74, 0x6c, 0x61, 0x6e, 0x64, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x63, 0x6f, 0x72,
-	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x61, 0x6e, 0x64, 0x62,
-	0x6f, 0x78, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2d, 0x2e, 0x61, 0x67, 0x65, 0x6e,
-	0x74, 0x6c, 0x61, 0x6e, 0x64, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x63, 0x6f, 0x72, 0x65, 0x2e,
-	0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x7b, 0x0a, 0x12, 0x43, 0x72, 0x65, 0x61,
-	0x74, 0x65, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x31,
-	0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x6c, 0x61, 0x6e, 0x64, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74,
-	0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x41, 0x67,
-	0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x32, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x6c, 0x61, 0x6e, 0x64, 0x2e, 0x61, 0x67,
-	0x65, 0x6e, 0x74, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74,
-	0x65, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x72, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x41, 0x67, 0x65, 0x6e,
-	0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2e, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74,
-	0x6c, 0x61, 0x6e, 0x64, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76,
-	0x31, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
-	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2f, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74,
-	0x6c, 0x61, 0x6e, 0x64, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76,
-	0x31, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
-	0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x7b, 0x0a, 0x12, 0x44, 0x65, 0x6c,
-	0x65, 0x74, 0x65, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12,
-	0x31, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x6c, 0x61, 0x6e, 0x64, 0x2e, 0x61, 0x67, 0x65, 0x6e,
-	0x74, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x41,
-	0x67, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x32, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x6c, 0x61, 0x6e, 0x64, 0x2e, 0x61,
-	0x67, 0x65, 0x6e, 0x74, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65,
-	0x74, 0x65, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0x78, 0x0a, 0x0e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f,
-	0x78, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x66, 0x0a, 0x0b, 0x45, 0x78, 0x65, 0x63,
-	0x75, 0x74, 0x65, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x2a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x6c,
-	0x61, 0x6e, 0x64, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31,
-	0x2e, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x2b, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x6c, 0x61, 0x6e, 0x64, 0x2e,
-	0x61, 0x67, 0x65, 0x6e, 0x74, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x65,
-	0x63, 0x75, 0x74, 0x65, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x42, 0x3b, 0x5a, 0x39, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x46,
-	0x6c, 0x30, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x73, 0x37, 0x32, 0x30, 0x2f, 0x61, 0x67, 0x65,
-	0x6e, 0x74, 0x6c, 0x61, 0x6e, 0x64, 0x2f, 0x70, 0x62, 0x2f, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x63,
-	0x6f, 0x72, 0x65, 0x3b, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x63, 0x6f, 0x72, 0x65, 0x62, 0x06, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x33,
-}
+const file_idl_agentcore_proto_rawDesc = "" +
+	"\n" +
+	"\x13idl/agentcore.proto\x12\x16agentland.agentcore.v1\",\n" +
+	"\x14CreateSandboxRequest\x12\x14\n" +
+	"\x05owner\x18\x01 \x01(\tR\x05owner\"[\n" +
+	"\x15CreateSandboxResponse\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12#\n" +
+	"\rgrpc_endpoint\x18\x02 \x01(\tR\fgrpcEndpoint\"2\n" +
+	"\x11GetSandboxRequest\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\"\xd4\x01\n" +
+	"\x12GetSandboxResponse\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12\x14\n" +
+	"\x05phase\x18\x02 \x01(\tR\x05phase\x12\x15\n" +
+	"\x06pod_ip\x18\x03 \x01(\tR\x05podIp\x12\x1d\n" +
+	"\n" +
+	"claim_name\x18\x04 \x01(\tR\tclaimName\x12!\n" +
+	"\fsandbox_name\x18\x05 \x01(\tR\vsandboxName\x12\x16\n" +
+	"\x06reason\x18\x06 \x01(\tR\x06reason\x12\x18\n" +
+	"\amessage\x18\a \x01(\tR\amessage\"5\n" +
+	"\x14DeleteSandboxRequest\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\"\x17\n" +
+	"\x15DeleteSandboxResponse\"\xaa\x01\n" +
+	"\x19CreateAgentSessionRequest\x12!\n" +
+	"\fruntime_name\x18\x01 \x01(\tR\vruntimeName\x12+\n" +
+	"\x11runtime_namespace\x18\x02 \x01(\tR\x10runtimeNamespace\x12\x14\n" +
+	"\x05owner\x18\x03 \x01(\tR\x05owner\x12'\n" +
+	"\x0fidempotency_key\x18\x04 \x01(\tR\x0eidempotencyKey\"`\n" +
+	"\x1aCreateAgentSessionResponse\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12#\n" +
+	"\rgrpc_endpoint\x18\x02 \x01(\tR\fgrpcEndpoint\"7\n" +
+	"\x16GetAgentSessionRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"s\n" +
+	"\x17GetAgentSessionResponse\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12#\n" +
+	"\rgrpc_endpoint\x18\x02 \x01(\tR\fgrpcEndpoint\x12\x14\n" +
+	"\x05ports\x18\x03 \x03(\rR\x05ports\":\n" +
+	"\x19DeleteAgentSessionRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"\x1c\n" +
+	"\x1aDeleteAgentSessionResponse\"D\n" +
+	"\x12ExecuteCodeRequest\x12\x1a\n" +
+	"\blanguage\x18\x01 \x01(\tR\blanguage\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\"b\n" +
+	"\x13ExecuteCodeResponse\x12\x1b\n" +
+	"\texit_code\x18\x01 \x01(\x05R\bexitCode\x12\x16\n" +
+	"\x06stdout\x18\x02 \x01(\tR\x06stdout\x12\x16\n" +
+	"\x06stderr\x18\x03 \x01(\tR\x06stderr2\xd9\x05\n" +
+	"\x10AgentCoreService\x12t\n" +
+	"\x15CreateCodeInterpreter\x12,.agentland.agentcore.v1.CreateSandboxRequest\x1a-.agentland.agentcore.v1.CreateSandboxResponse\x12k\n" +
+	"\x12GetCodeInterpreter\x12).agentland.agentcore.v1.GetSandboxRequest\x1a*.agentland.agentcore.v1.GetSandboxResponse\x12t\n" +
+	"\x15DeleteCodeInterpreter\x12,.agentland.agentcore.v1.DeleteSandboxRequest\x1a-.agentland.agentcore.v1.DeleteSandboxResponse\x12{\n" +
+	"\x12CreateAgentSession\x121.agentland.agentcore.v1.CreateAgentSessionRequest\x1a2.agentland.agentcore.v1.CreateAgentSessionResponse\x12r\n" +
+	"\x0fGetAgentSession\x12..agentland.agentcore.v1.GetAgentSessionRequest\x1a/.agentland.agentcore.v1.GetAgentSessionResponse\x12{\n" +
+	"\x12DeleteAgentSession\x121.agentland.agentcore.v1.DeleteAgentSessionRequest\x1a2.agentland.agentcore.v1.DeleteAgentSessionResponse2x\n" +
+	"\x0eSandboxService\x12f\n" +
+	"\vExecuteCode\x12*.agentland.agentcore.v1.ExecuteCodeRequest\x1a+.agentland.agentcore.v1.ExecuteCodeResponseB;Z9github.com/Fl0rencess720/agentland/pb/agentcore;agentcoreb\x06proto3"
 
 var (
 	file_idl_agentcore_proto_rawDescOnce sync.Once
-	file_idl_agentcore_proto_rawDescData = file_idl_agentcore_proto_rawDesc
+	file_idl_agentcore_proto_rawDescData []byte
 )
 
 func file_idl_agentcore_proto_rawDescGZIP() []byte {
 	file_idl_agentcore_proto_rawDescOnce.Do(func() {
-		file_idl_agentcore_proto_rawDescData = protoimpl.X.CompressGZIP(file_idl_agentcore_proto_rawDescData)
+		file_idl_agentcore_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_idl_agentcore_proto_rawDesc), len(file_idl_agentcore_proto_rawDesc)))
 	})
 	return file_idl_agentcore_proto_rawDescData
 }
 
-var file_idl_agentcore_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_idl_agentcore_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_idl_agentcore_proto_goTypes = []any{
 	(*CreateSandboxRequest)(nil),       // 0: agentland.agentcore.v1.CreateSandboxRequest
 	(*CreateSandboxResponse)(nil),      // 1: agentland.agentcore.v1.CreateSandboxResponse
-	(*CreateAgentSessionRequest)(nil),  // 2: agentland.agentcore.v1.CreateAgentSessionRequest
-	(*CreateAgentSessionResponse)(nil), // 3: agentland.agentcore.v1.CreateAgentSessionResponse
-	(*GetAgentSessionRequest)(nil),     // 4: agentland.agentcore.v1.GetAgentSessionRequest
-	(*GetAgentSessionResponse)(nil),    // 5: agentland.agentcore.v1.GetAgentSessionResponse
-	(*DeleteAgentSessionRequest)(nil),  // 6: agentland.agentcore.v1.DeleteAgentSessionRequest
-	(*DeleteAgentSessionResponse)(nil), // 7: agentland.agentcore.v1.DeleteAgentSessionResponse
-	(*ExecuteCodeRequest)(nil),         // 8: agentland.agentcore.v1.ExecuteCodeRequest
-	(*ExecuteCodeResponse)(nil),        // 9: agentland.agentcore.v1.ExecuteCodeResponse
+	(*GetSandboxRequest)(nil),          // 2: agentland.agentcore.v1.GetSandboxRequest
+	(*GetSandboxResponse)(nil),         // 3: agentland.agentcore.v1.GetSandboxResponse
+	(*DeleteSandboxRequest)(nil),       // 4: agentland.agentcore.v1.DeleteSandboxRequest
+	(*DeleteSandboxResponse)(nil),      // 5: agentland.agentcore.v1.DeleteSandboxResponse
+	(*CreateAgentSessionRequest)(nil),  // 6: agentland.agentcore.v1.CreateAgentSessionRequest
+	(*CreateAgentSessionResponse)(nil), // 7: agentland.agentcore.v1.CreateAgentSessionResponse
+	(*GetAgentSessionRequest)(nil),     // 8: agentland.agentcore.v1.GetAgentSessionRequest
+	(*GetAgentSessionResponse)(nil),    // 9: agentland.agentcore.v1.GetAgentSessionResponse
+	(*DeleteAgentSessionRequest)(nil),  // 10: agentland.agentcore.v1.DeleteAgentSessionRequest
+	(*DeleteAgentSessionResponse)(nil), // 11: agentland.agentcore.v1.DeleteAgentSessionResponse
+	(*ExecuteCodeRequest)(nil),         // 12: agentland.agentcore.v1.ExecuteCodeRequest
+	(*ExecuteCodeResponse)(nil),        // 13: agentland.agentcore.v1.ExecuteCodeResponse
 }
 var file_idl_agentcore_proto_depIdxs = []int32{
-	0, // 0: agentland.agentcore.v1.AgentCoreService.CreateCodeInterpreter:input_type -> agentland.agentcore.v1.CreateSandboxRequest
-	2, // 1: agentland.agentcore.v1.AgentCoreService.CreateAgentSession:input_type -> agentland.agentcore.v1.CreateAgentSessionRequest
-	4, // 2: agentland.agentcore.v1.AgentCoreService.GetAgentSession:input_type -> agentland.agentcore.v1.GetAgentSessionRequest
-	6, // 3: agentland.agentcore.v1.AgentCoreService.DeleteAgentSession:input_type -> agentland.agentcore.v1.DeleteAgentSessionRequest
-	8, // 4: agentland.agentcore.v1.SandboxService.ExecuteCode:input_type -> agentland.agentcore.v1.ExecuteCodeRequest
-	1, // 5: agentland.agentcore.v1.AgentCoreService.CreateCodeInterpreter:output_type -> agentland.agentcore.v1.CreateSandboxResponse
-	3, // 6: agentland.agentcore.v1.AgentCoreService.CreateAgentSession:output_type -> agentland.agentcore.v1.CreateAgentSessionResponse
-	5, // 7: agentland.agentcore.v1.AgentCoreService.GetAgentSession:output_type -> agentland.agentcore.v1.GetAgentSessionResponse
-	7, // 8: agentland.agentcore.v1.AgentCoreService.DeleteAgentSession:output_type -> agentland.agentcore.v1.DeleteAgentSessionResponse
-	9, // 9: agentland.agentcore.v1.SandboxService.ExecuteCode:output_type -> agentland.agentcore.v1.ExecuteCodeResponse
-	5, // [5:10] is the sub-list for method output_type
-	0, // [0:5] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: agentland.agentcore.v1.AgentCoreService.CreateCodeInterpreter:input_type -> agentland.agentcore.v1.CreateSandboxRequest
+	2,  // 1: agentland.agentcore.v1.AgentCoreService.GetCodeInterpreter:input_type -> agentland.agentcore.v1.GetSandboxRequest
+	4,  // 2: agentland.agentcore.v1.AgentCoreService.DeleteCodeInterpreter:input_type -> agentland.agentcore.v1.DeleteSandboxRequest
+	6,  // 3: agentland.agentcore.v1.AgentCoreService.CreateAgentSession:input_type -> agentland.agentcore.v1.CreateAgentSessionRequest
+	8,  // 4: agentland.agentcore.v1.AgentCoreService.GetAgentSession:input_type -> agentland.agentcore.v1.GetAgentSessionRequest
+	10, // 5: agentland.agentcore.v1.AgentCoreService.DeleteAgentSession:input_type -> agentland.agentcore.v1.DeleteAgentSessionRequest
+	12, // 6: agentland.agentcore.v1.SandboxService.ExecuteCode:input_type -> agentland.agentcore.v1.ExecuteCodeRequest
+	1,  // 7: agentland.agentcore.v1.AgentCoreService.CreateCodeInterpreter:output_type -> agentland.agentcore.v1.CreateSandboxResponse
+	3,  // 8: agentland.agentcore.v1.AgentCoreService.GetCodeInterpreter:output_type -> agentland.agentcore.v1.GetSandboxResponse
+	5,  // 9: agentland.agentcore.v1.AgentCoreService.DeleteCodeInterpreter:output_type -> agentland.agentcore.v1.DeleteSandboxResponse
+	7,  // 10: agentland.agentcore.v1.AgentCoreService.CreateAgentSession:output_type -> agentland.agentcore.v1.CreateAgentSessionResponse
+	9,  // 11: agentland.agentcore.v1.AgentCoreService.GetAgentSession:output_type -> agentland.agentcore.v1.GetAgentSessionResponse
+	11, // 12: agentland.agentcore.v1.AgentCoreService.DeleteAgentSession:output_type -> agentland.agentcore.v1.DeleteAgentSessionResponse
+	13, // 13: agentland.agentcore.v1.SandboxService.ExecuteCode:output_type -> agentland.agentcore.v1.ExecuteCodeResponse
+	7,  // [7:14] is the sub-list for method output_type
+	0,  // [0:7] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
 }
 
 func init() { file_idl_agentcore_proto_init() }
@@ -658,9 +875,9 @@ func file_idl_agentcore_proto_init() {
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_idl_agentcore_proto_rawDesc,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_idl_agentcore_proto_rawDesc), len(file_idl_agentcore_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   10,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
@@ -669,7 +886,6 @@ func file_idl_agentcore_proto_init() {
 		MessageInfos:      file_idl_agentcore_proto_msgTypes,
 	}.Build()
 	File_idl_agentcore_proto = out.File
-	file_idl_agentcore_proto_rawDesc = nil
 	file_idl_agentcore_proto_goTypes = nil
 	file_idl_agentcore_proto_depIdxs = nil
 }
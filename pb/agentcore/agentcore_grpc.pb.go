@@ -20,6 +20,8 @@ const _ = grpc.SupportPackageIsVersion9
 
 const (
 	AgentCoreService_CreateCodeInterpreter_FullMethodName = "/agentland.agentcore.v1.AgentCoreService/CreateCodeInterpreter"
+	AgentCoreService_GetCodeInterpreter_FullMethodName    = "/agentland.agentcore.v1.AgentCoreService/GetCodeInterpreter"
+	AgentCoreService_DeleteCodeInterpreter_FullMethodName = "/agentland.agentcore.v1.AgentCoreService/DeleteCodeInterpreter"
 	AgentCoreService_CreateAgentSession_FullMethodName    = "/agentland.agentcore.v1.AgentCoreService/CreateAgentSession"
 	AgentCoreService_GetAgentSession_FullMethodName       = "/agentland.agentcore.v1.AgentCoreService/GetAgentSession"
 	AgentCoreService_DeleteAgentSession_FullMethodName    = "/agentland.agentcore.v1.AgentCoreService/DeleteAgentSession"
@@ -30,6 +32,8 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type AgentCoreServiceClient interface {
 	CreateCodeInterpreter(ctx context.Context, in *CreateSandboxRequest, opts ...grpc.CallOption) (*CreateSandboxResponse, error)
+	GetCodeInterpreter(ctx context.Context, in *GetSandboxRequest, opts ...grpc.CallOption) (*GetSandboxResponse, error)
+	DeleteCodeInterpreter(ctx context.Context, in *DeleteSandboxRequest, opts ...grpc.CallOption) (*DeleteSandboxResponse, error)
 	CreateAgentSession(ctx context.Context, in *CreateAgentSessionRequest, opts ...grpc.CallOption) (*CreateAgentSessionResponse, error)
 	GetAgentSession(ctx context.Context, in *GetAgentSessionRequest, opts ...grpc.CallOption) (*GetAgentSessionResponse, error)
 	DeleteAgentSession(ctx context.Context, in *DeleteAgentSessionRequest, opts ...grpc.CallOption) (*DeleteAgentSessionResponse, error)
@@ -53,6 +57,26 @@ func (c *agentCoreServiceClient) CreateCodeInterpreter(ctx context.Context, in *
 	return out, nil
 }
 
+func (c *agentCoreServiceClient) GetCodeInterpreter(ctx context.Context, in *GetSandboxRequest, opts ...grpc.CallOption) (*GetSandboxResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSandboxResponse)
+	err := c.cc.Invoke(ctx, AgentCoreService_GetCodeInterpreter_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentCoreServiceClient) DeleteCodeInterpreter(ctx context.Context, in *DeleteSandboxRequest, opts ...grpc.CallOption) (*DeleteSandboxResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteSandboxResponse)
+	err := c.cc.Invoke(ctx, AgentCoreService_DeleteCodeInterpreter_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *agentCoreServiceClient) CreateAgentSession(ctx context.Context, in *CreateAgentSessionRequest, opts ...grpc.CallOption) (*CreateAgentSessionResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CreateAgentSessionResponse)
@@ -88,6 +112,8 @@ func (c *agentCoreServiceClient) DeleteAgentSession(ctx context.Context, in *Del
 // for forward compatibility.
 type AgentCoreServiceServer interface {
 	CreateCodeInterpreter(context.Context, *CreateSandboxRequest) (*CreateSandboxResponse, error)
+	GetCodeInterpreter(context.Context, *GetSandboxRequest) (*GetSandboxResponse, error)
+	DeleteCodeInterpreter(context.Context, *DeleteSandboxRequest) (*DeleteSandboxResponse, error)
 	CreateAgentSession(context.Context, *CreateAgentSessionRequest) (*CreateAgentSessionResponse, error)
 	GetAgentSession(context.Context, *GetAgentSessionRequest) (*GetAgentSessionResponse, error)
 	DeleteAgentSession(context.Context, *DeleteAgentSessionRequest) (*DeleteAgentSessionResponse, error)
@@ -104,6 +130,12 @@ type UnimplementedAgentCoreServiceServer struct{}
 func (UnimplementedAgentCoreServiceServer) CreateCodeInterpreter(context.Context, *CreateSandboxRequest) (*CreateSandboxResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateCodeInterpreter not implemented")
 }
+func (UnimplementedAgentCoreServiceServer) GetCodeInterpreter(context.Context, *GetSandboxRequest) (*GetSandboxResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCodeInterpreter not implemented")
+}
+func (UnimplementedAgentCoreServiceServer) DeleteCodeInterpreter(context.Context, *DeleteSandboxRequest) (*DeleteSandboxResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCodeInterpreter not implemented")
+}
 func (UnimplementedAgentCoreServiceServer) CreateAgentSession(context.Context, *CreateAgentSessionRequest) (*CreateAgentSessionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateAgentSession not implemented")
 }
@@ -152,6 +184,42 @@ func _AgentCoreService_CreateCodeInterpreter_Handler(srv interface{}, ctx contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentCoreService_GetCodeInterpreter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSandboxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentCoreServiceServer).GetCodeInterpreter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentCoreService_GetCodeInterpreter_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentCoreServiceServer).GetCodeInterpreter(ctx, req.(*GetSandboxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentCoreService_DeleteCodeInterpreter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSandboxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentCoreServiceServer).DeleteCodeInterpreter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentCoreService_DeleteCodeInterpreter_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentCoreServiceServer).DeleteCodeInterpreter(ctx, req.(*DeleteSandboxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _AgentCoreService_CreateAgentSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateAgentSessionRequest)
 	if err := dec(in); err != nil {
@@ -217,6 +285,14 @@ var AgentCoreService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateCodeInterpreter",
 			Handler:    _AgentCoreService_CreateCodeInterpreter_Handler,
 		},
+		{
+			MethodName: "GetCodeInterpreter",
+			Handler:    _AgentCoreService_GetCodeInterpreter_Handler,
+		},
+		{
+			MethodName: "DeleteCodeInterpreter",
+			Handler:    _AgentCoreService_DeleteCodeInterpreter_Handler,
+		},
 		{
 			MethodName: "CreateAgentSession",
 			Handler:    _AgentCoreService_CreateAgentSession_Handler,
@@ -0,0 +1,940 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v5.28.3
+// source: idl/korokd.proto
+
+package korokd
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CreateContextRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Language      string                 `protobuf:"bytes,1,opt,name=language,proto3" json:"language,omitempty"`
+	Cwd           string                 `protobuf:"bytes,2,opt,name=cwd,proto3" json:"cwd,omitempty"`
+	MemBytes      int64                  `protobuf:"varint,3,opt,name=mem_bytes,json=memBytes,proto3" json:"mem_bytes,omitempty"`
+	CpuMillis     int64                  `protobuf:"varint,4,opt,name=cpu_millis,json=cpuMillis,proto3" json:"cpu_millis,omitempty"`
+	Env           map[string]string      `protobuf:"bytes,5,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Parallelism   int32                  `protobuf:"varint,6,opt,name=parallelism,proto3" json:"parallelism,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateContextRequest) Reset() {
+	*x = CreateContextRequest{}
+	mi := &file_idl_korokd_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateContextRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateContextRequest) ProtoMessage() {}
+
+func (x *CreateContextRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_idl_korokd_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateContextRequest.ProtoReflect.Descriptor instead.
+func (*CreateContextRequest) Descriptor() ([]byte, []int) {
+	return file_idl_korokd_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateContextRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *CreateContextRequest) GetCwd() string {
+	if x != nil {
+		return x.Cwd
+	}
+	return ""
+}
+
+func (x *CreateContextRequest) GetMemBytes() int64 {
+	if x != nil {
+		return x.MemBytes
+	}
+	return 0
+}
+
+func (x *CreateContextRequest) GetCpuMillis() int64 {
+	if x != nil {
+		return x.CpuMillis
+	}
+	return 0
+}
+
+func (x *CreateContextRequest) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *CreateContextRequest) GetParallelism() int32 {
+	if x != nil {
+		return x.Parallelism
+	}
+	return 0
+}
+
+type CreateContextResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContextId     string                 `protobuf:"bytes,1,opt,name=context_id,json=contextId,proto3" json:"context_id,omitempty"`
+	Language      string                 `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
+	Cwd           string                 `protobuf:"bytes,3,opt,name=cwd,proto3" json:"cwd,omitempty"`
+	State         string                 `protobuf:"bytes,4,opt,name=state,proto3" json:"state,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateContextResponse) Reset() {
+	*x = CreateContextResponse{}
+	mi := &file_idl_korokd_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateContextResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateContextResponse) ProtoMessage() {}
+
+func (x *CreateContextResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_idl_korokd_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateContextResponse.ProtoReflect.Descriptor instead.
+func (*CreateContextResponse) Descriptor() ([]byte, []int) {
+	return file_idl_korokd_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateContextResponse) GetContextId() string {
+	if x != nil {
+		return x.ContextId
+	}
+	return ""
+}
+
+func (x *CreateContextResponse) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *CreateContextResponse) GetCwd() string {
+	if x != nil {
+		return x.Cwd
+	}
+	return ""
+}
+
+func (x *CreateContextResponse) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *CreateContextResponse) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type ListContextsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListContextsRequest) Reset() {
+	*x = ListContextsRequest{}
+	mi := &file_idl_korokd_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListContextsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListContextsRequest) ProtoMessage() {}
+
+func (x *ListContextsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_idl_korokd_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListContextsRequest.ProtoReflect.Descriptor instead.
+func (*ListContextsRequest) Descriptor() ([]byte, []int) {
+	return file_idl_korokd_proto_rawDescGZIP(), []int{2}
+}
+
+type ListContextsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Contexts      []*ContextInfo         `protobuf:"bytes,1,rep,name=contexts,proto3" json:"contexts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListContextsResponse) Reset() {
+	*x = ListContextsResponse{}
+	mi := &file_idl_korokd_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListContextsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListContextsResponse) ProtoMessage() {}
+
+func (x *ListContextsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_idl_korokd_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListContextsResponse.ProtoReflect.Descriptor instead.
+func (*ListContextsResponse) Descriptor() ([]byte, []int) {
+	return file_idl_korokd_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListContextsResponse) GetContexts() []*ContextInfo {
+	if x != nil {
+		return x.Contexts
+	}
+	return nil
+}
+
+type ContextInfo struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ContextId      string                 `protobuf:"bytes,1,opt,name=context_id,json=contextId,proto3" json:"context_id,omitempty"`
+	Language       string                 `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
+	Cwd            string                 `protobuf:"bytes,3,opt,name=cwd,proto3" json:"cwd,omitempty"`
+	CurrentCwd     string                 `protobuf:"bytes,4,opt,name=current_cwd,json=currentCwd,proto3" json:"current_cwd,omitempty"`
+	KernelId       string                 `protobuf:"bytes,5,opt,name=kernel_id,json=kernelId,proto3" json:"kernel_id,omitempty"`
+	ExecutionCount int64                  `protobuf:"varint,6,opt,name=execution_count,json=executionCount,proto3" json:"execution_count,omitempty"`
+	Busy           bool                   `protobuf:"varint,7,opt,name=busy,proto3" json:"busy,omitempty"`
+	Parallelism    int32                  `protobuf:"varint,8,opt,name=parallelism,proto3" json:"parallelism,omitempty"`
+	MemBytes       int64                  `protobuf:"varint,9,opt,name=mem_bytes,json=memBytes,proto3" json:"mem_bytes,omitempty"`
+	CpuMillis      int64                  `protobuf:"varint,10,opt,name=cpu_millis,json=cpuMillis,proto3" json:"cpu_millis,omitempty"`
+	CreatedAt      string                 `protobuf:"bytes,11,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	LastActiveAt   string                 `protobuf:"bytes,12,opt,name=last_active_at,json=lastActiveAt,proto3" json:"last_active_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ContextInfo) Reset() {
+	*x = ContextInfo{}
+	mi := &file_idl_korokd_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContextInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContextInfo) ProtoMessage() {}
+
+func (x *ContextInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_idl_korokd_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContextInfo.ProtoReflect.Descriptor instead.
+func (*ContextInfo) Descriptor() ([]byte, []int) {
+	return file_idl_korokd_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ContextInfo) GetContextId() string {
+	if x != nil {
+		return x.ContextId
+	}
+	return ""
+}
+
+func (x *ContextInfo) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *ContextInfo) GetCwd() string {
+	if x != nil {
+		return x.Cwd
+	}
+	return ""
+}
+
+func (x *ContextInfo) GetCurrentCwd() string {
+	if x != nil {
+		return x.CurrentCwd
+	}
+	return ""
+}
+
+func (x *ContextInfo) GetKernelId() string {
+	if x != nil {
+		return x.KernelId
+	}
+	return ""
+}
+
+func (x *ContextInfo) GetExecutionCount() int64 {
+	if x != nil {
+		return x.ExecutionCount
+	}
+	return 0
+}
+
+func (x *ContextInfo) GetBusy() bool {
+	if x != nil {
+		return x.Busy
+	}
+	return false
+}
+
+func (x *ContextInfo) GetParallelism() int32 {
+	if x != nil {
+		return x.Parallelism
+	}
+	return 0
+}
+
+func (x *ContextInfo) GetMemBytes() int64 {
+	if x != nil {
+		return x.MemBytes
+	}
+	return 0
+}
+
+func (x *ContextInfo) GetCpuMillis() int64 {
+	if x != nil {
+		return x.CpuMillis
+	}
+	return 0
+}
+
+func (x *ContextInfo) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *ContextInfo) GetLastActiveAt() string {
+	if x != nil {
+		return x.LastActiveAt
+	}
+	return ""
+}
+
+type DeleteContextRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContextId     string                 `protobuf:"bytes,1,opt,name=context_id,json=contextId,proto3" json:"context_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteContextRequest) Reset() {
+	*x = DeleteContextRequest{}
+	mi := &file_idl_korokd_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteContextRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteContextRequest) ProtoMessage() {}
+
+func (x *DeleteContextRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_idl_korokd_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteContextRequest.ProtoReflect.Descriptor instead.
+func (*DeleteContextRequest) Descriptor() ([]byte, []int) {
+	return file_idl_korokd_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeleteContextRequest) GetContextId() string {
+	if x != nil {
+		return x.ContextId
+	}
+	return ""
+}
+
+type DeleteContextResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContextId     string                 `protobuf:"bytes,1,opt,name=context_id,json=contextId,proto3" json:"context_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteContextResponse) Reset() {
+	*x = DeleteContextResponse{}
+	mi := &file_idl_korokd_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteContextResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteContextResponse) ProtoMessage() {}
+
+func (x *DeleteContextResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_idl_korokd_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteContextResponse.ProtoReflect.Descriptor instead.
+func (*DeleteContextResponse) Descriptor() ([]byte, []int) {
+	return file_idl_korokd_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeleteContextResponse) GetContextId() string {
+	if x != nil {
+		return x.ContextId
+	}
+	return ""
+}
+
+type ExecuteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContextId     string                 `protobuf:"bytes,1,opt,name=context_id,json=contextId,proto3" json:"context_id,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	CodeEncoding  string                 `protobuf:"bytes,3,opt,name=code_encoding,json=codeEncoding,proto3" json:"code_encoding,omitempty"`
+	TimeoutMs     int32                  `protobuf:"varint,4,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+	Stdin         *string                `protobuf:"bytes,5,opt,name=stdin,proto3,oneof" json:"stdin,omitempty"`
+	ReturnVars    bool                   `protobuf:"varint,6,opt,name=return_vars,json=returnVars,proto3" json:"return_vars,omitempty"`
+	CheckOnly     bool                   `protobuf:"varint,7,opt,name=check_only,json=checkOnly,proto3" json:"check_only,omitempty"`
+	Parallel      bool                   `protobuf:"varint,8,opt,name=parallel,proto3" json:"parallel,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteRequest) Reset() {
+	*x = ExecuteRequest{}
+	mi := &file_idl_korokd_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteRequest) ProtoMessage() {}
+
+func (x *ExecuteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_idl_korokd_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteRequest.ProtoReflect.Descriptor instead.
+func (*ExecuteRequest) Descriptor() ([]byte, []int) {
+	return file_idl_korokd_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ExecuteRequest) GetContextId() string {
+	if x != nil {
+		return x.ContextId
+	}
+	return ""
+}
+
+func (x *ExecuteRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ExecuteRequest) GetCodeEncoding() string {
+	if x != nil {
+		return x.CodeEncoding
+	}
+	return ""
+}
+
+func (x *ExecuteRequest) GetTimeoutMs() int32 {
+	if x != nil {
+		return x.TimeoutMs
+	}
+	return 0
+}
+
+func (x *ExecuteRequest) GetStdin() string {
+	if x != nil && x.Stdin != nil {
+		return *x.Stdin
+	}
+	return ""
+}
+
+func (x *ExecuteRequest) GetReturnVars() bool {
+	if x != nil {
+		return x.ReturnVars
+	}
+	return false
+}
+
+func (x *ExecuteRequest) GetCheckOnly() bool {
+	if x != nil {
+		return x.CheckOnly
+	}
+	return false
+}
+
+func (x *ExecuteRequest) GetParallel() bool {
+	if x != nil {
+		return x.Parallel
+	}
+	return false
+}
+
+type RichOutput struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MimeType      string                 `protobuf:"bytes,1,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	Data          string                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Truncated     bool                   `protobuf:"varint,3,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RichOutput) Reset() {
+	*x = RichOutput{}
+	mi := &file_idl_korokd_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RichOutput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RichOutput) ProtoMessage() {}
+
+func (x *RichOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_idl_korokd_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RichOutput.ProtoReflect.Descriptor instead.
+func (*RichOutput) Descriptor() ([]byte, []int) {
+	return file_idl_korokd_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RichOutput) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *RichOutput) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *RichOutput) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+type ExecuteEvent struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Type            string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Timestamp       int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	ContextId       string                 `protobuf:"bytes,3,opt,name=context_id,json=contextId,proto3" json:"context_id,omitempty"`
+	Text            string                 `protobuf:"bytes,4,opt,name=text,proto3" json:"text,omitempty"`
+	ExecutionCount  int64                  `protobuf:"varint,5,opt,name=execution_count,json=executionCount,proto3" json:"execution_count,omitempty"`
+	ExecutionTime   int64                  `protobuf:"varint,6,opt,name=execution_time,json=executionTime,proto3" json:"execution_time,omitempty"`
+	ExitCode        int32                  `protobuf:"varint,7,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	StdoutTruncated bool                   `protobuf:"varint,8,opt,name=stdout_truncated,json=stdoutTruncated,proto3" json:"stdout_truncated,omitempty"`
+	StderrTruncated bool                   `protobuf:"varint,9,opt,name=stderr_truncated,json=stderrTruncated,proto3" json:"stderr_truncated,omitempty"`
+	Outputs         []*RichOutput          `protobuf:"bytes,10,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	Variables       map[string]string      `protobuf:"bytes,11,rep,name=variables,proto3" json:"variables,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Error           string                 `protobuf:"bytes,12,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ExecuteEvent) Reset() {
+	*x = ExecuteEvent{}
+	mi := &file_idl_korokd_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteEvent) ProtoMessage() {}
+
+func (x *ExecuteEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_idl_korokd_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteEvent.ProtoReflect.Descriptor instead.
+func (*ExecuteEvent) Descriptor() ([]byte, []int) {
+	return file_idl_korokd_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ExecuteEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ExecuteEvent) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *ExecuteEvent) GetContextId() string {
+	if x != nil {
+		return x.ContextId
+	}
+	return ""
+}
+
+func (x *ExecuteEvent) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *ExecuteEvent) GetExecutionCount() int64 {
+	if x != nil {
+		return x.ExecutionCount
+	}
+	return 0
+}
+
+func (x *ExecuteEvent) GetExecutionTime() int64 {
+	if x != nil {
+		return x.ExecutionTime
+	}
+	return 0
+}
+
+func (x *ExecuteEvent) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *ExecuteEvent) GetStdoutTruncated() bool {
+	if x != nil {
+		return x.StdoutTruncated
+	}
+	return false
+}
+
+func (x *ExecuteEvent) GetStderrTruncated() bool {
+	if x != nil {
+		return x.StderrTruncated
+	}
+	return false
+}
+
+func (x *ExecuteEvent) GetOutputs() []*RichOutput {
+	if x != nil {
+		return x.Outputs
+	}
+	return nil
+}
+
+func (x *ExecuteEvent) GetVariables() map[string]string {
+	if x != nil {
+		return x.Variables
+	}
+	return nil
+}
+
+func (x *ExecuteEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_idl_korokd_proto protoreflect.FileDescriptor
+
+const file_idl_korokd_proto_rawDesc = "" +
+	"\n" +
+	"\x10idl/korokd.proto\x12\x13agentland.korokd.v1\"\xa0\x02\n" +
+	"\x14CreateContextRequest\x12\x1a\n" +
+	"\blanguage\x18\x01 \x01(\tR\blanguage\x12\x10\n" +
+	"\x03cwd\x18\x02 \x01(\tR\x03cwd\x12\x1b\n" +
+	"\tmem_bytes\x18\x03 \x01(\x03R\bmemBytes\x12\x1d\n" +
+	"\n" +
+	"cpu_millis\x18\x04 \x01(\x03R\tcpuMillis\x12D\n" +
+	"\x03env\x18\x05 \x03(\v22.agentland.korokd.v1.CreateContextRequest.EnvEntryR\x03env\x12 \n" +
+	"\vparallelism\x18\x06 \x01(\x05R\vparallelism\x1a6\n" +
+	"\bEnvEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x99\x01\n" +
+	"\x15CreateContextResponse\x12\x1d\n" +
+	"\n" +
+	"context_id\x18\x01 \x01(\tR\tcontextId\x12\x1a\n" +
+	"\blanguage\x18\x02 \x01(\tR\blanguage\x12\x10\n" +
+	"\x03cwd\x18\x03 \x01(\tR\x03cwd\x12\x14\n" +
+	"\x05state\x18\x04 \x01(\tR\x05state\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\tR\tcreatedAt\"\x15\n" +
+	"\x13ListContextsRequest\"T\n" +
+	"\x14ListContextsResponse\x12<\n" +
+	"\bcontexts\x18\x01 \x03(\v2 .agentland.korokd.v1.ContextInfoR\bcontexts\"\xf8\x02\n" +
+	"\vContextInfo\x12\x1d\n" +
+	"\n" +
+	"context_id\x18\x01 \x01(\tR\tcontextId\x12\x1a\n" +
+	"\blanguage\x18\x02 \x01(\tR\blanguage\x12\x10\n" +
+	"\x03cwd\x18\x03 \x01(\tR\x03cwd\x12\x1f\n" +
+	"\vcurrent_cwd\x18\x04 \x01(\tR\n" +
+	"currentCwd\x12\x1b\n" +
+	"\tkernel_id\x18\x05 \x01(\tR\bkernelId\x12'\n" +
+	"\x0fexecution_count\x18\x06 \x01(\x03R\x0eexecutionCount\x12\x12\n" +
+	"\x04busy\x18\a \x01(\bR\x04busy\x12 \n" +
+	"\vparallelism\x18\b \x01(\x05R\vparallelism\x12\x1b\n" +
+	"\tmem_bytes\x18\t \x01(\x03R\bmemBytes\x12\x1d\n" +
+	"\n" +
+	"cpu_millis\x18\n" +
+	" \x01(\x03R\tcpuMillis\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\v \x01(\tR\tcreatedAt\x12$\n" +
+	"\x0elast_active_at\x18\f \x01(\tR\flastActiveAt\"5\n" +
+	"\x14DeleteContextRequest\x12\x1d\n" +
+	"\n" +
+	"context_id\x18\x01 \x01(\tR\tcontextId\"6\n" +
+	"\x15DeleteContextResponse\x12\x1d\n" +
+	"\n" +
+	"context_id\x18\x01 \x01(\tR\tcontextId\"\x88\x02\n" +
+	"\x0eExecuteRequest\x12\x1d\n" +
+	"\n" +
+	"context_id\x18\x01 \x01(\tR\tcontextId\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12#\n" +
+	"\rcode_encoding\x18\x03 \x01(\tR\fcodeEncoding\x12\x1d\n" +
+	"\n" +
+	"timeout_ms\x18\x04 \x01(\x05R\ttimeoutMs\x12\x19\n" +
+	"\x05stdin\x18\x05 \x01(\tH\x00R\x05stdin\x88\x01\x01\x12\x1f\n" +
+	"\vreturn_vars\x18\x06 \x01(\bR\n" +
+	"returnVars\x12\x1d\n" +
+	"\n" +
+	"check_only\x18\a \x01(\bR\tcheckOnly\x12\x1a\n" +
+	"\bparallel\x18\b \x01(\bR\bparallelB\b\n" +
+	"\x06_stdin\"[\n" +
+	"\n" +
+	"RichOutput\x12\x1b\n" +
+	"\tmime_type\x18\x01 \x01(\tR\bmimeType\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\tR\x04data\x12\x1c\n" +
+	"\ttruncated\x18\x03 \x01(\bR\ttruncated\"\x95\x04\n" +
+	"\fExecuteEvent\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\x12\x1d\n" +
+	"\n" +
+	"context_id\x18\x03 \x01(\tR\tcontextId\x12\x12\n" +
+	"\x04text\x18\x04 \x01(\tR\x04text\x12'\n" +
+	"\x0fexecution_count\x18\x05 \x01(\x03R\x0eexecutionCount\x12%\n" +
+	"\x0eexecution_time\x18\x06 \x01(\x03R\rexecutionTime\x12\x1b\n" +
+	"\texit_code\x18\a \x01(\x05R\bexitCode\x12)\n" +
+	"\x10stdout_truncated\x18\b \x01(\bR\x0fstdoutTruncated\x12)\n" +
+	"\x10stderr_truncated\x18\t \x01(\bR\x0fstderrTruncated\x129\n" +
+	"\aoutputs\x18\n" +
+	" \x03(\v2\x1f.agentland.korokd.v1.RichOutputR\aoutputs\x12N\n" +
+	"\tvariables\x18\v \x03(\v20.agentland.korokd.v1.ExecuteEvent.VariablesEntryR\tvariables\x12\x14\n" +
+	"\x05error\x18\f \x01(\tR\x05error\x1a<\n" +
+	"\x0eVariablesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x012\x9a\x03\n" +
+	"\x0eContextService\x12f\n" +
+	"\rCreateContext\x12).agentland.korokd.v1.CreateContextRequest\x1a*.agentland.korokd.v1.CreateContextResponse\x12c\n" +
+	"\fListContexts\x12(.agentland.korokd.v1.ListContextsRequest\x1a).agentland.korokd.v1.ListContextsResponse\x12f\n" +
+	"\rDeleteContext\x12).agentland.korokd.v1.DeleteContextRequest\x1a*.agentland.korokd.v1.DeleteContextResponse\x12S\n" +
+	"\aExecute\x12#.agentland.korokd.v1.ExecuteRequest\x1a!.agentland.korokd.v1.ExecuteEvent0\x01B5Z3github.com/Fl0rencess720/agentland/pb/korokd;korokdb\x06proto3"
+
+var (
+	file_idl_korokd_proto_rawDescOnce sync.Once
+	file_idl_korokd_proto_rawDescData []byte
+)
+
+func file_idl_korokd_proto_rawDescGZIP() []byte {
+	file_idl_korokd_proto_rawDescOnce.Do(func() {
+		file_idl_korokd_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_idl_korokd_proto_rawDesc), len(file_idl_korokd_proto_rawDesc)))
+	})
+	return file_idl_korokd_proto_rawDescData
+}
+
+var file_idl_korokd_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_idl_korokd_proto_goTypes = []any{
+	(*CreateContextRequest)(nil),  // 0: agentland.korokd.v1.CreateContextRequest
+	(*CreateContextResponse)(nil), // 1: agentland.korokd.v1.CreateContextResponse
+	(*ListContextsRequest)(nil),   // 2: agentland.korokd.v1.ListContextsRequest
+	(*ListContextsResponse)(nil),  // 3: agentland.korokd.v1.ListContextsResponse
+	(*ContextInfo)(nil),           // 4: agentland.korokd.v1.ContextInfo
+	(*DeleteContextRequest)(nil),  // 5: agentland.korokd.v1.DeleteContextRequest
+	(*DeleteContextResponse)(nil), // 6: agentland.korokd.v1.DeleteContextResponse
+	(*ExecuteRequest)(nil),        // 7: agentland.korokd.v1.ExecuteRequest
+	(*RichOutput)(nil),            // 8: agentland.korokd.v1.RichOutput
+	(*ExecuteEvent)(nil),          // 9: agentland.korokd.v1.ExecuteEvent
+	nil,                           // 10: agentland.korokd.v1.CreateContextRequest.EnvEntry
+	nil,                           // 11: agentland.korokd.v1.ExecuteEvent.VariablesEntry
+}
+var file_idl_korokd_proto_depIdxs = []int32{
+	10, // 0: agentland.korokd.v1.CreateContextRequest.env:type_name -> agentland.korokd.v1.CreateContextRequest.EnvEntry
+	4,  // 1: agentland.korokd.v1.ListContextsResponse.contexts:type_name -> agentland.korokd.v1.ContextInfo
+	8,  // 2: agentland.korokd.v1.ExecuteEvent.outputs:type_name -> agentland.korokd.v1.RichOutput
+	11, // 3: agentland.korokd.v1.ExecuteEvent.variables:type_name -> agentland.korokd.v1.ExecuteEvent.VariablesEntry
+	0,  // 4: agentland.korokd.v1.ContextService.CreateContext:input_type -> agentland.korokd.v1.CreateContextRequest
+	2,  // 5: agentland.korokd.v1.ContextService.ListContexts:input_type -> agentland.korokd.v1.ListContextsRequest
+	5,  // 6: agentland.korokd.v1.ContextService.DeleteContext:input_type -> agentland.korokd.v1.DeleteContextRequest
+	7,  // 7: agentland.korokd.v1.ContextService.Execute:input_type -> agentland.korokd.v1.ExecuteRequest
+	1,  // 8: agentland.korokd.v1.ContextService.CreateContext:output_type -> agentland.korokd.v1.CreateContextResponse
+	3,  // 9: agentland.korokd.v1.ContextService.ListContexts:output_type -> agentland.korokd.v1.ListContextsResponse
+	6,  // 10: agentland.korokd.v1.ContextService.DeleteContext:output_type -> agentland.korokd.v1.DeleteContextResponse
+	9,  // 11: agentland.korokd.v1.ContextService.Execute:output_type -> agentland.korokd.v1.ExecuteEvent
+	8,  // [8:12] is the sub-list for method output_type
+	4,  // [4:8] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_idl_korokd_proto_init() }
+func file_idl_korokd_proto_init() {
+	if File_idl_korokd_proto != nil {
+		return
+	}
+	file_idl_korokd_proto_msgTypes[7].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_idl_korokd_proto_rawDesc), len(file_idl_korokd_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_idl_korokd_proto_goTypes,
+		DependencyIndexes: file_idl_korokd_proto_depIdxs,
+		MessageInfos:      file_idl_korokd_proto_msgTypes,
+	}.Build()
+	File_idl_korokd_proto = out.File
+	file_idl_korokd_proto_goTypes = nil
+	file_idl_korokd_proto_depIdxs = nil
+}
@@ -2,6 +2,9 @@ package agentcore
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -11,16 +14,23 @@ import (
 	"github.com/Fl0rencess720/agentland/pkg/agentcore/pkgs/db"
 	"github.com/Fl0rencess720/agentland/pkg/common/consts"
 	"github.com/Fl0rencess720/agentland/pkg/common/observability"
+	commonutils "github.com/Fl0rencess720/agentland/pkg/common/utils"
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	grpccodes "google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	grpcstatus "google.golang.org/grpc/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 var (
@@ -28,6 +38,45 @@ var (
 	KorokdImage = "korokd:latest"
 )
 
+// defaultProvisioningTimeout 为未配置 ProvisioningTimeout 时等待沙箱就绪的时长
+const defaultProvisioningTimeout = 60 * time.Second
+
+const (
+	// ownerQuotaReservationGrace 为配额占位在就绪等待之外额外保留的时长
+	ownerQuotaReservationGrace = 30 * time.Second
+	// ownerQuotaReleaseTimeout 为释放配额占位的超时时间
+	ownerQuotaReleaseTimeout = 5 * time.Second
+)
+
+// ErrProvisioningTimeout 表示沙箱在 ProvisioningTimeout 内未进入 Running
+var ErrProvisioningTimeout = errors.New("timeout waiting for sandbox to be ready")
+
+// ProvisioningTimeoutError 携带超时时最后观察到的 phase，可通过 errors.Is(err, ErrProvisioningTimeout) 判断
+// 经 gRPC 返回时映射为 DeadlineExceeded，网关据此区分 504 与 500
+type ProvisioningTimeoutError struct {
+	SessionID string
+	// Phase 为最后一次观察到的 phase，尚未观察到任何状态时为空
+	Phase   string
+	Timeout time.Duration
+}
+
+func (e *ProvisioningTimeoutError) Error() string {
+	phase := e.Phase
+	if phase == "" {
+		phase = "unknown"
+	}
+	return fmt.Sprintf("%s: session=%s timeout=%s last_phase=%s", ErrProvisioningTimeout, e.SessionID, e.Timeout, phase)
+}
+
+func (e *ProvisioningTimeoutError) Unwrap() error {
+	return ErrProvisioningTimeout
+}
+
+// GRPCStatus 让 gRPC 服务端以 DeadlineExceeded 返回该错误
+func (e *ProvisioningTimeoutError) GRPCStatus() *grpcstatus.Status {
+	return grpcstatus.New(grpccodes.DeadlineExceeded, e.Error())
+}
+
 var codeInterpreterGVR = schema.GroupVersionResource{
 	Group:    "agentland.fl0rencess720.app",
 	Version:  "v1alpha1",
@@ -46,7 +95,7 @@ var sandboxGVR = schema.GroupVersionResource{
 	Resource: "sandboxes",
 }
 
-func (s *Server) CreateCodeInterpreter(ctx context.Context, _ *pb.CreateSandboxRequest) (*pb.CreateSandboxResponse, error) {
+func (s *Server) CreateCodeInterpreter(ctx context.Context, req *pb.CreateSandboxRequest) (*pb.CreateSandboxResponse, error) {
 	ctx = withIncomingRequestID(ctx)
 	tracer := otel.Tracer("agentcore.service")
 	ctx, span := tracer.Start(ctx, "agentcore.create_codeinterpreter", trace.WithSpanKind(trace.SpanKindServer))
@@ -57,6 +106,15 @@ func (s *Server) CreateCodeInterpreter(ctx context.Context, _ *pb.CreateSandboxR
 		attribute.String("request.id", requestID),
 	)
 
+	owner := strings.TrimSpace(req.GetOwner())
+	releaseQuota, err := s.reserveOwnerQuota(ctx, owner)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "owner quota check failed")
+		return nil, err
+	}
+	defer releaseQuota()
+
 	korokdImage := s.korokdImage
 	if korokdImage == "" {
 		korokdImage = KorokdImage
@@ -72,6 +130,7 @@ func (s *Server) CreateCodeInterpreter(ctx context.Context, _ *pb.CreateSandboxR
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: "session-",
 			Namespace:    consts.AgentLandSandboxesNamespace,
+			Labels:       ownerLabels(owner),
 			Annotations:  observability.InjectContextToAnnotations(ctx, nil),
 		},
 		Spec: v1alpha1.CodeInterpreterSpec{
@@ -111,7 +170,12 @@ func (s *Server) CreateCodeInterpreter(ctx context.Context, _ *pb.CreateSandboxR
 	}
 	uObj := &unstructured.Unstructured{Object: objMap}
 
-	result, err := s.k8sClient.Resource(codeInterpreterGVR).Namespace(cr.Namespace).Create(ctx, uObj, metav1.CreateOptions{})
+	var result *unstructured.Unstructured
+	err = retryK8sCreate(ctx, "create codeinterpreter", func() error {
+		var createErr error
+		result, createErr = s.k8sClient.Resource(codeInterpreterGVR).Namespace(cr.Namespace).Create(ctx, uObj.DeepCopy(), metav1.CreateOptions{})
+		return createErr
+	})
 	if err != nil {
 		zap.L().Error("Failed to create CodeInterpreter in k8s", zap.Error(err))
 		span.RecordError(err)
@@ -128,7 +192,7 @@ func (s *Server) CreateCodeInterpreter(ctx context.Context, _ *pb.CreateSandboxR
 	}
 	span.SetAttributes(attribute.String("agentland.session_id", sandboxID))
 
-	grpcEndpoint, err := s.waitSessionReady(ctx, sandboxGVR, codeInterpreterGVR, cr.Namespace, sandboxID)
+	grpcEndpoint, err := s.waitSessionReady(ctx, sandboxGVR, codeInterpreterGVR, cr.Namespace, sandboxID, owner, "")
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "wait session ready failed")
@@ -141,6 +205,83 @@ func (s *Server) CreateCodeInterpreter(ctx context.Context, _ *pb.CreateSandboxR
 	}, nil
 }
 
+func (s *Server) GetCodeInterpreter(ctx context.Context, req *pb.GetSandboxRequest) (*pb.GetSandboxResponse, error) {
+	ctx = withIncomingRequestID(ctx)
+	tracer := otel.Tracer("agentcore.service")
+	ctx, span := tracer.Start(ctx, "agentcore.get_codeinterpreter", trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	span.SetAttributes(attribute.String("request.id", observability.RequestIDFromContext(ctx)))
+
+	sandboxID := strings.TrimSpace(req.GetSandboxId())
+	if sandboxID == "" {
+		span.SetStatus(codes.Error, "sandbox_id is required")
+		return nil, grpcstatus.Error(grpccodes.InvalidArgument, "sandbox_id is required")
+	}
+	span.SetAttributes(attribute.String("agentland.session_id", sandboxID))
+
+	obj, err := s.k8sClient.Resource(codeInterpreterGVR).
+		Namespace(consts.AgentLandSandboxesNamespace).
+		Get(ctx, sandboxID, metav1.GetOptions{})
+	if err != nil {
+		span.RecordError(err)
+		if apierrors.IsNotFound(err) {
+			span.SetStatus(codes.Error, "codeinterpreter not found")
+			return nil, grpcstatus.Errorf(grpccodes.NotFound, "codeinterpreter %s not found", sandboxID)
+		}
+		span.SetStatus(codes.Error, "get codeinterpreter CR failed")
+		return nil, fmt.Errorf("failed to get codeinterpreter from k8s: %w", err)
+	}
+
+	resp := &pb.GetSandboxResponse{SandboxId: sandboxID}
+	status, found, _ := unstructured.NestedMap(obj.Object, "status")
+	if !found {
+		// 控制器尚未写入 status，视为仍在排队
+		return resp, nil
+	}
+	resp.Phase, _, _ = unstructured.NestedString(status, "phase")
+	resp.PodIp, _, _ = unstructured.NestedString(status, "podIP")
+	resp.ClaimName, _, _ = unstructured.NestedString(status, "claimName")
+	resp.SandboxName, _, _ = unstructured.NestedString(status, "sandboxName")
+	if resp.Phase == "Failed" {
+		resp.Reason, resp.Message = extractCondition(status, "Accepted")
+	}
+	span.SetAttributes(attribute.String("sandbox.phase", resp.Phase))
+
+	return resp, nil
+}
+
+func (s *Server) DeleteCodeInterpreter(ctx context.Context, req *pb.DeleteSandboxRequest) (*pb.DeleteSandboxResponse, error) {
+	ctx = withIncomingRequestID(ctx)
+	tracer := otel.Tracer("agentcore.service")
+	ctx, span := tracer.Start(ctx, "agentcore.delete_codeinterpreter", trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+
+	span.SetAttributes(attribute.String("request.id", observability.RequestIDFromContext(ctx)))
+
+	sandboxID := strings.TrimSpace(req.GetSandboxId())
+	if sandboxID == "" {
+		span.SetStatus(codes.Error, "sandbox_id is required")
+		return nil, fmt.Errorf("sandbox_id is required")
+	}
+	span.SetAttributes(attribute.String("agentland.session_id", sandboxID))
+
+	if err := s.deleteSessionCRByGVR(ctx, codeInterpreterGVR, sandboxID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "delete codeinterpreter CR failed")
+		return nil, fmt.Errorf("delete codeinterpreter CR failed: %w", err)
+	}
+	if s.sessionStore != nil {
+		if err := s.sessionStore.DeleteSession(ctx, sandboxID); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "delete session from store failed")
+			return nil, fmt.Errorf("delete session from store failed: %w", err)
+		}
+	}
+
+	return &pb.DeleteSandboxResponse{}, nil
+}
+
 func (s *Server) CreateAgentSession(ctx context.Context, req *pb.CreateAgentSessionRequest) (*pb.CreateAgentSessionResponse, error) {
 	ctx = withIncomingRequestID(ctx)
 	tracer := otel.Tracer("agentcore.service")
@@ -159,6 +300,29 @@ func (s *Server) CreateAgentSession(ctx context.Context, req *pb.CreateAgentSess
 		runtimeNamespace = consts.AgentLandSandboxesNamespace
 	}
 
+	owner := strings.TrimSpace(req.GetOwner())
+	idempotencyKey, err := scopedIdempotencyKey(owner, req.GetIdempotencyKey())
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid idempotency key")
+		return nil, err
+	}
+	// 携带幂等键的重试直接返回已就绪的会话，不占用配额
+	if existing := s.lookupIdempotentSession(ctx, idempotencyKey); existing != nil {
+		span.SetAttributes(attribute.String("agentland.session_id", existing.SandboxID), attribute.Bool("agentland.idempotent_replay", true))
+		return &pb.CreateAgentSessionResponse{
+			SessionId:    existing.SandboxID,
+			GrpcEndpoint: existing.GrpcEndpoint,
+		}, nil
+	}
+
+	releaseQuota, err := s.reserveOwnerQuota(ctx, owner)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "owner quota check failed")
+		return nil, err
+	}
+	defer releaseQuota()
+
 	cr := &v1alpha1.AgentSession{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: agentSessionGVR.GroupVersion().String(),
@@ -167,6 +331,7 @@ func (s *Server) CreateAgentSession(ctx context.Context, req *pb.CreateAgentSess
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: "session-",
 			Namespace:    consts.AgentLandSandboxesNamespace,
+			Labels:       ownerLabels(owner),
 			Annotations:  observability.InjectContextToAnnotations(ctx, nil),
 		},
 		Spec: v1alpha1.AgentSessionSpec{
@@ -176,6 +341,11 @@ func (s *Server) CreateAgentSession(ctx context.Context, req *pb.CreateAgentSess
 			},
 		},
 	}
+	if idempotencyKey != "" {
+		// 同一幂等键映射到固定的 CR 名称，并发重试由 k8s 的 AlreadyExists 去重
+		cr.GenerateName = ""
+		cr.Name = idempotentSessionName(idempotencyKey)
+	}
 
 	if s.warmPoolEnabled {
 		mode := v1alpha1.ProvisioningModePoolPreferred
@@ -204,7 +374,22 @@ func (s *Server) CreateAgentSession(ctx context.Context, req *pb.CreateAgentSess
 	}
 	uObj := &unstructured.Unstructured{Object: objMap}
 
-	result, err := s.k8sClient.Resource(agentSessionGVR).Namespace(cr.Namespace).Create(ctx, uObj, metav1.CreateOptions{})
+	var result *unstructured.Unstructured
+	err = retryK8sCreate(ctx, "create agentsession", func() error {
+		var createErr error
+		result, createErr = s.k8sClient.Resource(agentSessionGVR).Namespace(cr.Namespace).Create(ctx, uObj.DeepCopy(), metav1.CreateOptions{})
+		return createErr
+	})
+	if err != nil && idempotencyKey != "" && apierrors.IsAlreadyExists(err) {
+		// 前一次携带相同幂等键的请求已创建 CR，复用它并等待其就绪
+		if existing := s.lookupSession(ctx, cr.Name); existing != nil {
+			return &pb.CreateAgentSessionResponse{
+				SessionId:    existing.SandboxID,
+				GrpcEndpoint: existing.GrpcEndpoint,
+			}, nil
+		}
+		result, err = uObj, nil
+	}
 	if err != nil {
 		zap.L().Error("Failed to create AgentSession in k8s", zap.Error(err))
 		span.RecordError(err)
@@ -221,7 +406,7 @@ func (s *Server) CreateAgentSession(ctx context.Context, req *pb.CreateAgentSess
 	}
 	span.SetAttributes(attribute.String("agentland.session_id", sessionID))
 
-	grpcEndpoint, err := s.waitSessionReady(ctx, sandboxGVR, agentSessionGVR, cr.Namespace, sessionID)
+	grpcEndpoint, err := s.waitSessionReady(ctx, sandboxGVR, agentSessionGVR, cr.Namespace, sessionID, owner, idempotencyKey)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "wait session ready failed")
@@ -244,15 +429,50 @@ func (s *Server) GetAgentSession(ctx context.Context, req *pb.GetAgentSessionReq
 
 	sessionInfo, err := s.sessionStore.GetSession(ctx, req.GetSessionId())
 	if err != nil {
+		if errors.Is(err, db.ErrSessionNotFound) {
+			return nil, grpcstatus.Errorf(grpccodes.NotFound, "agentsession %s not found", req.GetSessionId())
+		}
 		return nil, fmt.Errorf("get session failed: %w", err)
 	}
 
 	return &pb.GetAgentSessionResponse{
 		SessionId:    sessionInfo.SandboxID,
 		GrpcEndpoint: sessionInfo.GrpcEndpoint,
+		Ports:        s.agentSessionPorts(ctx, sessionInfo.SandboxID),
 	}, nil
 }
 
+// agentSessionPorts 读取 AgentSession status 中声明的端口；CR 不可读时仅记录日志并返回空列表
+func (s *Server) agentSessionPorts(ctx context.Context, sessionID string) []uint32 {
+	if s.k8sClient == nil {
+		return nil
+	}
+	obj, err := s.k8sClient.Resource(agentSessionGVR).
+		Namespace(consts.AgentLandSandboxesNamespace).
+		Get(ctx, sessionID, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			zap.L().Warn("Get agentsession CR for ports failed", zap.String("sessionID", sessionID), zap.Error(err))
+		}
+		return nil
+	}
+
+	items, _, _ := unstructured.NestedSlice(obj.Object, "status", "ports")
+	ports := make([]uint32, 0, len(items))
+	for _, item := range items {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		port, found, err := unstructured.NestedInt64(entry, "port")
+		if err != nil || !found || port <= 0 {
+			continue
+		}
+		ports = append(ports, uint32(port))
+	}
+	return ports
+}
+
 func (s *Server) DeleteAgentSession(ctx context.Context, req *pb.DeleteAgentSessionRequest) (*pb.DeleteAgentSessionResponse, error) {
 	if req.GetSessionId() == "" {
 		return nil, fmt.Errorf("session_id is required")
@@ -270,7 +490,7 @@ func (s *Server) DeleteAgentSession(ctx context.Context, req *pb.DeleteAgentSess
 	return &pb.DeleteAgentSessionResponse{}, nil
 }
 
-func (s *Server) waitSessionReady(ctx context.Context, readyGVR, failureGVR schema.GroupVersionResource, namespace, sessionID string) (string, error) {
+func (s *Server) waitSessionReady(ctx context.Context, readyGVR, failureGVR schema.GroupVersionResource, namespace, sessionID, owner, idempotencyKey string) (string, error) {
 	tracer := otel.Tracer("agentcore.service")
 	ctx, span := tracer.Start(ctx, "agentcore.wait_session_ready")
 	defer span.End()
@@ -282,8 +502,13 @@ func (s *Server) waitSessionReady(ctx context.Context, readyGVR, failureGVR sche
 		attribute.String("k8s.failure_resource", failureGVR.Resource),
 	)
 
-	readyWatcher, err := s.k8sClient.Resource(readyGVR).Namespace(namespace).Watch(ctx, metav1.ListOptions{
-		FieldSelector: "metadata.name=" + sessionID,
+	var readyWatcher watch.Interface
+	err := retryK8s(ctx, "watch ready resource", func() error {
+		var watchErr error
+		readyWatcher, watchErr = s.k8sClient.Resource(readyGVR).Namespace(namespace).Watch(ctx, metav1.ListOptions{
+			FieldSelector: "metadata.name=" + sessionID,
+		})
+		return watchErr
 	})
 	if err != nil {
 		span.RecordError(err)
@@ -292,8 +517,13 @@ func (s *Server) waitSessionReady(ctx context.Context, readyGVR, failureGVR sche
 	}
 	defer readyWatcher.Stop()
 
-	failureWatcher, err := s.k8sClient.Resource(failureGVR).Namespace(namespace).Watch(ctx, metav1.ListOptions{
-		FieldSelector: "metadata.name=" + sessionID,
+	var failureWatcher watch.Interface
+	err = retryK8s(ctx, "watch failure resource", func() error {
+		var watchErr error
+		failureWatcher, watchErr = s.k8sClient.Resource(failureGVR).Namespace(namespace).Watch(ctx, metav1.ListOptions{
+			FieldSelector: "metadata.name=" + sessionID,
+		})
+		return watchErr
 	})
 	if err != nil {
 		span.RecordError(err)
@@ -302,9 +532,12 @@ func (s *Server) waitSessionReady(ctx context.Context, readyGVR, failureGVR sche
 	}
 	defer failureWatcher.Stop()
 
-	timeoutCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	timeout := s.provisioningTimeout()
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	// lastPhase 记录最近一次观察到的 phase，超时时随错误返回
+	var lastPhase string
 	for {
 		select {
 		case event, ok := <-readyWatcher.ResultChan():
@@ -325,6 +558,9 @@ func (s *Server) waitSessionReady(ctx context.Context, readyGVR, failureGVR sche
 
 			phase, _, _ := unstructured.NestedString(status, "phase")
 			podIP, _, _ := unstructured.NestedString(status, "podIP")
+			if phase != "" {
+				lastPhase = phase
+			}
 			if phase == "Running" && podIP != "" {
 				span.AddEvent("sandbox.running", trace.WithAttributes(attribute.String("sandbox.pod_ip", podIP)))
 				if s.sessionStore == nil {
@@ -334,10 +570,13 @@ func (s *Server) waitSessionReady(ctx context.Context, readyGVR, failureGVR sche
 
 				now := time.Now()
 				sessionInfo := &db.SandboxInfo{
-					SandboxID:    sessionID,
-					GrpcEndpoint: podIP + KorokdPort,
-					CreatedAt:    now,
-					ExpiresAt:    now.Add(db.MaxSessionDuration),
+					SandboxID:      sessionID,
+					GrpcEndpoint:   podIP + KorokdPort,
+					CreatedAt:      now,
+					ExpiresAt:      now.Add(db.MaxSessionDuration),
+					Owner:          owner,
+					IdempotencyKey: idempotencyKey,
+					Resource:       failureGVR.Resource,
 				}
 
 				if err := s.sessionStore.CreateSession(ctx, sessionInfo); err != nil {
@@ -375,6 +614,10 @@ func (s *Server) waitSessionReady(ctx context.Context, readyGVR, failureGVR sche
 
 			phase, _, _ := unstructured.NestedString(status, "phase")
 			if phase != "Failed" {
+				// sandbox 尚未出现时以 CR 自身的 phase 兜底
+				if lastPhase == "" {
+					lastPhase = phase
+				}
 				continue
 			}
 			reason, message := extractCondition(status, "Accepted")
@@ -387,9 +630,112 @@ func (s *Server) waitSessionReady(ctx context.Context, readyGVR, failureGVR sche
 		case <-timeoutCtx.Done():
 			span.RecordError(timeoutCtx.Err())
 			span.SetStatus(codes.Error, "timeout waiting for sandbox")
-			return "", fmt.Errorf("timeout waiting for sandbox to be ready")
+			span.SetAttributes(attribute.String("sandbox.last_phase", lastPhase))
+			return "", &ProvisioningTimeoutError{SessionID: sessionID, Phase: lastPhase, Timeout: timeout}
+		}
+	}
+}
+
+// reserveOwnerQuota 校验 owner 并在创建 CR 前原子地占用一个并发会话名额，owner 为空时不做限制。
+// owner 会写入 CR 标签，因此必须是合法的 label value；超出配额时返回 ResourceExhausted。
+// 返回的 release 在创建结束后删除占位：成功时会话已以自身 ID 计入 owner 索引，失败时名额随之归还。
+func (s *Server) reserveOwnerQuota(ctx context.Context, owner string) (func(), error) {
+	noop := func() {}
+	if owner == "" {
+		return noop, nil
+	}
+	if errs := validation.IsValidLabelValue(owner); len(errs) > 0 {
+		return nil, grpcstatus.Errorf(grpccodes.InvalidArgument, "invalid owner %q: %s", owner, strings.Join(errs, "; "))
+	}
+	if s.MaxSessionsPerOwner <= 0 || s.sessionStore == nil {
+		return noop, nil
+	}
+
+	// 占位的有效期覆盖整个就绪等待，进程异常退出时由过期清理归还名额
+	reservationID := uuid.NewString()
+	now := time.Now()
+	until := now.Add(s.provisioningTimeout() + ownerQuotaReservationGrace)
+	reserved, err := s.sessionStore.ReserveOwnerSlot(ctx, owner, reservationID, int64(s.MaxSessionsPerOwner), now, until)
+	if err != nil {
+		return nil, fmt.Errorf("reserve owner session slot failed: %w", err)
+	}
+	if !reserved {
+		return nil, grpcstatus.Errorf(grpccodes.ResourceExhausted,
+			"owner %s reached max concurrent sessions (%d)", owner, s.MaxSessionsPerOwner)
+	}
+
+	return func() {
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ownerQuotaReleaseTimeout)
+		defer cancel()
+		if err := s.sessionStore.ReleaseOwnerSlot(releaseCtx, owner, reservationID); err != nil {
+			zap.L().Warn("Release owner session slot failed", zap.String("owner", owner), zap.Error(err))
 		}
+	}, nil
+}
+
+func ownerLabels(owner string) map[string]string {
+	if owner == "" {
+		return nil
+	}
+	return map[string]string{commonutils.OwnerLabel: owner}
+}
+
+// maxIdempotencyKeyLength 为幂等键的最大长度
+const maxIdempotencyKeyLength = 256
+
+// scopedIdempotencyKey 校验幂等键并按 owner 限定作用域，避免不同 owner 使用相同的键时互相复用会话
+func scopedIdempotencyKey(owner, key string) (string, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", nil
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return "", grpcstatus.Errorf(grpccodes.InvalidArgument, "idempotency_key exceeds %d bytes", maxIdempotencyKeyLength)
+	}
+	return owner + "/" + key, nil
+}
+
+// idempotentSessionName 由幂等键推导固定的 CR 名称
+func idempotentSessionName(scopedKey string) string {
+	sum := sha256.Sum256([]byte(scopedKey))
+	return "session-" + hex.EncodeToString(sum[:])[:20]
+}
+
+// lookupIdempotentSession 返回幂等键对应的已就绪会话，未命中或查询失败时返回 nil
+func (s *Server) lookupIdempotentSession(ctx context.Context, scopedKey string) *db.SandboxInfo {
+	if scopedKey == "" || s.sessionStore == nil {
+		return nil
+	}
+	info, err := s.sessionStore.GetSessionByIdempotencyKey(ctx, scopedKey)
+	if err != nil {
+		if !errors.Is(err, db.ErrSessionNotFound) {
+			zap.L().Warn("Lookup session by idempotency key failed", zap.Error(err))
+		}
+		return nil
+	}
+	if info.GrpcEndpoint == "" {
+		return nil
+	}
+	return info
+}
+
+// lookupSession 返回已写入 session store 的会话，未命中时返回 nil
+func (s *Server) lookupSession(ctx context.Context, sessionID string) *db.SandboxInfo {
+	if s.sessionStore == nil {
+		return nil
+	}
+	info, err := s.sessionStore.GetSession(ctx, sessionID)
+	if err != nil || info.GrpcEndpoint == "" {
+		return nil
+	}
+	return info
+}
+
+func (s *Server) provisioningTimeout() time.Duration {
+	if s.ProvisioningTimeout <= 0 {
+		return defaultProvisioningTimeout
 	}
+	return s.ProvisioningTimeout
 }
 
 func withIncomingRequestID(ctx context.Context) context.Context {
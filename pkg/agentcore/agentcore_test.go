@@ -11,7 +11,10 @@ import (
 	pb "github.com/Fl0rencess720/agentland/pb/agentcore"
 	"github.com/Fl0rencess720/agentland/pkg/agentcore/pkgs/db"
 	"github.com/Fl0rencess720/agentland/pkg/common/consts"
+	commonutils "github.com/Fl0rencess720/agentland/pkg/common/utils"
 	"github.com/stretchr/testify/suite"
+	grpccodes "google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -214,6 +217,175 @@ func (s *AgentCoreSuite) TestCreateSandboxWithWarmPoolProvisioning() {
 	s.Equal("python-default", profile)
 }
 
+func (s *AgentCoreSuite) TestCreateSandbox_ProvisioningTimeoutReportsLastPhase() {
+	scheme := runtime.NewScheme()
+	s.NoError(v1alpha1.AddToScheme(scheme))
+	fakeDynamicClient := fake.NewSimpleDynamicClient(scheme)
+	installGenerateNameReactor(fakeDynamicClient)
+	mockStore := &mockSessionStore{}
+
+	server := &Server{
+		k8sClient:           fakeDynamicClient,
+		sessionStore:        mockStore,
+		ProvisioningTimeout: 300 * time.Millisecond,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(20 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				list, err := fakeDynamicClient.Resource(codeInterpreterGVR).Namespace(consts.AgentLandSandboxesNamespace).List(context.Background(), metav1.ListOptions{})
+				if err != nil || len(list.Items) == 0 {
+					continue
+				}
+				upsertSandboxStatus(fakeDynamicClient, list.Items[0].GetName(), "Pending", "")
+			}
+		}
+	}()
+	defer close(done)
+
+	start := time.Now()
+	resp, err := server.CreateCodeInterpreter(context.Background(), &pb.CreateSandboxRequest{})
+	s.Nil(resp)
+	s.Less(time.Since(start), 5*time.Second)
+
+	s.ErrorIs(err, ErrProvisioningTimeout)
+	var timeoutErr *ProvisioningTimeoutError
+	s.Require().ErrorAs(err, &timeoutErr)
+	s.Equal("Pending", timeoutErr.Phase)
+	s.Equal(300*time.Millisecond, timeoutErr.Timeout)
+	s.Equal(grpccodes.DeadlineExceeded, grpcstatus.Code(err))
+	s.Empty(mockStore.created)
+}
+
+func (s *AgentCoreSuite) TestProvisioningTimeoutDefault() {
+	s.Equal(defaultProvisioningTimeout, (&Server{}).provisioningTimeout())
+	s.Equal(2*time.Minute, (&Server{ProvisioningTimeout: 2 * time.Minute}).provisioningTimeout())
+}
+
+func (s *AgentCoreSuite) TestCreateSandbox_OwnerQuotaBoundary() {
+	scheme := runtime.NewScheme()
+	s.NoError(v1alpha1.AddToScheme(scheme))
+	fakeDynamicClient := fake.NewSimpleDynamicClient(scheme)
+	installGenerateNameReactor(fakeDynamicClient)
+	mockStore := &mockSessionStore{ownerCounts: map[string]int64{"alice": 2, "bob": 1}}
+
+	server := &Server{
+		k8sClient:           fakeDynamicClient,
+		sessionStore:        mockStore,
+		MaxSessionsPerOwner: 2,
+	}
+
+	// 已达上限时在创建 CR 之前拒绝
+	resp, err := server.CreateCodeInterpreter(context.Background(), &pb.CreateSandboxRequest{Owner: "alice"})
+	s.Nil(resp)
+	s.Equal(grpccodes.ResourceExhausted, grpcstatus.Code(err))
+	list, err := fakeDynamicClient.Resource(codeInterpreterGVR).Namespace(consts.AgentLandSandboxesNamespace).List(context.Background(), metav1.ListOptions{})
+	s.NoError(err)
+	s.Empty(list.Items)
+
+	resp, err = server.CreateCodeInterpreter(context.Background(), &pb.CreateSandboxRequest{Owner: "not a label!"})
+	s.Nil(resp)
+	s.Equal(grpccodes.InvalidArgument, grpcstatus.Code(err))
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(20 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				list, err := fakeDynamicClient.Resource(codeInterpreterGVR).Namespace(consts.AgentLandSandboxesNamespace).List(context.Background(), metav1.ListOptions{})
+				if err != nil || len(list.Items) == 0 {
+					continue
+				}
+				upsertSandboxStatus(fakeDynamicClient, list.Items[0].GetName(), "Running", "10.42.0.12")
+			}
+		}
+	}()
+	defer close(done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 低于上限时正常创建，owner 写入标签与会话信息
+	resp, err = server.CreateCodeInterpreter(ctx, &pb.CreateSandboxRequest{Owner: "bob"})
+	s.Require().NoError(err)
+	s.NotNil(resp)
+
+	s.Require().Len(mockStore.created, 1)
+	s.Equal("bob", mockStore.created[0].Owner)
+	s.Equal(codeInterpreterGVR.Resource, mockStore.created[0].Resource)
+
+	list, err = fakeDynamicClient.Resource(codeInterpreterGVR).Namespace(consts.AgentLandSandboxesNamespace).List(context.Background(), metav1.ListOptions{})
+	s.NoError(err)
+	s.Require().Len(list.Items, 1)
+	s.Equal("bob", list.Items[0].GetLabels()[commonutils.OwnerLabel])
+	// 创建成功后占位随之释放，会话以自身 ID 计入配额
+	s.Zero(mockStore.pendingReservations("bob"))
+}
+
+func (s *AgentCoreSuite) TestCreateSandbox_ConcurrentCreatesReserveOwnerQuota() {
+	scheme := runtime.NewScheme()
+	s.NoError(v1alpha1.AddToScheme(scheme))
+	fakeDynamicClient := fake.NewSimpleDynamicClient(scheme)
+	installGenerateNameReactor(fakeDynamicClient)
+
+	// 拿到名额的请求阻塞在创建 CR 处，直到其余请求都已完成配额检查
+	entered := make(chan struct{}, 8)
+	gate := make(chan struct{})
+	fakeDynamicClient.PrependReactor("create", codeInterpreterGVR.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		entered <- struct{}{}
+		<-gate
+		return true, nil, k8serrors.NewForbidden(codeInterpreterGVR.GroupResource(), "", fmt.Errorf("denied"))
+	})
+	mockStore := &mockSessionStore{ownerCounts: map[string]int64{"alice": 1}}
+
+	server := &Server{
+		k8sClient:           fakeDynamicClient,
+		sessionStore:        mockStore,
+		MaxSessionsPerOwner: 3,
+	}
+
+	const workers = 6
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			_, err := server.CreateCodeInterpreter(context.Background(), &pb.CreateSandboxRequest{Owner: "alice"})
+			errs <- err
+		}()
+	}
+
+	// 已有 1 个会话、上限为 3，只有 2 个并发请求能通过配额检查
+	exhausted := 0
+	for i := 0; i < workers-2; i++ {
+		err := <-errs
+		s.Equal(grpccodes.ResourceExhausted, grpcstatus.Code(err))
+		exhausted++
+	}
+	<-entered
+	s.Equal(workers-2, exhausted)
+	s.Equal(2, mockStore.pendingReservations("alice"))
+
+	// 创建失败时归还名额
+	close(gate)
+	for i := 0; i < 2; i++ {
+		err := <-errs
+		s.Error(err)
+		s.NotEqual(grpccodes.ResourceExhausted, grpcstatus.Code(err))
+	}
+	s.Zero(mockStore.pendingReservations("alice"))
+}
+
 func (s *AgentCoreSuite) TestCreateAgentSession() {
 	scheme := runtime.NewScheme()
 	s.NoError(v1alpha1.AddToScheme(scheme))
@@ -376,3 +548,373 @@ func (s *AgentCoreSuite) TestGetAndDeleteAgentSession() {
 	s.Len(list.Items, 0)
 	s.Contains(mockStore.deleted, "session-to-delete")
 }
+
+func (s *AgentCoreSuite) TestDeleteCodeInterpreter() {
+	scheme := runtime.NewScheme()
+	s.NoError(v1alpha1.AddToScheme(scheme))
+
+	obj := &v1alpha1.CodeInterpreter{
+		TypeMeta: metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "CodeInterpreter"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "session-ci-delete",
+			Namespace: consts.AgentLandSandboxesNamespace,
+		},
+		Spec: v1alpha1.CodeInterpreterSpec{
+			Template: &v1alpha1.SandboxTemplate{Image: "korokd:latest"},
+		},
+	}
+
+	fakeDynamicClient := fake.NewSimpleDynamicClient(scheme, obj)
+	mockStore := &mockSessionStore{}
+
+	server := &Server{
+		k8sClient:    fakeDynamicClient,
+		sessionStore: mockStore,
+	}
+
+	_, err := server.DeleteCodeInterpreter(context.Background(), &pb.DeleteSandboxRequest{})
+	s.Error(err)
+
+	_, err = server.DeleteCodeInterpreter(context.Background(), &pb.DeleteSandboxRequest{SandboxId: "session-ci-delete"})
+	s.NoError(err)
+
+	list, err := fakeDynamicClient.Resource(codeInterpreterGVR).Namespace(consts.AgentLandSandboxesNamespace).List(context.Background(), metav1.ListOptions{})
+	s.NoError(err)
+	s.Len(list.Items, 0)
+	s.Equal([]string{"session-ci-delete"}, mockStore.deleted)
+
+	// 重复删除时 CR 已不存在，仍视为成功
+	_, err = server.DeleteCodeInterpreter(context.Background(), &pb.DeleteSandboxRequest{SandboxId: "session-ci-delete"})
+	s.NoError(err)
+}
+
+func (s *AgentCoreSuite) TestGetCodeInterpreter() {
+	scheme := runtime.NewScheme()
+	s.NoError(v1alpha1.AddToScheme(scheme))
+
+	newCR := func(name string, status v1alpha1.CodeInterpreterStatus) *v1alpha1.CodeInterpreter {
+		return &v1alpha1.CodeInterpreter{
+			TypeMeta: metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "CodeInterpreter"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: consts.AgentLandSandboxesNamespace,
+			},
+			Spec: v1alpha1.CodeInterpreterSpec{
+				Template: &v1alpha1.SandboxTemplate{Image: "korokd:latest"},
+			},
+			Status: status,
+		}
+	}
+
+	running := newCR("session-running", v1alpha1.CodeInterpreterStatus{
+		Phase:       "Running",
+		PodIP:       "10.42.0.40",
+		ClaimName:   "session-running",
+		SandboxName: "sandbox-warm-1",
+	})
+	failed := newCR("session-failed", v1alpha1.CodeInterpreterStatus{
+		Phase:     "Failed",
+		ClaimName: "session-failed",
+		Conditions: []metav1.Condition{
+			{
+				Type:               "Accepted",
+				Status:             metav1.ConditionFalse,
+				Reason:             "PoolExhausted",
+				Message:            "no warm pod available",
+				LastTransitionTime: metav1.Now(),
+			},
+		},
+	})
+	pending := newCR("session-pending", v1alpha1.CodeInterpreterStatus{})
+
+	server := &Server{
+		k8sClient:    fake.NewSimpleDynamicClient(scheme, running, failed, pending),
+		sessionStore: &mockSessionStore{},
+	}
+
+	resp, err := server.GetCodeInterpreter(context.Background(), &pb.GetSandboxRequest{SandboxId: "session-running"})
+	s.NoError(err)
+	s.Equal("Running", resp.Phase)
+	s.Equal("10.42.0.40", resp.PodIp)
+	s.Equal("session-running", resp.ClaimName)
+	s.Equal("sandbox-warm-1", resp.SandboxName)
+	s.Empty(resp.Reason)
+
+	resp, err = server.GetCodeInterpreter(context.Background(), &pb.GetSandboxRequest{SandboxId: "session-failed"})
+	s.NoError(err)
+	s.Equal("Failed", resp.Phase)
+	s.Equal("PoolExhausted", resp.Reason)
+	s.Equal("no warm pod available", resp.Message)
+
+	resp, err = server.GetCodeInterpreter(context.Background(), &pb.GetSandboxRequest{SandboxId: "session-pending"})
+	s.NoError(err)
+	s.Equal("session-pending", resp.SandboxId)
+	s.Empty(resp.Phase)
+
+	_, err = server.GetCodeInterpreter(context.Background(), &pb.GetSandboxRequest{SandboxId: "session-missing"})
+	s.Equal(grpccodes.NotFound, grpcstatus.Code(err))
+
+	_, err = server.GetCodeInterpreter(context.Background(), &pb.GetSandboxRequest{})
+	s.Equal(grpccodes.InvalidArgument, grpcstatus.Code(err))
+}
+
+func (s *AgentCoreSuite) TestCreateCodeInterpreter_RetriesTransientCreateError() {
+	defer func(d time.Duration) { k8sRetryBaseDelay = d }(k8sRetryBaseDelay)
+	k8sRetryBaseDelay = time.Millisecond
+
+	scheme := runtime.NewScheme()
+	s.NoError(v1alpha1.AddToScheme(scheme))
+	fakeDynamicClient := fake.NewSimpleDynamicClient(scheme)
+	installGenerateNameReactor(fakeDynamicClient)
+	createCalls := 0
+	fakeDynamicClient.PrependReactor("create", codeInterpreterGVR.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		createCalls++
+		if createCalls == 1 {
+			return true, nil, k8serrors.NewTooManyRequests("slow down", 0)
+		}
+		return false, nil, nil
+	})
+
+	server := &Server{
+		k8sClient:    fakeDynamicClient,
+		sessionStore: &mockSessionStore{},
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(20 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				list, err := fakeDynamicClient.Resource(codeInterpreterGVR).Namespace(consts.AgentLandSandboxesNamespace).List(context.Background(), metav1.ListOptions{})
+				if err != nil || len(list.Items) == 0 {
+					continue
+				}
+				upsertSandboxStatus(fakeDynamicClient, list.Items[0].GetName(), "Running", "10.42.0.50")
+			}
+		}
+	}()
+	defer close(done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := server.CreateCodeInterpreter(ctx, &pb.CreateSandboxRequest{})
+	s.Require().NoError(err)
+	s.Equal("10.42.0.50:1883", resp.GrpcEndpoint)
+	s.Equal(2, createCalls)
+}
+
+func (s *AgentCoreSuite) TestCreateCodeInterpreter_DoesNotRetryCreateTimeout() {
+	defer func(d time.Duration) { k8sRetryBaseDelay = d }(k8sRetryBaseDelay)
+	k8sRetryBaseDelay = time.Millisecond
+
+	scheme := runtime.NewScheme()
+	s.NoError(v1alpha1.AddToScheme(scheme))
+	fakeDynamicClient := fake.NewSimpleDynamicClient(scheme)
+	createCalls := 0
+	// 超时的创建请求可能已在服务端生效，重试会产生重复的 CodeInterpreter
+	fakeDynamicClient.PrependReactor("create", codeInterpreterGVR.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		createCalls++
+		if createCalls%2 == 1 {
+			return true, nil, k8serrors.NewServerTimeout(codeInterpreterGVR.GroupResource(), "create", 0)
+		}
+		return true, nil, k8serrors.NewTimeoutError("request timed out", 0)
+	})
+
+	server := &Server{
+		k8sClient:    fakeDynamicClient,
+		sessionStore: &mockSessionStore{},
+	}
+
+	for _, want := range []func(error) bool{k8serrors.IsServerTimeout, k8serrors.IsTimeout} {
+		_, err := server.CreateCodeInterpreter(context.Background(), &pb.CreateSandboxRequest{})
+		s.Error(err)
+		s.True(want(err))
+	}
+	s.Equal(2, createCalls)
+	s.False(isRetriableK8sCreateError(k8serrors.NewConflict(codeInterpreterGVR.GroupResource(), "session-1", nil)))
+}
+
+func (s *AgentCoreSuite) TestCreateCodeInterpreter_DoesNotRetryInvalidError() {
+	scheme := runtime.NewScheme()
+	s.NoError(v1alpha1.AddToScheme(scheme))
+	fakeDynamicClient := fake.NewSimpleDynamicClient(scheme)
+	createCalls := 0
+	fakeDynamicClient.PrependReactor("create", codeInterpreterGVR.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		createCalls++
+		return true, nil, k8serrors.NewInvalid(schema.GroupKind{Group: codeInterpreterGVR.Group, Kind: "CodeInterpreter"}, "", nil)
+	})
+
+	server := &Server{
+		k8sClient:    fakeDynamicClient,
+		sessionStore: &mockSessionStore{},
+	}
+
+	_, err := server.CreateCodeInterpreter(context.Background(), &pb.CreateSandboxRequest{})
+	s.Error(err)
+	s.True(k8serrors.IsInvalid(err))
+	s.Equal(1, createCalls)
+}
+
+func (s *AgentCoreSuite) TestRetryK8s_ReportsAttemptCount() {
+	defer func(d time.Duration) { k8sRetryBaseDelay = d }(k8sRetryBaseDelay)
+	k8sRetryBaseDelay = time.Millisecond
+
+	calls := 0
+	err := retryK8s(context.Background(), "create codeinterpreter", func() error {
+		calls++
+		return k8serrors.NewServerTimeout(codeInterpreterGVR.GroupResource(), "create", 0)
+	})
+	s.Error(err)
+	s.Equal(k8sRetryAttempts, calls)
+	s.Contains(err.Error(), fmt.Sprintf("failed after %d attempts", k8sRetryAttempts))
+	s.True(k8serrors.IsServerTimeout(err))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls = 0
+	err = retryK8s(ctx, "watch ready resource", func() error {
+		calls++
+		return k8serrors.NewConflict(sandboxGVR.GroupResource(), "session-1", nil)
+	})
+	s.ErrorIs(err, context.Canceled)
+	s.Equal(1, calls)
+}
+
+func (s *AgentCoreSuite) TestCreateAgentSession_IdempotencyKeyReusesSession() {
+	scheme := runtime.NewScheme()
+	s.NoError(v1alpha1.AddToScheme(scheme))
+	fakeDynamicClient := fake.NewSimpleDynamicClient(scheme)
+	installGenerateNameReactor(fakeDynamicClient)
+	mockStore := &mockSessionStore{}
+
+	server := &Server{
+		k8sClient:           fakeDynamicClient,
+		sessionStore:        mockStore,
+		MaxSessionsPerOwner: 1,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(20 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				list, err := fakeDynamicClient.Resource(agentSessionGVR).Namespace(consts.AgentLandSandboxesNamespace).List(context.Background(), metav1.ListOptions{})
+				if err != nil || len(list.Items) == 0 {
+					continue
+				}
+				upsertSandboxStatus(fakeDynamicClient, list.Items[0].GetName(), "Running", "10.42.0.60")
+			}
+		}
+	}()
+	defer close(done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := &pb.CreateAgentSessionRequest{
+		RuntimeName:    "default-runtime",
+		Owner:          "alice",
+		IdempotencyKey: "retry-1",
+	}
+	first, err := server.CreateAgentSession(ctx, req)
+	s.Require().NoError(err)
+	s.Equal(idempotentSessionName("alice/retry-1"), first.SessionId)
+
+	// 模拟会话已计入配额，重试仍应返回已有会话
+	mockStore.ownerCounts = map[string]int64{"alice": 1}
+	second, err := server.CreateAgentSession(ctx, req)
+	s.Require().NoError(err)
+	s.Equal(first.SessionId, second.SessionId)
+	s.Equal(first.GrpcEndpoint, second.GrpcEndpoint)
+
+	list, err := fakeDynamicClient.Resource(agentSessionGVR).Namespace(consts.AgentLandSandboxesNamespace).List(context.Background(), metav1.ListOptions{})
+	s.NoError(err)
+	s.Len(list.Items, 1)
+	s.Len(mockStore.created, 1)
+	s.Equal("alice/retry-1", mockStore.created[0].IdempotencyKey)
+
+	_, err = server.CreateAgentSession(ctx, &pb.CreateAgentSessionRequest{
+		RuntimeName:    "default-runtime",
+		IdempotencyKey: strings.Repeat("k", maxIdempotencyKeyLength+1),
+	})
+	s.Equal(grpccodes.InvalidArgument, grpcstatus.Code(err))
+}
+
+func (s *AgentCoreSuite) TestCreateAgentSession_IdempotencyKeyAlreadyExistsReusesCR() {
+	scheme := runtime.NewScheme()
+	s.NoError(v1alpha1.AddToScheme(scheme))
+	mockStore := &mockSessionStore{}
+	name := idempotentSessionName("/retry-2")
+	mockStore.created = []*db.SandboxInfo{{SandboxID: name, GrpcEndpoint: "10.42.0.61:1883"}}
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": agentSessionGVR.GroupVersion().String(),
+		"kind":       "AgentSession",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": consts.AgentLandSandboxesNamespace,
+		},
+	}}
+	fakeDynamicClient := fake.NewSimpleDynamicClient(scheme, existing)
+
+	server := &Server{
+		k8sClient:    fakeDynamicClient,
+		sessionStore: mockStore,
+	}
+
+	resp, err := server.CreateAgentSession(context.Background(), &pb.CreateAgentSessionRequest{
+		RuntimeName:    "default-runtime",
+		IdempotencyKey: "retry-2",
+	})
+	s.Require().NoError(err)
+	s.Equal(name, resp.SessionId)
+	s.Equal("10.42.0.61:1883", resp.GrpcEndpoint)
+
+	list, err := fakeDynamicClient.Resource(agentSessionGVR).Namespace(consts.AgentLandSandboxesNamespace).List(context.Background(), metav1.ListOptions{})
+	s.NoError(err)
+	s.Len(list.Items, 1)
+}
+
+func (s *AgentCoreSuite) TestGetAgentSessionReturnsAdvertisedPorts() {
+	scheme := runtime.NewScheme()
+	s.NoError(v1alpha1.AddToScheme(scheme))
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": agentSessionGVR.GroupVersion().String(),
+		"kind":       "AgentSession",
+		"metadata": map[string]interface{}{
+			"name":      "session-ports",
+			"namespace": consts.AgentLandSandboxesNamespace,
+		},
+		"status": map[string]interface{}{
+			"phase": "Running",
+			"ports": []interface{}{
+				map[string]interface{}{"port": int64(8080)},
+				map[string]interface{}{"port": int64(5173)},
+			},
+		},
+	}}
+
+	server := &Server{
+		k8sClient: fake.NewSimpleDynamicClient(scheme, obj),
+		sessionStore: &mockSessionStore{created: []*db.SandboxInfo{
+			{SandboxID: "session-ports", GrpcEndpoint: "10.42.0.70:1883"},
+		}},
+	}
+
+	resp, err := server.GetAgentSession(context.Background(), &pb.GetAgentSessionRequest{SessionId: "session-ports"})
+	s.Require().NoError(err)
+	s.Equal([]uint32{8080, 5173}, resp.Ports)
+
+	_, err = server.GetAgentSession(context.Background(), &pb.GetAgentSessionRequest{SessionId: "session-missing"})
+	s.Equal(grpccodes.NotFound, grpcstatus.Code(err))
+}
@@ -1,6 +1,10 @@
 package config
 
-import "k8s.io/client-go/dynamic"
+import (
+	"time"
+
+	"k8s.io/client-go/dynamic"
+)
 
 type Config struct {
 	Port string `json:"port"`
@@ -14,4 +18,10 @@ type Config struct {
 	WarmPoolDefaultMode string
 	WarmPoolPoolRef     string
 	WarmPoolProfile     string
+
+	// ProvisioningTimeout 为等待沙箱就绪的最长时间，<=0 时使用默认值
+	ProvisioningTimeout time.Duration
+
+	// MaxSessionsPerOwner 为单个 owner 允许的最大并发会话数，<=0 时不限制
+	MaxSessionsPerOwner int
 }
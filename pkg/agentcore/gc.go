@@ -8,10 +8,12 @@ import (
 
 	"github.com/Fl0rencess720/agentland/pkg/agentcore/pkgs/db"
 	"github.com/Fl0rencess720/agentland/pkg/common/consts"
+	"github.com/Fl0rencess720/agentland/pkg/common/utils"
 	"go.uber.org/zap"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 const (
@@ -53,6 +55,11 @@ func (s *Server) gcOnce(parent context.Context) error {
 		return fmt.Errorf("list expired sessions failed: %w", err)
 	}
 
+	var errs []error
+	if err := s.syncSessionActivity(ctx, now); err != nil {
+		errs = append(errs, fmt.Errorf("sync session activity failed: %w", err))
+	}
+
 	candidates := make(map[string]struct{}, len(inactiveIDs)+len(expiredIDs))
 	for _, id := range inactiveIDs {
 		if id != "" {
@@ -65,11 +72,6 @@ func (s *Server) gcOnce(parent context.Context) error {
 		}
 	}
 
-	if len(candidates) == 0 {
-		return nil
-	}
-
-	var errs []error
 	for sessionID := range candidates {
 		if err := s.deleteSessionCR(ctx, sessionID); err != nil {
 			errs = append(errs, fmt.Errorf("delete session CR %s failed: %w", sessionID, err))
@@ -91,6 +93,66 @@ func (s *Server) gcOnce(parent context.Context) error {
 	return nil
 }
 
+// syncSessionActivity 将网关记录在 Redis 中的最后活跃时间写入 AgentSession 的 LastActivityAnnotation，
+// 供控制器按 SessionTimeout 判断空闲过期；每轮只同步上次同步之后有活动的会话。
+func (s *Server) syncSessionActivity(ctx context.Context, now time.Time) error {
+	since := s.lastActivitySync
+	if since.IsZero() {
+		since = now.Add(-db.MaxIdleDuration)
+	}
+	// 活跃时间精度为秒，回退一秒避免边界上的更新被漏掉
+	active, err := s.sessionStore.ListActiveSessions(ctx, since.Add(-time.Second), sessionGCBatchLimit)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for sessionID, at := range active {
+		isAgentSession, err := s.isAgentSession(ctx, sessionID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("get session %s failed: %w", sessionID, err))
+			continue
+		}
+		if !isAgentSession {
+			continue
+		}
+		patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, utils.LastActivityAnnotation, at.UTC().Format(time.RFC3339Nano))
+		_, err = s.k8sClient.Resource(agentSessionGVR).
+			Namespace(consts.AgentLandSandboxesNamespace).
+			Patch(ctx, sessionID, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("patch agent session %s failed: %w", sessionID, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	// 结果被截断时只推进到本批最晚的活跃时间，其余会话留到下一轮同步
+	if int64(len(active)) >= sessionGCBatchLimit {
+		for _, at := range active {
+			if at.After(s.lastActivitySync) {
+				s.lastActivitySync = at
+			}
+		}
+		return nil
+	}
+	s.lastActivitySync = now
+	return nil
+}
+
+// isAgentSession 判断会话是否对应 AgentSession，CodeInterpreter 会话没有需要同步活跃时间的 CR。
+// 未记录资源类型的旧会话按 AgentSession 处理，由 Patch 的 NotFound 兜底。
+func (s *Server) isAgentSession(ctx context.Context, sessionID string) (bool, error) {
+	info, err := s.sessionStore.GetSession(ctx, sessionID)
+	if errors.Is(err, db.ErrSessionNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return info.Resource == "" || info.Resource == agentSessionGVR.Resource, nil
+}
+
 func (s *Server) deleteSessionCR(ctx context.Context, sessionID string) error {
 	if err := s.deleteSessionCRByGVR(ctx, codeInterpreterGVR, sessionID); err != nil {
 		return err
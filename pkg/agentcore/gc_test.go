@@ -2,15 +2,20 @@ package agentcore
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/Fl0rencess720/agentland/api/v1alpha1"
+	"github.com/Fl0rencess720/agentland/pkg/agentcore/pkgs/db"
 	"github.com/Fl0rencess720/agentland/pkg/common/consts"
+	"github.com/Fl0rencess720/agentland/pkg/common/utils"
 	"github.com/stretchr/testify/suite"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestAgentCoreGCSuite(t *testing.T) {
@@ -78,3 +83,74 @@ func (s *AgentCoreGCSuite) TestGCOnceDeletesInactiveAndExpiredSessions() {
 	sort.Strings(mockStore.deleted)
 	s.Equal([]string{"session-a", "session-b"}, mockStore.deleted)
 }
+
+func (s *AgentCoreGCSuite) TestGCOnceSyncsLastActivityToAgentSession() {
+	scheme := runtime.NewScheme()
+	s.NoError(v1alpha1.AddToScheme(scheme))
+
+	session := &v1alpha1.AgentSession{
+		TypeMeta: metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "AgentSession"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "agent-session-a",
+			Namespace: consts.AgentLandSandboxesNamespace,
+		},
+	}
+	fakeDynamicClient := fake.NewSimpleDynamicClient(scheme, session)
+	activeAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	mockStore := &mockSessionStore{
+		active: map[string]time.Time{
+			"agent-session-a": activeAt,
+			"code-session-b":  activeAt,
+		},
+		created: []*db.SandboxInfo{
+			{SandboxID: "agent-session-a", Resource: agentSessionGVR.Resource},
+			{SandboxID: "code-session-b", Resource: codeInterpreterGVR.Resource},
+		},
+	}
+	server := &Server{
+		k8sClient:    fakeDynamicClient,
+		sessionStore: mockStore,
+	}
+
+	s.Require().NoError(server.gcOnce(context.Background()))
+
+	got, err := fakeDynamicClient.Resource(agentSessionGVR).Namespace(consts.AgentLandSandboxesNamespace).
+		Get(context.Background(), "agent-session-a", metav1.GetOptions{})
+	s.Require().NoError(err)
+	s.Equal(activeAt.UTC().Format(time.RFC3339Nano), got.GetAnnotations()[utils.LastActivityAnnotation])
+	s.False(server.lastActivitySync.IsZero())
+
+	// CodeInterpreter 会话没有 AgentSession CR，不应发出 Patch
+	var patched []string
+	for _, action := range fakeDynamicClient.Actions() {
+		if patch, ok := action.(k8stesting.PatchAction); ok {
+			patched = append(patched, patch.GetName())
+		}
+	}
+	s.Equal([]string{"agent-session-a"}, patched)
+}
+
+func (s *AgentCoreGCSuite) TestSyncSessionActivityStopsAtTruncatedPage() {
+	scheme := runtime.NewScheme()
+	s.NoError(v1alpha1.AddToScheme(scheme))
+	fakeDynamicClient := fake.NewSimpleDynamicClient(scheme)
+
+	now := time.Now().Truncate(time.Second)
+	active := make(map[string]time.Time, sessionGCBatchLimit)
+	latest := now.Add(-time.Hour)
+	for i := int64(0); i < sessionGCBatchLimit; i++ {
+		at := now.Add(-10*time.Minute + time.Duration(i)*time.Second)
+		active[fmt.Sprintf("session-%d", i)] = at
+		if at.After(latest) {
+			latest = at
+		}
+	}
+	server := &Server{
+		k8sClient:    fakeDynamicClient,
+		sessionStore: &mockSessionStore{active: active},
+	}
+
+	// 结果达到批量上限时只推进到本批最晚的活跃时间，避免跳过未读到的会话
+	s.Require().NoError(server.syncSessionActivity(context.Background(), now))
+	s.Equal(latest, server.lastActivitySync)
+}
@@ -14,6 +14,8 @@ var (
 	keyPrefixSession     = "agentland:session:"      // 会话信息前缀
 	keyLastActivityIndex = "agentland:last-activity" // 按活跃时间排序的索引
 	keyExpiresAtIndex    = "agentland:expires-at"    // 按过期时间排序的索引
+	keyPrefixOwnerIndex  = "agentland:owner:"        // 按 owner 归属的会话索引前缀，成员分数为过期时间
+	keyPrefixIdempotency = "agentland:idempotency:"  // 幂等键到会话 ID 的映射前缀
 
 	MaxSessionDuration = 1 * time.Hour
 	MaxIdleDuration    = 15 * time.Minute
@@ -30,6 +32,11 @@ type SandboxInfo struct {
 	GrpcEndpoint string    `json:"grpc_endpoint"`
 	CreatedAt    time.Time `json:"created_at"`
 	ExpiresAt    time.Time `json:"expires_at"`
+	Owner        string    `json:"owner,omitempty"`
+	// IdempotencyKey 为创建会话时携带的幂等键（已按 owner 限定作用域），为空表示未使用
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	// Resource 为会话对应的 CR 资源名（如 agentsessions、codeinterpreters），为空表示旧版本写入的记录
+	Resource string `json:"resource,omitempty"`
 }
 
 func NewRedis() *redis.Client {
@@ -84,6 +91,19 @@ func (s *SessionStore) CreateSession(ctx context.Context, info *SandboxInfo) err
 		Score:  float64(info.ExpiresAt.Unix()),
 		Member: info.SandboxID,
 	})
+	if info.Owner != "" {
+		ownerKey := keyPrefixOwnerIndex + info.Owner
+		pipe.ZAdd(ctx, ownerKey, redis.Z{
+			Score:  float64(info.ExpiresAt.Unix()),
+			Member: info.SandboxID,
+		})
+		// owner 索引不早于其中最晚过期的会话过期
+		pipe.ExpireGT(ctx, ownerKey, ttl)
+		pipe.ExpireNX(ctx, ownerKey, ttl)
+	}
+	if info.IdempotencyKey != "" {
+		pipe.Set(ctx, keyPrefixIdempotency+info.IdempotencyKey, info.SandboxID, ttl)
+	}
 	if _, err = pipe.Exec(ctx); err != nil {
 		return err
 	}
@@ -95,10 +115,21 @@ func (s *SessionStore) CreateSession(ctx context.Context, info *SandboxInfo) err
 func (s *SessionStore) DeleteSession(ctx context.Context, sandboxID string) error {
 	key := keyPrefixSession + sandboxID
 
+	info, err := s.GetSession(ctx, sandboxID)
+	if err != nil && err != ErrSessionNotFound {
+		return err
+	}
+
 	pipe := s.client.Pipeline()
 	pipe.Del(ctx, key)
 	pipe.ZRem(ctx, keyLastActivityIndex, sandboxID)
 	pipe.ZRem(ctx, keyExpiresAtIndex, sandboxID)
+	if info != nil && info.Owner != "" {
+		pipe.ZRem(ctx, keyPrefixOwnerIndex+info.Owner, sandboxID)
+	}
+	if info != nil && info.IdempotencyKey != "" {
+		pipe.Del(ctx, keyPrefixIdempotency+info.IdempotencyKey)
+	}
 	if _, err := pipe.Exec(ctx); err != nil {
 		return err
 	}
@@ -126,6 +157,55 @@ func (s *SessionStore) GetSession(ctx context.Context, sandboxID string) (*Sandb
 	return &info, nil
 }
 
+// GetSessionByIdempotencyKey 按幂等键查找已创建的 Session，映射或会话不存在时返回 ErrSessionNotFound
+func (s *SessionStore) GetSessionByIdempotencyKey(ctx context.Context, key string) (*SandboxInfo, error) {
+	sandboxID, err := s.client.Get(ctx, keyPrefixIdempotency+key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return s.GetSession(ctx, sandboxID)
+}
+
+// ownerSlotReservationPrefix 为配额占位成员的前缀，与真实会话 ID 共用 owner 索引计数
+const ownerSlotReservationPrefix = "reservation:"
+
+// reserveOwnerSlotScript 在同一个脚本内清理过期成员、计数并写入占位，避免并发创建同时通过配额检查
+var reserveOwnerSlotScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', '(' .. ARGV[1])
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[4], ARGV[3])
+if redis.call('TTL', KEYS[1]) < tonumber(ARGV[5]) then
+	redis.call('EXPIRE', KEYS[1], ARGV[5])
+end
+return 1
+`)
+
+// ReserveOwnerSlot 在 owner 未过期的会话与占位数小于 limit 时原子地写入一个占位并返回 true。
+// 占位在 until 之后视为过期，保证进程异常退出时名额最终会被归还。
+func (s *SessionStore) ReserveOwnerSlot(ctx context.Context, owner, reservationID string, limit int64, now, until time.Time) (bool, error) {
+	ttl := int64(until.Sub(now).Seconds())
+	if ttl <= 0 {
+		return false, fmt.Errorf("owner slot reservation until is invalid: %s", until.Format(time.RFC3339))
+	}
+
+	reserved, err := reserveOwnerSlotScript.Run(ctx, s.client, []string{keyPrefixOwnerIndex + owner},
+		now.Unix(), limit, ownerSlotReservationPrefix+reservationID, until.Unix(), ttl).Int()
+	if err != nil {
+		return false, err
+	}
+	return reserved == 1, nil
+}
+
+// ReleaseOwnerSlot 删除 ReserveOwnerSlot 写入的占位
+func (s *SessionStore) ReleaseOwnerSlot(ctx context.Context, owner, reservationID string) error {
+	return s.client.ZRem(ctx, keyPrefixOwnerIndex+owner, ownerSlotReservationPrefix+reservationID).Err()
+}
+
 // ListInactiveSessions 返回超过 IdleTimeout 的 Session 列表
 func (s *SessionStore) ListInactiveSessions(ctx context.Context, before time.Time, limit int64) ([]string, error) {
 	// 查询 LastActivity < before 的 Session
@@ -152,3 +232,25 @@ func (s *SessionStore) ListExpiredSessions(ctx context.Context, now time.Time, l
 	}
 	return result, nil
 }
+
+// ListActiveSessions 返回 LastActivity >= since 的 Session 及其最后活跃时间
+func (s *SessionStore) ListActiveSessions(ctx context.Context, since time.Time, limit int64) (map[string]time.Time, error) {
+	result, err := s.client.ZRangeByScoreWithScores(ctx, keyLastActivityIndex, &redis.ZRangeBy{
+		Min:   fmt.Sprintf("%d", since.Unix()),
+		Max:   "+inf",
+		Count: limit,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	active := make(map[string]time.Time, len(result))
+	for _, z := range result {
+		id, ok := z.Member.(string)
+		if !ok || id == "" {
+			continue
+		}
+		active[id] = time.Unix(int64(z.Score), 0)
+	}
+	return active, nil
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSessionStore(t *testing.T) (*SessionStore, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return &SessionStore{client: client}, mr
+}
+
+func TestReserveOwnerSlot_ConcurrentReservationsRespectLimit(t *testing.T) {
+	store, mr := newTestSessionStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, store.CreateSession(ctx, &SandboxInfo{SandboxID: "session-1", Owner: "alice", ExpiresAt: now.Add(time.Hour)}))
+
+	const workers = 20
+	var (
+		wg       sync.WaitGroup
+		reserved atomic.Int64
+	)
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ok, err := store.ReserveOwnerSlot(ctx, "alice", fmt.Sprintf("r-%d", i), 3, now, now.Add(time.Minute))
+			if err != nil {
+				errs <- err
+				return
+			}
+			if ok {
+				reserved.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	// 已有 1 个会话，上限为 3 时只能有 2 个并发请求拿到名额
+	require.Equal(t, int64(2), reserved.Load())
+	members, err := mr.ZMembers(keyPrefixOwnerIndex + "alice")
+	require.NoError(t, err)
+	require.Len(t, members, 3)
+}
+
+func TestReserveOwnerSlot_ReleaseAndExpiry(t *testing.T) {
+	store, mr := newTestSessionStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	ok, err := store.ReserveOwnerSlot(ctx, "alice", "r-1", 1, now, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = store.ReserveOwnerSlot(ctx, "alice", "r-2", 1, now, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Positive(t, mr.TTL(keyPrefixOwnerIndex+"alice"))
+
+	// 释放后名额归还
+	require.NoError(t, store.ReleaseOwnerSlot(ctx, "alice", "r-1"))
+	ok, err = store.ReserveOwnerSlot(ctx, "alice", "r-2", 1, now, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// 未释放的占位过期后同样不再占用名额
+	later := now.Add(2 * time.Minute)
+	ok, err = store.ReserveOwnerSlot(ctx, "alice", "r-3", 1, later, later.Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, err = store.ReserveOwnerSlot(ctx, "alice", "r-4", 1, now, now)
+	require.Error(t, err)
+}
+
+func TestGetSessionByIdempotencyKey(t *testing.T) {
+	store, _ := newTestSessionStore(t)
+	ctx := context.Background()
+
+	_, err := store.GetSessionByIdempotencyKey(ctx, "alice/key-1")
+	require.ErrorIs(t, err, ErrSessionNotFound)
+
+	require.NoError(t, store.CreateSession(ctx, &SandboxInfo{SandboxID: "session-1", Owner: "alice", IdempotencyKey: "alice/key-1"}))
+	got, err := store.GetSessionByIdempotencyKey(ctx, "alice/key-1")
+	require.NoError(t, err)
+	require.Equal(t, "session-1", got.SandboxID)
+
+	require.NoError(t, store.DeleteSession(ctx, "session-1"))
+	_, err = store.GetSessionByIdempotencyKey(ctx, "alice/key-1")
+	require.ErrorIs(t, err, ErrSessionNotFound)
+}
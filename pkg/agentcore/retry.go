@@ -0,0 +1,80 @@
+package agentcore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+var (
+	// k8sRetryAttempts 为 k8s API 调用的最大尝试次数（含首次）
+	k8sRetryAttempts = 5
+	// k8sRetryBaseDelay 为首次重试前的等待时间，此后每次翻倍，不超过 k8sRetryMaxDelay
+	k8sRetryBaseDelay = 100 * time.Millisecond
+	k8sRetryMaxDelay  = 2 * time.Second
+)
+
+// isRetriableK8sError 判断 k8s API 错误是否为暂时性错误；校验失败等错误重试也不会成功，直接返回
+func isRetriableK8sError(err error) bool {
+	return apierrors.IsConflict(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err)
+}
+
+// isRetriableK8sCreateError 判断创建请求能否安全重试。创建使用 GenerateName，超时的请求可能已在服务端创建成功，
+// 重试会留下占用 Pod 的重复资源；只有明确未被处理的限流错误才重试，Conflict 对创建没有意义
+func isRetriableK8sCreateError(err error) bool {
+	return apierrors.IsTooManyRequests(err)
+}
+
+// retryK8s 以有界指数退避重试 fn，仅对暂时性错误重试，等待期间响应 ctx 取消。
+// 重试后仍失败时返回的错误携带尝试次数，并保留原始错误以便 apierrors 判断
+func retryK8s(ctx context.Context, op string, fn func() error) error {
+	return retryK8sWhen(ctx, op, isRetriableK8sError, fn)
+}
+
+// retryK8sCreate 以 retryK8s 相同的退避策略重试非幂等的创建请求，仅对 isRetriableK8sCreateError 重试
+func retryK8sCreate(ctx context.Context, op string, fn func() error) error {
+	return retryK8sWhen(ctx, op, isRetriableK8sCreateError, fn)
+}
+
+func retryK8sWhen(ctx context.Context, op string, retriable func(error) bool, fn func() error) error {
+	delay := k8sRetryBaseDelay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if !retriable(err) {
+			if attempt == 1 {
+				return err
+			}
+			return fmt.Errorf("%s failed after %d attempts: %w", op, attempt, err)
+		}
+		if attempt >= k8sRetryAttempts {
+			return fmt.Errorf("%s failed after %d attempts: %w", op, attempt, err)
+		}
+
+		zap.L().Warn("Retrying k8s API call after transient error",
+			zap.String("op", op),
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s failed after %d attempts: %w (last error: %v)", op, attempt, ctx.Err(), err)
+		case <-timer.C:
+		}
+		delay *= 2
+		if delay > k8sRetryMaxDelay {
+			delay = k8sRetryMaxDelay
+		}
+	}
+}
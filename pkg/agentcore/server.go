@@ -18,9 +18,13 @@ import (
 type sessionStore interface {
 	CreateSession(ctx context.Context, info *db.SandboxInfo) error
 	GetSession(ctx context.Context, sandboxID string) (*db.SandboxInfo, error)
+	GetSessionByIdempotencyKey(ctx context.Context, key string) (*db.SandboxInfo, error)
 	DeleteSession(ctx context.Context, sandboxID string) error
 	ListInactiveSessions(ctx context.Context, before time.Time, limit int64) ([]string, error)
 	ListExpiredSessions(ctx context.Context, now time.Time, limit int64) ([]string, error)
+	ListActiveSessions(ctx context.Context, since time.Time, limit int64) (map[string]time.Time, error)
+	ReserveOwnerSlot(ctx context.Context, owner, reservationID string, limit int64, now, until time.Time) (bool, error)
+	ReleaseOwnerSlot(ctx context.Context, owner, reservationID string) error
 }
 
 type Server struct {
@@ -39,6 +43,15 @@ type Server struct {
 	warmPoolDefaultMode string
 	warmPoolPoolRef     string
 	warmPoolProfile     string
+
+	// ProvisioningTimeout 为等待沙箱进入 Running 的最长时间，零值使用 defaultProvisioningTimeout
+	ProvisioningTimeout time.Duration
+
+	// MaxSessionsPerOwner 为单个 owner 的并发会话上限，<=0 时不限制
+	MaxSessionsPerOwner int
+
+	// lastActivitySync 为上次将活跃时间同步到 AgentSession 注解的时间，仅由 GC 循环访问
+	lastActivitySync time.Time
 }
 
 func NewServer(cfg *config.Config) (*Server, error) {
@@ -75,6 +88,9 @@ func NewServer(cfg *config.Config) (*Server, error) {
 		warmPoolDefaultMode: cfg.WarmPoolDefaultMode,
 		warmPoolPoolRef:     cfg.WarmPoolPoolRef,
 		warmPoolProfile:     cfg.WarmPoolProfile,
+
+		ProvisioningTimeout: cfg.ProvisioningTimeout,
+		MaxSessionsPerOwner: cfg.MaxSessionsPerOwner,
 	}
 
 	pb.RegisterAgentCoreServiceServer(server, s)
@@ -2,7 +2,7 @@ package agentcore
 
 import (
 	"context"
-	"fmt"
+	"sync"
 	"time"
 
 	"github.com/Fl0rencess720/agentland/pkg/agentcore/pkgs/db"
@@ -16,8 +16,15 @@ type mockSessionStore struct {
 
 	inactive []string
 	expired  []string
+	active   map[string]time.Time
 	created  []*db.SandboxInfo
 	deleted  []string
+	// ownerCounts 为各 owner 已计入配额的会话数
+	ownerCounts map[string]int64
+
+	quotaMu sync.Mutex
+	// reservations 为各 owner 尚未释放的配额占位
+	reservations map[string]map[string]struct{}
 }
 
 func (m *mockSessionStore) CreateSession(ctx context.Context, info *db.SandboxInfo) error {
@@ -38,7 +45,17 @@ func (m *mockSessionStore) GetSession(ctx context.Context, sandboxID string) (*d
 			return &cloned, nil
 		}
 	}
-	return nil, fmt.Errorf("session not found")
+	return nil, db.ErrSessionNotFound
+}
+
+func (m *mockSessionStore) GetSessionByIdempotencyKey(ctx context.Context, key string) (*db.SandboxInfo, error) {
+	for _, item := range m.created {
+		if item != nil && item.IdempotencyKey == key {
+			cloned := *item
+			return &cloned, nil
+		}
+	}
+	return nil, db.ErrSessionNotFound
 }
 
 func (m *mockSessionStore) DeleteSession(ctx context.Context, sandboxID string) error {
@@ -68,3 +85,42 @@ func (m *mockSessionStore) ListExpiredSessions(ctx context.Context, now time.Tim
 	copy(result, m.expired)
 	return result, nil
 }
+
+func (m *mockSessionStore) ListActiveSessions(ctx context.Context, since time.Time, limit int64) (map[string]time.Time, error) {
+	result := make(map[string]time.Time, len(m.active))
+	for id, at := range m.active {
+		if !at.Before(since) {
+			result[id] = at
+		}
+	}
+	return result, nil
+}
+
+func (m *mockSessionStore) ReserveOwnerSlot(ctx context.Context, owner, reservationID string, limit int64, now, until time.Time) (bool, error) {
+	m.quotaMu.Lock()
+	defer m.quotaMu.Unlock()
+	if m.ownerCounts[owner]+int64(len(m.reservations[owner])) >= limit {
+		return false, nil
+	}
+	if m.reservations == nil {
+		m.reservations = map[string]map[string]struct{}{}
+	}
+	if m.reservations[owner] == nil {
+		m.reservations[owner] = map[string]struct{}{}
+	}
+	m.reservations[owner][reservationID] = struct{}{}
+	return true, nil
+}
+
+func (m *mockSessionStore) ReleaseOwnerSlot(ctx context.Context, owner, reservationID string) error {
+	m.quotaMu.Lock()
+	defer m.quotaMu.Unlock()
+	delete(m.reservations[owner], reservationID)
+	return nil
+}
+
+func (m *mockSessionStore) pendingReservations(owner string) int {
+	m.quotaMu.Lock()
+	defer m.quotaMu.Unlock()
+	return len(m.reservations[owner])
+}
@@ -0,0 +1,217 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// codeHashLength 为审计记录中代码哈希保留的十六进制字符数
+	codeHashLength = 16
+	// maxAuditCodeBytes 为开启 IncludeCode 时记录的代码最大字节数
+	maxAuditCodeBytes = 4096
+	// minRedactLength 为需要脱敏的环境变量值最小长度，过短的值替换会误伤正常代码
+	minRedactLength = 4
+
+	redactedPlaceholder = "[REDACTED]"
+)
+
+const (
+	SourceKorokd  = "korokd"
+	SourceGateway = "gateway"
+)
+
+// Event 为一次代码执行的审计记录，默认只记录代码哈希，不记录代码原文与注入的环境变量
+type Event struct {
+	Time      time.Time `json:"time"`
+	Source    string    `json:"source"`
+	SessionID string    `json:"session_id"`
+	Subject   string    `json:"subject,omitempty"`
+	ContextID string    `json:"context_id"`
+	Language  string    `json:"language,omitempty"`
+	CodeHash  string    `json:"code_hash"`
+	CodeBytes int       `json:"code_bytes"`
+	Code      string    `json:"code,omitempty"`
+	// ExitCode 仅在执行端可知，网关侧记录为空
+	ExitCode   *int32 `json:"exit_code,omitempty"`
+	HTTPStatus int    `json:"http_status,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Sink 为审计记录的输出端
+type Sink interface {
+	Write(event Event)
+}
+
+// ZapSink 将审计记录作为结构化日志写入 zap logger
+type ZapSink struct {
+	logger *zap.Logger
+}
+
+// NewZapSink 创建写入 logger 的 Sink，logger 为空时使用全局 logger
+func NewZapSink(logger *zap.Logger) *ZapSink {
+	return &ZapSink{logger: logger}
+}
+
+func (s *ZapSink) Write(event Event) {
+	logger := s.logger
+	if logger == nil {
+		logger = zap.L()
+	}
+
+	fields := []zap.Field{
+		zap.String("source", event.Source),
+		zap.String("session_id", event.SessionID),
+		zap.String("context_id", event.ContextID),
+		zap.String("language", event.Language),
+		zap.String("code_hash", event.CodeHash),
+		zap.Int("code_bytes", event.CodeBytes),
+		zap.Int64("duration_ms", event.DurationMs),
+	}
+	if event.Code != "" {
+		fields = append(fields, zap.String("code", event.Code))
+	}
+	if event.ExitCode != nil {
+		fields = append(fields, zap.Int32("exit_code", *event.ExitCode))
+	}
+	if event.HTTPStatus != 0 {
+		fields = append(fields, zap.Int("http_status", event.HTTPStatus))
+	}
+	if event.Error != "" {
+		fields = append(fields, zap.String("error", event.Error))
+	}
+	logger.Info("Code execution audit", fields...)
+}
+
+// FileSink 以 JSON Lines 格式将审计记录追加写入文件
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// NewFileSink 以追加模式打开 path，文件不存在时创建
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log file failed: %w", err)
+	}
+	return &FileSink{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+func (s *FileSink) Write(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.enc.Encode(event); err != nil {
+		zap.L().Warn("Write audit event failed", zap.Error(err))
+	}
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// NewSink 按配置创建 Sink：filePath 为空时写入全局 zap logger，否则追加写入文件
+func NewSink(filePath string) (Sink, error) {
+	if strings.TrimSpace(filePath) == "" {
+		return NewZapSink(nil), nil
+	}
+	return NewFileSink(filePath)
+}
+
+// Execution 描述一次待审计的代码执行
+type Execution struct {
+	Source     string
+	SessionID  string
+	Subject    string
+	ContextID  string
+	Language   string
+	Code       string
+	ExitCode   *int32
+	HTTPStatus int
+	Duration   time.Duration
+	Err        error
+	// Secrets 为执行上下文注入的环境变量值，出现在记录中的位置会被替换为占位符
+	Secrets []string
+}
+
+// Recorder 将执行信息转换为审计记录写入 Sink，nil Recorder 不做任何事
+type Recorder struct {
+	sink        Sink
+	includeCode bool
+	now         func() time.Time
+}
+
+// NewRecorder 创建 Recorder，includeCode 为 true 时额外记录截断并脱敏后的代码原文
+func NewRecorder(sink Sink, includeCode bool) *Recorder {
+	return &Recorder{sink: sink, includeCode: includeCode, now: time.Now}
+}
+
+func (r *Recorder) Record(exec Execution) {
+	if r == nil || r.sink == nil {
+		return
+	}
+
+	event := Event{
+		Time:       r.now().UTC(),
+		Source:     exec.Source,
+		SessionID:  exec.SessionID,
+		Subject:    exec.Subject,
+		ContextID:  exec.ContextID,
+		Language:   exec.Language,
+		CodeHash:   HashCode(exec.Code),
+		CodeBytes:  len(exec.Code),
+		ExitCode:   exec.ExitCode,
+		HTTPStatus: exec.HTTPStatus,
+		DurationMs: exec.Duration.Milliseconds(),
+	}
+	if r.includeCode {
+		event.Code = truncate(redact(exec.Code, exec.Secrets), maxAuditCodeBytes)
+	}
+	if exec.Err != nil {
+		event.Error = redact(exec.Err.Error(), exec.Secrets)
+	}
+
+	r.sink.Write(event)
+}
+
+// HashCode 返回代码 SHA-256 的截断十六进制表示，用于关联同一段代码的多次执行
+func HashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])[:codeHashLength]
+}
+
+func truncate(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	// 避免截断在多字节字符中间
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
+func redact(s string, secrets []string) string {
+	for _, secret := range secrets {
+		if len(secret) < minRedactLength {
+			continue
+		}
+		s = strings.ReplaceAll(s, secret, redactedPlaceholder)
+	}
+	return s
+}
@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type captureSink struct {
+	events []Event
+}
+
+func (s *captureSink) Write(event Event) {
+	s.events = append(s.events, event)
+}
+
+func TestRecorder_OmitsCodeByDefault(t *testing.T) {
+	sink := &captureSink{}
+	recorder := NewRecorder(sink, false)
+	recorder.now = func() time.Time { return time.Unix(1000, 0) }
+
+	exitCode := int32(1)
+	recorder.Record(Execution{
+		Source:    SourceKorokd,
+		SessionID: "session-1",
+		ContextID: "ctx-1",
+		Language:  "python",
+		Code:      "print('hello')",
+		ExitCode:  &exitCode,
+		Duration:  1500 * time.Millisecond,
+	})
+
+	require.Len(t, sink.events, 1)
+	event := sink.events[0]
+	require.Equal(t, time.Unix(1000, 0).UTC(), event.Time)
+	require.Equal(t, HashCode("print('hello')"), event.CodeHash)
+	require.Len(t, event.CodeHash, codeHashLength)
+	require.Equal(t, len("print('hello')"), event.CodeBytes)
+	require.Empty(t, event.Code)
+	require.Equal(t, int32(1), *event.ExitCode)
+	require.Equal(t, int64(1500), event.DurationMs)
+}
+
+func TestRecorder_RedactsSecrets(t *testing.T) {
+	sink := &captureSink{}
+	recorder := NewRecorder(sink, true)
+
+	recorder.Record(Execution{
+		Code:    `token = "sk-123456"; x = 1`,
+		Err:     errors.New("kernel died with sk-123456"),
+		Secrets: []string{"sk-123456", "1"},
+	})
+
+	require.Len(t, sink.events, 1)
+	event := sink.events[0]
+	require.Equal(t, `token = "[REDACTED]"; x = 1`, event.Code)
+	require.Equal(t, "kernel died with [REDACTED]", event.Error)
+}
+
+func TestRecorder_NilIsNoop(t *testing.T) {
+	var recorder *Recorder
+	recorder.Record(Execution{Code: "print(1)"})
+}
+
+func TestTruncate_KeepsRuneBoundary(t *testing.T) {
+	require.Equal(t, "ab", truncate("ab", 4))
+	require.Equal(t, "a", truncate("a中文", 3))
+	require.Equal(t, "a中", truncate("a中文", 4))
+}
+
+func TestFileSink_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+
+	recorder := NewRecorder(sink, false)
+	recorder.Record(Execution{Source: SourceGateway, SessionID: "session-1", ContextID: "ctx-1", Code: "a", HTTPStatus: 200})
+	recorder.Record(Execution{Source: SourceGateway, SessionID: "session-1", ContextID: "ctx-2", Code: "b", HTTPStatus: 502})
+	require.NoError(t, sink.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		require.False(t, strings.Contains(line, `"code":`), "code must not be logged by default")
+		var event Event
+		require.NoError(t, json.Unmarshal([]byte(line), &event))
+		events = append(events, event)
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, events, 2)
+	require.Equal(t, "ctx-2", events[1].ContextID)
+	require.Equal(t, 502, events[1].HTTPStatus)
+	require.Nil(t, events[1].ExitCode)
+}
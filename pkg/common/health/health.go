@@ -0,0 +1,100 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+	statusDraining    = "draining"
+
+	// defaultCheckTimeout 为单次 /readyz 所有依赖检查的总超时
+	defaultCheckTimeout = 2 * time.Second
+)
+
+// CheckFunc 检查单个依赖是否可用，返回 nil 表示就绪
+type CheckFunc func(ctx context.Context) error
+
+type namedCheck struct {
+	name string
+	fn   CheckFunc
+}
+
+// Prober 提供存活与就绪探针：/healthz 在服务运行期间恒为 200，
+// /readyz 依次执行已注册的依赖检查，服务开始关闭后返回 503 以便摘除流量。
+type Prober struct {
+	timeout  time.Duration
+	mu       sync.RWMutex
+	checks   []namedCheck
+	draining atomic.Bool
+}
+
+// NewProber 创建 Prober，timeout<=0 时使用默认超时
+func NewProber(timeout time.Duration) *Prober {
+	if timeout <= 0 {
+		timeout = defaultCheckTimeout
+	}
+	return &Prober{timeout: timeout}
+}
+
+// AddCheck 注册一个就绪检查
+func (p *Prober) AddCheck(name string, fn CheckFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checks = append(p.checks, namedCheck{name: name, fn: fn})
+}
+
+// SetDraining 标记服务正在关闭，此后 /readyz 恒返回 503
+func (p *Prober) SetDraining() {
+	p.draining.Store(true)
+}
+
+// Register 在路由上注册 /healthz 与 /readyz，两者均不需要鉴权
+func (p *Prober) Register(r gin.IRoutes) {
+	r.GET("/healthz", p.Healthz)
+	r.GET("/readyz", p.Readyz)
+}
+
+// Healthz 存活探针，只要进程能处理请求即返回 200
+func (p *Prober) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": statusOK})
+}
+
+// Readyz 就绪探针，任一依赖检查失败时返回 503 并附带失败原因
+func (p *Prober) Readyz(c *gin.Context) {
+	if p.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": statusDraining})
+		return
+	}
+
+	p.mu.RLock()
+	checks := append([]namedCheck(nil), p.checks...)
+	p.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), p.timeout)
+	defer cancel()
+
+	results := make(map[string]string, len(checks))
+	ready := true
+	for _, check := range checks {
+		if err := check.fn(ctx); err != nil {
+			results[check.name] = err.Error()
+			ready = false
+			continue
+		}
+		results[check.name] = statusOK
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": statusUnavailable, "checks": results})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": statusOK, "checks": results})
+}
@@ -0,0 +1,55 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func serve(t *testing.T, p *Prober, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	p.Register(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestProber_ReadyWhenAllChecksPass(t *testing.T) {
+	p := NewProber(0)
+	p.AddCheck("redis", func(ctx context.Context) error { return nil })
+
+	w := serve(t, p, "/readyz")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"status":"ok","checks":{"redis":"ok"}}`, w.Body.String())
+}
+
+func TestProber_NotReadyReportsFailedCheck(t *testing.T) {
+	p := NewProber(0)
+	p.AddCheck("redis", func(ctx context.Context) error { return nil })
+	p.AddCheck("agentcore", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	w := serve(t, p, "/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.JSONEq(t, `{"status":"unavailable","checks":{"redis":"ok","agentcore":"connection refused"}}`, w.Body.String())
+
+	// 依赖不可用不影响存活探针
+	require.Equal(t, http.StatusOK, serve(t, p, "/healthz").Code)
+}
+
+func TestProber_DrainingIsNotReady(t *testing.T) {
+	p := NewProber(0)
+	p.SetDraining()
+
+	w := serve(t, p, "/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Contains(t, w.Body.String(), "draining")
+	require.Equal(t, http.StatusOK, serve(t, p, "/healthz").Code)
+}
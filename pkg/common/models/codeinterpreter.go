@@ -2,8 +2,15 @@ package models
 
 // CreateContextReq 对应 POST /contexts 的请求体
 type CreateContextReq struct {
-	Language string `json:"language" jsonschema:"Execution language, supported values: python, bash"`
+	Language string `json:"language" jsonschema:"Execution language, supported values: python, bash, node"`
 	CWD      string `json:"cwd,omitempty" jsonschema:"Working directory inside sandbox, defaults to /workspace"`
+	// MemBytes/CPUMillis 为 0 时不限制
+	MemBytes  int64 `json:"mem_bytes,omitempty" jsonschema:"Optional address-space limit for the context's kernel in bytes, at least 64MiB"`
+	CPUMillis int64 `json:"cpu_millis,omitempty" jsonschema:"Optional CPU time limit for the context's kernel in milliseconds, rounded up to whole seconds"`
+	// Env 注入到 context 进程的环境变量，PATH/LD_PRELOAD 默认不允许覆盖
+	Env map[string]string `json:"env,omitempty" jsonschema:"Optional environment variables for the context's kernel; keys must match [A-Za-z_][A-Za-z0-9_]*"`
+	// Parallelism 大于 1 时为 context 创建多个 kernel，仅支持 python；各 kernel 的命名空间互相独立
+	Parallelism int `json:"parallelism,omitempty" jsonschema:"Optional number of python kernels backing the context (1-4); executions opting in with parallel run on any idle kernel, each with its own namespace"`
 }
 
 // CreateContextResp 创建上下文接口响应体
@@ -17,8 +24,18 @@ type CreateContextResp struct {
 
 // ExecuteContextReq 对应 POST /contexts/{contextId}/execute 的请求体
 type ExecuteContextReq struct {
-	Code      string `json:"code" jsonschema:"Code snippet to execute"`
-	TimeoutMs int    `json:"timeout_ms,omitempty" jsonschema:"Execution timeout in milliseconds, valid range is 100-300000"`
+	Code string `json:"code" jsonschema:"Code snippet to execute"`
+	// CodeEncoding 为空时 Code 为原始 UTF-8 源码，为 base64 时先解码再执行，避免大段脚本的 JSON 转义问题
+	CodeEncoding string `json:"code_encoding,omitempty" jsonschema:"Encoding of code, empty for raw UTF-8 or base64 for standard base64-encoded UTF-8 source"`
+	TimeoutMs    int    `json:"timeout_ms,omitempty" jsonschema:"Execution timeout in milliseconds, valid range is 100-300000"`
+	// Stdin 非空时作为程序的标准输入；显式传空串表示 stdin 立即 EOF
+	Stdin *string `json:"stdin,omitempty" jsonschema:"Optional data fed to the program's standard input"`
+	// ReturnVars 仅支持 python，开启后执行成功时额外返回全局变量快照
+	ReturnVars bool `json:"return_vars,omitempty" jsonschema:"Return top-level variable names and truncated repr() after a successful python execution"`
+	// CheckOnly 仅检查语法而不执行，支持 python 与 bash，语法错误时 exit_code 为 1
+	CheckOnly bool `json:"check_only,omitempty" jsonschema:"Only check the code for syntax errors without running it, supported for python and bash"`
+	// Parallel 为 true 时可在并行 context 的任一空闲 kernel 上执行，不保证看到其它执行定义的变量
+	Parallel bool `json:"parallel,omitempty" jsonschema:"Allow running on any idle kernel of a context created with parallelism > 1; state defined by executions on other kernels is not visible"`
 }
 
 // ExecuteContextResp 上下文执行接口响应体
@@ -28,7 +45,116 @@ type ExecuteContextResp struct {
 	ExitCode       int32  `json:"exit_code" jsonschema:"Process-like exit code, 0 means success"`
 	Stdout         string `json:"stdout" jsonschema:"Captured standard output"`
 	Stderr         string `json:"stderr" jsonschema:"Captured standard error"`
+	// StdoutTruncated/StderrTruncated 为 true 时对应输出超过 korokd 的 max_output_bytes，截断处带有提示
+	StdoutTruncated bool  `json:"stdout_truncated,omitempty" jsonschema:"Whether stdout exceeded the sandbox output limit and was truncated"`
+	StderrTruncated bool  `json:"stderr_truncated,omitempty" jsonschema:"Whether stderr exceeded the sandbox output limit and was truncated"`
+	DurationMs      int64 `json:"duration_ms" jsonschema:"Execution duration in milliseconds"`
+	// Results 为 display_data/execute_result 产生的富输出，按产生顺序排列
+	Results []RichOutput `json:"results,omitempty" jsonschema:"Rich outputs such as plots or HTML produced by the execution"`
+	// Variables 仅在请求 return_vars 且执行成功时返回，值为截断后的 repr()
+	Variables map[string]string `json:"variables,omitempty" jsonschema:"Top-level variable names mapped to their truncated repr(), present when return_vars is set"`
+}
+
+// ExecuteBatchCell 为批量执行中的一个代码单元
+type ExecuteBatchCell struct {
+	Code      string `json:"code" jsonschema:"Code snippet of this cell"`
+	TimeoutMs int    `json:"timeout_ms,omitempty" jsonschema:"Execution timeout of this cell in milliseconds, valid range is 100-300000"`
+}
+
+// ExecuteBatchReq 对应 POST /contexts/{contextId}/execute-batch 的请求体
+type ExecuteBatchReq struct {
+	Cells []ExecuteBatchCell `json:"cells" jsonschema:"Ordered code cells executed serially in the same context"`
+	// StopOnError 为 true 时遇到首个失败（执行错误或非零 exit_code）的单元即停止，后续单元不再执行
+	StopOnError bool `json:"stop_on_error,omitempty" jsonschema:"Stop at the first cell that fails or exits non-zero"`
+}
+
+// ExecuteBatchCellResult 为批量执行中单个代码单元的结果
+type ExecuteBatchCellResult struct {
+	Index int `json:"index" jsonschema:"Zero-based position of the cell in the request"`
+	ExecuteContextResp
+	// Error 为单元未能执行时的错误信息，此时 exit_code 等字段无意义
+	Error string `json:"error,omitempty" jsonschema:"Error message when the cell could not be executed"`
+}
+
+// ExecuteBatchResp 批量执行接口响应体
+type ExecuteBatchResp struct {
+	ContextID string                   `json:"context_id" jsonschema:"Context ID where the cells ran"`
+	Results   []ExecuteBatchCellResult `json:"results" jsonschema:"Per-cell results in execution order; cells after an early stop are omitted"`
+	Stopped   bool                     `json:"stopped" jsonschema:"Whether execution stopped before running every cell"`
+}
+
+// RichOutput 为一条富输出中的单个 MIME 表示
+type RichOutput struct {
+	MimeType string `json:"mime_type" jsonschema:"MIME type of the output, e.g. image/png or text/html"`
+	// Data 统一为 base64 编码；超过单条大小上限时为空并标记 Truncated
+	Data      string `json:"data" jsonschema:"Base64-encoded output payload"`
+	Truncated bool   `json:"truncated,omitempty" jsonschema:"Whether the payload was dropped for exceeding the size limit"`
+}
+
+// ContextInfo 描述一个存活上下文的运行时状态
+type ContextInfo struct {
+	ContextID      string `json:"context_id" jsonschema:"Context ID"`
+	Language       string `json:"language" jsonschema:"Execution language"`
+	CWD            string `json:"cwd" jsonschema:"Working directory the kernel initializes into"`
+	CurrentCWD     string `json:"current_cwd" jsonschema:"Working directory at the end of the last execution; tracks cd for bash contexts"`
+	KernelID       string `json:"kernel_id" jsonschema:"Underlying Jupyter kernel ID"`
+	ExecutionCount int64  `json:"execution_count" jsonschema:"Last observed execution counter in the context"`
+	Busy           bool   `json:"busy" jsonschema:"Whether an execution is currently running"`
+	Parallelism    int    `json:"parallelism" jsonschema:"Number of kernels backing the context"`
+	MemBytes       int64  `json:"mem_bytes,omitempty" jsonschema:"Address-space limit in bytes, omitted when unlimited"`
+	CPUMillis      int64  `json:"cpu_millis,omitempty" jsonschema:"CPU time limit in milliseconds, omitted when unlimited"`
+	CreatedAt      string `json:"created_at" jsonschema:"Context creation time in RFC3339 format"`
+	LastActiveAt   string `json:"last_active_at" jsonschema:"Last activity time in RFC3339 format"`
+}
+
+// RecycledContextInfo 描述近期被自动回收的上下文及回收原因
+type RecycledContextInfo struct {
+	ContextID  string `json:"context_id" jsonschema:"Context ID"`
+	Reason     string `json:"reason" jsonschema:"Why the context was recycled: timeout or idle"`
+	Detail     string `json:"detail,omitempty" jsonschema:"Human-readable details of the recycle"`
+	RecycledAt string `json:"recycled_at" jsonschema:"Recycle time in RFC3339 format"`
+}
+
+// ListContextsResp 对应 GET /contexts 的响应体
+type ListContextsResp struct {
+	Contexts []ContextInfo `json:"contexts" jsonschema:"Live contexts in the sandbox"`
+	// Recycled 为近期（10 分钟内）被自动回收的上下文，便于调用方解释 context 为何消失
+	Recycled []RecycledContextInfo `json:"recycled,omitempty" jsonschema:"Contexts recently recycled by korokd with the reason"`
+}
+
+// InterruptContextResp 中断上下文执行接口响应体
+type InterruptContextResp struct {
+	ContextID   string `json:"context_id" jsonschema:"Context ID"`
+	Interrupted bool   `json:"interrupted" jsonschema:"Whether a running execution was interrupted; false when the context was idle"`
+}
+
+// ExecutionHistoryEntry 为上下文中一次执行的摘要，不保存完整代码
+type ExecutionHistoryEntry struct {
+	ExecutionCount int64  `json:"execution_count" jsonschema:"Execution counter reported by the kernel, 0 when the execution failed to run"`
+	CodeHash       string `json:"code_hash" jsonschema:"Truncated SHA-256 of the executed code"`
+	CodePreview    string `json:"code_preview" jsonschema:"Leading part of the executed code"`
+	ExitCode       int32  `json:"exit_code" jsonschema:"Process-like exit code, 124 on timeout"`
 	DurationMs     int64  `json:"duration_ms" jsonschema:"Execution duration in milliseconds"`
+	StartedAt      string `json:"started_at" jsonschema:"Execution start time in RFC3339 format"`
+	Error          string `json:"error,omitempty" jsonschema:"Error message when the execution could not run"`
+}
+
+// ContextHistoryResp 对应 GET /contexts/{contextId}/history 的响应体
+type ContextHistoryResp struct {
+	ContextID string                  `json:"context_id" jsonschema:"Context ID"`
+	Entries   []ExecutionHistoryEntry `json:"entries" jsonschema:"Most recent executions since the last reset, oldest first"`
+}
+
+// InstallPackagesReq 对应 POST /contexts/{contextId}/install 的请求体
+type InstallPackagesReq struct {
+	Packages  []string `json:"packages" jsonschema:"Package requirements to install with pip, e.g. requests or numpy==2.1.0"`
+	TimeoutMs int      `json:"timeout_ms,omitempty" jsonschema:"Install timeout in milliseconds, valid range is 100-300000, defaults to 120000"`
+}
+
+// InstalledPackage 为安装完成后解析出的包版本
+type InstalledPackage struct {
+	Name    string `json:"name" jsonschema:"Installed distribution name"`
+	Version string `json:"version" jsonschema:"Installed version"`
 }
 
 // DeleteContextResp 删除上下文接口响应体
@@ -22,18 +22,102 @@ type FSTreeNode struct {
 	ModTime string `json:"modTime,omitempty" jsonschema:"Last modified time in RFC3339 format, only for files"`
 }
 
+// StatFSReq 对应 GET /fs/stat 的查询参数
+type StatFSReq struct {
+	Path string `json:"path" jsonschema:"File or directory path to inspect, relative or absolute"`
+}
+
+// StatFSResp 文件元信息接口响应体
+type StatFSResp struct {
+	Path       string `json:"path" jsonschema:"Normalized path"`
+	Name       string `json:"name" jsonschema:"Base name of the path"`
+	Size       int64  `json:"size" jsonschema:"Size in bytes as reported by the filesystem"`
+	Mode       string `json:"mode" jsonschema:"File mode string such as -rw-r--r--"`
+	Perm       string `json:"perm" jsonschema:"Permission bits in octal such as 0644"`
+	ModTime    string `json:"modTime" jsonschema:"Last modified time in RFC3339 format"`
+	IsDir      bool   `json:"isDir" jsonschema:"Whether the path is a directory"`
+	IsSymlink  bool   `json:"isSymlink" jsonschema:"Whether the path itself is a symbolic link"`
+	LinkTarget string `json:"linkTarget,omitempty" jsonschema:"Symbolic link target, only for symlinks"`
+	MimeType   string `json:"mimeType,omitempty" jsonschema:"Detected MIME type, only for regular files"`
+}
+
+// SearchFSReq 对应 GET /fs/search 的查询参数
+type SearchFSReq struct {
+	Query         string `json:"q" jsonschema:"Literal text to search for, case-sensitive"`
+	Path          string `json:"path,omitempty" jsonschema:"Directory path to search under, relative or absolute"`
+	Glob          string `json:"glob,omitempty" jsonschema:"Optional file name glob such as *.py, matched against base names"`
+	Depth         int    `json:"depth,omitempty" jsonschema:"Traversal depth, valid range is 1-20"`
+	IncludeHidden bool   `json:"includeHidden,omitempty" jsonschema:"Whether to search hidden files and directories"`
+	MaxResults    int    `json:"maxResults,omitempty" jsonschema:"Maximum number of matches to return, valid range is 1-1000"`
+}
+
+// SearchFSResp 搜索接口响应体
+type SearchFSResp struct {
+	Root         string          `json:"root" jsonschema:"Normalized root path of the search"`
+	Matches      []FSSearchMatch `json:"matches" jsonschema:"Matched lines ordered by path and line number"`
+	ScannedFiles int             `json:"scannedFiles" jsonschema:"Number of text files scanned"`
+	ScannedBytes int64           `json:"scannedBytes" jsonschema:"Total bytes scanned"`
+	Truncated    bool            `json:"truncated" jsonschema:"Whether the search stopped early due to result or scan limits"`
+}
+
+// FSSearchMatch 搜索结果中的单行匹配
+type FSSearchMatch struct {
+	Path string `json:"path" jsonschema:"File path relative to the search root"`
+	Line int    `json:"line" jsonschema:"1-based line number"`
+	Text string `json:"text" jsonschema:"Matched line content, truncated when too long"`
+}
+
 // GetFSFileReq 对应 GET /fs/file 的查询参数
 type GetFSFileReq struct {
-	Path     string `json:"path" jsonschema:"File path to read, relative or absolute"`
-	Encoding string `json:"encoding,omitempty" jsonschema:"Content encoding, supported values: utf8, utf-8, base64"`
+	Path      string `json:"path" jsonschema:"File path to read, relative or absolute"`
+	Encoding  string `json:"encoding,omitempty" jsonschema:"Content encoding, supported values: utf8, utf-8, base64"`
+	StartLine int    `json:"start_line,omitempty" jsonschema:"First line to return, 1-based and inclusive; utf8 only"`
+	EndLine   int    `json:"end_line,omitempty" jsonschema:"Last line to return, inclusive; defaults to the end of the file"`
 }
 
 // GetFSFileResp 读取文件接口响应体
 type GetFSFileResp struct {
-	Path     string `json:"path" jsonschema:"Normalized file path"`
-	Size     int64  `json:"size" jsonschema:"File size in bytes"`
-	Encoding string `json:"encoding" jsonschema:"Returned content encoding"`
-	Content  string `json:"content" jsonschema:"File content encoded by the encoding field"`
+	Path        string `json:"path" jsonschema:"Normalized file path"`
+	Size        int64  `json:"size" jsonschema:"File size in bytes"`
+	Encoding    string `json:"encoding" jsonschema:"Returned content encoding"`
+	Content     string `json:"content" jsonschema:"File content encoded by the encoding field"`
+	ContentType string `json:"content_type" jsonschema:"MIME type inferred from the file extension or sniffed from the first 512 bytes"`
+	TotalLines  int    `json:"total_lines,omitempty" jsonschema:"Total number of lines in the file, only for line range reads"`
+}
+
+// TailFSReq 对应 GET /fs/tail 的查询参数
+type TailFSReq struct {
+	Path   string `json:"path" jsonschema:"Log file path to tail, relative or absolute"`
+	Lines  int    `json:"lines,omitempty" jsonschema:"Number of trailing lines to return, defaults to 10"`
+	Follow bool   `json:"follow,omitempty" jsonschema:"Stream appended lines over SSE after the initial tail"`
+}
+
+// TailFSResp 非 follow 模式下 tail 接口响应体
+type TailFSResp struct {
+	Path    string   `json:"path" jsonschema:"Normalized file path"`
+	Lines   []string `json:"lines" jsonschema:"Trailing lines without line terminators, oldest first"`
+	Offset  int64    `json:"offset" jsonschema:"File size at read time, appended content starts here"`
+	Partial bool     `json:"partial" jsonschema:"Whether fewer lines were returned because the scan limit was reached"`
+}
+
+// FSTailEvent follow 模式下 tail 接口推送的 SSE 事件
+type FSTailEvent struct {
+	// Type 取值：lines、truncated、end、error
+	Type      string   `json:"type"`
+	Timestamp int64    `json:"timestamp,omitempty"`
+	Lines     []string `json:"lines,omitempty"`
+	Offset    int64    `json:"offset"`
+	// Reason 仅在 end/error 事件中设置
+	Reason string `json:"reason,omitempty"`
+}
+
+// FSUsageResp 工作区磁盘用量接口响应体
+type FSUsageResp struct {
+	Root              string `json:"root" jsonschema:"Workspace root directory"`
+	TotalBytes        int64  `json:"total_bytes" jsonschema:"Total size of regular files under the workspace root in bytes"`
+	FileCount         int    `json:"file_count" jsonschema:"Number of regular files under the workspace root, symlinks excluded"`
+	MaxFileBytes      int64  `json:"max_file_bytes" jsonschema:"Per-file size limit for reads and writes, 0 means unlimited"`
+	MaxWorkspaceBytes int64  `json:"max_workspace_bytes" jsonschema:"Workspace quota enforced on writes and uploads, 0 means unlimited"`
 }
 
 // WriteFSFileReq 写入文件接口请求体
@@ -41,6 +125,9 @@ type WriteFSFileReq struct {
 	Path     string `json:"path" jsonschema:"Destination file path, relative or absolute"`
 	Content  string `json:"content" jsonschema:"File content to write"`
 	Encoding string `json:"encoding,omitempty" jsonschema:"Input content encoding, supported values: utf8, utf-8, base64"`
+	Mode     string `json:"mode,omitempty" jsonschema:"Optional permission bits in octal such as 0755, setuid/setgid/sticky bits are rejected"`
+	// IfMatchHash 非空时仅当目标文件当前内容的 SHA-256 与之相同时才写入，用于乐观并发控制
+	IfMatchHash string `json:"if_match_hash,omitempty" jsonschema:"Optional hex SHA-256 of the current file content; the write fails with 409 when it does not match"`
 }
 
 // WriteFSFileResp 写入文件接口响应体
@@ -48,6 +135,56 @@ type WriteFSFileResp struct {
 	Path     string `json:"path" jsonschema:"Normalized written file path"`
 	Size     int64  `json:"size" jsonschema:"Written content size in bytes"`
 	Encoding string `json:"encoding" jsonschema:"Resolved encoding used to decode input content"`
+	Mode     string `json:"mode" jsonschema:"Resulting permission bits in octal such as 0644"`
+	// ContentHash 为写入后内容的 SHA-256，可作为下次写入的 if_match_hash
+	ContentHash string `json:"content_hash" jsonschema:"Hex SHA-256 of the written content, usable as the next if_match_hash"`
+}
+
+// DeleteFSFileReq 对应 DELETE /fs/file 的查询参数
+type DeleteFSFileReq struct {
+	Path      string `json:"path" jsonschema:"File or directory path to delete, relative or absolute"`
+	Recursive bool   `json:"recursive,omitempty" jsonschema:"Delete non-empty directories recursively"`
+}
+
+// DeleteFSFileResp 删除文件接口响应体
+type DeleteFSFileResp struct {
+	Path string `json:"path" jsonschema:"Normalized deleted path"`
+	Type string `json:"type" jsonschema:"Deleted entry type: file or dir"`
+}
+
+// MkdirFSReq 创建目录接口请求体
+type MkdirFSReq struct {
+	Path string `json:"path" jsonschema:"Directory path to create, relative or absolute; parents are created as needed"`
+}
+
+// MkdirFSResp 创建目录接口响应体
+type MkdirFSResp struct {
+	Path string `json:"path" jsonschema:"Normalized created directory path"`
+}
+
+// MoveFSReq 移动文件或目录接口请求体
+type MoveFSReq struct {
+	Src string `json:"src" jsonschema:"Source file or directory path, relative or absolute"`
+	Dst string `json:"dst" jsonschema:"Destination path, relative or absolute; must not exist"`
+}
+
+// MoveFSResp 移动文件或目录接口响应体
+type MoveFSResp struct {
+	Src string `json:"src" jsonschema:"Normalized source path"`
+	Dst string `json:"dst" jsonschema:"Normalized destination path"`
+}
+
+// CopyFSReq 复制文件或目录接口请求体
+type CopyFSReq struct {
+	Src string `json:"src" jsonschema:"Source file or directory path, relative or absolute; directories are copied recursively"`
+	Dst string `json:"dst" jsonschema:"Destination path, relative or absolute; must not exist"`
+}
+
+// CopyFSResp 复制文件或目录接口响应体
+type CopyFSResp struct {
+	Src   string `json:"src" jsonschema:"Normalized source path"`
+	Dst   string `json:"dst" jsonschema:"Normalized destination path"`
+	Bytes int64  `json:"bytes" jsonschema:"Total copied file content size in bytes"`
 }
 
 // UploadFSFileReq 对应 POST /fs/upload 的请求体（MCP 友好形式）
@@ -55,6 +192,7 @@ type UploadFSFileReq struct {
 	TargetFilePath string `json:"target_file_path" jsonschema:"Destination file path in sandbox, relative or absolute"`
 	FileName       string `json:"file_name,omitempty" jsonschema:"Original file name"`
 	ContentBase64  string `json:"content_base64" jsonschema:"File content in base64"`
+	Mode           string `json:"mode,omitempty" jsonschema:"Optional permission bits in octal such as 0755, setuid/setgid/sticky bits are rejected"`
 }
 
 // UploadFSFileResp 上传文件接口响应体
@@ -62,6 +200,27 @@ type UploadFSFileResp struct {
 	SourcePath string `json:"source_path" jsonschema:"Source file name"`
 	TargetPath string `json:"target_path" jsonschema:"Normalized destination file path"`
 	Size       int64  `json:"size" jsonschema:"Uploaded file size in bytes"`
+	Mode       string `json:"mode" jsonschema:"Resulting permission bits in octal such as 0644"`
+}
+
+// UploadFSFileResult 为批量上传中单个文件的结果，写入失败时 Error 非空且 Size、Mode 为空
+type UploadFSFileResult struct {
+	UploadFSFileResp
+	Error string `json:"error,omitempty" jsonschema:"Error message when this file could not be written"`
+}
+
+// UploadFSFilesResp 批量上传接口响应体，Files 与请求中的文件按顺序一一对应
+type UploadFSFilesResp struct {
+	Files     []UploadFSFileResult `json:"files" jsonschema:"Per-file results in request order"`
+	TotalSize int64                `json:"total_size" jsonschema:"Total bytes written across successful files"`
+}
+
+// ExtractFSResp 上传并解压归档接口响应体
+type ExtractFSResp struct {
+	TargetDir string `json:"target_dir" jsonschema:"Normalized target directory path"`
+	Format    string `json:"format" jsonschema:"Archive format used for extraction: zip or targz"`
+	Files     int    `json:"files" jsonschema:"Number of extracted regular files"`
+	Bytes     int64  `json:"bytes" jsonschema:"Total uncompressed size of extracted files in bytes"`
 }
 
 // DownloadFSFileReq 对应 GET /fs/download 的查询参数
@@ -3,7 +3,7 @@ package models
 // ExecuteStreamEvent is one event frame in SSE streaming execution.
 // It is intentionally small and generic so that clients can incrementally render output.
 type ExecuteStreamEvent struct {
-	// Type is the event kind: init, stdout, stderr, count, status, execution_complete, error, ping.
+	// Type is the event kind: init, stdout, stderr, display, count, status, execution_complete, install_complete, error, ping.
 	Type string `json:"type"`
 
 	// Timestamp is milliseconds since epoch.
@@ -14,14 +14,29 @@ type ExecuteStreamEvent struct {
 	// Text carries stdout/stderr/status payload.
 	Text string `json:"text,omitempty"`
 
+	// ExecutionCount is set for "count" and "execution_complete" events.
 	ExecutionCount int64 `json:"execution_count,omitempty"`
 
-	// ExecutionTime is only set for "execution_complete" events (milliseconds).
+	// ExecutionTime is only set for "execution_complete" and "install_complete" events (milliseconds).
 	ExecutionTime int64 `json:"execution_time,omitempty"`
 
-	// ExitCode is only set for "execution_complete" events.
+	// ExitCode is only set for "execution_complete" and "install_complete" events.
 	ExitCode int32 `json:"exit_code,omitempty"`
 
+	// StdoutTruncated/StderrTruncated are only set for "execution_complete" events when the output
+	// exceeded the sandbox limit; streamed stdout/stderr frames stop at the same limit.
+	StdoutTruncated bool `json:"stdout_truncated,omitempty"`
+	StderrTruncated bool `json:"stderr_truncated,omitempty"`
+
+	// Outputs is only set for "display" events and carries one MIME bundle.
+	Outputs []RichOutput `json:"outputs,omitempty"`
+
+	// Variables is only set for "execution_complete" events when return_vars was requested.
+	Variables map[string]string `json:"variables,omitempty"`
+
+	// Packages is only set for "install_complete" events and lists the installed versions.
+	Packages []InstalledPackage `json:"packages,omitempty"`
+
 	// Result is deprecated; do not rely on it being populated.
 	Result *ExecuteContextResp `json:"result,omitempty"`
 
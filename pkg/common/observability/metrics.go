@@ -0,0 +1,28 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// InitMeterProvider sets the global meter provider, exporting OTel metrics through
+// the given Prometheus registerer so they are scraped from the existing metrics endpoint.
+func InitMeterProvider(registerer prometheus.Registerer) (func(context.Context) error, error) {
+	exporter, err := otelprom.New(
+		otelprom.WithRegisterer(registerer),
+		otelprom.WithoutScopeInfo(),
+		otelprom.WithoutTargetInfo(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create prometheus metric exporter: %w", err)
+	}
+
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(exporter))
+	otel.SetMeterProvider(mp)
+	return mp.Shutdown, nil
+}
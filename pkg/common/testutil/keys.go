@@ -1,6 +1,9 @@
 package testutil
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -27,9 +30,65 @@ func WriteTestRSAKeys(dir string) (string, string, error) {
 		return "", "", fmt.Errorf("marshal public key failed: %w", err)
 	}
 
-	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: privateBytes})
-	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicBytes})
+	return writeKeyPair(dir,
+		pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: privateBytes}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicBytes}),
+	)
+}
+
+// WriteTestECKeys generates an ECDSA P-256 key pair and writes PEM files under dir.
+func WriteTestECKeys(dir string) (string, string, error) {
+	if dir == "" {
+		return "", "", fmt.Errorf("dir is required")
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("generate ecdsa key failed: %w", err)
+	}
+
+	privateBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal private key failed: %w", err)
+	}
+	publicBytes, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal public key failed: %w", err)
+	}
+
+	return writeKeyPair(dir,
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: privateBytes}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicBytes}),
+	)
+}
+
+// WriteTestEd25519Keys generates an Ed25519 key pair and writes PEM files under dir.
+func WriteTestEd25519Keys(dir string) (string, string, error) {
+	if dir == "" {
+		return "", "", fmt.Errorf("dir is required")
+	}
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("generate ed25519 key failed: %w", err)
+	}
+
+	privateBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal private key failed: %w", err)
+	}
+	publicBytes, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal public key failed: %w", err)
+	}
+
+	return writeKeyPair(dir,
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateBytes}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicBytes}),
+	)
+}
 
+func writeKeyPair(dir string, privatePEM, publicPEM []byte) (string, string, error) {
 	privatePath := filepath.Join(dir, "private.pem")
 	publicPath := filepath.Join(dir, "public.pem")
 
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// CodeEncodingRaw 为默认编码，code 字段即 UTF-8 源码
+	CodeEncodingRaw = ""
+	// CodeEncodingBase64 表示 code 字段为标准 base64 编码的 UTF-8 源码
+	CodeEncodingBase64 = "base64"
+)
+
+var ErrInvalidCodeEncoding = errors.New("invalid code encoding")
+
+// DecodeCode 按 encoding 解码执行请求中的代码，未知编码、base64 非法或解码结果不是合法 UTF-8 时返回 ErrInvalidCodeEncoding
+func DecodeCode(code, encoding string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case CodeEncodingRaw:
+		return code, nil
+	case CodeEncodingBase64:
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(code))
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidCodeEncoding, err)
+		}
+		if !utf8.Valid(data) {
+			return "", fmt.Errorf("%w: decoded code is not valid UTF-8", ErrInvalidCodeEncoding)
+		}
+		return string(data), nil
+	default:
+		return "", fmt.Errorf("%w: unsupported encoding %q", ErrInvalidCodeEncoding, encoding)
+	}
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCode(t *testing.T) {
+	script := "msg = \"he said \\\"hi\\\"\"\nfor i in range(2):\n\tprint(msg, i)\n"
+
+	code, err := DecodeCode(script, "")
+	require.NoError(t, err)
+	require.Equal(t, script, code)
+
+	code, err = DecodeCode(base64.StdEncoding.EncodeToString([]byte(script)), "base64")
+	require.NoError(t, err)
+	require.Equal(t, script, code)
+
+	_, err = DecodeCode("not base64!", "base64")
+	require.ErrorIs(t, err, ErrInvalidCodeEncoding)
+
+	_, err = DecodeCode(base64.StdEncoding.EncodeToString([]byte{0xff, 0xfe}), "base64")
+	require.ErrorIs(t, err, ErrInvalidCodeEncoding)
+
+	_, err = DecodeCode(script, "hex")
+	require.ErrorIs(t, err, ErrInvalidCodeEncoding)
+}
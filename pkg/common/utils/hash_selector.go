@@ -20,3 +20,16 @@ func AddHashValueRequirement(selector labels.Selector, key, name string) (labels
 	}
 	return selector.Add(*req), nil
 }
+
+// AddHashValuesRequirement 为 selector 追加 key in (hash(names)...) 条件，用于同时匹配多个名称。
+func AddHashValuesRequirement(selector labels.Selector, key string, names []string) (labels.Selector, error) {
+	values := make([]string, 0, len(names))
+	for _, name := range names {
+		values = append(values, NameHash(name))
+	}
+	req, err := labels.NewRequirement(key, selection.In, values)
+	if err != nil {
+		return nil, err
+	}
+	return selector.Add(*req), nil
+}
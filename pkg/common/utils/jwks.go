@@ -0,0 +1,258 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultJWKSCacheTTL        = 5 * time.Minute
+	defaultJWKSRefreshInterval = 10 * time.Second
+	defaultJWKSFetchTimeout    = 5 * time.Second
+	maxJWKSResponseBytes       = 1 << 20
+)
+
+// JWK 为 RFC 7517 公钥表示，仅包含验签所需字段。
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS 为 /.well-known/jwks.json 的响应体。
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWK 将公钥转换为带 kid 的 JWK。
+func NewJWK(kid string, publicKey crypto.PublicKey) (JWK, error) {
+	alg, err := KeyAlgorithm(publicKey)
+	if err != nil {
+		return JWK{}, err
+	}
+
+	jwk := JWK{Kid: kid, Use: "sig", Alg: alg}
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = rawBase64URL.EncodeToString(key.N.Bytes())
+		jwk.E = rawBase64URL.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	case *ecdsa.PublicKey:
+		x := make([]byte, es256ComponentSize)
+		y := make([]byte, es256ComponentSize)
+		key.X.FillBytes(x)
+		key.Y.FillBytes(y)
+		jwk.Kty = "EC"
+		jwk.Crv = "P-256"
+		jwk.X = rawBase64URL.EncodeToString(x)
+		jwk.Y = rawBase64URL.EncodeToString(y)
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = rawBase64URL.EncodeToString(key)
+	}
+	return jwk, nil
+}
+
+// PublicKey 将 JWK 还原为公钥。
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := rawBase64URL.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("decode n failed: %w", err)
+		}
+		e, err := rawBase64URL.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("decode e failed: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid rsa key parameters")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := rawBase64URL.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("decode x failed: %w", err)
+		}
+		y, err := rawBase64URL.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("decode y failed: %w", err)
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if _, err := key.ECDH(); err != nil {
+			return nil, fmt.Errorf("invalid ec point: %w", err)
+		}
+		return key, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := rawBase64URL.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("decode x failed: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid ed25519 key length: %d", len(x))
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported kty: %s", k.Kty)
+	}
+}
+
+// NewVerifierFromJWKS 创建按 kid 从 JWKS 地址拉取公钥的 Verifier，
+// 公钥按 cfg.JWKSCacheTTL 缓存，遇到未知 kid 时提前刷新，从而支持不重启沙箱的密钥轮换。
+func NewVerifierFromJWKS(jwksURL string, cfg VerifierConfig) (*Verifier, error) {
+	parsed, err := url.Parse(strings.TrimSpace(jwksURL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid jwks url: %q", jwksURL)
+	}
+	if cfg.Issuer == "" {
+		return nil, fmt.Errorf("issuer is required")
+	}
+	if cfg.Audience == "" {
+		return nil, fmt.Errorf("audience is required")
+	}
+	if cfg.ClockSkew < 0 {
+		return nil, fmt.Errorf("clock skew cannot be negative")
+	}
+	alg := strings.TrimSpace(cfg.Algorithm)
+	if alg != "" && !IsSupportedAlgorithm(alg) {
+		return nil, fmt.Errorf("unsupported alg: %s", alg)
+	}
+
+	ttl := cfg.JWKSCacheTTL
+	if ttl <= 0 {
+		ttl = defaultJWKSCacheTTL
+	}
+
+	return &Verifier{
+		alg: alg,
+		jwks: &jwksCache{
+			url:             parsed.String(),
+			client:          &http.Client{Timeout: defaultJWKSFetchTimeout},
+			ttl:             ttl,
+			refreshInterval: defaultJWKSRefreshInterval,
+			now:             time.Now,
+		},
+		issuer:      cfg.Issuer,
+		audience:    cfg.Audience,
+		clockSkew:   cfg.ClockSkew,
+		optionalSID: cfg.OptionalSessionID,
+		now:         time.Now,
+	}, nil
+}
+
+type jwksKey struct {
+	publicKey crypto.PublicKey
+	alg       string
+}
+
+type jwksCache struct {
+	url             string
+	client          *http.Client
+	ttl             time.Duration
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	mu          sync.Mutex
+	keys        map[string]jwksKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+// lookup 返回 kid 对应的公钥；缓存过期或 kid 未知时刷新，
+// 未知 kid 的刷新受 refreshInterval 限制，避免伪造 kid 打爆网关。
+func (c *jwksCache) lookup(kid string) (jwksKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	key, ok := c.find(kid)
+	expired := c.keys == nil || now.Sub(c.fetchedAt) >= c.ttl
+	if expired || (!ok && now.Sub(c.lastAttempt) >= c.refreshInterval) {
+		if err := c.refreshLocked(now); err != nil && !ok {
+			return jwksKey{}, err
+		}
+		key, ok = c.find(kid)
+	}
+	if !ok {
+		return jwksKey{}, fmt.Errorf("unknown kid: %q", kid)
+	}
+	return key, nil
+}
+
+func (c *jwksCache) find(kid string) (jwksKey, bool) {
+	if kid == "" && len(c.keys) == 1 {
+		for _, key := range c.keys {
+			return key, true
+		}
+	}
+	key, ok := c.keys[kid]
+	return key, ok
+}
+
+func (c *jwksCache) refreshLocked(now time.Time) error {
+	c.lastAttempt = now
+
+	resp, err := c.client.Get(c.url)
+	if err != nil {
+		return fmt.Errorf("fetch jwks failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch jwks failed: status %d", resp.StatusCode)
+	}
+
+	var set JWKS
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxJWKSResponseBytes)).Decode(&set); err != nil {
+		return fmt.Errorf("decode jwks failed: %w", err)
+	}
+
+	keys := make(map[string]jwksKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		publicKey, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		alg, err := KeyAlgorithm(publicKey)
+		if err != nil || (jwk.Alg != "" && jwk.Alg != alg) {
+			continue
+		}
+		keys[jwk.Kid] = jwksKey{publicKey: publicKey, alg: alg}
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("jwks contains no usable keys")
+	}
+
+	c.keys = keys
+	c.fetchedAt = now
+	return nil
+}
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"crypto"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type jwksTestServer struct {
+	mu       sync.Mutex
+	keys     JWKS
+	requests atomic.Int32
+}
+
+func (s *jwksTestServer) setKeys(keys ...JWK) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = JWKS{Keys: keys}
+}
+
+func (s *jwksTestServer) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	s.requests.Add(1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = json.NewEncoder(w).Encode(s.keys)
+}
+
+func newTestSignerWithJWK(t *testing.T, writeKeys func(string) (string, string, error), kid string) (*Signer, JWK) {
+	t.Helper()
+
+	privatePath, _, err := writeKeys(t.TempDir())
+	require.NoError(t, err)
+
+	signer, err := NewSignerFromConfig(SignerConfig{
+		PrivateKeyPath: privatePath,
+		Issuer:         "agentland-gateway",
+		Audience:       "sandbox",
+		KID:            kid,
+		TTL:            5 * time.Minute,
+	})
+	require.NoError(t, err)
+
+	jwk, err := NewJWK(kid, signer.current.Load().privateKey.Public())
+	require.NoError(t, err)
+	return signer, jwk
+}
+
+func TestJWK_RoundTrip(t *testing.T) {
+	for _, writeKeys := range []func(string) (string, string, error){
+		testutil.WriteTestRSAKeys,
+		testutil.WriteTestECKeys,
+		testutil.WriteTestEd25519Keys,
+	} {
+		privatePath, _, err := writeKeys(t.TempDir())
+		require.NoError(t, err)
+		data, err := os.ReadFile(privatePath)
+		require.NoError(t, err)
+		privateKey, err := ParsePrivateKeyPEM(data)
+		require.NoError(t, err)
+
+		jwk, err := NewJWK("kid", privateKey.Public())
+		require.NoError(t, err)
+
+		publicKey, err := jwk.PublicKey()
+		require.NoError(t, err)
+		require.True(t, privateKey.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(publicKey))
+	}
+}
+
+func TestVerifierFromJWKS_RefreshesOnUnknownKID(t *testing.T) {
+	oldSigner, oldJWK := newTestSignerWithJWK(t, testutil.WriteTestRSAKeys, "kid-old")
+	newSigner, newJWK := newTestSignerWithJWK(t, testutil.WriteTestECKeys, "kid-new")
+
+	keyServer := &jwksTestServer{}
+	keyServer.setKeys(oldJWK)
+	httpServer := httptest.NewServer(keyServer)
+	defer httpServer.Close()
+
+	verifier, err := NewVerifierFromJWKS(httpServer.URL, VerifierConfig{
+		Issuer:       "agentland-gateway",
+		Audience:     "sandbox",
+		ClockSkew:    30 * time.Second,
+		JWKSCacheTTL: time.Hour,
+	})
+	require.NoError(t, err)
+	verifier.jwks.refreshInterval = 0
+
+	oldToken, err := oldSigner.Sign("session-abc", "", 0)
+	require.NoError(t, err)
+	_, err = verifier.Verify(oldToken)
+	require.NoError(t, err)
+	_, err = verifier.Verify(oldToken)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), keyServer.requests.Load())
+
+	keyServer.setKeys(oldJWK, newJWK)
+	newToken, err := newSigner.Sign("session-abc", "", 0)
+	require.NoError(t, err)
+	claims, err := verifier.Verify(newToken)
+	require.NoError(t, err)
+	require.Equal(t, "session-abc", claims.SessionID)
+	require.Equal(t, int32(2), keyServer.requests.Load())
+}
+
+func TestVerifierFromJWKS_RejectsUnknownKIDWithinRefreshInterval(t *testing.T) {
+	_, knownJWK := newTestSignerWithJWK(t, testutil.WriteTestRSAKeys, "kid-known")
+	unknownSigner, _ := newTestSignerWithJWK(t, testutil.WriteTestRSAKeys, "kid-unknown")
+
+	keyServer := &jwksTestServer{}
+	keyServer.setKeys(knownJWK)
+	httpServer := httptest.NewServer(keyServer)
+	defer httpServer.Close()
+
+	verifier, err := NewVerifierFromJWKS(httpServer.URL, VerifierConfig{
+		Issuer:    "agentland-gateway",
+		Audience:  "sandbox",
+		ClockSkew: 30 * time.Second,
+	})
+	require.NoError(t, err)
+
+	token, err := unknownSigner.Sign("session-abc", "", 0)
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(token)
+	require.ErrorContains(t, err, "unknown kid")
+	_, err = verifier.Verify(token)
+	require.ErrorContains(t, err, "unknown kid")
+	require.Equal(t, int32(1), keyServer.requests.Load())
+}
+
+func TestVerifierFromJWKS_RefetchesAfterTTL(t *testing.T) {
+	signer, jwk := newTestSignerWithJWK(t, testutil.WriteTestEd25519Keys, "kid-1")
+
+	keyServer := &jwksTestServer{}
+	keyServer.setKeys(jwk)
+	httpServer := httptest.NewServer(keyServer)
+	defer httpServer.Close()
+
+	verifier, err := NewVerifierFromJWKS(httpServer.URL, VerifierConfig{
+		Issuer:       "agentland-gateway",
+		Audience:     "sandbox",
+		JWKSCacheTTL: time.Minute,
+	})
+	require.NoError(t, err)
+	current := time.Now()
+	verifier.jwks.now = func() time.Time { return current }
+
+	token, err := signer.Sign("session-abc", "", 0)
+	require.NoError(t, err)
+	_, err = verifier.Verify(token)
+	require.NoError(t, err)
+
+	current = current.Add(2 * time.Minute)
+	_, err = verifier.Verify(token)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), keyServer.requests.Load())
+}
+
+func TestNewVerifierFromJWKS_RejectsInvalidURL(t *testing.T) {
+	_, err := NewVerifierFromJWKS("not-a-url", VerifierConfig{Issuer: "agentland-gateway", Audience: "sandbox"})
+	require.Error(t, err)
+}
@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultKeyReloadInterval 为未配置 KeyReloadInterval 时重新读取公钥文件的最小间隔。
+const defaultKeyReloadInterval = 10 * time.Second
+
+// 网关公钥 Secret 中按 kid 保存的公钥文件名为 public-<kid>.pem，与 public.pem 挂载在同一目录。
+const (
+	retainedPublicKeyPrefix = "public-"
+	retainedPublicKeySuffix = ".pem"
+)
+
+type publicKeyEntry struct {
+	publicKey crypto.PublicKey
+	alg       string
+}
+
+type loadedPublicKey struct {
+	publicKeyEntry
+	// byKID 为同目录下 public-<kid>.pem 中的公钥，包含轮换宽限期内保留的旧密钥
+	byKID  map[string]publicKeyEntry
+	digest [sha256.Size]byte
+}
+
+// ReloadablePublicKey 持有从文件解析的验签公钥，文件内容变化时原子替换，
+// 使挂载的 Secret 轮换后无需重启沙箱即可生效。同目录下的 public-<kid>.pem 按 kid 一并加载，
+// 网关轮换密钥后尚未切换的实例以旧 kid 签发的 token 在宽限期内仍可验。
+type ReloadablePublicKey struct {
+	path     string
+	alg      string
+	interval time.Duration
+	now      func() time.Time
+
+	current atomic.Pointer[loadedPublicKey]
+
+	// mu 保证同一时刻只有一个调用方重读文件，其余调用方直接使用当前公钥
+	mu        sync.Mutex
+	lastCheck time.Time
+}
+
+// NewReloadablePublicKey 读取并解析 path 处的公钥，alg 非空时要求公钥类型与之一致，
+// interval<=0 时使用默认重读间隔。
+func NewReloadablePublicKey(path, alg string, interval time.Duration) (*ReloadablePublicKey, error) {
+	if interval <= 0 {
+		interval = defaultKeyReloadInterval
+	}
+	k := &ReloadablePublicKey{
+		path:     path,
+		alg:      alg,
+		interval: interval,
+		now:      time.Now,
+	}
+	if _, err := k.Reload(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Load 返回当前公钥及其算法；距上次检查超过重读间隔时顺带检查文件是否变化。
+// 重读失败时保留旧公钥，避免写入中途的文件导致验签全部失败。
+func (k *ReloadablePublicKey) Load() (crypto.PublicKey, string) {
+	return k.LoadKID("")
+}
+
+// LoadKID 返回 kid 对应的公钥及其算法，kid 为空或未找到对应的 public-<kid>.pem 时返回 public.pem 中的公钥。
+func (k *ReloadablePublicKey) LoadKID(kid string) (crypto.PublicKey, string) {
+	k.maybeReload()
+	entry := k.current.Load()
+	if kid != "" {
+		if key, ok := entry.byKID[kid]; ok {
+			return key.publicKey, key.alg
+		}
+	}
+	return entry.publicKey, entry.alg
+}
+
+// Reload 立即重读公钥文件，内容变化且解析成功时替换当前公钥并返回 true。
+func (k *ReloadablePublicKey) Reload() (bool, error) {
+	data, err := os.ReadFile(k.path)
+	if err != nil {
+		return false, fmt.Errorf("read public key file failed: %w", err)
+	}
+	retained, err := filepath.Glob(filepath.Join(filepath.Dir(k.path), retainedPublicKeyPrefix+"*"+retainedPublicKeySuffix))
+	if err != nil {
+		return false, fmt.Errorf("list public key files failed: %w", err)
+	}
+	retainedData := make(map[string][]byte, len(retained))
+	hash := sha256.New()
+	hash.Write(data)
+	for _, path := range retained {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return false, fmt.Errorf("read public key file failed: %w", err)
+		}
+		retainedData[path] = content
+		hash.Write([]byte(filepath.Base(path)))
+		hash.Write(content)
+	}
+
+	var digest [sha256.Size]byte
+	copy(digest[:], hash.Sum(nil))
+	if old := k.current.Load(); old != nil && bytes.Equal(old.digest[:], digest[:]) {
+		return false, nil
+	}
+
+	primary, err := k.parseEntry(data)
+	if err != nil {
+		return false, err
+	}
+	loaded := &loadedPublicKey{publicKeyEntry: primary, byKID: make(map[string]publicKeyEntry, len(retained)), digest: digest}
+	for _, path := range retained {
+		kid := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), retainedPublicKeyPrefix), retainedPublicKeySuffix)
+		if kid == "" || filepath.Base(path) == filepath.Base(k.path) {
+			continue
+		}
+		entry, err := k.parseEntry(retainedData[path])
+		if err != nil {
+			return false, fmt.Errorf("load public key %q failed: %w", kid, err)
+		}
+		loaded.byKID[kid] = entry
+	}
+
+	k.current.Store(loaded)
+	return true, nil
+}
+
+func (k *ReloadablePublicKey) parseEntry(data []byte) (publicKeyEntry, error) {
+	publicKey, err := parsePublicKeyPEM(data)
+	if err != nil {
+		return publicKeyEntry{}, err
+	}
+	alg, err := resolveAlgorithm(k.alg, publicKey)
+	if err != nil {
+		return publicKeyEntry{}, err
+	}
+	return publicKeyEntry{publicKey: publicKey, alg: alg}, nil
+}
+
+func (k *ReloadablePublicKey) maybeReload() {
+	if !k.mu.TryLock() {
+		return
+	}
+	defer k.mu.Unlock()
+
+	now := k.now()
+	if now.Sub(k.lastCheck) < k.interval {
+		return
+	}
+	k.lastCheck = now
+	_, _ = k.Reload()
+}
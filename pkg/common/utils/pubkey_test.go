@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSigner(t *testing.T, privatePath string, now time.Time) *Signer {
+	t.Helper()
+	signer, err := NewSignerFromConfig(SignerConfig{
+		PrivateKeyPath: privatePath,
+		Issuer:         "agentland-gateway",
+		Audience:       "sandbox",
+		TTL:            5 * time.Minute,
+	})
+	require.NoError(t, err)
+	signer.now = func() time.Time { return now }
+	return signer
+}
+
+func TestVerifier_ReloadsRotatedPublicKey(t *testing.T) {
+	oldPrivate, publicPath, err := testutil.WriteTestRSAKeys(t.TempDir())
+	require.NoError(t, err)
+	newPrivate, newPublic, err := testutil.WriteTestRSAKeys(t.TempDir())
+	require.NoError(t, err)
+
+	now := time.Unix(1000, 0).UTC()
+	verifier, err := NewVerifierFromConfig(VerifierConfig{
+		PublicKeyPath:     publicPath,
+		Issuer:            "agentland-gateway",
+		Audience:          "sandbox",
+		KeyReloadInterval: time.Minute,
+	})
+	require.NoError(t, err)
+	verifier.now = func() time.Time { return now }
+	verifier.fileKey.now = func() time.Time { return now }
+
+	oldToken, err := newTestSigner(t, oldPrivate, now).Sign("session-abc", "", 0)
+	require.NoError(t, err)
+	newToken, err := newTestSigner(t, newPrivate, now).Sign("session-abc", "", 0)
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(oldToken)
+	require.NoError(t, err)
+	_, err = verifier.Verify(newToken)
+	require.Error(t, err)
+
+	data, err := os.ReadFile(newPublic)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(publicPath, data, 0o600))
+
+	// 未到重读间隔时仍使用旧公钥
+	_, err = verifier.Verify(oldToken)
+	require.NoError(t, err)
+
+	now = now.Add(time.Minute)
+	_, err = verifier.Verify(oldToken)
+	require.Error(t, err)
+	_, err = verifier.Verify(newToken)
+	require.NoError(t, err)
+}
+
+func TestReloadablePublicKey_KeepsKeyOnInvalidFile(t *testing.T) {
+	_, publicPath, err := testutil.WriteTestRSAKeys(t.TempDir())
+	require.NoError(t, err)
+
+	key, err := NewReloadablePublicKey(publicPath, "", time.Minute)
+	require.NoError(t, err)
+	before, alg := key.Load()
+	require.Equal(t, AlgRS256, alg)
+
+	require.NoError(t, os.WriteFile(publicPath, []byte("not a pem"), 0o600))
+	changed, err := key.Reload()
+	require.Error(t, err)
+	require.False(t, changed)
+
+	after, _ := key.Load()
+	require.Same(t, before, after)
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrTokenRevoked 表示 token 版本低于会话当前记录的最小版本。
+var ErrTokenRevoked = errors.New("sandbox token has been revoked")
+
+// RevocationStore 记录每个会话当前允许的最小 token 版本，网关递增版本即可使该会话已签发的 token 全部失效。
+type RevocationStore interface {
+	MinTokenVersion(ctx context.Context, sessionID string) (int64, error)
+}
+
+// tokenVersionObserver 由需要根据已验签 token 推进最小版本的 RevocationStore 实现
+type tokenVersionObserver interface {
+	ObserveTokenVersion(sessionID string, version int64)
+}
+
+// CheckRevocation 校验 claims 的版本未被吊销。比较只依赖版本号而非签发时间，不受网关与沙箱时钟偏差影响。
+// claims 必须已通过验签；校验通过后 store 若实现了 tokenVersionObserver，会以该版本推进会话的最小版本。
+func CheckRevocation(ctx context.Context, store RevocationStore, claims *Claims) error {
+	if store == nil {
+		return nil
+	}
+	if claims == nil {
+		return fmt.Errorf("claims is nil")
+	}
+
+	minVersion, err := store.MinTokenVersion(ctx, claims.SessionID)
+	if err != nil {
+		return fmt.Errorf("get min token version failed: %w", err)
+	}
+	if claims.Version < minVersion {
+		return fmt.Errorf("%w: version %d is below %d", ErrTokenRevoked, claims.Version, minVersion)
+	}
+	if observer, ok := store.(tokenVersionObserver); ok {
+		observer.ObserveTokenVersion(claims.SessionID, claims.Version)
+	}
+	return nil
+}
+
+// TokenVersionWatermark 在沙箱进程内以已验签 token 中见过的最高版本作为会话的最小版本。
+// 网关总以会话当前的最小版本签发 token，版本递增后经网关转发的首个请求即会使此前签发的 token
+// 在沙箱侧失效，沙箱无需访问网关的会话存储。
+type TokenVersionWatermark struct {
+	mu       sync.Mutex
+	versions map[string]int64
+}
+
+func NewTokenVersionWatermark() *TokenVersionWatermark {
+	return &TokenVersionWatermark{versions: map[string]int64{}}
+}
+
+// MinTokenVersion 返回会话已见过的最高 token 版本，未见过的会话为 0
+func (w *TokenVersionWatermark) MinTokenVersion(_ context.Context, sessionID string) (int64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.versions[sessionID], nil
+}
+
+// ObserveTokenVersion 在 version 更高时推进会话的最小版本，版本只增不减
+func (w *TokenVersionWatermark) ObserveTokenVersion(sessionID string, version int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if version > w.versions[sessionID] {
+		w.versions[sessionID] = version
+	}
+}
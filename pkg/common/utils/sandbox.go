@@ -15,6 +15,13 @@ const (
 	ClaimUIDLabel               = "agentland.fl0rencess720.app/claim-uid"
 	PodNameAnnotation           = "agentland.fl0rencess720.app/pod-name"
 	PoolBackfillTouchAnnotation = "agentland.fl0rencess720.app/pool-backfill-touch-at"
+	LastActivityAnnotation      = "agentland.fl0rencess720.app/last-activity"
+	OwnerLabel                  = "agentland.fl0rencess720.app/owner"
+	// SessionIDLabel 与 ProfileLabel 标记沙箱 Pod 所属的会话与 profile，供成本归属与排障检索
+	SessionIDLabel = "agentland.fl0rencess720.app/session-id"
+	ProfileLabel   = "agentland.fl0rencess720.app/profile"
+	// WarmImageAnnotation 记录仅预拉取镜像的预热 Pod 在被认领时要切换到的沙箱镜像
+	WarmImageAnnotation = "agentland.fl0rencess720.app/warm-image"
 )
 
 const (
@@ -3,6 +3,9 @@ package utils
 import (
 	"bytes"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
@@ -11,20 +14,37 @@ import (
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"math/big"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // 后
 var rawBase64URL = base64.RawURLEncoding
 
+// 支持的 JWS 签名算法。
+const (
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+// es256 签名为 r||s 定长拼接，每段 32 字节。
+const es256ComponentSize = 32
+
 type SignerConfig struct {
 	PrivateKeyPath string
 	Issuer         string
 	Audience       string
 	KID            string
 	TTL            time.Duration
+	// Algorithm 为空时按私钥类型推断。
+	Algorithm string
+	// KeyReloadInterval 为重读私钥文件的最小间隔，<=0 时不重读。
+	KeyReloadInterval time.Duration
 }
 
 type VerifierConfig struct {
@@ -32,23 +52,52 @@ type VerifierConfig struct {
 	Issuer        string
 	Audience      string
 	ClockSkew     time.Duration
+	// Algorithm 为空时按公钥类型推断，token 的 alg 必须与之一致。
+	Algorithm string
+	// JWKSCacheTTL 仅用于 NewVerifierFromJWKS，默认 5 分钟。
+	JWKSCacheTTL time.Duration
+	// KeyReloadInterval 仅用于 NewVerifierFromConfig，为重读公钥文件的最小间隔，默认 10 秒。
+	KeyReloadInterval time.Duration
+	// OptionalSessionID 为 true 时不要求 sid claim，用于校验不绑定会话的调用方身份 token。
+	OptionalSessionID bool
 }
 
 type Signer struct {
-	privateKey *rsa.PrivateKey
-	issuer     string
-	audience   string
+	issuer   string
+	audience string
+	ttl      time.Duration
+	now      func() time.Time
+
+	privateKeyPath string
+	// configuredAlg 与 configuredKID 为 SignerConfig 中的配置，私钥 PEM 头携带 kid 时以 PEM 头为准
+	configuredAlg  string
+	configuredKID  string
+	reloadInterval time.Duration
+
+	current atomic.Pointer[signingKey]
+
+	// mu 保证同一时刻只有一个调用方重读私钥文件
+	mu        sync.Mutex
+	lastCheck time.Time
+}
+
+type signingKey struct {
+	privateKey crypto.Signer
+	alg        string
 	kid        string
-	ttl        time.Duration
-	now        func() time.Time
+	digest     [sha256.Size]byte
 }
 
 type Verifier struct {
-	publicKey *rsa.PublicKey
+	fileKey   *ReloadablePublicKey
+	alg       string
+	jwks      *jwksCache
 	issuer    string
 	audience  string
 	clockSkew time.Duration
-	now       func() time.Time
+	// optionalSID 为 true 时不要求 sid claim
+	optionalSID bool
+	now         func() time.Time
 }
 
 type Claims struct {
@@ -83,19 +132,20 @@ func NewSignerFromConfig(cfg SignerConfig) (*Signer, error) {
 		return nil, fmt.Errorf("ttl must be greater than 0")
 	}
 
-	privateKey, err := loadRSAPrivateKey(cfg.PrivateKeyPath)
-	if err != nil {
+	s := &Signer{
+		issuer:         cfg.Issuer,
+		audience:       cfg.Audience,
+		ttl:            cfg.TTL,
+		now:            time.Now,
+		privateKeyPath: cfg.PrivateKeyPath,
+		configuredAlg:  cfg.Algorithm,
+		configuredKID:  cfg.KID,
+		reloadInterval: cfg.KeyReloadInterval,
+	}
+	if _, err := s.reloadKey(); err != nil {
 		return nil, fmt.Errorf("load private key failed: %w", err)
 	}
-
-	return &Signer{
-		privateKey: privateKey,
-		issuer:     cfg.Issuer,
-		audience:   cfg.Audience,
-		kid:        cfg.KID,
-		ttl:        cfg.TTL,
-		now:        time.Now,
-	}, nil
+	return s, nil
 }
 
 func NewVerifierFromConfig(cfg VerifierConfig) (*Verifier, error) {
@@ -112,17 +162,18 @@ func NewVerifierFromConfig(cfg VerifierConfig) (*Verifier, error) {
 		return nil, fmt.Errorf("clock skew cannot be negative")
 	}
 
-	publicKey, err := loadRSAPublicKey(cfg.PublicKeyPath)
+	fileKey, err := NewReloadablePublicKey(cfg.PublicKeyPath, strings.TrimSpace(cfg.Algorithm), cfg.KeyReloadInterval)
 	if err != nil {
 		return nil, fmt.Errorf("load public key failed: %w", err)
 	}
 
 	return &Verifier{
-		publicKey: publicKey,
-		issuer:    cfg.Issuer,
-		audience:  cfg.Audience,
-		clockSkew: cfg.ClockSkew,
-		now:       time.Now,
+		fileKey:     fileKey,
+		issuer:      cfg.Issuer,
+		audience:    cfg.Audience,
+		clockSkew:   cfg.ClockSkew,
+		optionalSID: cfg.OptionalSessionID,
+		now:         time.Now,
 	}, nil
 }
 
@@ -144,13 +195,70 @@ func (s *Signer) Sign(sessionID, subject string, version int64) (string, error)
 		JWTID:     randomID(),
 	}
 
+	s.maybeReloadKey()
+	key := s.current.Load()
 	header := Header{
-		Alg: "RS256",
+		Alg: key.alg,
 		Typ: "JWT",
-		KID: s.kid,
+		KID: key.kid,
 	}
 
-	return signToken(s.privateKey, header, claims)
+	return signToken(key.privateKey, header, claims)
+}
+
+// Algorithm 返回签名使用的 JWS 算法。
+func (s *Signer) Algorithm() string {
+	return s.current.Load().alg
+}
+
+// KID 返回当前签名使用的 kid。
+func (s *Signer) KID() string {
+	return s.current.Load().kid
+}
+
+// reloadKey 重读私钥文件，内容变化且解析成功时替换当前密钥并返回 true。
+// 私钥与 kid 位于同一文件（PEM 头），写入方以原子替换更新该文件，重读时不会得到不匹配的组合。
+func (s *Signer) reloadKey() (bool, error) {
+	data, err := os.ReadFile(s.privateKeyPath)
+	if err != nil {
+		return false, fmt.Errorf("read private key file failed: %w", err)
+	}
+
+	digest := sha256.Sum256(data)
+	if old := s.current.Load(); old != nil && bytes.Equal(old.digest[:], digest[:]) {
+		return false, nil
+	}
+
+	privateKey, err := ParsePrivateKeyPEM(data)
+	if err != nil {
+		return false, err
+	}
+	alg, err := resolveAlgorithm(s.configuredAlg, privateKey.Public())
+	if err != nil {
+		return false, err
+	}
+	kid := s.configuredKID
+	if headerKID := PrivateKeyPEMKID(data); headerKID != "" {
+		kid = headerKID
+	}
+
+	s.current.Store(&signingKey{privateKey: privateKey, alg: alg, kid: kid, digest: digest})
+	return true, nil
+}
+
+// maybeReloadKey 在距上次检查超过重读间隔时重读私钥文件，失败时保留当前密钥。
+func (s *Signer) maybeReloadKey() {
+	if s.reloadInterval <= 0 || !s.mu.TryLock() {
+		return
+	}
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastCheck) < s.reloadInterval {
+		return
+	}
+	s.lastCheck = now
+	_, _ = s.reloadKey()
 }
 
 func (v *Verifier) Verify(token string) (*Claims, error) {
@@ -159,12 +267,15 @@ func (v *Verifier) Verify(token string) (*Claims, error) {
 		return nil, err
 	}
 
-	if header.Alg != "RS256" {
-		return nil, fmt.Errorf("unsupported alg: %s", header.Alg)
+	publicKey, alg, err := v.resolveKey(header.KID)
+	if err != nil {
+		return nil, err
+	}
+	if header.Alg != alg {
+		return nil, fmt.Errorf("unexpected alg: %s", header.Alg)
 	}
 
-	hash := sha256.Sum256([]byte(signingInput))
-	if err := rsa.VerifyPKCS1v15(v.publicKey, crypto.SHA256, hash[:], signature); err != nil {
+	if err := verifySignature(alg, publicKey, signingInput, signature); err != nil {
 		return nil, fmt.Errorf("verify signature failed: %w", err)
 	}
 
@@ -175,6 +286,22 @@ func (v *Verifier) Verify(token string) (*Claims, error) {
 	return claims, nil
 }
 
+func (v *Verifier) resolveKey(kid string) (crypto.PublicKey, string, error) {
+	if v.jwks == nil {
+		publicKey, alg := v.fileKey.LoadKID(kid)
+		return publicKey, alg, nil
+	}
+
+	key, err := v.jwks.lookup(kid)
+	if err != nil {
+		return nil, "", err
+	}
+	if v.alg != "" && key.alg != v.alg {
+		return nil, "", fmt.Errorf("key %q alg %s does not match configured alg %s", kid, key.alg, v.alg)
+	}
+	return key.publicKey, key.alg, nil
+}
+
 func ParseBearerToken(headerValue string) (string, error) {
 	parts := strings.Fields(strings.TrimSpace(headerValue))
 	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
@@ -196,7 +323,7 @@ func (v *Verifier) validateClaims(claims *Claims) error {
 	if claims.Audience != v.audience {
 		return fmt.Errorf("audience mismatch: got %q", claims.Audience)
 	}
-	if strings.TrimSpace(claims.SessionID) == "" {
+	if !v.optionalSID && strings.TrimSpace(claims.SessionID) == "" {
 		return fmt.Errorf("sid claim is required")
 	}
 
@@ -224,7 +351,7 @@ func (v *Verifier) validateClaims(claims *Claims) error {
 	return nil
 }
 
-func signToken(privateKey *rsa.PrivateKey, header Header, claims Claims) (string, error) {
+func signToken(privateKey crypto.Signer, header Header, claims Claims) (string, error) {
 	headerBytes, err := json.Marshal(header)
 	if err != nil {
 		return "", fmt.Errorf("marshal header failed: %w", err)
@@ -235,8 +362,7 @@ func signToken(privateKey *rsa.PrivateKey, header Header, claims Claims) (string
 	}
 
 	signingInput := rawBase64URL.EncodeToString(headerBytes) + "." + rawBase64URL.EncodeToString(claimsBytes)
-	hash := sha256.Sum256([]byte(signingInput))
-	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, hash[:])
+	signature, err := signPayload(header.Alg, privateKey, signingInput)
 	if err != nil {
 		return "", fmt.Errorf("sign token failed: %w", err)
 	}
@@ -244,6 +370,115 @@ func signToken(privateKey *rsa.PrivateKey, header Header, claims Claims) (string
 	return signingInput + "." + rawBase64URL.EncodeToString(signature), nil
 }
 
+func signPayload(alg string, privateKey crypto.Signer, signingInput string) ([]byte, error) {
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		if alg != AlgRS256 {
+			break
+		}
+		hash := sha256.Sum256([]byte(signingInput))
+		return rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	case *ecdsa.PrivateKey:
+		if alg != AlgES256 {
+			break
+		}
+		hash := sha256.Sum256([]byte(signingInput))
+		r, sigS, err := ecdsa.Sign(rand.Reader, key, hash[:])
+		if err != nil {
+			return nil, err
+		}
+		signature := make([]byte, 2*es256ComponentSize)
+		r.FillBytes(signature[:es256ComponentSize])
+		sigS.FillBytes(signature[es256ComponentSize:])
+		return signature, nil
+	case ed25519.PrivateKey:
+		if alg != AlgEdDSA {
+			break
+		}
+		return ed25519.Sign(key, []byte(signingInput)), nil
+	}
+	return nil, fmt.Errorf("alg %s does not match private key type %T", alg, privateKey)
+}
+
+func verifySignature(alg string, publicKey crypto.PublicKey, signingInput string, signature []byte) error {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		if alg != AlgRS256 {
+			break
+		}
+		hash := sha256.Sum256([]byte(signingInput))
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature)
+	case *ecdsa.PublicKey:
+		if alg != AlgES256 {
+			break
+		}
+		if len(signature) != 2*es256ComponentSize {
+			return fmt.Errorf("invalid ES256 signature length: %d", len(signature))
+		}
+		hash := sha256.Sum256([]byte(signingInput))
+		r := new(big.Int).SetBytes(signature[:es256ComponentSize])
+		sigS := new(big.Int).SetBytes(signature[es256ComponentSize:])
+		if !ecdsa.Verify(key, hash[:], r, sigS) {
+			return fmt.Errorf("ecdsa verification error")
+		}
+		return nil
+	case ed25519.PublicKey:
+		if alg != AlgEdDSA {
+			break
+		}
+		if !ed25519.Verify(key, []byte(signingInput), signature) {
+			return fmt.Errorf("ed25519 verification error")
+		}
+		return nil
+	}
+	return fmt.Errorf("alg %s does not match public key type %T", alg, publicKey)
+}
+
+// KeyAlgorithm 根据公钥类型返回对应的 JWS 算法。
+func KeyAlgorithm(publicKey crypto.PublicKey) (string, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return AlgRS256, nil
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return "", fmt.Errorf("unsupported ecdsa curve: %s", key.Curve.Params().Name)
+		}
+		return AlgES256, nil
+	case ed25519.PublicKey:
+		return AlgEdDSA, nil
+	default:
+		return "", fmt.Errorf("unsupported key type %T", publicKey)
+	}
+}
+
+func resolveAlgorithm(configured string, publicKey crypto.PublicKey) (string, error) {
+	keyAlg, err := KeyAlgorithm(publicKey)
+	if err != nil {
+		return "", err
+	}
+	configured = strings.TrimSpace(configured)
+	if configured == "" {
+		return keyAlg, nil
+	}
+	if !IsSupportedAlgorithm(configured) {
+		return "", fmt.Errorf("unsupported alg: %s", configured)
+	}
+	if configured != keyAlg {
+		return "", fmt.Errorf("alg %s does not match key algorithm %s", configured, keyAlg)
+	}
+	return configured, nil
+}
+
+// IsSupportedAlgorithm 判断 alg 是否为受支持的签名算法。
+func IsSupportedAlgorithm(alg string) bool {
+	switch alg {
+	case AlgRS256, AlgES256, AlgEdDSA:
+		return true
+	default:
+		return false
+	}
+}
+
 func parseToken(token string) (*Header, *Claims, []byte, string, error) {
 	parts := strings.Split(token, ".")
 	if len(parts) != 3 {
@@ -276,11 +511,20 @@ func parseToken(token string) (*Header, *Claims, []byte, string, error) {
 	return &header, &claims, signature, signingInput, nil
 }
 
-func loadRSAPrivateKey(path string) (*rsa.PrivateKey, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("read private key file failed: %w", err)
+// PrivateKeyPEMKIDHeader 为私钥 PEM 中携带 kid 的头字段名。
+const PrivateKeyPEMKIDHeader = "kid"
+
+// PrivateKeyPEMKID 返回私钥 PEM 头中的 kid，不存在时返回空串。
+func PrivateKeyPEMKID(data []byte) string {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return ""
 	}
+	return strings.TrimSpace(block.Headers[PrivateKeyPEMKIDHeader])
+}
+
+// ParsePrivateKeyPEM 解析 PKCS1/SEC1/PKCS8 格式的 RSA、ECDSA P-256 或 Ed25519 私钥。
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
 	block, rest := pem.Decode(data)
 	if block == nil {
 		return nil, fmt.Errorf("invalid private key pem")
@@ -292,23 +536,28 @@ func loadRSAPrivateKey(path string) (*rsa.PrivateKey, error) {
 	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
 		return key, nil
 	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		if _, err := KeyAlgorithm(key.Public()); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
 
 	keyAny, err := x509.ParsePKCS8PrivateKey(block.Bytes)
 	if err != nil {
 		return nil, fmt.Errorf("parse private key failed: %w", err)
 	}
-	key, ok := keyAny.(*rsa.PrivateKey)
+	key, ok := keyAny.(crypto.Signer)
 	if !ok {
-		return nil, fmt.Errorf("private key is not RSA")
+		return nil, fmt.Errorf("unsupported private key type %T", keyAny)
+	}
+	if _, err := KeyAlgorithm(key.Public()); err != nil {
+		return nil, err
 	}
 	return key, nil
 }
 
-func loadRSAPublicKey(path string) (*rsa.PublicKey, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("read public key file failed: %w", err)
-	}
+func parsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
 	block, rest := pem.Decode(data)
 	if block == nil {
 		return nil, fmt.Errorf("invalid public key pem")
@@ -317,20 +566,16 @@ func loadRSAPublicKey(path string) (*rsa.PublicKey, error) {
 		return nil, fmt.Errorf("extra data found in public key pem")
 	}
 
-	if pubAny, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
-		if pub, ok := pubAny.(*rsa.PublicKey); ok {
-			return pub, nil
-		}
-		return nil, fmt.Errorf("public key is not RSA")
-	}
-
-	cert, err := x509.ParseCertificate(block.Bytes)
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
 	if err != nil {
-		return nil, fmt.Errorf("parse public key failed: %w", err)
+		cert, certErr := x509.ParseCertificate(block.Bytes)
+		if certErr != nil {
+			return nil, fmt.Errorf("parse public key failed: %w", certErr)
+		}
+		pub = cert.PublicKey
 	}
-	pub, ok := cert.PublicKey.(*rsa.PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("certificate public key is not RSA")
+	if _, err := KeyAlgorithm(pub); err != nil {
+		return nil, err
 	}
 	return pub, nil
 }
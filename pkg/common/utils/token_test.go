@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"os"
 	"strings"
 	"testing"
@@ -150,3 +151,129 @@ func TestNewVerifierFromConfig_RejectsExtraPEMData(t *testing.T) {
 	})
 	require.Error(t, err)
 }
+
+func TestSignerAndVerifier_Algorithms(t *testing.T) {
+	cases := []struct {
+		name      string
+		writeKeys func(string) (string, string, error)
+		alg       string
+	}{
+		{name: "rs256", writeKeys: testutil.WriteTestRSAKeys, alg: AlgRS256},
+		{name: "es256", writeKeys: testutil.WriteTestECKeys, alg: AlgES256},
+		{name: "eddsa", writeKeys: testutil.WriteTestEd25519Keys, alg: AlgEdDSA},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			privatePath, publicPath, err := tc.writeKeys(t.TempDir())
+			require.NoError(t, err)
+
+			signer, err := NewSignerFromConfig(SignerConfig{
+				PrivateKeyPath: privatePath,
+				Issuer:         "agentland-gateway",
+				Audience:       "sandbox",
+				TTL:            5 * time.Minute,
+				Algorithm:      tc.alg,
+			})
+			require.NoError(t, err)
+			require.Equal(t, tc.alg, signer.Algorithm())
+
+			verifier, err := NewVerifierFromConfig(VerifierConfig{
+				PublicKeyPath: publicPath,
+				Issuer:        "agentland-gateway",
+				Audience:      "sandbox",
+				ClockSkew:     30 * time.Second,
+			})
+			require.NoError(t, err)
+
+			token, err := signer.Sign("session-abc", "", 0)
+			require.NoError(t, err)
+
+			header, _, _, _, err := parseToken(token)
+			require.NoError(t, err)
+			require.Equal(t, tc.alg, header.Alg)
+
+			claims, err := verifier.Verify(token)
+			require.NoError(t, err)
+			require.Equal(t, "session-abc", claims.SessionID)
+		})
+	}
+}
+
+func TestNewSignerFromConfig_RejectsAlgorithmKeyMismatch(t *testing.T) {
+	privatePath, _, err := testutil.WriteTestRSAKeys(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = NewSignerFromConfig(SignerConfig{
+		PrivateKeyPath: privatePath,
+		Issuer:         "agentland-gateway",
+		Audience:       "sandbox",
+		TTL:            5 * time.Minute,
+		Algorithm:      AlgES256,
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not match")
+}
+
+func TestVerifier_RejectsUnexpectedAlg(t *testing.T) {
+	privatePath, _, err := testutil.WriteTestEd25519Keys(t.TempDir())
+	require.NoError(t, err)
+	_, publicPath, err := testutil.WriteTestRSAKeys(t.TempDir())
+	require.NoError(t, err)
+
+	signer, err := NewSignerFromConfig(SignerConfig{
+		PrivateKeyPath: privatePath,
+		Issuer:         "agentland-gateway",
+		Audience:       "sandbox",
+		TTL:            5 * time.Minute,
+	})
+	require.NoError(t, err)
+
+	verifier, err := NewVerifierFromConfig(VerifierConfig{
+		PublicKeyPath: publicPath,
+		Issuer:        "agentland-gateway",
+		Audience:      "sandbox",
+		ClockSkew:     30 * time.Second,
+		Algorithm:     AlgRS256,
+	})
+	require.NoError(t, err)
+
+	token, err := signer.Sign("session-abc", "", 0)
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(token)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unexpected alg")
+}
+
+type staticRevocationStore map[string]int64
+
+func (s staticRevocationStore) MinTokenVersion(_ context.Context, sessionID string) (int64, error) {
+	return s[sessionID], nil
+}
+
+func TestCheckRevocation(t *testing.T) {
+	store := staticRevocationStore{"session-abc": 3}
+
+	require.NoError(t, CheckRevocation(context.Background(), store, &Claims{SessionID: "session-abc", Version: 3}))
+	require.NoError(t, CheckRevocation(context.Background(), store, &Claims{SessionID: "session-other", Version: 0}))
+	require.NoError(t, CheckRevocation(context.Background(), nil, &Claims{SessionID: "session-abc", Version: 0}))
+
+	err := CheckRevocation(context.Background(), store, &Claims{SessionID: "session-abc", Version: 2})
+	require.ErrorIs(t, err, ErrTokenRevoked)
+}
+
+func TestTokenVersionWatermark_RejectsOlderVersionsAfterNewerToken(t *testing.T) {
+	watermark := NewTokenVersionWatermark()
+	ctx := context.Background()
+
+	require.NoError(t, CheckRevocation(ctx, watermark, &Claims{SessionID: "session-abc", Version: 0}))
+	// 网关递增版本后签发的 token 推进最小版本，此前签发的 token 随即失效
+	require.NoError(t, CheckRevocation(ctx, watermark, &Claims{SessionID: "session-abc", Version: 2}))
+	err := CheckRevocation(ctx, watermark, &Claims{SessionID: "session-abc", Version: 1})
+	require.ErrorIs(t, err, ErrTokenRevoked)
+	require.NoError(t, CheckRevocation(ctx, watermark, &Claims{SessionID: "session-abc", Version: 2}))
+
+	// 不同会话的版本互不影响
+	require.NoError(t, CheckRevocation(ctx, watermark, &Claims{SessionID: "session-other", Version: 0}))
+}
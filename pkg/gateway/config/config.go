@@ -10,7 +10,58 @@ type Config struct {
 	SandboxJWTAudience    string        `json:"sandbox_jwt_audience"`
 	SandboxJWTTTL         time.Duration `json:"sandbox_jwt_ttl"`
 	SandboxJWTKID         string        `json:"sandbox_jwt_kid"`
+	SandboxJWTAlgorithm   string        `json:"sandbox_jwt_algorithm"`
+	// SandboxJWTKeyRefreshInterval 为重读密钥集合、切换到新 active 密钥的间隔，<=0 时不刷新
+	SandboxJWTKeyRefreshInterval time.Duration `json:"sandbox_jwt_key_refresh_interval"`
 
 	DefaultAgentRuntimeName      string `json:"default_agent_runtime_name"`
 	DefaultAgentRuntimeNamespace string `json:"default_agent_runtime_namespace"`
+
+	// RateLimitRPS 为单个调用方（未鉴权时为客户端 IP）及单个会话每秒允许的请求数，<=0 时关闭限流
+	RateLimitRPS   float64 `json:"rate_limit_rps"`
+	RateLimitBurst int     `json:"rate_limit_burst"`
+
+	// DrainTimeout 为停机时等待在途代理请求结束的最长时间，<=0 时使用默认值
+	DrainTimeout time.Duration `json:"drain_timeout"`
+
+	// MaxUploadBytes 为文件写入、上传类请求体的大小上限，超出时网关直接返回 413，<=0 表示不限制
+	MaxUploadBytes int64 `json:"max_upload_bytes"`
+
+	// ExecuteDefaultTimeout 为代码执行请求未指定 timeout_ms 时网关注入的超时，<=0 时沿用沙箱默认值
+	ExecuteDefaultTimeout time.Duration `json:"execute_default_timeout"`
+	// ExecuteMaxTimeout 为代码执行允许的最大超时，超出时截断，<=0 时上限为 300s
+	ExecuteMaxTimeout time.Duration `json:"execute_max_timeout"`
+
+	// SandboxHTTP* 为网关转发到沙箱的共享 HTTP 连接池参数，<=0 时使用默认值；
+	// MaxConnsPerHost 与 ResponseHeaderTimeout 默认不限制
+	SandboxHTTPMaxIdleConns          int           `json:"sandbox_http_max_idle_conns"`
+	SandboxHTTPMaxIdleConnsPerHost   int           `json:"sandbox_http_max_idle_conns_per_host"`
+	SandboxHTTPMaxConnsPerHost       int           `json:"sandbox_http_max_conns_per_host"`
+	SandboxHTTPIdleConnTimeout       time.Duration `json:"sandbox_http_idle_conn_timeout"`
+	SandboxHTTPDialTimeout           time.Duration `json:"sandbox_http_dial_timeout"`
+	SandboxHTTPResponseHeaderTimeout time.Duration `json:"sandbox_http_response_header_timeout"`
+	// SandboxHTTPTLSCAPath 非空时以该 PEM 文件中的证书校验 https 沙箱地址
+	SandboxHTTPTLSCAPath string `json:"sandbox_http_tls_ca_path"`
+
+	// AuthAPIKeys 为 "<principal>:<key>" 形式的客户端 API Key 列表
+	AuthAPIKeys []string `json:"auth_api_keys"`
+	// AuthJWTIssuer 非空时以 AuthJWTPublicKeyPath 或 AuthJWTJWKSURL 校验客户端 Bearer JWT；
+	// API Key 与 JWT 均未配置时网关不校验客户端身份
+	AuthJWTIssuer        string `json:"auth_jwt_issuer"`
+	AuthJWTAudience      string `json:"auth_jwt_audience"`
+	AuthJWTPublicKeyPath string `json:"auth_jwt_public_key_path"`
+	AuthJWTJWKSURL       string `json:"auth_jwt_jwks_url"`
+
+	// CORSAllowedOrigins 为允许跨域访问的来源，"*" 表示任意来源，为空时不处理跨域请求；
+	// CORSAllowedMethods、CORSAllowedHeaders 为空时使用默认值
+	CORSAllowedOrigins   []string      `json:"cors_allowed_origins"`
+	CORSAllowedMethods   []string      `json:"cors_allowed_methods"`
+	CORSAllowedHeaders   []string      `json:"cors_allowed_headers"`
+	CORSAllowCredentials bool          `json:"cors_allow_credentials"`
+	CORSMaxAge           time.Duration `json:"cors_max_age"`
+
+	// AuditLogPath 非空时将代码执行审计记录追加写入该文件，否则写入进程日志
+	AuditLogPath string `json:"audit_log_path"`
+	// AuditIncludeCode 为 true 时审计记录包含截断后的代码原文
+	AuditIncludeCode bool `json:"audit_include_code"`
 }
@@ -1,18 +1,30 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	pb "github.com/Fl0rencess720/agentland/pb/agentcore"
+	"github.com/Fl0rencess720/agentland/pkg/common/observability"
 	"github.com/Fl0rencess720/agentland/pkg/gateway/config"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/middleware"
 	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/db"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/metrics"
 	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.uber.org/zap"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	grpcstatus "google.golang.org/grpc/status"
 )
 
 type AgentSessionHandler struct {
@@ -24,8 +36,20 @@ type AgentSessionHandler struct {
 	defaultRuntimeNS   string
 }
 
-// InitAgentSessionApi 注册路由并在内部完成 Handler 字段的初始化
-func InitAgentSessionApi(group *gin.RouterGroup, cfg *config.Config) {
+// AgentSessionEndpoint 为会话声明的一个端口及其在网关上的访问路径
+type AgentSessionEndpoint struct {
+	Port uint32 `json:"port"`
+	Path string `json:"path"`
+}
+
+// ListEndpointsResp 为会话声明的端口列表，未声明端口时 endpoints 为空数组
+type ListEndpointsResp struct {
+	SessionID string                 `json:"session_id"`
+	Endpoints []AgentSessionEndpoint `json:"endpoints"`
+}
+
+// InitAgentSessionApi 注册路由并在内部完成 Handler 字段的初始化，transport 为转发到沙箱的共享连接池
+func InitAgentSessionApi(group *gin.RouterGroup, cfg *config.Config, transport http.RoundTripper) {
 	client, err := BuildAgentCoreClient(viper.GetString("agentcore.address"))
 	if err != nil {
 		zap.L().Error("Init AgentSession CoreClient failed", zap.Error(err))
@@ -42,19 +66,20 @@ func InitAgentSessionApi(group *gin.RouterGroup, cfg *config.Config) {
 		agentCoreClient:    client,
 		sessionStore:       db.NewSessionStore(),
 		tokenSigner:        signer,
-		proxyEngine:        NewProxyEngine(),
+		proxyEngine:        NewProxyEngine(transport),
 		defaultRuntimeName: cfg.DefaultAgentRuntimeName,
 		defaultRuntimeNS:   cfg.DefaultAgentRuntimeNamespace,
 	}
 
 	group.POST("/invocations/*path", h.Invoke)
 	group.GET("/invocations/*path", h.Invoke)
+	group.GET("/:sessionId/endpoints", h.ListEndpoints)
 	group.Any("/:sessionId/endpoints/by-port/:port", h.ProxyByPort)
 	group.Any("/:sessionId/endpoints/by-port/:port/*path", h.ProxyByPort)
 }
 
 func (h *AgentSessionHandler) Invoke(ctx *gin.Context) {
-	bodyBytes, ok := readRequestBody(ctx)
+	bodyBytes, ok := readProxyRequestBody(ctx)
 	if !ok {
 		return
 	}
@@ -62,6 +87,9 @@ func (h *AgentSessionHandler) Invoke(ctx *gin.Context) {
 	sandboxInfo, sessionID, err := h.resolveOrCreateSession(ctx)
 	if err != nil {
 		zap.L().Error("Resolve agent session failed", zap.Error(err))
+		if writeCreateSessionError(ctx, err) {
+			return
+		}
 		response.ErrorResponse(ctx, response.ServerError)
 		return
 	}
@@ -83,12 +111,12 @@ func (h *AgentSessionHandler) ProxyByPort(ctx *gin.Context) {
 		return
 	}
 
-	bodyBytes, ok := readRequestBody(ctx)
+	bodyBytes, ok := readProxyRequestBody(ctx)
 	if !ok {
 		return
 	}
 
-	sandboxInfo, err := h.sessionStore.GetSession(ctx.Request.Context(), sessionID)
+	sandboxInfo, err := getSession(ctx.Request.Context(), h.sessionStore, sessionID)
 	if err != nil {
 		if errors.Is(err, db.ErrSessionNotFound) {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
@@ -107,6 +135,54 @@ func (h *AgentSessionHandler) ProxyByPort(ctx *gin.Context) {
 	h.forwardRequest(ctx, sessionID, sandboxInfo, ctx.Request.Method, internalPath, bodyBytes)
 }
 
+// ListEndpoints 返回会话运行时声明的端口，便于前端生成按端口透传的链接
+func (h *AgentSessionHandler) ListEndpoints(ctx *gin.Context) {
+	sessionID := strings.TrimSpace(ctx.Param("sessionId"))
+	if sessionID == "" {
+		response.ErrorResponse(ctx, response.FormError)
+		return
+	}
+
+	reqCtx, requestID := initRequestContext(ctx)
+
+	tracer := otel.Tracer("gateway.agentsession")
+	reqCtx, span := tracer.Start(reqCtx, "gateway.agentsession.get_rpc")
+	defer span.End()
+	span.SetAttributes(attribute.String("agentland.session_id", sessionID))
+
+	if requestID != "" {
+		reqCtx = metadata.AppendToOutgoingContext(reqCtx, observability.RequestIDHeader, requestID)
+		span.SetAttributes(attribute.String("request.id", requestID))
+	}
+
+	resp, err := h.agentCoreClient.GetAgentSession(reqCtx, &pb.GetAgentSessionRequest{SessionId: sessionID})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "get agentsession rpc failed")
+		if grpcstatus.Code(err) == grpccodes.NotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+			return
+		}
+		zap.L().Error("Get agent session failed", zap.String("sessionID", sessionID), zap.Error(err))
+		response.ErrorResponse(ctx, response.ServerError)
+		return
+	}
+
+	basePath := strings.TrimSuffix(ctx.Request.URL.Path, "/")
+	endpoints := make([]AgentSessionEndpoint, 0, len(resp.GetPorts()))
+	for _, port := range resp.GetPorts() {
+		endpoints = append(endpoints, AgentSessionEndpoint{
+			Port: port,
+			Path: fmt.Sprintf("%s/by-port/%d", basePath, port),
+		})
+	}
+
+	response.SuccessResponse(ctx, ListEndpointsResp{
+		SessionID: sessionID,
+		Endpoints: endpoints,
+	})
+}
+
 func (h *AgentSessionHandler) forwardRequest(ctx *gin.Context, sessionID string, sandboxInfo *db.SandboxInfo, method, path string, body []byte) {
 	reqCtx, requestID := initRequestContext(ctx)
 	ctx.Writer.Header().Set(SessionHeader, sessionID)
@@ -115,7 +191,16 @@ func (h *AgentSessionHandler) forwardRequest(ctx *gin.Context, sessionID string,
 		zap.L().Warn("Update latest activity failed", zap.String("sessionID", sessionID), zap.Error(err))
 	}
 
-	token, err := h.tokenSigner.Sign(sessionID, "", 0)
+	subject, err := resolveSandboxSubject(ctx, sandboxInfo)
+	if err != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if !middleware.AllowSession(ctx, sessionID) {
+		return
+	}
+
+	token, err := issueSandboxToken(reqCtx, h.sessionStore, h.tokenSigner, sessionID, subject)
 	if err != nil {
 		zap.L().Error("Issue sandbox token failed", zap.String("sessionID", sessionID), zap.Error(err))
 		response.ErrorResponse(ctx, response.ServerError)
@@ -137,6 +222,21 @@ func (h *AgentSessionHandler) forwardRequest(ctx *gin.Context, sessionID string,
 		SessionID:    sessionID,
 		SandboxToken: token,
 		RequestID:    requestID,
+		Refresh:      h.endpointRefresher(sessionID, sandboxInfo.GrpcEndpoint, requestID),
+	})
+}
+
+// endpointRefresher 在缓存地址失效时向 agentcore 查询会话的最新沙箱地址
+func (h *AgentSessionHandler) endpointRefresher(sessionID, staleEndpoint, requestID string) func(context.Context) (*url.URL, error) {
+	return sandboxRefresher(h.sessionStore, sessionID, staleEndpoint, func(ctx context.Context) (string, error) {
+		if requestID != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, observability.RequestIDHeader, requestID)
+		}
+		resp, err := h.agentCoreClient.GetAgentSession(ctx, &pb.GetAgentSessionRequest{SessionId: sessionID})
+		if err != nil {
+			return "", err
+		}
+		return resp.GetGrpcEndpoint(), nil
 	})
 }
 
@@ -145,7 +245,7 @@ func (h *AgentSessionHandler) resolveOrCreateSession(ctx *gin.Context) (*db.Sand
 	reqCtx := ctx.Request.Context()
 
 	if sessionID != "" {
-		sandboxInfo, err := h.sessionStore.GetSession(reqCtx, sessionID)
+		sandboxInfo, err := getSession(reqCtx, h.sessionStore, sessionID)
 		if err == nil {
 			return sandboxInfo, sessionID, nil
 		}
@@ -160,13 +260,22 @@ func (h *AgentSessionHandler) resolveOrCreateSession(ctx *gin.Context) (*db.Sand
 		return nil, "", fmt.Errorf("runtime name is required")
 	}
 
+	start := time.Now()
 	createResp, err := h.agentCoreClient.CreateAgentSession(reqCtx, &pb.CreateAgentSessionRequest{
 		RuntimeName:      runtimeName,
 		RuntimeNamespace: runtimeNamespace,
+		Owner:            resolveOwner(ctx),
+		IdempotencyKey:   strings.TrimSpace(ctx.GetHeader(IdempotencyKeyHeader)),
 	})
 	if err != nil {
+		result := metrics.CreateResultError
+		if grpcstatus.Code(err) == grpccodes.DeadlineExceeded {
+			result = metrics.CreateResultTimeout
+		}
+		metrics.ObserveSandboxCreate(metrics.SandboxKindAgentSession, result, start)
 		return nil, "", fmt.Errorf("create agent session failed: %w", err)
 	}
+	metrics.ObserveSandboxCreate(metrics.SandboxKindAgentSession, metrics.CreateResultSuccess, start)
 
 	info := &db.SandboxInfo{
 		SandboxID:    createResp.SessionId,
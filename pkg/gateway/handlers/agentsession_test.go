@@ -16,6 +16,8 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap"
+	grpccodes "google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
 )
 
 func TestAgentSessionHandlerSuite(t *testing.T) {
@@ -102,6 +104,28 @@ func (s *AgentSessionHandlerSuite) TestInvoke_CreateSessionAndProxy() {
 	s.mockAgentCoreClient.AssertExpectations(s.T())
 }
 
+func (s *AgentSessionHandlerSuite) TestInvoke_OwnerQuotaExceeded() {
+	req := httptest.NewRequest("POST", "/invocations/chat", strings.NewReader(`{}`))
+	req.Header.Set(OwnerHeader, "alice")
+	s.ctx.Request = req
+	s.ctx.Params = gin.Params{{Key: "path", Value: "/chat"}}
+
+	s.mockAgentCoreClient.On("CreateAgentSession",
+		mock.Anything,
+		&pb.CreateAgentSessionRequest{
+			RuntimeName:      "default-runtime",
+			RuntimeNamespace: "agentland-sandboxes",
+			Owner:            "alice",
+		},
+	).Return(nil, grpcstatus.Error(grpccodes.ResourceExhausted, "owner alice reached max concurrent sessions (1)")).Once()
+
+	s.handler.Invoke(s.ctx)
+
+	s.Equal(http.StatusTooManyRequests, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), "session quota exceeded")
+	s.mockAgentCoreClient.AssertExpectations(s.T())
+}
+
 func (s *AgentSessionHandlerSuite) TestInvoke_ReuseSessionFromHeader() {
 	s.handler.sessionStore = &mockSessionStore{
 		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
@@ -168,3 +192,40 @@ func (s *AgentSessionHandlerSuite) TestProxyByPort_SubPathSuccess() {
 	s.Equal(http.StatusOK, s.recorder.Code)
 	s.Equal("ok", s.recorder.Body.String())
 }
+
+func (s *AgentSessionHandlerSuite) TestListEndpoints() {
+	s.ctx.Request = httptest.NewRequest(http.MethodGet, "/api/agent-sessions/session-1/endpoints", nil)
+	s.ctx.Params = gin.Params{{Key: "sessionId", Value: "session-1"}}
+
+	s.mockAgentCoreClient.On("GetAgentSession",
+		mock.Anything,
+		&pb.GetAgentSessionRequest{SessionId: "session-1"},
+	).Return(&pb.GetAgentSessionResponse{
+		SessionId:    "session-1",
+		GrpcEndpoint: "sandbox.test:1883",
+		Ports:        []uint32{8080, 5173},
+	}, nil).Once()
+
+	s.handler.ListEndpoints(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.JSONEq(`{"code":200,"msg":"success","data":{"session_id":"session-1","endpoints":[
+		{"port":8080,"path":"/api/agent-sessions/session-1/endpoints/by-port/8080"},
+		{"port":5173,"path":"/api/agent-sessions/session-1/endpoints/by-port/5173"}]}}`, s.recorder.Body.String())
+	s.mockAgentCoreClient.AssertExpectations(s.T())
+}
+
+func (s *AgentSessionHandlerSuite) TestListEndpoints_SessionNotFound() {
+	s.ctx.Request = httptest.NewRequest(http.MethodGet, "/api/agent-sessions/missing/endpoints", nil)
+	s.ctx.Params = gin.Params{{Key: "sessionId", Value: "missing"}}
+
+	s.mockAgentCoreClient.On("GetAgentSession",
+		mock.Anything,
+		&pb.GetAgentSessionRequest{SessionId: "missing"},
+	).Return(nil, grpcstatus.Error(grpccodes.NotFound, "agentsession missing not found")).Once()
+
+	s.handler.ListEndpoints(s.ctx)
+
+	s.Equal(http.StatusNotFound, s.recorder.Code)
+	s.JSONEq(`{"error":"session not found"}`, s.recorder.Body.String())
+}
@@ -2,19 +2,29 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"path"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	pb "github.com/Fl0rencess720/agentland/pb/agentcore"
+	"github.com/Fl0rencess720/agentland/pkg/common/audit"
 	"github.com/Fl0rencess720/agentland/pkg/common/models"
 	"github.com/Fl0rencess720/agentland/pkg/common/observability"
+	"github.com/Fl0rencess720/agentland/pkg/common/utils"
 	"github.com/Fl0rencess720/agentland/pkg/gateway/config"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/middleware"
 	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/db"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/metrics"
 	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
@@ -22,7 +32,9 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.uber.org/zap"
+	grpccodes "google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	grpcstatus "google.golang.org/grpc/status"
 )
 
 type CodeInterpreterHandler struct {
@@ -30,14 +42,83 @@ type CodeInterpreterHandler struct {
 	sessionStore    SessionStore
 	tokenSigner     TokenSigner
 	proxyEngine     *ProxyEngine
+	// auditor 记录经网关转发的代码执行，为 nil 时不记录
+	auditor *audit.Recorder
+	// maxUploadBytes 为文件写入、上传类请求体的大小上限，<=0 表示不限制
+	maxUploadBytes int64
+	// executeTimeout 为转发代码执行前注入默认超时、截断超长超时的策略
+	executeTimeout executeTimeoutPolicy
 }
 
 type CreateSandboxResp struct {
 	SandboxID string `json:"sandbox_id"`
 }
 
-// InitCodeInterpreterApi 注册路由并在内部完成 Handler 字段的初始化
-func InitCodeInterpreterApi(group *gin.RouterGroup, cfg *config.Config) {
+// GetSandboxResp 为沙箱的供给状态，phase 为空表示控制器尚未处理
+type GetSandboxResp struct {
+	SandboxID string `json:"sandbox_id"`
+	Phase     string `json:"phase"`
+	Reason    string `json:"reason,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+type DeleteSandboxResp struct {
+	SandboxID string `json:"sandbox_id"`
+}
+
+// KeepaliveSandboxResp 中 expires_at 为续期后的过期时间，取空闲回收时间与会话最长存活时间中较早者
+type KeepaliveSandboxResp struct {
+	SandboxID string    `json:"sandbox_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RevokeSandboxTokensResp 中 token_version 为吊销后会话的最小 token 版本
+type RevokeSandboxTokensResp struct {
+	SandboxID    string `json:"sandbox_id"`
+	TokenVersion int64  `json:"token_version"`
+}
+
+// SandboxSummary 为列表接口返回的会话概要，不暴露沙箱内部地址
+type SandboxSummary struct {
+	SandboxID string    `json:"sandbox_id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ListSandboxesResp 中 next_cursor 为空表示已遍历完毕
+type ListSandboxesResp struct {
+	Sandboxes  []SandboxSummary `json:"sandboxes"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+// sandboxWorkspaceRoot 为沙箱镜像默认的工作区根目录，与 korokd 的 AL_KOROKD_WORKSPACE_ROOT 默认值一致
+const sandboxWorkspaceRoot = "/workspace"
+
+// sandboxLanguages 为执行上下文支持的语言
+var sandboxLanguages = []string{"python", "bash", "node"}
+
+// ExecuteTimeoutInfo 描述网关对 timeout_ms 的约束，单位均为毫秒
+type ExecuteTimeoutInfo struct {
+	Default int `json:"default"`
+	Min     int `json:"min"`
+	Max     int `json:"max"`
+}
+
+// SandboxInfoResp 描述沙箱的使用约定，供 MCP 等客户端自动配置；max_upload_bytes 为 0 表示网关不限制
+type SandboxInfoResp struct {
+	WorkspaceRoot    string             `json:"workspace_root"`
+	Languages        []string           `json:"languages"`
+	ExecuteTimeoutMs ExecuteTimeoutInfo `json:"execute_timeout_ms"`
+	MaxUploadBytes   int64              `json:"max_upload_bytes"`
+}
+
+const (
+	defaultListSandboxesLimit = 50
+	maxListSandboxesLimit     = 200
+)
+
+// InitCodeInterpreterApi 注册路由并在内部完成 Handler 字段的初始化，transport 为转发到沙箱的共享连接池
+func InitCodeInterpreterApi(group *gin.RouterGroup, cfg *config.Config, transport http.RoundTripper) {
 	client, err := BuildAgentCoreClient(viper.GetString("agentcore.address"))
 	if err != nil {
 		zap.L().Error("Init CodeInterpreter CoreClient failed", zap.Error(err))
@@ -50,23 +131,41 @@ func InitCodeInterpreterApi(group *gin.RouterGroup, cfg *config.Config) {
 		return
 	}
 
+	auditSink, err := audit.NewSink(cfg.AuditLogPath)
+	if err != nil {
+		zap.L().Error("Init CodeInterpreter audit sink failed", zap.Error(err))
+		return
+	}
+
 	h := &CodeInterpreterHandler{
 		agentCoreClient: client,
 		sessionStore:    db.NewSessionStore(),
 		tokenSigner:     signer,
-		proxyEngine:     NewProxyEngine(),
+		proxyEngine:     NewProxyEngine(transport),
+		auditor:         audit.NewRecorder(auditSink, cfg.AuditIncludeCode),
+		maxUploadBytes:  cfg.MaxUploadBytes,
+		executeTimeout:  newExecuteTimeoutPolicy(cfg.ExecuteDefaultTimeout, cfg.ExecuteMaxTimeout),
 	}
 
+	group.GET("/info", h.GetSandboxInfo)
 	group.POST("/sandboxes", h.CreateSandbox)
+	group.GET("/sandboxes", h.ListSandboxes)
+	group.GET("/sandboxes/:sandboxId", h.GetSandbox)
+	group.DELETE("/sandboxes/:sandboxId", h.DeleteSandbox)
+	group.POST("/sandboxes/:sandboxId/keepalive", h.KeepaliveSandbox)
+	group.POST("/sandboxes/:sandboxId/revoke-tokens", h.RevokeSandboxTokens)
+	group.GET("/contexts", h.ListContexts)
 	group.POST("/contexts", h.CreateContext)
 	group.POST("/contexts/:contextId/execute", h.ExecuteInContext)
+	group.POST("/contexts/:contextId/execute-batch", h.ExecuteBatch)
+	group.POST("/contexts/:contextId/install", h.InstallPackages)
+	group.POST("/contexts/:contextId/interrupt", h.InterruptContext)
+	group.POST("/contexts/:contextId/reset", h.ResetContext)
+	group.GET("/contexts/:contextId/history", h.GetContextHistory)
 	group.DELETE("/contexts/:contextId", h.DeleteContext)
 
-	group.GET("/fs/tree", h.GetFSTree)
-	group.GET("/fs/file", h.GetFSFile)
-	group.POST("/fs/file", h.WriteFSFile)
-	group.POST("/fs/upload", h.UploadFSFile)
-	group.GET("/fs/download", h.DownloadFSFile)
+	// gin 不允许通配路由与同级静态路由共存，/fs 下的已知接口由 ProxyFS 按方法与子路径分发
+	group.Any("/fs/*path", h.ProxyFS)
 }
 
 func (h *CodeInterpreterHandler) CreateSandbox(ctx *gin.Context) {
@@ -81,13 +180,27 @@ func (h *CodeInterpreterHandler) CreateSandbox(ctx *gin.Context) {
 		span.SetAttributes(attribute.String("request.id", requestID))
 	}
 
-	resp, err := h.agentCoreClient.CreateCodeInterpreter(reqCtx, &pb.CreateSandboxRequest{})
+	start := time.Now()
+	resp, err := h.agentCoreClient.CreateCodeInterpreter(reqCtx, &pb.CreateSandboxRequest{
+		Owner: resolveOwner(ctx),
+	})
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "create codeinterpreter rpc failed")
+		if writeCreateSessionError(ctx, err) {
+			metrics.ObserveSandboxCreate(metrics.SandboxKindCodeRunner, metrics.CreateResultError, start)
+			return
+		}
+		if grpcstatus.Code(err) == grpccodes.DeadlineExceeded {
+			metrics.ObserveSandboxCreate(metrics.SandboxKindCodeRunner, metrics.CreateResultTimeout, start)
+			ctx.JSON(http.StatusGatewayTimeout, gin.H{"error": "sandbox provisioning timed out"})
+			return
+		}
+		metrics.ObserveSandboxCreate(metrics.SandboxKindCodeRunner, metrics.CreateResultError, start)
 		response.ErrorResponse(ctx, response.ServerError)
 		return
 	}
+	metrics.ObserveSandboxCreate(metrics.SandboxKindCodeRunner, metrics.CreateResultSuccess, start)
 	span.SetAttributes(attribute.String("agentland.session_id", resp.SandboxId))
 
 	if err := h.sessionStore.UpdateLatestActivity(reqCtx, resp.SandboxId); err != nil {
@@ -97,6 +210,271 @@ func (h *CodeInterpreterHandler) CreateSandbox(ctx *gin.Context) {
 	response.SuccessResponse(ctx, CreateSandboxResp{SandboxID: resp.SandboxId})
 }
 
+// GetSandboxInfo 返回工作区根目录、支持的语言、超时范围与上传大小上限，不访问沙箱
+func (h *CodeInterpreterHandler) GetSandboxInfo(ctx *gin.Context) {
+	response.SuccessResponse(ctx, SandboxInfoResp{
+		WorkspaceRoot: sandboxWorkspaceRoot,
+		Languages:     sandboxLanguages,
+		ExecuteTimeoutMs: ExecuteTimeoutInfo{
+			Default: h.executeTimeout.effectiveDefault(),
+			Min:     minExecuteTimeoutMs,
+			Max:     h.executeTimeout.effectiveMax(),
+		},
+		MaxUploadBytes: max(h.maxUploadBytes, 0),
+	})
+}
+
+// ListSandboxes 按游标分页列出请求方名下的存活沙箱，供丢失会话 Header 的客户端找回；
+// 会话 ID 即访问沙箱的凭据，无法确定请求方身份时拒绝列出
+func (h *CodeInterpreterHandler) ListSandboxes(ctx *gin.Context) {
+	owner := resolveOwner(ctx)
+	if owner == "" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "owner identity required"})
+		return
+	}
+
+	var cursor uint64
+	if raw := strings.TrimSpace(ctx.Query("cursor")); raw != "" {
+		parsed, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			response.ErrorResponse(ctx, response.FormError)
+			return
+		}
+		cursor = parsed
+	}
+	limit := int64(defaultListSandboxesLimit)
+	if raw := strings.TrimSpace(ctx.Query("limit")); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed <= 0 || parsed > maxListSandboxesLimit {
+			response.ErrorResponse(ctx, response.FormError)
+			return
+		}
+		limit = parsed
+	}
+
+	reqCtx, _ := initRequestContext(ctx)
+	sessions, next, err := h.sessionStore.ListSessions(reqCtx, owner, strings.TrimSpace(ctx.Query("prefix")), cursor, limit)
+	if err != nil {
+		zap.L().Error("List sessions failed", zap.Error(err))
+		response.ErrorResponse(ctx, response.ServerError)
+		return
+	}
+
+	resp := ListSandboxesResp{Sandboxes: make([]SandboxSummary, 0, len(sessions))}
+	for _, s := range sessions {
+		resp.Sandboxes = append(resp.Sandboxes, SandboxSummary{
+			SandboxID: s.SandboxID,
+			CreatedAt: s.CreatedAt,
+			ExpiresAt: s.ExpiresAt,
+		})
+	}
+	if next != 0 {
+		resp.NextCursor = strconv.FormatUint(next, 10)
+	}
+	response.SuccessResponse(ctx, resp)
+}
+
+// authorizeSandbox 读取沙箱会话并校验请求方为其 owner：会话不存在时返回 404 与 notFound，身份不一致时返回 403，
+// 校验失败时已写入响应并返回 false
+func (h *CodeInterpreterHandler) authorizeSandbox(ctx *gin.Context, reqCtx context.Context, sandboxID, notFound string) (*db.SandboxInfo, bool) {
+	info, err := getSession(reqCtx, h.sessionStore, sandboxID)
+	if err != nil {
+		if errors.Is(err, db.ErrSessionNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": notFound})
+			return nil, false
+		}
+		zap.L().Error("Get session info failed", zap.String("sandboxID", sandboxID), zap.Error(err))
+		response.ErrorResponse(ctx, response.ServerError)
+		return nil, false
+	}
+	if _, err := resolveSandboxSubject(ctx, info); err != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return info, true
+}
+
+func (h *CodeInterpreterHandler) GetSandbox(ctx *gin.Context) {
+	sandboxID := strings.TrimSpace(ctx.Param("sandboxId"))
+	if sandboxID == "" {
+		response.ErrorResponse(ctx, response.FormError)
+		return
+	}
+
+	reqCtx, requestID := initRequestContext(ctx)
+	if _, ok := h.authorizeSandbox(ctx, reqCtx, sandboxID, "sandbox not found"); !ok {
+		return
+	}
+
+	tracer := otel.Tracer("gateway.codeinterpreter")
+	reqCtx, span := tracer.Start(reqCtx, "gateway.codeinterpreter.get_rpc")
+	defer span.End()
+	span.SetAttributes(attribute.String("agentland.session_id", sandboxID))
+
+	if requestID != "" {
+		reqCtx = metadata.AppendToOutgoingContext(reqCtx, observability.RequestIDHeader, requestID)
+		span.SetAttributes(attribute.String("request.id", requestID))
+	}
+
+	resp, err := h.agentCoreClient.GetCodeInterpreter(reqCtx, &pb.GetSandboxRequest{SandboxId: sandboxID})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "get codeinterpreter rpc failed")
+		if grpcstatus.Code(err) == grpccodes.NotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "sandbox not found"})
+			return
+		}
+		zap.L().Error("Get codeinterpreter failed", zap.String("sandboxID", sandboxID), zap.Error(err))
+		response.ErrorResponse(ctx, response.ServerError)
+		return
+	}
+
+	response.SuccessResponse(ctx, GetSandboxResp{
+		SandboxID: sandboxID,
+		Phase:     resp.Phase,
+		Reason:    resp.Reason,
+		Message:   resp.Message,
+	})
+}
+
+// KeepaliveSandbox 仅刷新会话的最后活跃时间，供长时间不执行代码的客户端显式续期，避免被空闲 GC 回收
+func (h *CodeInterpreterHandler) KeepaliveSandbox(ctx *gin.Context) {
+	sandboxID := strings.TrimSpace(ctx.Param("sandboxId"))
+	if sandboxID == "" {
+		response.ErrorResponse(ctx, response.FormError)
+		return
+	}
+
+	reqCtx, _ := initRequestContext(ctx)
+
+	info, ok := h.authorizeSandbox(ctx, reqCtx, sandboxID, "session not found")
+	if !ok {
+		return
+	}
+	if err := h.sessionStore.UpdateLatestActivity(reqCtx, sandboxID); err != nil {
+		if errors.Is(err, db.ErrSessionNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+			return
+		}
+		zap.L().Error("Keepalive sandbox failed", zap.String("sandboxID", sandboxID), zap.Error(err))
+		response.ErrorResponse(ctx, response.ServerError)
+		return
+	}
+
+	expiresAt := time.Now().Add(db.MaxIdleDuration)
+	if !info.ExpiresAt.IsZero() && info.ExpiresAt.Before(expiresAt) {
+		expiresAt = info.ExpiresAt
+	}
+	response.SuccessResponse(ctx, KeepaliveSandboxResp{
+		SandboxID: sandboxID,
+		ExpiresAt: expiresAt.UTC(),
+	})
+}
+
+// RevokeSandboxTokens 递增会话的最小 token 版本，并以新版本请求沙箱一次，使此前签发的 token 立即失效；
+// 沙箱只从网关签发的 token 中得知最小版本，同步失败时返回 502，旧 token 在下一次经网关转发的请求后失效
+func (h *CodeInterpreterHandler) RevokeSandboxTokens(ctx *gin.Context) {
+	sandboxID := strings.TrimSpace(ctx.Param("sandboxId"))
+	if sandboxID == "" {
+		response.ErrorResponse(ctx, response.FormError)
+		return
+	}
+
+	reqCtx, requestID := initRequestContext(ctx)
+	info, ok := h.authorizeSandbox(ctx, reqCtx, sandboxID, "session not found")
+	if !ok {
+		return
+	}
+	subject, err := resolveSandboxSubject(ctx, info)
+	if err != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	version, err := h.sessionStore.BumpTokenVersion(reqCtx, sandboxID)
+	if err != nil {
+		zap.L().Error("Bump token version failed", zap.String("sandboxID", sandboxID), zap.Error(err))
+		response.ErrorResponse(ctx, response.ServerError)
+		return
+	}
+	if err := h.syncTokenVersion(reqCtx, info, subject, requestID); err != nil {
+		zap.L().Warn("Sync token version to sandbox failed", zap.String("sandboxID", sandboxID), zap.Error(err))
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "sync token version to sandbox failed"})
+		return
+	}
+
+	response.SuccessResponse(ctx, RevokeSandboxTokensResp{
+		SandboxID:    sandboxID,
+		TokenVersion: version,
+	})
+}
+
+// syncTokenVersion 以会话当前的最小版本签发 token 并请求沙箱，沙箱验签后将进程内记录的最小版本推进到该版本
+func (h *CodeInterpreterHandler) syncTokenVersion(ctx context.Context, info *db.SandboxInfo, subject, requestID string) error {
+	token, err := issueSandboxToken(ctx, h.sessionStore, h.tokenSigner, info.SandboxID, subject)
+	if err != nil {
+		return err
+	}
+	target, err := resolveSandboxTarget(info.GrpcEndpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.JoinPath("/api/token/version").String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(SessionHeader, info.SandboxID)
+	if requestID != "" {
+		req.Header.Set(observability.RequestIDHeader, requestID)
+	}
+
+	resp, err := (&http.Client{Transport: h.proxyEngine.Transport}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("sandbox returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (h *CodeInterpreterHandler) DeleteSandbox(ctx *gin.Context) {
+	sandboxID := strings.TrimSpace(ctx.Param("sandboxId"))
+	if sandboxID == "" {
+		response.ErrorResponse(ctx, response.FormError)
+		return
+	}
+
+	reqCtx, requestID := initRequestContext(ctx)
+	if _, ok := h.authorizeSandbox(ctx, reqCtx, sandboxID, "sandbox not found"); !ok {
+		return
+	}
+
+	tracer := otel.Tracer("gateway.codeinterpreter")
+	reqCtx, span := tracer.Start(reqCtx, "gateway.codeinterpreter.delete_rpc")
+	defer span.End()
+	span.SetAttributes(attribute.String("agentland.session_id", sandboxID))
+
+	if requestID != "" {
+		reqCtx = metadata.AppendToOutgoingContext(reqCtx, observability.RequestIDHeader, requestID)
+		span.SetAttributes(attribute.String("request.id", requestID))
+	}
+
+	if _, err := h.agentCoreClient.DeleteCodeInterpreter(reqCtx, &pb.DeleteSandboxRequest{SandboxId: sandboxID}); err != nil {
+		zap.L().Error("Delete codeinterpreter failed", zap.String("sandboxID", sandboxID), zap.Error(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "delete codeinterpreter rpc failed")
+		response.ErrorResponse(ctx, response.ServerError)
+		return
+	}
+
+	response.SuccessResponse(ctx, DeleteSandboxResp{SandboxID: sandboxID})
+}
+
 func (h *CodeInterpreterHandler) CreateContext(ctx *gin.Context) {
 	var req models.CreateContextReq
 	bodyBytes, ok := bindJSONWithBody(ctx, &req)
@@ -107,6 +485,10 @@ func (h *CodeInterpreterHandler) CreateContext(ctx *gin.Context) {
 	h.forwardToSandbox(ctx, http.MethodPost, "/api/contexts", bodyBytes)
 }
 
+func (h *CodeInterpreterHandler) ListContexts(ctx *gin.Context) {
+	h.forwardToSandbox(ctx, http.MethodGet, "/api/contexts", nil)
+}
+
 func (h *CodeInterpreterHandler) ExecuteInContext(ctx *gin.Context) {
 	contextID := strings.TrimSpace(ctx.Param("contextId"))
 	if contextID == "" {
@@ -126,18 +508,128 @@ func (h *CodeInterpreterHandler) ExecuteInContext(ctx *gin.Context) {
 		writeSSEError(ctx, contextID, "invalid request body")
 		return
 	}
+	code, err := utils.DecodeCode(req.Code, req.CodeEncoding)
+	if err != nil {
+		writeSSEError(ctx, contextID, "invalid code encoding")
+		return
+	}
+	req.Code = code
 	if strings.TrimSpace(req.Code) == "" {
 		writeSSEError(ctx, contextID, "code is required")
 		return
 	}
-	if req.TimeoutMs != 0 && (req.TimeoutMs < 100 || req.TimeoutMs > 300000) {
+	if !validTimeout(req.TimeoutMs) {
 		writeSSEError(ctx, contextID, "timeout_ms must be between 100 and 300000")
 		return
 	}
+	bodyBytes, err = h.executeTimeout.applyExecuteTimeout(bodyBytes, req.TimeoutMs)
+	if err != nil {
+		writeSSEError(ctx, contextID, "invalid request body")
+		return
+	}
 
 	// Force SSE transport for code execution.
 	ctx.Request.Header.Set("Accept", "text/event-stream")
+	start := time.Now()
 	h.forwardToSandboxSSE(ctx, http.MethodPost, "/api/contexts/"+contextID+"/execute", bodyBytes, contextID)
+	h.auditExecution(ctx, contextID, req.Code, time.Since(start))
+}
+
+func (h *CodeInterpreterHandler) ExecuteBatch(ctx *gin.Context) {
+	contextID := strings.TrimSpace(ctx.Param("contextId"))
+	var req models.ExecuteBatchReq
+	bodyBytes, ok := bindJSONWithBody(ctx, &req)
+	if !ok || contextID == "" || len(req.Cells) == 0 {
+		response.ErrorResponse(ctx, response.FormError)
+		return
+	}
+	timeouts := make([]int, len(req.Cells))
+	for i, cell := range req.Cells {
+		if strings.TrimSpace(cell.Code) == "" || !validTimeout(cell.TimeoutMs) {
+			response.ErrorResponse(ctx, response.FormError)
+			return
+		}
+		timeouts[i] = cell.TimeoutMs
+	}
+	bodyBytes, err := h.executeTimeout.applyBatchTimeout(bodyBytes, timeouts)
+	if err != nil {
+		response.ErrorResponse(ctx, response.FormError)
+		return
+	}
+	start := time.Now()
+	h.forwardToSandbox(ctx, http.MethodPost, "/api/contexts/"+contextID+"/execute-batch", bodyBytes)
+	duration := time.Since(start)
+	for _, cell := range req.Cells {
+		h.auditExecution(ctx, contextID, cell.Code, duration)
+	}
+}
+
+// auditExecution 记录经网关转发的代码执行，退出码由沙箱侧审计记录
+func (h *CodeInterpreterHandler) auditExecution(ctx *gin.Context, contextID, code string, duration time.Duration) {
+	h.auditor.Record(audit.Execution{
+		Source:     audit.SourceGateway,
+		SessionID:  strings.TrimSpace(ctx.GetHeader(SessionHeader)),
+		Subject:    ctx.GetString(sandboxSubjectKey),
+		ContextID:  contextID,
+		Code:       code,
+		HTTPStatus: ctx.Writer.Status(),
+		Duration:   duration,
+	})
+}
+
+func (h *CodeInterpreterHandler) InstallPackages(ctx *gin.Context) {
+	contextID := strings.TrimSpace(ctx.Param("contextId"))
+	if contextID == "" {
+		writeSSEError(ctx, contextID, "context_id is required")
+		return
+	}
+
+	bodyBytes, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		writeSSEError(ctx, contextID, "read request body failed")
+		return
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+
+	var req models.InstallPackagesReq
+	if err := json.Unmarshal(bodyBytes, &req); err != nil {
+		writeSSEError(ctx, contextID, "invalid request body")
+		return
+	}
+	if len(req.Packages) == 0 {
+		writeSSEError(ctx, contextID, "packages is required")
+		return
+	}
+
+	ctx.Request.Header.Set("Accept", "text/event-stream")
+	h.forwardToSandboxSSE(ctx, http.MethodPost, "/api/contexts/"+contextID+"/install", bodyBytes, contextID)
+}
+
+func (h *CodeInterpreterHandler) InterruptContext(ctx *gin.Context) {
+	contextID := strings.TrimSpace(ctx.Param("contextId"))
+	if contextID == "" {
+		response.ErrorResponse(ctx, response.FormError)
+		return
+	}
+	h.forwardToSandbox(ctx, http.MethodPost, "/api/contexts/"+contextID+"/interrupt", nil)
+}
+
+func (h *CodeInterpreterHandler) ResetContext(ctx *gin.Context) {
+	contextID := strings.TrimSpace(ctx.Param("contextId"))
+	if contextID == "" {
+		response.ErrorResponse(ctx, response.FormError)
+		return
+	}
+	h.forwardToSandbox(ctx, http.MethodPost, "/api/contexts/"+contextID+"/reset", nil)
+}
+
+func (h *CodeInterpreterHandler) GetContextHistory(ctx *gin.Context) {
+	contextID := strings.TrimSpace(ctx.Param("contextId"))
+	if contextID == "" {
+		response.ErrorResponse(ctx, response.FormError)
+		return
+	}
+	h.forwardToSandbox(ctx, http.MethodGet, "/api/contexts/"+contextID+"/history", nil)
 }
 
 func (h *CodeInterpreterHandler) DeleteContext(ctx *gin.Context) {
@@ -149,6 +641,55 @@ func (h *CodeInterpreterHandler) DeleteContext(ctx *gin.Context) {
 	h.forwardToSandbox(ctx, http.MethodDelete, "/api/contexts/"+contextID, nil)
 }
 
+// ProxyFS 将 /fs 下的请求转发到 korokd：已知接口先做各自的参数校验，
+// 其余子路径连同方法、查询参数与请求体原样透传，korokd 新增 FS 接口时网关无需新增路由
+func (h *CodeInterpreterHandler) ProxyFS(ctx *gin.Context) {
+	subPath := path.Clean("/" + ctx.Param("path"))
+	if handler := h.fsHandler(ctx.Request.Method, subPath); handler != nil {
+		handler(ctx)
+		return
+	}
+	if !limitRequestBody(ctx, h.maxUploadBytes) {
+		return
+	}
+	h.forwardToSandbox(ctx, ctx.Request.Method, "/api/fs"+subPath, nil)
+}
+
+// fsHandler 返回需要网关预校验的 FS 接口，未命中时返回 nil
+func (h *CodeInterpreterHandler) fsHandler(method, subPath string) gin.HandlerFunc {
+	switch method + " " + subPath {
+	case "GET /tree":
+		return h.GetFSTree
+	case "GET /file":
+		return h.GetFSFile
+	case "GET /stat":
+		return h.StatFS
+	case "GET /search":
+		return h.SearchFS
+	case "GET /tail":
+		return h.TailFSFile
+	case "POST /file":
+		return h.WriteFSFile
+	case "DELETE /file":
+		return h.DeleteFSFile
+	case "POST /mkdir":
+		return h.MkdirFS
+	case "POST /move":
+		return h.MoveFS
+	case "POST /copy":
+		return h.CopyFS
+	case "POST /upload":
+		return h.UploadFSFile
+	case "POST /extract":
+		return h.ExtractFS
+	case "GET /download":
+		return h.DownloadFSFile
+	case "GET /archive":
+		return h.ArchiveFS
+	}
+	return nil
+}
+
 func (h *CodeInterpreterHandler) GetFSTree(ctx *gin.Context) {
 	h.forwardToSandbox(ctx, ctx.Request.Method, "/api/fs/tree", nil)
 }
@@ -157,7 +698,38 @@ func (h *CodeInterpreterHandler) GetFSFile(ctx *gin.Context) {
 	h.forwardToSandbox(ctx, ctx.Request.Method, "/api/fs/file", nil)
 }
 
+func (h *CodeInterpreterHandler) StatFS(ctx *gin.Context) {
+	if strings.TrimSpace(ctx.Query("path")) == "" {
+		response.ErrorResponse(ctx, response.FormError)
+		return
+	}
+	h.forwardToSandbox(ctx, http.MethodGet, "/api/fs/stat", nil)
+}
+
+func (h *CodeInterpreterHandler) SearchFS(ctx *gin.Context) {
+	if ctx.Query("q") == "" {
+		response.ErrorResponse(ctx, response.FormError)
+		return
+	}
+	h.forwardToSandbox(ctx, http.MethodGet, "/api/fs/search", nil)
+}
+
+// TailFSFile 透传 tail 请求；follow=true 时沙箱返回 SSE，按流式请求转发以免响应被缓冲
+func (h *CodeInterpreterHandler) TailFSFile(ctx *gin.Context) {
+	if strings.TrimSpace(ctx.Query("path")) == "" {
+		response.ErrorResponse(ctx, response.FormError)
+		return
+	}
+	if follow, _ := strconv.ParseBool(ctx.Query("follow")); follow {
+		ctx.Request.Header.Set("Accept", "text/event-stream")
+	}
+	h.forwardToSandbox(ctx, http.MethodGet, "/api/fs/tail", nil)
+}
+
 func (h *CodeInterpreterHandler) WriteFSFile(ctx *gin.Context) {
+	if !limitRequestBody(ctx, h.maxUploadBytes) {
+		return
+	}
 	var req models.WriteFSFileReq
 	bodyBytes, ok := bindJSONWithBody(ctx, &req)
 	if !ok || strings.TrimSpace(req.Path) == "" {
@@ -167,15 +739,68 @@ func (h *CodeInterpreterHandler) WriteFSFile(ctx *gin.Context) {
 	h.forwardToSandbox(ctx, http.MethodPost, "/api/fs/file", bodyBytes)
 }
 
+func (h *CodeInterpreterHandler) DeleteFSFile(ctx *gin.Context) {
+	if strings.TrimSpace(ctx.Query("path")) == "" {
+		response.ErrorResponse(ctx, response.FormError)
+		return
+	}
+	h.forwardToSandbox(ctx, http.MethodDelete, "/api/fs/file", nil)
+}
+
+func (h *CodeInterpreterHandler) MkdirFS(ctx *gin.Context) {
+	var req models.MkdirFSReq
+	bodyBytes, ok := bindJSONWithBody(ctx, &req)
+	if !ok || strings.TrimSpace(req.Path) == "" {
+		response.ErrorResponse(ctx, response.FormError)
+		return
+	}
+	h.forwardToSandbox(ctx, http.MethodPost, "/api/fs/mkdir", bodyBytes)
+}
+
+func (h *CodeInterpreterHandler) MoveFS(ctx *gin.Context) {
+	var req models.MoveFSReq
+	bodyBytes, ok := bindJSONWithBody(ctx, &req)
+	if !ok || strings.TrimSpace(req.Src) == "" || strings.TrimSpace(req.Dst) == "" {
+		response.ErrorResponse(ctx, response.FormError)
+		return
+	}
+	h.forwardToSandbox(ctx, http.MethodPost, "/api/fs/move", bodyBytes)
+}
+
+func (h *CodeInterpreterHandler) CopyFS(ctx *gin.Context) {
+	var req models.CopyFSReq
+	bodyBytes, ok := bindJSONWithBody(ctx, &req)
+	if !ok || strings.TrimSpace(req.Src) == "" || strings.TrimSpace(req.Dst) == "" {
+		response.ErrorResponse(ctx, response.FormError)
+		return
+	}
+	h.forwardToSandbox(ctx, http.MethodPost, "/api/fs/copy", bodyBytes)
+}
+
 func (h *CodeInterpreterHandler) UploadFSFile(ctx *gin.Context) {
 	contentType := strings.ToLower(strings.TrimSpace(ctx.GetHeader("Content-Type")))
 	if !strings.HasPrefix(contentType, "multipart/form-data") {
 		response.ErrorResponse(ctx, response.FormError)
 		return
 	}
+	if !limitRequestBody(ctx, h.maxUploadBytes) {
+		return
+	}
 	h.forwardToSandbox(ctx, http.MethodPost, "/api/fs/upload", nil)
 }
 
+func (h *CodeInterpreterHandler) ExtractFS(ctx *gin.Context) {
+	contentType := strings.ToLower(strings.TrimSpace(ctx.GetHeader("Content-Type")))
+	if !strings.HasPrefix(contentType, "multipart/form-data") {
+		response.ErrorResponse(ctx, response.FormError)
+		return
+	}
+	if !limitRequestBody(ctx, h.maxUploadBytes) {
+		return
+	}
+	h.forwardToSandbox(ctx, http.MethodPost, "/api/fs/extract", nil)
+}
+
 func (h *CodeInterpreterHandler) DownloadFSFile(ctx *gin.Context) {
 	if strings.TrimSpace(ctx.Query("path")) == "" {
 		response.ErrorResponse(ctx, response.FormError)
@@ -184,6 +809,14 @@ func (h *CodeInterpreterHandler) DownloadFSFile(ctx *gin.Context) {
 	h.forwardToSandbox(ctx, http.MethodGet, "/api/fs/download", nil)
 }
 
+func (h *CodeInterpreterHandler) ArchiveFS(ctx *gin.Context) {
+	if strings.TrimSpace(ctx.Query("path")) == "" {
+		response.ErrorResponse(ctx, response.FormError)
+		return
+	}
+	h.forwardToSandbox(ctx, http.MethodGet, "/api/fs/archive", nil)
+}
+
 func (h *CodeInterpreterHandler) forwardToSandbox(ctx *gin.Context, method, path string, body []byte) {
 	sessionID := strings.TrimSpace(ctx.GetHeader(SessionHeader))
 	if sessionID == "" {
@@ -194,7 +827,7 @@ func (h *CodeInterpreterHandler) forwardToSandbox(ctx *gin.Context, method, path
 	reqCtx, requestID := initRequestContext(ctx)
 	ctx.Writer.Header().Set(SessionHeader, sessionID)
 
-	sandboxInfo, err := h.sessionStore.GetSession(reqCtx, sessionID)
+	sandboxInfo, err := getSession(reqCtx, h.sessionStore, sessionID)
 	if err != nil {
 		if errors.Is(err, db.ErrSessionNotFound) {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
@@ -209,7 +842,17 @@ func (h *CodeInterpreterHandler) forwardToSandbox(ctx *gin.Context, method, path
 		zap.L().Warn("Update latest activity failed", zap.String("sessionID", sessionID), zap.Error(err))
 	}
 
-	token, err := h.tokenSigner.Sign(sessionID, "", 0)
+	subject, err := resolveSandboxSubject(ctx, sandboxInfo)
+	if err != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Set(sandboxSubjectKey, subject)
+	if !middleware.AllowSession(ctx, sessionID) {
+		return
+	}
+
+	token, err := issueSandboxToken(reqCtx, h.sessionStore, h.tokenSigner, sessionID, subject)
 	if err != nil {
 		zap.L().Error("Issue sandbox token failed", zap.String("sessionID", sessionID), zap.Error(err))
 		response.ErrorResponse(ctx, response.ServerError)
@@ -231,6 +874,7 @@ func (h *CodeInterpreterHandler) forwardToSandbox(ctx *gin.Context, method, path
 		SessionID:    sessionID,
 		SandboxToken: token,
 		RequestID:    requestID,
+		Refresh:      h.endpointRefresher(sessionID, sandboxInfo.GrpcEndpoint, requestID),
 	})
 }
 
@@ -298,7 +942,7 @@ func (h *CodeInterpreterHandler) forwardToSandboxSSE(
 	reqCtx, requestID := initRequestContext(ctx)
 	ctx.Writer.Header().Set(SessionHeader, sessionID)
 
-	sandboxInfo, err := h.sessionStore.GetSession(reqCtx, sessionID)
+	sandboxInfo, err := getSession(reqCtx, h.sessionStore, sessionID)
 	if err != nil {
 		if errors.Is(err, db.ErrSessionNotFound) {
 			writeSSEError(ctx, contextID, "session not found")
@@ -313,7 +957,17 @@ func (h *CodeInterpreterHandler) forwardToSandboxSSE(
 		zap.L().Warn("Update latest activity failed", zap.String("sessionID", sessionID), zap.Error(err))
 	}
 
-	token, err := h.tokenSigner.Sign(sessionID, "", 0)
+	subject, err := resolveSandboxSubject(ctx, sandboxInfo)
+	if err != nil {
+		writeSSEError(ctx, contextID, err.Error())
+		return
+	}
+	ctx.Set(sandboxSubjectKey, subject)
+	if !middleware.AllowSession(ctx, sessionID) {
+		return
+	}
+
+	token, err := issueSandboxToken(reqCtx, h.sessionStore, h.tokenSigner, sessionID, subject)
 	if err != nil {
 		zap.L().Error("Issue sandbox token failed", zap.String("sessionID", sessionID), zap.Error(err))
 		writeSSEError(ctx, contextID, "issue sandbox token failed")
@@ -335,5 +989,32 @@ func (h *CodeInterpreterHandler) forwardToSandboxSSE(
 		SessionID:    sessionID,
 		SandboxToken: token,
 		RequestID:    requestID,
+		Refresh:      h.endpointRefresher(sessionID, sandboxInfo.GrpcEndpoint, requestID),
+	})
+}
+
+// endpointRefresher 在缓存地址失效时以 CodeInterpreter 当前的 Pod IP 重新拼出 korokd 地址，端口沿用旧地址
+func (h *CodeInterpreterHandler) endpointRefresher(sessionID, staleEndpoint, requestID string) func(context.Context) (*url.URL, error) {
+	return sandboxRefresher(h.sessionStore, sessionID, staleEndpoint, func(ctx context.Context) (string, error) {
+		if requestID != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, observability.RequestIDHeader, requestID)
+		}
+		resp, err := h.agentCoreClient.GetCodeInterpreter(ctx, &pb.GetSandboxRequest{SandboxId: sessionID})
+		if err != nil {
+			return "", err
+		}
+		if resp.GetPodIp() == "" {
+			return "", fmt.Errorf("sandbox %s has no pod ip", sessionID)
+		}
+		return replaceEndpointHost(staleEndpoint, resp.GetPodIp()), nil
 	})
 }
+
+// replaceEndpointHost 将沙箱地址中的主机替换为 host，保留原端口
+func replaceEndpointHost(endpoint, host string) string {
+	target, err := resolveSandboxTarget(endpoint)
+	if err != nil || target.Port() == "" {
+		return host
+	}
+	return net.JoinHostPort(host, target.Port())
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -17,15 +19,23 @@ import (
 	pb "github.com/Fl0rencess720/agentland/pb/agentcore"
 	"github.com/Fl0rencess720/agentland/pkg/common/models"
 	"github.com/Fl0rencess720/agentland/pkg/common/testutil"
+	"github.com/Fl0rencess720/agentland/pkg/common/utils"
 	"github.com/Fl0rencess720/agentland/pkg/gateway/config"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/middleware"
 	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/db"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/metrics"
+	korokdhandlers "github.com/Fl0rencess720/agentland/pkg/korokd/handlers"
+	korokdmiddleware "github.com/Fl0rencess720/agentland/pkg/korokd/middleware"
 	"github.com/gin-gonic/gin"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
 )
 
 type MockAgentCoreServiceClient struct {
@@ -35,6 +45,24 @@ type MockAgentCoreServiceClient struct {
 type mockSessionStore struct {
 	getSessionFn           func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error)
 	updateLatestActivityFn func(ctx context.Context, sandboxID string) error
+	minTokenVersionFn      func(ctx context.Context, sandboxID string) (int64, error)
+	bumpTokenVersionFn     func(ctx context.Context, sandboxID string) (int64, error)
+	listSessionsFn         func(ctx context.Context, owner, prefix string, cursor uint64, limit int64) ([]*db.SandboxInfo, uint64, error)
+	updateEndpointFn       func(ctx context.Context, sandboxID, endpoint string) error
+}
+
+func (m *mockSessionStore) UpdateEndpoint(ctx context.Context, sandboxID, endpoint string) error {
+	if m.updateEndpointFn != nil {
+		return m.updateEndpointFn(ctx, sandboxID, endpoint)
+	}
+	return nil
+}
+
+func (m *mockSessionStore) ListSessions(ctx context.Context, owner, prefix string, cursor uint64, limit int64) ([]*db.SandboxInfo, uint64, error) {
+	if m.listSessionsFn != nil {
+		return m.listSessionsFn(ctx, owner, prefix, cursor, limit)
+	}
+	return nil, 0, nil
 }
 
 type mockTokenSigner struct {
@@ -55,6 +83,20 @@ func (m *mockSessionStore) UpdateLatestActivity(ctx context.Context, sandboxID s
 	return nil
 }
 
+func (m *mockSessionStore) MinTokenVersion(ctx context.Context, sandboxID string) (int64, error) {
+	if m.minTokenVersionFn != nil {
+		return m.minTokenVersionFn(ctx, sandboxID)
+	}
+	return 0, nil
+}
+
+func (m *mockSessionStore) BumpTokenVersion(ctx context.Context, sandboxID string) (int64, error) {
+	if m.bumpTokenVersionFn != nil {
+		return m.bumpTokenVersionFn(ctx, sandboxID)
+	}
+	return 0, fmt.Errorf("bump token version not implemented")
+}
+
 func (m *mockTokenSigner) Sign(sessionID, subject string, version int64) (string, error) {
 	if m.signFn != nil {
 		return m.signFn(sessionID, subject, version)
@@ -70,6 +112,22 @@ func (m *MockAgentCoreServiceClient) CreateCodeInterpreter(ctx context.Context,
 	return args.Get(0).(*pb.CreateSandboxResponse), args.Error(1)
 }
 
+func (m *MockAgentCoreServiceClient) GetCodeInterpreter(ctx context.Context, in *pb.GetSandboxRequest, opts ...grpc.CallOption) (*pb.GetSandboxResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.GetSandboxResponse), args.Error(1)
+}
+
+func (m *MockAgentCoreServiceClient) DeleteCodeInterpreter(ctx context.Context, in *pb.DeleteSandboxRequest, opts ...grpc.CallOption) (*pb.DeleteSandboxResponse, error) {
+	args := m.Called(ctx, in)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.DeleteSandboxResponse), args.Error(1)
+}
+
 func (m *MockAgentCoreServiceClient) CreateAgentSession(ctx context.Context, in *pb.CreateAgentSessionRequest, opts ...grpc.CallOption) (*pb.CreateAgentSessionResponse, error) {
 	args := m.Called(ctx, in)
 	if args.Get(0) == nil {
@@ -122,7 +180,7 @@ func TestInitCodeInterpreterApi_RegistersSessionFSRoutes(t *testing.T) {
 
 	r := gin.New()
 	api := r.Group("/api")
-	InitCodeInterpreterApi(api.Group("/code-runner"), cfg)
+	InitCodeInterpreterApi(api.Group("/code-runner"), cfg, http.DefaultTransport)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/code-runner/fs/tree?path=.", nil)
 	rec := httptest.NewRecorder()
@@ -211,6 +269,136 @@ func (s *CodeInterpreterSuite) TestCreateContext_ProxySuccess() {
 	s.Contains(s.recorder.Body.String(), `"context_id":"ctx-1"`)
 }
 
+func (s *CodeInterpreterSuite) TestCreateContext_SignsWithSessionTokenVersion() {
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+		minTokenVersionFn: func(ctx context.Context, sandboxID string) (int64, error) {
+			s.Equal("session-1", sandboxID)
+			return 4, nil
+		},
+	}
+	s.handler.tokenSigner = &mockTokenSigner{
+		signFn: func(sessionID, subject string, version int64) (string, error) {
+			s.Equal(int64(4), version)
+			return "versioned.jwt.token", nil
+		},
+	}
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal("Bearer versioned.jwt.token", r.Header.Get("Authorization"))
+		return &http.Response{
+			StatusCode: http.StatusCreated,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"context_id":"ctx-1"}`)),
+		}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/contexts", strings.NewReader(`{"language":"python"}`))
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.CreateContext(s.ctx)
+
+	s.Equal(http.StatusCreated, s.recorder.Code)
+}
+
+func (s *CodeInterpreterSuite) TestCreateContext_TokenVersionLookupFails() {
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+		minTokenVersionFn: func(ctx context.Context, sandboxID string) (int64, error) {
+			return 0, fmt.Errorf("redis unavailable")
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/contexts", strings.NewReader(`{"language":"python"}`))
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+	tokenErrors := promtestutil.ToFloat64(metrics.SandboxTokenErrors)
+
+	s.handler.CreateContext(s.ctx)
+
+	s.Equal(http.StatusInternalServerError, s.recorder.Code)
+	s.Equal(tokenErrors+1, promtestutil.ToFloat64(metrics.SandboxTokenErrors))
+}
+
+func (s *CodeInterpreterSuite) TestStatFS_SignsTokenWithSessionOwner() {
+	privatePath, publicPath, err := testutil.WriteTestRSAKeys(s.T().TempDir())
+	s.Require().NoError(err)
+	signer, err := BuildTokenSigner(&config.Config{
+		SandboxJWTPrivatePath: privatePath,
+		SandboxJWTIssuer:      "agentland-gateway",
+		SandboxJWTAudience:    "sandbox",
+		SandboxJWTTTL:         time.Minute,
+	})
+	s.Require().NoError(err)
+	verifier, err := utils.NewVerifierFromConfig(utils.VerifierConfig{
+		PublicKeyPath: publicPath,
+		Issuer:        "agentland-gateway",
+		Audience:      "sandbox",
+	})
+	s.Require().NoError(err)
+
+	s.handler.tokenSigner = signer
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883", Owner: "alice"}, nil
+		},
+	}
+	var subjects []string
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		token, err := utils.ParseBearerToken(r.Header.Get("Authorization"))
+		s.Require().NoError(err)
+		claims, err := verifier.Verify(token)
+		s.Require().NoError(err)
+		s.Equal("session-1", claims.SessionID)
+		subjects = append(subjects, claims.Subject)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"code":200,"msg":"success","data":{}}`)),
+		}, nil
+	})
+
+	// 携带一致的身份时正常签发
+	for _, owner := range []string{"alice", " alice "} {
+		s.recorder = httptest.NewRecorder()
+		s.ctx, _ = gin.CreateTestContext(s.recorder)
+		req := httptest.NewRequest(http.MethodGet, "/fs/stat?path=data.csv", nil)
+		req.Header.Set(SessionHeader, "session-1")
+		req.Header.Set(OwnerHeader, owner)
+		s.ctx.Request = req
+
+		s.handler.StatFS(s.ctx)
+		s.Equal(http.StatusOK, s.recorder.Code)
+	}
+	s.Equal([]string{"alice", "alice"}, subjects)
+
+	s.recorder = httptest.NewRecorder()
+	s.ctx, _ = gin.CreateTestContext(s.recorder)
+	req := httptest.NewRequest(http.MethodGet, "/fs/stat?path=data.csv", nil)
+	req.Header.Set(SessionHeader, "session-1")
+	req.Header.Set(OwnerHeader, "mallory")
+	s.ctx.Request = req
+
+	s.handler.StatFS(s.ctx)
+	s.Equal(http.StatusForbidden, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), "session owner mismatch")
+
+	// 未携带身份的请求不能沿用会话 owner
+	s.recorder = httptest.NewRecorder()
+	s.ctx, _ = gin.CreateTestContext(s.recorder)
+	req = httptest.NewRequest(http.MethodGet, "/fs/stat?path=data.csv", nil)
+	req.Header.Set(SessionHeader, "session-1")
+	s.ctx.Request = req
+
+	s.handler.StatFS(s.ctx)
+	s.Equal(http.StatusForbidden, s.recorder.Code)
+	s.Len(subjects, 2)
+}
+
 func (s *CodeInterpreterSuite) TestCreateContext_BashProxySuccess() {
 	reqBody := models.CreateContextReq{Language: "bash", CWD: "/workspace"}
 	jsonBytes, _ := json.Marshal(reqBody)
@@ -253,6 +441,48 @@ func (s *CodeInterpreterSuite) TestCreateContext_BashProxySuccess() {
 	s.Contains(s.recorder.Body.String(), `"language":"bash"`)
 }
 
+func (s *CodeInterpreterSuite) TestCreateContext_NodeProxySuccess() {
+	reqBody := models.CreateContextReq{Language: "node", CWD: "/workspace"}
+	jsonBytes, _ := json.Marshal(reqBody)
+
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			s.Equal("session-1", sandboxID)
+			return &db.SandboxInfo{
+				SandboxID:    "session-1",
+				GrpcEndpoint: "sandbox.test:1883",
+			}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal(http.MethodPost, r.Method)
+		s.Equal("/api/contexts", r.URL.Path)
+		body, err := io.ReadAll(r.Body)
+		s.NoError(err)
+		s.JSONEq(string(jsonBytes), string(body))
+		resp := &http.Response{
+			StatusCode: http.StatusCreated,
+			Header:     make(http.Header),
+			Body: io.NopCloser(strings.NewReader(
+				`{"context_id":"ctx-node-1","language":"node","cwd":"/workspace","state":"ready","created_at":"2026-02-17T08:30:00Z"}`,
+			)),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest("POST", "/contexts", bytes.NewBuffer(jsonBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.CreateContext(s.ctx)
+
+	s.Equal(http.StatusCreated, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), `"language":"node"`)
+}
+
 func (s *CodeInterpreterSuite) TestCreateSandbox_Success() {
 	req := httptest.NewRequest("POST", "/sandboxes", nil)
 	s.ctx.Request = req
@@ -290,137 +520,1129 @@ func (s *CodeInterpreterSuite) TestCreateSandbox_IgnoresBody() {
 	s.Contains(s.recorder.Body.String(), `"sandbox_id":"session-sbx-body-ignored"`)
 }
 
-func (s *CodeInterpreterSuite) TestCreateContext_MissingSession() {
-	reqBody := models.CreateContextReq{Language: "python", CWD: "/workspace"}
-	jsonBytes, _ := json.Marshal(reqBody)
-	req := httptest.NewRequest("POST", "/contexts", bytes.NewBuffer(jsonBytes))
-	req.Header.Set("Content-Type", "application/json")
-	s.ctx.Request = req
+func (s *CodeInterpreterSuite) TestCreateSandbox_ProvisioningTimeout() {
+	s.ctx.Request = httptest.NewRequest(http.MethodPost, "/sandboxes", nil)
 
-	s.handler.CreateContext(s.ctx)
+	s.mockAgentCoreClient.On("CreateCodeInterpreter",
+		mock.Anything,
+		&pb.CreateSandboxRequest{},
+	).Return(nil, grpcstatus.Error(grpccodes.DeadlineExceeded, "provisioning timed out")).Once()
 
-	s.Equal(http.StatusBadRequest, s.recorder.Code)
-	s.Contains(s.recorder.Body.String(), `"msg":"Form Error"`)
+	s.handler.CreateSandbox(s.ctx)
+
+	s.Equal(http.StatusGatewayTimeout, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), "sandbox provisioning timed out")
 }
 
-func (s *CodeInterpreterSuite) TestExecuteInContext_MissingSession() {
-	reqBody := models.ExecuteContextReq{Code: "print(1)"}
-	jsonBytes, _ := json.Marshal(reqBody)
-	req := httptest.NewRequest("POST", "/contexts/ctx-1/execute", bytes.NewBuffer(jsonBytes))
-	req.Header.Set("Content-Type", "application/json")
-	s.ctx.Request = req
-	s.ctx.Params = gin.Params{{Key: "contextId", Value: "ctx-1"}}
+func (s *CodeInterpreterSuite) TestCreateSandbox_OwnerQuotaExceeded() {
+	s.ctx.Request = httptest.NewRequest(http.MethodPost, "/sandboxes", nil)
+	s.ctx.Request.Header.Set(OwnerHeader, " alice ")
 
-	s.handler.ExecuteInContext(s.ctx)
+	s.mockAgentCoreClient.On("CreateCodeInterpreter",
+		mock.Anything,
+		&pb.CreateSandboxRequest{Owner: "alice"},
+	).Return(nil, grpcstatus.Error(grpccodes.ResourceExhausted, "owner alice reached max concurrent sessions (2)")).Once()
+
+	s.handler.CreateSandbox(s.ctx)
+
+	s.Equal(http.StatusTooManyRequests, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), "session quota exceeded")
+	s.mockAgentCoreClient.AssertExpectations(s.T())
+}
+
+func (s *CodeInterpreterSuite) TestCreateSandbox_AuthPrincipalOverridesOwnerHeader() {
+	s.ctx.Request = httptest.NewRequest(http.MethodPost, "/sandboxes", nil)
+	s.ctx.Request.Header.Set(OwnerHeader, "mallory")
+	s.ctx.Set(middleware.AuthPrincipalKey, "alice")
+
+	s.mockAgentCoreClient.On("CreateCodeInterpreter",
+		mock.Anything,
+		&pb.CreateSandboxRequest{Owner: "alice"},
+	).Return(nil, grpcstatus.Error(grpccodes.ResourceExhausted, "owner alice reached max concurrent sessions (2)")).Once()
+
+	s.handler.CreateSandbox(s.ctx)
+
+	s.Equal(http.StatusTooManyRequests, s.recorder.Code)
+	s.mockAgentCoreClient.AssertExpectations(s.T())
+}
+
+func (s *CodeInterpreterSuite) TestListSandboxes_Paginates() {
+	created := time.Date(2026, 2, 17, 8, 30, 0, 0, time.UTC)
+	s.handler.sessionStore = &mockSessionStore{
+		listSessionsFn: func(ctx context.Context, owner, prefix string, cursor uint64, limit int64) ([]*db.SandboxInfo, uint64, error) {
+			s.Equal("alice", owner)
+			s.Equal("sbx-", prefix)
+			s.Equal(uint64(7), cursor)
+			s.Equal(int64(2), limit)
+			return []*db.SandboxInfo{{
+				SandboxID:    "sbx-1",
+				GrpcEndpoint: "10.0.0.1:1883",
+				CreatedAt:    created,
+				ExpiresAt:    created.Add(time.Hour),
+			}}, 42, nil
+		},
+	}
+
+	s.ctx.Request = httptest.NewRequest(http.MethodGet, "/sandboxes?prefix=sbx-&cursor=7&limit=2", nil)
+	s.ctx.Request.Header.Set(OwnerHeader, "mallory")
+	s.ctx.Set(middleware.AuthPrincipalKey, "alice")
+	s.handler.ListSandboxes(s.ctx)
 
 	s.Equal(http.StatusOK, s.recorder.Code)
-	s.Contains(s.recorder.Header().Get("Content-Type"), "text/event-stream")
-	s.Contains(s.recorder.Body.String(), `"type":"error"`)
+	var body struct {
+		Data ListSandboxesResp `json:"data"`
+	}
+	s.NoError(json.Unmarshal(s.recorder.Body.Bytes(), &body))
+	s.Equal("42", body.Data.NextCursor)
+	s.Len(body.Data.Sandboxes, 1)
+	s.Equal("sbx-1", body.Data.Sandboxes[0].SandboxID)
+	s.True(created.Equal(body.Data.Sandboxes[0].CreatedAt))
+	s.NotContains(s.recorder.Body.String(), "10.0.0.1")
 }
 
-func (s *CodeInterpreterSuite) TestExecuteInContext_ProxySuccess() {
-	reqBody := models.ExecuteContextReq{Code: "print(1)", TimeoutMs: 30000}
-	jsonBytes, _ := json.Marshal(reqBody)
+func (s *CodeInterpreterSuite) TestListSandboxes_InvalidQuery() {
+	for _, query := range []string{"cursor=abc", "limit=0", "limit=1000"} {
+		s.SetupTest()
+		s.ctx.Request = httptest.NewRequest(http.MethodGet, "/sandboxes?"+query, nil)
+		s.ctx.Request.Header.Set(OwnerHeader, "alice")
+		s.handler.ListSandboxes(s.ctx)
+		s.Equal(http.StatusBadRequest, s.recorder.Code, query)
+	}
+}
 
+func (s *CodeInterpreterSuite) TestListSandboxes_RequiresOwner() {
 	s.handler.sessionStore = &mockSessionStore{
+		listSessionsFn: func(ctx context.Context, owner, prefix string, cursor uint64, limit int64) ([]*db.SandboxInfo, uint64, error) {
+			s.Fail("sessions must not be listed without an owner")
+			return nil, 0, nil
+		},
+	}
+
+	s.ctx.Request = httptest.NewRequest(http.MethodGet, "/sandboxes", nil)
+	s.handler.ListSandboxes(s.ctx)
+
+	s.Equal(http.StatusUnauthorized, s.recorder.Code)
+}
+
+// ownedSessionStore 返回记录了 owner 的会话，供校验沙箱归属的接口使用
+func ownedSessionStore(owner string) *mockSessionStore {
+	return &mockSessionStore{
 		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
-			s.Equal("session-1", sandboxID)
-			return &db.SandboxInfo{
-				SandboxID:    "session-1",
-				GrpcEndpoint: "sandbox.test:1883",
-			}, nil
+			return &db.SandboxInfo{SandboxID: sandboxID, GrpcEndpoint: "sandbox.test:1883", Owner: owner}, nil
 		},
 	}
+}
 
-	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
-		s.Equal(http.MethodPost, r.Method)
-		s.Equal("/api/contexts/ctx-1/execute", r.URL.Path)
-		s.Equal("text/event-stream", r.Header.Get("Accept"))
-		s.Equal("Bearer default.jwt.token", r.Header.Get("Authorization"))
-		body, err := io.ReadAll(r.Body)
-		s.NoError(err)
-		s.JSONEq(string(jsonBytes), string(body))
-		resp := &http.Response{
-			StatusCode: http.StatusOK,
-			Header:     make(http.Header),
-			Body: io.NopCloser(strings.NewReader(
-				"data: {\"type\":\"init\",\"timestamp\":1,\"context_id\":\"ctx-1\"}\n\n" +
-					"data: {\"type\":\"stdout\",\"timestamp\":2,\"context_id\":\"ctx-1\",\"text\":\"1\\\\n\"}\n\n" +
-					"data: {\"type\":\"execution_complete\",\"timestamp\":3,\"context_id\":\"ctx-1\",\"execution_time\":5,\"exit_code\":0}\n\n",
-			)),
-		}
-		resp.Header.Set("Content-Type", "text/event-stream")
-		return resp, nil
-	})
+func (s *CodeInterpreterSuite) TestGetSandbox_ReportsFailure() {
+	s.ctx.Request = httptest.NewRequest(http.MethodGet, "/sandboxes/session-sbx-1", nil)
+	s.ctx.Request.Header.Set(OwnerHeader, "alice")
+	s.ctx.Params = gin.Params{{Key: "sandboxId", Value: "session-sbx-1"}}
+	s.handler.sessionStore = ownedSessionStore("alice")
 
-	req := httptest.NewRequest("POST", "/contexts/ctx-1/execute", bytes.NewBuffer(jsonBytes))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-agentland-session", "session-1")
-	s.ctx.Request = req
-	s.ctx.Params = gin.Params{{Key: "contextId", Value: "ctx-1"}}
+	s.mockAgentCoreClient.On("GetCodeInterpreter",
+		mock.Anything,
+		&pb.GetSandboxRequest{SandboxId: "session-sbx-1"},
+	).Return(&pb.GetSandboxResponse{
+		SandboxId: "session-sbx-1",
+		Phase:     "Failed",
+		PodIp:     "10.42.0.10",
+		Reason:    "PoolExhausted",
+		Message:   "no warm pod available",
+	}, nil).Once()
 
-	s.handler.ExecuteInContext(s.ctx)
+	s.handler.GetSandbox(s.ctx)
 
 	s.Equal(http.StatusOK, s.recorder.Code)
-	s.Equal("session-1", s.recorder.Header().Get("x-agentland-session"))
-	s.Contains(s.recorder.Header().Get("Content-Type"), "text/event-stream")
-	s.Contains(s.recorder.Body.String(), `"type":"execution_complete"`)
+	s.Contains(s.recorder.Body.String(), `"phase":"Failed"`)
+	s.Contains(s.recorder.Body.String(), `"reason":"PoolExhausted"`)
+	s.NotContains(s.recorder.Body.String(), "10.42.0.10")
 }
 
-func (s *CodeInterpreterSuite) TestGetFSTree_ProxySuccess() {
+func (s *CodeInterpreterSuite) TestGetFSFile_RetriesWithRefreshedEndpoint() {
+	updatedEndpoint := ""
 	s.handler.sessionStore = &mockSessionStore{
 		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "10.42.0.10:1883"}, nil
+		},
+		updateEndpointFn: func(ctx context.Context, sandboxID, endpoint string) error {
 			s.Equal("session-1", sandboxID)
-			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+			updatedEndpoint = endpoint
+			return nil
 		},
 	}
+	s.mockAgentCoreClient.On("GetCodeInterpreter",
+		mock.Anything,
+		&pb.GetSandboxRequest{SandboxId: "session-1"},
+	).Return(&pb.GetSandboxResponse{SandboxId: "session-1", Phase: "Running", PodIp: "10.42.0.11"}, nil).Once()
 
+	var hosts []string
 	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
-		s.Equal(http.MethodGet, r.Method)
-		s.Equal("sandbox.test:1883", r.URL.Host)
-		s.Equal("/api/fs/tree", r.URL.Path)
-		s.Equal("path=src&depth=2", r.URL.RawQuery)
-		s.Equal("Bearer default.jwt.token", r.Header.Get("Authorization"))
-		s.Equal("session-1", r.Header.Get("x-agentland-session"))
-
-		resp := &http.Response{
-			StatusCode: http.StatusOK,
-			Header:     make(http.Header),
-			Body:       io.NopCloser(strings.NewReader(`{"root":"src","nodes":[]}`)),
+		hosts = append(hosts, r.URL.Host)
+		if r.URL.Host == "10.42.0.10:1883" {
+			return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("no route to host")}
 		}
-		resp.Header.Set("Content-Type", "application/json")
-		return resp, nil
+		s.Equal("/api/fs/file", r.URL.Path)
+		s.Equal("path=a.txt", r.URL.RawQuery)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"code":200,"msg":"success","data":{"path":"a.txt"}}`)),
+		}, nil
 	})
 
-	req := httptest.NewRequest(http.MethodGet, "/fs/tree?path=src&depth=2", nil)
+	req := httptest.NewRequest(http.MethodGet, "/fs/file?path=a.txt", nil)
 	req.Header.Set("x-agentland-session", "session-1")
 	s.ctx.Request = req
 
-	s.handler.GetFSTree(s.ctx)
+	s.handler.GetFSFile(s.ctx)
 
 	s.Equal(http.StatusOK, s.recorder.Code)
-	s.Equal("session-1", s.recorder.Header().Get("x-agentland-session"))
-	s.JSONEq(`{"root":"src","nodes":[]}`, s.recorder.Body.String())
+	s.Equal([]string{"10.42.0.10:1883", "10.42.0.11:1883"}, hosts)
+	s.Equal("10.42.0.11:1883", updatedEndpoint)
+	s.mockAgentCoreClient.AssertExpectations(s.T())
 }
 
-func (s *CodeInterpreterSuite) TestGetFSTree_SessionNotFound() {
+func (s *CodeInterpreterSuite) TestGetFSFile_NoRetryWhenEndpointUnchanged() {
 	s.handler.sessionStore = &mockSessionStore{
 		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
-			return nil, db.ErrSessionNotFound
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "10.42.0.10:1883"}, nil
+		},
+		updateEndpointFn: func(ctx context.Context, sandboxID, endpoint string) error {
+			s.Fail("endpoint should not be updated")
+			return nil
 		},
 	}
+	s.mockAgentCoreClient.On("GetCodeInterpreter",
+		mock.Anything,
+		&pb.GetSandboxRequest{SandboxId: "session-1"},
+	).Return(&pb.GetSandboxResponse{SandboxId: "session-1", Phase: "Running", PodIp: "10.42.0.10"}, nil).Once()
 
-	req := httptest.NewRequest(http.MethodGet, "/fs/tree", nil)
-	req.Header.Set("x-agentland-session", "missing")
+	attempts := 0
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		attempts++
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/fs/file?path=a.txt", nil)
+	req.Header.Set("x-agentland-session", "session-1")
 	s.ctx.Request = req
 
-	s.handler.GetFSTree(s.ctx)
+	s.handler.GetFSFile(s.ctx)
+
+	s.Equal(http.StatusBadGateway, s.recorder.Code)
+	s.Equal(1, attempts)
+}
+
+func (s *CodeInterpreterSuite) TestGetSandbox_NotFound() {
+	s.ctx.Request = httptest.NewRequest(http.MethodGet, "/sandboxes/session-missing", nil)
+	s.ctx.Params = gin.Params{{Key: "sandboxId", Value: "session-missing"}}
+
+	s.handler.GetSandbox(s.ctx)
 
 	s.Equal(http.StatusNotFound, s.recorder.Code)
-	s.Contains(s.recorder.Body.String(), "session not found")
+	s.mockAgentCoreClient.AssertNotCalled(s.T(), "GetCodeInterpreter", mock.Anything, mock.Anything)
 }
 
-func (s *CodeInterpreterSuite) TestWriteFSFile_ProxySuccess() {
+func (s *CodeInterpreterSuite) TestSandboxManagement_RejectsOtherOwners() {
+	handlers := map[string]gin.HandlerFunc{
+		"get":       s.handler.GetSandbox,
+		"keepalive": s.handler.KeepaliveSandbox,
+		"delete":    s.handler.DeleteSandbox,
+	}
+	for name, handle := range handlers {
+		// 身份不一致与未携带身份均拒绝
+		for _, owner := range []string{"mallory", ""} {
+			s.recorder = httptest.NewRecorder()
+			s.ctx, _ = gin.CreateTestContext(s.recorder)
+			s.ctx.Request = httptest.NewRequest(http.MethodPost, "/sandboxes/session-sbx-1", nil)
+			s.ctx.Request.Header.Set(OwnerHeader, owner)
+			s.ctx.Params = gin.Params{{Key: "sandboxId", Value: "session-sbx-1"}}
+			s.handler.sessionStore = ownedSessionStore("alice")
+			s.handler.sessionStore.(*mockSessionStore).updateLatestActivityFn = func(ctx context.Context, sandboxID string) error {
+				s.Fail("sandbox of another owner must not be refreshed")
+				return nil
+			}
+
+			handle(s.ctx)
+
+			s.Equal(http.StatusForbidden, s.recorder.Code, "%s owner=%q", name, owner)
+		}
+	}
+	s.mockAgentCoreClient.AssertNotCalled(s.T(), "GetCodeInterpreter", mock.Anything, mock.Anything)
+	s.mockAgentCoreClient.AssertNotCalled(s.T(), "DeleteCodeInterpreter", mock.Anything, mock.Anything)
+}
+
+func (s *CodeInterpreterSuite) TestGetSandboxInfo_ReflectsConfig() {
+	s.ctx.Request = httptest.NewRequest(http.MethodGet, "/info", nil)
+	s.handler.maxUploadBytes = 8 << 20
+	s.handler.executeTimeout = newExecuteTimeoutPolicy(0, 20*time.Second)
+
+	s.handler.GetSandboxInfo(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.JSONEq(`{"code":200,"msg":"success","data":{
+		"workspace_root":"/workspace",
+		"languages":["python","bash","node"],
+		"execute_timeout_ms":{"default":20000,"min":100,"max":20000},
+		"max_upload_bytes":8388608
+	}}`, s.recorder.Body.String())
+}
+
+func (s *CodeInterpreterSuite) TestKeepaliveSandbox_RefreshesActivity() {
+	s.ctx.Request = httptest.NewRequest(http.MethodPost, "/sandboxes/session-sbx-1/keepalive", nil)
+	s.ctx.Params = gin.Params{{Key: "sandboxId", Value: "session-sbx-1"}}
+
+	expiresAt := time.Now().Add(5 * time.Minute).UTC().Truncate(time.Second)
+	touched := ""
+	s.ctx.Request.Header.Set(OwnerHeader, "alice")
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: sandboxID, ExpiresAt: expiresAt, Owner: "alice"}, nil
+		},
+		updateLatestActivityFn: func(ctx context.Context, sandboxID string) error {
+			touched = sandboxID
+			return nil
+		},
+	}
+
+	s.handler.KeepaliveSandbox(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Equal("session-sbx-1", touched)
+	var body struct {
+		Data KeepaliveSandboxResp `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(s.recorder.Body.Bytes(), &body))
+	s.Equal("session-sbx-1", body.Data.SandboxID)
+	// 会话剩余存活时间短于空闲阈值时，以会话过期时间为准
+	s.True(expiresAt.Equal(body.Data.ExpiresAt))
+}
+
+func (s *CodeInterpreterSuite) TestKeepaliveSandbox_NotFound() {
+	s.ctx.Request = httptest.NewRequest(http.MethodPost, "/sandboxes/session-missing/keepalive", nil)
+	s.ctx.Params = gin.Params{{Key: "sandboxId", Value: "session-missing"}}
+
+	s.handler.sessionStore = &mockSessionStore{
+		updateLatestActivityFn: func(ctx context.Context, sandboxID string) error {
+			s.Fail("expired session must not be refreshed")
+			return nil
+		},
+	}
+
+	s.handler.KeepaliveSandbox(s.ctx)
+
+	s.Equal(http.StatusNotFound, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), "session not found")
+}
+
+// 测试吊销后此前签发的 token 在沙箱侧被拒绝：网关递增版本后以新版本请求沙箱，沙箱随即推进最小版本
+func (s *CodeInterpreterSuite) TestRevokeSandboxTokens_RejectsPreviouslyIssuedToken() {
+	privatePath, publicPath, err := testutil.WriteTestRSAKeys(s.T().TempDir())
+	s.Require().NoError(err)
+	signer, err := utils.NewSignerFromConfig(utils.SignerConfig{
+		PrivateKeyPath: privatePath,
+		Issuer:         "agentland-gateway",
+		Audience:       "sandbox",
+		TTL:            5 * time.Minute,
+	})
+	s.Require().NoError(err)
+	verifier, err := utils.NewVerifierFromConfig(utils.VerifierConfig{
+		PublicKeyPath: publicPath,
+		Issuer:        "agentland-gateway",
+		Audience:      "sandbox",
+		ClockSkew:     30 * time.Second,
+	})
+	s.Require().NoError(err)
+
+	engine := gin.New()
+	api := engine.Group("/api")
+	api.Use(korokdmiddleware.SandboxAuth(verifier, utils.NewTokenVersionWatermark()))
+	korokdhandlers.InitTokenApi(api)
+	sandbox := httptest.NewServer(engine)
+	defer sandbox.Close()
+
+	callSandbox := func(token string) int {
+		req, err := http.NewRequest(http.MethodGet, sandbox.URL+"/api/token/version", nil)
+		s.Require().NoError(err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set(SessionHeader, "session-1")
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	var version int64
+	s.handler.tokenSigner = signer
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: sandboxID, Owner: "alice", GrpcEndpoint: sandbox.URL}, nil
+		},
+		minTokenVersionFn: func(ctx context.Context, sandboxID string) (int64, error) {
+			return version, nil
+		},
+		bumpTokenVersionFn: func(ctx context.Context, sandboxID string) (int64, error) {
+			version++
+			return version, nil
+		},
+	}
+
+	oldToken, err := signer.Sign("session-1", "alice", 0)
+	s.Require().NoError(err)
+	s.Equal(http.StatusOK, callSandbox(oldToken))
+
+	s.ctx.Request = httptest.NewRequest(http.MethodPost, "/sandboxes/session-1/revoke-tokens", nil)
+	s.ctx.Request.Header.Set(OwnerHeader, "alice")
+	s.ctx.Params = gin.Params{{Key: "sandboxId", Value: "session-1"}}
+	s.handler.RevokeSandboxTokens(s.ctx)
+
+	s.Require().Equal(http.StatusOK, s.recorder.Code)
+	var body struct {
+		Data RevokeSandboxTokensResp `json:"data"`
+	}
+	s.Require().NoError(json.Unmarshal(s.recorder.Body.Bytes(), &body))
+	s.Equal(int64(1), body.Data.TokenVersion)
+
+	s.Equal(http.StatusUnauthorized, callSandbox(oldToken))
+	newToken, err := signer.Sign("session-1", "alice", version)
+	s.Require().NoError(err)
+	s.Equal(http.StatusOK, callSandbox(newToken))
+}
+
+func (s *CodeInterpreterSuite) TestRevokeSandboxTokens_RejectsOtherOwners() {
+	s.ctx.Request = httptest.NewRequest(http.MethodPost, "/sandboxes/session-1/revoke-tokens", nil)
+	s.ctx.Request.Header.Set(OwnerHeader, "mallory")
+	s.ctx.Params = gin.Params{{Key: "sandboxId", Value: "session-1"}}
+	s.handler.sessionStore = ownedSessionStore("alice")
+
+	s.handler.RevokeSandboxTokens(s.ctx)
+
+	s.Equal(http.StatusForbidden, s.recorder.Code)
+}
+
+func (s *CodeInterpreterSuite) TestDeleteSandbox_Success() {
+	s.ctx.Request = httptest.NewRequest(http.MethodDelete, "/sandboxes/session-sbx-1", nil)
+	s.ctx.Request.Header.Set(OwnerHeader, "alice")
+	s.ctx.Params = gin.Params{{Key: "sandboxId", Value: "session-sbx-1"}}
+	s.handler.sessionStore = ownedSessionStore("alice")
+
+	s.mockAgentCoreClient.On("DeleteCodeInterpreter",
+		mock.Anything,
+		&pb.DeleteSandboxRequest{SandboxId: "session-sbx-1"},
+	).Return(&pb.DeleteSandboxResponse{}, nil).Once()
+
+	s.handler.DeleteSandbox(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), `"sandbox_id":"session-sbx-1"`)
+	s.mockAgentCoreClient.AssertExpectations(s.T())
+}
+
+func (s *CodeInterpreterSuite) TestDeleteSandbox_RPCError() {
+	s.ctx.Request = httptest.NewRequest(http.MethodDelete, "/sandboxes/session-sbx-1", nil)
+	s.ctx.Request.Header.Set(OwnerHeader, "alice")
+	s.ctx.Params = gin.Params{{Key: "sandboxId", Value: "session-sbx-1"}}
+	s.handler.sessionStore = ownedSessionStore("alice")
+
+	s.mockAgentCoreClient.On("DeleteCodeInterpreter",
+		mock.Anything,
+		&pb.DeleteSandboxRequest{SandboxId: "session-sbx-1"},
+	).Return(nil, fmt.Errorf("boom")).Once()
+
+	s.handler.DeleteSandbox(s.ctx)
+
+	s.Equal(http.StatusInternalServerError, s.recorder.Code)
+}
+
+func (s *CodeInterpreterSuite) TestCreateContext_MissingSession() {
+	reqBody := models.CreateContextReq{Language: "python", CWD: "/workspace"}
+	jsonBytes, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest("POST", "/contexts", bytes.NewBuffer(jsonBytes))
+	req.Header.Set("Content-Type", "application/json")
+	s.ctx.Request = req
+
+	s.handler.CreateContext(s.ctx)
+
+	s.Equal(http.StatusBadRequest, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), `"msg":"Form Error"`)
+}
+
+func (s *CodeInterpreterSuite) TestExecuteInContext_MissingSession() {
+	reqBody := models.ExecuteContextReq{Code: "print(1)"}
+	jsonBytes, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest("POST", "/contexts/ctx-1/execute", bytes.NewBuffer(jsonBytes))
+	req.Header.Set("Content-Type", "application/json")
+	s.ctx.Request = req
+	s.ctx.Params = gin.Params{{Key: "contextId", Value: "ctx-1"}}
+
+	s.handler.ExecuteInContext(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Contains(s.recorder.Header().Get("Content-Type"), "text/event-stream")
+	s.Contains(s.recorder.Body.String(), `"type":"error"`)
+}
+
+func (s *CodeInterpreterSuite) TestExecuteInContext_InvalidCodeEncoding() {
+	reqBody := models.ExecuteContextReq{Code: "not base64!", CodeEncoding: "base64"}
+	jsonBytes, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest("POST", "/contexts/ctx-1/execute", bytes.NewBuffer(jsonBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+	s.ctx.Params = gin.Params{{Key: "contextId", Value: "ctx-1"}}
+
+	s.handler.ExecuteInContext(s.ctx)
+
+	s.Contains(s.recorder.Header().Get("Content-Type"), "text/event-stream")
+	s.Contains(s.recorder.Body.String(), `invalid code encoding`)
+}
+
+func (s *CodeInterpreterSuite) TestExecuteInContext_ProxySuccess() {
+	reqBody := models.ExecuteContextReq{Code: "print(1)", TimeoutMs: 30000}
+	jsonBytes, _ := json.Marshal(reqBody)
+
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			s.Equal("session-1", sandboxID)
+			return &db.SandboxInfo{
+				SandboxID:    "session-1",
+				GrpcEndpoint: "sandbox.test:1883",
+			}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal(http.MethodPost, r.Method)
+		s.Equal("/api/contexts/ctx-1/execute", r.URL.Path)
+		s.Equal("text/event-stream", r.Header.Get("Accept"))
+		s.Equal("Bearer default.jwt.token", r.Header.Get("Authorization"))
+		body, err := io.ReadAll(r.Body)
+		s.NoError(err)
+		s.JSONEq(string(jsonBytes), string(body))
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body: io.NopCloser(strings.NewReader(
+				"data: {\"type\":\"init\",\"timestamp\":1,\"context_id\":\"ctx-1\"}\n\n" +
+					"data: {\"type\":\"stdout\",\"timestamp\":2,\"context_id\":\"ctx-1\",\"text\":\"1\\\\n\"}\n\n" +
+					"data: {\"type\":\"execution_complete\",\"timestamp\":3,\"context_id\":\"ctx-1\",\"execution_time\":5,\"exit_code\":0}\n\n",
+			)),
+		}
+		resp.Header.Set("Content-Type", "text/event-stream")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest("POST", "/contexts/ctx-1/execute", bytes.NewBuffer(jsonBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+	s.ctx.Params = gin.Params{{Key: "contextId", Value: "ctx-1"}}
+
+	s.handler.ExecuteInContext(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Equal("session-1", s.recorder.Header().Get("x-agentland-session"))
+	s.Contains(s.recorder.Header().Get("Content-Type"), "text/event-stream")
+	s.Contains(s.recorder.Body.String(), `"type":"execution_complete"`)
+}
+
+func (s *CodeInterpreterSuite) TestInstallPackages_ProxySuccess() {
+	reqBody := models.InstallPackagesReq{Packages: []string{"requests"}}
+	jsonBytes, _ := json.Marshal(reqBody)
+
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal(http.MethodPost, r.Method)
+		s.Equal("/api/contexts/ctx-1/install", r.URL.Path)
+		s.Equal("text/event-stream", r.Header.Get("Accept"))
+		body, err := io.ReadAll(r.Body)
+		s.NoError(err)
+		s.JSONEq(string(jsonBytes), string(body))
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body: io.NopCloser(strings.NewReader(
+				"data: {\"type\":\"install_complete\",\"context_id\":\"ctx-1\",\"packages\":[{\"name\":\"requests\",\"version\":\"2.32.3\"}]}\n\n",
+			)),
+		}
+		resp.Header.Set("Content-Type", "text/event-stream")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest("POST", "/contexts/ctx-1/install", bytes.NewBuffer(jsonBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+	s.ctx.Params = gin.Params{{Key: "contextId", Value: "ctx-1"}}
+
+	s.handler.InstallPackages(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), `"type":"install_complete"`)
+}
+
+func (s *CodeInterpreterSuite) TestInstallPackages_EmptyPackages() {
+	req := httptest.NewRequest("POST", "/contexts/ctx-1/install", strings.NewReader(`{"packages":[]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+	s.ctx.Params = gin.Params{{Key: "contextId", Value: "ctx-1"}}
+
+	s.handler.InstallPackages(s.ctx)
+
+	s.Contains(s.recorder.Body.String(), `"type":"error"`)
+	s.Contains(s.recorder.Body.String(), "packages is required")
+}
+
+func (s *CodeInterpreterSuite) TestExecuteBatch_ProxySuccess() {
+	reqBody := models.ExecuteBatchReq{
+		Cells:       []models.ExecuteBatchCell{{Code: "x = 41"}, {Code: "print(x + 1)", TimeoutMs: 1000}},
+		StopOnError: true,
+	}
+	jsonBytes, _ := json.Marshal(reqBody)
+
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal(http.MethodPost, r.Method)
+		s.Equal("/api/contexts/ctx-1/execute-batch", r.URL.Path)
+		body, err := io.ReadAll(r.Body)
+		s.NoError(err)
+		s.JSONEq(string(jsonBytes), string(body))
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"code":200,"msg":"success","data":{"context_id":"ctx-1","results":[],"stopped":false}}`)),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/contexts/ctx-1/execute-batch", bytes.NewBuffer(jsonBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+	s.ctx.Params = gin.Params{{Key: "contextId", Value: "ctx-1"}}
+
+	s.handler.ExecuteBatch(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), `"context_id":"ctx-1"`)
+}
+
+func (s *CodeInterpreterSuite) TestExecuteInContext_InjectsDefaultTimeout() {
+	s.handler.executeTimeout = newExecuteTimeoutPolicy(20*time.Second, time.Minute)
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	var forwarded []string
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(r.Body)
+		s.NoError(err)
+		forwarded = append(forwarded, string(body))
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader("data: {\"type\":\"execution_complete\",\"exit_code\":0}\n\n")),
+		}
+		resp.Header.Set("Content-Type", "text/event-stream")
+		return resp, nil
+	})
+
+	for _, body := range []string{
+		`{"code":"print(1)","return_vars":true}`,
+		`{"code":"print(1)","timeout_ms":5000}`,
+		`{"code":"print(1)","timeout_ms":120000}`,
+	} {
+		s.recorder = httptest.NewRecorder()
+		s.ctx, _ = gin.CreateTestContext(s.recorder)
+		req := httptest.NewRequest(http.MethodPost, "/contexts/ctx-1/execute", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(SessionHeader, "session-1")
+		s.ctx.Request = req
+		s.ctx.Params = gin.Params{{Key: "contextId", Value: "ctx-1"}}
+
+		s.handler.ExecuteInContext(s.ctx)
+		s.Equal(http.StatusOK, s.recorder.Code)
+	}
+
+	s.Require().Len(forwarded, 3)
+	// 未指定时注入默认值且保留其他字段，指定值在上限内时原样转发，超过上限时截断
+	s.JSONEq(`{"code":"print(1)","return_vars":true,"timeout_ms":20000}`, forwarded[0])
+	s.JSONEq(`{"code":"print(1)","timeout_ms":5000}`, forwarded[1])
+	s.JSONEq(`{"code":"print(1)","timeout_ms":60000}`, forwarded[2])
+}
+
+func (s *CodeInterpreterSuite) TestExecuteBatch_ClampsTimeoutToMax() {
+	s.handler.executeTimeout = newExecuteTimeoutPolicy(0, 10*time.Second)
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(r.Body)
+		s.NoError(err)
+		s.JSONEq(`{"cells":[{"code":"x = 41"},{"code":"print(x + 1)","timeout_ms":10000},{"code":"x","timeout_ms":500}],"stop_on_error":true}`, string(body))
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"code":200,"msg":"success","data":{"context_id":"ctx-1","results":[],"stopped":false}}`)),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/contexts/ctx-1/execute-batch", strings.NewReader(
+		`{"cells":[{"code":"x = 41"},{"code":"print(x + 1)","timeout_ms":300000},{"code":"x","timeout_ms":500}],"stop_on_error":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SessionHeader, "session-1")
+	s.ctx.Request = req
+	s.ctx.Params = gin.Params{{Key: "contextId", Value: "ctx-1"}}
+
+	s.handler.ExecuteBatch(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+}
+
+func (s *CodeInterpreterSuite) TestExecuteBatch_InvalidCells() {
+	req := httptest.NewRequest(http.MethodPost, "/contexts/ctx-1/execute-batch", strings.NewReader(`{"cells":[{"code":"print(1)","timeout_ms":99}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+	s.ctx.Params = gin.Params{{Key: "contextId", Value: "ctx-1"}}
+
+	s.handler.ExecuteBatch(s.ctx)
+
+	s.Equal(http.StatusBadRequest, s.recorder.Code)
+}
+
+func (s *CodeInterpreterSuite) TestListContexts_ProxySuccess() {
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			s.Equal("session-1", sandboxID)
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal(http.MethodGet, r.Method)
+		s.Equal("/api/contexts", r.URL.Path)
+		s.Equal("Bearer default.jwt.token", r.Header.Get("Authorization"))
+		s.Equal("session-1", r.Header.Get("x-agentland-session"))
+
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"contexts":[{"context_id":"ctx-1","busy":false}]}`)),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/contexts", nil)
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.ListContexts(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), `"context_id":"ctx-1"`)
+}
+
+func (s *CodeInterpreterSuite) TestInterruptContext_ProxySuccess() {
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal(http.MethodPost, r.Method)
+		s.Equal("/api/contexts/ctx-1/interrupt", r.URL.Path)
+		s.Equal("Bearer default.jwt.token", r.Header.Get("Authorization"))
+
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"context_id":"ctx-1","interrupted":true}`)),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/contexts/ctx-1/interrupt", nil)
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+	s.ctx.Params = gin.Params{{Key: "contextId", Value: "ctx-1"}}
+
+	s.handler.InterruptContext(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), `"interrupted":true`)
+}
+
+func (s *CodeInterpreterSuite) TestResetContext_ProxyForwardsForceQuery() {
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal(http.MethodPost, r.Method)
+		s.Equal("/api/contexts/ctx-1/reset", r.URL.Path)
+		s.Equal("force=true", r.URL.RawQuery)
+
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"context_id":"ctx-1","execution_count":0}`)),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/contexts/ctx-1/reset?force=true", nil)
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+	s.ctx.Params = gin.Params{{Key: "contextId", Value: "ctx-1"}}
+
+	s.handler.ResetContext(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), `"context_id":"ctx-1"`)
+}
+
+func (s *CodeInterpreterSuite) TestGetContextHistory_ProxySuccess() {
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal(http.MethodGet, r.Method)
+		s.Equal("/api/contexts/ctx-1/history", r.URL.Path)
+
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"context_id":"ctx-1","entries":[{"code_hash":"abc","exit_code":0}]}`)),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/contexts/ctx-1/history", nil)
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+	s.ctx.Params = gin.Params{{Key: "contextId", Value: "ctx-1"}}
+
+	s.handler.GetContextHistory(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), `"code_hash":"abc"`)
+}
+
+func (s *CodeInterpreterSuite) TestGetFSTree_ProxySuccess() {
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			s.Equal("session-1", sandboxID)
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal(http.MethodGet, r.Method)
+		s.Equal("sandbox.test:1883", r.URL.Host)
+		s.Equal("/api/fs/tree", r.URL.Path)
+		s.Equal("path=src&depth=2", r.URL.RawQuery)
+		s.Equal("Bearer default.jwt.token", r.Header.Get("Authorization"))
+		s.Equal("session-1", r.Header.Get("x-agentland-session"))
+
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"root":"src","nodes":[]}`)),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/fs/tree?path=src&depth=2", nil)
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.GetFSTree(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Equal("session-1", s.recorder.Header().Get("x-agentland-session"))
+	s.JSONEq(`{"root":"src","nodes":[]}`, s.recorder.Body.String())
+}
+
+func (s *CodeInterpreterSuite) TestProxyFS_ForwardsUnknownSubPathWithBody() {
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal(http.MethodDelete, r.Method)
+		s.Equal("/api/fs/trash", r.URL.Path)
+		s.Equal("path=a.txt&recursive=true", r.URL.RawQuery)
+		s.Equal("Bearer default.jwt.token", r.Header.Get("Authorization"))
+		s.Equal("text/plain", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		s.NoError(err)
+		s.Equal("keep-versions", string(body))
+
+		return &http.Response{
+			StatusCode: http.StatusNoContent,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader("")),
+		}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/fs/trash/?path=a.txt&recursive=true", strings.NewReader("keep-versions"))
+	req.Header.Set("x-agentland-session", "session-1")
+	req.Header.Set("Content-Type", "text/plain")
+	s.ctx.Request = req
+	s.ctx.Params = gin.Params{{Key: "path", Value: "/trash/"}}
+
+	s.handler.ProxyFS(s.ctx)
+
+	s.Equal(http.StatusNoContent, s.ctx.Writer.Status())
+}
+
+func (s *CodeInterpreterSuite) TestProxyFS_DispatchesKnownRouteValidation() {
+	req := httptest.NewRequest(http.MethodDelete, "/fs/file", nil)
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+	s.ctx.Params = gin.Params{{Key: "path", Value: "/file"}}
+
+	s.handler.ProxyFS(s.ctx)
+
+	// DELETE /fs/file 仍要求 path 参数，未转发到沙箱
+	s.Equal(http.StatusBadRequest, s.recorder.Code)
+}
+
+func (s *CodeInterpreterSuite) TestGetFSTree_SessionNotFound() {
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return nil, db.ErrSessionNotFound
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/fs/tree", nil)
+	req.Header.Set("x-agentland-session", "missing")
+	s.ctx.Request = req
+	misses := promtestutil.ToFloat64(metrics.SessionLookups.WithLabelValues(metrics.SessionLookupMiss))
+
+	s.handler.GetFSTree(s.ctx)
+
+	s.Equal(http.StatusNotFound, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), "session not found")
+	s.Equal(misses+1, promtestutil.ToFloat64(metrics.SessionLookups.WithLabelValues(metrics.SessionLookupMiss)))
+}
+
+func (s *CodeInterpreterSuite) TestStatFS_ProxySuccess() {
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal(http.MethodGet, r.Method)
+		s.Equal("/api/fs/stat", r.URL.Path)
+		s.Equal("path=data.csv", r.URL.RawQuery)
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"code":200,"msg":"success","data":{"path":"data.csv","name":"data.csv","size":2147483648,"mode":"-rw-r--r--","perm":"0644","modTime":"2024-05-01T08:30:00Z","isDir":false,"isSymlink":false,"mimeType":"text/csv; charset=utf-8"}}`)),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/fs/stat?path=data.csv", nil)
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.StatFS(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), `"size":2147483648`)
+}
+
+func (s *CodeInterpreterSuite) TestTailFSFile_FollowStreamsEvents() {
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal("/api/fs/tail", r.URL.Path)
+		s.Equal("app.log", r.URL.Query().Get("path"))
+		s.Equal("true", r.URL.Query().Get("follow"))
+		s.Equal("text/event-stream", r.Header.Get("Accept"))
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader("data: {\"type\":\"lines\",\"lines\":[\"ready\"],\"offset\":6}\n\n")),
+		}
+		resp.Header.Set("Content-Type", "text/event-stream")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/fs/tail?path=app.log&follow=true", nil)
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.TailFSFile(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Equal("text/event-stream", s.recorder.Header().Get("Content-Type"))
+	s.Contains(s.recorder.Body.String(), `"lines":["ready"]`)
+}
+
+func (s *CodeInterpreterSuite) TestTailFSFile_RequiresPath() {
+	req := httptest.NewRequest(http.MethodGet, "/fs/tail?lines=5", nil)
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.TailFSFile(s.ctx)
+
+	s.Equal(http.StatusBadRequest, s.recorder.Code)
+}
+
+func (s *CodeInterpreterSuite) TestGetFSFile_ForwardsLineRange() {
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal("/api/fs/file", r.URL.Path)
+		s.Equal("500", r.URL.Query().Get("start_line"))
+		s.Equal("520", r.URL.Query().Get("end_line"))
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"code":200,"msg":"success","data":{"path":"big.py","size":4096,"encoding":"utf8","content":"x = 1\n","content_type":"text/x-python","total_lines":10000}}`)),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/fs/file?path=big.py&start_line=500&end_line=520", nil)
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.GetFSFile(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), `"total_lines":10000`)
+}
+
+func (s *CodeInterpreterSuite) TestGetFSFile_PassesThroughNotModified() {
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal("/api/fs/file", r.URL.Path)
+		s.Equal(`"17c-2"`, r.Header.Get("If-None-Match"))
+		resp := &http.Response{
+			StatusCode: http.StatusNotModified,
+			Header:     make(http.Header),
+			Body:       http.NoBody,
+		}
+		resp.Header.Set("ETag", `"17c-2"`)
+		return resp, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/fs/file?path=notes.txt", nil)
+	req.Header.Set("x-agentland-session", "session-1")
+	req.Header.Set("If-None-Match", `"17c-2"`)
+	s.ctx.Request = req
+
+	s.handler.GetFSFile(s.ctx)
+
+	s.Equal(http.StatusNotModified, s.ctx.Writer.Status())
+	s.Equal(`"17c-2"`, s.recorder.Header().Get("ETag"))
+	s.Empty(s.recorder.Body.String())
+}
+
+func (s *CodeInterpreterSuite) TestStatFS_MissingPath() {
+	req := httptest.NewRequest(http.MethodGet, "/fs/stat", nil)
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.StatFS(s.ctx)
+
+	s.Equal(http.StatusBadRequest, s.recorder.Code)
+}
+
+func (s *CodeInterpreterSuite) TestSearchFS_ProxySuccess() {
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal(http.MethodGet, r.Method)
+		s.Equal("/api/fs/search", r.URL.Path)
+		s.Equal("q=TODO&glob=%2A.py", r.URL.RawQuery)
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"code":200,"msg":"success","data":{"root":".","matches":[{"path":"main.py","line":3,"text":"# TODO"}],"scannedFiles":1,"scannedBytes":20,"truncated":false}}`)),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/fs/search?q=TODO&glob=%2A.py", nil)
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.SearchFS(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), `"main.py"`)
+}
+
+func (s *CodeInterpreterSuite) TestSearchFS_MissingQuery() {
+	req := httptest.NewRequest(http.MethodGet, "/fs/search?path=src", nil)
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.SearchFS(s.ctx)
+
+	s.Equal(http.StatusBadRequest, s.recorder.Code)
+}
+
+func (s *CodeInterpreterSuite) TestWriteFSFile_ProxySuccess() {
 	reqBody := models.WriteFSFileReq{
 		Path:     "/home/user/data.txt",
 		Content:  "这是测试数据\n第二行数据",
@@ -463,6 +1685,171 @@ func (s *CodeInterpreterSuite) TestWriteFSFile_ProxySuccess() {
 	s.Contains(s.recorder.Body.String(), `"/home/user/data.txt"`)
 }
 
+func (s *CodeInterpreterSuite) TestDeleteFSFile_ProxySuccess() {
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal(http.MethodDelete, r.Method)
+		s.Equal("/api/fs/file", r.URL.Path)
+		s.Equal("path=build&recursive=true", r.URL.RawQuery)
+		s.Equal("Bearer default.jwt.token", r.Header.Get("Authorization"))
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"code":200,"msg":"success","data":{"path":"build","type":"dir"}}`)),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/fs/file?path=build&recursive=true", nil)
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.DeleteFSFile(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), `"type":"dir"`)
+}
+
+func (s *CodeInterpreterSuite) TestDeleteFSFile_MissingPath() {
+	req := httptest.NewRequest(http.MethodDelete, "/fs/file", nil)
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.DeleteFSFile(s.ctx)
+
+	s.Equal(http.StatusBadRequest, s.recorder.Code)
+}
+
+func (s *CodeInterpreterSuite) TestMkdirFS_ProxySuccess() {
+	jsonBytes, _ := json.Marshal(models.MkdirFSReq{Path: "out/reports"})
+
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal(http.MethodPost, r.Method)
+		s.Equal("/api/fs/mkdir", r.URL.Path)
+		body, err := io.ReadAll(r.Body)
+		s.NoError(err)
+		s.JSONEq(string(jsonBytes), string(body))
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"code":200,"msg":"success","data":{"path":"out/reports"}}`)),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/fs/mkdir", bytes.NewBuffer(jsonBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.MkdirFS(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), `"out/reports"`)
+}
+
+func (s *CodeInterpreterSuite) TestCopyFS_ProxySuccess() {
+	jsonBytes, _ := json.Marshal(models.CopyFSReq{Src: "data", Dst: "backup/data"})
+
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal(http.MethodPost, r.Method)
+		s.Equal("/api/fs/copy", r.URL.Path)
+		body, err := io.ReadAll(r.Body)
+		s.NoError(err)
+		s.JSONEq(string(jsonBytes), string(body))
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"code":200,"msg":"success","data":{"src":"data","dst":"backup/data","bytes":42}}`)),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/fs/copy", bytes.NewBuffer(jsonBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.CopyFS(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), `"bytes":42`)
+}
+
+func (s *CodeInterpreterSuite) TestMoveFS_MissingDst() {
+	jsonBytes, _ := json.Marshal(models.MoveFSReq{Src: "a.txt"})
+
+	req := httptest.NewRequest(http.MethodPost, "/fs/move", bytes.NewBuffer(jsonBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.MoveFS(s.ctx)
+
+	s.Equal(http.StatusBadRequest, s.recorder.Code)
+}
+
+func (s *CodeInterpreterSuite) TestUploadFSFile_RejectsOversizedBody() {
+	s.handler.maxUploadBytes = 16
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Fail("oversized upload must not reach the sandbox")
+		return nil, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/fs/upload", strings.NewReader(strings.Repeat("x", 64)))
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=abc")
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.UploadFSFile(s.ctx)
+
+	s.Equal(http.StatusRequestEntityTooLarge, s.recorder.Code)
+	s.JSONEq(`{"error":"request body exceeds limit 16 bytes"}`, s.recorder.Body.String())
+}
+
+func (s *CodeInterpreterSuite) TestWriteFSFile_RejectsOversizedChunkedBody() {
+	s.handler.maxUploadBytes = 16
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Fail("oversized write must not reach the sandbox")
+		return nil, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/fs/file", strings.NewReader(`{"path":"a.txt","content":"`+strings.Repeat("x", 64)+`"}`))
+	// 未声明长度的请求体在读取时才触发上限
+	req.ContentLength = -1
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.WriteFSFile(s.ctx)
+
+	s.Equal(http.StatusRequestEntityTooLarge, s.recorder.Code)
+}
+
 func (s *CodeInterpreterSuite) TestUploadFSFile_ProxySuccess() {
 	var reqBody bytes.Buffer
 	writer := multipart.NewWriter(&reqBody)
@@ -511,6 +1898,60 @@ func (s *CodeInterpreterSuite) TestUploadFSFile_ProxySuccess() {
 	s.Contains(s.recorder.Body.String(), `"/workspace/dataset.csv"`)
 }
 
+func (s *CodeInterpreterSuite) TestExtractFS_ProxySuccess() {
+	var reqBody bytes.Buffer
+	writer := multipart.NewWriter(&reqBody)
+	part, err := writer.CreateFormFile("file", "project.zip")
+	s.NoError(err)
+	_, err = part.Write([]byte("PK-archive"))
+	s.NoError(err)
+	s.NoError(writer.WriteField("target_dir", "/workspace/project"))
+	s.NoError(writer.Close())
+
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal(http.MethodPost, r.Method)
+		s.Equal("/api/fs/extract", r.URL.Path)
+		s.True(strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data"))
+		body, readErr := io.ReadAll(r.Body)
+		s.NoError(readErr)
+		s.Contains(string(body), "PK-archive")
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"code":200,"msg":"success","data":{"target_dir":"/workspace/project","format":"zip","files":3,"bytes":42}}`)),
+		}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/fs/extract", &reqBody)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.ExtractFS(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), `"files":3`)
+}
+
+func (s *CodeInterpreterSuite) TestExtractFS_RejectJSONBody() {
+	req := httptest.NewRequest(http.MethodPost, "/fs/extract", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.ExtractFS(s.ctx)
+
+	s.Equal(http.StatusBadRequest, s.recorder.Code)
+}
+
 func (s *CodeInterpreterSuite) TestDownloadFSFile_ProxySuccess() {
 	path := "/workspace/result.csv"
 	queryPath := url.QueryEscape(path)
@@ -548,3 +1989,78 @@ func (s *CodeInterpreterSuite) TestDownloadFSFile_ProxySuccess() {
 	s.Equal("id,score\n1,100\n", s.recorder.Body.String())
 	s.Contains(s.recorder.Header().Get("Content-Disposition"), "result.csv")
 }
+
+func (s *CodeInterpreterSuite) TestArchiveFS_ProxySuccess() {
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal(http.MethodGet, r.Method)
+		s.Equal("/api/fs/archive", r.URL.Path)
+		s.Equal("path=project&format=targz", r.URL.RawQuery)
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader("archive-bytes")),
+		}
+		resp.Header.Set("Content-Type", "application/gzip")
+		resp.Header.Set("Content-Disposition", "attachment; filename=\"project.tar.gz\"")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/fs/archive?path=project&format=targz", nil)
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.ArchiveFS(s.ctx)
+
+	s.Equal(http.StatusOK, s.recorder.Code)
+	s.Equal("archive-bytes", s.recorder.Body.String())
+	s.Contains(s.recorder.Header().Get("Content-Disposition"), "project.tar.gz")
+}
+
+func (s *CodeInterpreterSuite) TestArchiveFS_MissingPath() {
+	req := httptest.NewRequest(http.MethodGet, "/fs/archive", nil)
+	req.Header.Set("x-agentland-session", "session-1")
+	s.ctx.Request = req
+
+	s.handler.ArchiveFS(s.ctx)
+
+	s.Equal(http.StatusBadRequest, s.recorder.Code)
+}
+
+func (s *CodeInterpreterSuite) TestDownloadFSFile_ForwardsRange() {
+	s.handler.sessionStore = &mockSessionStore{
+		getSessionFn: func(ctx context.Context, sandboxID string) (*db.SandboxInfo, error) {
+			return &db.SandboxInfo{SandboxID: "session-1", GrpcEndpoint: "sandbox.test:1883"}, nil
+		},
+	}
+
+	s.handler.proxyEngine.Transport = RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+		s.Equal("/api/fs/download", r.URL.Path)
+		s.Equal("bytes=4-7", r.Header.Get("Range"))
+		resp := &http.Response{
+			StatusCode: http.StatusPartialContent,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader("core")),
+		}
+		resp.Header.Set("Content-Type", "application/octet-stream")
+		resp.Header.Set("Content-Range", "bytes 4-7/15")
+		resp.Header.Set("Accept-Ranges", "bytes")
+		return resp, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/fs/download?path=result.csv", nil)
+	req.Header.Set("x-agentland-session", "session-1")
+	req.Header.Set("Range", "bytes=4-7")
+	s.ctx.Request = req
+
+	s.handler.DownloadFSFile(s.ctx)
+
+	s.Equal(http.StatusPartialContent, s.recorder.Code)
+	s.Equal("bytes 4-7/15", s.recorder.Header().Get("Content-Range"))
+	s.Equal("core", s.recorder.Body.String())
+}
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
@@ -16,7 +18,9 @@ import (
 	"github.com/Fl0rencess720/agentland/pkg/common/observability"
 	"github.com/Fl0rencess720/agentland/pkg/common/utils"
 	"github.com/Fl0rencess720/agentland/pkg/gateway/config"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/middleware"
 	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/db"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/metrics"
 	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
@@ -24,19 +28,24 @@ import (
 	"go.opentelemetry.io/otel/propagation"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
+	grpcstatus "google.golang.org/grpc/status"
 )
 
 const (
-	SessionHeader  = "x-agentland-session"
-	LanguagePython = "python"
-	LanguageBash   = "bash"
+	SessionHeader        = "x-agentland-session"
+	OwnerHeader          = "x-agentland-owner"
+	IdempotencyKeyHeader = "x-agentland-idempotency-key"
+	LanguagePython       = "python"
+	LanguageBash         = "bash"
+	LanguageNode         = "node"
 )
 
 func isSupportedCodeLanguage(language string) bool {
 	switch strings.ToLower(strings.TrimSpace(language)) {
-	case LanguagePython, LanguageBash:
+	case LanguagePython, LanguageBash, LanguageNode:
 		return true
 	default:
 		return false
@@ -46,6 +55,10 @@ func isSupportedCodeLanguage(language string) bool {
 type SessionStore interface {
 	GetSession(ctx context.Context, sandboxID string) (*db.SandboxInfo, error)
 	UpdateLatestActivity(ctx context.Context, sandboxID string) error
+	MinTokenVersion(ctx context.Context, sandboxID string) (int64, error)
+	BumpTokenVersion(ctx context.Context, sandboxID string) (int64, error)
+	ListSessions(ctx context.Context, owner, prefix string, cursor uint64, limit int64) ([]*db.SandboxInfo, uint64, error)
+	UpdateEndpoint(ctx context.Context, sandboxID, endpoint string) error
 }
 
 type TokenSigner interface {
@@ -54,6 +67,8 @@ type TokenSigner interface {
 
 type ProxyEngine struct {
 	Transport http.RoundTripper
+	// Inflight 非空时登记每次转发，停机时据此等待在途请求
+	Inflight *InflightTracker
 }
 
 type ProxyConfig struct {
@@ -64,24 +79,71 @@ type ProxyConfig struct {
 	SessionID    string
 	SandboxToken string
 	RequestID    string
+	// Refresh 非空时，连接沙箱失败后调用一次以重新解析沙箱地址并重试；仅对可安全重放的请求生效
+	Refresh func(ctx context.Context) (*url.URL, error)
 }
 
-func NewProxyEngine() *ProxyEngine {
+// errEndpointUnchanged 表示重新解析得到的沙箱地址与失败的地址相同，重试没有意义
+var errEndpointUnchanged = errors.New("sandbox endpoint unchanged")
+
+// errOwnerMismatch 表示请求方身份与会话 owner 不一致
+var errOwnerMismatch = errors.New("session owner mismatch")
+
+// sandboxSubjectKey 为转发时签入 token 的调用方身份在 gin.Context 中的键，供网关侧审计记录读取
+const sandboxSubjectKey = "sandboxSubject"
+
+// NewProxyEngine 使用调用方注入的 Transport 创建代理，多个 Handler 传入同一个 Transport 以共享连接池
+func NewProxyEngine(transport http.RoundTripper) *ProxyEngine {
 	return &ProxyEngine{
-		Transport: &http.Transport{
-			MaxIdleConns:        200,
-			MaxIdleConnsPerHost: 10,
-			IdleConnTimeout:     90 * time.Second,
-		},
+		Transport: transport,
+		Inflight:  proxyInflight,
 	}
 }
 
-// Forward 执行 HTTP 代理、Header 注入及 Body 恢复
+// Forward 执行 HTTP 代理、Header 注入及 Body 恢复；沙箱 Pod 重建导致缓存地址失效时，按 cfg.Refresh 刷新地址后重试一次
 func (e *ProxyEngine) Forward(ctx *gin.Context, cfg ProxyConfig) {
+	if e.Inflight != nil {
+		defer e.Inflight.Track()()
+	}
+
+	start := time.Now()
+	retryable := cfg.Refresh != nil && isReplayableRequest(ctx.Request, cfg)
+	if e.serve(ctx, cfg, retryable) {
+		target, err := cfg.Refresh(ctx.Request.Context())
+		if err != nil {
+			zap.L().Warn(
+				"Refresh sandbox endpoint failed",
+				zap.String("target", cfg.Target.String()),
+				zap.String("session_id", cfg.SessionID),
+				zap.String("request_id", cfg.RequestID),
+				zap.Error(err),
+			)
+			http.Error(ctx.Writer, "sandbox unreachable", http.StatusBadGateway)
+		} else {
+			zap.L().Info(
+				"Retry proxy request with refreshed sandbox endpoint",
+				zap.String("stale_target", cfg.Target.String()),
+				zap.String("target", target.String()),
+				zap.String("session_id", cfg.SessionID),
+				zap.String("request_id", cfg.RequestID),
+			)
+			cfg.Target = target
+			e.serve(ctx, cfg, false)
+		}
+	}
+	metrics.ObserveProxy(ctx.FullPath(), ctx.Writer.Status(), start)
+}
+
+// serve 执行一次反向代理；retryable 为 true 且连接沙箱失败时不写响应，返回 true 交由调用方刷新地址后重试
+func (e *ProxyEngine) serve(ctx *gin.Context, cfg ProxyConfig, retryable bool) (dialFailed bool) {
 	proxy := httputil.NewSingleHostReverseProxy(cfg.Target)
 	proxy.Transport = e.Transport
 	// Ensure streaming responses (SSE/chunked) are flushed to the client promptly.
 	proxy.FlushInterval = 100 * time.Millisecond
+	if isStreamingRequest(ctx.Request) {
+		// 流式请求逐次写出即刷新，避免 token 在代理中积攒
+		proxy.FlushInterval = -1
+	}
 
 	originalDirector := proxy.Director
 	proxy.Director = func(req *http.Request) {
@@ -95,6 +157,8 @@ func (e *ProxyEngine) Forward(ctx *gin.Context, cfg ProxyConfig) {
 		req.Header.Del("Authorization")
 		req.Header.Del(SessionHeader)
 		req.Header.Del("X-Agentland-Session")
+		// 客户端 API Key 仅用于网关鉴权，不能泄露给沙箱内运行的代码
+		req.Header.Del(middleware.APIKeyHeader)
 
 		if cfg.SandboxToken != "" {
 			req.Header.Set("Authorization", "Bearer "+cfg.SandboxToken)
@@ -124,15 +188,44 @@ func (e *ProxyEngine) Forward(ctx *gin.Context, cfg ProxyConfig) {
 		if cfg.SessionID != "" {
 			resp.Header.Set(SessionHeader, cfg.SessionID)
 		}
+		// 网关已写入跨域响应头时丢弃沙箱返回的跨域头，避免重复的 Allow-Origin 导致浏览器拒绝响应
+		if ctx.Writer.Header().Get("Access-Control-Allow-Origin") != "" {
+			for key := range resp.Header {
+				if strings.HasPrefix(key, "Access-Control-") {
+					resp.Header.Del(key)
+				}
+			}
+		}
 		// Avoid buffering SSE responses in common proxies.
-		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Type"))), "text/event-stream") {
+		if isEventStream(resp.Header.Get("Content-Type")) {
 			resp.Header.Set("Cache-Control", "no-cache")
 			resp.Header.Set("X-Accel-Buffering", "no")
+			resp.Header.Del("Content-Length")
 		}
 		return nil
 	}
 
 	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeBodyTooLarge(ctx, maxErr.Limit)
+			return
+		}
+		if retryable && isDialError(err) {
+			dialFailed = true
+			return
+		}
+		if isTimeoutError(err) {
+			zap.L().Warn(
+				"Reverse proxy request timed out",
+				zap.String("target", cfg.Target.String()),
+				zap.String("session_id", cfg.SessionID),
+				zap.String("request_id", cfg.RequestID),
+				zap.Error(err),
+			)
+			http.Error(w, "sandbox timeout", http.StatusGatewayTimeout)
+			return
+		}
 		zap.L().Error(
 			"Reverse proxy request failed",
 			zap.String("target", cfg.Target.String()),
@@ -144,6 +237,58 @@ func (e *ProxyEngine) Forward(ctx *gin.Context, cfg ProxyConfig) {
 	}
 
 	proxy.ServeHTTP(closeNotifySafeWriter{ResponseWriter: ctx.Writer}, ctx.Request)
+	return dialFailed
+}
+
+// isReplayableRequest 判断请求能否在连接失败后重放：请求体已缓冲，或为不带请求体的安全方法
+func isReplayableRequest(r *http.Request, cfg ProxyConfig) bool {
+	if cfg.Body != nil {
+		return true
+	}
+	switch cfg.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return r.ContentLength == 0
+	default:
+		return false
+	}
+}
+
+// isDialError 判断代理错误是否发生在与沙箱建立连接阶段，此时请求尚未发出
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// sandboxRefresher 构造 ProxyConfig.Refresh：通过 resolve 获取会话当前的沙箱地址，地址变化时写回 session store。
+// 写回失败不影响本次重试，下次请求会再次触发刷新
+func sandboxRefresher(store SessionStore, sessionID, staleEndpoint string, resolve func(ctx context.Context) (string, error)) func(context.Context) (*url.URL, error) {
+	return func(ctx context.Context) (*url.URL, error) {
+		endpoint, err := resolve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		endpoint = strings.TrimSpace(endpoint)
+		if endpoint == "" || endpoint == strings.TrimSpace(staleEndpoint) {
+			return nil, errEndpointUnchanged
+		}
+		target, err := resolveSandboxTarget(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		if err := store.UpdateEndpoint(ctx, sessionID, endpoint); err != nil {
+			zap.L().Warn("Update sandbox endpoint failed", zap.String("sessionID", sessionID), zap.Error(err))
+		}
+		return target, nil
+	}
+}
+
+// isTimeoutError 判断代理错误是否为连接沙箱或等待响应头超时，客户端主动断开不算超时
+func isTimeoutError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
 }
 
 func BuildAgentCoreClient(address string) (pb.AgentCoreServiceClient, error) {
@@ -172,9 +317,72 @@ func BuildTokenSigner(cfg *config.Config) (TokenSigner, error) {
 		Audience:       cfg.SandboxJWTAudience,
 		KID:            cfg.SandboxJWTKID,
 		TTL:            cfg.SandboxJWTTTL,
+		Algorithm:      cfg.SandboxJWTAlgorithm,
+
+		KeyReloadInterval: cfg.SandboxJWTKeyRefreshInterval,
 	})
 }
 
+// issueSandboxToken 以会话当前的最小版本签发 token，使吊销前签发的 token 在沙箱侧失效；
+// subject 写入 sub claim，供沙箱侧将操作与审计记录归属到具体调用方。
+func issueSandboxToken(ctx context.Context, store SessionStore, signer TokenSigner, sessionID, subject string) (string, error) {
+	version, err := store.MinTokenVersion(ctx, sessionID)
+	if err != nil {
+		metrics.SandboxTokenErrors.Inc()
+		return "", fmt.Errorf("get token version failed: %w", err)
+	}
+	token, err := signer.Sign(sessionID, subject, version)
+	if err != nil {
+		metrics.SandboxTokenErrors.Inc()
+		return "", err
+	}
+	return token, nil
+}
+
+// getSession 查询会话信息并记录 hit/miss/error 指标
+// resolveOwner 读取请求方身份，用于按 owner 统计并发会话配额；
+// 网关开启鉴权时以鉴权得到的调用方身份为准，忽略客户端自报的 owner header
+func resolveOwner(ctx *gin.Context) string {
+	if principal := ctx.GetString(middleware.AuthPrincipalKey); principal != "" {
+		return principal
+	}
+	return strings.TrimSpace(ctx.GetHeader(OwnerHeader))
+}
+
+// resolveSandboxSubject 确定签入沙箱 token 的调用方身份：会话记录了 owner 时请求方身份必须与之一致，
+// 未携带身份的请求同样视为不一致；会话无 owner 时使用请求方身份（可为空）。
+// 身份不一致时返回 errOwnerMismatch
+func resolveSandboxSubject(ctx *gin.Context, info *db.SandboxInfo) (string, error) {
+	subject := resolveOwner(ctx)
+	if info == nil || info.Owner == "" {
+		return subject, nil
+	}
+	if subject != info.Owner {
+		return "", errOwnerMismatch
+	}
+	return info.Owner, nil
+}
+
+// writeCreateSessionError 将 agentcore 创建会话返回的业务错误映射为对应 HTTP 状态，未识别的错误返回 false 交由调用方处理
+func writeCreateSessionError(ctx *gin.Context, err error) bool {
+	switch grpcstatus.Code(err) {
+	case grpccodes.ResourceExhausted:
+		ctx.JSON(http.StatusTooManyRequests, gin.H{"error": "session quota exceeded"})
+		return true
+	case grpccodes.InvalidArgument:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid owner"})
+		return true
+	default:
+		return false
+	}
+}
+
+func getSession(ctx context.Context, store SessionStore, sessionID string) (*db.SandboxInfo, error) {
+	info, err := store.GetSession(ctx, sessionID)
+	metrics.ObserveSessionLookup(err)
+	return info, err
+}
+
 func resolveSandboxTarget(endpoint string) (*url.URL, error) {
 	trimmed := strings.TrimSpace(endpoint)
 	if trimmed == "" {
@@ -193,9 +401,34 @@ func initRequestContext(ctx *gin.Context) (context.Context, string) {
 	return reqCtx, requestID
 }
 
+// limitRequestBody 限制请求体不超过 maxBytes：声明的 Content-Length 已超限时直接返回 413，
+// 否则以 http.MaxBytesReader 包装请求体，读取或转发途中超限时同样返回 413。maxBytes<=0 时不限制
+func limitRequestBody(ctx *gin.Context, maxBytes int64) bool {
+	if maxBytes <= 0 {
+		return true
+	}
+	if ctx.Request.ContentLength > maxBytes {
+		writeBodyTooLarge(ctx, maxBytes)
+		return false
+	}
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBytes)
+	return true
+}
+
+func writeBodyTooLarge(ctx *gin.Context, maxBytes int64) {
+	ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{
+		"error": fmt.Sprintf("request body exceeds limit %d bytes", maxBytes),
+	})
+}
+
 func readRequestBody(ctx *gin.Context) ([]byte, bool) {
 	bodyBytes, err := io.ReadAll(ctx.Request.Body)
 	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeBodyTooLarge(ctx, maxErr.Limit)
+			return nil, false
+		}
 		zap.L().Error("Read request body failed", zap.Error(err))
 		response.ErrorResponse(ctx, response.FormError)
 		return nil, false
@@ -203,6 +436,49 @@ func readRequestBody(ctx *gin.Context) ([]byte, bool) {
 	return bodyBytes, true
 }
 
+// readProxyRequestBody 读取需要透传的请求体；WebSocket 升级与 SSE 等流式请求不做缓冲，
+// 返回 nil 使代理直接转发原始请求体。
+func readProxyRequestBody(ctx *gin.Context) ([]byte, bool) {
+	if isStreamingRequest(ctx.Request) {
+		return nil, true
+	}
+	return readRequestBody(ctx)
+}
+
+// isStreamingRequest 判断请求是否为 WebSocket 升级或期望 text/event-stream 响应的流式请求
+func isStreamingRequest(r *http.Request) bool {
+	if r == nil {
+		return false
+	}
+	if strings.EqualFold(strings.TrimSpace(r.Header.Get("Upgrade")), "websocket") &&
+		headerContainsToken(r.Header, "Connection", "upgrade") {
+		return true
+	}
+	for _, accept := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(accept, ",") {
+			if isEventStream(part) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isEventStream(contentType string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(contentType)), "text/event-stream")
+}
+
+func headerContainsToken(h http.Header, key, token string) bool {
+	for _, v := range h.Values(key) {
+		for _, part := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func bindJSONWithBody(ctx *gin.Context, obj interface{}) ([]byte, bool) {
 	bodyBytes, ok := readRequestBody(ctx)
 	if !ok {
@@ -223,3 +499,8 @@ type closeNotifySafeWriter struct {
 func (w closeNotifySafeWriter) CloseNotify() <-chan bool {
 	return nil
 }
+
+// Unwrap 供 http.ResponseController 找到底层的 Flusher/Hijacker，保证流式刷新与 WebSocket 升级可用
+func (w closeNotifySafeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -175,6 +178,223 @@ func (s *CommonSuite) TestProxyEngineForward() {
 	s.Equal("session-1", s.recorder.Header().Get(SessionHeader))
 }
 
+func (s *CommonSuite) TestProxyEngineForwardResponseHeaderTimeout() {
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 模拟卡死的沙箱：接受连接但始终不返回响应头
+		<-release
+	}))
+	defer upstream.Close()
+	defer close(release)
+
+	transport, err := NewSandboxTransport(&config.Config{SandboxHTTPResponseHeaderTimeout: 100 * time.Millisecond})
+	s.Require().NoError(err)
+	defer transport.CloseIdleConnections()
+	target, err := url.Parse(upstream.URL)
+	s.Require().NoError(err)
+
+	s.ctx.Request = httptest.NewRequest(http.MethodGet, "/from-gw", nil)
+	start := time.Now()
+	NewProxyEngine(transport).Forward(s.ctx, ProxyConfig{
+		Target:       target,
+		Method:       http.MethodGet,
+		InternalPath: "/api/fs/tree",
+		SessionID:    "session-1",
+	})
+
+	s.Less(time.Since(start), 2*time.Second)
+	s.Equal(http.StatusGatewayTimeout, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), "sandbox timeout")
+}
+
+func (s *CommonSuite) TestProxyEngineForwardUnreachable() {
+	engine := &ProxyEngine{
+		Transport: commonRoundTripFunc(func(r *http.Request) (*http.Response, error) {
+			return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+		}),
+	}
+	target, err := url.Parse("http://sandbox.test:1883")
+	s.Require().NoError(err)
+
+	s.ctx.Request = httptest.NewRequest(http.MethodGet, "/from-gw", nil)
+	engine.Forward(s.ctx, ProxyConfig{Target: target, Method: http.MethodGet, InternalPath: "/api/fs/tree"})
+
+	s.Equal(http.StatusBadGateway, s.recorder.Code)
+}
+
+func (s *CommonSuite) TestProxyEngineForwardSkipsRefreshForUnbufferedBody() {
+	attempts := 0
+	engine := &ProxyEngine{
+		Transport: commonRoundTripFunc(func(r *http.Request) (*http.Response, error) {
+			attempts++
+			return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+		}),
+	}
+	target, err := url.Parse("http://sandbox.test:1883")
+	s.Require().NoError(err)
+
+	refreshed := 0
+	s.ctx.Request = httptest.NewRequest(http.MethodPost, "/from-gw", strings.NewReader("streamed body"))
+	engine.Forward(s.ctx, ProxyConfig{
+		Target:       target,
+		Method:       http.MethodPost,
+		InternalPath: "/api/fs/upload",
+		Refresh: func(ctx context.Context) (*url.URL, error) {
+			refreshed++
+			return url.Parse("http://sandbox-new.test:1883")
+		},
+	})
+
+	s.Equal(http.StatusBadGateway, s.recorder.Code)
+	s.Equal(1, attempts)
+	s.Zero(refreshed)
+}
+
+func (s *CommonSuite) TestProxyEngineForwardFlushesSSEIncrementally() {
+	release := make(chan struct{})
+	defer close(release)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, "data: first\n\n")
+		w.(http.Flusher).Flush()
+		// 上游在第二个事件前阻塞，客户端必须先收到第一个事件
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		_, _ = io.WriteString(w, "data: second\n\n")
+	}))
+	defer upstream.Close()
+
+	target, err := url.Parse(upstream.URL)
+	s.NoError(err)
+	engine := &ProxyEngine{Transport: http.DefaultTransport}
+	router := gin.New()
+	router.Any("/stream", func(ctx *gin.Context) {
+		body, ok := readProxyRequestBody(ctx)
+		if !ok {
+			return
+		}
+		engine.Forward(ctx, ProxyConfig{
+			Target:       target,
+			Method:       ctx.Request.Method,
+			InternalPath: "/events",
+			Body:         body,
+			SessionID:    "session-1",
+		})
+	})
+	gateway := httptest.NewServer(router)
+	defer gateway.Close()
+
+	req, err := http.NewRequest(http.MethodPost, gateway.URL+"/stream", strings.NewReader(`{"stream":true}`))
+	s.NoError(err)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	s.Equal("no-cache", resp.Header.Get("Cache-Control"))
+	s.Equal("session-1", resp.Header.Get(SessionHeader))
+
+	first := make(chan string, 1)
+	go func() {
+		buf := make([]byte, len("data: first\n\n"))
+		n, _ := io.ReadFull(resp.Body, buf)
+		first <- string(buf[:n])
+	}()
+	select {
+	case got := <-first:
+		s.Equal("data: first\n\n", got)
+	case <-time.After(2 * time.Second):
+		s.Fail("first SSE event was not flushed before upstream completed")
+	}
+}
+
+func (s *CommonSuite) TestProxyEngineForwardWebSocketUpgrade() {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Equal("websocket", r.Header.Get("Upgrade"))
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if !s.NoError(err) {
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		_ = rw.Flush()
+		line, _ := rw.ReadString('\n')
+		_, _ = rw.WriteString("echo:" + line)
+		_ = rw.Flush()
+	}))
+	defer upstream.Close()
+
+	target, err := url.Parse(upstream.URL)
+	s.NoError(err)
+	engine := &ProxyEngine{Transport: http.DefaultTransport}
+	router := gin.New()
+	router.GET("/ws", func(ctx *gin.Context) {
+		body, ok := readProxyRequestBody(ctx)
+		if !ok {
+			return
+		}
+		s.Nil(body)
+		engine.Forward(ctx, ProxyConfig{
+			Target:       target,
+			Method:       http.MethodGet,
+			InternalPath: "/ws",
+			Body:         body,
+		})
+	})
+	gateway := httptest.NewServer(router)
+	defer gateway.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(gateway.URL, "http://"))
+	s.Require().NoError(err)
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	_, err = io.WriteString(conn, "GET /ws HTTP/1.1\r\nHost: gateway\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+	s.Require().NoError(err)
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	s.Require().NoError(err)
+	s.Equal(http.StatusSwitchingProtocols, resp.StatusCode)
+
+	_, err = io.WriteString(conn, "ping\n")
+	s.Require().NoError(err)
+	line, err := reader.ReadString('\n')
+	s.NoError(err)
+	s.Equal("echo:ping\n", line)
+}
+
+func (s *CommonSuite) TestIsStreamingRequest() {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	s.False(isStreamingRequest(req))
+
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	s.True(isStreamingRequest(req))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Upgrade", "websocket")
+	s.False(isStreamingRequest(req))
+	req.Header.Set("Connection", "keep-alive, Upgrade")
+	s.True(isStreamingRequest(req))
+}
+
+func (s *CommonSuite) TestInflightTrackerDrain() {
+	tracker := &InflightTracker{}
+	done := tracker.Track()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	s.ErrorIs(tracker.Drain(ctx), context.DeadlineExceeded)
+
+	// 排空开始后的新请求不再登记
+	tracker.Track()()
+	done()
+	s.NoError(tracker.Drain(context.Background()))
+}
+
 func (s *CommonSuite) TestBuildTokenSigner() {
 	privatePath, _, err := testutil.WriteTestRSAKeys(s.T().TempDir())
 	s.NoError(err)
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+)
+
+// proxyInflight 为所有 ProxyEngine 共享的在途请求计数，停机时由 DrainProxies 等待
+var proxyInflight = &InflightTracker{}
+
+// InflightTracker 记录进行中的代理请求，停机时等待其自然结束
+type InflightTracker struct {
+	mu       sync.Mutex
+	draining bool
+	wg       sync.WaitGroup
+}
+
+// Track 登记一个代理请求，返回的函数需在请求结束时调用。
+// 开始排空后不再登记新请求，避免 WaitGroup 在 Wait 期间从零重新计数，
+// 这部分请求仍由 http.Server.Shutdown 兜底等待。
+func (t *InflightTracker) Track() func() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.draining {
+		return func() {}
+	}
+	t.wg.Add(1)
+	return t.wg.Done
+}
+
+// Drain 停止登记新请求并等待已登记的请求结束，ctx 结束时返回其错误
+func (t *InflightTracker) Drain(ctx context.Context) error {
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// DrainProxies 等待网关上所有在途代理请求结束，供停机流程在关闭 HTTP Server 前调用
+func DrainProxies(ctx context.Context) error {
+	return proxyInflight.Drain(ctx)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/utils"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/config"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/sandboxjwt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const jwksCacheControl = "public, max-age=60"
+
+type JWKSHandler struct {
+	loadKeys func() (utils.JWKS, error)
+}
+
+func InitJWKSApi(routes gin.IRoutes, cfg *config.Config) {
+	h := &JWKSHandler{
+		loadKeys: func() (utils.JWKS, error) {
+			return sandboxjwt.PublicJWKS(cfg.SandboxJWTPrivatePath, cfg.SandboxJWTKID)
+		},
+	}
+
+	routes.GET("/.well-known/jwks.json", h.GetJWKS)
+}
+
+// GetJWKS 以 JWKS 格式返回当前有效的沙箱 token 验签公钥。
+func (h *JWKSHandler) GetJWKS(ctx *gin.Context) {
+	keys, err := h.loadKeys()
+	if err != nil {
+		zap.L().Error("Load sandbox JWKS failed", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "jwks unavailable"})
+		return
+	}
+
+	ctx.Header("Cache-Control", jwksCacheControl)
+	ctx.JSON(http.StatusOK, keys)
+}
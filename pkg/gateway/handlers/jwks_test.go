@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/testutil"
+	"github.com/Fl0rencess720/agentland/pkg/common/utils"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/config"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetJWKS_ServesSigningKey(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	privatePath, _, err := testutil.WriteTestRSAKeys(t.TempDir())
+	require.NoError(t, err)
+
+	engine := gin.New()
+	InitJWKSApi(engine, &config.Config{SandboxJWTPrivatePath: privatePath, SandboxJWTKID: "kid-1"})
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, jwksCacheControl, rec.Header().Get("Cache-Control"))
+
+	var keys utils.JWKS
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &keys))
+	require.Len(t, keys.Keys, 1)
+	require.Equal(t, "kid-1", keys.Keys[0].Kid)
+	require.Equal(t, "RSA", keys.Keys[0].Kty)
+	require.Equal(t, utils.AlgRS256, keys.Keys[0].Alg)
+	require.NotEmpty(t, keys.Keys[0].N)
+	require.NotEmpty(t, keys.Keys[0].E)
+}
+
+func TestGetJWKS_MissingKey(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	engine := gin.New()
+	InitJWKSApi(engine, &config.Config{SandboxJWTPrivatePath: t.TempDir() + "/missing.pem", SandboxJWTKID: "kid-1"})
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+}
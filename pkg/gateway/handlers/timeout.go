@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 代码执行 timeout_ms 的合法范围与沙箱侧未指定时的默认值，与沙箱侧校验保持一致
+const (
+	minExecuteTimeoutMs     = 100
+	maxExecuteTimeoutMs     = 300000
+	sandboxExecuteTimeoutMs = 30000
+)
+
+// executeTimeoutPolicy 为网关对代码执行超时的统一约束，在转发前改写请求体
+type executeTimeoutPolicy struct {
+	// defaultMs 为请求未指定超时时注入的值，0 表示沿用沙箱默认值
+	defaultMs int
+	// maxMs 为允许的最大超时，超过时截断，0 表示仅受沙箱允许的最大值约束
+	maxMs int
+}
+
+// newExecuteTimeoutPolicy 按配置构建超时策略，<=0 的配置项不生效；上限不超过沙箱允许的最大值，默认值不超过上限
+func newExecuteTimeoutPolicy(defaultTimeout, maxTimeout time.Duration) executeTimeoutPolicy {
+	var p executeTimeoutPolicy
+	upper := maxExecuteTimeoutMs
+	if maxTimeout > 0 {
+		upper = min(max(int(maxTimeout.Milliseconds()), minExecuteTimeoutMs), maxExecuteTimeoutMs)
+		p.maxMs = upper
+	}
+	if defaultTimeout > 0 {
+		p.defaultMs = min(max(int(defaultTimeout.Milliseconds()), minExecuteTimeoutMs), upper)
+	}
+	return p
+}
+
+// validTimeout 判断请求中的 timeout_ms 是否在沙箱允许的范围内，0 表示未指定
+func validTimeout(timeoutMs int) bool {
+	return timeoutMs == 0 || (timeoutMs >= minExecuteTimeoutMs && timeoutMs <= maxExecuteTimeoutMs)
+}
+
+// effectiveDefault 返回未指定 timeout_ms 时实际生效的超时
+func (p executeTimeoutPolicy) effectiveDefault() int {
+	if p.defaultMs > 0 {
+		return p.defaultMs
+	}
+	return min(sandboxExecuteTimeoutMs, p.effectiveMax())
+}
+
+// effectiveMax 返回实际允许的最大超时
+func (p executeTimeoutPolicy) effectiveMax() int {
+	if p.maxMs > 0 {
+		return p.maxMs
+	}
+	return maxExecuteTimeoutMs
+}
+
+// apply 返回转发时使用的 timeout_ms：未指定时取默认值，超过上限时截断到上限
+func (p executeTimeoutPolicy) apply(timeoutMs int) int {
+	if timeoutMs == 0 {
+		return p.defaultMs
+	}
+	if p.maxMs > 0 {
+		return min(timeoutMs, p.maxMs)
+	}
+	return timeoutMs
+}
+
+// applyExecuteTimeout 改写单次执行请求体中的 timeout_ms，未发生变化时原样返回 body
+func (p executeTimeoutPolicy) applyExecuteTimeout(body []byte, timeoutMs int) ([]byte, error) {
+	effective := p.apply(timeoutMs)
+	if effective == timeoutMs {
+		return body, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	if err := setTimeoutField(fields, effective); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// applyBatchTimeout 改写批量执行请求体中每个 cell 的 timeout_ms，其余字段原样保留
+func (p executeTimeoutPolicy) applyBatchTimeout(body []byte, timeouts []int) ([]byte, error) {
+	changed := false
+	for _, timeoutMs := range timeouts {
+		if p.apply(timeoutMs) != timeoutMs {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return body, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	var cells []map[string]json.RawMessage
+	if err := json.Unmarshal(fields["cells"], &cells); err != nil {
+		return nil, err
+	}
+	for i := range cells {
+		if err := setTimeoutField(cells[i], p.apply(timeouts[i])); err != nil {
+			return nil, err
+		}
+	}
+	raw, err := json.Marshal(cells)
+	if err != nil {
+		return nil, err
+	}
+	fields["cells"] = raw
+	return json.Marshal(fields)
+}
+
+func setTimeoutField(fields map[string]json.RawMessage, timeoutMs int) error {
+	if timeoutMs == 0 {
+		delete(fields, "timeout_ms")
+		return nil
+	}
+	raw, err := json.Marshal(timeoutMs)
+	if err != nil {
+		return err
+	}
+	fields["timeout_ms"] = raw
+	return nil
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/gateway/config"
+)
+
+// 沙箱 HTTP 连接池参数未配置（<=0）时使用的默认值
+const (
+	defaultSandboxMaxIdleConns        = 200
+	defaultSandboxMaxIdleConnsPerHost = 10
+	defaultSandboxIdleConnTimeout     = 90 * time.Second
+	defaultSandboxDialTimeout         = 10 * time.Second
+	defaultSandboxTLSHandshakeTimeout = 10 * time.Second
+)
+
+// NewSandboxTransport 按配置构建网关转发到沙箱的共享 HTTP Transport，各 Handler 复用同一个连接池。
+// MaxConnsPerHost 与 ResponseHeaderTimeout 未配置时不限制，避免长时间执行的非流式请求被提前中断
+func NewSandboxTransport(cfg *config.Config) (*http.Transport, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.SandboxHTTPTLSCAPath != "" {
+		pem, err := os.ReadFile(cfg.SandboxHTTPTLSCAPath)
+		if err != nil {
+			return nil, fmt.Errorf("read sandbox tls ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("sandbox tls ca %s contains no certificates", cfg.SandboxHTTPTLSCAPath)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   positiveOr(cfg.SandboxHTTPDialTimeout, defaultSandboxDialTimeout),
+		KeepAlive: 30 * time.Second,
+	}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   defaultSandboxTLSHandshakeTimeout,
+		MaxIdleConns:          positiveOr(cfg.SandboxHTTPMaxIdleConns, defaultSandboxMaxIdleConns),
+		MaxIdleConnsPerHost:   positiveOr(cfg.SandboxHTTPMaxIdleConnsPerHost, defaultSandboxMaxIdleConnsPerHost),
+		MaxConnsPerHost:       max(cfg.SandboxHTTPMaxConnsPerHost, 0),
+		IdleConnTimeout:       positiveOr(cfg.SandboxHTTPIdleConnTimeout, defaultSandboxIdleConnTimeout),
+		ResponseHeaderTimeout: max(cfg.SandboxHTTPResponseHeaderTimeout, 0),
+	}, nil
+}
+
+// positiveOr 在 v<=0 时返回默认值 def
+func positiveOr[T int | time.Duration](v, def T) T {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
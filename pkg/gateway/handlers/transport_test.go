@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/gateway/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSandboxTransport_Defaults(t *testing.T) {
+	transport, err := NewSandboxTransport(&config.Config{})
+	require.NoError(t, err)
+
+	require.Equal(t, defaultSandboxMaxIdleConns, transport.MaxIdleConns)
+	require.Equal(t, defaultSandboxMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	require.Zero(t, transport.MaxConnsPerHost)
+	require.Equal(t, defaultSandboxIdleConnTimeout, transport.IdleConnTimeout)
+	require.Zero(t, transport.ResponseHeaderTimeout)
+	require.Nil(t, transport.TLSClientConfig.RootCAs)
+}
+
+func TestNewSandboxTransport_LimitsConnsPerHost(t *testing.T) {
+	var active, peak atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		<-release
+	}))
+	defer srv.Close()
+
+	transport, err := NewSandboxTransport(&config.Config{
+		SandboxHTTPMaxIdleConnsPerHost:   3,
+		SandboxHTTPMaxConnsPerHost:       2,
+		SandboxHTTPResponseHeaderTimeout: 5 * time.Second,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, transport.MaxIdleConnsPerHost)
+	require.Equal(t, 2, transport.MaxConnsPerHost)
+	require.Equal(t, 5*time.Second, transport.ResponseHeaderTimeout)
+	defer transport.CloseIdleConnections()
+
+	client := &http.Client{Transport: transport}
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(srv.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+	}
+	require.Eventually(t, func() bool { return active.Load() == 2 }, time.Second, 10*time.Millisecond)
+	// 连接数已达上限，其余请求应排队等待而不是新建连接
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, int32(2), active.Load())
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(2), peak.Load())
+}
+
+func TestNewSandboxTransport_InvalidCA(t *testing.T) {
+	_, err := NewSandboxTransport(&config.Config{SandboxHTTPTLSCAPath: filepath.Join(t.TempDir(), "missing.pem")})
+	require.Error(t, err)
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caPath, []byte("not a certificate"), 0o600))
+	_, err = NewSandboxTransport(&config.Config{SandboxHTTPTLSCAPath: caPath})
+	require.ErrorContains(t, err, "contains no certificates")
+}
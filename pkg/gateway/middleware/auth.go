@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/utils"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// APIKeyHeader 为客户端携带 API Key 的请求头
+	APIKeyHeader = "x-agentland-api-key"
+
+	// AuthPrincipalKey 为鉴权通过后调用方身份在 gin.Context 中的键
+	AuthPrincipalKey = "authPrincipal"
+)
+
+// ErrNoCredentials 表示请求未携带某种鉴权方式所需的凭证，Auth 会继续尝试下一种方式
+var ErrNoCredentials = errors.New("no credentials")
+
+// Authenticator 校验请求携带的凭证并返回调用方身份
+type Authenticator interface {
+	Authenticate(r *http.Request) (string, error)
+}
+
+// APIKeyAuthenticator 以静态 API Key 校验调用方，key 以 SHA-256 摘要保存和比较
+type APIKeyAuthenticator struct {
+	principals map[[sha256.Size]byte]string
+}
+
+// NewAPIKeyAuthenticator 解析 "<principal>:<key>" 形式的配置项，principal 作为调用方身份
+func NewAPIKeyAuthenticator(entries []string) (*APIKeyAuthenticator, error) {
+	principals := make(map[[sha256.Size]byte]string, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		principal, key, ok := strings.Cut(entry, ":")
+		principal, key = strings.TrimSpace(principal), strings.TrimSpace(key)
+		if !ok || principal == "" || key == "" {
+			return nil, fmt.Errorf("invalid api key entry for principal %q, expected <principal>:<key>", principal)
+		}
+		digest := sha256.Sum256([]byte(key))
+		if _, exists := principals[digest]; exists {
+			return nil, fmt.Errorf("duplicate api key for principal %q", principal)
+		}
+		principals[digest] = principal
+	}
+	if len(principals) == 0 {
+		return nil, fmt.Errorf("at least one api key is required")
+	}
+	return &APIKeyAuthenticator{principals: principals}, nil
+}
+
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (string, error) {
+	key := strings.TrimSpace(r.Header.Get(APIKeyHeader))
+	if key == "" {
+		return "", ErrNoCredentials
+	}
+	principal, ok := a.principals[sha256.Sum256([]byte(key))]
+	if !ok {
+		return "", fmt.Errorf("invalid api key")
+	}
+	return principal, nil
+}
+
+type claimsVerifier interface {
+	Verify(token string) (*utils.Claims, error)
+}
+
+// JWTAuthenticator 校验 Authorization 中的 Bearer JWT，调用方身份取自 sub claim
+type JWTAuthenticator struct {
+	verifier claimsVerifier
+}
+
+func NewJWTAuthenticator(verifier claimsVerifier) *JWTAuthenticator {
+	return &JWTAuthenticator{verifier: verifier}
+}
+
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (string, error) {
+	authorization := strings.TrimSpace(r.Header.Get("Authorization"))
+	if authorization == "" {
+		return "", ErrNoCredentials
+	}
+	token, err := utils.ParseBearerToken(authorization)
+	if err != nil {
+		return "", err
+	}
+	claims, err := a.verifier.Verify(token)
+	if err != nil {
+		return "", err
+	}
+	subject := strings.TrimSpace(claims.Subject)
+	if subject == "" {
+		return "", fmt.Errorf("sub claim is required")
+	}
+	return subject, nil
+}
+
+// Auth 依次尝试各鉴权方式，通过后将调用方身份写入 AuthPrincipalKey；
+// 未配置任何鉴权方式时直接放行，便于本地开发。凭证缺失或无效时返回 401
+func Auth(authenticators ...Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(authenticators) == 0 {
+			c.Next()
+			return
+		}
+
+		for _, authenticator := range authenticators {
+			principal, err := authenticator.Authenticate(c.Request)
+			if errors.Is(err, ErrNoCredentials) {
+				continue
+			}
+			if err != nil {
+				c.Header("WWW-Authenticate", `Bearer realm="agentland"`)
+				response.APIErrorResponse(c, response.CodeUnauthenticated, "invalid credentials")
+				c.Abort()
+				return
+			}
+			c.Set(AuthPrincipalKey, principal)
+			c.Next()
+			return
+		}
+
+		c.Header("WWW-Authenticate", `Bearer realm="agentland"`)
+		response.APIErrorResponse(c, response.CodeUnauthenticated, "missing credentials")
+		c.Abort()
+	}
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/testutil"
+	"github.com/Fl0rencess720/agentland/pkg/common/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestAuthSuite(t *testing.T) {
+	suite.Run(t, &AuthSuite{})
+}
+
+type AuthSuite struct {
+	suite.Suite
+	signer *utils.Signer
+	engine *gin.Engine
+}
+
+func (s *AuthSuite) SetupSuite() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func (s *AuthSuite) SetupTest() {
+	dir := s.T().TempDir()
+	privatePath, publicPath, err := testutil.WriteTestRSAKeys(dir)
+	s.Require().NoError(err)
+
+	s.signer, err = utils.NewSignerFromConfig(utils.SignerConfig{
+		PrivateKeyPath: privatePath,
+		Issuer:         "https://idp.example.com",
+		Audience:       "agentland",
+		TTL:            time.Minute,
+	})
+	s.Require().NoError(err)
+	verifier, err := utils.NewVerifierFromConfig(utils.VerifierConfig{
+		PublicKeyPath:     publicPath,
+		Issuer:            "https://idp.example.com",
+		Audience:          "agentland",
+		OptionalSessionID: true,
+	})
+	s.Require().NoError(err)
+
+	apiKeys, err := NewAPIKeyAuthenticator([]string{"ci-bot:key-123", " alice : key-456 "})
+	s.Require().NoError(err)
+
+	s.engine = gin.New()
+	s.engine.GET("/sandboxes", Auth(apiKeys, NewJWTAuthenticator(verifier)), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(AuthPrincipalKey))
+	})
+}
+
+func (s *AuthSuite) do(header, value string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/sandboxes", nil)
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	recorder := httptest.NewRecorder()
+	s.engine.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// 测试合法的 API Key 与 JWT 均可通过，调用方身份写入上下文
+func (s *AuthSuite) TestAcceptsValidCredentials() {
+	w := s.do(APIKeyHeader, "key-123")
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("ci-bot", w.Body.String())
+
+	w = s.do(APIKeyHeader, "key-456")
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("alice", w.Body.String())
+
+	token, err := s.signer.Sign("unused", "bob", 0)
+	s.Require().NoError(err)
+	w = s.do("Authorization", "Bearer "+token)
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal("bob", w.Body.String())
+}
+
+// 测试缺失或无效的凭证返回结构化 401
+func (s *AuthSuite) TestRejectsInvalidCredentials() {
+	w := s.do("", "")
+	s.Equal(http.StatusUnauthorized, w.Code)
+	s.Equal(`Bearer realm="agentland"`, w.Header().Get("WWW-Authenticate"))
+	s.JSONEq(`{"code":"UNAUTHENTICATED","msg":"missing credentials"}`, w.Body.String())
+
+	w = s.do(APIKeyHeader, "key-999")
+	s.Equal(http.StatusUnauthorized, w.Code)
+	s.JSONEq(`{"code":"UNAUTHENTICATED","msg":"invalid credentials"}`, w.Body.String())
+
+	s.Equal(http.StatusUnauthorized, s.do("Authorization", "Bearer not.a.jwt").Code)
+	s.Equal(http.StatusUnauthorized, s.do("Authorization", "Basic YWxpY2U6c2VjcmV0").Code)
+
+	// 缺少 sub 的 token 无法确定调用方身份
+	token, err := s.signer.Sign("unused", "", 0)
+	s.Require().NoError(err)
+	s.Equal(http.StatusUnauthorized, s.do("Authorization", "Bearer "+token).Code)
+}
+
+// 测试未配置任何鉴权方式时放行
+func (s *AuthSuite) TestDisabledWithoutAuthenticators() {
+	engine := gin.New()
+	engine.GET("/sandboxes", Auth(), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/sandboxes", nil))
+	s.Equal(http.StatusNoContent, recorder.Code)
+}
+
+func (s *AuthSuite) TestNewAPIKeyAuthenticator_RejectsInvalidEntries() {
+	for _, entries := range [][]string{nil, {"missing-colon"}, {":key"}, {"alice:"}, {"a:dup", "b:dup"}} {
+		_, err := NewAPIKeyAuthenticator(entries)
+		s.Error(err, "%v", entries)
+	}
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/observability"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	defaultCORSMethods = []string{
+		http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions,
+	}
+	defaultCORSHeaders = []string{
+		"Authorization", "Content-Type", "Accept", "Cache-Control", "If-None-Match", "Last-Event-ID",
+		"x-agentland-session", APIKeyHeader, observability.RequestIDHeader,
+		"x-agentland-owner", "x-agentland-runtime", "x-agentland-runtime-namespace", "x-agentland-idempotency-key",
+	}
+	// corsExposedHeaders 为浏览器脚本可读取的响应头
+	corsExposedHeaders = []string{
+		"x-agentland-session", observability.RequestIDHeader,
+		"Retry-After", "ETag", "Content-Disposition",
+	}
+)
+
+// CORSOptions 为跨域配置，AllowedOrigins 为空时不处理跨域请求；
+// AllowedMethods、AllowedHeaders 为空时使用网关接口所需的默认值
+type CORSOptions struct {
+	// AllowedOrigins 为允许的来源列表，"*" 表示任意来源
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	// MaxAge 为浏览器缓存预检结果的时长，<=0 时不下发
+	MaxAge time.Duration
+}
+
+// CORS 为允许的来源写入跨域响应头，并由网关直接应答预检请求，预检不会转发到沙箱。
+// 需挂载在鉴权之前，浏览器发出的预检请求不携带凭证
+func CORS(opts CORSOptions) gin.HandlerFunc {
+	if len(opts.AllowedOrigins) == 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	anyOrigin := slices.Contains(opts.AllowedOrigins, "*")
+	methods := strings.Join(orDefault(opts.AllowedMethods, defaultCORSMethods), ", ")
+	headers := strings.Join(orDefault(opts.AllowedHeaders, defaultCORSHeaders), ", ")
+	exposed := strings.Join(corsExposedHeaders, ", ")
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		c.Writer.Header().Add("Vary", "Origin")
+
+		allowed := anyOrigin || slices.Contains(opts.AllowedOrigins, origin)
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if !allowed {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		// 携带凭证时规范不允许使用通配符，回写具体来源
+		if anyOrigin && !opts.AllowCredentials {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if opts.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			h.Set("Access-Control-Expose-Headers", exposed)
+			c.Next()
+			return
+		}
+
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+		h.Set("Access-Control-Allow-Methods", methods)
+		h.Set("Access-Control-Allow-Headers", headers)
+		h.Set("Access-Control-Expose-Headers", exposed)
+		if opts.MaxAge > 0 {
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(opts.MaxAge.Seconds())))
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+func orDefault(values, def []string) []string {
+	if len(values) == 0 {
+		return def
+	}
+	return values
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestCORSSuite(t *testing.T) {
+	suite.Run(t, &CORSSuite{})
+}
+
+type CORSSuite struct {
+	suite.Suite
+	proxied int
+}
+
+func (s *CORSSuite) SetupSuite() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+func (s *CORSSuite) newEngine(opts CORSOptions) *gin.Engine {
+	s.proxied = 0
+	engine := gin.New()
+	engine.Use(CORS(opts))
+	// 与网关透传路由一致，Any 会匹配 OPTIONS
+	engine.Any("/api/code-runner/fs/*path", Auth(denyAll{}), func(c *gin.Context) {
+		s.proxied++
+		c.Header(testSessionHeader, "session-1")
+		c.Status(http.StatusOK)
+	})
+	return engine
+}
+
+func (s *CORSSuite) do(engine *gin.Engine, method, origin string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api/code-runner/fs/file", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// 测试预检请求由网关直接应答，不经过鉴权也不转发到沙箱
+func (s *CORSSuite) TestPreflightAnsweredByGateway() {
+	engine := s.newEngine(CORSOptions{
+		AllowedOrigins: []string{"https://app.example.com"},
+		MaxAge:         10 * time.Minute,
+	})
+
+	w := s.do(engine, http.MethodOptions, "https://app.example.com", map[string]string{
+		"Access-Control-Request-Method":  http.MethodPost,
+		"Access-Control-Request-Headers": "content-type, x-agentland-session",
+	})
+
+	s.Equal(http.StatusNoContent, w.Code)
+	s.Zero(s.proxied)
+	s.Equal("https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	s.Empty(w.Header().Get("Access-Control-Allow-Credentials"))
+	s.Contains(w.Header().Get("Access-Control-Allow-Methods"), http.MethodPost)
+	s.Contains(w.Header().Get("Access-Control-Allow-Headers"), "x-agentland-session")
+	s.Contains(w.Header().Get("Access-Control-Expose-Headers"), "x-agentland-session")
+	s.Contains(w.Header().Get("Access-Control-Expose-Headers"), "x-agentland-request-id")
+	s.Equal("600", w.Header().Get("Access-Control-Max-Age"))
+	s.Contains(w.Header().Values("Vary"), "Origin")
+}
+
+// 测试未允许的来源：预检返回 403，普通请求不附带跨域头
+func (s *CORSSuite) TestRejectsUnknownOrigin() {
+	engine := s.newEngine(CORSOptions{AllowedOrigins: []string{"https://app.example.com"}})
+
+	w := s.do(engine, http.MethodOptions, "https://evil.example.com", map[string]string{
+		"Access-Control-Request-Method": http.MethodGet,
+	})
+	s.Equal(http.StatusForbidden, w.Code)
+	s.Empty(w.Header().Get("Access-Control-Allow-Origin"))
+
+	w = s.do(engine, http.MethodGet, "https://evil.example.com", nil)
+	s.Equal(http.StatusUnauthorized, w.Code)
+	s.Empty(w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+// 测试携带凭证时通配来源回写具体 Origin，实际请求附带可读的响应头
+func (s *CORSSuite) TestWildcardWithCredentials() {
+	engine := s.newEngine(CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+
+	w := s.do(engine, http.MethodGet, "http://localhost:5173", nil)
+	s.Equal(http.StatusUnauthorized, w.Code)
+	s.Equal("http://localhost:5173", w.Header().Get("Access-Control-Allow-Origin"))
+	s.Equal("true", w.Header().Get("Access-Control-Allow-Credentials"))
+	s.Contains(w.Header().Get("Access-Control-Expose-Headers"), "x-agentland-session")
+
+	engine = s.newEngine(CORSOptions{AllowedOrigins: []string{"*"}})
+	w = s.do(engine, http.MethodOptions, "http://localhost:5173", map[string]string{
+		"Access-Control-Request-Method": http.MethodGet,
+	})
+	s.Equal(http.StatusNoContent, w.Code)
+	s.Equal("*", w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+// 测试未配置来源时不处理跨域，OPTIONS 照常进入路由
+func (s *CORSSuite) TestDisabledWithoutOrigins() {
+	engine := s.newEngine(CORSOptions{})
+
+	w := s.do(engine, http.MethodOptions, "https://app.example.com", map[string]string{
+		"Access-Control-Request-Method": http.MethodGet,
+	})
+	s.Equal(http.StatusUnauthorized, w.Code)
+	s.Empty(w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+// denyAll 模拟开启鉴权且请求未携带有效凭证
+type denyAll struct{}
+
+func (denyAll) Authenticate(*http.Request) (string, error) {
+	return "", ErrNoCredentials
+}
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics 按路由模板记录请求数与耗时，未命中路由的请求统一归入 unmatched
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		metrics.ObserveHTTPRequest(c.Request.Method, c.FullPath(), c.Writer.Status(), start)
+	}
+}
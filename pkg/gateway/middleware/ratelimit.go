@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// rateLimiterKey 为 RateLimit 写入 gin.Context 的限流器，供 AllowSession 在会话校验后复用
+	rateLimiterKey = "rateLimiter"
+
+	// limiterIdleTTL 为令牌桶空闲多久后回收，空闲足够久的桶已回满，回收后重建行为一致
+	limiterIdleTTL = 10 * time.Minute
+)
+
+// RateLimiter 判断 key 对应的请求是否放行，拒绝时返回建议的重试等待时间
+type RateLimiter interface {
+	Allow(key string) (bool, time.Duration)
+}
+
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// KeyedRateLimiter 为每个 key 维护独立的令牌桶
+type KeyedRateLimiter struct {
+	limit rate.Limit
+	burst int
+	now   func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]*limiterEntry
+	lastSweep time.Time
+}
+
+// NewKeyedRateLimiter 创建按 key 限流的令牌桶，now 为空时使用 time.Now，测试可注入假时钟
+func NewKeyedRateLimiter(rps float64, burst int, now func() time.Time) *KeyedRateLimiter {
+	if now == nil {
+		now = time.Now
+	}
+	if burst <= 0 {
+		burst = int(math.Ceil(rps))
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	return &KeyedRateLimiter{
+		limit:   rate.Limit(rps),
+		burst:   burst,
+		now:     now,
+		entries: make(map[string]*limiterEntry),
+	}
+}
+
+func (l *KeyedRateLimiter) Allow(key string) (bool, time.Duration) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweepLocked(now)
+
+	entry, ok := l.entries[key]
+	if !ok {
+		entry = &limiterEntry{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.entries[key] = entry
+	}
+	entry.lastSeen = now
+
+	reservation := entry.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, time.Second
+	}
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+func (l *KeyedRateLimiter) sweepLocked(now time.Time) {
+	if now.Sub(l.lastSweep) < limiterIdleTTL {
+		return
+	}
+	l.lastSweep = now
+	for key, entry := range l.entries {
+		if now.Sub(entry.lastSeen) >= limiterIdleTTL {
+			delete(l.entries, key)
+		}
+	}
+}
+
+// RateLimit 按鉴权得到的调用方身份限流，未鉴权的请求按客户端 IP 计数，超限时返回 429 与 Retry-After；
+// 客户端自报的会话 ID 不参与计数，会话维度的限流由 handler 在校验会话归属后调用 AllowSession 完成
+func RateLimit(limiter RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		allowed, retryAfter := limiter.Allow(rateLimitKey(c))
+		if !allowed {
+			abortTooManyRequests(c, retryAfter)
+			return
+		}
+		c.Set(rateLimiterKey, limiter)
+		c.Next()
+	}
+}
+
+// AllowSession 按会话 ID 计数，限制同一沙箱承受的请求速率；调用方必须已校验请求方有权访问该会话。
+// 未经 RateLimit 中间件或未配置限流器时直接放行，超限时写入 429 并返回 false
+func AllowSession(c *gin.Context, sessionID string) bool {
+	value, ok := c.Get(rateLimiterKey)
+	if !ok {
+		return true
+	}
+	limiter, ok := value.(RateLimiter)
+	if !ok || limiter == nil {
+		return true
+	}
+	allowed, retryAfter := limiter.Allow("session:" + sessionID)
+	if !allowed {
+		abortTooManyRequests(c, retryAfter)
+		return false
+	}
+	return true
+}
+
+func abortTooManyRequests(c *gin.Context, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	response.ErrorResponse(c, response.TooManyRequests)
+	c.Abort()
+}
+
+func rateLimitKey(c *gin.Context) string {
+	if principal := c.GetString(AuthPrincipalKey); principal != "" {
+		return "principal:" + principal
+	}
+	return "ip:" + c.ClientIP()
+}
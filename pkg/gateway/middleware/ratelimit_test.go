@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRateLimitSuite(t *testing.T) {
+	suite.Run(t, &RateLimitSuite{})
+}
+
+type RateLimitSuite struct {
+	suite.Suite
+	now    time.Time
+	engine *gin.Engine
+}
+
+func (s *RateLimitSuite) SetupSuite() {
+	gin.SetMode(gin.ReleaseMode)
+}
+
+const testSessionHeader = "x-agentland-session"
+
+func (s *RateLimitSuite) SetupTest() {
+	s.now = time.Date(2026, 2, 17, 8, 30, 0, 0, time.UTC)
+	limiter := NewKeyedRateLimiter(2, 3, func() time.Time { return s.now })
+
+	// 模拟鉴权中间件写入调用方身份，以及 handler 校验会话归属后按会话限流
+	setPrincipal := func(c *gin.Context) {
+		if principal := c.GetHeader("x-test-principal"); principal != "" {
+			c.Set(AuthPrincipalKey, principal)
+		}
+	}
+	s.engine = gin.New()
+	s.engine.POST("/execute", setPrincipal, RateLimit(limiter), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	s.engine.POST("/sessions/:sessionId/invoke", setPrincipal, RateLimit(limiter), func(c *gin.Context) {
+		if !AllowSession(c, c.Param("sessionId")) {
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+}
+
+func (s *RateLimitSuite) do(path, principal, clientIP, sessionID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	if principal != "" {
+		req.Header.Set("x-test-principal", principal)
+	}
+	if clientIP != "" {
+		req.RemoteAddr = clientIP + ":12345"
+	}
+	if sessionID != "" {
+		req.Header.Set(testSessionHeader, sessionID)
+	}
+	recorder := httptest.NewRecorder()
+	s.engine.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// 测试突发额度耗尽后限流，按速率补充令牌后恢复
+func (s *RateLimitSuite) TestBurstThenThrottle() {
+	for i := 0; i < 3; i++ {
+		s.Equal(http.StatusOK, s.do("/execute", "alice", "", "sbx-1").Code, "request %d within burst", i)
+	}
+
+	throttled := s.do("/execute", "alice", "", "sbx-1")
+	s.Equal(http.StatusTooManyRequests, throttled.Code)
+	s.Equal("1", throttled.Header().Get("Retry-After"))
+	s.JSONEq(`{"code":2,"msg":"Too Many Requests"}`, throttled.Body.String())
+
+	// 其他调用方不受影响
+	s.Equal(http.StatusOK, s.do("/execute", "bob", "", "sbx-2").Code)
+
+	// 2 rps 下 500ms 补充一个令牌
+	s.now = s.now.Add(500 * time.Millisecond)
+	s.Equal(http.StatusOK, s.do("/execute", "alice", "", "sbx-1").Code)
+	s.Equal(http.StatusTooManyRequests, s.do("/execute", "alice", "", "sbx-1").Code)
+}
+
+// 测试轮换自报的会话 ID 无法绕过按调用方身份的限流
+func (s *RateLimitSuite) TestRotatingSessionHeaderIsThrottled() {
+	for i := 0; i < 3; i++ {
+		s.Equal(http.StatusOK, s.do("/execute", "alice", "", fmt.Sprintf("sbx-%d", i)).Code, "request %d within burst", i)
+	}
+	s.Equal(http.StatusTooManyRequests, s.do("/execute", "alice", "", "sbx-rotated").Code)
+}
+
+// 测试未鉴权的请求按客户端 IP 计数，同样不受会话 ID 影响
+func (s *RateLimitSuite) TestAnonymousKeyedByClientIP() {
+	for i := 0; i < 3; i++ {
+		s.Equal(http.StatusOK, s.do("/execute", "", "192.0.2.1", fmt.Sprintf("sbx-%d", i)).Code, "request %d within burst", i)
+	}
+	s.Equal(http.StatusTooManyRequests, s.do("/execute", "", "192.0.2.1", "sbx-rotated").Code)
+	s.Equal(http.StatusOK, s.do("/execute", "", "192.0.2.2", "sbx-rotated").Code)
+}
+
+// 测试会话校验后按会话限流，不同来源共享同一会话的额度
+func (s *RateLimitSuite) TestAllowSessionSharesBucketAcrossClients() {
+	for i := 0; i < 3; i++ {
+		clientIP := fmt.Sprintf("192.0.2.%d", i+1)
+		s.Equal(http.StatusOK, s.do("/sessions/sbx-1/invoke", "", clientIP, "").Code, "request %d within burst", i)
+	}
+
+	throttled := s.do("/sessions/sbx-1/invoke", "", "192.0.2.9", "")
+	s.Equal(http.StatusTooManyRequests, throttled.Code)
+	s.Equal("1", throttled.Header().Get("Retry-After"))
+	s.Equal(http.StatusOK, s.do("/sessions/sbx-2/invoke", "", "192.0.2.10", "").Code)
+}
+
+// 测试未经 RateLimit 中间件时 AllowSession 直接放行
+func (s *RateLimitSuite) TestAllowSessionWithoutLimiter() {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	s.True(AllowSession(c, "sbx-1"))
+}
+
+// 测试空闲桶被回收后按满额度重建
+func (s *RateLimitSuite) TestIdleBucketsAreSwept() {
+	limiter := NewKeyedRateLimiter(1, 1, func() time.Time { return s.now })
+
+	allowed, _ := limiter.Allow("session:sbx-1")
+	s.True(allowed)
+	allowed, retryAfter := limiter.Allow("session:sbx-1")
+	s.False(allowed)
+	s.Equal(time.Second, retryAfter)
+
+	s.now = s.now.Add(limiterIdleTTL)
+	allowed, _ = limiter.Allow("session:sbx-2")
+	s.True(allowed)
+	s.Len(limiter.entries, 1)
+}
+
+// 测试未配置限流器时直接放行
+func (s *RateLimitSuite) TestNilLimiterPassesThrough() {
+	engine := gin.New()
+	engine.GET("/ping", RateLimit(nil), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	s.Equal(http.StatusNoContent, recorder.Code)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -11,8 +13,16 @@ import (
 )
 
 var (
-	keyPrefixSession     = "agentland:session:"      // 会话信息前缀
-	keyLastActivityIndex = "agentland:last-activity" // 按活跃时间排序的索引
+	keyPrefixSession      = "agentland:session:"       // 会话信息前缀
+	keyLastActivityIndex  = "agentland:last-activity"  // 按活跃时间排序的索引
+	keyPrefixTokenVersion = "agentland:token-version:" // 会话最小 token 版本前缀
+	keyPrefixOwnerIndex   = "agentland:owner:"         // 按 owner 归属的会话索引前缀，由 agentcore 维护，成员分数为过期时间
+
+	// tokenVersionTTL 需远大于 token TTL 加时钟偏差，版本 key 过期时被吊销的 token 早已失效。
+	tokenVersionTTL = 24 * time.Hour
+
+	// MaxIdleDuration 与 agentcore 会话 GC 的空闲阈值保持一致，用于计算续期后的过期时间
+	MaxIdleDuration = 15 * time.Minute
 
 	ErrSessionNotFound = fmt.Errorf("session not found")
 )
@@ -26,6 +36,7 @@ type SandboxInfo struct {
 	GrpcEndpoint string    `json:"grpc_endpoint"`
 	CreatedAt    time.Time `json:"created_at"`
 	ExpiresAt    time.Time `json:"expires_at"`
+	Owner        string    `json:"owner,omitempty"`
 }
 
 func NewRedis() *redis.Client {
@@ -90,3 +101,130 @@ func (s *SessionStore) GetSession(ctx context.Context, sandboxID string) (*Sandb
 
 	return &info, nil
 }
+
+// UpdateEndpoint 改写会话记录中的沙箱地址并保留原有过期时间，其余字段（含 agentcore 写入的未知字段）原样保留
+func (s *SessionStore) UpdateEndpoint(ctx context.Context, sandboxID, endpoint string) error {
+	key := keyPrefixSession + sandboxID
+
+	data, err := s.client.Get(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &fields); err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(endpoint)
+	if err != nil {
+		return err
+	}
+	fields["grpc_endpoint"] = encoded
+	updated, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+
+	return s.client.SetArgs(ctx, key, updated, redis.SetArgs{KeepTTL: true, Mode: "XX"}).Err()
+}
+
+// ListSessions 以 ZSCAN 游标分页列出 owner 名下 sandbox ID 以 prefix 开头的存活会话，数据来自 agentcore 维护的 owner 索引，
+// 不会返回其他 owner 的会话。cursor 为 0 表示从头开始，返回的 next 为 0 表示已遍历完毕；单页数量可能略多于 limit。
+func (s *SessionStore) ListSessions(ctx context.Context, owner, prefix string, cursor uint64, limit int64) ([]*SandboxInfo, uint64, error) {
+	if owner == "" {
+		return nil, 0, nil
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	ownerKey := keyPrefixOwnerIndex + owner
+	match := escapeGlob(prefix) + "*"
+	now := time.Now()
+
+	keys := make([]string, 0, limit)
+	for {
+		// ZSCAN 返回成员与分数交替排列的列表
+		batch, next, err := s.client.ZScan(ctx, ownerKey, cursor, match, limit).Result()
+		if err != nil {
+			return nil, 0, err
+		}
+		for i := 0; i+1 < len(batch); i += 2 {
+			expiresAt, err := strconv.ParseInt(batch[i+1], 10, 64)
+			if err == nil && expiresAt < now.Unix() {
+				continue
+			}
+			keys = append(keys, keyPrefixSession+batch[i])
+		}
+		cursor = next
+		if cursor == 0 || int64(len(keys)) >= limit {
+			break
+		}
+	}
+	if len(keys) == 0 {
+		return nil, cursor, nil
+	}
+
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	sessions := make([]*SandboxInfo, 0, len(values))
+	for _, v := range values {
+		// 扫描与读取之间过期的 key 返回 nil
+		data, ok := v.(string)
+		if !ok {
+			continue
+		}
+		var info SandboxInfo
+		if err := json.Unmarshal([]byte(data), &info); err != nil {
+			continue
+		}
+		if info.Owner != owner {
+			continue
+		}
+		if !info.ExpiresAt.IsZero() && info.ExpiresAt.Before(now) {
+			continue
+		}
+		sessions = append(sessions, &info)
+	}
+	return sessions, cursor, nil
+}
+
+// escapeGlob 转义 Redis MATCH 模式中的通配字符，使用户提供的前缀按字面匹配
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// MinTokenVersion 返回会话当前允许的最小 token 版本，未吊销过的会话为 0
+func (s *SessionStore) MinTokenVersion(ctx context.Context, sandboxID string) (int64, error) {
+	version, err := s.client.Get(ctx, keyPrefixTokenVersion+sandboxID).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return version, err
+}
+
+// BumpTokenVersion 递增会话的最小 token 版本，使此前签发的 token 全部失效。
+// 沙箱不访问该存储，而是从网关随后以新版本签发并转发的 token 中得知最小版本。
+func (s *SessionStore) BumpTokenVersion(ctx context.Context, sandboxID string) (int64, error) {
+	key := keyPrefixTokenVersion + sandboxID
+
+	pipe := s.client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, tokenVersionTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
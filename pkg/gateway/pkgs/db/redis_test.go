@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSessionStore(t *testing.T) (*SessionStore, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return &SessionStore{client: client}, mr
+}
+
+func putSession(t *testing.T, mr *miniredis.Miniredis, info SandboxInfo) {
+	t.Helper()
+
+	data, err := json.Marshal(info)
+	require.NoError(t, err)
+	require.NoError(t, mr.Set(keyPrefixSession+info.SandboxID, string(data)))
+	if info.Owner != "" {
+		_, err := mr.ZAdd(keyPrefixOwnerIndex+info.Owner, float64(info.ExpiresAt.Unix()), info.SandboxID)
+		require.NoError(t, err)
+	}
+}
+
+func TestListSessions_PaginatesWithCursor(t *testing.T) {
+	store, mr := newTestSessionStore(t)
+	now := time.Now().UTC()
+	for _, id := range []string{"sbx-a", "sbx-b", "sbx-c", "other-d"} {
+		putSession(t, mr, SandboxInfo{
+			SandboxID:    id,
+			GrpcEndpoint: "10.0.0.1:1883",
+			CreatedAt:    now,
+			ExpiresAt:    now.Add(time.Hour),
+			Owner:        "alice",
+		})
+	}
+	putSession(t, mr, SandboxInfo{SandboxID: "sbx-expired", CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour), Owner: "alice"})
+	// 其他 owner 的会话不应出现在结果中
+	putSession(t, mr, SandboxInfo{SandboxID: "sbx-bob", CreatedAt: now, ExpiresAt: now.Add(time.Hour), Owner: "bob"})
+
+	var (
+		ids    []string
+		cursor uint64
+	)
+	for page := 0; ; page++ {
+		require.Less(t, page, 10, "cursor must terminate")
+		sessions, next, err := store.ListSessions(context.Background(), "alice", "sbx-", cursor, 1)
+		require.NoError(t, err)
+		for _, s := range sessions {
+			ids = append(ids, s.SandboxID)
+			require.False(t, s.CreatedAt.IsZero())
+			require.False(t, s.ExpiresAt.IsZero())
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	sort.Strings(ids)
+	require.Equal(t, []string{"sbx-a", "sbx-b", "sbx-c"}, ids)
+}
+
+func TestListSessions_EscapesPrefixGlob(t *testing.T) {
+	store, mr := newTestSessionStore(t)
+	now := time.Now().UTC()
+	for _, id := range []string{"sbx-a", "sbx*b"} {
+		putSession(t, mr, SandboxInfo{SandboxID: id, CreatedAt: now, ExpiresAt: now.Add(time.Hour), Owner: "alice"})
+	}
+
+	sessions, _, err := store.ListSessions(context.Background(), "alice", "sbx*", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, "sbx*b", sessions[0].SandboxID)
+
+	sessions, _, err = store.ListSessions(context.Background(), "alice", "*", 0, 10)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
+
+func TestListSessions_Empty(t *testing.T) {
+	store, mr := newTestSessionStore(t)
+	now := time.Now().UTC()
+	putSession(t, mr, SandboxInfo{SandboxID: "sbx-a", CreatedAt: now, ExpiresAt: now.Add(time.Hour), Owner: "alice"})
+
+	for _, owner := range []string{"", "bob"} {
+		sessions, next, err := store.ListSessions(context.Background(), owner, "", 0, 10)
+		require.NoError(t, err)
+		require.Empty(t, sessions)
+		require.Zero(t, next)
+	}
+}
+
+func TestUpdateEndpoint_KeepsTTLAndUnknownFields(t *testing.T) {
+	store, mr := newTestSessionStore(t)
+	key := keyPrefixSession + "sbx-a"
+	require.NoError(t, mr.Set(key, `{"sandbox_id":"sbx-a","grpc_endpoint":"10.0.0.1:1883","idempotency_key":"k-1"}`))
+	mr.SetTTL(key, time.Hour)
+
+	require.NoError(t, store.UpdateEndpoint(context.Background(), "sbx-a", "10.0.0.2:1883"))
+
+	info, err := store.GetSession(context.Background(), "sbx-a")
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.2:1883", info.GrpcEndpoint)
+	raw, err := mr.Get(key)
+	require.NoError(t, err)
+	require.Contains(t, raw, `"idempotency_key":"k-1"`)
+	require.Equal(t, time.Hour, mr.TTL(key))
+
+	require.ErrorIs(t, store.UpdateEndpoint(context.Background(), "missing", "10.0.0.2:1883"), ErrSessionNotFound)
+}
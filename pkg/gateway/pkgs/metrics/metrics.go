@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/db"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "agentland_gateway"
+
+// UnmatchedRoute 为未命中任何路由的请求使用的 route 标签，避免原始路径导致标签基数膨胀
+const UnmatchedRoute = "unmatched"
+
+// 沙箱创建类型
+const (
+	SandboxKindCodeRunner   = "code_runner"
+	SandboxKindAgentSession = "agent_session"
+)
+
+// 会话查询结果
+const (
+	SessionLookupHit   = "hit"
+	SessionLookupMiss  = "miss"
+	SessionLookupError = "error"
+)
+
+// 沙箱创建结果
+const (
+	CreateResultSuccess = "success"
+	CreateResultTimeout = "timeout"
+	CreateResultError   = "error"
+)
+
+var (
+	// HTTPRequests 按路由与状态码统计网关请求数
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Total number of HTTP requests handled by the gateway.",
+	}, []string{"method", "route", "code"})
+
+	// HTTPRequestDuration 网关请求处理耗时
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests handled by the gateway.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// ProxyDuration 转发到沙箱的请求耗时，流式响应按整个流的持续时间计
+	ProxyDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "proxy_duration_seconds",
+		Help:      "Latency of requests proxied to sandboxes.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "code"})
+
+	// SandboxTokenErrors 沙箱 token 签发失败次数
+	SandboxTokenErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sandbox_token_errors_total",
+		Help:      "Total number of sandbox token issuance failures.",
+	})
+
+	// SessionLookups 按 hit/miss/error 统计会话存储查询
+	SessionLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "session_lookups_total",
+		Help:      "Total number of session store lookups by result.",
+	}, []string{"result"})
+
+	// SandboxCreateDuration 创建沙箱（含冷启动等待）的端到端耗时
+	SandboxCreateDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "sandbox_create_duration_seconds",
+		Help:      "End-to-end latency of sandbox creation including cold start.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120},
+	}, []string{"kind", "result"})
+)
+
+// Handler 返回暴露默认注册表的 /metrics 处理器
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveSessionLookup 根据会话查询返回的错误记录 hit/miss/error
+func ObserveSessionLookup(err error) {
+	switch {
+	case err == nil:
+		SessionLookups.WithLabelValues(SessionLookupHit).Inc()
+	case errors.Is(err, db.ErrSessionNotFound):
+		SessionLookups.WithLabelValues(SessionLookupMiss).Inc()
+	default:
+		SessionLookups.WithLabelValues(SessionLookupError).Inc()
+	}
+}
+
+// ObserveSandboxCreate 记录一次沙箱创建的耗时与结果
+func ObserveSandboxCreate(kind, result string, start time.Time) {
+	SandboxCreateDuration.WithLabelValues(kind, result).Observe(time.Since(start).Seconds())
+}
+
+// ObserveProxy 记录一次沙箱转发的耗时与状态码
+func ObserveProxy(route string, code int, start time.Time) {
+	ProxyDuration.WithLabelValues(routeLabel(route), strconv.Itoa(code)).Observe(time.Since(start).Seconds())
+}
+
+// ObserveHTTPRequest 记录一次网关请求的状态码与耗时
+func ObserveHTTPRequest(method, route string, code int, start time.Time) {
+	route = routeLabel(route)
+	HTTPRequests.WithLabelValues(method, route, strconv.Itoa(code)).Inc()
+	HTTPRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+}
+
+func routeLabel(route string) string {
+	if route == "" {
+		return UnmatchedRoute
+	}
+	return route
+}
@@ -9,18 +9,62 @@ type ErrorCode uint
 const (
 	ServerError ErrorCode = iota
 	FormError
+	TooManyRequests
 
 	NoError
 )
 
 var HttpCode = map[ErrorCode]int{
-	FormError:   400,
-	ServerError: 500,
+	FormError:       400,
+	TooManyRequests: 429,
+	ServerError:     500,
 }
 
 var Message = map[ErrorCode]string{
-	ServerError: "Server Error",
-	FormError:   "Form Error",
+	ServerError:     "Server Error",
+	FormError:       "Form Error",
+	TooManyRequests: "Too Many Requests",
+}
+
+// APIErrorCode 为机器可读的错误码，客户端据此区分失败原因而无需匹配 msg 文本
+type APIErrorCode string
+
+const (
+	CodeInvalidArgument   APIErrorCode = "INVALID_ARGUMENT"
+	CodePathEscape        APIErrorCode = "PATH_ESCAPE"
+	CodeProtectedPath     APIErrorCode = "PROTECTED_PATH"
+	CodeNotFound          APIErrorCode = "NOT_FOUND"
+	CodeAlreadyExists     APIErrorCode = "ALREADY_EXISTS"
+	CodeNotDirectory      APIErrorCode = "NOT_DIRECTORY"
+	CodeNotRegularFile    APIErrorCode = "NOT_REGULAR_FILE"
+	CodeDirectoryNotEmpty APIErrorCode = "DIRECTORY_NOT_EMPTY"
+	CodeTooLarge          APIErrorCode = "TOO_LARGE"
+	CodeNotUTF8           APIErrorCode = "NOT_UTF8"
+	CodeInvalidArchive    APIErrorCode = "INVALID_ARCHIVE"
+	CodeQuotaExceeded     APIErrorCode = "QUOTA_EXCEEDED"
+	CodeHashMismatch      APIErrorCode = "HASH_MISMATCH"
+	CodeUnauthenticated   APIErrorCode = "UNAUTHENTICATED"
+	CodeContextBusy       APIErrorCode = "CONTEXT_BUSY"
+	CodeInternal          APIErrorCode = "INTERNAL"
+)
+
+var APIHttpCode = map[APIErrorCode]int{
+	CodeInvalidArgument:   400,
+	CodePathEscape:        403,
+	CodeProtectedPath:     403,
+	CodeNotFound:          404,
+	CodeAlreadyExists:     409,
+	CodeNotDirectory:      400,
+	CodeNotRegularFile:    400,
+	CodeDirectoryNotEmpty: 409,
+	CodeTooLarge:          413,
+	CodeNotUTF8:           422,
+	CodeInvalidArchive:    400,
+	CodeQuotaExceeded:     507,
+	CodeHashMismatch:      409,
+	CodeUnauthenticated:   401,
+	CodeContextBusy:       409,
+	CodeInternal:          500,
 }
 
 func SuccessResponse(c *gin.Context, data any) {
@@ -46,3 +90,15 @@ func ErrorResponse(c *gin.Context, code ErrorCode) {
 		"msg":  msg,
 	})
 }
+
+// APIErrorResponse 返回 {"code":"<错误码>","msg":"<详情>"}，HTTP 状态码由错误码决定，未登记的错误码按 500 处理
+func APIErrorResponse(c *gin.Context, code APIErrorCode, msg string) {
+	httpStatus, ok := APIHttpCode[code]
+	if !ok {
+		httpStatus = 500
+	}
+	c.JSON(httpStatus, gin.H{
+		"code": code,
+		"msg":  msg,
+	})
+}
@@ -82,3 +82,19 @@ func (s *ResponseSuite) TestErrorResponse_Unknown() {
 	expectedJSON, _ := json.Marshal(expectedBody)
 	s.JSONEq(string(expectedJSON), s.recorder.Body.String())
 }
+
+// 测试带错误码的错误体
+func (s *ResponseSuite) TestAPIErrorResponse() {
+	APIErrorResponse(s.ctx, CodeNotFound, "path not found")
+
+	s.Equal(404, s.recorder.Code)
+	s.JSONEq(`{"code":"NOT_FOUND","msg":"path not found"}`, s.recorder.Body.String())
+}
+
+// 测试未登记的错误码
+func (s *ResponseSuite) TestAPIErrorResponse_Unknown() {
+	APIErrorResponse(s.ctx, APIErrorCode("SOMETHING_ELSE"), "boom")
+
+	s.Equal(500, s.recorder.Code)
+	s.JSONEq(`{"code":"SOMETHING_ELSE","msg":"boom"}`, s.recorder.Body.String())
+}
@@ -0,0 +1,213 @@
+package sandboxjwt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/utils"
+)
+
+const (
+	activeKIDDataKey     = "active-kid"
+	privateKeyDataPrefix = "private-"
+	publicKeyDataPrefix  = "public-"
+	retireAtDataPrefix   = "retire-at-"
+	pemDataSuffix        = ".pem"
+)
+
+// kid 会作为 Secret data key 的一部分，只允许 Secret key 合法字符。
+var kidPattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// keyRing 为网关签名密钥集合：active 用于签名，其余密钥在 retireAt 之前仍对外发布用于验签。
+type keyRing struct {
+	active string
+	keys   map[string]*ringKey
+}
+
+type ringKey struct {
+	privatePEM []byte
+	// retireAt 为零值表示不过期（仅 active 密钥）。
+	retireAt time.Time
+}
+
+// parseKeyRing 从 Secret data 解析密钥集合；旧版只有 private.pem 的数据会以 legacyKID 迁移为 active 密钥，
+// 此时 migrated 为 true，调用方需要回写。
+func parseKeyRing(data map[string][]byte, legacyKID string) (ring *keyRing, migrated bool, err error) {
+	ring = &keyRing{keys: make(map[string]*ringKey)}
+	retireAt := make(map[string]time.Time)
+
+	for name, value := range data {
+		switch {
+		case strings.HasPrefix(name, privateKeyDataPrefix) && strings.HasSuffix(name, pemDataSuffix):
+			kid := strings.TrimSuffix(strings.TrimPrefix(name, privateKeyDataPrefix), pemDataSuffix)
+			if !kidPattern.MatchString(kid) {
+				continue
+			}
+			ring.keys[kid] = &ringKey{privatePEM: value}
+		case strings.HasPrefix(name, retireAtDataPrefix):
+			kid := strings.TrimPrefix(name, retireAtDataPrefix)
+			t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(value)))
+			if err != nil {
+				return nil, false, fmt.Errorf("parse %s failed: %w", name, err)
+			}
+			retireAt[kid] = t
+		}
+	}
+	for kid, t := range retireAt {
+		if key, ok := ring.keys[kid]; ok {
+			key.retireAt = t
+		}
+	}
+
+	ring.active = strings.TrimSpace(string(data[activeKIDDataKey]))
+	if ring.active == "" {
+		legacy := data[privateKeyDataKey]
+		if len(legacy) == 0 {
+			if len(ring.keys) > 0 {
+				return nil, false, fmt.Errorf("%s is missing", activeKIDDataKey)
+			}
+			return ring, false, nil
+		}
+		if !kidPattern.MatchString(legacyKID) {
+			return nil, false, fmt.Errorf("invalid kid: %q", legacyKID)
+		}
+		ring.keys[legacyKID] = &ringKey{privatePEM: legacy}
+		ring.active = legacyKID
+		migrated = true
+	}
+
+	active, ok := ring.keys[ring.active]
+	if !ok {
+		return nil, false, fmt.Errorf("active kid %q has no private key", ring.active)
+	}
+	active.retireAt = time.Time{}
+	for kid, key := range ring.keys {
+		if _, err := utils.ParsePrivateKeyPEM(key.privatePEM); err != nil {
+			return nil, false, fmt.Errorf("parse private key %q failed: %w", kid, err)
+		}
+	}
+	return ring, migrated, nil
+}
+
+func (r *keyRing) empty() bool {
+	return len(r.keys) == 0
+}
+
+func (r *keyRing) activeKey() *ringKey {
+	return r.keys[r.active]
+}
+
+// add 加入新密钥并设为 active，原 active 密钥保留到 now+grace 供验签。
+func (r *keyRing) add(kid string, privatePEM []byte, now time.Time, grace time.Duration) error {
+	if !kidPattern.MatchString(kid) {
+		return fmt.Errorf("invalid kid: %q", kid)
+	}
+	if _, exists := r.keys[kid]; exists {
+		return fmt.Errorf("kid %q already exists", kid)
+	}
+	if previous, ok := r.keys[r.active]; ok {
+		previous.retireAt = now.Add(grace)
+	}
+	r.keys[kid] = &ringKey{privatePEM: privatePEM}
+	r.active = kid
+	return nil
+}
+
+// prune 删除已过宽限期的非 active 密钥，返回是否有变更。
+func (r *keyRing) prune(now time.Time) bool {
+	changed := false
+	for kid, key := range r.keys {
+		if kid == r.active || key.retireAt.IsZero() || now.Before(key.retireAt) {
+			continue
+		}
+		delete(r.keys, kid)
+		changed = true
+	}
+	return changed
+}
+
+// kids 返回 active 在前、其余按 kid 排序的列表。
+func (r *keyRing) kids() []string {
+	others := slices.Sorted(maps.Keys(r.keys))
+	others = slices.DeleteFunc(others, func(kid string) bool { return kid == r.active })
+	return append([]string{r.active}, others...)
+}
+
+// identityData 编码为身份 Secret data，同时保留 private.pem/public.pem 兼容旧版读取方。
+func (r *keyRing) identityData() (map[string][]byte, error) {
+	active := r.activeKey()
+	publicPEM, err := publicKeyPEMFromPrivatePEM(active.privatePEM)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string][]byte{
+		activeKIDDataKey:  []byte(r.active),
+		privateKeyDataKey: active.privatePEM,
+		publicKeyDataKey:  publicPEM,
+	}
+	for kid, key := range r.keys {
+		data[privateKeyDataPrefix+kid+pemDataSuffix] = key.privatePEM
+		if !key.retireAt.IsZero() {
+			data[retireAtDataPrefix+kid] = []byte(key.retireAt.UTC().Format(time.RFC3339))
+		}
+	}
+	return data, nil
+}
+
+// publicData 编码为公钥 Secret data：public.pem 为 active 公钥，public-<kid>.pem 为全部有效公钥。
+func (r *keyRing) publicData() (map[string][]byte, error) {
+	data := make(map[string][]byte, len(r.keys)+1)
+	for kid, key := range r.keys {
+		publicPEM, err := publicKeyPEMFromPrivatePEM(key.privatePEM)
+		if err != nil {
+			return nil, fmt.Errorf("derive public key %q failed: %w", kid, err)
+		}
+		data[publicKeyDataPrefix+kid+pemDataSuffix] = publicPEM
+		if kid == r.active {
+			data[publicKeyDataKey] = publicPEM
+		}
+	}
+	return data, nil
+}
+
+func (r *keyRing) publicKeys() ([]cachedPublicKey, error) {
+	keys := make([]cachedPublicKey, 0, len(r.keys))
+	for _, kid := range r.kids() {
+		key := r.keys[kid]
+		privateKey, err := utils.ParsePrivateKeyPEM(key.privatePEM)
+		if err != nil {
+			return nil, fmt.Errorf("parse private key %q failed: %w", kid, err)
+		}
+		jwk, err := utils.NewJWK(kid, privateKey.Public())
+		if err != nil {
+			return nil, err
+		}
+		cached := cachedPublicKey{JWK: jwk}
+		if !key.retireAt.IsZero() {
+			retireAt := key.retireAt.UTC()
+			cached.RetireAt = &retireAt
+		}
+		keys = append(keys, cached)
+	}
+	return keys, nil
+}
+
+func dataEqual(a, b map[string][]byte) bool {
+	return maps.EqualFunc(a, b, bytes.Equal)
+}
+
+func newKID(now time.Time) (string, error) {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return now.UTC().Format("20060102150405") + "-" + hex.EncodeToString(buf), nil
+}
@@ -1,17 +1,24 @@
 package sandboxjwt
 
 import (
-	"bytes"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/Fl0rencess720/agentland/pkg/common/utils"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -25,6 +32,12 @@ const (
 	defaultPublicSecretNamespace = "agentland-sandboxes"
 	defaultLocalPrivateKeyPath   = "/tmp/agentland/jwt/private.pem"
 	defaultRSAKeyBits            = 2048
+	defaultKID                   = "default"
+	defaultRotationGracePeriod   = 30 * time.Minute
+	maxKeyRingUpdateRetries      = 3
+
+	localKeyRingDirName    = "keyring"
+	publicKeyCacheFileName = "jwks.json"
 
 	privateKeyDataKey = "private.pem"
 	publicKeyDataKey  = "public.pem"
@@ -40,48 +53,207 @@ type BootstrapConfig struct {
 	PublicSecretNamespace   string
 	LocalPrivateKeyPath     string
 	RSAKeyBits              int
+	// Algorithm 决定新生成密钥的类型（RS256/ES256/EdDSA），默认 RS256。
+	Algorithm string
+	// KID 为首个密钥（及旧版单密钥迁移）使用的 kid，默认 "default"；轮换生成的 kid 带时间戳。
+	KID string
+}
+
+var errKeyRingConflict = errors.New("signing key ring was modified concurrently")
+
+// SigningKey 描述网关当前用于签发沙箱 token 的 active 密钥。
+type SigningKey struct {
+	PrivateKeyPath string
+	KID            string
 }
 
-func EnsureGatewaySigningKey(ctx context.Context, cfg BootstrapConfig) (string, error) {
+// EnsureGatewaySigningKey 加载（必要时生成）签名密钥集合，清理过期密钥、发布公钥，
+// 并把 active 私钥缓存到本地供 Signer 使用。
+func EnsureGatewaySigningKey(ctx context.Context, cfg BootstrapConfig) (*SigningKey, error) {
 	resolved := withDefaults(cfg)
 
-	restCfg, err := rest.InClusterConfig()
+	store, clientset, err := newKeyRingStore(resolved)
+	if err != nil {
+		return nil, err
+	}
+	return ensureSigningKey(ctx, store, clientset, resolved, time.Now())
+}
+
+// RotateSigningKey 生成新密钥并设为 active，旧 active 密钥在 grace 时间内继续对外发布用于验签，
+// 返回新的 kid。grace 不大于 0 时使用 defaultRotationGracePeriod。
+func RotateSigningKey(ctx context.Context, cfg BootstrapConfig, grace time.Duration) (string, error) {
+	resolved := withDefaults(cfg)
+
+	store, clientset, err := newKeyRingStore(resolved)
 	if err != nil {
-		if data, readErr := os.ReadFile(resolved.LocalPrivateKeyPath); readErr == nil {
-			if _, parseErr := parseRSAPrivateKeyPEM(data); parseErr == nil {
-				return resolved.LocalPrivateKeyPath, nil
+		return "", err
+	}
+	key, err := rotateSigningKey(ctx, store, clientset, resolved, grace, time.Now())
+	if err != nil {
+		return "", err
+	}
+	return key.KID, nil
+}
+
+// RefreshSigningKey 重新读取签名密钥集合并刷新本地缓存的 active 私钥与公钥集合，不修改密钥集合。
+// 运行中的网关定期调用，其他实例或 -rotate-sandbox-jwt-key 轮换密钥后无需重启即可切换到新的 active 密钥。
+func RefreshSigningKey(ctx context.Context, cfg BootstrapConfig) (*SigningKey, error) {
+	resolved := withDefaults(cfg)
+
+	store, _, err := newKeyRingStore(resolved)
+	if err != nil {
+		return nil, err
+	}
+	return refreshSigningKey(ctx, store, resolved)
+}
+
+func ensureSigningKey(ctx context.Context, store keyRingStore, clientset kubernetes.Interface, cfg BootstrapConfig, now time.Time) (*SigningKey, error) {
+	ring, err := updateKeyRing(ctx, store, cfg, func(ring *keyRing) (bool, error) {
+		if ring.empty() {
+			privatePEM, err := generatePrivateKeyPEM(cfg.Algorithm, cfg.RSAKeyBits)
+			if err != nil {
+				return false, fmt.Errorf("generate identity key failed: %w", err)
 			}
+			ring.keys[cfg.KID] = &ringKey{privatePEM: privatePEM}
+			ring.active = cfg.KID
+			return true, nil
 		}
 
-		privatePEM, err := generatePrivateKeyPEM(resolved.RSAKeyBits)
+		changed := ring.prune(now)
+		activeKey, err := utils.ParsePrivateKeyPEM(ring.activeKey().privatePEM)
 		if err != nil {
-			return "", fmt.Errorf("generate local private key failed: %w", err)
+			return false, err
 		}
-		if err := writePrivateKeyFile(resolved.LocalPrivateKeyPath, privatePEM); err != nil {
-			return "", fmt.Errorf("write local private key failed: %w", err)
+		if !keyMatchesAlgorithm(activeKey, cfg.Algorithm) {
+			if err := addGeneratedKey(ring, cfg, defaultRotationGracePeriod, now); err != nil {
+				return false, err
+			}
+			changed = true
 		}
-		return resolved.LocalPrivateKeyPath, nil
+		return changed, nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return publishKeyRing(ctx, clientset, cfg, ring)
+}
 
-	clientset, err := kubernetes.NewForConfig(restCfg)
+func rotateSigningKey(ctx context.Context, store keyRingStore, clientset kubernetes.Interface, cfg BootstrapConfig, grace time.Duration, now time.Time) (*SigningKey, error) {
+	if grace <= 0 {
+		grace = defaultRotationGracePeriod
+	}
+
+	ring, err := updateKeyRing(ctx, store, cfg, func(ring *keyRing) (bool, error) {
+		ring.prune(now)
+		if err := addGeneratedKey(ring, cfg, grace, now); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("create kubernetes client failed: %w", err)
+		return nil, err
 	}
+	return publishKeyRing(ctx, clientset, cfg, ring)
+}
 
-	privatePEM, publicPEM, err := ensureIdentitySecret(ctx, clientset, resolved)
+func refreshSigningKey(ctx context.Context, store keyRingStore, cfg BootstrapConfig) (*SigningKey, error) {
+	data, err := store.load(ctx)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	ring, _, err := parseKeyRing(data, cfg.KID)
+	if err != nil {
+		return nil, fmt.Errorf("parse signing key ring failed: %w", err)
+	}
+	if ring.empty() {
+		return nil, fmt.Errorf("signing key ring is empty")
 	}
+	return cacheKeyRing(cfg, ring)
+}
 
-	if err := ensurePublicKeySecret(ctx, clientset, resolved.PublicSecretNamespace, resolved.PublicSecretName, publicPEM); err != nil {
-		return "", err
+func addGeneratedKey(ring *keyRing, cfg BootstrapConfig, grace time.Duration, now time.Time) error {
+	kid, err := newKID(now)
+	if err != nil {
+		return fmt.Errorf("generate kid failed: %w", err)
+	}
+	privatePEM, err := generatePrivateKeyPEM(cfg.Algorithm, cfg.RSAKeyBits)
+	if err != nil {
+		return fmt.Errorf("generate identity key failed: %w", err)
 	}
+	return ring.add(kid, privatePEM, now, grace)
+}
+
+// updateKeyRing 读取密钥集合并执行 mutate，有变更时回写；存储返回冲突时重新读取重试。
+func updateKeyRing(ctx context.Context, store keyRingStore, cfg BootstrapConfig, mutate func(*keyRing) (bool, error)) (*keyRing, error) {
+	for attempt := 0; ; attempt++ {
+		data, err := store.load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ring, migrated, err := parseKeyRing(data, cfg.KID)
+		if err != nil {
+			return nil, fmt.Errorf("parse signing key ring failed: %w", err)
+		}
 
-	if err := writePrivateKeyFile(resolved.LocalPrivateKeyPath, privatePEM); err != nil {
-		return "", fmt.Errorf("write cached private key failed: %w", err)
+		changed, err := mutate(ring)
+		if err != nil {
+			return nil, err
+		}
+		if !changed && !migrated {
+			return ring, nil
+		}
+
+		identityData, err := ring.identityData()
+		if err != nil {
+			return nil, err
+		}
+		err = store.save(ctx, identityData)
+		if err == nil {
+			return ring, nil
+		}
+		if !errors.Is(err, errKeyRingConflict) || attempt >= maxKeyRingUpdateRetries {
+			return nil, err
+		}
 	}
+}
 
-	return resolved.LocalPrivateKeyPath, nil
+// publishKeyRing 发布全部有效公钥，并把 active 私钥和公钥集合缓存到本地。
+func publishKeyRing(ctx context.Context, clientset kubernetes.Interface, cfg BootstrapConfig, ring *keyRing) (*SigningKey, error) {
+	if clientset != nil {
+		publicData, err := ring.publicData()
+		if err != nil {
+			return nil, err
+		}
+		if err := ensurePublicKeySecret(ctx, clientset, cfg.PublicSecretNamespace, cfg.PublicSecretName, publicData); err != nil {
+			return nil, err
+		}
+	}
+
+	return cacheKeyRing(cfg, ring)
+}
+
+// cacheKeyRing 把 active 私钥和公钥集合缓存到本地，供 Signer 与 JWKS 接口读取。
+// active kid 与配置的 kid 不同时写入 PEM 头，旧版单密钥文件保持原样。
+func cacheKeyRing(cfg BootstrapConfig, ring *keyRing) (*SigningKey, error) {
+	privatePEM := ring.activeKey().privatePEM
+	if ring.active != cfg.KID {
+		var err error
+		if privatePEM, err = withKIDHeader(privatePEM, ring.active); err != nil {
+			return nil, err
+		}
+	}
+	if err := writePrivateKeyFile(cfg.LocalPrivateKeyPath, privatePEM); err != nil {
+		return nil, fmt.Errorf("write cached private key failed: %w", err)
+	}
+	publicKeys, err := ring.publicKeys()
+	if err != nil {
+		return nil, err
+	}
+	if err := writePublicKeyCache(publicKeyCachePath(cfg.LocalPrivateKeyPath), publicKeys); err != nil {
+		return nil, fmt.Errorf("write cached public keys failed: %w", err)
+	}
+
+	return &SigningKey{PrivateKeyPath: cfg.LocalPrivateKeyPath, KID: ring.active}, nil
 }
 
 func withDefaults(cfg BootstrapConfig) BootstrapConfig {
@@ -104,6 +276,12 @@ func withDefaults(cfg BootstrapConfig) BootstrapConfig {
 	if resolved.RSAKeyBits <= 0 {
 		resolved.RSAKeyBits = defaultRSAKeyBits
 	}
+	if strings.TrimSpace(resolved.Algorithm) == "" {
+		resolved.Algorithm = utils.AlgRS256
+	}
+	if strings.TrimSpace(resolved.KID) == "" {
+		resolved.KID = defaultKID
+	}
 	return resolved
 }
 
@@ -122,79 +300,158 @@ func currentNamespaceOrDefault(defaultNS string) string {
 	return ns
 }
 
-func ensureIdentitySecret(ctx context.Context, clientset kubernetes.Interface, cfg BootstrapConfig) ([]byte, []byte, error) {
-	secretClient := clientset.CoreV1().Secrets(cfg.IdentitySecretNamespace)
-	secret, err := secretClient.Get(ctx, cfg.IdentitySecretName, metav1.GetOptions{})
-	if err == nil {
-		privatePEM, ok := secret.Data[privateKeyDataKey]
-		if !ok || len(privatePEM) == 0 {
-			return nil, nil, fmt.Errorf("identity secret %s/%s missing %s", cfg.IdentitySecretNamespace, cfg.IdentitySecretName, privateKeyDataKey)
-		}
-		publicPEM, err := publicKeyPEMFromPrivatePEM(privatePEM)
-		if err != nil {
-			return nil, nil, fmt.Errorf("derive public key from identity secret failed: %w", err)
-		}
+// keyRingStore 持久化身份密钥集合；save 在并发修改时返回 errKeyRingConflict。
+type keyRingStore interface {
+	load(ctx context.Context) (map[string][]byte, error)
+	save(ctx context.Context, data map[string][]byte) error
+}
 
-		if !bytes.Equal(secret.Data[publicKeyDataKey], publicPEM) {
-			updated := secret.DeepCopy()
-			if updated.Data == nil {
-				updated.Data = make(map[string][]byte)
-			}
-			updated.Data[publicKeyDataKey] = publicPEM
-			if _, err := secretClient.Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
-				return nil, nil, fmt.Errorf("update identity secret public key failed: %w", err)
-			}
-		}
-		return privatePEM, publicPEM, nil
+func newKeyRingStore(cfg BootstrapConfig) (keyRingStore, kubernetes.Interface, error) {
+	restCfg, err := rest.InClusterConfig()
+	if err != nil {
+		return &dirKeyRingStore{
+			dir:              filepath.Join(filepath.Dir(cfg.LocalPrivateKeyPath), localKeyRingDirName),
+			legacyPrivateKey: cfg.LocalPrivateKeyPath,
+		}, nil, nil
 	}
 
-	if !apierrors.IsNotFound(err) {
-		return nil, nil, fmt.Errorf("get identity secret %s/%s failed: %w", cfg.IdentitySecretNamespace, cfg.IdentitySecretName, err)
+	clientset, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create kubernetes client failed: %w", err)
 	}
+	return &secretKeyRingStore{
+		client:    clientset,
+		namespace: cfg.IdentitySecretNamespace,
+		name:      cfg.IdentitySecretName,
+	}, clientset, nil
+}
+
+type secretKeyRingStore struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+
+	current *corev1.Secret
+}
 
-	privatePEM, publicPEM, err := generateRSAKeyPairPEM(cfg.RSAKeyBits)
+func (s *secretKeyRingStore) load(ctx context.Context) (map[string][]byte, error) {
+	secret, err := s.client.CoreV1().Secrets(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		s.current = nil
+		return nil, nil
+	}
 	if err != nil {
-		return nil, nil, fmt.Errorf("generate identity key pair failed: %w", err)
+		return nil, fmt.Errorf("get identity secret %s/%s failed: %w", s.namespace, s.name, err)
 	}
+	s.current = secret
+	return secret.Data, nil
+}
 
-	newSecret := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      cfg.IdentitySecretName,
-			Namespace: cfg.IdentitySecretNamespace,
-			Labels: map[string]string{
-				"app.kubernetes.io/name":    "gateway",
-				"app.kubernetes.io/part-of": "agentland",
+func (s *secretKeyRingStore) save(ctx context.Context, data map[string][]byte) error {
+	secretClient := s.client.CoreV1().Secrets(s.namespace)
+	if s.current == nil {
+		newSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      s.name,
+				Namespace: s.namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/name":    "gateway",
+					"app.kubernetes.io/part-of": "agentland",
+				},
 			},
-		},
-		Type: corev1.SecretTypeOpaque,
-		Data: map[string][]byte{
-			privateKeyDataKey: privatePEM,
-			publicKeyDataKey:  publicPEM,
-		},
+			Type: corev1.SecretTypeOpaque,
+			Data: data,
+		}
+		if _, err := secretClient.Create(ctx, newSecret, metav1.CreateOptions{}); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				return fmt.Errorf("create identity secret %s/%s: %w", s.namespace, s.name, errKeyRingConflict)
+			}
+			return fmt.Errorf("create identity secret %s/%s failed: %w", s.namespace, s.name, err)
+		}
+		return nil
 	}
 
-	if _, err := secretClient.Create(ctx, newSecret, metav1.CreateOptions{}); err != nil {
-		if apierrors.IsAlreadyExists(err) {
-			return ensureIdentitySecret(ctx, clientset, cfg)
+	if dataEqual(s.current.Data, data) {
+		return nil
+	}
+	updated := s.current.DeepCopy()
+	updated.Data = data
+	if _, err := secretClient.Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		if apierrors.IsConflict(err) {
+			return fmt.Errorf("update identity secret %s/%s: %w", s.namespace, s.name, errKeyRingConflict)
+		}
+		return fmt.Errorf("update identity secret %s/%s failed: %w", s.namespace, s.name, err)
+	}
+	return nil
+}
+
+// dirKeyRingStore 用于集群外运行，每个 data key 对应目录下一个文件。
+type dirKeyRingStore struct {
+	dir string
+	// legacyPrivateKey 为旧版单密钥缓存路径，目录为空时作为 private.pem 迁移。
+	legacyPrivateKey string
+}
+
+func (s *dirKeyRingStore) load(_ context.Context) (map[string][]byte, error) {
+	data := make(map[string][]byte)
+	entries, err := os.ReadDir(s.dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read local key ring failed: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read local key ring failed: %w", err)
 		}
-		return nil, nil, fmt.Errorf("create identity secret %s/%s failed: %w", cfg.IdentitySecretNamespace, cfg.IdentitySecretName, err)
+		data[entry.Name()] = content
 	}
 
-	return privatePEM, publicPEM, nil
+	if len(data) == 0 && s.legacyPrivateKey != "" {
+		if content, err := os.ReadFile(s.legacyPrivateKey); err == nil {
+			if _, parseErr := utils.ParsePrivateKeyPEM(content); parseErr == nil {
+				data[privateKeyDataKey] = content
+			}
+		}
+	}
+	return data, nil
 }
 
-func ensurePublicKeySecret(ctx context.Context, clientset kubernetes.Interface, namespace, secretName string, publicPEM []byte) error {
+func (s *dirKeyRingStore) save(_ context.Context, data map[string][]byte) error {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+	for name, content := range data {
+		if err := os.WriteFile(filepath.Join(s.dir, name), content, 0o600); err != nil {
+			return err
+		}
+	}
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if _, ok := data[entry.Name()]; !ok && entry.Type().IsRegular() {
+			if err := os.Remove(filepath.Join(s.dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func ensurePublicKeySecret(ctx context.Context, clientset kubernetes.Interface, namespace, secretName string, publicData map[string][]byte) error {
 	secretClient := clientset.CoreV1().Secrets(namespace)
 	secret, err := secretClient.Get(ctx, secretName, metav1.GetOptions{})
 	if err == nil {
-		if bytes.Equal(secret.Data[publicKeyDataKey], publicPEM) {
+		if dataEqual(secret.Data, publicData) {
 			return nil
 		}
 		updated := secret.DeepCopy()
-		if updated.Data == nil {
-			updated.Data = make(map[string][]byte)
-		}
-		updated.Data[publicKeyDataKey] = publicPEM
+		updated.Data = publicData
 		if _, err := secretClient.Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
 			return fmt.Errorf("update public key secret %s/%s failed: %w", namespace, secretName, err)
 		}
@@ -214,11 +471,11 @@ func ensurePublicKeySecret(ctx context.Context, clientset kubernetes.Interface,
 			},
 		},
 		Type: corev1.SecretTypeOpaque,
-		Data: map[string][]byte{publicKeyDataKey: publicPEM},
+		Data: publicData,
 	}
 	if _, err := secretClient.Create(ctx, newSecret, metav1.CreateOptions{}); err != nil {
 		if apierrors.IsAlreadyExists(err) {
-			return ensurePublicKeySecret(ctx, clientset, namespace, secretName, publicPEM)
+			return ensurePublicKeySecret(ctx, clientset, namespace, secretName, publicData)
 		}
 		return fmt.Errorf("create public key secret %s/%s failed: %w", namespace, secretName, err)
 	}
@@ -230,63 +487,137 @@ func writePrivateKeyFile(path string, privatePEM []byte) error {
 	if err := os.MkdirAll(dir, 0o700); err != nil {
 		return err
 	}
-	return os.WriteFile(path, privatePEM, 0o600)
+	return writeFileAtomic(path, privatePEM)
 }
 
-func generatePrivateKeyPEM(bits int) ([]byte, error) {
-	privateKey, err := rsa.GenerateKey(rand.Reader, bits)
+// writeFileAtomic 先写临时文件再重命名，避免 Signer 或 JWKS 接口读到写入中途的内容。
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
 	if err != nil {
-		return nil, err
+		return err
 	}
-	privateBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
-	if err != nil {
-		return nil, err
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
 	}
-	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateBytes}), nil
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
 }
 
-func generateRSAKeyPairPEM(bits int) ([]byte, []byte, error) {
-	privatePEM, err := generatePrivateKeyPEM(bits)
+// withKIDHeader 在私钥 PEM 头中写入 kid，使私钥与 kid 随同一个文件原子更新。
+func withKIDHeader(privatePEM []byte, kid string) ([]byte, error) {
+	block, _ := pem.Decode(privatePEM)
+	if block == nil {
+		return nil, fmt.Errorf("invalid private key pem")
+	}
+	headers := make(map[string]string, len(block.Headers)+1)
+	for key, value := range block.Headers {
+		headers[key] = value
+	}
+	headers[utils.PrivateKeyPEMKIDHeader] = kid
+	block.Headers = headers
+	return pem.EncodeToMemory(block), nil
+}
+
+func generatePrivateKeyPEM(alg string, bits int) ([]byte, error) {
+	var privateKey crypto.Signer
+	var err error
+	switch alg {
+	case utils.AlgRS256:
+		privateKey, err = rsa.GenerateKey(rand.Reader, bits)
+	case utils.AlgES256:
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case utils.AlgEdDSA:
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", alg)
+	}
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	publicPEM, err := publicKeyPEMFromPrivatePEM(privatePEM)
+	privateBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	return privatePEM, publicPEM, nil
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateBytes}), nil
 }
 
 func publicKeyPEMFromPrivatePEM(privatePEM []byte) ([]byte, error) {
-	privateKey, err := parseRSAPrivateKeyPEM(privatePEM)
+	privateKey, err := utils.ParsePrivateKeyPEM(privatePEM)
 	if err != nil {
 		return nil, err
 	}
-	publicBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	publicBytes, err := x509.MarshalPKIXPublicKey(privateKey.Public())
 	if err != nil {
 		return nil, err
 	}
 	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicBytes}), nil
 }
 
-func parseRSAPrivateKeyPEM(data []byte) (*rsa.PrivateKey, error) {
-	block, rest := pem.Decode(data)
-	if block == nil {
-		return nil, fmt.Errorf("invalid private key pem")
+func keyMatchesAlgorithm(key crypto.Signer, alg string) bool {
+	keyAlg, err := utils.KeyAlgorithm(key.Public())
+	return err == nil && keyAlg == alg
+}
+
+// cachedPublicKey 为本地缓存的公钥，RetireAt 之后不再对外发布。
+type cachedPublicKey struct {
+	utils.JWK
+	RetireAt *time.Time `json:"retire_at,omitempty"`
+}
+
+func publicKeyCachePath(privateKeyPath string) string {
+	return filepath.Join(filepath.Dir(privateKeyPath), publicKeyCacheFileName)
+}
+
+func writePublicKeyCache(path string, keys []cachedPublicKey) error {
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
 	}
-	if len(bytes.TrimSpace(rest)) > 0 {
-		return nil, fmt.Errorf("extra data found in private key pem")
+	return writeFileAtomic(path, data)
+}
+
+// PublicJWKS 返回当前有效的验签公钥集合，供沙箱按 kid 拉取。
+// 优先读取 EnsureGatewaySigningKey 写入的本地公钥缓存，缺失时退化为由 active 私钥导出。
+func PublicJWKS(privateKeyPath, kid string) (utils.JWKS, error) {
+	return publicJWKSAt(privateKeyPath, kid, time.Now())
+}
+
+func publicJWKSAt(privateKeyPath, kid string, now time.Time) (utils.JWKS, error) {
+	if data, err := os.ReadFile(publicKeyCachePath(privateKeyPath)); err == nil {
+		var cached []cachedPublicKey
+		if err := json.Unmarshal(data, &cached); err != nil {
+			return utils.JWKS{}, fmt.Errorf("decode cached public keys failed: %w", err)
+		}
+		set := utils.JWKS{Keys: make([]utils.JWK, 0, len(cached))}
+		for _, key := range cached {
+			if key.RetireAt != nil && !now.Before(*key.RetireAt) {
+				continue
+			}
+			set.Keys = append(set.Keys, key.JWK)
+		}
+		return set, nil
 	}
-	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
-		return key, nil
+
+	data, err := os.ReadFile(privateKeyPath)
+	if err != nil {
+		return utils.JWKS{}, fmt.Errorf("read private key file failed: %w", err)
 	}
-	keyAny, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	privateKey, err := utils.ParsePrivateKeyPEM(data)
 	if err != nil {
-		return nil, fmt.Errorf("parse private key failed: %w", err)
+		return utils.JWKS{}, err
 	}
-	key, ok := keyAny.(*rsa.PrivateKey)
-	if !ok {
-		return nil, fmt.Errorf("private key is not RSA")
+	jwk, err := utils.NewJWK(kid, privateKey.Public())
+	if err != nil {
+		return utils.JWKS{}, err
 	}
-	return key, nil
+	return utils.JWKS{Keys: []utils.JWK{jwk}}, nil
 }
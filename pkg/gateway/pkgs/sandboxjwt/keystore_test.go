@@ -0,0 +1,267 @@
+package sandboxjwt
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/testutil"
+	"github.com/Fl0rencess720/agentland/pkg/common/utils"
+	"github.com/stretchr/testify/suite"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestKeystoreSuite(t *testing.T) {
+	suite.Run(t, &KeystoreSuite{})
+}
+
+type KeystoreSuite struct {
+	suite.Suite
+	clientset *fake.Clientset
+	store     *secretKeyRingStore
+	cfg       BootstrapConfig
+	now       time.Time
+}
+
+func (s *KeystoreSuite) SetupTest() {
+	s.clientset = fake.NewClientset()
+	s.cfg = withDefaults(BootstrapConfig{
+		IdentitySecretNamespace: "agentland-system",
+		LocalPrivateKeyPath:     filepath.Join(s.T().TempDir(), "private.pem"),
+	})
+	s.store = &secretKeyRingStore{
+		client:    s.clientset,
+		namespace: s.cfg.IdentitySecretNamespace,
+		name:      s.cfg.IdentitySecretName,
+	}
+	s.now = time.Now()
+}
+
+func (s *KeystoreSuite) getSecret(namespace, name string) *corev1.Secret {
+	secret, err := s.clientset.CoreV1().Secrets(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	s.Require().NoError(err)
+	return secret
+}
+
+func (s *KeystoreSuite) newSigner(key *SigningKey) *utils.Signer {
+	signer, err := utils.NewSignerFromConfig(utils.SignerConfig{
+		PrivateKeyPath: key.PrivateKeyPath,
+		Issuer:         "agentland-gateway",
+		Audience:       "sandbox",
+		KID:            key.KID,
+		TTL:            5 * time.Minute,
+	})
+	s.Require().NoError(err)
+	return signer
+}
+
+// verifyWithJWKS 以 at 时刻网关发布的 JWKS 验证 token。
+func (s *KeystoreSuite) verifyWithJWKS(token string, at time.Time) error {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		keys, err := publicJWKSAt(s.cfg.LocalPrivateKeyPath, s.cfg.KID, at)
+		s.Require().NoError(err)
+		s.Require().NoError(json.NewEncoder(w).Encode(keys))
+	}))
+	defer server.Close()
+
+	verifier, err := utils.NewVerifierFromJWKS(server.URL, utils.VerifierConfig{
+		Issuer:    "agentland-gateway",
+		Audience:  "sandbox",
+		ClockSkew: 30 * time.Second,
+	})
+	s.Require().NoError(err)
+	_, err = verifier.Verify(token)
+	return err
+}
+
+func (s *KeystoreSuite) TestEnsureSigningKey_CreatesIdentityAndPublicSecrets() {
+	key, err := ensureSigningKey(context.Background(), s.store, s.clientset, s.cfg, s.now)
+	s.Require().NoError(err)
+	s.Equal(defaultKID, key.KID)
+	s.Equal(s.cfg.LocalPrivateKeyPath, key.PrivateKeyPath)
+
+	identity := s.getSecret(s.cfg.IdentitySecretNamespace, s.cfg.IdentitySecretName)
+	s.Equal(defaultKID, string(identity.Data[activeKIDDataKey]))
+	s.NotEmpty(identity.Data["private-default.pem"])
+
+	public := s.getSecret(s.cfg.PublicSecretNamespace, s.cfg.PublicSecretName)
+	s.NotEmpty(public.Data[publicKeyDataKey])
+	s.Equal(public.Data[publicKeyDataKey], public.Data["public-default.pem"])
+
+	again, err := ensureSigningKey(context.Background(), s.store, s.clientset, s.cfg, s.now)
+	s.Require().NoError(err)
+	s.Equal(key.KID, again.KID)
+}
+
+func (s *KeystoreSuite) TestEnsureSigningKey_MigratesLegacySecret() {
+	privatePath, _, err := testutil.WriteTestRSAKeys(s.T().TempDir())
+	s.Require().NoError(err)
+	legacyPEM, err := os.ReadFile(privatePath)
+	s.Require().NoError(err)
+
+	_, err = s.clientset.CoreV1().Secrets(s.cfg.IdentitySecretNamespace).Create(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: s.cfg.IdentitySecretName, Namespace: s.cfg.IdentitySecretNamespace},
+		Data:       map[string][]byte{privateKeyDataKey: legacyPEM},
+	}, metav1.CreateOptions{})
+	s.Require().NoError(err)
+
+	key, err := ensureSigningKey(context.Background(), s.store, s.clientset, s.cfg, s.now)
+	s.Require().NoError(err)
+	s.Equal(defaultKID, key.KID)
+
+	identity := s.getSecret(s.cfg.IdentitySecretNamespace, s.cfg.IdentitySecretName)
+	s.Equal(defaultKID, string(identity.Data[activeKIDDataKey]))
+	s.Equal(legacyPEM, identity.Data["private-default.pem"])
+	s.Equal(legacyPEM, identity.Data[privateKeyDataKey])
+}
+
+func (s *KeystoreSuite) TestRotateSigningKey_OldKIDVerifiesDuringGraceWindow() {
+	oldKey, err := ensureSigningKey(context.Background(), s.store, s.clientset, s.cfg, s.now)
+	s.Require().NoError(err)
+	oldToken, err := s.newSigner(oldKey).Sign("session-abc", "", 0)
+	s.Require().NoError(err)
+
+	newKey, err := rotateSigningKey(context.Background(), s.store, s.clientset, s.cfg, 10*time.Minute, s.now)
+	s.Require().NoError(err)
+	s.NotEqual(oldKey.KID, newKey.KID)
+	newToken, err := s.newSigner(newKey).Sign("session-abc", "", 0)
+	s.Require().NoError(err)
+
+	public := s.getSecret(s.cfg.PublicSecretNamespace, s.cfg.PublicSecretName)
+	s.Contains(public.Data, "public-"+oldKey.KID+".pem")
+	s.Contains(public.Data, "public-"+newKey.KID+".pem")
+
+	withinGrace := s.now.Add(5 * time.Minute)
+	s.NoError(s.verifyWithJWKS(oldToken, withinGrace))
+	s.NoError(s.verifyWithJWKS(newToken, withinGrace))
+
+	afterGrace := s.now.Add(11 * time.Minute)
+	s.ErrorContains(s.verifyWithJWKS(oldToken, afterGrace), "unknown kid")
+	s.NoError(s.verifyWithJWKS(newToken, afterGrace))
+
+	key, err := ensureSigningKey(context.Background(), s.store, s.clientset, s.cfg, afterGrace)
+	s.Require().NoError(err)
+	s.Equal(newKey.KID, key.KID)
+
+	identity := s.getSecret(s.cfg.IdentitySecretNamespace, s.cfg.IdentitySecretName)
+	s.NotContains(identity.Data, "private-"+oldKey.KID+".pem")
+	public = s.getSecret(s.cfg.PublicSecretNamespace, s.cfg.PublicSecretName)
+	s.NotContains(public.Data, "public-"+oldKey.KID+".pem")
+}
+
+// mountPublicSecret 模拟 kubelet 将公钥 Secret 挂载为目录，返回 public.pem 路径。
+func (s *KeystoreSuite) mountPublicSecret(dir string) string {
+	public := s.getSecret(s.cfg.PublicSecretNamespace, s.cfg.PublicSecretName)
+	for name, data := range public.Data {
+		s.Require().NoError(os.WriteFile(filepath.Join(dir, name), data, 0o600))
+	}
+	return filepath.Join(dir, publicKeyDataKey)
+}
+
+func (s *KeystoreSuite) TestRotateSigningKey_FileVerifierAcceptsRetainedKID() {
+	oldKey, err := ensureSigningKey(context.Background(), s.store, s.clientset, s.cfg, s.now)
+	s.Require().NoError(err)
+	oldToken, err := s.newSigner(oldKey).Sign("session-abc", "", 0)
+	s.Require().NoError(err)
+
+	dir := s.T().TempDir()
+	verifier, err := utils.NewVerifierFromConfig(utils.VerifierConfig{
+		PublicKeyPath:     s.mountPublicSecret(dir),
+		Issuer:            "agentland-gateway",
+		Audience:          "sandbox",
+		ClockSkew:         30 * time.Second,
+		KeyReloadInterval: time.Nanosecond,
+	})
+	s.Require().NoError(err)
+	_, err = verifier.Verify(oldToken)
+	s.Require().NoError(err)
+
+	newKey, err := rotateSigningKey(context.Background(), s.store, s.clientset, s.cfg, 10*time.Minute, s.now)
+	s.Require().NoError(err)
+	newToken, err := s.newSigner(newKey).Sign("session-abc", "", 0)
+	s.Require().NoError(err)
+	s.mountPublicSecret(dir)
+
+	_, err = verifier.Verify(oldToken)
+	s.NoError(err)
+	_, err = verifier.Verify(newToken)
+	s.NoError(err)
+}
+
+func (s *KeystoreSuite) TestRefreshSigningKey_SignerSwitchesToRotatedKID() {
+	oldKey, err := ensureSigningKey(context.Background(), s.store, s.clientset, s.cfg, s.now)
+	s.Require().NoError(err)
+	signer, err := utils.NewSignerFromConfig(utils.SignerConfig{
+		PrivateKeyPath:    oldKey.PrivateKeyPath,
+		Issuer:            "agentland-gateway",
+		Audience:          "sandbox",
+		KID:               oldKey.KID,
+		TTL:               5 * time.Minute,
+		KeyReloadInterval: time.Nanosecond,
+	})
+	s.Require().NoError(err)
+
+	// 另一个网关实例轮换密钥，本实例的本地缓存不变。
+	other := s.cfg
+	other.LocalPrivateKeyPath = filepath.Join(s.T().TempDir(), "private.pem")
+	newKey, err := rotateSigningKey(context.Background(), s.store, s.clientset, other, 10*time.Minute, s.now)
+	s.Require().NoError(err)
+	_, err = signer.Sign("session-abc", "", 0)
+	s.Require().NoError(err)
+	s.Equal(oldKey.KID, signer.KID())
+
+	key, err := refreshSigningKey(context.Background(), s.store, s.cfg)
+	s.Require().NoError(err)
+	s.Equal(newKey.KID, key.KID)
+
+	token, err := signer.Sign("session-abc", "", 0)
+	s.Require().NoError(err)
+	s.Equal(newKey.KID, signer.KID())
+	s.NoError(s.verifyWithJWKS(token, s.now))
+}
+
+func (s *KeystoreSuite) TestEnsureSigningKey_RotatesOnAlgorithmChange() {
+	oldKey, err := ensureSigningKey(context.Background(), s.store, s.clientset, s.cfg, s.now)
+	s.Require().NoError(err)
+
+	s.cfg.Algorithm = utils.AlgEdDSA
+	key, err := ensureSigningKey(context.Background(), s.store, s.clientset, s.cfg, s.now)
+	s.Require().NoError(err)
+	s.NotEqual(oldKey.KID, key.KID)
+	s.Equal(utils.AlgEdDSA, s.newSigner(key).Algorithm())
+}
+
+func (s *KeystoreSuite) TestDirKeyRingStore_MigratesLegacyLocalKey() {
+	dir := s.T().TempDir()
+	legacyPath, _, err := testutil.WriteTestRSAKeys(dir)
+	s.Require().NoError(err)
+	legacyPEM, err := os.ReadFile(legacyPath)
+	s.Require().NoError(err)
+
+	s.cfg.LocalPrivateKeyPath = legacyPath
+	store := &dirKeyRingStore{dir: filepath.Join(dir, localKeyRingDirName), legacyPrivateKey: legacyPath}
+
+	key, err := ensureSigningKey(context.Background(), store, nil, s.cfg, s.now)
+	s.Require().NoError(err)
+	s.Equal(defaultKID, key.KID)
+
+	cached, err := os.ReadFile(legacyPath)
+	s.Require().NoError(err)
+	s.Equal(legacyPEM, cached)
+
+	rotated, err := rotateSigningKey(context.Background(), store, nil, s.cfg, time.Minute, s.now)
+	s.Require().NoError(err)
+
+	data, err := store.load(context.Background())
+	s.Require().NoError(err)
+	s.Equal(rotated.KID, string(data[activeKIDDataKey]))
+	s.Contains(data, "private-"+defaultKID+".pem")
+	s.Contains(data, retireAtDataPrefix+defaultKID)
+}
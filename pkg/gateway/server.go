@@ -2,30 +2,83 @@ package gateway
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/Fl0rencess720/agentland/pkg/common/health"
+	"github.com/Fl0rencess720/agentland/pkg/common/utils"
 	"github.com/Fl0rencess720/agentland/pkg/gateway/config"
 	"github.com/Fl0rencess720/agentland/pkg/gateway/handlers"
 	"github.com/Fl0rencess720/agentland/pkg/gateway/middleware"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/db"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/metrics"
 	ginZap "github.com/gin-contrib/zap"
 	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
+// defaultDrainTimeout 为未配置 DrainTimeout 时等待在途代理请求的时长，需小于 Pod 的 terminationGracePeriodSeconds
+const defaultDrainTimeout = 20 * time.Second
+
 type Server struct {
-	httpServer *http.Server
+	httpServer   *http.Server
+	prober       *health.Prober
+	drainTimeout time.Duration
 }
 
 func NewServer(cfg *config.Config) (*Server, error) {
+	prober := health.NewProber(0)
+	rdb := db.NewRedis()
+	prober.AddCheck("redis", func(ctx context.Context) error {
+		return rdb.Ping(ctx).Err()
+	})
+	prober.AddCheck("agentcore", checkAgentCoreDialable(viper.GetString("agentcore.address")))
+
+	sandboxTransport, err := handlers.NewSandboxTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	e := gin.New()
-	e.Use(middleware.Tracing())
+	// 探针在挂载中间件之前注册，不产生链路、指标与访问日志
+	prober.Register(e)
+	e.Use(middleware.Tracing(), middleware.Metrics())
 	e.Use(gin.Recovery(), ginZap.Ginzap(zap.L(), time.RFC3339, false), ginZap.RecoveryWithZap(zap.L(), false))
+	// 跨域预检在路由与鉴权之前由网关直接应答
+	e.Use(middleware.CORS(middleware.CORSOptions{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowedMethods:   cfg.CORSAllowedMethods,
+		AllowedHeaders:   cfg.CORSAllowedHeaders,
+		AllowCredentials: cfg.CORSAllowCredentials,
+		MaxAge:           cfg.CORSMaxAge,
+	}))
+
+	handlers.InitJWKSApi(e, cfg)
+	e.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	var limiter middleware.RateLimiter
+	if cfg.RateLimitRPS > 0 {
+		limiter = middleware.NewKeyedRateLimiter(cfg.RateLimitRPS, cfg.RateLimitBurst, nil)
+	}
+
+	authenticators, err := buildAuthenticators(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(authenticators) == 0 {
+		zap.L().Warn("Gateway client authentication is disabled, any caller with a session id can access sandboxes")
+	}
+	auth := middleware.Auth(authenticators...)
 
 	app := e.Group("/api")
 	{
-		handlers.InitCodeInterpreterApi(app.Group("/code-runner"), cfg)
-		handlers.InitAgentSessionApi(app.Group("/agent-sessions"), cfg)
+		handlers.InitCodeInterpreterApi(app.Group("/code-runner", auth, middleware.RateLimit(limiter)), cfg, sandboxTransport)
+		handlers.InitAgentSessionApi(app.Group("/agent-sessions", auth, middleware.RateLimit(limiter)), cfg, sandboxTransport)
 	}
 
 	httpServer := &http.Server{
@@ -33,12 +86,78 @@ func NewServer(cfg *config.Config) (*Server, error) {
 		Handler: e,
 	}
 
-	return &Server{httpServer: httpServer}, nil
+	drainTimeout := cfg.DrainTimeout
+	if drainTimeout <= 0 {
+		drainTimeout = defaultDrainTimeout
+	}
+
+	return &Server{httpServer: httpServer, prober: prober, drainTimeout: drainTimeout}, nil
 }
 
+// buildAuthenticators 按配置构建客户端鉴权方式，均未配置时返回空列表
+func buildAuthenticators(cfg *config.Config) ([]middleware.Authenticator, error) {
+	var authenticators []middleware.Authenticator
+	if len(cfg.AuthAPIKeys) > 0 {
+		apiKeys, err := middleware.NewAPIKeyAuthenticator(cfg.AuthAPIKeys)
+		if err != nil {
+			return nil, fmt.Errorf("build api key authenticator: %w", err)
+		}
+		authenticators = append(authenticators, apiKeys)
+	}
+	if cfg.AuthJWTIssuer == "" {
+		return authenticators, nil
+	}
+
+	verifierCfg := utils.VerifierConfig{
+		PublicKeyPath:     cfg.AuthJWTPublicKeyPath,
+		Issuer:            cfg.AuthJWTIssuer,
+		Audience:          cfg.AuthJWTAudience,
+		ClockSkew:         30 * time.Second,
+		OptionalSessionID: true,
+	}
+	var (
+		verifier *utils.Verifier
+		err      error
+	)
+	if cfg.AuthJWTJWKSURL != "" {
+		verifier, err = utils.NewVerifierFromJWKS(cfg.AuthJWTJWKSURL, verifierCfg)
+	} else {
+		verifier, err = utils.NewVerifierFromConfig(verifierCfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("build jwt authenticator: %w", err)
+	}
+	return append(authenticators, middleware.NewJWTAuthenticator(verifier)), nil
+}
+
+// checkAgentCoreDialable 检查 agentcore 地址能否建立 TCP 连接
+func checkAgentCoreDialable(address string) health.CheckFunc {
+	address = strings.TrimPrefix(strings.TrimSpace(address), "dns:///")
+	return func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// Serve 启动 HTTP 服务，ctx 结束后先摘除就绪、等待在途代理请求排空，再关闭 HTTP Server。
+// 返回前保证停机流程已完成，调用方退出进程时不会打断仍在转发的请求。
 func (s *Server) Serve(ctx context.Context) error {
+	shutdownDone := make(chan struct{})
 	go func() {
+		defer close(shutdownDone)
 		<-ctx.Done()
+		s.prober.SetDraining()
+
+		drainCtx, cancelDrain := context.WithTimeout(context.Background(), s.drainTimeout)
+		if err := handlers.DrainProxies(drainCtx); err != nil {
+			zap.L().Warn("Drain in-flight proxy requests timed out", zap.Duration("timeout", s.drainTimeout), zap.Error(err))
+		}
+		cancelDrain()
+
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 		if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
@@ -48,5 +167,9 @@ func (s *Server) Serve(ctx context.Context) error {
 
 	zap.S().Infof("Gateway server listening on %s", s.httpServer.Addr)
 
-	return s.httpServer.ListenAndServe()
+	err := s.httpServer.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		<-shutdownDone
+	}
+	return err
 }
@@ -2,13 +2,18 @@ package gateway
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
+	"github.com/Fl0rencess720/agentland/pkg/common/health"
 	"github.com/Fl0rencess720/agentland/pkg/common/testutil"
 	"github.com/Fl0rencess720/agentland/pkg/gateway/config"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/handlers"
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap"
 )
@@ -64,6 +69,48 @@ func (s *ServerSuite) TestHandlerLogic() {
 	s.Equal(404, w.Code)
 }
 
+// 测试 /metrics 暴露请求计数，未命中路由的请求归入 unmatched
+func (s *ServerSuite) TestMetricsEndpoint() {
+	srv, _ := NewServer(s.testConfig)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/not-exist-route", nil)
+	srv.httpServer.Handler.ServeHTTP(w, req)
+	s.Equal(404, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/metrics", nil)
+	srv.httpServer.Handler.ServeHTTP(w, req)
+
+	s.Equal(200, w.Code)
+	body := w.Body.String()
+	s.Contains(body, `agentland_gateway_http_requests_total{code="404",method="GET",route="unmatched"}`)
+	s.NotContains(body, "not-exist-route")
+}
+
+// 测试探针：依赖不可达时存活探针仍为 200，就绪探针返回 503
+func (s *ServerSuite) TestProbes() {
+	srv, _ := NewServer(s.testConfig)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/healthz", nil)
+	srv.httpServer.Handler.ServeHTTP(w, req)
+	s.Equal(200, w.Code)
+
+	// 测试环境未配置 agentcore 地址，拨号必然失败
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/readyz", nil)
+	srv.httpServer.Handler.ServeHTTP(w, req)
+	s.Equal(503, w.Code)
+	s.Contains(w.Body.String(), `"agentcore"`)
+
+	srv.prober.SetDraining()
+	w = httptest.NewRecorder()
+	srv.httpServer.Handler.ServeHTTP(w, req)
+	s.Equal(503, w.Code)
+	s.Contains(w.Body.String(), "draining")
+}
+
 // 测试 Serve 方法的生命周期
 func (s *ServerSuite) TestServe_Lifecycle() {
 	srv, _ := NewServer(s.testConfig)
@@ -102,3 +149,87 @@ func (s *ServerSuite) TestServe_Lifecycle() {
 		s.Fail("Server did not shutdown within timeout")
 	}
 }
+
+// 测试停机信号之前发起的代理请求在信号之后仍能完整返回
+func (s *ServerSuite) TestServe_DrainsInflightProxy() {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		_, _ = w.Write([]byte("done"))
+	}))
+	defer upstream.Close()
+	target, err := url.Parse(upstream.URL)
+	s.Require().NoError(err)
+
+	proxyEngine := handlers.NewProxyEngine(http.DefaultTransport)
+	engine := gin.New()
+	engine.GET("/slow", func(c *gin.Context) {
+		proxyEngine.Forward(c, handlers.ProxyConfig{Target: target, Method: http.MethodGet, InternalPath: "/slow"})
+	})
+
+	srv := &Server{
+		httpServer:   &http.Server{Addr: "127.0.0.1:18884", Handler: engine},
+		prober:       health.NewProber(0),
+		drainTimeout: 5 * time.Second,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ctx)
+	}()
+
+	type result struct {
+		body string
+		err  error
+	}
+	respCh := make(chan result, 1)
+	go func() {
+		var resp *http.Response
+		var err error
+		for i := 0; i < 50; i++ {
+			resp, err = http.Get("http://127.0.0.1:18884/slow")
+			if err == nil {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		if err != nil {
+			respCh <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		respCh <- result{body: string(body), err: err}
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(3 * time.Second):
+		s.FailNow("proxied request did not reach upstream")
+	}
+
+	// 请求进行中触发停机，Serve 需等待其结束后才返回
+	cancel()
+	select {
+	case err := <-serveErr:
+		s.FailNow("Serve returned before in-flight proxy finished", "err: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	close(release)
+
+	res := <-respCh
+	s.Require().NoError(res.err)
+	s.Equal("done", res.body)
+
+	select {
+	case err := <-serveErr:
+		s.ErrorIs(err, http.ErrServerClosed)
+	case <-time.After(3 * time.Second):
+		s.Fail("Server did not shutdown after draining")
+	}
+}
@@ -4,12 +4,56 @@ import "time"
 
 type Config struct {
 	Port string `json:"port"`
+	// GRPCPort 为 ContextService gRPC 端口，为空时不启动 gRPC 服务
+	GRPCPort string `json:"grpc_port"`
+	// MetricsPort 为 Prometheus 指标端口，为空时不启动指标服务
+	MetricsPort string `json:"metrics_port"`
 
 	SandboxJWTPublicPath string        `json:"sandbox_jwt_public_path"`
 	SandboxJWTIssuer     string        `json:"sandbox_jwt_issuer"`
 	SandboxJWTAudience   string        `json:"sandbox_jwt_audience"`
 	SandboxJWTClockSkew  time.Duration `json:"sandbox_jwt_clock_skew"`
+	// SandboxJWTJWKSURL 非空时从网关 JWKS 拉取验签公钥，替代 SandboxJWTPublicPath
+	SandboxJWTJWKSURL      string        `json:"sandbox_jwt_jwks_url"`
+	SandboxJWTJWKSCacheTTL time.Duration `json:"sandbox_jwt_jwks_cache_ttl"`
+	// SandboxJWTKeyReloadInterval 为重读 SandboxJWTPublicPath 的最小间隔，Secret 轮换后无需重启即可生效
+	SandboxJWTKeyReloadInterval time.Duration `json:"sandbox_jwt_key_reload_interval"`
 
 	WorkspaceRoot string `json:"workspace_root"`
 	MaxFileBytes  int64  `json:"max_file_bytes"`
+	// MaxArchiveBytes 为目录打包下载时文件内容总大小上限，<=0 表示不限制
+	MaxArchiveBytes int64 `json:"max_archive_bytes"`
+	// MaxWorkspaceBytes 为经文件写入、上传接口写入后工作区普通文件的总大小上限，<=0 表示不限制
+	MaxWorkspaceBytes int64 `json:"max_workspace_bytes"`
+	// MaxUploadTotalBytes 为单次批量上传的文件总大小上限，<=0 表示不限制
+	MaxUploadTotalBytes int64 `json:"max_upload_total_bytes"`
+
+	MaxRichOutputBytes int64 `json:"max_rich_output_bytes"`
+	// MaxOutputBytes 为单次执行 stdout/stderr 各自保留的字节上限，超出部分截断，<=0 表示不限制
+	MaxOutputBytes int64 `json:"max_output_bytes"`
+
+	// ProxyReadyTimeout 为按端口代理前等待目标端口可连接的最长时间，<=0 表示不检查
+	ProxyReadyTimeout time.Duration `json:"proxy_ready_timeout"`
+
+	ContextMaxCount         int           `json:"context_max_count"`
+	ContextIdleTTL          time.Duration `json:"context_idle_ttl"`
+	ContextGCInterval       time.Duration `json:"context_gc_interval"`
+	ContextDefaultTimeoutMs int           `json:"context_default_timeout_ms"`
+	// ContextHistorySize 为每个 context 保留的执行记录条数，0 使用默认值
+	ContextHistorySize int `json:"context_history_size"`
+
+	// ContextEnvAllowlist 允许 context 覆盖的受保护环境变量（如 PATH、LD_PRELOAD）
+	ContextEnvAllowlist []string `json:"context_env_allowlist"`
+
+	// AuditLogPath 非空时将代码执行审计记录追加写入该文件，否则写入进程日志
+	AuditLogPath string `json:"audit_log_path"`
+	// AuditIncludeCode 为 true 时审计记录包含截断、脱敏后的代码原文
+	AuditIncludeCode bool `json:"audit_include_code"`
+
+	// KernelTransport 为 Jupyter 内核连接文件的传输方式（tcp/ipc），由入口脚本通过 -jupyter-args 读取
+	KernelTransport string `json:"kernel_transport"`
+	// KernelIPCDir 为 ipc 模式下内核 Unix Domain Socket 所在目录
+	KernelIPCDir string `json:"kernel_ipc_dir"`
+	// KernelSignatureScheme 为内核消息签名算法，如 hmac-sha256
+	KernelSignatureScheme string `json:"kernel_signature_scheme"`
 }
@@ -1,12 +1,19 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/Fl0rencess720/agentland/pkg/common/audit"
 	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	commonutils "github.com/Fl0rencess720/agentland/pkg/common/utils"
 	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
+	"github.com/Fl0rencess720/agentland/pkg/korokd/middleware"
 	"github.com/Fl0rencess720/agentland/pkg/korokd/pkgs/utils"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -14,20 +21,39 @@ import (
 
 type CodeInterpreterHandler struct {
 	contexts *contextManager
+	// auditor 记录每次代码执行，为 nil 时不记录
+	auditor *audit.Recorder
 }
 
-func InitCodeInterpreterApi(group *gin.RouterGroup) {
-	manager, err := newContextManager()
+// InitCodeInterpreterApi 注册 context 相关路由，返回的 handler 可供 gRPC 接口共用；初始化失败时返回 nil
+// ctx 控制后台 GC 的生命周期
+func InitCodeInterpreterApi(ctx context.Context, group *gin.RouterGroup, cfg ContextManagerConfig, auditor *audit.Recorder) *CodeInterpreterHandler {
+	manager, err := newContextManager(ctx, cfg)
 	if err != nil {
 		zap.L().Error("Init context manager failed", zap.Error(err))
-		return
+		return nil
 	}
 
-	h := &CodeInterpreterHandler{contexts: manager}
+	h := &CodeInterpreterHandler{contexts: manager, auditor: auditor}
 
+	group.GET("/contexts", h.ListContexts)
 	group.POST("/contexts", h.CreateContext)
 	group.POST("/contexts/:contextId/execute", h.ExecuteInContext)
+	group.POST("/contexts/:contextId/execute-batch", h.ExecuteBatch)
+	group.POST("/contexts/:contextId/install", h.InstallPackages)
+	group.POST("/contexts/:contextId/interrupt", h.InterruptContext)
+	group.POST("/contexts/:contextId/reset", h.ResetContext)
+	group.GET("/contexts/:contextId/history", h.GetContextHistory)
 	group.DELETE("/contexts/:contextId", h.DeleteContext)
+	return h
+}
+
+// Shutdown 回收全部 context 并停止后台 GC，供进程退出时调用；handler 为 nil 时直接返回
+func (h *CodeInterpreterHandler) Shutdown(ctx context.Context) error {
+	if h == nil || h.contexts == nil {
+		return nil
+	}
+	return h.contexts.Shutdown(ctx)
 }
 
 // CreateContext 创建代码执行上下文
@@ -38,7 +64,11 @@ func (h *CodeInterpreterHandler) CreateContext(c *gin.Context) {
 		return
 	}
 
-	kernelCtx, err := h.contexts.create(req.Language, req.CWD)
+	kernelCtx, err := h.contexts.create(req)
+	if errors.Is(err, errInvalidLimits) || errors.Is(err, errInvalidEnv) || errors.Is(err, errInvalidParallelism) {
+		response.ErrorResponse(c, response.FormError)
+		return
+	}
 	if err != nil {
 		response.ErrorResponse(c, response.ServerError)
 		return
@@ -47,12 +77,20 @@ func (h *CodeInterpreterHandler) CreateContext(c *gin.Context) {
 	response.SuccessResponse(c, models.CreateContextResp{
 		ContextID: kernelCtx.ID,
 		Language:  kernelCtx.Language,
-		CWD:       kernelCtx.CWD,
+		CWD:       kernelCtx.initCWD(),
 		State:     "ready",
 		CreatedAt: kernelCtx.createdAt.Format(time.RFC3339),
 	})
 }
 
+// ListContexts 列出当前沙箱内所有存活的上下文
+func (h *CodeInterpreterHandler) ListContexts(c *gin.Context) {
+	response.SuccessResponse(c, models.ListContextsResp{
+		Contexts: h.contexts.list(),
+		Recycled: h.contexts.listRecycled(),
+	})
+}
+
 // ExecuteInContext 在上下文中执行代码
 func (h *CodeInterpreterHandler) ExecuteInContext(c *gin.Context) {
 	contextID := c.Param("contextId")
@@ -62,47 +100,53 @@ func (h *CodeInterpreterHandler) ExecuteInContext(c *gin.Context) {
 		response.ErrorResponse(c, response.FormError)
 		return
 	}
-
-	if strings.TrimSpace(req.Code) == "" {
+	if err := normalizeExecuteReq(&req); err != nil {
 		response.ErrorResponse(c, response.FormError)
 		return
 	}
-	if req.TimeoutMs != 0 && (req.TimeoutMs < contextMinTimeoutMs || req.TimeoutMs > contextMaxTimeoutMs) {
-		response.ErrorResponse(c, response.FormError)
+
+	emit, stop := startSSEStream(c, contextID)
+	defer stop()
+
+	if !h.runExecution(c.Request.Context(), sessionIDFromRequest(c), contextID, req, emit) {
 		return
 	}
+	// 在 handler 返回前给客户端一个很短的窗口读取最后一帧，避免尾帧丢失
+	if req.CheckOnly {
+		waitTailFrame(0)
+	} else {
+		waitTailFrame(req.TimeoutMs)
+	}
+}
 
-	utils.SetupSSEResponse(c)
-
-	var mu sync.Mutex
-	emit := func(evt models.ExecuteStreamEvent) bool {
-		if evt.Timestamp == 0 {
-			evt.Timestamp = time.Now().UnixMilli()
-		}
-		if evt.ContextID == "" {
-			evt.ContextID = contextID
-		}
-		return utils.WriteSSE(c, &mu, evt)
+// normalizeExecuteReq 解码 code 并校验执行参数，HTTP 与 gRPC 共用
+func normalizeExecuteReq(req *models.ExecuteContextReq) error {
+	code, err := commonutils.DecodeCode(req.Code, req.CodeEncoding)
+	if err != nil {
+		return err
 	}
+	req.Code = code
 
-	_ = emit(models.ExecuteStreamEvent{Type: "init"})
+	if strings.TrimSpace(req.Code) == "" {
+		return fmt.Errorf("code is required")
+	}
+	if req.TimeoutMs != 0 && (req.TimeoutMs < contextMinTimeoutMs || req.TimeoutMs > contextMaxTimeoutMs) {
+		return errInvalidTimeoutMS
+	}
+	return nil
+}
 
-	done := make(chan struct{})
-	defer close(done)
-	go func() {
-		ticker := time.NewTicker(3 * time.Second)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-done:
-				return
-			case <-c.Request.Context().Done():
-				return
-			case <-ticker.C:
-				_ = emit(models.ExecuteStreamEvent{Type: "ping", Text: "pong"})
-			}
-		}
-	}()
+// runExecution 执行已校验的请求并通过 emit 推送流式帧，HTTP(SSE) 与 gRPC 共用
+// 执行失败时发送 error 帧并返回 false，否则以 execution_complete 帧结束并返回 true
+func (h *CodeInterpreterHandler) runExecution(
+	ctx context.Context,
+	sessionID, contextID string,
+	req models.ExecuteContextReq,
+	emit func(models.ExecuteStreamEvent) bool,
+) bool {
+	if req.CheckOnly {
+		return h.checkSyntax(ctx, contextID, req.Code, emit)
+	}
 
 	hookSet := executeStreamHooks{
 		OnStdout: func(text string) {
@@ -129,37 +173,294 @@ func (h *CodeInterpreterHandler) ExecuteInContext(c *gin.Context) {
 			}
 			_ = emit(models.ExecuteStreamEvent{Type: "count", ExecutionCount: count})
 		},
+		OnDisplay: func(outputs []models.RichOutput) {
+			if len(outputs) == 0 {
+				return
+			}
+			_ = emit(models.ExecuteStreamEvent{Type: "display", Outputs: outputs})
+		},
 	}
 
+	auditMeta := h.captureExecutionAudit(contextID)
+	start := time.Now()
 	resp, err := h.contexts.executeWithHooks(
-		c.Request.Context(),
+		ctx,
 		contextID,
 		req.Code,
 		req.TimeoutMs,
+		executeOptions{Stdin: req.Stdin, ReturnVars: req.ReturnVars, Parallel: req.Parallel},
 		&hookSet,
 	)
+	var exitCode *int32
+	if resp != nil {
+		exitCode = &resp.ExitCode
+	}
+	h.auditExecution(ctx, sessionID, contextID, auditMeta, req.Code, exitCode, time.Since(start), err)
+	if err != nil {
+		_ = emit(models.ExecuteStreamEvent{Type: "error", Error: err.Error()})
+		return false
+	}
+
+	// 执行结束发送 execution_count、execution_time 与 exit_code，stdout/stderr 由流式帧增量传输
+	_ = emit(models.ExecuteStreamEvent{
+		Type:            "execution_complete",
+		ExecutionCount:  resp.ExecutionCount,
+		ExecutionTime:   resp.DurationMs,
+		ExitCode:        resp.ExitCode,
+		StdoutTruncated: resp.StdoutTruncated,
+		StderrTruncated: resp.StderrTruncated,
+		Variables:       resp.Variables,
+	})
+	return true
+}
+
+// checkSyntax 以与正常执行相同的帧格式返回语法检查结果：诊断信息作为 stderr 帧，随后发送 execution_complete
+func (h *CodeInterpreterHandler) checkSyntax(ctx context.Context, contextID, code string, emit func(models.ExecuteStreamEvent) bool) bool {
+	resp, err := h.contexts.checkSyntax(ctx, contextID, code)
+	if err != nil {
+		_ = emit(models.ExecuteStreamEvent{Type: "error", Error: err.Error()})
+		return false
+	}
+
+	if resp.Stderr != "" {
+		_ = emit(models.ExecuteStreamEvent{Type: "stderr", Text: resp.Stderr})
+	}
+	_ = emit(models.ExecuteStreamEvent{
+		Type:           "execution_complete",
+		ExecutionCount: resp.ExecutionCount,
+		ExecutionTime:  resp.DurationMs,
+		ExitCode:       resp.ExitCode,
+	})
+	return true
+}
+
+// ExecuteBatch 在上下文中按顺序执行多个代码单元，一次性返回各单元结果
+func (h *CodeInterpreterHandler) ExecuteBatch(c *gin.Context) {
+	contextID := c.Param("contextId")
+
+	var req models.ExecuteBatchReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorResponse(c, response.FormError)
+		return
+	}
+
+	auditMeta := h.captureExecutionAudit(contextID)
+	resp, err := h.contexts.executeBatch(c.Request.Context(), contextID, req.Cells, req.StopOnError)
+	if errors.Is(err, errInvalidBatch) || errors.Is(err, errInvalidTimeoutMS) {
+		response.ErrorResponse(c, response.FormError)
+		return
+	}
+	if err != nil {
+		writeContextError(c, err)
+		return
+	}
+
+	for i := range resp.Results {
+		result := &resp.Results[i]
+		var cellErr error
+		var exitCode *int32
+		if result.Error != "" {
+			cellErr = errors.New(result.Error)
+		} else {
+			exitCode = &result.ExitCode
+		}
+		h.auditExecution(c.Request.Context(), sessionIDFromRequest(c), contextID, auditMeta, req.Cells[result.Index].Code, exitCode,
+			time.Duration(result.DurationMs)*time.Millisecond, cellErr)
+	}
+
+	response.SuccessResponse(c, resp)
+}
+
+// sessionIDFromRequest 优先取 token 中的会话 ID，未经鉴权时回退到会话 header
+func sessionIDFromRequest(c *gin.Context) string {
+	if claims, ok := middleware.ClaimsFromContext(c); ok {
+		return claims.SessionID
+	}
+	return strings.TrimSpace(c.GetHeader("x-agentland-session"))
+}
+
+// executionAuditMeta 为执行前从 context 中取得的审计信息。执行超时会回收 context，
+// 因此语言与需要脱敏的环境变量值必须在执行前捕获
+type executionAuditMeta struct {
+	language string
+	secrets  []string
+}
+
+func (h *CodeInterpreterHandler) captureExecutionAudit(contextID string) executionAuditMeta {
+	var meta executionAuditMeta
+	if h.auditor == nil {
+		return meta
+	}
+	if kctx := h.contexts.get(contextID); kctx != nil {
+		meta.language = kctx.Language
+		for _, value := range kctx.Env {
+			meta.secrets = append(meta.secrets, value)
+		}
+	}
+	return meta
+}
+
+// auditExecution 记录一次代码执行，调用方身份取自 ctx 中的 token claims；注入的环境变量值会从记录中脱敏
+func (h *CodeInterpreterHandler) auditExecution(ctx context.Context, sessionID, contextID string, meta executionAuditMeta, code string, exitCode *int32, duration time.Duration, err error) {
+	if h.auditor == nil {
+		return
+	}
+
+	h.auditor.Record(audit.Execution{
+		Source:    audit.SourceKorokd,
+		ContextID: contextID,
+		Language:  meta.language,
+		Code:      code,
+		ExitCode:  exitCode,
+		Duration:  duration,
+		Err:       err,
+		SessionID: sessionID,
+		Subject:   middleware.SubjectFromContext(ctx),
+		Secrets:   meta.secrets,
+	})
+}
+
+// InstallPackages 在 python 上下文中执行 pip install，以 SSE 流式返回安装输出
+func (h *CodeInterpreterHandler) InstallPackages(c *gin.Context) {
+	contextID := c.Param("contextId")
+
+	var req models.InstallPackagesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorResponse(c, response.FormError)
+		return
+	}
+	if _, err := validatePackages(req.Packages); err != nil {
+		response.ErrorResponse(c, response.FormError)
+		return
+	}
+	if req.TimeoutMs != 0 && (req.TimeoutMs < contextMinTimeoutMs || req.TimeoutMs > contextMaxTimeoutMs) {
+		response.ErrorResponse(c, response.FormError)
+		return
+	}
+
+	emit, stop := startSSEStream(c, contextID)
+	defer stop()
+
+	result, err := h.contexts.installPackages(c.Request.Context(), contextID, req.Packages, req.TimeoutMs, &executeStreamHooks{
+		OnStdout: func(text string) {
+			_ = emit(models.ExecuteStreamEvent{Type: "stdout", Text: text})
+		},
+		OnStderr: func(text string) {
+			_ = emit(models.ExecuteStreamEvent{Type: "stderr", Text: text})
+		},
+	})
 	if err != nil {
 		_ = emit(models.ExecuteStreamEvent{Type: "error", Error: err.Error()})
 		return
 	}
 
-	// 执行结束发送 execution_time 与 exit_code，stdout/stderr 由流式帧增量传输
 	_ = emit(models.ExecuteStreamEvent{
-		Type:          "execution_complete",
-		ExecutionTime: resp.DurationMs,
-		ExitCode:      resp.ExitCode,
+		Type:          "install_complete",
+		ExecutionTime: result.DurationMs,
+		ExitCode:      result.ExitCode,
+		Packages:      result.Packages,
 	})
+	waitTailFrame(req.TimeoutMs)
+}
 
-	// 在 handler 返回前给客户端一个很短的窗口读取最后一帧，避免尾帧丢失
-	if req.TimeoutMs > 0 {
-		sleepMs := 50
-		if req.TimeoutMs < 50 {
-			sleepMs = req.TimeoutMs
+// startSSEStream 切换为 SSE 响应并发送 init 帧，期间每 3 秒发送一次 ping 保活
+// 返回的 stop 用于结束保活协程
+func startSSEStream(c *gin.Context, contextID string) (func(models.ExecuteStreamEvent) bool, func()) {
+	utils.SetupSSEResponse(c)
+
+	var mu sync.Mutex
+	emit := func(evt models.ExecuteStreamEvent) bool {
+		if evt.Timestamp == 0 {
+			evt.Timestamp = time.Now().UnixMilli()
 		}
-		time.Sleep(time.Duration(sleepMs) * time.Millisecond)
-	} else {
-		time.Sleep(50 * time.Millisecond)
+		if evt.ContextID == "" {
+			evt.ContextID = contextID
+		}
+		return utils.WriteSSE(c, &mu, evt)
 	}
+
+	_ = emit(models.ExecuteStreamEvent{Type: "init"})
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(3 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-c.Request.Context().Done():
+				return
+			case <-ticker.C:
+				_ = emit(models.ExecuteStreamEvent{Type: "ping", Text: "pong"})
+			}
+		}
+	}()
+	return emit, func() { close(done) }
+}
+
+func waitTailFrame(timeoutMs int) {
+	sleepMs := 50
+	if timeoutMs > 0 && timeoutMs < 50 {
+		sleepMs = timeoutMs
+	}
+	time.Sleep(time.Duration(sleepMs) * time.Millisecond)
+}
+
+// InterruptContext 中断上下文中正在运行的执行，不销毁上下文
+func (h *CodeInterpreterHandler) InterruptContext(c *gin.Context) {
+	contextID := c.Param("contextId")
+	if contextID == "" {
+		response.ErrorResponse(c, response.FormError)
+		return
+	}
+
+	interrupted, err := h.contexts.interrupt(c.Request.Context(), contextID)
+	if err != nil {
+		writeContextError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, models.InterruptContextResp{ContextID: contextID, Interrupted: interrupted})
+}
+
+// ResetContext 原地重启上下文的 kernel，保留上下文 ID 与工作目录
+func (h *CodeInterpreterHandler) ResetContext(c *gin.Context) {
+	contextID := c.Param("contextId")
+	if contextID == "" {
+		response.ErrorResponse(c, response.FormError)
+		return
+	}
+	force, err := parseForceQuery(c.Query("force"))
+	if err != nil {
+		response.ErrorResponse(c, response.FormError)
+		return
+	}
+
+	kctx, err := h.contexts.reset(c.Request.Context(), contextID, force)
+	if err != nil {
+		writeContextError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, kctx.info())
+}
+
+// GetContextHistory 返回上下文自上次重置以来的执行记录
+func (h *CodeInterpreterHandler) GetContextHistory(c *gin.Context) {
+	contextID := c.Param("contextId")
+	if contextID == "" {
+		response.ErrorResponse(c, response.FormError)
+		return
+	}
+
+	entries, err := h.contexts.history(contextID)
+	if err != nil {
+		writeContextError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, models.ContextHistoryResp{ContextID: contextID, Entries: entries})
 }
 
 func (h *CodeInterpreterHandler) DeleteContext(c *gin.Context) {
@@ -170,9 +471,29 @@ func (h *CodeInterpreterHandler) DeleteContext(c *gin.Context) {
 	}
 
 	if err := h.contexts.removeContext(contextID, false); err != nil {
-		response.ErrorResponse(c, response.ServerError)
+		writeContextError(c, err)
 		return
 	}
 
 	response.SuccessResponse(c, models.DeleteContextResp{ContextID: contextID})
 }
+
+// writeContextError 将 contextManager 的错误映射为 HTTP 响应：不存在或已被自动回收时返回 404，
+// msg 中携带回收原因；正在执行时返回 409（CONTEXT_BUSY）
+func writeContextError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errContextNotFound):
+		response.APIErrorResponse(c, response.CodeNotFound, err.Error())
+	case errors.Is(err, errContextBusy):
+		response.APIErrorResponse(c, response.CodeContextBusy, err.Error())
+	default:
+		response.ErrorResponse(c, response.ServerError)
+	}
+}
+
+func parseForceQuery(raw string) (bool, error) {
+	if strings.TrimSpace(raw) == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(raw)
+}
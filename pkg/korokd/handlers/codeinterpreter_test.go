@@ -65,6 +65,18 @@ func (s *CodeInterpreterSuite) TestExecuteInContext_EmptyCode_ReturnsFormErrorJS
 	s.Contains(s.recorder.Header().Get("Content-Type"), "application/json")
 }
 
+func (s *CodeInterpreterSuite) TestExecuteInContext_InvalidBase64_ReturnsFormErrorJSON() {
+	req := httptest.NewRequest(http.MethodPost, "/contexts/ctx-1/execute", strings.NewReader(`{"code":"not base64!","code_encoding":"base64"}`))
+	req.Header.Set("Content-Type", "application/json")
+	s.ctx.Request = req
+	s.ctx.Params = gin.Params{{Key: "contextId", Value: "ctx-1"}}
+
+	s.handler.ExecuteInContext(s.ctx)
+
+	s.Equal(http.StatusBadRequest, s.recorder.Code)
+	s.Contains(s.recorder.Body.String(), `"msg":"Form Error"`)
+}
+
 func (s *CodeInterpreterSuite) TestExecuteInContext_InvalidTimeout_ReturnsFormErrorJSON() {
 	req := httptest.NewRequest(http.MethodPost, "/contexts/ctx-1/execute", strings.NewReader(`{"code":"print(1)","timeout_ms":99}`))
 	req.Header.Set("Content-Type", "application/json")
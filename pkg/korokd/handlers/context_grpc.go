@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	pb "github.com/Fl0rencess720/agentland/pb/korokd"
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	"github.com/Fl0rencess720/agentland/pkg/korokd/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// contextGRPCService 以 gRPC 暴露 context 的创建/列举/删除/执行，与 HTTP 接口共用同一个 contextManager
+type contextGRPCService struct {
+	pb.UnimplementedContextServiceServer
+
+	h *CodeInterpreterHandler
+}
+
+// RegisterContextService 将 ContextService 注册到 gRPC server；h 为 nil 时（context 管理初始化失败）不注册
+func RegisterContextService(server *grpc.Server, h *CodeInterpreterHandler) {
+	if h == nil {
+		return
+	}
+	pb.RegisterContextServiceServer(server, &contextGRPCService{h: h})
+}
+
+func (s *contextGRPCService) CreateContext(ctx context.Context, req *pb.CreateContextRequest) (*pb.CreateContextResponse, error) {
+	kctx, err := s.h.contexts.create(models.CreateContextReq{
+		Language:    req.GetLanguage(),
+		CWD:         req.GetCwd(),
+		MemBytes:    req.GetMemBytes(),
+		CPUMillis:   req.GetCpuMillis(),
+		Env:         req.GetEnv(),
+		Parallelism: int(req.GetParallelism()),
+	})
+	if err != nil {
+		return nil, contextStatusError(err)
+	}
+	return &pb.CreateContextResponse{
+		ContextId: kctx.ID,
+		Language:  kctx.Language,
+		Cwd:       kctx.initCWD(),
+		State:     "ready",
+		CreatedAt: kctx.createdAt.Format(time.RFC3339),
+	}, nil
+}
+
+func (s *contextGRPCService) ListContexts(ctx context.Context, _ *pb.ListContextsRequest) (*pb.ListContextsResponse, error) {
+	infos := s.h.contexts.list()
+	resp := &pb.ListContextsResponse{Contexts: make([]*pb.ContextInfo, 0, len(infos))}
+	for _, info := range infos {
+		resp.Contexts = append(resp.Contexts, &pb.ContextInfo{
+			ContextId:      info.ContextID,
+			Language:       info.Language,
+			Cwd:            info.CWD,
+			CurrentCwd:     info.CurrentCWD,
+			KernelId:       info.KernelID,
+			ExecutionCount: info.ExecutionCount,
+			Busy:           info.Busy,
+			Parallelism:    int32(info.Parallelism),
+			MemBytes:       info.MemBytes,
+			CpuMillis:      info.CPUMillis,
+			CreatedAt:      info.CreatedAt,
+			LastActiveAt:   info.LastActiveAt,
+		})
+	}
+	return resp, nil
+}
+
+func (s *contextGRPCService) DeleteContext(ctx context.Context, req *pb.DeleteContextRequest) (*pb.DeleteContextResponse, error) {
+	if req.GetContextId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "context_id is required")
+	}
+	if err := s.h.contexts.removeContext(req.GetContextId(), false); err != nil {
+		return nil, contextStatusError(err)
+	}
+	return &pb.DeleteContextResponse{ContextId: req.GetContextId()}, nil
+}
+
+// Execute 以服务端流返回与 SSE 相同的帧序列（init、stdout/stderr/status/count/display、execution_complete 或 error），
+// 长连接由 gRPC 自身保活，不发送 ping 帧
+func (s *contextGRPCService) Execute(req *pb.ExecuteRequest, stream grpc.ServerStreamingServer[pb.ExecuteEvent]) error {
+	contextID := req.GetContextId()
+	if contextID == "" {
+		return status.Error(codes.InvalidArgument, "context_id is required")
+	}
+	execReq := models.ExecuteContextReq{
+		Code:         req.GetCode(),
+		CodeEncoding: req.GetCodeEncoding(),
+		TimeoutMs:    int(req.GetTimeoutMs()),
+		Stdin:        req.Stdin,
+		ReturnVars:   req.GetReturnVars(),
+		CheckOnly:    req.GetCheckOnly(),
+		Parallel:     req.GetParallel(),
+	}
+	if err := normalizeExecuteReq(&execReq); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var (
+		mu      sync.Mutex
+		sendErr error
+	)
+	emit := func(evt models.ExecuteStreamEvent) bool {
+		if evt.Timestamp == 0 {
+			evt.Timestamp = time.Now().UnixMilli()
+		}
+		if evt.ContextID == "" {
+			evt.ContextID = contextID
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if sendErr != nil {
+			return false
+		}
+		sendErr = stream.Send(executeEventToPB(evt))
+		return sendErr == nil
+	}
+
+	ctx := stream.Context()
+	sessionID := ""
+	if claims, ok := middleware.ClaimsFromGRPCContext(ctx); ok {
+		sessionID = claims.SessionID
+	}
+
+	_ = emit(models.ExecuteStreamEvent{Type: "init"})
+	s.h.runExecution(ctx, sessionID, contextID, execReq, emit)
+
+	mu.Lock()
+	defer mu.Unlock()
+	return sendErr
+}
+
+func executeEventToPB(evt models.ExecuteStreamEvent) *pb.ExecuteEvent {
+	out := &pb.ExecuteEvent{
+		Type:            evt.Type,
+		Timestamp:       evt.Timestamp,
+		ContextId:       evt.ContextID,
+		Text:            evt.Text,
+		ExecutionCount:  evt.ExecutionCount,
+		ExecutionTime:   evt.ExecutionTime,
+		ExitCode:        evt.ExitCode,
+		StdoutTruncated: evt.StdoutTruncated,
+		StderrTruncated: evt.StderrTruncated,
+		Variables:       evt.Variables,
+		Error:           evt.Error,
+	}
+	for _, output := range evt.Outputs {
+		out.Outputs = append(out.Outputs, &pb.RichOutput{
+			MimeType:  output.MimeType,
+			Data:      output.Data,
+			Truncated: output.Truncated,
+		})
+	}
+	return out
+}
+
+// contextStatusError 将 contextManager 的错误映射为 gRPC 状态码
+func contextStatusError(err error) error {
+	switch {
+	case errors.Is(err, errContextNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, errContextBusy):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, errContextLimitExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, errInvalidLimits), errors.Is(err, errInvalidEnv), errors.Is(err, errInvalidParallelism),
+		errors.Is(err, errUnsupportedLanguage), errors.Is(err, errCWDOutsideWorkspace):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
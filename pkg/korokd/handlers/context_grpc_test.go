@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	pb "github.com/Fl0rencess720/agentland/pb/korokd"
+	"github.com/Fl0rencess720/agentland/pkg/korokd/pkgs/jupyter"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newContextGRPCClient 在内存连接上启动 ContextService，返回指向 fake Jupyter 的客户端
+func newContextGRPCClient(t *testing.T, fj *fakeJupyter) pb.ContextServiceClient {
+	t.Helper()
+
+	jc, err := jupyter.NewClient(fj.server.URL, "")
+	require.NoError(t, err)
+	m, err := newContextManagerWithClient(ContextManagerConfig{WorkspaceRoot: t.TempDir()}, t.TempDir(), jc)
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	RegisterContextService(server, &CodeInterpreterHandler{contexts: m})
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return pb.NewContextServiceClient(conn)
+}
+
+func TestContextGRPC_CreateExecuteListDelete(t *testing.T) {
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		return fakeKernelReply{Stdout: "hello\n"}
+	})
+	client := newContextGRPCClient(t, fj)
+	ctx := context.Background()
+
+	created, err := client.CreateContext(ctx, &pb.CreateContextRequest{Language: "python"})
+	require.NoError(t, err)
+	require.Equal(t, "python", created.GetLanguage())
+	require.Equal(t, "ready", created.GetState())
+
+	stream, err := client.Execute(ctx, &pb.ExecuteRequest{ContextId: created.GetContextId(), Code: "print('hello')"})
+	require.NoError(t, err)
+	var (
+		types  []string
+		stdout string
+		last   *pb.ExecuteEvent
+	)
+	for {
+		evt, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.Equal(t, created.GetContextId(), evt.GetContextId())
+		types = append(types, evt.GetType())
+		if evt.GetType() == "stdout" {
+			stdout += evt.GetText()
+		}
+		last = evt
+	}
+	require.Equal(t, "init", types[0])
+	require.Equal(t, "hello\n", stdout)
+	require.Equal(t, "execution_complete", last.GetType())
+	require.Equal(t, int64(1), last.GetExecutionCount())
+
+	listed, err := client.ListContexts(ctx, &pb.ListContextsRequest{})
+	require.NoError(t, err)
+	require.Len(t, listed.GetContexts(), 1)
+	require.Equal(t, int64(1), listed.GetContexts()[0].GetExecutionCount())
+	require.Equal(t, int32(1), listed.GetContexts()[0].GetParallelism())
+
+	deleted, err := client.DeleteContext(ctx, &pb.DeleteContextRequest{ContextId: created.GetContextId()})
+	require.NoError(t, err)
+	require.Equal(t, created.GetContextId(), deleted.GetContextId())
+
+	_, err = client.DeleteContext(ctx, &pb.DeleteContextRequest{ContextId: created.GetContextId()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestContextGRPC_ErrorMapping(t *testing.T) {
+	fj := newFakeJupyter(t, nil)
+	client := newContextGRPCClient(t, fj)
+	ctx := context.Background()
+
+	_, err := client.CreateContext(ctx, &pb.CreateContextRequest{Language: "ruby"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.CreateContext(ctx, &pb.CreateContextRequest{Language: "python", Cwd: "../escape"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	stream, err := client.Execute(ctx, &pb.ExecuteRequest{ContextId: "ctx-1", Code: "  "})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// 执行阶段的错误与 SSE 一致，以 error 帧返回
+	stream, err = client.Execute(ctx, &pb.ExecuteRequest{ContextId: "missing", Code: "x = 1"})
+	require.NoError(t, err)
+	var last *pb.ExecuteEvent
+	for {
+		evt, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		last = evt
+	}
+	require.Equal(t, "error", last.GetType())
+	require.Contains(t, last.GetError(), errContextNotFound.Error())
+}
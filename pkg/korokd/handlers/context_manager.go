@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -14,31 +17,44 @@ import (
 
 	"github.com/Fl0rencess720/agentland/pkg/common/models"
 	"github.com/Fl0rencess720/agentland/pkg/korokd/pkgs/jupyter"
+	"github.com/Fl0rencess720/agentland/pkg/korokd/pkgs/metrics"
 	utils "github.com/Fl0rencess720/agentland/pkg/korokd/pkgs/utils"
 	"github.com/google/uuid"
 )
 
 const (
-	// 所有 context 的工作目录必须位于该根目录下，避免访问容器内任意路径
+	// 所有 context 的工作目录必须位于 workspace 根目录下，避免访问容器内任意路径；未配置时使用该默认值
 	contextWorkspaceRoot  = "/workspace"
 	contextLanguagePython = "python"
 	contextLanguageBash   = "bash"
+	contextLanguageNode   = "node"
 	// context 的运行时元数据放在 /tmp/korokd/contexts/<contextID> 下
 	contextBaseDir  = "/tmp/korokd"
 	contextsDirName = "contexts"
 	// 以下为运行安全边界与默认值：
-	// - contextMaxCount: 单个 korokd 进程允许维护的最大 context 数
-	// - contextIdleTTL/contextGCInterval: 空闲回收策略
-	// - contextCreateTimeout: 创建后探活超时
-	// - context*Timeout*: 执行阶段超时控制
-	contextMaxCount           = 32
-	contextIdleTTL            = 15 * time.Minute
-	contextGCInterval         = 30 * time.Second
-	contextCreateTimeout      = 10 * time.Second
-	contextDefaultTimeoutMs   = 30000
-	contextMinTimeoutMs       = 100
-	contextMaxTimeoutMs       = 300000
-	contextTimeoutGraceMillis = 2000
+	// - contextMaxCount: 单个 korokd 进程允许维护的最大 context 数（默认值，可配置）
+	// - contextIdleTTL/contextGCInterval: 空闲回收策略（默认值，可配置）
+	// - contextCreateTimeout: 创建（含 kernel 探活重试）的总超时
+	// - contextProbeAttemptTimeout/contextProbeInterval: 单次探活超时与重试间隔
+	// - context*Timeout*: 执行阶段超时控制，contextDefaultTimeoutMs 可配置
+	contextMaxCount            = 32
+	contextIdleTTL             = 15 * time.Minute
+	contextGCInterval          = 30 * time.Second
+	contextCreateTimeout       = 10 * time.Second
+	contextProbeAttemptTimeout = 2 * time.Second
+	contextProbeInterval       = 200 * time.Millisecond
+	contextDefaultTimeoutMs    = 30000
+	contextMinTimeoutMs        = 100
+	contextMaxTimeoutMs        = 300000
+	contextTimeoutGraceMillis  = 2000
+	// 单次批量执行允许的最大代码单元数
+	contextMaxBatchCells = 64
+	// 发送中断后等待当前执行退出的最长时间
+	contextInterruptTimeout = 5 * time.Second
+	// 内存上限过低会导致 kernel 自身无法运行，设置下限避免创建出不可用的 context
+	contextMinMemBytes = 64 << 20
+	// bash wrapper 在每次执行结束时将 pwd 写入 context 运行目录下的该文件
+	contextCWDFileName = ".cwd"
 )
 
 var (
@@ -46,22 +62,161 @@ var (
 	errContextBusy          = fmt.Errorf("context is busy")
 	errContextLimitExceeded = fmt.Errorf("context limit exceeded")
 	errInvalidTimeoutMS     = fmt.Errorf("invalid timeout_ms")
+	errInvalidBatch         = fmt.Errorf("invalid batch")
 	errCWDOutsideWorkspace  = fmt.Errorf("cwd outside workspace")
 	errUnsupportedLanguage  = fmt.Errorf("unsupported language")
+	errInvalidLimits        = fmt.Errorf("invalid resource limits")
+	errInterruptTimeout     = fmt.Errorf("execution did not stop after interrupt")
+	errInvalidEnv           = fmt.Errorf("invalid env")
+	errLimitsUnsupported    = fmt.Errorf("resource limits are not supported for this language")
 )
 
+var (
+	contextEnvKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// 覆盖这些变量会改变 kernel 加载的可执行文件/动态库，默认禁止，需通过 allowlist 显式放开
+	contextProtectedEnvKeys = map[string]struct{}{
+		"PATH":       {},
+		"LD_PRELOAD": {},
+	}
+)
+
+// contextLimits 为单个 context 的资源上限，0 表示不限制
+// 上限在 kernel 首次执行时通过 setrlimit/ulimit 施加在 kernel 进程（及其子进程）上
+type contextLimits struct {
+	MemBytes  int64
+	CPUMillis int64
+}
+
+func (l contextLimits) validate() error {
+	if l.MemBytes < 0 || l.CPUMillis < 0 {
+		return fmt.Errorf("%w: limits must not be negative", errInvalidLimits)
+	}
+	if l.MemBytes > 0 && l.MemBytes < contextMinMemBytes {
+		return fmt.Errorf("%w: mem_bytes must be at least %d", errInvalidLimits, contextMinMemBytes)
+	}
+	return nil
+}
+
+// cpuSeconds 将毫秒向上取整为秒，RLIMIT_CPU 的粒度为秒
+func (l contextLimits) cpuSeconds() int64 {
+	return (l.CPUMillis + 999) / 1000
+}
+
+// validateContextEnv 校验创建 context 时注入的环境变量
+func validateContextEnv(env map[string]string, allowlist map[string]struct{}) error {
+	for key, value := range env {
+		if !contextEnvKeyPattern.MatchString(key) {
+			return fmt.Errorf("%w: invalid key %q", errInvalidEnv, key)
+		}
+		if _, protected := contextProtectedEnvKeys[key]; protected {
+			if _, allowed := allowlist[key]; !allowed {
+				return fmt.Errorf("%w: overriding %s is not allowed", errInvalidEnv, key)
+			}
+		}
+		if strings.ContainsRune(value, 0) {
+			return fmt.Errorf("%w: value of %s contains NUL", errInvalidEnv, key)
+		}
+	}
+	return nil
+}
+
 // kernelContext 表示一个可复用的执行上下文
-// python/bash 对应 Jupyter session/kernel，都会在多次执行间保留状态
+// python/bash/node 对应 Jupyter session/kernel，都会在多次执行间保留状态
 type kernelContext struct {
 	ID       string
 	Language string
+	// CWD 为 kernel 首次执行时切换到的目录；shell context 重建 kernel 时会恢复为最近一次执行结束时的目录
+	// 发布到 map 之后的读写需持有 cwdMu
 	CWD      string
 	KernelID string
+	Limits   contextLimits
+	// Env 为创建时注入的环境变量，与 Limits 一样在 kernel 首次执行时生效
+	Env map[string]string
 
 	createdAt      time.Time
 	lastActiveUnix atomic.Int64
 	executionCount atomic.Int64
 	busy           atomic.Bool
+	// generation 在每次 reset 时递增，执行超时时据此判断 kernel 是否已被替换
+	generation atomic.Int64
+	cwdMu      sync.RWMutex
+	// lastCWD 为 shell 最近一次执行结束时的工作目录，尚未执行过时为 nil
+	lastCWD atomic.Pointer[string]
+	// history 记录最近若干次执行的摘要，reset 时清空
+	history executionHistory
+	// pool 为并行模式下的额外 kernel，串行 context 为空；创建后不再变更
+	pool []*poolKernel
+	// nextKernel 为并行执行轮转分派的计数
+	nextKernel atomic.Uint64
+}
+
+// initCWD 返回 kernel 初始化时切换到的目录
+func (k *kernelContext) initCWD() string {
+	k.cwdMu.RLock()
+	defer k.cwdMu.RUnlock()
+	return k.CWD
+}
+
+// currentCWD 返回最近一次已知的工作目录，未跟踪到时回退到 initCWD
+func (k *kernelContext) currentCWD() string {
+	if cwd := k.lastCWD.Load(); cwd != nil {
+		return *cwd
+	}
+	return k.initCWD()
+}
+
+// ContextManagerConfig 为 context 管理的可调参数，零值字段使用默认值
+type ContextManagerConfig struct {
+	// MaxCount 为单个 korokd 进程允许维护的最大 context 数
+	MaxCount int
+	// IdleTTL 为 context 空闲多久后被 GC 回收
+	IdleTTL time.Duration
+	// GCInterval 为空闲回收的扫描间隔
+	GCInterval time.Duration
+	// DefaultTimeoutMs 为未指定 timeout_ms 时的执行超时
+	DefaultTimeoutMs int
+	// MaxRichOutputBytes 为单条富输出的大小上限，<=0 表示不限制
+	MaxRichOutputBytes int64
+	// MaxOutputBytes 为单次执行 stdout/stderr 各自的大小上限，<=0 表示不限制
+	MaxOutputBytes int64
+	// HistorySize 为每个 context 保留的执行记录条数
+	HistorySize int
+	// EnvAllowlist 为允许 context 覆盖的受保护环境变量
+	EnvAllowlist []string
+	// WorkspaceRoot 为 context 工作目录的校验根目录，需与 Jupyter 的 notebook-dir 一致
+	WorkspaceRoot string
+}
+
+func (c ContextManagerConfig) withDefaults() (ContextManagerConfig, error) {
+	if c.MaxCount < 0 || c.IdleTTL < 0 || c.GCInterval < 0 || c.HistorySize < 0 {
+		return c, fmt.Errorf("context config must not be negative")
+	}
+	if c.MaxCount == 0 {
+		c.MaxCount = contextMaxCount
+	}
+	if c.IdleTTL == 0 {
+		c.IdleTTL = contextIdleTTL
+	}
+	if c.GCInterval == 0 {
+		c.GCInterval = contextGCInterval
+	}
+	if c.DefaultTimeoutMs == 0 {
+		c.DefaultTimeoutMs = contextDefaultTimeoutMs
+	}
+	if c.HistorySize == 0 {
+		c.HistorySize = contextHistorySize
+	}
+	if c.WorkspaceRoot = strings.TrimSpace(c.WorkspaceRoot); c.WorkspaceRoot == "" {
+		c.WorkspaceRoot = contextWorkspaceRoot
+	}
+	if !filepath.IsAbs(c.WorkspaceRoot) {
+		return c, fmt.Errorf("workspace root must be an absolute path")
+	}
+	c.WorkspaceRoot = filepath.Clean(c.WorkspaceRoot)
+	if c.DefaultTimeoutMs < contextMinTimeoutMs || c.DefaultTimeoutMs > contextMaxTimeoutMs {
+		return c, fmt.Errorf("default timeout must be between %d and %d ms", contextMinTimeoutMs, contextMaxTimeoutMs)
+	}
+	return c, nil
 }
 
 type contextManager struct {
@@ -69,6 +224,41 @@ type contextManager struct {
 	contexts map[string]*kernelContext
 	rootDir  string
 	jupyter  *jupyter.Client
+	// workspaceRoot 为 context 工作目录必须位于的根目录
+	workspaceRoot string
+
+	maxCount         int
+	idleTTL          time.Duration
+	gcInterval       time.Duration
+	defaultTimeoutMs int
+	// maxRichOutputBytes 为单条富输出的大小上限，<=0 表示不限制
+	maxRichOutputBytes int64
+	// maxOutputBytes 为单次执行 stdout/stderr 各自的大小上限，<=0 表示不限制
+	maxOutputBytes int64
+	// historySize 为每个 context 保留的执行记录条数
+	historySize int
+	// envAllowlist 中的受保护变量（如 PATH）允许被覆盖
+	envAllowlist map[string]struct{}
+	// pipCommand 为执行 pip 的命令前缀，为空时使用 python3 -m pip
+	pipCommand []string
+
+	// gcStop 关闭后后台 GC 协程退出
+	gcStop     chan struct{}
+	gcStopOnce sync.Once
+
+	// recycled 保存近期被自动回收的 context 及原因，key 为 context ID
+	recycledMu sync.Mutex
+	recycled   map[string]recycledContext
+}
+
+// executeOptions 为单次执行的可选参数
+type executeOptions struct {
+	// Stdin 非 nil 时作为程序的标准输入
+	Stdin *string
+	// ReturnVars 为 true 时在执行成功后返回 python 全局变量快照
+	ReturnVars bool
+	// Parallel 为 true 时允许分派到并行 context 的任一空闲 kernel
+	Parallel bool
 }
 
 type executeStreamHooks struct {
@@ -76,9 +266,10 @@ type executeStreamHooks struct {
 	OnStderr         func(text string)
 	OnStatus         func(state string)
 	OnExecutionCount func(count int64)
+	OnDisplay        func(outputs []models.RichOutput)
 }
 
-func newContextManager() (*contextManager, error) {
+func newContextManager(ctx context.Context, cfg ContextManagerConfig) (*contextManager, error) {
 	// 1. 准备运行目录
 	// 2. 初始化 Jupyter 客户端（指向本容器内的 Jupyter Server）
 	// 3. 启动后台 GC，负责回收空闲 context
@@ -97,71 +288,167 @@ func newContextManager() (*contextManager, error) {
 		return nil, fmt.Errorf("init jupyter client failed: %w", err)
 	}
 
-	m := &contextManager{
-		contexts: make(map[string]*kernelContext),
-		rootDir:  rootDir,
-		jupyter:  jc,
+	m, err := newContextManagerWithClient(cfg, rootDir, jc)
+	if err != nil {
+		return nil, err
 	}
 
-	// 后台协程定时回收空闲 context，限制资源持续增长
-	go m.runGC()
+	// 后台协程定时回收空闲 context，限制资源持续增长，随 ctx 取消退出
+	go m.runGC(ctx)
 
 	return m, nil
 }
 
-func (m *contextManager) runGC() {
-	// 周期扫描：
-	// - 跳过 busy 的 context（避免中断正在执行的任务）
-	// - 对超过空闲阈值的 context 执行强制回收
-	ticker := time.NewTicker(contextGCInterval)
+// newContextManagerWithClient 按配置构造 contextManager，不启动后台 GC
+func newContextManagerWithClient(cfg ContextManagerConfig, rootDir string, jc *jupyter.Client) (*contextManager, error) {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &contextManager{
+		contexts:      make(map[string]*kernelContext),
+		rootDir:       rootDir,
+		jupyter:       jc,
+		workspaceRoot: cfg.WorkspaceRoot,
+
+		maxCount:           cfg.MaxCount,
+		idleTTL:            cfg.IdleTTL,
+		gcInterval:         cfg.GCInterval,
+		defaultTimeoutMs:   cfg.DefaultTimeoutMs,
+		maxRichOutputBytes: cfg.MaxRichOutputBytes,
+		maxOutputBytes:     cfg.MaxOutputBytes,
+		historySize:        cfg.HistorySize,
+		envAllowlist:       make(map[string]struct{}, len(cfg.EnvAllowlist)),
+		gcStop:             make(chan struct{}),
+	}
+	for _, key := range cfg.EnvAllowlist {
+		if key = strings.TrimSpace(key); key != "" {
+			m.envAllowlist[key] = struct{}{}
+		}
+	}
+	return m, nil
+}
+
+// runGC 按 gcInterval 周期执行 gcPass，ctx 取消或 Shutdown 后退出
+func (m *contextManager) runGC(ctx context.Context) {
+	ticker := time.NewTicker(m.gcInterval)
 	defer ticker.Stop()
-	for range ticker.C {
-		now := time.Now()
-		staleIDs := make([]string, 0)
-		m.mu.RLock()
-		for id, ctx := range m.contexts {
-			if ctx.busy.Load() {
-				continue
-			}
-			last := time.Unix(0, ctx.lastActiveUnix.Load())
-			if now.Sub(last) > contextIdleTTL {
-				staleIDs = append(staleIDs, id)
-			}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.gcStop:
+			return
+		case now := <-ticker.C:
+			m.gcPass(now)
+		}
+	}
+}
+
+// gcPass 同步执行一轮空闲回收并返回回收数量：
+// - 跳过 busy 的 context（避免中断正在执行的任务）
+// - 对截至 now 空闲超过阈值的 context 执行强制回收
+func (m *contextManager) gcPass(now time.Time) int {
+	staleIDs := make([]string, 0)
+	m.mu.RLock()
+	for id, ctx := range m.contexts {
+		if ctx.anyBusy() {
+			continue
 		}
-		m.mu.RUnlock()
-		for _, id := range staleIDs {
-			// GC 回收失败不影响下一轮扫描
-			_ = m.removeContext(id, true)
+		last := time.Unix(0, ctx.lastActiveUnix.Load())
+		if now.Sub(last) > m.idleTTL {
+			staleIDs = append(staleIDs, id)
+		}
+	}
+	m.mu.RUnlock()
+	reclaimed := 0
+	for _, id := range staleIDs {
+		// GC 回收失败不影响下一轮扫描
+		m.recordRecycle(id, recycleReasonIdle, fmt.Sprintf("idle for more than %s", m.idleTTL))
+		if err := m.removeContext(id, true); err == nil {
+			metrics.GCReclaimed.Inc()
+			reclaimed++
 		}
 	}
+	return reclaimed
+}
+
+// Shutdown 停止后台 GC 并强制回收全部 context，释放 kernel 与运行目录。
+// 各 context 并发回收，ctx 截止时不再等待尚未完成的回收并返回 ctx.Err()
+func (m *contextManager) Shutdown(ctx context.Context) error {
+	m.gcStopOnce.Do(func() { close(m.gcStop) })
+
+	m.mu.RLock()
+	ids := make([]string, 0, len(m.contexts))
+	for id := range m.contexts {
+		ids = append(ids, id)
+	}
+	m.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = m.removeContextCtx(ctx, id, true)
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
-func (m *contextManager) create(language, cwd string) (*kernelContext, error) {
+func (m *contextManager) create(req models.CreateContextReq) (*kernelContext, error) {
 	// 创建流程：
-	// 1. 校验 cwd 必须位于 /workspace 内
-	// 2. 根据 language 选择运行时（python/bash）
+	// 1. 校验 cwd 必须位于 workspace 根目录内，校验资源上限与环境变量
+	// 2. 根据 language 选择运行时（python/bash/node）
 	// 3. 注册到内存 map
-	// 4. python 分支会在创建后做 probe 探活
-	resolvedCWD, err := resolveContextCWD(cwd)
+	// 4. 在创建超时内反复探活，所有 kernel 就绪后才返回
+	language := req.Language
+	resolvedCWD, err := resolveContextCWD(m.workspaceRoot, req.CWD)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", errCWDOutsideWorkspace, err)
 	}
+	limits := contextLimits{MemBytes: req.MemBytes, CPUMillis: req.CPUMillis}
+	if err := limits.validate(); err != nil {
+		return nil, err
+	}
+	if err := validateContextEnv(req.Env, m.envAllowlist); err != nil {
+		return nil, err
+	}
 	normalizedLanguage := strings.ToLower(strings.TrimSpace(language))
+	if err := validateParallelism(req.Parallelism, normalizedLanguage); err != nil {
+		return nil, err
+	}
 
 	m.mu.Lock()
-	if len(m.contexts) >= contextMaxCount {
+	if len(m.contexts) >= m.maxCount {
 		m.mu.Unlock()
 		return nil, errContextLimitExceeded
 	}
 
-	if normalizedLanguage != contextLanguagePython && normalizedLanguage != contextLanguageBash {
+	if !isSupportedContextLanguage(normalizedLanguage) {
 		m.mu.Unlock()
 		return nil, fmt.Errorf("%w: %s", errUnsupportedLanguage, language)
 	}
+	// node kernel 进程无法在运行时自行施加 rlimit
+	if normalizedLanguage == contextLanguageNode && (limits.MemBytes > 0 || limits.CPUMillis > 0) {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("%w: %w: %s", errInvalidLimits, errLimitsUnsupported, normalizedLanguage)
+	}
 
-	// python/bash context：创建 Jupyter session/kernel。
+	// python/bash/node context：创建 Jupyter session/kernel。
 	contextID := uuid.NewString()
-	notebookPath, err := notebookPathForCWD(contextID, resolvedCWD)
+	notebookPath, err := notebookPathForCWD(m.workspaceRoot, contextID, resolvedCWD)
 	if err != nil {
 		m.mu.Unlock()
 		return nil, err
@@ -198,86 +485,303 @@ func (m *contextManager) create(language, cwd string) (*kernelContext, error) {
 		return nil, fmt.Errorf("jupyter session created but kernel id is empty")
 	}
 
+	var pool []*poolKernel
+	if req.Parallelism > 1 {
+		pool, err = m.createPoolKernels(createCtx, actualID, resolvedCWD, kernelName, req.Parallelism-1)
+		if err != nil {
+			_ = m.jupyter.DeleteSession(context.Background(), actualID)
+			m.mu.Unlock()
+			return nil, err
+		}
+	}
+
+	probeIDs := []string{kernelID}
+	for _, pk := range pool {
+		probeIDs = append(probeIDs, pk.KernelID)
+	}
+	for _, id := range probeIDs {
+		if err := m.waitKernelReady(createCtx, id); err != nil {
+			m.deletePoolSessions(pool)
+			_ = m.jupyter.DeleteSession(context.Background(), actualID)
+			m.mu.Unlock()
+			return nil, err
+		}
+	}
+
 	kctx := &kernelContext{
 		ID:        actualID,
 		Language:  normalizedLanguage,
 		CWD:       resolvedCWD,
 		KernelID:  kernelID,
+		Limits:    limits,
+		Env:       maps.Clone(req.Env),
 		createdAt: time.Now().UTC(),
+		pool:      pool,
 	}
 	now := time.Now().UnixNano()
 	kctx.lastActiveUnix.Store(now)
-	m.contexts[actualID] = kctx
+	m.addContextLocked(kctx)
 	m.mu.Unlock()
 	return kctx, nil
 }
 
+// waitKernelReady 在 ctx 截止前按固定间隔反复探活 kernel，直到收到 kernel_info_reply。
+// 节点繁忙时 kernel 启动较慢，单次探活失败不应导致 context 创建失败
+func (m *contextManager) waitKernelReady(ctx context.Context, kernelID string) error {
+	for attempt := 1; ; attempt++ {
+		probeCtx, cancel := context.WithTimeout(ctx, contextProbeAttemptTimeout)
+		err := m.jupyter.KernelInfo(probeCtx, kernelID)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("kernel %s not ready after %d probes: %w", kernelID, attempt, err)
+		case <-time.After(contextProbeInterval):
+		}
+	}
+}
+
+// addContextLocked 注册 context 并更新活跃数指标，调用方需持有写锁
+func (m *contextManager) addContextLocked(kctx *kernelContext) {
+	if _, exists := m.contexts[kctx.ID]; !exists {
+		metrics.ActiveContexts.Inc()
+	}
+	m.contexts[kctx.ID] = kctx
+}
+
 func (m *contextManager) executeWithHooks(
 	ctx context.Context,
 	contextID, code string,
 	timeoutMs int,
+	opts executeOptions,
 	hooks *executeStreamHooks,
 ) (*models.ExecuteContextResp, error) {
 	// 执行流程：
 	// 1. 查找 context 并校验参数
-	// 2. busy 原子位做串行保护（同一 context 同时只允许一个执行）
+	// 2. busy 原子位做串行保护（同一 kernel 同时只允许一个执行）；
+	//    opts.Parallel 且 context 创建了多个 kernel 时轮转分派到空闲 kernel
 	// 3. 根据 language 走对应执行器
 	kctx := m.get(contextID)
 	if kctx == nil {
-		return nil, errContextNotFound
+		return nil, m.notFound(contextID)
 	}
 
 	if timeoutMs == 0 {
-		timeoutMs = contextDefaultTimeoutMs
+		timeoutMs = m.defaultTimeoutMs
 	}
 
 	if timeoutMs < contextMinTimeoutMs || timeoutMs > contextMaxTimeoutMs {
 		return nil, fmt.Errorf("%w: timeout_ms must be between 100 and 300000", errInvalidTimeoutMS)
 	}
+	if opts.ReturnVars && kctx.Language != contextLanguagePython {
+		return nil, fmt.Errorf("%w: return_vars requires a python context", errUnsupportedLanguage)
+	}
+
+	kernelID, release, ok := kctx.acquireKernel(opts.Parallel)
+	if !ok {
+		return nil, errContextBusy
+	}
+	// 同一个 kernel 只能串行执行，避免状态竞争
+	defer release()
+
+	return m.executeLocked(ctx, contextID, kctx, kernelID, code, timeoutMs, opts, hooks)
+}
+
+// executeBatch 在同一 context 的主 kernel 中按顺序执行多个代码单元，整个批次期间持有 busy 位，
+// 单元之间共享 kernel 状态；context 因超时被回收后不再执行后续单元
+func (m *contextManager) executeBatch(
+	ctx context.Context,
+	contextID string,
+	cells []models.ExecuteBatchCell,
+	stopOnError bool,
+) (*models.ExecuteBatchResp, error) {
+	kctx := m.get(contextID)
+	if kctx == nil {
+		return nil, m.notFound(contextID)
+	}
+	if len(cells) == 0 || len(cells) > contextMaxBatchCells {
+		return nil, fmt.Errorf("%w: cells must contain 1-%d entries", errInvalidBatch, contextMaxBatchCells)
+	}
+	timeouts := make([]int, len(cells))
+	for i, cell := range cells {
+		if strings.TrimSpace(cell.Code) == "" {
+			return nil, fmt.Errorf("%w: cell %d has empty code", errInvalidBatch, i)
+		}
+		timeouts[i] = cell.TimeoutMs
+		if timeouts[i] == 0 {
+			timeouts[i] = m.defaultTimeoutMs
+		}
+		if timeouts[i] < contextMinTimeoutMs || timeouts[i] > contextMaxTimeoutMs {
+			return nil, fmt.Errorf("%w: timeout_ms must be between 100 and 300000", errInvalidTimeoutMS)
+		}
+	}
 
 	if !kctx.busy.CompareAndSwap(false, true) {
 		return nil, errContextBusy
 	}
-	// 同一个 context 只能串行执行，避免状态竞争
 	defer kctx.busy.Store(false)
 
+	resp := &models.ExecuteBatchResp{
+		ContextID: contextID,
+		Results:   make([]models.ExecuteBatchCellResult, 0, len(cells)),
+	}
+	for i, cell := range cells {
+		if ctx.Err() != nil || m.get(contextID) != kctx {
+			resp.Stopped = true
+			break
+		}
+		result := models.ExecuteBatchCellResult{Index: i}
+		out, err := m.executeLocked(ctx, contextID, kctx, kctx.KernelID, cell.Code, timeouts[i], executeOptions{}, nil)
+		if err != nil {
+			result.ContextID = contextID
+			result.Error = err.Error()
+		} else {
+			result.ExecuteContextResp = *out
+		}
+		resp.Results = append(resp.Results, result)
+		if stopOnError && (err != nil || result.ExitCode != 0) && i < len(cells)-1 {
+			resp.Stopped = true
+			break
+		}
+	}
+	return resp, nil
+}
+
+// executeLocked 按 language 分派执行，调用方需已持有 kernelID 对应的 busy 位
+func (m *contextManager) executeLocked(
+	ctx context.Context,
+	contextID string,
+	kctx *kernelContext,
+	kernelID string,
+	code string,
+	timeoutMs int,
+	opts executeOptions,
+	hooks *executeStreamHooks,
+) (*models.ExecuteContextResp, error) {
+	var (
+		resp *models.ExecuteContextResp
+		err  error
+	)
+	start := time.Now()
 	switch kctx.Language {
 	case contextLanguagePython:
-		return m.executePython(ctx, contextID, kctx, code, timeoutMs, hooks)
+		resp, err = m.executePython(ctx, contextID, kctx, kernelID, code, timeoutMs, opts, hooks)
 	case contextLanguageBash:
-		return m.executeBash(ctx, contextID, kctx, code, timeoutMs, hooks)
+		resp, err = m.executeBash(ctx, contextID, kctx, kernelID, code, timeoutMs, opts.Stdin, hooks)
+	case contextLanguageNode:
+		resp, err = m.executeNode(ctx, contextID, kctx, kernelID, code, timeoutMs, opts.Stdin, hooks)
 	default:
 		return nil, fmt.Errorf("%w: %s", errUnsupportedLanguage, kctx.Language)
 	}
+	kctx.history.add(newHistoryEntry(code, start, resp, err), m.historySize)
+	if err == nil && resp != nil {
+		metrics.ObserveExecution(kctx.Language, resp.ExitCode, resp.DurationMs)
+	}
+	return resp, err
 }
 
-func toJupyterHooks(hooks *executeStreamHooks) jupyter.ExecuteHooks {
+// toJupyterHooks 将流式 hook 转换为 jupyter 回调，stdout/stderr 帧与聚合结果使用相同的字节上限
+func (m *contextManager) toJupyterHooks(hooks *executeStreamHooks) jupyter.ExecuteHooks {
 	if hooks == nil {
 		return jupyter.ExecuteHooks{}
 	}
 	return jupyter.ExecuteHooks{
-		OnStdout: hooks.OnStdout,
-		OnStderr: hooks.OnStderr,
+		OnStdout: limitStream(hooks.OnStdout, m.maxOutputBytes),
+		OnStderr: limitStream(hooks.OnStderr, m.maxOutputBytes),
 		OnStatus: hooks.OnStatus,
 		OnExecutionCount: func(count int64) {
 			if hooks.OnExecutionCount != nil {
 				hooks.OnExecutionCount(count)
 			}
 		},
+		OnDisplay: func(bundle jupyter.MimeBundle) {
+			if hooks.OnDisplay != nil {
+				hooks.OnDisplay(richOutputsFromBundle(bundle, m.maxRichOutputBytes))
+			}
+		},
 	}
 }
 
+// limitStream 包装单个输出流的回调，超过 maxBytes 后只转发一次截断提示
+func limitStream(fn func(text string), maxBytes int64) func(text string) {
+	if fn == nil || maxBytes <= 0 {
+		return fn
+	}
+	limiter := jupyter.NewOutputLimiter(maxBytes)
+	return func(text string) {
+		if out := limiter.Take(text); out != "" {
+			fn(out)
+		}
+	}
+}
+
+// richOutputs 将一次执行产生的全部 MIME bundle 展开为富输出列表
+func (m *contextManager) richOutputs(displays []jupyter.MimeBundle) []models.RichOutput {
+	var outputs []models.RichOutput
+	for _, bundle := range displays {
+		outputs = append(outputs, richOutputsFromBundle(bundle, m.maxRichOutputBytes)...)
+	}
+	return outputs
+}
+
 func (m *contextManager) executePython(
 	ctx context.Context,
 	contextID string,
 	kctx *kernelContext,
+	kernelID string,
 	code string,
 	timeoutMs int,
+	opts executeOptions,
 	hooks *executeStreamHooks,
 ) (*models.ExecuteContextResp, error) {
 	// python 执行：
 	// - 仅在第一次执行前注入 os.chdir(cwd)，之后允许用户自行 os.chdir 并在后续执行中保持
 	// - 通过 Jupyter kernel channels websocket 执行并聚合 stdout/stderr
+	// - stdin 通过 kernel 的 stdin channel（input_request/input_reply）逐行提供
+	// - ReturnVars 时附带 user_expression，由 kernel 在执行成功后求值变量快照
+	fullCode, err := withPythonInit(kctx.initCWD(), kctx.Limits, kctx.Env, code)
+	if err != nil {
+		return nil, err
+	}
+	jopts := jupyter.ExecuteOptions{Stdin: opts.Stdin}
+	if opts.ReturnVars {
+		jopts.UserExpressions = map[string]string{contextVarsExpressionKey: pythonVarsExpression()}
+	}
+	return m.executeKernel(ctx, contextID, kctx, kernelID, fullCode, timeoutMs, jopts, hooks)
+}
+
+func (m *contextManager) executeNode(
+	ctx context.Context,
+	contextID string,
+	kctx *kernelContext,
+	kernelID string,
+	code string,
+	timeoutMs int,
+	stdin *string,
+	hooks *executeStreamHooks,
+) (*models.ExecuteContextResp, error) {
+	// node 执行与 python 相同，由 JavaScript kernel 维持跨执行的全局状态；
+	// 未捕获的异常以 execute_reply 的 error 状态体现为非零 exit_code
+	fullCode, err := withNodeInit(kctx.initCWD(), kctx.Env, code)
+	if err != nil {
+		return nil, err
+	}
+	return m.executeKernel(ctx, contextID, kctx, kernelID, fullCode, timeoutMs, jupyter.ExecuteOptions{Stdin: stdin}, hooks)
+}
+
+// executeKernel 在 kernel 中执行已注入初始化逻辑的代码，并按 execute_reply 状态给出 exit_code
+func (m *contextManager) executeKernel(
+	ctx context.Context,
+	contextID string,
+	kctx *kernelContext,
+	kernelID string,
+	fullCode string,
+	timeoutMs int,
+	jopts jupyter.ExecuteOptions,
+	hooks *executeStreamHooks,
+) (*models.ExecuteContextResp, error) {
 	if m.jupyter == nil {
 		return nil, fmt.Errorf("jupyter client is nil")
 	}
@@ -286,24 +790,24 @@ func (m *contextManager) executePython(
 	execCtx, cancel := context.WithTimeout(ctx, time.Duration(timeoutMs+contextTimeoutGraceMillis)*time.Millisecond)
 	defer cancel()
 
-	fullCode, err := withPythonInit(kctx.CWD, code)
-	if err != nil {
-		return nil, err
-	}
-
-	jhooks := toJupyterHooks(hooks)
-	result, runErr := m.jupyter.Execute(execCtx, kctx.KernelID, fullCode, jhooks)
+	jopts.MaxOutputBytes = m.maxOutputBytes
+	jhooks := m.toJupyterHooks(hooks)
+	generation := kctx.generation.Load()
+	result, runErr := m.jupyter.Execute(execCtx, kernelID, fullCode, jopts, jhooks)
 	if runErr != nil && errors.Is(runErr, context.DeadlineExceeded) {
 		// 超时后认为 kernel 可能进入不稳定状态，直接回收重建更安全
-		_ = m.jupyter.InterruptKernel(context.Background(), kctx.KernelID)
-		_ = m.removeContext(contextID, true)
+		// 若执行期间 context 已被强制 reset，kernel 已是全新的，不再回收
+		m.recycleAfterTimeout(contextID, kctx, kernelID, generation, timeoutMs)
 		return &models.ExecuteContextResp{
-			ContextID:      contextID,
-			ExecutionCount: result.ExecutionCount,
-			ExitCode:       124,
-			Stdout:         result.Stdout,
-			Stderr:         result.Stderr,
-			DurationMs:     time.Since(start).Milliseconds(),
+			ContextID:       contextID,
+			ExecutionCount:  result.ExecutionCount,
+			ExitCode:        124,
+			Stdout:          result.Stdout,
+			Stderr:          result.Stderr,
+			StdoutTruncated: result.StdoutTruncated,
+			StderrTruncated: result.StderrTruncated,
+			DurationMs:      time.Since(start).Milliseconds(),
+			Results:         m.richOutputs(result.Displays),
 		}, nil
 	}
 	if runErr != nil {
@@ -311,7 +815,10 @@ func (m *contextManager) executePython(
 	}
 
 	kctx.lastActiveUnix.Store(time.Now().UnixNano())
-	kctx.executionCount.Store(result.ExecutionCount)
+	// 并行模式下各 kernel 独立计数，context 级别的 execution_count 只跟踪主 kernel
+	if kernelID == kctx.KernelID {
+		kctx.executionCount.Store(result.ExecutionCount)
+	}
 
 	exitCode := int32(0)
 	if result.Status == "error" {
@@ -319,12 +826,16 @@ func (m *contextManager) executePython(
 	}
 
 	return &models.ExecuteContextResp{
-		ContextID:      contextID,
-		ExecutionCount: result.ExecutionCount,
-		ExitCode:       exitCode,
-		Stdout:         result.Stdout,
-		Stderr:         result.Stderr,
-		DurationMs:     time.Since(start).Milliseconds(),
+		ContextID:       contextID,
+		ExecutionCount:  result.ExecutionCount,
+		ExitCode:        exitCode,
+		Stdout:          result.Stdout,
+		Stderr:          result.Stderr,
+		StdoutTruncated: result.StdoutTruncated,
+		StderrTruncated: result.StderrTruncated,
+		DurationMs:      time.Since(start).Milliseconds(),
+		Results:         m.richOutputs(result.Displays),
+		Variables:       variablesFromResult(result),
 	}, nil
 }
 
@@ -332,14 +843,18 @@ func (m *contextManager) executeBash(
 	ctx context.Context,
 	contextID string,
 	kctx *kernelContext,
+	kernelID string,
 	code string,
 	timeoutMs int,
+	stdin *string,
 	hooks *executeStreamHooks,
 ) (*models.ExecuteContextResp, error) {
 	// bash 执行（Jupyter bash_kernel）：
 	// - 使用同一个 kernel session，变量/函数/cwd 等状态跨多次执行保留
 	// - 为保持与历史 shell→bash 迁移语义对齐：仅在第一次执行时 cd 到创建 context 的 cwd（后续允许用户 cd 持久化）
 	// - 追加一个服务端 marker 行携带 exit_code，并在 SSE 与最终 stdout 中剥离
+	// - stdin 写入临时文件，脚本的标准输入从该文件重定向，执行结束后删除
+	// - 执行结束时将 pwd 写入 .cwd 文件，kernel 重建后据此恢复 cwd
 	if m.jupyter == nil {
 		return nil, fmt.Errorf("jupyter client is nil")
 	}
@@ -347,38 +862,52 @@ func (m *contextManager) executeBash(
 	execCtx, cancel := context.WithTimeout(ctx, time.Duration(timeoutMs+contextTimeoutGraceMillis)*time.Millisecond)
 	defer cancel()
 
+	stdinPath := ""
+	if stdin != nil {
+		path, err := m.writeStdinFile(contextID, *stdin)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(path)
+		stdinPath = path
+	}
+
+	cwdPath, err := m.cwdFilePath(contextID)
+	if err != nil {
+		return nil, err
+	}
+
 	markerKey := utils.BashExitMarkerPrefix + uuid.NewString()
-	wrapped := withBashInit(kctx.CWD, code, markerKey)
+	wrapped := withBashInit(kctx.initCWD(), kctx.Limits, kctx.Env, code, markerKey, stdinPath, cwdPath)
 
+	// marker 位于输出末尾，聚合的 stdout 被截断时只能从流式 filter 中取得 exit_code，因此 filter 始终生效
 	filter := utils.NewBashExitCodeFilter(markerKey)
-	jhooks := toJupyterHooks(hooks)
-	var stdoutDownstream func(string)
-	if jhooks.OnStdout != nil {
-		stdoutDownstream = jhooks.OnStdout
-		jhooks.OnStdout = func(text string) {
-			if out := filter.HandleChunk(text); out != "" {
-				stdoutDownstream(out)
-			}
+	jhooks := m.toJupyterHooks(hooks)
+	stdoutDownstream := jhooks.OnStdout
+	jhooks.OnStdout = func(text string) {
+		if out := filter.HandleChunk(text); out != "" && stdoutDownstream != nil {
+			stdoutDownstream(out)
 		}
 	}
 
-	result, runErr := m.jupyter.Execute(execCtx, kctx.KernelID, wrapped, jhooks)
-	if stdoutDownstream != nil {
-		if out := filter.Flush(); out != "" {
-			stdoutDownstream(out)
-		}
+	generation := kctx.generation.Load()
+	result, runErr := m.jupyter.Execute(execCtx, kernelID, wrapped, jupyter.ExecuteOptions{MaxOutputBytes: m.maxOutputBytes}, jhooks)
+	if out := filter.Flush(); out != "" && stdoutDownstream != nil {
+		stdoutDownstream(out)
 	}
 
 	if runErr != nil && errors.Is(runErr, context.DeadlineExceeded) {
-		_ = m.jupyter.InterruptKernel(context.Background(), kctx.KernelID)
-		_ = m.removeContext(contextID, true)
+		m.recycleAfterTimeout(contextID, kctx, kernelID, generation, timeoutMs)
 		return &models.ExecuteContextResp{
-			ContextID:      contextID,
-			ExecutionCount: result.ExecutionCount,
-			ExitCode:       124,
-			Stdout:         utils.StripExitMarker(result.Stdout, markerKey),
-			Stderr:         result.Stderr,
-			DurationMs:     time.Since(start).Milliseconds(),
+			ContextID:       contextID,
+			ExecutionCount:  result.ExecutionCount,
+			ExitCode:        124,
+			Stdout:          utils.StripExitMarker(result.Stdout, markerKey),
+			Stderr:          result.Stderr,
+			StdoutTruncated: result.StdoutTruncated,
+			StderrTruncated: result.StderrTruncated,
+			DurationMs:      time.Since(start).Milliseconds(),
+			Results:         m.richOutputs(result.Displays),
 		}, nil
 	}
 	if runErr != nil {
@@ -387,6 +916,9 @@ func (m *contextManager) executeBash(
 
 	kctx.lastActiveUnix.Store(time.Now().UnixNano())
 	kctx.executionCount.Store(result.ExecutionCount)
+	if cwd, ok := m.readCWDFile(contextID); ok {
+		kctx.lastCWD.Store(&cwd)
+	}
 
 	exitCode := int32(0)
 	if parsed, ok := utils.ParseExitMarker(result.Stdout, markerKey); ok {
@@ -398,16 +930,164 @@ func (m *contextManager) executeBash(
 	}
 
 	return &models.ExecuteContextResp{
-		ContextID:      contextID,
-		ExecutionCount: result.ExecutionCount,
-		ExitCode:       exitCode,
-		Stdout:         utils.StripExitMarker(result.Stdout, markerKey),
-		Stderr:         result.Stderr,
-		DurationMs:     time.Since(start).Milliseconds(),
+		ContextID:       contextID,
+		ExecutionCount:  result.ExecutionCount,
+		ExitCode:        exitCode,
+		Stdout:          utils.StripExitMarker(result.Stdout, markerKey),
+		Stderr:          result.Stderr,
+		StdoutTruncated: result.StdoutTruncated,
+		StderrTruncated: result.StderrTruncated,
+		DurationMs:      time.Since(start).Milliseconds(),
+		Results:         m.richOutputs(result.Displays),
 	}, nil
 }
 
+// recycleAfterTimeout 在执行超时后中断 kernel 并回收 context
+// generation 不一致说明执行期间发生过 reset，此时 kernel 已重建，保留 context
+func (m *contextManager) recycleAfterTimeout(contextID string, kctx *kernelContext, kernelID string, generation int64, timeoutMs int) {
+	if kctx.generation.Load() != generation {
+		return
+	}
+	m.recordRecycle(contextID, recycleReasonTimeout, fmt.Sprintf("execution exceeded timeout_ms=%d", timeoutMs))
+	_ = m.jupyter.InterruptKernel(context.Background(), kernelID)
+	_ = m.removeContext(contextID, true)
+}
+
+// interrupt 中断 context 当前正在进行的执行，kernel 与其状态保留
+// 空闲时不向 kernel 发送任何信号，返回 false；否则发送中断并等待 busy 位释放，
+// 确保返回后 context 已可接受新的执行
+func (m *contextManager) interrupt(ctx context.Context, contextID string) (bool, error) {
+	kctx := m.get(contextID)
+	if kctx == nil {
+		return false, m.notFound(contextID)
+	}
+	if m.jupyter == nil {
+		return false, fmt.Errorf("jupyter client is nil")
+	}
+	if !kctx.anyBusy() {
+		return false, nil
+	}
+
+	if err := m.interruptBusyKernels(ctx, kctx); err != nil {
+		return false, fmt.Errorf("interrupt kernel failed: %w", err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, contextInterruptTimeout)
+	defer cancel()
+	ticker := time.NewTicker(20 * time.Millisecond)
+	defer ticker.Stop()
+	for kctx.anyBusy() {
+		select {
+		case <-waitCtx.Done():
+			return true, errInterruptTimeout
+		case <-ticker.C:
+		}
+	}
+	return true, nil
+}
+
+// reset 原地重启 context 对应的 kernel，保留 ID、语言与 CWD，执行计数归零
+// shell context 会将 CWD 恢复为最近一次执行结束时的目录，使 cd 跨 kernel 重建保留
+// 默认仅允许空闲时重置；force 为 true 时会中断正在进行的执行
+func (m *contextManager) reset(ctx context.Context, contextID string, force bool) (*kernelContext, error) {
+	kctx := m.get(contextID)
+	if kctx == nil {
+		return nil, m.notFound(contextID)
+	}
+	if m.jupyter == nil {
+		return nil, fmt.Errorf("jupyter client is nil")
+	}
+
+	kernelIDs := kctx.kernelIDs()
+	if !force {
+		// 占用全部 busy 位，避免重置过程中有新的执行进入
+		release, ok := kctx.acquireAllKernels()
+		if !ok {
+			return nil, errContextBusy
+		}
+		defer release()
+	} else {
+		for _, id := range kernelIDs {
+			_ = m.jupyter.InterruptKernel(ctx, id)
+		}
+	}
+
+	kctx.generation.Add(1)
+	for _, id := range kernelIDs {
+		if err := m.jupyter.RestartKernel(ctx, id); err != nil {
+			return nil, fmt.Errorf("restart kernel failed: %w", err)
+		}
+	}
+
+	if kctx.Language == contextLanguageBash {
+		if cwd, ok := m.readCWDFile(contextID); ok {
+			kctx.cwdMu.Lock()
+			kctx.CWD = cwd
+			kctx.cwdMu.Unlock()
+		}
+	}
+
+	kctx.executionCount.Store(0)
+	kctx.history.reset()
+	kctx.lastActiveUnix.Store(time.Now().UnixNano())
+	return kctx, nil
+}
+
+// cwdFilePath 返回 context 的 .cwd 文件路径，并确保其所在目录存在
+func (m *contextManager) cwdFilePath(contextID string) (string, error) {
+	dir := filepath.Join(m.rootDir, contextID)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("create context dir failed: %w", err)
+	}
+	return filepath.Join(dir, contextCWDFileName), nil
+}
+
+// readCWDFile 读取 bash wrapper 记录的最近工作目录
+// 文件不存在或目录已离开 workspace 根目录时返回 false，调用方保留原有 cwd
+func (m *contextManager) readCWDFile(contextID string) (string, bool) {
+	b, err := os.ReadFile(filepath.Join(m.rootDir, contextID, contextCWDFileName))
+	if err != nil {
+		return "", false
+	}
+	raw := strings.TrimSpace(string(b))
+	if raw == "" || !filepath.IsAbs(raw) {
+		return "", false
+	}
+	cwd, err := resolveContextCWD(m.workspaceRoot, raw)
+	if err != nil {
+		return "", false
+	}
+	return cwd, true
+}
+
+// writeStdinFile 将 stdin 内容写入 context 运行目录下的临时文件并返回其路径
+func (m *contextManager) writeStdinFile(contextID, data string) (string, error) {
+	dir := filepath.Join(m.rootDir, contextID)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("create context dir failed: %w", err)
+	}
+	f, err := os.CreateTemp(dir, "stdin-*")
+	if err != nil {
+		return "", fmt.Errorf("create stdin file failed: %w", err)
+	}
+	if _, err := f.WriteString(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return "", fmt.Errorf("write stdin file failed: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return "", fmt.Errorf("close stdin file failed: %w", err)
+	}
+	return f.Name(), nil
+}
+
 func (m *contextManager) removeContext(contextID string, force bool) error {
+	return m.removeContextCtx(context.Background(), contextID, force)
+}
+
+// removeContextCtx 同 removeContext，Jupyter 侧回收同时受 parent 截止时间约束
+func (m *contextManager) removeContextCtx(parent context.Context, contextID string, force bool) error {
 	// 删除流程：
 	// 1. 从 map 摘除（先摘除再关进程，避免新请求并发进来）
 	// 2. 尝试优雅 shutdown
@@ -418,21 +1098,22 @@ func (m *contextManager) removeContext(contextID string, force bool) error {
 	m.mu.Lock()
 	if existing, ok := m.contexts[contextID]; ok {
 		kctx = existing
-		if !force && kctx.busy.Load() {
+		if !force && kctx.anyBusy() {
 			m.mu.Unlock()
 			return errContextBusy
 		}
 		// 先从 map 删除，阻止后续请求命中正在关闭的 context
 		delete(m.contexts, contextID)
+		metrics.ActiveContexts.Dec()
 	}
 	m.mu.Unlock()
 
 	if kctx == nil {
-		return errContextNotFound
+		return m.notFound(contextID)
 	}
 
 	// Jupyter server 侧回收 session 即可释放 kernel 资源（python/bash 同构）。
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(parent, 5*time.Second)
 	defer cancel()
 	if m.jupyter != nil {
 		err := m.jupyter.DeleteSession(shutdownCtx, contextID)
@@ -445,9 +1126,53 @@ func (m *contextManager) removeContext(contextID string, force bool) error {
 			}
 		}
 	}
+	m.deletePoolSessions(kctx.pool)
+	if m.rootDir != "" {
+		_ = os.RemoveAll(filepath.Join(m.rootDir, contextID))
+	}
 	return nil
 }
 
+// list 返回当前所有 context 的快照，按创建时间升序
+// 读锁保证与 create/removeContext（含 GC）互斥，字段均为原子读取
+func (m *contextManager) list() []models.ContextInfo {
+	m.mu.RLock()
+	snapshot := make([]*kernelContext, 0, len(m.contexts))
+	for _, kctx := range m.contexts {
+		snapshot = append(snapshot, kctx)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(snapshot, func(i, j int) bool {
+		if snapshot[i].createdAt.Equal(snapshot[j].createdAt) {
+			return snapshot[i].ID < snapshot[j].ID
+		}
+		return snapshot[i].createdAt.Before(snapshot[j].createdAt)
+	})
+	infos := make([]models.ContextInfo, 0, len(snapshot))
+	for _, kctx := range snapshot {
+		infos = append(infos, kctx.info())
+	}
+	return infos
+}
+
+func (k *kernelContext) info() models.ContextInfo {
+	return models.ContextInfo{
+		ContextID:      k.ID,
+		Language:       k.Language,
+		CWD:            k.initCWD(),
+		CurrentCWD:     k.currentCWD(),
+		KernelID:       k.KernelID,
+		ExecutionCount: k.executionCount.Load(),
+		Busy:           k.anyBusy(),
+		Parallelism:    k.parallelism(),
+		MemBytes:       k.Limits.MemBytes,
+		CPUMillis:      k.Limits.CPUMillis,
+		CreatedAt:      k.createdAt.Format(time.RFC3339),
+		LastActiveAt:   time.Unix(0, k.lastActiveUnix.Load()).UTC().Format(time.RFC3339),
+	}
+}
+
 func (m *contextManager) get(contextID string) *kernelContext {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -467,6 +1192,9 @@ func (m *contextManager) searchKernel(ctx context.Context, normalizedLanguage st
 	case contextLanguageBash:
 		// 优先使用常见的 bash kernelspec 名称；否则选择任意 bash kernelspec。
 		return pickKernelBySpecLanguage(specs, contextLanguageBash, []string{"bash", "bash_kernel"}, nil, "")
+	case contextLanguageNode:
+		// ijavascript 注册的 kernelspec 名称与 language 均为 javascript。
+		return pickKernelBySpecLanguage(specs, "javascript", []string{"javascript", "nodejs"}, nil, "")
 	default:
 		return "", fmt.Errorf("%w: %s", errUnsupportedLanguage, normalizedLanguage)
 	}
@@ -495,29 +1223,38 @@ func (m *contextManager) getKernelSpecsWithRetry(ctx context.Context) (*jupyter.
 	return specs, nil
 }
 
+func isSupportedContextLanguage(language string) bool {
+	switch language {
+	case contextLanguagePython, contextLanguageBash, contextLanguageNode:
+		return true
+	default:
+		return false
+	}
+}
+
 func shellQuote(s string) string {
 	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
 }
 
-func resolveContextCWD(input string) (string, error) {
+func resolveContextCWD(workspaceRoot, input string) (string, error) {
 	// cwd 解析规则：
-	// - 空值默认 /workspace
-	// - 相对路径按 /workspace 拼接
+	// - 空值默认 workspaceRoot
+	// - 相对路径按 workspaceRoot 拼接
 	// - 绝对路径与相对路径都要经过 Clean
-	// - 最终必须仍在 /workspace 内，防止目录穿越
+	// - 最终必须仍在 workspaceRoot 内，防止目录穿越
+	root := filepath.Clean(workspaceRoot)
 	raw := strings.TrimSpace(input)
 	if raw == "" {
-		raw = contextWorkspaceRoot
+		raw = root
 	}
 	var candidate string
 	if filepath.IsAbs(raw) {
 		candidate = filepath.Clean(raw)
 	} else {
-		candidate = filepath.Clean(filepath.Join(contextWorkspaceRoot, raw))
+		candidate = filepath.Clean(filepath.Join(root, raw))
 	}
-	root := filepath.Clean(contextWorkspaceRoot)
 	if candidate != root && !strings.HasPrefix(candidate, root+string(filepath.Separator)) {
-		return "", fmt.Errorf("cwd must be inside /workspace")
+		return "", fmt.Errorf("cwd must be inside %s", root)
 	}
 	return candidate, nil
 }
@@ -566,8 +1303,8 @@ func pickKernelBySpecLanguage(
 	return "", fmt.Errorf("no kernelspec found for language=%s", specLanguage)
 }
 
-func notebookPathForCWD(contextID, cwd string) (string, error) {
-	// Jupyter session 的 "path" 相对 Jupyter server root（我们使用 notebook-dir=/workspace）。
+func notebookPathForCWD(workspaceRoot, contextID, cwd string) (string, error) {
+	// Jupyter session 的 "path" 相对 Jupyter server root（notebook-dir 与 workspaceRoot 一致）。
 	// 将 notebook 存到 <cwd>/.agentland_contexts/<contextID>.ipynb。
 	dir := filepath.Join(cwd, ".agentland_contexts")
 	if err := os.MkdirAll(dir, 0o700); err != nil {
@@ -575,7 +1312,7 @@ func notebookPathForCWD(contextID, cwd string) (string, error) {
 	}
 	abs := filepath.Join(dir, contextID+".ipynb")
 
-	rel, err := filepath.Rel(contextWorkspaceRoot, abs)
+	rel, err := filepath.Rel(workspaceRoot, abs)
 	if err != nil {
 		return "", fmt.Errorf("rel notebook path failed: %w", err)
 	}
@@ -586,7 +1323,7 @@ func notebookPathForCWD(contextID, cwd string) (string, error) {
 	return filepath.ToSlash(rel), nil
 }
 
-func withPythonInit(cwd, code string) (string, error) {
+func withPythonInit(cwd string, limits contextLimits, env map[string]string, code string) (string, error) {
 	// 使用 JSON 字符串编码，保证可作为 Python 字符串字面量安全拼接。
 	b, err := json.Marshal(cwd)
 	if err != nil {
@@ -594,24 +1331,96 @@ func withPythonInit(cwd, code string) (string, error) {
 	}
 	// Initialize cwd only once for this kernel session; allow later `os.chdir` to persist across executions.
 	// This keeps "interactive Python" semantics closer to bash.
-	return strings.Join([]string{
+	// 资源上限与环境变量同样只在首次执行时施加（kernel 重启后 globals 清空，会重新施加）。
+	lines := []string{
 		"import os",
 		"if '__agentland_cwd_inited' not in globals():",
-		"\tos.chdir(" + string(b) + ")",
-		"\t__agentland_cwd_inited = True",
+	}
+	if limits.MemBytes > 0 || limits.CPUMillis > 0 {
+		lines = append(lines, "	import resource")
+	}
+	if limits.MemBytes > 0 {
+		lines = append(lines, fmt.Sprintf("	resource.setrlimit(resource.RLIMIT_AS, (%d, %d))", limits.MemBytes, limits.MemBytes))
+	}
+	if limits.CPUMillis > 0 {
+		sec := limits.cpuSeconds()
+		lines = append(lines, fmt.Sprintf("	resource.setrlimit(resource.RLIMIT_CPU, (%d, %d))", sec, sec))
+	}
+	if len(env) > 0 {
+		// JSON object 同时是合法的 Python dict 字面量
+		envJSON, err := json.Marshal(env)
+		if err != nil {
+			return "", fmt.Errorf("encode env failed: %w", err)
+		}
+		lines = append(lines, "	os.environ.update("+string(envJSON)+")")
+	}
+	lines = append(lines,
+		"	os.chdir("+string(b)+")",
+		"	__agentland_cwd_inited = True",
+		code,
+	)
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+func withNodeInit(cwd string, env map[string]string, code string) (string, error) {
+	// JSON 字符串/对象同时是合法的 JavaScript 字面量。
+	// 与 python 一致，仅在 kernel 首次执行时切换 cwd 并注入环境变量；kernel 重启后全局对象重建，会重新初始化。
+	b, err := json.Marshal(cwd)
+	if err != nil {
+		return "", fmt.Errorf("encode cwd failed: %w", err)
+	}
+	init := "process.chdir(" + string(b) + ");"
+	if len(env) > 0 {
+		envJSON, err := json.Marshal(env)
+		if err != nil {
+			return "", fmt.Errorf("encode env failed: %w", err)
+		}
+		init += " Object.assign(process.env, " + string(envJSON) + ");"
+	}
+	return strings.Join([]string{
+		"if (typeof globalThis.__agentland_cwd_inited === 'undefined') { " + init + " globalThis.__agentland_cwd_inited = true; }",
 		code,
 	}, "\n") + "\n", nil
 }
 
-func withBashInit(cwd, code, markerKey string) string {
-	// 仅在本 kernel session 第一次执行时初始化 cwd；之后允许用户 `cd` 并在后续执行中保持。
+func withBashInit(cwd string, limits contextLimits, env map[string]string, code, markerKey, stdinPath, cwdPath string) string {
+	// 仅在本 kernel session 第一次执行时初始化 cwd、资源上限与环境变量；之后允许用户 `cd` 并在后续执行中保持。
 	// 在输出中追加一行包含 exit_code 的 marker（服务端会在 SSE 与最终 stdout 中剥离）。
+	// stdinPath 非空时用 { ... } 包裹脚本并重定向标准输入；花括号在当前 shell 执行，状态仍可跨执行保留。
+	// cwdPath 非空时在 marker 之前将 pwd 写入该文件，写入失败不影响 exit_code。
 	quotedCWD := shellQuote(cwd)
 	quotedMarkerKey := shellQuote(markerKey)
-	return strings.Join([]string{
-		`if [ -z "${__agentland_cwd_inited+x}" ]; then cd ` + quotedCWD + `; __agentland_cwd_inited=1; fi`,
+	if stdinPath != "" {
+		code = "{\n" + code + "\n} < " + shellQuote(stdinPath)
+	}
+	init := "cd " + quotedCWD + "; __agentland_cwd_inited=1"
+	if len(env) > 0 {
+		keys := make([]string, 0, len(env))
+		for key := range env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		exports := make([]string, 0, len(keys))
+		for _, key := range keys {
+			exports = append(exports, "export "+key+"="+shellQuote(env[key])+"; ")
+		}
+		init = strings.Join(exports, "") + init
+	}
+	if limits.CPUMillis > 0 {
+		init = fmt.Sprintf("ulimit -t %d; ", limits.cpuSeconds()) + init
+	}
+	if limits.MemBytes > 0 {
+		// ulimit -v 的单位为 KiB
+		init = fmt.Sprintf("ulimit -v %d; ", limits.MemBytes/1024) + init
+	}
+	lines := []string{
+		`if [ -z "${__agentland_cwd_inited+x}" ]; then ` + init + `; fi`,
 		code,
 		`__agentland_ec=$?`,
-		`printf '%s=%s\n' ` + quotedMarkerKey + ` "$__agentland_ec"`,
-	}, "\n") + "\n"
+	}
+	if cwdPath != "" {
+		lines = append(lines, `pwd > `+shellQuote(cwdPath)+` 2>/dev/null`)
+	}
+	lines = append(lines, `printf '%s=%s\n' `+quotedMarkerKey+` "$__agentland_ec"`)
+	return strings.Join(lines, "\n") + "\n"
 }
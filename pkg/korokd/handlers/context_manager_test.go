@@ -0,0 +1,1492 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/audit"
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
+	"github.com/Fl0rencess720/agentland/pkg/korokd/pkgs/jupyter"
+	"github.com/Fl0rencess720/agentland/pkg/korokd/pkgs/metrics"
+	"github.com/Fl0rencess720/agentland/pkg/korokd/pkgs/utils"
+	"github.com/gin-gonic/gin"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+// fakeKernelReply 描述 fake kernel 对一次 execute_request 的响应
+type fakeKernelReply struct {
+	Stdout string
+	Stderr string
+	Status string
+	// Displays 为依次发送的 display_data MIME bundle
+	Displays []map[string]any
+	// Inputs 为 kernel 发起的 input_request 次数，收到的每个 input_reply 值会按行回显到 stdout
+	Inputs int
+	// Hang 为 true 时输出 stdout/stderr 后不再回复，用于模拟超时
+	Hang bool
+	// IgnoreInterrupt 为 true 时 Hang 的执行不响应中断，只能等待超时
+	IgnoreInterrupt bool
+	// UserExpressions 原样放入 execute_reply 的 user_expressions
+	UserExpressions map[string]any
+}
+
+type fakeJupyterMessage struct {
+	Header       map[string]any  `json:"header"`
+	ParentHeader map[string]any  `json:"parent_header"`
+	Metadata     map[string]any  `json:"metadata"`
+	Content      json.RawMessage `json:"content"`
+	Channel      string          `json:"channel"`
+}
+
+// fakeJupyter 是一个最小化的 Jupyter Server，实现 korokd 用到的 REST 与 kernel channels 接口
+type fakeJupyter struct {
+	server    *httptest.Server
+	execCount atomic.Int64
+	// interruptCh 收到中断请求时写入，Hang 中的执行据此以 KeyboardInterrupt 结束
+	interruptCh chan struct{}
+
+	mu              sync.Mutex
+	onExecute       func(code string) fakeKernelReply
+	interrupted     []string
+	restarted       []string
+	deletedSessions []string
+	// userExpressions 为最近一次 execute_request 携带的 user_expressions
+	userExpressions map[string]string
+
+	// kernelInfoFailures 为接下来需要失败的 kernel_info_request 次数，失败时直接断开连接模拟 kernel 未就绪
+	kernelInfoFailures atomic.Int64
+	kernelInfoCount    atomic.Int64
+}
+
+func newFakeJupyter(t *testing.T, onExecute func(code string) fakeKernelReply) *fakeJupyter {
+	t.Helper()
+
+	fj := &fakeJupyter{onExecute: onExecute, interruptCh: make(chan struct{}, 8)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/kernelspecs", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"kernelspecs":{"python3":{"spec":{"language":"python"}},"bash":{"spec":{"language":"bash"}},"javascript":{"spec":{"language":"javascript"}}}}`))
+	})
+	mux.HandleFunc("POST /api/sessions", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Name string `json:"name"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_ = json.NewEncoder(w).Encode(jupyter.Session{ID: req.Name, Kernel: jupyter.Kernel{ID: "kernel-" + req.Name}})
+	})
+	mux.HandleFunc("DELETE /api/sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		fj.mu.Lock()
+		fj.deletedSessions = append(fj.deletedSessions, r.PathValue("id"))
+		fj.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /api/kernels/{id}/interrupt", func(w http.ResponseWriter, r *http.Request) {
+		fj.mu.Lock()
+		fj.interrupted = append(fj.interrupted, r.PathValue("id"))
+		fj.mu.Unlock()
+		select {
+		case fj.interruptCh <- struct{}{}:
+		default:
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /api/kernels/{id}/restart", func(w http.ResponseWriter, r *http.Request) {
+		fj.mu.Lock()
+		fj.restarted = append(fj.restarted, r.PathValue("id"))
+		fj.mu.Unlock()
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.Handle("/api/kernels/{id}/channels", websocket.Handler(fj.serveChannels))
+
+	fj.server = httptest.NewServer(mux)
+	t.Cleanup(fj.server.Close)
+	return fj
+}
+
+func (fj *fakeJupyter) serveChannels(conn *websocket.Conn) {
+	for {
+		var req fakeJupyterMessage
+		if err := websocket.JSON.Receive(conn, &req); err != nil {
+			return
+		}
+		if req.Header["msg_type"] == "kernel_info_request" {
+			fj.kernelInfoCount.Add(1)
+			if fj.kernelInfoFailures.Add(-1) >= 0 {
+				return
+			}
+			_ = websocket.JSON.Send(conn, fakeJupyterMessage{
+				Header:       map[string]any{"msg_type": "kernel_info_reply"},
+				ParentHeader: req.Header,
+				Content:      json.RawMessage(`{"status":"ok"}`),
+			})
+			continue
+		}
+		var content struct {
+			Code            string            `json:"code"`
+			UserExpressions map[string]string `json:"user_expressions"`
+		}
+		_ = json.Unmarshal(req.Content, &content)
+		fj.mu.Lock()
+		fj.userExpressions = content.UserExpressions
+		fj.mu.Unlock()
+
+		fj.mu.Lock()
+		onExecute := fj.onExecute
+		fj.mu.Unlock()
+		reply := fakeKernelReply{}
+		if onExecute != nil {
+			reply = onExecute(content.Code)
+		}
+		count := fj.execCount.Add(1)
+
+		send := func(msgType string, content any) {
+			b, _ := json.Marshal(content)
+			_ = websocket.JSON.Send(conn, fakeJupyterMessage{
+				Header:       map[string]any{"msg_type": msgType},
+				ParentHeader: req.Header,
+				Content:      b,
+			})
+		}
+
+		send("status", map[string]any{"execution_state": "busy"})
+		send("execute_input", map[string]any{"execution_count": count})
+		for i := 0; i < reply.Inputs; i++ {
+			send("input_request", map[string]any{"prompt": "", "password": false})
+			var inputReply fakeJupyterMessage
+			if err := websocket.JSON.Receive(conn, &inputReply); err != nil {
+				return
+			}
+			var value struct {
+				Value string `json:"value"`
+			}
+			_ = json.Unmarshal(inputReply.Content, &value)
+			send("stream", map[string]any{"name": "stdout", "text": value.Value + "\n"})
+		}
+		if reply.Stdout != "" {
+			send("stream", map[string]any{"name": "stdout", "text": reply.Stdout})
+		}
+		if reply.Stderr != "" {
+			send("stream", map[string]any{"name": "stderr", "text": reply.Stderr})
+		}
+		for _, bundle := range reply.Displays {
+			send("display_data", map[string]any{"data": bundle, "metadata": map[string]any{}})
+		}
+		if reply.Hang {
+			// 挂起直到客户端断开（超时）或收到中断
+			closed := make(chan struct{})
+			go func() {
+				defer close(closed)
+				var discard fakeJupyterMessage
+				for websocket.JSON.Receive(conn, &discard) == nil {
+				}
+			}()
+			interruptCh := fj.interruptCh
+			if reply.IgnoreInterrupt {
+				interruptCh = nil
+			}
+			select {
+			case <-closed:
+			case <-interruptCh:
+				send("error", map[string]any{"ename": "KeyboardInterrupt", "evalue": "", "traceback": []string{"KeyboardInterrupt"}})
+				send("execute_reply", map[string]any{"status": "error", "execution_count": count})
+				send("status", map[string]any{"execution_state": "idle"})
+				<-closed
+			}
+			return
+		}
+		status := reply.Status
+		if status == "" {
+			status = "ok"
+		}
+		send("execute_reply", map[string]any{"status": status, "execution_count": count, "user_expressions": reply.UserExpressions})
+		send("status", map[string]any{"execution_state": "idle"})
+	}
+}
+
+func (fj *fakeJupyter) interruptedKernels() []string {
+	fj.mu.Lock()
+	defer fj.mu.Unlock()
+	return append([]string(nil), fj.interrupted...)
+}
+
+func (fj *fakeJupyter) restartedKernels() []string {
+	fj.mu.Lock()
+	defer fj.mu.Unlock()
+	return append([]string(nil), fj.restarted...)
+}
+
+// newTestContextManager 构造一个不启动 GC、直接指向 fake Jupyter 的 contextManager
+func newTestContextManager(t *testing.T, fj *fakeJupyter) *contextManager {
+	t.Helper()
+
+	jc, err := jupyter.NewClient(fj.server.URL, "")
+	require.NoError(t, err)
+	m, err := newContextManagerWithClient(ContextManagerConfig{}, t.TempDir(), jc)
+	require.NoError(t, err)
+	return m
+}
+
+// addTestContext 直接注册一个 context，跳过需要 /workspace 的 create 流程
+func addTestContext(m *contextManager, id, language string) *kernelContext {
+	kctx := &kernelContext{
+		ID:        id,
+		Language:  language,
+		CWD:       contextWorkspaceRoot,
+		KernelID:  "kernel-" + id,
+		createdAt: time.Now().UTC(),
+	}
+	kctx.lastActiveUnix.Store(time.Now().UnixNano())
+	m.mu.Lock()
+	m.addContextLocked(kctx)
+	m.mu.Unlock()
+	return kctx
+}
+
+func decodeSSEEvents(t *testing.T, body string) []models.ExecuteStreamEvent {
+	t.Helper()
+
+	events := make([]models.ExecuteStreamEvent, 0)
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var evt models.ExecuteStreamEvent
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &evt))
+		events = append(events, evt)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func executeViaHandler(t *testing.T, m *contextManager, contextID, body string) []models.ExecuteStreamEvent {
+	t.Helper()
+	gin.SetMode(gin.ReleaseMode)
+
+	router := gin.New()
+	h := &CodeInterpreterHandler{contexts: m}
+	router.POST("/contexts/:contextId/execute", h.ExecuteInContext)
+
+	req := httptest.NewRequest(http.MethodPost, "/contexts/"+contextID+"/execute", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	return decodeSSEEvents(t, w.Body.String())
+}
+
+func TestExecuteInContext_StreamsOutputAndCompletesWithCount(t *testing.T) {
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		return fakeKernelReply{Stdout: "hello\n", Stderr: "warn\n"}
+	})
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-1", contextLanguagePython)
+
+	events := executeViaHandler(t, m, "ctx-1", `{"code":"print('hello')"}`)
+
+	types := make([]string, 0, len(events))
+	for _, evt := range events {
+		if evt.Type != "ping" {
+			types = append(types, evt.Type)
+		}
+	}
+	require.Equal(t, "init", types[0])
+	require.Contains(t, types, "stdout")
+	require.Contains(t, types, "stderr")
+
+	last := events[len(events)-1]
+	require.Equal(t, "execution_complete", last.Type)
+	require.Equal(t, "ctx-1", last.ContextID)
+	require.Equal(t, int64(1), last.ExecutionCount)
+	require.Equal(t, int32(0), last.ExitCode)
+	require.GreaterOrEqual(t, last.ExecutionTime, int64(0))
+	require.Equal(t, int64(1), m.get("ctx-1").executionCount.Load())
+}
+
+func TestExecuteInContext_DecodesBase64Code(t *testing.T) {
+	script := "msg = \"it's \\\"quoted\\\"\"\nfor i in range(2):\n\tprint(msg, i)\n"
+	var got string
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		got = code
+		return fakeKernelReply{Stdout: "ok\n"}
+	})
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-b64", contextLanguagePython)
+
+	body, err := json.Marshal(models.ExecuteContextReq{
+		Code:         base64.StdEncoding.EncodeToString([]byte(script)),
+		CodeEncoding: "base64",
+	})
+	require.NoError(t, err)
+	events := executeViaHandler(t, m, "ctx-b64", string(body))
+
+	last := events[len(events)-1]
+	require.Equal(t, "execution_complete", last.Type)
+	require.Equal(t, int32(0), last.ExitCode)
+	require.Contains(t, got, script)
+}
+
+func TestExecuteInContext_TimeoutMidStreamRecyclesContext(t *testing.T) {
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		return fakeKernelReply{Stdout: "partial\n", Hang: true}
+	})
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-timeout", contextLanguagePython)
+
+	events := executeViaHandler(t, m, "ctx-timeout", `{"code":"while True: pass","timeout_ms":100}`)
+
+	var sawPartial bool
+	for _, evt := range events {
+		if evt.Type == "stdout" && evt.Text == "partial\n" {
+			sawPartial = true
+		}
+	}
+	require.True(t, sawPartial, "stdout emitted before timeout should still be streamed")
+
+	last := events[len(events)-1]
+	require.Equal(t, "execution_complete", last.Type)
+	require.Equal(t, int32(124), last.ExitCode)
+	require.Nil(t, m.get("ctx-timeout"))
+	require.Equal(t, []string{"kernel-ctx-timeout"}, fj.interruptedKernels())
+}
+
+func TestExecute_TimeoutRecordsRecycleReason(t *testing.T) {
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		return fakeKernelReply{Hang: true}
+	})
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-timeout", contextLanguagePython)
+
+	resp, err := m.executeWithHooks(t.Context(), "ctx-timeout", "while True: pass", 100, executeOptions{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, int32(124), resp.ExitCode)
+
+	_, err = m.executeWithHooks(t.Context(), "ctx-timeout", "1", 0, executeOptions{}, nil)
+	require.ErrorIs(t, err, errContextNotFound)
+	require.ErrorContains(t, err, "recycled (timeout)")
+
+	recycled := m.listRecycled()
+	require.Len(t, recycled, 1)
+	require.Equal(t, "ctx-timeout", recycled[0].ContextID)
+	require.Equal(t, recycleReasonTimeout, recycled[0].Reason)
+	require.Contains(t, recycled[0].Detail, "timeout_ms=100")
+
+	// 超过保留期后只返回普通的 not found
+	m.recycledMu.Lock()
+	rc := m.recycled["ctx-timeout"]
+	rc.at = rc.at.Add(-contextRecycledTTL - time.Second)
+	m.recycled["ctx-timeout"] = rc
+	m.recycledMu.Unlock()
+	require.Equal(t, errContextNotFound, m.notFound("ctx-timeout"))
+	require.Empty(t, m.listRecycled())
+}
+
+func TestExecute_PythonStdinFeedsInputRequests(t *testing.T) {
+	large := strings.Repeat("x", 70*1024)
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		return fakeKernelReply{Inputs: 3}
+	})
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-py", contextLanguagePython)
+
+	stdin := "first\n" + large + "\n"
+	resp, err := m.executeWithHooks(t.Context(), "ctx-py", "input()", 0, executeOptions{Stdin: &stdin}, nil)
+	require.NoError(t, err)
+	// stdin 耗尽后的 input_request 得到空串
+	require.Equal(t, "first\n"+large+"\n\n", resp.Stdout)
+}
+
+func TestExecute_PythonEmptyStdinRepliesEmptyLine(t *testing.T) {
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		return fakeKernelReply{Inputs: 1}
+	})
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-py", contextLanguagePython)
+
+	empty := ""
+	resp, err := m.executeWithHooks(t.Context(), "ctx-py", "input()", 0, executeOptions{Stdin: &empty}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "\n", resp.Stdout)
+}
+
+func TestExecute_BashStdinRedirectsFromTempFile(t *testing.T) {
+	cases := []struct {
+		name  string
+		stdin string
+	}{
+		{name: "empty", stdin: ""},
+		{name: "large", stdin: strings.Repeat("line of input\n", 6*1024)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var seenPath, seenContent string
+			fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+				idx := strings.Index(code, "} < '")
+				if idx < 0 {
+					return fakeKernelReply{Status: "error"}
+				}
+				rest := code[idx+len("} < '"):]
+				seenPath = rest[:strings.Index(rest, "'")]
+				b, err := os.ReadFile(seenPath)
+				if err != nil {
+					return fakeKernelReply{Status: "error"}
+				}
+				seenContent = string(b)
+				return fakeKernelReply{}
+			})
+			m := newTestContextManager(t, fj)
+			addTestContext(m, "ctx-sh", contextLanguageBash)
+
+			stdin := tc.stdin
+			resp, err := m.executeWithHooks(t.Context(), "ctx-sh", "read x; echo $x", 0, executeOptions{Stdin: &stdin}, nil)
+			require.NoError(t, err)
+			require.Equal(t, int32(0), resp.ExitCode)
+			require.Equal(t, tc.stdin, seenContent)
+			require.True(t, strings.HasPrefix(seenPath, filepath.Join(m.rootDir, "ctx-sh")))
+			_, statErr := os.Stat(seenPath)
+			require.True(t, os.IsNotExist(statErr), "stdin file should be removed after execution")
+		})
+	}
+}
+
+func TestWithBashInit_NoStdinLeavesCodeUnwrapped(t *testing.T) {
+	wrapped := withBashInit("/workspace", contextLimits{}, nil, "echo hi", "marker", "", "")
+	require.NotContains(t, wrapped, "} <")
+
+	wrapped = withBashInit("/workspace", contextLimits{}, nil, "read x", "marker", "/tmp/stdin-1", "")
+	require.Contains(t, wrapped, "{\nread x\n} < '/tmp/stdin-1'")
+}
+
+func TestListContexts_ReturnsSnapshotOrderedByCreation(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	fj := newFakeJupyter(t, nil)
+	m := newTestContextManager(t, fj)
+	older := addTestContext(m, "ctx-b", contextLanguageBash)
+	older.createdAt = time.Now().Add(-time.Minute).UTC()
+	newer := addTestContext(m, "ctx-a", contextLanguagePython)
+	newer.busy.Store(true)
+	newer.executionCount.Store(3)
+
+	router := gin.New()
+	h := &CodeInterpreterHandler{contexts: m}
+	router.GET("/contexts", h.ListContexts)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/contexts", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.ListContextsResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Len(t, resp.Contexts, 2)
+	require.Equal(t, "ctx-b", resp.Contexts[0].ContextID)
+	require.Equal(t, contextLanguageBash, resp.Contexts[0].Language)
+	require.False(t, resp.Contexts[0].Busy)
+	require.Equal(t, "ctx-a", resp.Contexts[1].ContextID)
+	require.Equal(t, "kernel-ctx-a", resp.Contexts[1].KernelID)
+	require.True(t, resp.Contexts[1].Busy)
+	require.Equal(t, int64(3), resp.Contexts[1].ExecutionCount)
+}
+
+func TestListContexts_ConcurrentWithRemoval(t *testing.T) {
+	fj := newFakeJupyter(t, nil)
+	m := newTestContextManager(t, fj)
+	for i := 0; i < 16; i++ {
+		addTestContext(m, "ctx-"+strings.Repeat("x", i+1), contextLanguagePython)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 16; i++ {
+			_ = m.removeContext("ctx-"+strings.Repeat("x", i+1), true)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 64; i++ {
+			_ = m.list()
+		}
+	}()
+	wg.Wait()
+	require.Empty(t, m.list())
+}
+
+func TestResetContext_RestartsKernelInPlace(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	fj := newFakeJupyter(t, nil)
+	m := newTestContextManager(t, fj)
+	kctx := addTestContext(m, "ctx-1", contextLanguagePython)
+	kctx.executionCount.Store(5)
+
+	router := gin.New()
+	h := &CodeInterpreterHandler{contexts: m}
+	router.POST("/contexts/:contextId/reset", h.ResetContext)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/contexts/ctx-1/reset", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.ContextInfo
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Equal(t, "ctx-1", resp.ContextID)
+	require.Equal(t, contextWorkspaceRoot, resp.CWD)
+	require.Equal(t, int64(0), resp.ExecutionCount)
+	require.False(t, resp.Busy)
+	require.Equal(t, []string{"kernel-ctx-1"}, fj.restartedKernels())
+	require.Same(t, kctx, m.get("ctx-1"))
+}
+
+func TestResetContext_BusyRequiresForce(t *testing.T) {
+	fj := newFakeJupyter(t, nil)
+	m := newTestContextManager(t, fj)
+	kctx := addTestContext(m, "ctx-1", contextLanguagePython)
+	kctx.busy.Store(true)
+
+	_, err := m.reset(t.Context(), "ctx-1", false)
+	require.ErrorIs(t, err, errContextBusy)
+	require.Empty(t, fj.restartedKernels())
+
+	_, err = m.reset(t.Context(), "ctx-1", true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"kernel-ctx-1"}, fj.interruptedKernels())
+	require.Equal(t, []string{"kernel-ctx-1"}, fj.restartedKernels())
+}
+
+func TestContextHandlers_MapBusyAndNotFound(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	fj := newFakeJupyter(t, nil)
+	m := newTestContextManager(t, fj)
+	kctx := addTestContext(m, "ctx-1", contextLanguagePython)
+	kctx.busy.Store(true)
+	m.recordRecycle("ctx-gone", recycleReasonIdle, "idle for more than 30m0s")
+
+	router := gin.New()
+	h := &CodeInterpreterHandler{contexts: m}
+	router.POST("/contexts/:contextId/reset", h.ResetContext)
+	router.POST("/contexts/:contextId/interrupt", h.InterruptContext)
+	router.GET("/contexts/:contextId/history", h.GetContextHistory)
+	router.DELETE("/contexts/:contextId", h.DeleteContext)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	w := serve(http.MethodPost, "/contexts/ctx-1/reset")
+	require.Equal(t, http.StatusConflict, w.Code)
+	require.Contains(t, w.Body.String(), string(response.CodeContextBusy))
+	w = serve(http.MethodDelete, "/contexts/ctx-1")
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	for _, req := range []struct{ method, path string }{
+		{http.MethodPost, "/contexts/missing/reset"},
+		{http.MethodPost, "/contexts/missing/interrupt"},
+		{http.MethodGet, "/contexts/missing/history"},
+		{http.MethodDelete, "/contexts/missing"},
+	} {
+		w := serve(req.method, req.path)
+		require.Equal(t, http.StatusNotFound, w.Code, req.path)
+		require.Contains(t, w.Body.String(), string(response.CodeNotFound), req.path)
+	}
+
+	// 被自动回收的 context 返回 404 并说明回收原因
+	w = serve(http.MethodGet, "/contexts/ctx-gone/history")
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Contains(t, w.Body.String(), "recycled ("+recycleReasonIdle+")")
+}
+
+func TestResetContext_InvalidForceQuery(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	fj := newFakeJupyter(t, nil)
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-1", contextLanguagePython)
+
+	router := gin.New()
+	h := &CodeInterpreterHandler{contexts: m}
+	router.POST("/contexts/:contextId/reset", h.ResetContext)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/contexts/ctx-1/reset?force=maybe", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestExecute_TimeoutAfterForcedResetKeepsContext(t *testing.T) {
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		return fakeKernelReply{Hang: true, IgnoreInterrupt: true}
+	})
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-1", contextLanguagePython)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = m.executeWithHooks(t.Context(), "ctx-1", "while True: pass", 100, executeOptions{}, nil)
+	}()
+	require.Eventually(t, func() bool { return m.get("ctx-1").busy.Load() }, time.Second, 10*time.Millisecond)
+
+	_, err := m.reset(t.Context(), "ctx-1", true)
+	require.NoError(t, err)
+	<-done
+
+	require.NotNil(t, m.get("ctx-1"), "context reset during execution must survive the execution timeout")
+}
+
+func TestResetContext_BashRestoresLastCWD(t *testing.T) {
+	var lastCode atomic.Value
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		lastCode.Store(code)
+		// 模拟 bash wrapper：用户 cd 之后由 pwd 写入 .cwd 文件
+		idx := strings.Index(code, "pwd > '")
+		if idx < 0 {
+			return fakeKernelReply{Status: "error"}
+		}
+		rest := code[idx+len("pwd > '"):]
+		if err := os.WriteFile(rest[:strings.Index(rest, "'")], []byte("/workspace/sub\n"), 0o600); err != nil {
+			return fakeKernelReply{Status: "error"}
+		}
+		return fakeKernelReply{}
+	})
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-sh", contextLanguageBash)
+
+	_, err := m.executeWithHooks(t.Context(), "ctx-sh", "cd /workspace/sub", 0, executeOptions{}, nil)
+	require.NoError(t, err)
+	infos := m.list()
+	require.Len(t, infos, 1)
+	require.Equal(t, contextWorkspaceRoot, infos[0].CWD)
+	require.Equal(t, "/workspace/sub", infos[0].CurrentCWD)
+
+	kctx, err := m.reset(t.Context(), "ctx-sh", true)
+	require.NoError(t, err)
+	require.Equal(t, "/workspace/sub", kctx.info().CWD)
+
+	_, err = m.executeWithHooks(t.Context(), "ctx-sh", "pwd", 0, executeOptions{}, nil)
+	require.NoError(t, err)
+	require.Contains(t, lastCode.Load().(string), "cd '/workspace/sub'; __agentland_cwd_inited=1")
+}
+
+func TestReadCWDFile_IgnoresPathOutsideWorkspace(t *testing.T) {
+	m := &contextManager{rootDir: t.TempDir(), workspaceRoot: contextWorkspaceRoot}
+	path, err := m.cwdFilePath("ctx-sh")
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("/tmp\n"), 0o600))
+	_, ok := m.readCWDFile("ctx-sh")
+	require.False(t, ok)
+
+	require.NoError(t, os.WriteFile(path, []byte("/workspace/a/../b\n"), 0o600))
+	cwd, ok := m.readCWDFile("ctx-sh")
+	require.True(t, ok)
+	require.Equal(t, "/workspace/b", cwd)
+}
+
+func TestResolveContextCWD_CustomWorkspaceRoot(t *testing.T) {
+	root := "/srv/project"
+	cases := []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{input: "", want: root, ok: true},
+		{input: "src/../lib", want: "/srv/project/lib", ok: true},
+		{input: "/srv/project/a", want: "/srv/project/a", ok: true},
+		{input: "..", ok: false},
+		{input: "../project-other", ok: false},
+		{input: "/srv/project/../etc", ok: false},
+		{input: "/workspace", ok: false},
+	}
+	for _, tc := range cases {
+		got, err := resolveContextCWD(root, tc.input)
+		if !tc.ok {
+			require.Error(t, err, tc.input)
+			continue
+		}
+		require.NoError(t, err, tc.input)
+		require.Equal(t, tc.want, got)
+	}
+}
+
+func TestCreateContext_CustomWorkspaceRootRejectsTraversal(t *testing.T) {
+	fj := newFakeJupyter(t, nil)
+	jc, err := jupyter.NewClient(fj.server.URL, "")
+	require.NoError(t, err)
+	root := t.TempDir()
+	m, err := newContextManagerWithClient(ContextManagerConfig{WorkspaceRoot: root}, t.TempDir(), jc)
+	require.NoError(t, err)
+	require.Equal(t, root, m.workspaceRoot)
+
+	_, err = m.create(models.CreateContextReq{Language: "python", CWD: "../escape"})
+	require.ErrorIs(t, err, errCWDOutsideWorkspace)
+	_, err = m.create(models.CreateContextReq{Language: "python", CWD: contextWorkspaceRoot})
+	require.ErrorIs(t, err, errCWDOutsideWorkspace)
+
+	rel, err := notebookPathForCWD(m.workspaceRoot, "ctx-1", filepath.Join(root, "sub"))
+	require.NoError(t, err)
+	require.Equal(t, "sub/.agentland_contexts/ctx-1.ipynb", rel)
+}
+
+func TestContextLimits_Validate(t *testing.T) {
+	require.NoError(t, contextLimits{}.validate())
+	require.NoError(t, contextLimits{MemBytes: 256 << 20, CPUMillis: 1500}.validate())
+	require.ErrorIs(t, contextLimits{MemBytes: -1}.validate(), errInvalidLimits)
+	require.ErrorIs(t, contextLimits{CPUMillis: -1}.validate(), errInvalidLimits)
+	require.ErrorIs(t, contextLimits{MemBytes: 1 << 20}.validate(), errInvalidLimits)
+	require.Equal(t, int64(2), contextLimits{CPUMillis: 1500}.cpuSeconds())
+}
+
+func TestWithInit_AppliesLimitsOnlyWhenSet(t *testing.T) {
+	code, err := withPythonInit("/workspace", contextLimits{}, nil, "pass")
+	require.NoError(t, err)
+	require.NotContains(t, code, "setrlimit")
+
+	code, err = withPythonInit("/workspace", contextLimits{MemBytes: 128 << 20, CPUMillis: 2000}, nil, "pass")
+	require.NoError(t, err)
+	require.Contains(t, code, "\tresource.setrlimit(resource.RLIMIT_AS, (134217728, 134217728))")
+	require.Contains(t, code, "\tresource.setrlimit(resource.RLIMIT_CPU, (2, 2))")
+
+	script := withBashInit("/workspace", contextLimits{}, nil, "true", "marker", "", "")
+	require.NotContains(t, script, "ulimit")
+
+	script = withBashInit("/workspace", contextLimits{MemBytes: 128 << 20, CPUMillis: 2000}, nil, "true", "marker", "", "")
+	require.Contains(t, script, "then ulimit -v 131072; ulimit -t 2; cd '/workspace'")
+}
+
+func TestExecute_MemoryLimitExceededReturnsNonZeroExitCode(t *testing.T) {
+	var executed string
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		executed = code
+		return fakeKernelReply{Stderr: "MemoryError\n", Status: "error"}
+	})
+	m := newTestContextManager(t, fj)
+	kctx := addTestContext(m, "ctx-mem", contextLanguagePython)
+	kctx.Limits = contextLimits{MemBytes: 64 << 20}
+
+	resp, err := m.executeWithHooks(t.Context(), "ctx-mem", "x = bytearray(1 << 30)", 0, executeOptions{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), resp.ExitCode)
+	require.Contains(t, resp.Stderr, "MemoryError")
+	require.Contains(t, executed, "RLIMIT_AS, (67108864, 67108864)")
+	require.NotNil(t, m.get("ctx-mem"), "context should stay usable after a failed cell")
+}
+
+func TestCreateContext_InvalidLimitsReturnsFormError(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	fj := newFakeJupyter(t, nil)
+	m := newTestContextManager(t, fj)
+
+	router := gin.New()
+	h := &CodeInterpreterHandler{contexts: m}
+	router.POST("/contexts", h.CreateContext)
+
+	req := httptest.NewRequest(http.MethodPost, "/contexts", strings.NewReader(`{"language":"python","mem_bytes":-1}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Empty(t, m.list())
+}
+
+func TestExecuteInContext_StreamsDisplayOutputs(t *testing.T) {
+	png := base64.StdEncoding.EncodeToString([]byte("\x89PNG fake"))
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		return fakeKernelReply{Displays: []map[string]any{
+			{"image/png": png + "\n", "text/plain": "<Figure size 640x480>"},
+		}}
+	})
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-plot", contextLanguagePython)
+
+	events := executeViaHandler(t, m, "ctx-plot", `{"code":"plt.show()"}`)
+
+	var display *models.ExecuteStreamEvent
+	for i := range events {
+		if events[i].Type == "display" {
+			display = &events[i]
+		}
+	}
+	require.NotNil(t, display)
+	require.Equal(t, []models.RichOutput{
+		{MimeType: "image/png", Data: png},
+		{MimeType: "text/plain", Data: base64.StdEncoding.EncodeToString([]byte("<Figure size 640x480>"))},
+	}, display.Outputs)
+}
+
+func TestExecute_ResultsBoundedByMaxRichOutputBytes(t *testing.T) {
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		return fakeKernelReply{Displays: []map[string]any{
+			{"text/html": strings.Repeat("<p>x</p>", 32)},
+			{"text/html": "<b>ok</b>"},
+		}}
+	})
+	m := newTestContextManager(t, fj)
+	m.maxRichOutputBytes = 64
+	addTestContext(m, "ctx-html", contextLanguagePython)
+
+	resp, err := m.executeWithHooks(t.Context(), "ctx-html", "display(HTML(...))", 0, executeOptions{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []models.RichOutput{
+		{MimeType: "text/html", Truncated: true},
+		{MimeType: "text/html", Data: base64.StdEncoding.EncodeToString([]byte("<b>ok</b>"))},
+	}, resp.Results)
+}
+
+func TestExecuteInContext_OutputBoundedByMaxOutputBytes(t *testing.T) {
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		return fakeKernelReply{Stdout: strings.Repeat("x", 4096), Stderr: "warn\n"}
+	})
+	m := newTestContextManager(t, fj)
+	m.maxOutputBytes = 64
+	addTestContext(m, "ctx-big", contextLanguagePython)
+
+	events := executeViaHandler(t, m, "ctx-big", `{"code":"print('x' * 4096)"}`)
+
+	var stdout, stderr string
+	for _, evt := range events {
+		switch evt.Type {
+		case "stdout":
+			stdout += evt.Text
+		case "stderr":
+			stderr += evt.Text
+		}
+	}
+	require.Equal(t, strings.Repeat("x", 64)+jupyter.TruncationMarker(64), stdout)
+	require.Equal(t, "warn\n", stderr)
+
+	last := events[len(events)-1]
+	require.Equal(t, "execution_complete", last.Type)
+	require.True(t, last.StdoutTruncated)
+	require.False(t, last.StderrTruncated)
+}
+
+func TestExecuteBash_TruncatedOutputKeepsExitCode(t *testing.T) {
+	markerPattern := regexp.MustCompile(utils.BashExitMarkerPrefix + `[0-9a-f-]+`)
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		marker := markerPattern.FindString(code)
+		return fakeKernelReply{Stdout: strings.Repeat("y", 4096) + "\n" + marker + "=3\n"}
+	})
+	m := newTestContextManager(t, fj)
+	m.maxOutputBytes = 128
+	addTestContext(m, "ctx-sh", contextLanguageBash)
+
+	resp, err := m.executeWithHooks(t.Context(), "ctx-sh", "yes y | head -c 4096; exit 3", 0, executeOptions{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, int32(3), resp.ExitCode)
+	require.True(t, resp.StdoutTruncated)
+	require.Equal(t, strings.Repeat("y", 128)+jupyter.TruncationMarker(128), resp.Stdout)
+}
+
+func TestRichOutputsFromBundle_NonStringValue(t *testing.T) {
+	outputs := richOutputsFromBundle(jupyter.MimeBundle{"application/json": json.RawMessage(`{"a":1}`)}, 0)
+	require.Equal(t, []models.RichOutput{
+		{MimeType: "application/json", Data: base64.StdEncoding.EncodeToString([]byte(`{"a":1}`))},
+	}, outputs)
+}
+
+func TestInterruptContext_StopsExecutionAndKeepsContext(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	hang := atomic.Bool{}
+	hang.Store(true)
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		if hang.Load() {
+			return fakeKernelReply{Stdout: "started\n", Hang: true}
+		}
+		return fakeKernelReply{Stdout: "again\n"}
+	})
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-1", contextLanguagePython)
+
+	type execOut struct {
+		resp *models.ExecuteContextResp
+		err  error
+	}
+	done := make(chan execOut, 1)
+	go func() {
+		resp, err := m.executeWithHooks(t.Context(), "ctx-1", "import time; time.sleep(60)", 60000, executeOptions{}, nil)
+		done <- execOut{resp: resp, err: err}
+	}()
+	require.Eventually(t, func() bool { return m.get("ctx-1").busy.Load() }, time.Second, 10*time.Millisecond)
+
+	router := gin.New()
+	h := &CodeInterpreterHandler{contexts: m}
+	router.POST("/contexts/:contextId/interrupt", h.InterruptContext)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/contexts/ctx-1/interrupt", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.InterruptContextResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.True(t, resp.Interrupted)
+	// 接口返回时执行已退出，context 可立即复用
+	require.False(t, m.get("ctx-1").busy.Load())
+
+	out := <-done
+	require.NoError(t, out.err)
+	require.Equal(t, int32(1), out.resp.ExitCode)
+	require.Contains(t, out.resp.Stderr, "KeyboardInterrupt")
+
+	hang.Store(false)
+	again, err := m.executeWithHooks(t.Context(), "ctx-1", "print('again')", 0, executeOptions{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "again\n", again.Stdout)
+}
+
+func TestInterruptContext_IdleIsNoop(t *testing.T) {
+	fj := newFakeJupyter(t, nil)
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-1", contextLanguagePython)
+
+	interrupted, err := m.interrupt(t.Context(), "ctx-1")
+	require.NoError(t, err)
+	require.False(t, interrupted)
+	require.Empty(t, fj.interruptedKernels())
+
+	_, err = m.interrupt(t.Context(), "missing")
+	require.ErrorIs(t, err, errContextNotFound)
+}
+
+func TestValidateContextEnv(t *testing.T) {
+	require.NoError(t, validateContextEnv(nil, nil))
+	require.NoError(t, validateContextEnv(map[string]string{"FOO": "bar", "_x1": ""}, nil))
+	require.ErrorIs(t, validateContextEnv(map[string]string{"1FOO": "bar"}, nil), errInvalidEnv)
+	require.ErrorIs(t, validateContextEnv(map[string]string{"FOO-BAR": "bar"}, nil), errInvalidEnv)
+	require.ErrorIs(t, validateContextEnv(map[string]string{"FOO": "a\x00b"}, nil), errInvalidEnv)
+	require.ErrorIs(t, validateContextEnv(map[string]string{"PATH": "/tmp"}, nil), errInvalidEnv)
+	require.ErrorIs(t, validateContextEnv(map[string]string{"LD_PRELOAD": "/tmp/x.so"}, nil), errInvalidEnv)
+	require.NoError(t, validateContextEnv(map[string]string{"PATH": "/tmp"}, map[string]struct{}{"PATH": {}}))
+}
+
+func TestExecute_InjectedEnvVisibleToCell(t *testing.T) {
+	var executed string
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		executed = code
+		if strings.Contains(code, `os.environ.update({"FOO":"it's bar"})`) && strings.Contains(code, "print(os.environ['FOO'])") {
+			return fakeKernelReply{Stdout: "it's bar\n"}
+		}
+		return fakeKernelReply{Stderr: "KeyError: 'FOO'\n", Status: "error"}
+	})
+	m := newTestContextManager(t, fj)
+	kctx := addTestContext(m, "ctx-env", contextLanguagePython)
+	kctx.Env = map[string]string{"FOO": "it's bar"}
+
+	resp, err := m.executeWithHooks(t.Context(), "ctx-env", "print(os.environ['FOO'])", 0, executeOptions{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "it's bar\n", resp.Stdout, "executed code:\n%s", executed)
+	require.Equal(t, int32(0), resp.ExitCode)
+}
+
+func TestWithBashInit_ExportsEnv(t *testing.T) {
+	script := withBashInit("/workspace", contextLimits{}, map[string]string{"FOO": "it's bar", "A": "1"}, "echo $FOO", "marker", "", "")
+	require.Contains(t, script, `then export A='1'; export FOO='it'"'"'s bar'; cd '/workspace'`)
+}
+
+func TestCreateContext_ProtectedEnvReturnsFormError(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	fj := newFakeJupyter(t, nil)
+	m := newTestContextManager(t, fj)
+
+	router := gin.New()
+	h := &CodeInterpreterHandler{contexts: m}
+	router.POST("/contexts", h.CreateContext)
+
+	req := httptest.NewRequest(http.MethodPost, "/contexts", strings.NewReader(`{"language":"python","env":{"PATH":"/tmp/evil"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Empty(t, m.list())
+}
+
+func TestWithNodeInit_InitializesOnce(t *testing.T) {
+	code, err := withNodeInit("/workspace/app", nil, "console.log(1)")
+	require.NoError(t, err)
+	require.Equal(t, "if (typeof globalThis.__agentland_cwd_inited === 'undefined') { process.chdir(\"/workspace/app\"); globalThis.__agentland_cwd_inited = true; }\nconsole.log(1)\n", code)
+
+	code, err = withNodeInit("/workspace", map[string]string{"FOO": "bar"}, "console.log(process.env.FOO)")
+	require.NoError(t, err)
+	require.Contains(t, code, `Object.assign(process.env, {"FOO":"bar"});`)
+}
+
+func TestExecute_NodeContext(t *testing.T) {
+	var executed []string
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		executed = append(executed, code)
+		if strings.Contains(code, "throw") {
+			return fakeKernelReply{Stderr: "Error: boom\n", Status: "error"}
+		}
+		return fakeKernelReply{Stdout: "42\n"}
+	})
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-node", contextLanguageNode)
+
+	resp, err := m.executeWithHooks(t.Context(), "ctx-node", "console.log(40 + 2)", 0, executeOptions{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, int32(0), resp.ExitCode)
+	require.Equal(t, "42\n", resp.Stdout)
+
+	resp, err = m.executeWithHooks(t.Context(), "ctx-node", "throw new Error('boom')", 0, executeOptions{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), resp.ExitCode)
+	require.Contains(t, resp.Stderr, "boom")
+	require.Len(t, executed, 2)
+	require.Contains(t, executed[0], "process.chdir(\"/workspace\")")
+}
+
+func TestSearchKernel_Node(t *testing.T) {
+	fj := newFakeJupyter(t, nil)
+	m := newTestContextManager(t, fj)
+
+	name, err := m.searchKernel(t.Context(), contextLanguageNode)
+	require.NoError(t, err)
+	require.Equal(t, "javascript", name)
+}
+
+func TestCreateContext_NodeRejectsLimits(t *testing.T) {
+	fj := newFakeJupyter(t, nil)
+	m := newTestContextManager(t, fj)
+
+	_, err := m.create(models.CreateContextReq{Language: "node", MemBytes: 128 << 20})
+	require.ErrorIs(t, err, errInvalidLimits)
+	require.ErrorIs(t, err, errLimitsUnsupported)
+	require.Empty(t, m.list())
+}
+
+func TestGCPass_ReclaimsStaleContexts(t *testing.T) {
+	fj := newFakeJupyter(t, nil)
+	m := newTestContextManager(t, fj)
+	now := time.Now()
+
+	stale := addTestContext(m, "ctx-stale", contextLanguagePython)
+	stale.lastActiveUnix.Store(now.Add(-m.idleTTL - time.Second).UnixNano())
+	require.NoError(t, os.MkdirAll(filepath.Join(m.rootDir, "ctx-stale"), 0o700))
+	addTestContext(m, "ctx-fresh", contextLanguagePython)
+	busy := addTestContext(m, "ctx-busy", contextLanguagePython)
+	busy.lastActiveUnix.Store(now.Add(-m.idleTTL - time.Second).UnixNano())
+	_, release, ok := busy.acquireKernel(false)
+	require.True(t, ok)
+	defer release()
+
+	before := promtestutil.ToFloat64(metrics.GCReclaimed)
+	require.Equal(t, 1, m.gcPass(now))
+	require.Equal(t, before+1, promtestutil.ToFloat64(metrics.GCReclaimed))
+	require.Nil(t, m.get("ctx-stale"))
+	require.NotNil(t, m.get("ctx-fresh"))
+	require.NotNil(t, m.get("ctx-busy"))
+	require.NoDirExists(t, filepath.Join(m.rootDir, "ctx-stale"))
+	require.ErrorContains(t, m.notFound("ctx-stale"), "recycled (idle)")
+
+	// 后台循环随 ctx 取消退出
+	ctx, cancel := context.WithCancel(t.Context())
+	gcDone := make(chan struct{})
+	go func() {
+		m.runGC(ctx)
+		close(gcDone)
+	}()
+	cancel()
+	select {
+	case <-gcDone:
+	case <-time.After(time.Second):
+		t.Fatal("gc goroutine did not stop")
+	}
+}
+
+func TestShutdown_RemovesAllContextsAndStopsGC(t *testing.T) {
+	fj := newFakeJupyter(t, nil)
+	m := newTestContextManager(t, fj)
+	m.gcInterval = time.Hour
+
+	for _, id := range []string{"ctx-a", "ctx-b"} {
+		addTestContext(m, id, contextLanguagePython)
+		require.NoError(t, os.MkdirAll(filepath.Join(m.rootDir, id), 0o700))
+	}
+	kctx := addTestContext(m, "ctx-busy", contextLanguagePython)
+	kctx.pool = []*poolKernel{{SessionID: "ctx-busy-1", KernelID: "kernel-ctx-busy-1"}}
+	_, release, ok := kctx.acquireKernel(false)
+	require.True(t, ok)
+	defer release()
+
+	gcDone := make(chan struct{})
+	go func() {
+		m.runGC(t.Context())
+		close(gcDone)
+	}()
+
+	require.NoError(t, m.Shutdown(t.Context()))
+	require.Empty(t, m.list())
+	for _, id := range []string{"ctx-a", "ctx-b"} {
+		require.NoDirExists(t, filepath.Join(m.rootDir, id))
+	}
+	select {
+	case <-gcDone:
+	case <-time.After(time.Second):
+		t.Fatal("gc goroutine did not stop")
+	}
+
+	fj.mu.Lock()
+	defer fj.mu.Unlock()
+	require.ElementsMatch(t, []string{"ctx-a", "ctx-b", "ctx-busy", "ctx-busy-1"}, fj.deletedSessions)
+
+	// 重复调用不会因重复关闭 gcStop 而 panic
+	require.NoError(t, m.Shutdown(t.Context()))
+}
+
+func TestCreateContext_RetriesProbeUntilKernelReady(t *testing.T) {
+	fj := newFakeJupyter(t, nil)
+	fj.kernelInfoFailures.Store(1)
+	m := newTestContextManager(t, fj)
+
+	kctx, err := m.create(models.CreateContextReq{Language: contextLanguagePython})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), fj.kernelInfoCount.Load())
+	require.Zero(t, fj.execCount.Load())
+	require.Len(t, m.list(), 1)
+	require.Equal(t, kctx.ID, m.list()[0].ContextID)
+
+	fj.mu.Lock()
+	defer fj.mu.Unlock()
+	require.Empty(t, fj.deletedSessions)
+}
+
+func TestCreateContext_ConfiguredMaxCount(t *testing.T) {
+	fj := newFakeJupyter(t, nil)
+	jc, err := jupyter.NewClient(fj.server.URL, "")
+	require.NoError(t, err)
+	m, err := newContextManagerWithClient(ContextManagerConfig{MaxCount: 2}, t.TempDir(), jc)
+	require.NoError(t, err)
+
+	addTestContext(m, "ctx-1", contextLanguagePython)
+	// 未达到上限时越过数量检查，继续校验后续参数
+	_, err = m.create(models.CreateContextReq{Language: "ruby"})
+	require.ErrorIs(t, err, errUnsupportedLanguage)
+
+	addTestContext(m, "ctx-2", contextLanguagePython)
+	_, err = m.create(models.CreateContextReq{Language: "python"})
+	require.ErrorIs(t, err, errContextLimitExceeded)
+	require.Len(t, m.list(), 2)
+}
+
+func TestContextMetrics_ActiveGaugeAndExecutions(t *testing.T) {
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		return fakeKernelReply{Stdout: "ok\n"}
+	})
+	m := newTestContextManager(t, fj)
+	active := promtestutil.ToFloat64(metrics.ActiveContexts)
+
+	addTestContext(m, "ctx-metrics", contextLanguagePython)
+	require.Equal(t, active+1, promtestutil.ToFloat64(metrics.ActiveContexts))
+
+	succeeded := promtestutil.ToFloat64(metrics.Executions.WithLabelValues(contextLanguagePython, metrics.ExitClassSuccess))
+	_, err := m.executeWithHooks(context.Background(), "ctx-metrics", "print('ok')", 0, executeOptions{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, succeeded+1, promtestutil.ToFloat64(metrics.Executions.WithLabelValues(contextLanguagePython, metrics.ExitClassSuccess)))
+
+	require.NoError(t, m.removeContext("ctx-metrics", true))
+	require.Equal(t, active, promtestutil.ToFloat64(metrics.ActiveContexts))
+	// 重复删除不会让活跃数继续减少
+	require.ErrorIs(t, m.removeContext("ctx-metrics", true), errContextNotFound)
+	require.Equal(t, active, promtestutil.ToFloat64(metrics.ActiveContexts))
+}
+
+func TestExitClass(t *testing.T) {
+	require.Equal(t, metrics.ExitClassSuccess, metrics.ExitClass(0))
+	require.Equal(t, metrics.ExitClassError, metrics.ExitClass(1))
+	require.Equal(t, metrics.ExitClassError, metrics.ExitClass(137))
+	require.Equal(t, metrics.ExitClassTimeout, metrics.ExitClass(124))
+}
+
+func TestContextManagerConfig_Defaults(t *testing.T) {
+	cfg, err := ContextManagerConfig{}.withDefaults()
+	require.NoError(t, err)
+	require.Equal(t, contextMaxCount, cfg.MaxCount)
+	require.Equal(t, contextIdleTTL, cfg.IdleTTL)
+	require.Equal(t, contextGCInterval, cfg.GCInterval)
+	require.Equal(t, contextDefaultTimeoutMs, cfg.DefaultTimeoutMs)
+	require.Equal(t, contextWorkspaceRoot, cfg.WorkspaceRoot)
+
+	_, err = ContextManagerConfig{DefaultTimeoutMs: 10}.withDefaults()
+	require.Error(t, err)
+	_, err = ContextManagerConfig{MaxCount: -1}.withDefaults()
+	require.Error(t, err)
+	_, err = ContextManagerConfig{WorkspaceRoot: "relative/dir"}.withDefaults()
+	require.Error(t, err)
+}
+
+func TestExecute_ConfiguredDefaultTimeout(t *testing.T) {
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		return fakeKernelReply{Hang: true, IgnoreInterrupt: true}
+	})
+	jc, err := jupyter.NewClient(fj.server.URL, "")
+	require.NoError(t, err)
+	m, err := newContextManagerWithClient(ContextManagerConfig{DefaultTimeoutMs: 100}, t.TempDir(), jc)
+	require.NoError(t, err)
+	addTestContext(m, "ctx-1", contextLanguagePython)
+
+	resp, err := m.executeWithHooks(t.Context(), "ctx-1", "while True: pass", 0, executeOptions{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, int32(124), resp.ExitCode)
+}
+
+func TestExecuteInContext_ReturnVars(t *testing.T) {
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		return fakeKernelReply{UserExpressions: map[string]any{
+			contextVarsExpressionKey: map[string]any{
+				"status": "ok",
+				"data":   map[string]any{"text/plain": `{"df": "<DataFrame 3x2>", "x": "1"}`},
+			},
+		}}
+	})
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-vars", contextLanguagePython)
+
+	events := executeViaHandler(t, m, "ctx-vars", `{"code":"x = 1","return_vars":true}`)
+	complete := events[len(events)-1]
+	require.Equal(t, "execution_complete", complete.Type)
+	require.Equal(t, map[string]string{"df": "<DataFrame 3x2>", "x": "1"}, complete.Variables)
+
+	fj.mu.Lock()
+	exprs := fj.userExpressions
+	fj.mu.Unlock()
+	require.Equal(t, map[string]string{contextVarsExpressionKey: pythonVarsExpression()}, exprs)
+}
+
+func TestExecute_ReturnVarsOffByDefault(t *testing.T) {
+	fj := newFakeJupyter(t, nil)
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-vars", contextLanguagePython)
+
+	resp, err := m.executeWithHooks(t.Context(), "ctx-vars", "x = 1", 0, executeOptions{}, nil)
+	require.NoError(t, err)
+	require.Nil(t, resp.Variables)
+
+	fj.mu.Lock()
+	defer fj.mu.Unlock()
+	require.Empty(t, fj.userExpressions)
+}
+
+func TestExecute_ReturnVarsRequiresPython(t *testing.T) {
+	fj := newFakeJupyter(t, nil)
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-sh", contextLanguageBash)
+
+	_, err := m.executeWithHooks(t.Context(), "ctx-sh", "x=1", 0, executeOptions{ReturnVars: true}, nil)
+	require.ErrorIs(t, err, errUnsupportedLanguage)
+}
+
+func TestVariablesFromResult_IgnoresFailedExpression(t *testing.T) {
+	result := &jupyter.ExecuteResult{UserExpressions: map[string]jupyter.UserExpressionResult{
+		contextVarsExpressionKey: {Status: "error", EName: "NameError"},
+	}}
+	require.Nil(t, variablesFromResult(result))
+	require.Nil(t, variablesFromResult(&jupyter.ExecuteResult{}))
+}
+
+func executeBatchViaHandler(t *testing.T, m *contextManager, contextID, body string) (int, models.ExecuteBatchResp) {
+	t.Helper()
+	gin.SetMode(gin.ReleaseMode)
+
+	router := gin.New()
+	h := &CodeInterpreterHandler{contexts: m}
+	router.POST("/contexts/:contextId/execute-batch", h.ExecuteBatch)
+
+	req := httptest.NewRequest(http.MethodPost, "/contexts/"+contextID+"/execute-batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var payload struct {
+		Data models.ExecuteBatchResp `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &payload))
+	}
+	return w.Code, payload.Data
+}
+
+// newStatefulFakeJupyter 模拟 kernel 全局状态：`x = 41` 赋值后 `print(x + 1)` 才能成功
+func newStatefulFakeJupyter(t *testing.T) *fakeJupyter {
+	t.Helper()
+
+	var mu sync.Mutex
+	defined := false
+	return newFakeJupyter(t, func(code string) fakeKernelReply {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.Contains(code, "x = 41"):
+			defined = true
+			return fakeKernelReply{}
+		case strings.Contains(code, "print(x + 1)") && defined:
+			return fakeKernelReply{Stdout: "42\n"}
+		default:
+			return fakeKernelReply{Stderr: "NameError: name 'x' is not defined\n", Status: "error"}
+		}
+	})
+}
+
+func TestExecuteBatch_SharesStateAcrossCells(t *testing.T) {
+	fj := newStatefulFakeJupyter(t)
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-batch", contextLanguagePython)
+
+	code, resp := executeBatchViaHandler(t, m, "ctx-batch", `{"cells":[{"code":"x = 41"},{"code":"print(x + 1)","timeout_ms":1000}]}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ctx-batch", resp.ContextID)
+	require.False(t, resp.Stopped)
+	require.Len(t, resp.Results, 2)
+	require.Equal(t, 0, resp.Results[0].Index)
+	require.Equal(t, int32(0), resp.Results[0].ExitCode)
+	require.Equal(t, 1, resp.Results[1].Index)
+	require.Equal(t, int32(0), resp.Results[1].ExitCode)
+	require.Equal(t, "42\n", resp.Results[1].Stdout)
+	require.Equal(t, int64(2), resp.Results[1].ExecutionCount)
+	require.False(t, m.get("ctx-batch").busy.Load())
+}
+
+func TestExecuteBatch_StopOnError(t *testing.T) {
+	fj := newStatefulFakeJupyter(t)
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-batch", contextLanguagePython)
+
+	body := `{"cells":[{"code":"print(x + 1)"},{"code":"x = 41"}],"stop_on_error":true}`
+	code, resp := executeBatchViaHandler(t, m, "ctx-batch", body)
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, resp.Stopped)
+	require.Len(t, resp.Results, 1)
+	require.Equal(t, int32(1), resp.Results[0].ExitCode)
+
+	// 未设置 stop_on_error 时失败的单元不影响后续单元
+	code, resp = executeBatchViaHandler(t, m, "ctx-batch", `{"cells":[{"code":"print(y)"},{"code":"x = 41"}]}`)
+	require.Equal(t, http.StatusOK, code)
+	require.False(t, resp.Stopped)
+	require.Len(t, resp.Results, 2)
+	require.Equal(t, int32(1), resp.Results[0].ExitCode)
+	require.Equal(t, int32(0), resp.Results[1].ExitCode)
+}
+
+func TestExecuteBatch_InvalidRequest(t *testing.T) {
+	fj := newStatefulFakeJupyter(t)
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-batch", contextLanguagePython)
+
+	for _, body := range []string{
+		`{"cells":[]}`,
+		`{"cells":[{"code":"  "}]}`,
+		`{"cells":[{"code":"x = 41","timeout_ms":99}]}`,
+	} {
+		code, _ := executeBatchViaHandler(t, m, "ctx-batch", body)
+		require.Equal(t, http.StatusBadRequest, code, body)
+	}
+}
+
+func TestExecuteBatch_BusyContextReturnsConflict(t *testing.T) {
+	fj := newStatefulFakeJupyter(t)
+	m := newTestContextManager(t, fj)
+	kctx := addTestContext(m, "ctx-batch", contextLanguagePython)
+
+	kctx.busy.Store(true)
+	code, _ := executeBatchViaHandler(t, m, "ctx-batch", `{"cells":[{"code":"x = 41"}]}`)
+	require.Equal(t, http.StatusConflict, code)
+}
+
+func TestExecuteBatch_MissingContextReturnsNotFound(t *testing.T) {
+	fj := newStatefulFakeJupyter(t)
+	m := newTestContextManager(t, fj)
+
+	code, _ := executeBatchViaHandler(t, m, "ctx-missing", `{"cells":[{"code":"x = 41"}]}`)
+	require.Equal(t, http.StatusNotFound, code)
+}
+
+type captureAuditSink struct {
+	mu     sync.Mutex
+	events []audit.Event
+}
+
+func (s *captureAuditSink) Write(event audit.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+}
+
+func TestExecuteInContext_RecordsAuditWithoutEnvSecrets(t *testing.T) {
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		return fakeKernelReply{Stdout: "ok\n"}
+	})
+	m := newTestContextManager(t, fj)
+	kctx := addTestContext(m, "ctx-audit", contextLanguagePython)
+	kctx.Env = map[string]string{"API_TOKEN": "sk-super-secret"}
+
+	sink := &captureAuditSink{}
+	router := gin.New()
+	h := &CodeInterpreterHandler{contexts: m, auditor: audit.NewRecorder(sink, true)}
+	router.POST("/contexts/:contextId/execute", h.ExecuteInContext)
+
+	code := `print("sk-super-secret")`
+	req := httptest.NewRequest(http.MethodPost, "/contexts/ctx-audit/execute", strings.NewReader(`{"code":"print(\"sk-super-secret\")"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-agentland-session", "session-audit")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.Len(t, sink.events, 1)
+	event := sink.events[0]
+	require.Equal(t, audit.SourceKorokd, event.Source)
+	require.Equal(t, "session-audit", event.SessionID)
+	require.Equal(t, "ctx-audit", event.ContextID)
+	require.Equal(t, contextLanguagePython, event.Language)
+	require.Equal(t, audit.HashCode(code), event.CodeHash)
+	require.NotNil(t, event.ExitCode)
+	require.Equal(t, int32(0), *event.ExitCode)
+
+	encoded, err := json.Marshal(event)
+	require.NoError(t, err)
+	require.NotContains(t, string(encoded), "sk-super-secret")
+	require.Contains(t, event.Code, "[REDACTED]")
+}
+
+func TestExecuteInContext_AuditSurvivesTimeoutRecycle(t *testing.T) {
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		return fakeKernelReply{Hang: true}
+	})
+	m := newTestContextManager(t, fj)
+	kctx := addTestContext(m, "ctx-audit", contextLanguagePython)
+	kctx.Env = map[string]string{"API_TOKEN": "sk-super-secret"}
+
+	sink := &captureAuditSink{}
+	router := gin.New()
+	h := &CodeInterpreterHandler{contexts: m, auditor: audit.NewRecorder(sink, true)}
+	router.POST("/contexts/:contextId/execute", h.ExecuteInContext)
+
+	req := httptest.NewRequest(http.MethodPost, "/contexts/ctx-audit/execute",
+		strings.NewReader(`{"code":"print(\"sk-super-secret\")\nwhile True: pass","timeout_ms":100}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Nil(t, m.get("ctx-audit"))
+
+	// 超时回收 context 后，审计记录仍带有执行前的语言并脱敏环境变量值
+	require.Len(t, sink.events, 1)
+	event := sink.events[0]
+	require.Equal(t, contextLanguagePython, event.Language)
+	require.NotNil(t, event.ExitCode)
+	require.Equal(t, int32(124), *event.ExitCode)
+	require.NotContains(t, event.Code, "sk-super-secret")
+	require.Contains(t, event.Code, "[REDACTED]")
+}
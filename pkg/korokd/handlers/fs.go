@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -12,6 +15,9 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 	"unicode/utf8"
 
 	"github.com/Fl0rencess720/agentland/pkg/common/models"
@@ -26,25 +32,53 @@ const (
 	defaultFileEncoding = "utf8"
 )
 
-var errPathEscapesWorkspaceRoot = fmt.Errorf("path escapes workspace root")
+var (
+	errPathEscapesWorkspaceRoot = fmt.Errorf("path escapes workspace root")
+	errDeleteProtectedPath      = fmt.Errorf("refusing to delete workspace root or filesystem root")
+	errMoveProtectedPath        = fmt.Errorf("refusing to move workspace root or filesystem root")
+	errNotRegularFile           = fmt.Errorf("path is not a regular file")
+)
 
 // FSHandler 封装文件系统相关接口所需的运行参数
 type FSHandler struct {
-	workspaceRoot string
-	maxFileBytes  int64
+	workspaceRoot   string
+	maxFileBytes    int64
+	maxArchiveBytes int64
+	// maxWorkspaceBytes 为写入、上传后工作区普通文件总大小上限，<=0 表示不限制
+	maxWorkspaceBytes int64
+	// maxUploadTotalBytes 为单次批量上传的文件总大小上限，<=0 表示不限制
+	maxUploadTotalBytes int64
+	// writeMu 串行化写文件接口的校验与写入，保证 if_match_hash 的比较与写入之间不被其他写请求插入
+	writeMu sync.Mutex
+	// usage 缓存配额校验使用的工作区用量，避免每次写入都遍历整个工作区
+	usage workspaceUsageCache
 }
 
-// InitFSApi 注册 fs 相关 HTTP 路由并初始化处理器
-func InitFSApi(group *gin.RouterGroup, workspaceRoot string, maxFileBytes int64) {
+// InitFSApi 注册 fs 相关 HTTP 路由并初始化处理器，maxArchiveBytes<=0 表示打包下载不限制总大小，
+// maxWorkspaceBytes<=0 表示写入、上传不检查工作区配额，maxUploadTotalBytes<=0 表示批量上传不限制总大小
+func InitFSApi(group *gin.RouterGroup, workspaceRoot string, maxFileBytes, maxArchiveBytes, maxWorkspaceBytes, maxUploadTotalBytes int64) {
 	h := &FSHandler{
-		workspaceRoot: workspaceRoot,
-		maxFileBytes:  maxFileBytes,
+		workspaceRoot:       workspaceRoot,
+		maxFileBytes:        maxFileBytes,
+		maxArchiveBytes:     maxArchiveBytes,
+		maxWorkspaceBytes:   maxWorkspaceBytes,
+		maxUploadTotalBytes: maxUploadTotalBytes,
 	}
 	group.GET("/fs/tree", h.GetFSTree)
 	group.GET("/fs/file", h.GetFSFile)
+	group.GET("/fs/stat", h.StatFS)
+	group.GET("/fs/tail", h.TailFSFile)
+	group.GET("/fs/usage", h.UsageFS)
+	group.GET("/fs/search", h.SearchFS)
 	group.POST("/fs/file", h.WriteFSFile)
+	group.DELETE("/fs/file", h.DeleteFSFile)
+	group.POST("/fs/mkdir", h.MkdirFS)
+	group.POST("/fs/move", h.MoveFS)
+	group.POST("/fs/copy", h.CopyFS)
 	group.POST("/fs/upload", h.UploadFSFile)
+	group.POST("/fs/extract", h.ExtractFS)
 	group.GET("/fs/download", h.DownloadFSFile)
+	group.GET("/fs/archive", h.ArchiveFS)
 }
 
 // GetFSTree 根据路径返回目录树，支持深度控制和是否包含隐藏文件
@@ -52,31 +86,27 @@ func (h *FSHandler) GetFSTree(c *gin.Context) {
 	rootPath := strings.TrimSpace(c.DefaultQuery("path", "."))
 	depth, err := parseDepth(c.DefaultQuery("depth", "5"))
 	if err != nil {
-		response.ErrorResponse(c, response.FormError)
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
 		return
 	}
 	includeHidden, err := parseIncludeHidden(c.DefaultQuery("includeHidden", "false"))
 	if err != nil {
-		response.ErrorResponse(c, response.FormError)
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
 		return
 	}
 	targetPath, cleanedRoot, err := resolveWorkspacePath(h.workspaceRoot, rootPath)
 	if err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		writePathError(c, err)
 		return
 	}
 
 	info, err := os.Stat(targetPath)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			response.ErrorResponse(c, response.FormError)
-			return
-		}
-		response.ErrorResponse(c, response.ServerError)
+		writeStatError(c, err)
 		return
 	}
 	if !info.IsDir() {
-		response.ErrorResponse(c, response.FormError)
+		response.APIErrorResponse(c, response.CodeNotDirectory, "path is not a directory")
 		return
 	}
 
@@ -138,7 +168,7 @@ func (h *FSHandler) GetFSTree(c *gin.Context) {
 		return nil
 	})
 	if walkErr != nil {
-		response.ErrorResponse(c, response.ServerError)
+		writeInternalError(c, walkErr)
 		return
 	}
 
@@ -156,105 +186,220 @@ func (h *FSHandler) GetFSTree(c *gin.Context) {
 func (h *FSHandler) GetFSFile(c *gin.Context) {
 	filePath := strings.TrimSpace(c.Query("path"))
 	if filePath == "" {
-		response.ErrorResponse(c, response.FormError)
+		response.APIErrorResponse(c, response.CodeInvalidArgument, "path is required")
 		return
 	}
 
 	encoding, err := parseEncoding(c.DefaultQuery("encoding", "utf8"))
 	if err != nil {
-		response.ErrorResponse(c, response.FormError)
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
+		return
+	}
+	lines, err := parseLineRange(c.Query("start_line"), c.Query("end_line"))
+	if err != nil {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
+		return
+	}
+	if lines != nil && encoding != defaultFileEncoding {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, "start_line and end_line require utf8 encoding")
 		return
 	}
 	targetPath, cleanedPath, err := resolveWorkspacePath(h.workspaceRoot, filePath)
 	if err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		writePathError(c, err)
 		return
 	}
 
 	info, err := os.Lstat(targetPath)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			response.ErrorResponse(c, response.FormError)
-			return
-		}
-		response.ErrorResponse(c, response.ServerError)
+		writeStatError(c, err)
 		return
 	}
 	if info.IsDir() {
-		response.ErrorResponse(c, response.FormError)
+		response.APIErrorResponse(c, response.CodeNotRegularFile, "path is not a regular file")
 		return
 	}
 	if info.Mode()&os.ModeSymlink != 0 {
-		response.ErrorResponse(c, response.FormError)
+		response.APIErrorResponse(c, response.CodeNotRegularFile, "path is not a regular file")
+		return
+	}
+	if notModified(c, info) {
 		return
 	}
 	if h.maxFileBytes > 0 && info.Size() > h.maxFileBytes {
-		response.ErrorResponse(c, response.FormError)
+		writeFileTooLarge(c, info.Size(), h.maxFileBytes)
 		return
 	}
 
 	data, err := os.ReadFile(targetPath)
 	if err != nil {
-		response.ErrorResponse(c, response.ServerError)
+		writeInternalError(c, err)
 		return
 	}
 
-	content := ""
+	contentType := mimeTypeByName(targetPath)
+	if contentType == "" {
+		contentType = sniffMimeType(data)
+	}
+
+	resp := models.GetFSFileResp{
+		Path:        filepath.ToSlash(cleanedPath),
+		Size:        int64(len(data)),
+		Encoding:    encoding,
+		ContentType: contentType,
+	}
 	if encoding == defaultFileEncoding {
-		if !utf8.Valid(data) {
-			response.ErrorResponse(c, response.FormError)
+		selected := data
+		if lines != nil {
+			selected, resp.TotalLines = sliceLines(data, *lines)
+		}
+		if !utf8.Valid(selected) {
+			response.APIErrorResponse(c, response.CodeNotUTF8, "file content is not valid utf8, use encoding=base64")
 			return
 		}
-		content = string(data)
+		resp.Content = string(selected)
 	} else {
-		content = base64.StdEncoding.EncodeToString(data)
+		resp.Content = base64.StdEncoding.EncodeToString(data)
 	}
 
-	response.SuccessResponse(c, models.GetFSFileResp{
-		Path:     filepath.ToSlash(cleanedPath),
-		Size:     int64(len(data)),
-		Encoding: encoding,
-		Content:  content,
-	})
+	response.SuccessResponse(c, resp)
+}
+
+// lineRange 为按行读取的范围，行号从 1 开始且包含两端，end 为 0 表示读到文件末尾
+type lineRange struct {
+	start int
+	end   int
+}
+
+// parseLineRange 解析 start_line/end_line，二者均未指定时返回 nil 表示读取整个文件
+func parseLineRange(startRaw, endRaw string) (*lineRange, error) {
+	startRaw = strings.TrimSpace(startRaw)
+	endRaw = strings.TrimSpace(endRaw)
+	if startRaw == "" && endRaw == "" {
+		return nil, nil
+	}
+
+	r := &lineRange{start: 1}
+	if startRaw != "" {
+		start, err := strconv.Atoi(startRaw)
+		if err != nil || start < 1 {
+			return nil, fmt.Errorf("start_line must be a positive integer")
+		}
+		r.start = start
+	}
+	if endRaw != "" {
+		end, err := strconv.Atoi(endRaw)
+		if err != nil || end < 1 {
+			return nil, fmt.Errorf("end_line must be a positive integer")
+		}
+		if end < r.start {
+			return nil, fmt.Errorf("start_line must not be greater than end_line")
+		}
+		r.end = end
+	}
+	return r, nil
+}
+
+// sliceLines 返回 data 中位于 r 范围内的行（保留换行符）及文件总行数，末尾没有换行符的最后一行同样计为一行；
+// 起始行超过总行数时返回空内容
+func sliceLines(data []byte, r lineRange) ([]byte, int) {
+	begin, end := len(data), len(data)
+	total := 0
+	for offset := 0; offset < len(data); {
+		total++
+		lineEnd := len(data)
+		if idx := bytes.IndexByte(data[offset:], '\n'); idx >= 0 {
+			lineEnd = offset + idx + 1
+		}
+		if total == r.start {
+			begin = offset
+		}
+		if total == r.end {
+			end = lineEnd
+		}
+		offset = lineEnd
+	}
+	return data[begin:end], total
 }
 
 // WriteFSFile 将请求内容按指定编码写入目标文件
 func (h *FSHandler) WriteFSFile(c *gin.Context) {
 	var req models.WriteFSFileReq
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.ErrorResponse(c, response.FormError)
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
 		return
 	}
 
 	path := strings.TrimSpace(req.Path)
 	if path == "" {
-		response.ErrorResponse(c, response.FormError)
+		response.APIErrorResponse(c, response.CodeInvalidArgument, "path is required")
 		return
 	}
 
 	encoding, err := parseEncoding(req.Encoding)
 	if err != nil {
-		response.ErrorResponse(c, response.FormError)
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
 		return
 	}
 	targetPath, cleanedPath, err := resolveWorkspacePath(h.workspaceRoot, path)
 	if err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		writePathError(c, err)
+		return
+	}
+
+	mode, hasMode, err := parseFileMode(req.Mode)
+	if err != nil {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
 		return
 	}
 
 	data, err := decodeContent(req.Content, encoding)
 	if err != nil {
-		response.ErrorResponse(c, response.FormError)
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
+		return
+	}
+	if h.maxFileBytes > 0 && int64(len(data)) > h.maxFileBytes {
+		writeFileTooLarge(c, int64(len(data)), h.maxFileBytes)
+		return
+	}
+	ifMatchHash := strings.ToLower(strings.TrimSpace(req.IfMatchHash))
+
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+	if ifMatchHash != "" {
+		current, err := fileContentHash(targetPath)
+		if errors.Is(err, errNotRegularFile) {
+			response.APIErrorResponse(c, response.CodeNotRegularFile, err.Error())
+			return
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			writeInternalError(c, err)
+			return
+		}
+		if current != ifMatchHash {
+			if current == "" {
+				current = "<missing>"
+			}
+			response.APIErrorResponse(c, response.CodeHashMismatch,
+				fmt.Sprintf("content hash mismatch: expected %s, current %s", ifMatchHash, current))
+			return
+		}
+	}
+	if !h.checkWorkspaceQuota(c, targetPath, int64(len(data))) {
 		return
 	}
 
 	if err := ensureParentDir(targetPath); err != nil {
-		response.ErrorResponse(c, response.ServerError)
+		writeInternalError(c, err)
 		return
 	}
 	if err := os.WriteFile(targetPath, data, 0o644); err != nil {
-		response.ErrorResponse(c, response.ServerError)
+		writeInternalError(c, err)
+		return
+	}
+	resultMode, err := applyFileMode(targetPath, mode, hasMode)
+	if err != nil {
+		writeInternalError(c, err)
 		return
 	}
 
@@ -262,48 +407,304 @@ func (h *FSHandler) WriteFSFile(c *gin.Context) {
 		Path:     filepath.ToSlash(cleanedPath),
 		Size:     int64(len(data)),
 		Encoding: encoding,
+		Mode:     formatPerm(resultMode),
+
+		ContentHash: contentHash(data),
+	})
+}
+
+// contentHash 返回内容的十六进制 SHA-256
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// fileContentHash 流式计算普通文件内容的十六进制 SHA-256；目录、符号链接等返回 errNotRegularFile
+func fileContentHash(path string) (string, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", errNotRegularFile
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// DeleteFSFile 删除指定文件或目录，非空目录需显式指定 recursive=true
+func (h *FSHandler) DeleteFSFile(c *gin.Context) {
+	path := strings.TrimSpace(c.Query("path"))
+	if path == "" {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, "path is required")
+		return
+	}
+	recursive, err := strconv.ParseBool(c.DefaultQuery("recursive", "false"))
+	if err != nil {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, "recursive must be a boolean")
+		return
+	}
+
+	targetPath, cleanedPath, err := resolveWorkspacePath(h.workspaceRoot, path)
+	if err != nil {
+		writePathError(c, err)
+		return
+	}
+	if isProtectedPath(h.workspaceRoot, targetPath) {
+		response.APIErrorResponse(c, response.CodeProtectedPath, errDeleteProtectedPath.Error())
+		return
+	}
+
+	info, err := os.Lstat(targetPath)
+	if err != nil {
+		writeStatError(c, err)
+		return
+	}
+
+	entryType := "file"
+	if info.IsDir() {
+		entryType = "dir"
+	}
+	if info.IsDir() && recursive {
+		err = os.RemoveAll(targetPath)
+	} else {
+		err = os.Remove(targetPath)
+	}
+	// 删除可能部分成功，无论结果如何都让下次配额校验重新统计
+	h.usage.invalidate()
+	if err != nil {
+		if info.IsDir() && !recursive {
+			// 非空目录且未指定 recursive 时 os.Remove 失败，视为参数错误
+			response.APIErrorResponse(c, response.CodeDirectoryNotEmpty, "directory is not empty, set recursive=true")
+			return
+		}
+		writeInternalError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, models.DeleteFSFileResp{
+		Path: filepath.ToSlash(cleanedPath),
+		Type: entryType,
+	})
+}
+
+// MkdirFS 创建目录，父目录不存在时一并创建
+func (h *FSHandler) MkdirFS(c *gin.Context) {
+	var req models.MkdirFSReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
+		return
+	}
+
+	path := strings.TrimSpace(req.Path)
+	if path == "" {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, "path is required")
+		return
+	}
+
+	targetPath, cleanedPath, err := resolveWorkspacePath(h.workspaceRoot, path)
+	if err != nil {
+		writePathError(c, err)
+		return
+	}
+
+	if info, err := os.Stat(targetPath); err == nil && !info.IsDir() {
+		response.APIErrorResponse(c, response.CodeAlreadyExists, "path exists and is not a directory")
+		return
+	}
+	if err := os.MkdirAll(targetPath, 0o755); err != nil {
+		writeInternalError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, models.MkdirFSResp{
+		Path: filepath.ToSlash(cleanedPath),
+	})
+}
+
+// MoveFS 移动文件或目录，跨设备时退化为复制后删除源路径
+func (h *FSHandler) MoveFS(c *gin.Context) {
+	var req models.MoveFSReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
+		return
+	}
+
+	paths, ok := h.resolveTransferPaths(c, req.Src, req.Dst, true)
+	if !ok {
+		return
+	}
+
+	if err := os.Rename(paths.src, paths.dst); err != nil {
+		if !errors.Is(err, syscall.EXDEV) {
+			writeInternalError(c, err)
+			return
+		}
+		if _, err := copyPath(paths.src, paths.dst); err != nil {
+			_ = os.RemoveAll(paths.dst)
+			writeInternalError(c, err)
+			return
+		}
+		if err := os.RemoveAll(paths.src); err != nil {
+			writeInternalError(c, err)
+			return
+		}
+	}
+
+	response.SuccessResponse(c, models.MoveFSResp{
+		Src: filepath.ToSlash(paths.cleanedSrc),
+		Dst: filepath.ToSlash(paths.cleanedDst),
+	})
+}
+
+// CopyFS 复制文件或目录，目录按层级递归复制，返回复制的文件内容字节数
+func (h *FSHandler) CopyFS(c *gin.Context) {
+	var req models.CopyFSReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
+		return
+	}
+
+	paths, ok := h.resolveTransferPaths(c, req.Src, req.Dst, false)
+	if !ok {
+		return
+	}
+	if h.maxWorkspaceBytes > 0 {
+		srcUsage, err := computeWorkspaceUsage(paths.src)
+		if err != nil {
+			writeInternalError(c, err)
+			return
+		}
+		if !h.checkWorkspaceQuota(c, paths.dst, srcUsage.bytes) {
+			return
+		}
+	}
+
+	copied, err := copyPath(paths.src, paths.dst)
+	if err != nil {
+		_ = os.RemoveAll(paths.dst)
+		writeInternalError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, models.CopyFSResp{
+		Src:   filepath.ToSlash(paths.cleanedSrc),
+		Dst:   filepath.ToSlash(paths.cleanedDst),
+		Bytes: copied,
 	})
 }
 
+// transferPaths 为移动/复制接口解析后的源路径与目标路径
+type transferPaths struct {
+	src        string
+	cleanedSrc string
+	dst        string
+	cleanedDst string
+}
+
+// resolveTransferPaths 解析并校验移动/复制的源路径与目标路径，校验失败时直接写入响应并返回 false；
+// protectSrc 为 true 时拒绝以工作区根目录或文件系统根目录作为源路径
+func (h *FSHandler) resolveTransferPaths(c *gin.Context, src, dst string, protectSrc bool) (transferPaths, bool) {
+	src = strings.TrimSpace(src)
+	dst = strings.TrimSpace(dst)
+	if src == "" || dst == "" {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, "src and dst are required")
+		return transferPaths{}, false
+	}
+
+	var (
+		paths transferPaths
+		err   error
+	)
+	paths.src, paths.cleanedSrc, err = resolveWorkspacePath(h.workspaceRoot, src)
+	if err != nil {
+		writePathError(c, err)
+		return transferPaths{}, false
+	}
+	paths.dst, paths.cleanedDst, err = resolveWorkspacePath(h.workspaceRoot, dst)
+	if err != nil {
+		writePathError(c, err)
+		return transferPaths{}, false
+	}
+	if protectSrc && isProtectedPath(h.workspaceRoot, paths.src) {
+		response.APIErrorResponse(c, response.CodeProtectedPath, errMoveProtectedPath.Error())
+		return transferPaths{}, false
+	}
+
+	if _, err := os.Lstat(paths.src); err != nil {
+		writeStatError(c, err)
+		return transferPaths{}, false
+	}
+	// 目标已存在或位于源目录内部时拒绝，避免覆盖数据或无限递归复制
+	if _, err := os.Lstat(paths.dst); err == nil || !errors.Is(err, os.ErrNotExist) {
+		response.APIErrorResponse(c, response.CodeAlreadyExists, "dst already exists")
+		return transferPaths{}, false
+	}
+	if isWithinPath(paths.src, paths.dst) {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, "dst must not be inside src")
+		return transferPaths{}, false
+	}
+
+	if err := ensureParentDir(paths.dst); err != nil {
+		writeInternalError(c, err)
+		return transferPaths{}, false
+	}
+	return paths, true
+}
+
 // UploadFSFile 接收调用方上传的文件流并写入沙箱目标路径
 func (h *FSHandler) UploadFSFile(c *gin.Context) {
+	// 携带 target_file_path[] 时按批量上传处理，多个 file 字段与其一一对应
+	if targets, ok := c.GetPostFormArray(uploadTargetPathsField); ok {
+		h.uploadFSFiles(c, targets)
+		return
+	}
+
 	targetPath := strings.TrimSpace(c.PostForm("target_file_path"))
 	if targetPath == "" {
 		targetPath = strings.TrimSpace(c.Query("target_file_path"))
 	}
 	if targetPath == "" {
-		response.ErrorResponse(c, response.FormError)
+		response.APIErrorResponse(c, response.CodeInvalidArgument, "target_file_path is required")
 		return
 	}
 
 	file, header, err := c.Request.FormFile("file")
 	if err != nil {
-		response.ErrorResponse(c, response.FormError)
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
 		return
 	}
 	defer file.Close()
-
-	resolvedTargetPath, cleanedTargetPath, err := resolveWorkspacePath(h.workspaceRoot, targetPath)
-	if err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	if h.maxFileBytes > 0 && header.Size > h.maxFileBytes {
+		writeFileTooLarge(c, header.Size, h.maxFileBytes)
 		return
 	}
-
-	if err := ensureParentDir(resolvedTargetPath); err != nil {
-		response.ErrorResponse(c, response.ServerError)
+	mode, hasMode, err := parseFileMode(c.PostForm("mode"))
+	if err != nil {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
 		return
 	}
 
-	target, err := os.OpenFile(resolvedTargetPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	resolvedTargetPath, cleanedTargetPath, err := resolveWorkspacePath(h.workspaceRoot, targetPath)
 	if err != nil {
-		response.ErrorResponse(c, response.ServerError)
+		writePathError(c, err)
+		return
+	}
+	if !h.checkWorkspaceQuota(c, resolvedTargetPath, header.Size) {
 		return
 	}
-	defer target.Close()
 
-	size, err := io.Copy(target, file)
+	size, resultMode, err := writeUploadedFile(resolvedTargetPath, file, mode, hasMode)
 	if err != nil {
-		response.ErrorResponse(c, response.ServerError)
+		writeInternalError(c, err)
 		return
 	}
 
@@ -311,41 +712,72 @@ func (h *FSHandler) UploadFSFile(c *gin.Context) {
 		SourcePath: header.Filename,
 		TargetPath: filepath.ToSlash(cleanedTargetPath),
 		Size:       size,
+		Mode:       formatPerm(resultMode),
 	})
 }
 
-// DownloadFSFile 将沙箱文件以二进制流返回给调用方
+// writeUploadedFile 将 src 写入 resolvedPath（自动创建父目录、覆盖已有文件）并按需设置权限位，返回写入字节数与最终权限
+func writeUploadedFile(resolvedPath string, src io.Reader, mode os.FileMode, hasMode bool) (int64, os.FileMode, error) {
+	if err := ensureParentDir(resolvedPath); err != nil {
+		return 0, 0, err
+	}
+	target, err := os.OpenFile(resolvedPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer target.Close()
+
+	size, err := io.Copy(target, src)
+	if err != nil {
+		return 0, 0, err
+	}
+	resultMode, err := applyFileMode(resolvedPath, mode, hasMode)
+	if err != nil {
+		return 0, 0, err
+	}
+	return size, resultMode, nil
+}
+
+// DownloadFSFile 将沙箱文件以二进制流返回给调用方，支持 Range 分段下载
 func (h *FSHandler) DownloadFSFile(c *gin.Context) {
 	sourcePath := strings.TrimSpace(c.Query("path"))
 	if sourcePath == "" {
-		response.ErrorResponse(c, response.FormError)
+		response.APIErrorResponse(c, response.CodeInvalidArgument, "path is required")
 		return
 	}
 
 	resolvedSourcePath, cleanedSourcePath, err := resolveWorkspacePath(h.workspaceRoot, sourcePath)
 	if err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		writePathError(c, err)
 		return
 	}
 
 	info, err := os.Lstat(resolvedSourcePath)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			response.ErrorResponse(c, response.FormError)
-			return
-		}
-		response.ErrorResponse(c, response.ServerError)
+		writeStatError(c, err)
 		return
 	}
 	if info.IsDir() || info.Mode()&os.ModeSymlink != 0 {
-		response.ErrorResponse(c, response.FormError)
+		response.APIErrorResponse(c, response.CodeNotRegularFile, "path is not a regular file")
 		return
 	}
-	if h.maxFileBytes > 0 && info.Size() > h.maxFileBytes {
-		response.ErrorResponse(c, response.FormError)
+	if notModified(c, info) {
+		return
+	}
+	// 超过 maxFileBytes 的文件不允许整体下载，但允许按不超过上限的单个 Range 分段读取
+	if h.maxFileBytes > 0 && info.Size() > h.maxFileBytes &&
+		!rangeWithinLimit(c.Request.Header, info.Size(), info.ModTime(), fileETag(info), h.maxFileBytes) {
+		writeFileTooLarge(c, info.Size(), h.maxFileBytes)
 		return
 	}
 
+	file, err := os.Open(resolvedSourcePath)
+	if err != nil {
+		writeInternalError(c, err)
+		return
+	}
+	defer file.Close()
+
 	fileName := filepath.Base(cleanedSourcePath)
 	if fileName == "." || fileName == string(filepath.Separator) || fileName == "" {
 		fileName = "download.bin"
@@ -353,7 +785,90 @@ func (h *FSHandler) DownloadFSFile(c *gin.Context) {
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
 	c.Header("Content-Type", "application/octet-stream")
 	c.Header("X-Agentland-File-Path", filepath.ToSlash(cleanedSourcePath))
-	c.File(resolvedSourcePath)
+	http.ServeContent(c.Writer, c.Request, fileName, info.ModTime(), file)
+}
+
+// fileETag 由文件修改时间与大小生成 ETag，文件被改写时两者至少一项会变化，无需读取内容计算哈希
+func fileETag(info os.FileInfo) string {
+	return fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size())
+}
+
+// notModified 设置 ETag 响应头，If-None-Match 命中时返回 304 并返回 true
+func notModified(c *gin.Context, info os.FileInfo) bool {
+	etag := fileETag(info)
+	c.Header("ETag", etag)
+	if !etagMatches(c.GetHeader("If-None-Match"), etag) {
+		return false
+	}
+	c.Status(http.StatusNotModified)
+	return true
+}
+
+// etagMatches 按弱比较判断 If-None-Match（逗号分隔的 ETag 列表或 *）是否包含 etag
+func etagMatches(ifNoneMatch, etag string) bool {
+	ifNoneMatch = strings.TrimSpace(ifNoneMatch)
+	if ifNoneMatch == "" {
+		return false
+	}
+	if ifNoneMatch == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeWithinLimit 判断请求是否为长度不超过 limit 的单个字节区间，且 http.ServeContent 一定会按该区间响应
+func rangeWithinLimit(header http.Header, size int64, modTime time.Time, etag string, limit int64) bool {
+	// If-Range 不匹配时 ServeContent 会返回完整文件，此处仅接受与当前 ETag 或文件修改时间一致的 If-Range
+	if ifRange := strings.TrimSpace(header.Get("If-Range")); ifRange != "" {
+		if strings.HasPrefix(ifRange, `"`) || strings.HasPrefix(ifRange, "W/") {
+			// If-Range 要求强比较，弱 ETag 永不匹配
+			if ifRange != etag {
+				return false
+			}
+		} else if t, err := http.ParseTime(ifRange); err != nil || !modTime.Truncate(time.Second).Equal(t) {
+			return false
+		}
+	}
+
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header.Get("Range")), "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return false
+	}
+	startStr, endStr, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return false
+	}
+
+	var length int64
+	if startStr == "" {
+		// bytes=-N 表示最后 N 个字节
+		n, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || n <= 0 {
+			return false
+		}
+		length = min(n, size)
+	} else {
+		start, err := strconv.ParseInt(startStr, 10, 64)
+		if err != nil || start < 0 || start >= size {
+			return false
+		}
+		end := size - 1
+		if endStr != "" {
+			end, err = strconv.ParseInt(endStr, 10, 64)
+			if err != nil || end < start {
+				return false
+			}
+			end = min(end, size-1)
+		}
+		length = end - start + 1
+	}
+	return length <= limit
 }
 
 // parseDepth 解析并校验目录遍历深度参数
@@ -426,6 +941,69 @@ func decodeContent(content, encoding string) ([]byte, error) {
 	}
 }
 
+// parseFileMode 解析八进制权限参数，空值表示不修改；仅允许 0777 以内的权限位，拒绝 setuid/setgid/sticky
+func parseFileMode(v string) (os.FileMode, bool, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false, nil
+	}
+	parsed, err := strconv.ParseUint(v, 8, 32)
+	if err != nil {
+		return 0, false, fmt.Errorf("mode must be an octal string such as 0755")
+	}
+	if parsed&^uint64(os.ModePerm) != 0 {
+		return 0, false, fmt.Errorf("mode must only contain permission bits within 0777")
+	}
+	return os.FileMode(parsed), true, nil
+}
+
+// applyFileMode 在写入完成后按需设置权限，并返回文件最终的权限位
+func applyFileMode(path string, mode os.FileMode, hasMode bool) (os.FileMode, error) {
+	if hasMode {
+		if err := os.Chmod(path, mode); err != nil {
+			return 0, err
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Mode().Perm(), nil
+}
+
+// formatPerm 将权限位格式化为 0644 形式的八进制字符串
+func formatPerm(mode os.FileMode) string {
+	return fmt.Sprintf("%04o", mode.Perm())
+}
+
+// writePathError 返回路径解析失败的错误，越出工作区根目录时为 PATH_ESCAPE
+func writePathError(c *gin.Context, err error) {
+	if errors.Is(err, errPathEscapesWorkspaceRoot) {
+		response.APIErrorResponse(c, response.CodePathEscape, err.Error())
+		return
+	}
+	response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
+}
+
+// writeStatError 返回读取路径元信息失败的错误，路径不存在时为 NOT_FOUND
+func writeStatError(c *gin.Context, err error) {
+	if errors.Is(err, os.ErrNotExist) {
+		response.APIErrorResponse(c, response.CodeNotFound, "path not found")
+		return
+	}
+	writeInternalError(c, err)
+}
+
+// writeFileTooLarge 返回文件大小超过 maxFileBytes 的错误
+func writeFileTooLarge(c *gin.Context, size, limit int64) {
+	response.APIErrorResponse(c, response.CodeTooLarge, fmt.Sprintf("file size %d exceeds limit %d", size, limit))
+}
+
+// writeInternalError 返回未预期的内部错误
+func writeInternalError(c *gin.Context, err error) {
+	response.APIErrorResponse(c, response.CodeInternal, err.Error())
+}
+
 // resolveWorkspacePath 将请求路径解析为实际路径，并返回清洗后的路径字符串
 func resolveWorkspacePath(workspaceRoot, requested string) (string, string, error) {
 	root := filepath.Clean(workspaceRoot)
@@ -449,6 +1027,77 @@ func resolveWorkspacePath(workspaceRoot, requested string) (string, string, erro
 	return target, cleanedPath, nil
 }
 
+// isProtectedPath 判断目标路径是否为工作区根目录或文件系统根目录，二者不允许删除
+func isProtectedPath(workspaceRoot, target string) bool {
+	cleaned := filepath.Clean(target)
+	return cleaned == filepath.Clean(workspaceRoot) || cleaned == filepath.VolumeName(cleaned)+string(filepath.Separator)
+}
+
+// isWithinPath 判断 target 是否等于 base 或位于 base 目录之下
+func isWithinPath(base, target string) bool {
+	rel, err := filepath.Rel(filepath.Clean(base), filepath.Clean(target))
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// copyPath 将 src 复制到 dst，目录递归复制，符号链接按原目标重建，其余特殊文件忽略
+func copyPath(src, dst string) (int64, error) {
+	var copied int64
+	err := filepath.WalkDir(src, func(curr string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		rel, err := filepath.Rel(src, curr)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(curr)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			n, err := copyFile(curr, target, info.Mode().Perm())
+			copied += n
+			return err
+		default:
+			return nil
+		}
+	})
+	return copied, err
+}
+
+// copyFile 复制单个普通文件，目标文件必须不存在
+func copyFile(src, dst string, perm os.FileMode) (int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_EXCL, perm)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return n, err
+}
+
 // ensureParentDir 确保目标文件的父目录存在，不存在则自动创建
 func ensureParentDir(path string) error {
 	parent := filepath.Dir(path)
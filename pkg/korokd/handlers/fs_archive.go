@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	archiveFormatZip   = "zip"
+	archiveFormatTarGz = "targz"
+)
+
+// archiveEntry 为打包前收集的单个目录或普通文件
+type archiveEntry struct {
+	// name 为归档内使用的相对路径，目录以 / 结尾
+	name string
+	path string
+	info os.FileInfo
+}
+
+// ArchiveFS 将目录打包为 zip 或 tar.gz 并以流的形式返回，跳过符号链接等非普通文件
+func (h *FSHandler) ArchiveFS(c *gin.Context) {
+	dirPath := strings.TrimSpace(c.Query("path"))
+	if dirPath == "" {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, "path is required")
+		return
+	}
+	format, err := parseArchiveFormat(c.DefaultQuery("format", archiveFormatZip))
+	if err != nil {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
+		return
+	}
+	includeHidden, err := parseIncludeHidden(c.DefaultQuery("includeHidden", "false"))
+	if err != nil {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
+		return
+	}
+
+	targetPath, cleanedPath, err := resolveWorkspacePath(h.workspaceRoot, dirPath)
+	if err != nil {
+		writePathError(c, err)
+		return
+	}
+	info, err := os.Lstat(targetPath)
+	if err != nil {
+		writeStatError(c, err)
+		return
+	}
+	if !info.IsDir() {
+		response.APIErrorResponse(c, response.CodeNotDirectory, "path is not a directory")
+		return
+	}
+
+	// 先完整遍历并统计大小，超过上限时在写出任何内容之前返回错误
+	entries, totalBytes, err := collectArchiveEntries(targetPath, includeHidden)
+	if err != nil {
+		writeInternalError(c, err)
+		return
+	}
+	if h.maxArchiveBytes > 0 && totalBytes > h.maxArchiveBytes {
+		response.APIErrorResponse(c, response.CodeTooLarge,
+			fmt.Sprintf("archive content size %d exceeds limit %d", totalBytes, h.maxArchiveBytes))
+		return
+	}
+
+	baseName := filepath.Base(filepath.Clean(targetPath))
+	if baseName == "." || baseName == string(filepath.Separator) || baseName == "" {
+		baseName = "archive"
+	}
+	fileName := baseName + ".zip"
+	contentType := "application/zip"
+	if format == archiveFormatTarGz {
+		fileName = baseName + ".tar.gz"
+		contentType = "application/gzip"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Header("Content-Type", contentType)
+	c.Header("X-Agentland-File-Path", filepath.ToSlash(cleanedPath))
+	c.Status(http.StatusOK)
+
+	if format == archiveFormatTarGz {
+		err = writeTarGzArchive(c.Writer, entries)
+	} else {
+		err = writeZipArchive(c.Writer, entries)
+	}
+	if err != nil {
+		// 响应头已发出，只能记录日志并结束，客户端会得到缺少结尾的不完整归档
+		zap.L().Warn("Write fs archive failed", zap.String("path", cleanedPath), zap.Error(err))
+		c.Abort()
+	}
+}
+
+// parseArchiveFormat 解析并校验打包格式参数
+func parseArchiveFormat(v string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", archiveFormatZip:
+		return archiveFormatZip, nil
+	case archiveFormatTarGz, "tar.gz", "tgz":
+		return archiveFormatTarGz, nil
+	default:
+		return "", fmt.Errorf("format must be zip or targz")
+	}
+}
+
+// collectArchiveEntries 收集目录下需要打包的目录与普通文件，并返回普通文件总字节数
+func collectArchiveEntries(root string, includeHidden bool) ([]archiveEntry, int64, error) {
+	var (
+		entries    []archiveEntry
+		totalBytes int64
+	)
+	err := filepath.WalkDir(root, func(curr string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if curr == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, curr)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if !includeHidden && containsHiddenSegment(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			rel += "/"
+		} else {
+			totalBytes += info.Size()
+		}
+		entries = append(entries, archiveEntry{name: rel, path: curr, info: info})
+		return nil
+	})
+	return entries, totalBytes, err
+}
+
+// writeZipArchive 按收集结果写出 zip 归档
+func writeZipArchive(w io.Writer, entries []archiveEntry) error {
+	zw := zip.NewWriter(w)
+	for _, entry := range entries {
+		header, err := zip.FileInfoHeader(entry.info)
+		if err != nil {
+			return err
+		}
+		header.Name = entry.name
+		if entry.info.IsDir() {
+			if _, err := zw.CreateHeader(header); err != nil {
+				return err
+			}
+			continue
+		}
+		header.Method = zip.Deflate
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if err := copyArchiveFile(fw, entry); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// writeTarGzArchive 按收集结果写出 tar.gz 归档
+func writeTarGzArchive(w io.Writer, entries []archiveEntry) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	for _, entry := range entries {
+		header, err := tar.FileInfoHeader(entry.info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = entry.name
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if entry.info.IsDir() {
+			continue
+		}
+		if err := copyArchiveFile(tw, entry); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// copyArchiveFile 按遍历时记录的大小写出文件内容，避免文件在打包期间增长导致超出总大小上限
+func copyArchiveFile(w io.Writer, entry archiveEntry) error {
+	f, err := os.Open(entry.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.CopyN(w, f, entry.info.Size())
+	return err
+}
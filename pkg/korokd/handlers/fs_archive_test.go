@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func setupArchiveWorkspace(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "project", "src"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "project", ".cache"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "project", "README.md"), []byte("# demo\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "project", "src", "main.py"), []byte("print(1)\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "project", ".cache", "blob"), []byte("cached"), 0o644))
+	require.NoError(t, os.Symlink("/etc/passwd", filepath.Join(root, "project", "passwd")))
+	return root
+}
+
+func doArchive(t *testing.T, root string, maxArchiveBytes int64, rawQuery string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	InitFSApi(router.Group("/api"), root, 1024, maxArchiveBytes, 0, 0)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/fs/archive?"+rawQuery, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestFSHandler_Archive_Zip(t *testing.T) {
+	root := setupArchiveWorkspace(t)
+
+	w := doArchive(t, root, 1024, "path=project")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	require.Contains(t, w.Header().Get("Content-Disposition"), `filename="project.zip"`)
+
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	require.NoError(t, err)
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			files[f.Name] = ""
+			continue
+		}
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		files[f.Name] = string(data)
+	}
+	require.Equal(t, map[string]string{
+		"README.md":   "# demo\n",
+		"src/":        "",
+		"src/main.py": "print(1)\n",
+	}, files)
+}
+
+func TestFSHandler_Archive_TarGzIncludeHidden(t *testing.T) {
+	root := setupArchiveWorkspace(t)
+
+	w := doArchive(t, root, 0, "path=project&format=targz&includeHidden=true")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Header().Get("Content-Disposition"), `filename="project.tar.gz"`)
+
+	gr, err := gzip.NewReader(bytes.NewReader(w.Body.Bytes()))
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+	var names []string
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, header.Name)
+	}
+	require.ElementsMatch(t, []string{".cache/", ".cache/blob", "README.md", "src/", "src/main.py"}, names)
+}
+
+func TestFSHandler_Archive_SizeLimit(t *testing.T) {
+	root := setupArchiveWorkspace(t)
+
+	w := doArchive(t, root, 10, "path=project")
+	requireFSError(t, w, http.StatusRequestEntityTooLarge, response.CodeTooLarge)
+	require.Contains(t, w.Body.String(), "exceeds limit 10")
+	require.Empty(t, w.Header().Get("Content-Disposition"))
+}
+
+func TestFSHandler_Archive_RejectsInvalidRequests(t *testing.T) {
+	root := setupArchiveWorkspace(t)
+
+	for _, tc := range []struct {
+		rawQuery string
+		status   int
+		code     response.APIErrorCode
+	}{
+		{"", http.StatusBadRequest, response.CodeInvalidArgument},
+		{"path=project/README.md", http.StatusBadRequest, response.CodeNotDirectory},
+		{"path=missing", http.StatusNotFound, response.CodeNotFound},
+		{"path=project&format=rar", http.StatusBadRequest, response.CodeInvalidArgument},
+		{"path=../", http.StatusForbidden, response.CodePathEscape},
+	} {
+		w := doArchive(t, root, 0, tc.rawQuery)
+		requireFSError(t, w, tc.status, tc.code, tc.rawQuery)
+	}
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errArchiveEntryEscapes     = errors.New("archive entry escapes target dir")
+	errArchiveEntryUnsupported = errors.New("archive entry type is not supported")
+	errArchiveEntryTooLarge    = errors.New("archive entry exceeds file size limit")
+	errArchiveTooLarge         = errors.New("archive content exceeds size limit")
+)
+
+// extractEntry 为归档中的单个条目
+type extractEntry struct {
+	name string
+	mode fs.FileMode
+	// size 为归档声明的解压后大小，写入时仍按实际读取字节数校验
+	size int64
+	open func() (io.Reader, error)
+}
+
+// ExtractFS 接收上传的 zip/tar.gz 归档并解压到目标目录，拒绝越界路径与符号链接条目
+func (h *FSHandler) ExtractFS(c *gin.Context) {
+	targetDir := strings.TrimSpace(c.PostForm("target_dir"))
+	if targetDir == "" {
+		targetDir = strings.TrimSpace(c.Query("target_dir"))
+	}
+	if targetDir == "" {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, "target_dir is required")
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
+		return
+	}
+	defer file.Close()
+
+	format, err := parseArchiveFormat(c.PostForm("format"))
+	if err != nil {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
+		return
+	}
+	if strings.TrimSpace(c.PostForm("format")) == "" {
+		format = archiveFormatFromName(header.Filename)
+	}
+
+	targetPath, cleanedTargetPath, err := resolveWorkspacePath(h.workspaceRoot, targetDir)
+	if err != nil {
+		writePathError(c, err)
+		return
+	}
+
+	// 第一遍只校验条目路径、类型与声明大小，全部通过后才开始写文件
+	var declaredBytes int64
+	writes := make(map[string]int64)
+	err = walkArchiveEntries(file, header.Size, format, func(entry extractEntry) error {
+		dest, err := resolveExtractPath(targetPath, entry.name)
+		if err != nil {
+			return err
+		}
+		if !entry.mode.IsDir() && !entry.mode.IsRegular() {
+			return fmt.Errorf("%w: %s", errArchiveEntryUnsupported, entry.name)
+		}
+		if entry.mode.IsRegular() {
+			if h.maxFileBytes > 0 && entry.size > h.maxFileBytes {
+				return fmt.Errorf("%w: %s", errArchiveEntryTooLarge, entry.name)
+			}
+			declaredBytes += entry.size
+			if h.maxArchiveBytes > 0 && declaredBytes > h.maxArchiveBytes {
+				return errArchiveTooLarge
+			}
+			writes[dest] += entry.size
+		}
+		return nil
+	})
+	if err != nil {
+		writeExtractError(c, err)
+		return
+	}
+	// 按声明大小校验工作区配额；zip 与 tar 读取时实际字节数不会超过声明大小
+	if !h.checkWorkspaceQuotaAll(c, writes) {
+		return
+	}
+
+	resp := models.ExtractFSResp{
+		TargetDir: filepath.ToSlash(cleanedTargetPath),
+		Format:    format,
+	}
+	// 仅记录本次新建的文件，失败时清理，避免删除被覆盖的已有文件
+	var created []string
+	err = walkArchiveEntries(file, header.Size, format, func(entry extractEntry) error {
+		dest, err := resolveExtractPath(targetPath, entry.name)
+		if err != nil {
+			return err
+		}
+		if entry.mode.IsDir() {
+			return os.MkdirAll(dest, 0o755)
+		}
+
+		content, err := entry.open()
+		if err != nil {
+			return err
+		}
+		if err := ensureParentDir(dest); err != nil {
+			return err
+		}
+		_, statErr := os.Lstat(dest)
+		out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, entry.mode.Perm()|0o600)
+		if err != nil {
+			return err
+		}
+		if errors.Is(statErr, os.ErrNotExist) {
+			created = append(created, dest)
+		}
+
+		// 声明大小可能被伪造，按实际读取字节数再次校验单文件与总大小上限，limit<0 表示不限制
+		limit := int64(-1)
+		if h.maxFileBytes > 0 {
+			limit = h.maxFileBytes
+		}
+		if remaining := h.maxArchiveBytes - resp.Bytes; h.maxArchiveBytes > 0 && (limit < 0 || remaining < limit) {
+			limit = remaining
+		}
+		if limit >= 0 {
+			content = io.LimitReader(content, limit+1)
+		}
+		n, err := io.Copy(out, content)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		if limit >= 0 && n > limit {
+			if h.maxFileBytes > 0 && n > h.maxFileBytes {
+				return fmt.Errorf("%w: %s", errArchiveEntryTooLarge, entry.name)
+			}
+			return errArchiveTooLarge
+		}
+		resp.Files++
+		resp.Bytes += n
+		return nil
+	})
+	if err != nil {
+		for _, path := range created {
+			_ = os.Remove(path)
+		}
+		writeExtractError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, resp)
+}
+
+// writeExtractError 将解压错误映射为 HTTP 响应
+func writeExtractError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errArchiveEntryEscapes):
+		response.APIErrorResponse(c, response.CodePathEscape, err.Error())
+	case errors.Is(err, errArchiveTooLarge), errors.Is(err, errArchiveEntryTooLarge):
+		response.APIErrorResponse(c, response.CodeTooLarge, err.Error())
+	case errors.Is(err, errArchiveEntryUnsupported),
+		errors.Is(err, zip.ErrFormat), errors.Is(err, zip.ErrAlgorithm), errors.Is(err, zip.ErrChecksum),
+		errors.Is(err, gzip.ErrHeader), errors.Is(err, gzip.ErrChecksum), errors.Is(err, tar.ErrHeader),
+		errors.Is(err, io.ErrUnexpectedEOF):
+		response.APIErrorResponse(c, response.CodeInvalidArchive, err.Error())
+	default:
+		writeInternalError(c, err)
+	}
+}
+
+// archiveFormatFromName 根据上传文件名推断归档格式，无法识别时按 zip 处理
+func archiveFormatFromName(name string) string {
+	lower := strings.ToLower(name)
+	if strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz") {
+		return archiveFormatTarGz
+	}
+	return archiveFormatZip
+}
+
+// resolveExtractPath 校验归档条目名并返回其在目标目录下的实际路径，
+// 绝对路径、包含 .. 段或反斜杠的条目一律视为越界（zip-slip）
+func resolveExtractPath(targetPath, name string) (string, error) {
+	trimmed := strings.TrimSuffix(name, "/")
+	if trimmed == "" || strings.Contains(trimmed, `\`) || path.IsAbs(trimmed) || filepath.IsAbs(trimmed) {
+		return "", fmt.Errorf("%w: %q", errArchiveEntryEscapes, name)
+	}
+	for _, seg := range strings.Split(trimmed, "/") {
+		if seg == ".." {
+			return "", fmt.Errorf("%w: %q", errArchiveEntryEscapes, name)
+		}
+	}
+
+	dest, _, err := resolveWorkspacePath(targetPath, filepath.FromSlash(trimmed))
+	if err != nil || !isWithinPath(targetPath, dest) {
+		return "", fmt.Errorf("%w: %q", errArchiveEntryEscapes, name)
+	}
+	return dest, nil
+}
+
+// walkArchiveEntries 依次回调归档中的每个条目；硬链接按符号链接类型上报，以便调用方统一拒绝
+func walkArchiveEntries(src io.ReaderAt, size int64, format string, fn func(entry extractEntry) error) error {
+	if format == archiveFormatTarGz {
+		gr, err := gzip.NewReader(io.NewSectionReader(src, 0, size))
+		if err != nil {
+			return err
+		}
+		defer gr.Close()
+
+		tr := tar.NewReader(gr)
+		for {
+			header, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if header.Typeflag == tar.TypeXGlobalHeader {
+				continue
+			}
+			mode := header.FileInfo().Mode()
+			if header.Typeflag == tar.TypeLink {
+				mode |= fs.ModeSymlink
+			}
+			entry := extractEntry{
+				name: header.Name,
+				mode: mode,
+				size: header.Size,
+				open: func() (io.Reader, error) { return tr, nil },
+			}
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	zr, err := zip.NewReader(src, size)
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		var rc io.ReadCloser
+		entry := extractEntry{
+			name: f.Name,
+			mode: f.Mode(),
+			size: int64(min(f.UncompressedSize64, math.MaxInt64)),
+			open: func() (io.Reader, error) {
+				var err error
+				rc, err = f.Open()
+				return rc, err
+			},
+		}
+		err := fn(entry)
+		if rc != nil {
+			rc.Close()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
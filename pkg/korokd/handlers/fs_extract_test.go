@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type testArchiveEntry struct {
+	name    string
+	content string
+	mode    os.FileMode
+}
+
+func buildTestZip(t *testing.T, entries []testArchiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		if e.mode != 0 {
+			header.SetMode(e.mode)
+		}
+		w, err := zw.CreateHeader(header)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(e.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func doExtract(t *testing.T, root string, maxFileBytes, maxArchiveBytes int64, fileName string, archive []byte, targetDir string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	InitFSApi(router.Group("/api"), root, maxFileBytes, maxArchiveBytes, 0, 0)
+	return postExtract(t, router, fileName, archive, targetDir)
+}
+
+func postExtract(t *testing.T, router *gin.Engine, fileName string, archive []byte, targetDir string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", fileName)
+	require.NoError(t, err)
+	_, err = part.Write(archive)
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteField("target_dir", targetDir))
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/fs/extract", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestFSHandler_Extract_NestedZip(t *testing.T) {
+	root := t.TempDir()
+	archive := buildTestZip(t, []testArchiveEntry{
+		{name: "project/"},
+		{name: "project/README.md", content: "# demo\n"},
+		{name: "project/src/pkg/util.py", content: "x = 1\n"},
+		{name: "project/bin/run.sh", content: "#!/bin/sh\n", mode: 0o755},
+	})
+
+	w := doExtract(t, root, 1024, 4096, "project.zip", archive, "out")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.ExtractFSResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Equal(t, "out", resp.TargetDir)
+	require.Equal(t, "zip", resp.Format)
+	require.Equal(t, 3, resp.Files)
+	require.Equal(t, int64(23), resp.Bytes)
+
+	data, err := os.ReadFile(filepath.Join(root, "out", "project", "src", "pkg", "util.py"))
+	require.NoError(t, err)
+	require.Equal(t, "x = 1\n", string(data))
+	info, err := os.Stat(filepath.Join(root, "out", "project", "bin", "run.sh"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+}
+
+func TestFSHandler_Extract_TarGz(t *testing.T) {
+	root := t.TempDir()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0o755}))
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "./data/a.txt", Typeflag: tar.TypeReg, Mode: 0o644, Size: 5}))
+	_, err := tw.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	w := doExtract(t, root, 1024, 0, "bundle.tgz", buf.Bytes(), ".")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.FileExists(t, filepath.Join(root, "data", "a.txt"))
+}
+
+func TestFSHandler_Extract_RejectZipSlip(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "workspace")
+	require.NoError(t, os.MkdirAll(root, 0o755))
+
+	for _, name := range []string{"../evil.txt", "ok/../../evil.txt", "/tmp/evil.txt", `..\evil.txt`} {
+		archive := buildTestZip(t, []testArchiveEntry{
+			{name: "safe.txt", content: "safe"},
+			{name: name, content: "pwned"},
+		})
+		w := doExtract(t, root, 1024, 0, "evil.zip", archive, "out")
+		requireFSError(t, w, http.StatusForbidden, response.CodePathEscape, name)
+	}
+
+	require.NoFileExists(t, filepath.Join(base, "evil.txt"))
+	require.NoFileExists(t, filepath.Join(root, "out", "safe.txt"))
+}
+
+func TestFSHandler_Extract_RejectSymlinkAndLimits(t *testing.T) {
+	root := t.TempDir()
+
+	archive := buildTestZip(t, []testArchiveEntry{{name: "link", content: "/etc/passwd", mode: os.ModeSymlink | 0o777}})
+	w := doExtract(t, root, 1024, 0, "link.zip", archive, ".")
+	requireFSError(t, w, http.StatusBadRequest, response.CodeInvalidArchive)
+	require.NoFileExists(t, filepath.Join(root, "link"))
+
+	archive = buildTestZip(t, []testArchiveEntry{{name: "big.txt", content: "0123456789"}})
+	w = doExtract(t, root, 5, 0, "big.zip", archive, ".")
+	requireFSError(t, w, http.StatusRequestEntityTooLarge, response.CodeTooLarge)
+
+	archive = buildTestZip(t, []testArchiveEntry{
+		{name: "a.txt", content: "01234"},
+		{name: "b.txt", content: "56789"},
+	})
+	w = doExtract(t, root, 1024, 8, "total.zip", archive, ".")
+	requireFSError(t, w, http.StatusRequestEntityTooLarge, response.CodeTooLarge)
+	require.NoFileExists(t, filepath.Join(root, "a.txt"))
+
+	w = doExtract(t, root, 1024, 0, "broken.zip", []byte("not a zip"), ".")
+	requireFSError(t, w, http.StatusBadRequest, response.CodeInvalidArchive)
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// 搜索接口默认返回的最大匹配数
+	defaultSearchMaxResults = 100
+	// 搜索接口允许的最大匹配数
+	maxSearchMaxResults = 1000
+	// 单次搜索累计扫描的字节上限，防止大目录扫描失控
+	maxSearchScanBytes = 64 << 20
+	// 匹配行返回内容的最大字节数
+	maxSearchLineBytes = 512
+)
+
+// errSearchLimitReached 用于在达到结果或扫描上限时提前结束目录遍历
+var errSearchLimitReached = errors.New("search limit reached")
+
+// SearchFS 在目录下按字面量搜索文本文件内容，返回匹配的文件、行号和行内容
+func (h *FSHandler) SearchFS(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" || strings.ContainsAny(query, "\r\n") {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, "q is required and must be a single line")
+		return
+	}
+	depth, err := parseDepth(c.DefaultQuery("depth", "20"))
+	if err != nil {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
+		return
+	}
+	includeHidden, err := parseIncludeHidden(c.DefaultQuery("includeHidden", "false"))
+	if err != nil {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
+		return
+	}
+	maxResults, err := parseMaxResults(c.DefaultQuery("maxResults", strconv.Itoa(defaultSearchMaxResults)))
+	if err != nil {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
+		return
+	}
+	glob := strings.TrimSpace(c.Query("glob"))
+	if _, err := filepath.Match(glob, ""); err != nil {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
+		return
+	}
+
+	targetPath, cleanedRoot, err := resolveWorkspacePath(h.workspaceRoot, strings.TrimSpace(c.DefaultQuery("path", ".")))
+	if err != nil {
+		writePathError(c, err)
+		return
+	}
+	info, err := os.Stat(targetPath)
+	if err != nil {
+		writeStatError(c, err)
+		return
+	}
+	if !info.IsDir() {
+		response.APIErrorResponse(c, response.CodeNotDirectory, "path is not a directory")
+		return
+	}
+
+	resp := models.SearchFSResp{
+		Root:    filepath.ToSlash(cleanedRoot),
+		Matches: make([]models.FSSearchMatch, 0),
+	}
+	walkErr := filepath.WalkDir(targetPath, func(curr string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			// 无权限等不可读的子目录直接跳过，不影响其余结果
+			if curr != targetPath && d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return walkErr
+		}
+		if curr == targetPath {
+			return nil
+		}
+
+		rel, err := filepath.Rel(targetPath, curr)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if (!includeHidden && containsHiddenSegment(rel)) || pathDepth(rel) > depth {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		if glob != "" {
+			if ok, _ := filepath.Match(glob, d.Name()); !ok {
+				return nil
+			}
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if h.maxFileBytes > 0 && info.Size() > h.maxFileBytes {
+			return nil
+		}
+		if resp.ScannedBytes+info.Size() > maxSearchScanBytes {
+			resp.Truncated = true
+			return errSearchLimitReached
+		}
+
+		data, err := os.ReadFile(curr)
+		if err != nil {
+			return nil
+		}
+		resp.ScannedBytes += int64(len(data))
+		if !utf8.Valid(data) {
+			return nil
+		}
+		resp.ScannedFiles++
+
+		for i, line := range strings.Split(string(data), "\n") {
+			if !strings.Contains(line, query) {
+				continue
+			}
+			if len(resp.Matches) >= maxResults {
+				resp.Truncated = true
+				return errSearchLimitReached
+			}
+			resp.Matches = append(resp.Matches, models.FSSearchMatch{
+				Path: rel,
+				Line: i + 1,
+				Text: truncateUTF8(strings.TrimSuffix(line, "\r"), maxSearchLineBytes),
+			})
+		}
+		return nil
+	})
+	if walkErr != nil && !errors.Is(walkErr, errSearchLimitReached) {
+		writeInternalError(c, walkErr)
+		return
+	}
+
+	response.SuccessResponse(c, resp)
+}
+
+// parseMaxResults 解析并校验搜索结果数量上限参数
+func parseMaxResults(v string) (int, error) {
+	parsed, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil {
+		return 0, fmt.Errorf("maxResults must be an integer")
+	}
+	if parsed < 1 || parsed > maxSearchMaxResults {
+		return 0, fmt.Errorf("maxResults must be between 1 and %d", maxSearchMaxResults)
+	}
+	return parsed, nil
+}
+
+// truncateUTF8 将字符串截断到不超过 maxBytes 字节，且不拆分多字节字符
+func truncateUTF8(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
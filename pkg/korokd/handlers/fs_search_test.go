@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newSearchRouter(t *testing.T, root string, maxFileBytes int64) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	InitFSApi(router.Group("/api"), root, maxFileBytes, 0, 0, 0)
+	return router
+}
+
+func doSearch(t *testing.T, router *gin.Engine, rawQuery string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/fs/search?"+rawQuery, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestFSHandler_Search_MatchesLines(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "src", "pkg"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src", "main.py"), []byte("import os\nprint('TODO: fix')\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src", "pkg", "util.py"), []byte("# TODO one\r\nx = 1\n# TODO two\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src", "notes.md"), []byte("TODO in markdown\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "HEAD"), []byte("TODO hidden\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src", "blob.bin"), []byte{'T', 'O', 'D', 'O', 0xff, 0xfe}, 0o644))
+
+	router := newSearchRouter(t, root, 1024)
+	w := doSearch(t, router, "q=TODO&glob=*.py")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.SearchFSResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Equal(t, ".", resp.Root)
+	require.False(t, resp.Truncated)
+	require.Equal(t, 2, resp.ScannedFiles)
+	require.Equal(t, []models.FSSearchMatch{
+		{Path: "src/main.py", Line: 2, Text: "print('TODO: fix')"},
+		{Path: "src/pkg/util.py", Line: 1, Text: "# TODO one"},
+		{Path: "src/pkg/util.py", Line: 3, Text: "# TODO two"},
+	}, resp.Matches)
+
+	w = doSearch(t, router, "q=TODO&includeHidden=true")
+	require.Equal(t, http.StatusOK, w.Code)
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	paths := make([]string, 0, len(resp.Matches))
+	for _, m := range resp.Matches {
+		paths = append(paths, m.Path)
+	}
+	require.Contains(t, paths, ".git/HEAD")
+	require.Contains(t, paths, "src/notes.md")
+	require.NotContains(t, paths, "src/blob.bin")
+}
+
+func TestFSHandler_Search_LimitsResultsAndFileSize(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("hit\nhit\nhit\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "big.txt"), []byte(strings.Repeat("hit\n", 10)), 0o644))
+
+	router := newSearchRouter(t, root, 20)
+	w := doSearch(t, router, "q=hit&maxResults=2")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.SearchFSResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.True(t, resp.Truncated)
+	require.Len(t, resp.Matches, 2)
+
+	w = doSearch(t, router, "q=hit")
+	require.Equal(t, http.StatusOK, w.Code)
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.False(t, resp.Truncated)
+	require.Len(t, resp.Matches, 3)
+	require.Equal(t, 1, resp.ScannedFiles)
+}
+
+func TestFSHandler_Search_RejectsInvalidParams(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "workspace")
+	require.NoError(t, os.MkdirAll(root, 0o755))
+
+	router := newSearchRouter(t, root, 1024)
+	for _, rawQuery := range []string{"", "q=x&maxResults=0", "q=x&maxResults=1001", "q=x&glob=%5B", "q=x&depth=0"} {
+		w := doSearch(t, router, rawQuery)
+		requireFSError(t, w, http.StatusBadRequest, response.CodeInvalidArgument, rawQuery)
+	}
+
+	w := doSearch(t, router, "q=x&path=../")
+	requireFSError(t, w, http.StatusForbidden, response.CodePathEscape)
+}
+
+func TestTruncateUTF8(t *testing.T) {
+	require.Equal(t, "abc", truncateUTF8("abc", 5))
+	require.Equal(t, "ab", truncateUTF8("abc", 2))
+	require.Equal(t, "中", truncateUTF8("中文", 4))
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
+	"github.com/gin-gonic/gin"
+)
+
+// MIME 探测时最多读取的文件头字节数，与 http.DetectContentType 一致
+const mimeSniffBytes = 512
+
+// StatFS 返回文件或目录的元信息，不读取文件内容（MIME 探测仅读取文件头）
+func (h *FSHandler) StatFS(c *gin.Context) {
+	statPath := strings.TrimSpace(c.Query("path"))
+	if statPath == "" {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, "path is required")
+		return
+	}
+
+	targetPath, cleanedPath, err := resolveWorkspacePath(h.workspaceRoot, statPath)
+	if err != nil {
+		writePathError(c, err)
+		return
+	}
+
+	info, err := os.Lstat(targetPath)
+	if err != nil {
+		writeStatError(c, err)
+		return
+	}
+
+	resp := models.StatFSResp{
+		Path:      filepath.ToSlash(cleanedPath),
+		Name:      info.Name(),
+		Size:      info.Size(),
+		Mode:      info.Mode().String(),
+		Perm:      formatPerm(info.Mode()),
+		ModTime:   info.ModTime().UTC().Format(timeLayoutRFC3339),
+		IsDir:     info.IsDir(),
+		IsSymlink: info.Mode()&os.ModeSymlink != 0,
+	}
+	if resp.IsSymlink {
+		if target, err := os.Readlink(targetPath); err == nil {
+			resp.LinkTarget = target
+		}
+	}
+	if info.Mode().IsRegular() {
+		resp.MimeType = detectMimeType(targetPath)
+	}
+
+	response.SuccessResponse(c, resp)
+}
+
+// codeMimeTypes 为常见代码文件扩展名的 MIME 类型，优先于系统 MIME 表：
+// 系统表通常缺少这些类型，或将 .ts 等扩展名映射为无关类型，且不同镜像结果不一致
+var codeMimeTypes = map[string]string{
+	".py":    "text/x-python",
+	".ipynb": "application/x-ipynb+json",
+	".js":    "text/javascript",
+	".mjs":   "text/javascript",
+	".ts":    "text/x-typescript",
+	".tsx":   "text/x-typescript",
+	".jsx":   "text/javascript",
+	".go":    "text/x-go",
+	".rs":    "text/x-rust",
+	".java":  "text/x-java",
+	".c":     "text/x-c",
+	".h":     "text/x-c",
+	".cpp":   "text/x-c++",
+	".hpp":   "text/x-c++",
+	".sh":    "text/x-shellscript",
+	".rb":    "text/x-ruby",
+	".sql":   "application/sql",
+	".md":    "text/markdown",
+	".json":  "application/json",
+	".yaml":  "application/yaml",
+	".yml":   "application/yaml",
+	".toml":  "application/toml",
+	".csv":   "text/csv",
+}
+
+// mimeTypeByName 按扩展名推断 MIME 类型，扩展名未知时返回空
+func mimeTypeByName(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if byCode, ok := codeMimeTypes[ext]; ok {
+		return byCode
+	}
+	return mime.TypeByExtension(ext)
+}
+
+// sniffMimeType 按文件头（最多 mimeSniffBytes 字节）探测 MIME 类型
+func sniffMimeType(head []byte) string {
+	if len(head) > mimeSniffBytes {
+		head = head[:mimeSniffBytes]
+	}
+	return http.DetectContentType(head)
+}
+
+// detectMimeType 优先按扩展名推断 MIME 类型，无法推断时读取文件头探测
+func detectMimeType(path string) string {
+	if byExt := mimeTypeByName(path); byExt != "" {
+		return byExt
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	buf := make([]byte, mimeSniffBytes)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return ""
+	}
+	return sniffMimeType(buf[:n])
+}
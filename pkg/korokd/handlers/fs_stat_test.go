@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func doStat(t *testing.T, root, rawQuery string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	InitFSApi(router.Group("/api"), root, 1024, 0, 0, 0)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/fs/stat?"+rawQuery, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestFSHandler_Stat_File(t *testing.T) {
+	root := t.TempDir()
+	filePath := filepath.Join(root, "blob")
+	// 超过 maxFileBytes 的文件同样可以获取元信息
+	require.NoError(t, os.WriteFile(filePath, append([]byte("%PDF-1.7\n"), make([]byte, 4096)...), 0o640))
+	modTime := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(filePath, modTime, modTime))
+
+	w := doStat(t, root, "path=blob")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.StatFSResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Equal(t, "blob", resp.Path)
+	require.Equal(t, int64(4105), resp.Size)
+	require.Equal(t, "-rw-r-----", resp.Mode)
+	require.Equal(t, "0640", resp.Perm)
+	require.Equal(t, "2024-05-01T08:30:00Z", resp.ModTime)
+	require.False(t, resp.IsDir)
+	require.False(t, resp.IsSymlink)
+	require.Equal(t, "application/pdf", resp.MimeType)
+}
+
+func TestFSHandler_Stat_DirAndSymlink(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "src"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src", "main.py"), []byte("print(1)\n"), 0o644))
+	require.NoError(t, os.Symlink("src/main.py", filepath.Join(root, "entry")))
+
+	w := doStat(t, root, "path=src")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp models.StatFSResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.True(t, resp.IsDir)
+	require.Empty(t, resp.MimeType)
+
+	w = doStat(t, root, "path=entry")
+	require.Equal(t, http.StatusOK, w.Code)
+	resp = models.StatFSResp{}
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.True(t, resp.IsSymlink)
+	require.Equal(t, "src/main.py", resp.LinkTarget)
+	require.Empty(t, resp.MimeType)
+}
+
+func TestFSHandler_Stat_RejectsInvalidRequests(t *testing.T) {
+	root := t.TempDir()
+
+	requireFSError(t, doStat(t, root, ""), http.StatusBadRequest, response.CodeInvalidArgument)
+	requireFSError(t, doStat(t, root, "path=missing.txt"), http.StatusNotFound, response.CodeNotFound)
+
+	w := doStat(t, root, "path=../etc")
+	requireFSError(t, w, http.StatusForbidden, response.CodePathEscape)
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
+	"github.com/Fl0rencess720/agentland/pkg/korokd/pkgs/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// tail 接口默认返回的行数
+	defaultTailLines = 10
+	// tail 接口允许返回的最大行数
+	maxTailLines = 1000
+	// 读取末尾若干行时从文件尾部向前扫描的字节上限
+	maxTailScanBytes = 1 << 20
+	// follow 模式下单行的最大字节数，超过后不再等待换行符直接推送
+	maxTailLineBytes = 64 << 10
+)
+
+// follow 模式的轮询间隔与时长、字节上限，声明为变量便于测试调整
+var (
+	tailPollInterval      = 500 * time.Millisecond
+	tailFollowMaxDuration = 10 * time.Minute
+	tailFollowMaxBytes    = int64(16 << 20)
+)
+
+// TailFSFile 返回文件末尾若干行；follow=true 时改为 SSE 响应，并按轮询方式持续推送新追加的行
+func (h *FSHandler) TailFSFile(c *gin.Context) {
+	filePath := strings.TrimSpace(c.Query("path"))
+	if filePath == "" {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, "path is required")
+		return
+	}
+	lineCount, err := parseTailLines(c.DefaultQuery("lines", strconv.Itoa(defaultTailLines)))
+	if err != nil {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
+		return
+	}
+	follow, err := strconv.ParseBool(c.DefaultQuery("follow", "false"))
+	if err != nil {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, "follow must be a boolean")
+		return
+	}
+	targetPath, cleanedPath, err := resolveWorkspacePath(h.workspaceRoot, filePath)
+	if err != nil {
+		writePathError(c, err)
+		return
+	}
+
+	info, err := os.Lstat(targetPath)
+	if err != nil {
+		writeStatError(c, err)
+		return
+	}
+	if !info.Mode().IsRegular() {
+		response.APIErrorResponse(c, response.CodeNotRegularFile, "path is not a regular file")
+		return
+	}
+
+	lines, partial, err := readTailLines(targetPath, info.Size(), lineCount)
+	if err != nil {
+		writeInternalError(c, err)
+		return
+	}
+	if !follow {
+		response.SuccessResponse(c, models.TailFSResp{
+			Path:    filepath.ToSlash(cleanedPath),
+			Lines:   lines,
+			Offset:  info.Size(),
+			Partial: partial,
+		})
+		return
+	}
+
+	utils.SetupSSEResponse(c)
+	var mu sync.Mutex
+	send := func(evt models.FSTailEvent) bool {
+		evt.Timestamp = time.Now().UnixMilli()
+		return utils.WriteSSEData(c, &mu, evt)
+	}
+	if !send(models.FSTailEvent{Type: "lines", Lines: lines, Offset: info.Size()}) {
+		return
+	}
+	followFile(c, targetPath, info.Size(), send)
+}
+
+// followFile 从 offset 开始轮询文件追加内容并推送完整的行；文件变小时视为被截断并从头读取。
+// 达到时长或字节上限、文件无法读取或客户端断开时结束
+func followFile(c *gin.Context, targetPath string, offset int64, send func(models.FSTailEvent) bool) {
+	ticker := time.NewTicker(tailPollInterval)
+	defer ticker.Stop()
+	deadline := time.NewTimer(tailFollowMaxDuration)
+	defer deadline.Stop()
+
+	var pending []byte
+	var sent int64
+	finish := func(evt models.FSTailEvent) {
+		if len(pending) > 0 && !send(models.FSTailEvent{Type: "lines", Lines: []string{tailLine(pending)}, Offset: offset}) {
+			return
+		}
+		evt.Offset = offset
+		send(evt)
+	}
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-deadline.C:
+			finish(models.FSTailEvent{Type: "end", Reason: "follow duration limit reached"})
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(targetPath)
+		if err != nil {
+			finish(models.FSTailEvent{Type: "error", Reason: err.Error()})
+			return
+		}
+		if info.Size() < offset {
+			offset, pending = 0, nil
+			if !send(models.FSTailEvent{Type: "truncated", Offset: offset}) {
+				return
+			}
+		}
+		if info.Size() == offset {
+			continue
+		}
+
+		chunk, err := readFileRange(targetPath, offset, min(info.Size()-offset, tailFollowMaxBytes-sent))
+		if err != nil {
+			finish(models.FSTailEvent{Type: "error", Reason: err.Error()})
+			return
+		}
+		offset += int64(len(chunk))
+		sent += int64(len(chunk))
+
+		var lines []string
+		lines, pending = splitCompleteLines(append(pending, chunk...))
+		if len(lines) > 0 && !send(models.FSTailEvent{Type: "lines", Lines: lines, Offset: offset}) {
+			return
+		}
+		if sent >= tailFollowMaxBytes {
+			finish(models.FSTailEvent{Type: "end", Reason: "follow byte limit reached"})
+			return
+		}
+	}
+}
+
+// readTailLines 读取文件末尾最多 n 行（不含行尾换行符）；扫描字节达到上限仍不足 n 行时 partial 为 true
+func readTailLines(targetPath string, size int64, n int) ([]string, bool, error) {
+	start := max(size-maxTailScanBytes, 0)
+	data, err := readFileRange(targetPath, start, size-start)
+	if err != nil {
+		return nil, false, err
+	}
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	if len(data) == 0 {
+		return []string{}, false, nil
+	}
+
+	parts := bytes.Split(data, []byte("\n"))
+	partial := false
+	if start > 0 {
+		// 扫描窗口的第一行可能只是某行的后半段，丢弃
+		parts = parts[1:]
+		partial = len(parts) < n
+	}
+	if len(parts) > n {
+		parts = parts[len(parts)-n:]
+	}
+	lines := make([]string, 0, len(parts))
+	for _, part := range parts {
+		lines = append(lines, tailLine(part))
+	}
+	return lines, partial, nil
+}
+
+// readFileRange 读取文件中 [offset, offset+length) 区间的内容，文件提前结束时返回实际读到的部分
+func readFileRange(targetPath string, offset, length int64) ([]byte, error) {
+	f, err := os.Open(targetPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, length)
+	n, err := f.ReadAt(buf, offset)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// splitCompleteLines 拆出 data 中以换行符结尾的完整行，返回剩余的不完整尾部；
+// 尾部超过单行上限时同样作为一行返回，避免无换行的输出无限积压
+func splitCompleteLines(data []byte) ([]string, []byte) {
+	var lines []string
+	for {
+		idx := bytes.IndexByte(data, '\n')
+		if idx < 0 {
+			break
+		}
+		lines = append(lines, tailLine(data[:idx]))
+		data = data[idx+1:]
+	}
+	if len(data) >= maxTailLineBytes {
+		lines = append(lines, tailLine(data))
+		data = nil
+	}
+	return lines, bytes.Clone(data)
+}
+
+// tailLine 去掉行尾的 \r 并替换非法 UTF-8 字节，保证事件可以安全地序列化为 JSON
+func tailLine(b []byte) string {
+	return strings.ToValidUTF8(strings.TrimSuffix(string(b), "\r"), "�")
+}
+
+// parseTailLines 解析并校验 tail 返回行数参数
+func parseTailLines(v string) (int, error) {
+	parsed, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil {
+		return 0, fmt.Errorf("lines must be an integer")
+	}
+	if parsed < 1 || parsed > maxTailLines {
+		return 0, fmt.Errorf("lines must be between 1 and %d", maxTailLines)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func doTail(t *testing.T, router *gin.Engine, rawQuery string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/fs/tail?"+rawQuery, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestFSHandler_Tail_LastLines(t *testing.T) {
+	root := t.TempDir()
+	var log strings.Builder
+	for i := 1; i <= 50; i++ {
+		fmt.Fprintf(&log, "line %d\r\n", i)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(root, "server.log"), []byte(log.String()), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "short.log"), []byte("only\nno newline"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "empty.log"), nil, 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "logs"), 0o755))
+
+	router := newSearchRouter(t, root, 16)
+
+	w := doTail(t, router, "path=server.log&lines=3")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp models.TailFSResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Equal(t, "server.log", resp.Path)
+	require.Equal(t, []string{"line 48", "line 49", "line 50"}, resp.Lines)
+	require.Equal(t, int64(log.Len()), resp.Offset)
+	require.False(t, resp.Partial)
+
+	w = doTail(t, router, "path=short.log")
+	require.Equal(t, http.StatusOK, w.Code)
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Equal(t, []string{"only", "no newline"}, resp.Lines)
+
+	w = doTail(t, router, "path=empty.log")
+	require.Equal(t, http.StatusOK, w.Code)
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Empty(t, resp.Lines)
+	require.Zero(t, resp.Offset)
+
+	requireFSError(t, doTail(t, router, "path=server.log&lines=0"), http.StatusBadRequest, response.CodeInvalidArgument)
+	requireFSError(t, doTail(t, router, "path=server.log&lines=abc"), http.StatusBadRequest, response.CodeInvalidArgument)
+	requireFSError(t, doTail(t, router, "path=server.log&follow=maybe"), http.StatusBadRequest, response.CodeInvalidArgument)
+	requireFSError(t, doTail(t, router, "lines=3"), http.StatusBadRequest, response.CodeInvalidArgument)
+	requireFSError(t, doTail(t, router, "path=missing.log"), http.StatusNotFound, response.CodeNotFound)
+	requireFSError(t, doTail(t, router, "path=logs"), http.StatusBadRequest, response.CodeNotRegularFile)
+	requireFSError(t, doTail(t, router, "path=../etc/passwd"), http.StatusForbidden, response.CodePathEscape)
+}
+
+func TestFSHandler_Tail_FollowStreamsAppendedLines(t *testing.T) {
+	oldInterval, oldDuration := tailPollInterval, tailFollowMaxDuration
+	tailPollInterval, tailFollowMaxDuration = 10*time.Millisecond, 300*time.Millisecond
+	t.Cleanup(func() { tailPollInterval, tailFollowMaxDuration = oldInterval, oldDuration })
+
+	root := t.TempDir()
+	logPath := filepath.Join(root, "app.log")
+	require.NoError(t, os.WriteFile(logPath, []byte("boot\n"), 0o644))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		f, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+		_, _ = f.WriteString("ready\nlisten")
+		time.Sleep(50 * time.Millisecond)
+		_, _ = f.WriteString("ing on :8000\n")
+	}()
+
+	router := newSearchRouter(t, root, 0)
+	w := doTail(t, router, "path=app.log&follow=true")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	var lines []string
+	var last models.FSTailEvent
+	for _, frame := range strings.Split(strings.TrimSpace(w.Body.String()), "\n\n") {
+		require.True(t, strings.HasPrefix(frame, "data: "), frame)
+		last = models.FSTailEvent{}
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(frame, "data: ")), &last))
+		lines = append(lines, last.Lines...)
+	}
+	require.Equal(t, []string{"boot", "ready", "listening on :8000"}, lines)
+	require.Equal(t, "end", last.Type)
+	require.Equal(t, "follow duration limit reached", last.Reason)
+	require.Equal(t, int64(len("boot\nready\nlistening on :8000\n")), last.Offset)
+}
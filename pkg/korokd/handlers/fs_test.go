@@ -4,15 +4,18 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
 )
@@ -33,6 +36,20 @@ func decodeFSSuccessData(t *testing.T, body []byte, out interface{}) {
 	require.NoError(t, json.Unmarshal(resp.Data, out))
 }
 
+// requireFSError 断言响应为统一错误体，且 HTTP 状态码与错误码符合预期
+func requireFSError(t *testing.T, w *httptest.ResponseRecorder, status int, code response.APIErrorCode, msgAndArgs ...interface{}) {
+	t.Helper()
+
+	require.Equal(t, status, w.Code, msgAndArgs...)
+	var resp struct {
+		Code response.APIErrorCode `json:"code"`
+		Msg  string                `json:"msg"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), msgAndArgs...)
+	require.Equal(t, code, resp.Code, msgAndArgs...)
+	require.NotEmpty(t, resp.Msg, msgAndArgs...)
+}
+
 func TestFSHandler_GetTree_HidesDotFilesByDefault(t *testing.T) {
 	gin.SetMode(gin.ReleaseMode)
 	root := t.TempDir()
@@ -41,7 +58,7 @@ func TestFSHandler_GetTree_HidesDotFilesByDefault(t *testing.T) {
 
 	router := gin.New()
 	group := router.Group("/api")
-	InitFSApi(group, root, 1024)
+	InitFSApi(group, root, 1024, 0, 0, 0)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/fs/tree?path=.&depth=5", nil)
 	w := httptest.NewRecorder()
@@ -66,7 +83,7 @@ func TestFSHandler_GetTree_IncludeHidden(t *testing.T) {
 
 	router := gin.New()
 	group := router.Group("/api")
-	InitFSApi(group, root, 1024)
+	InitFSApi(group, root, 1024, 0, 0, 0)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/fs/tree?path=.&depth=5&includeHidden=true", nil)
 	w := httptest.NewRecorder()
@@ -87,7 +104,7 @@ func TestFSHandler_GetTree_AllowsAbsolutePath(t *testing.T) {
 
 	router := gin.New()
 	group := router.Group("/api")
-	InitFSApi(group, root, 1024)
+	InitFSApi(group, root, 1024, 0, 0, 0)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/fs/tree?path="+url.QueryEscape(absRoot)+"&depth=5", nil)
 	w := httptest.NewRecorder()
@@ -107,13 +124,12 @@ func TestFSHandler_GetTree_RejectRelativeTraversal(t *testing.T) {
 
 	router := gin.New()
 	group := router.Group("/api")
-	InitFSApi(group, root, 1024)
+	InitFSApi(group, root, 1024, 0, 0, 0)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/fs/tree?path=../../etc&depth=5", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
-	require.Equal(t, http.StatusForbidden, w.Code)
-	require.Contains(t, w.Body.String(), "path escapes workspace root")
+	requireFSError(t, w, http.StatusForbidden, response.CodePathEscape)
 }
 
 func TestFSHandler_GetFile_UTF8(t *testing.T) {
@@ -123,7 +139,7 @@ func TestFSHandler_GetFile_UTF8(t *testing.T) {
 
 	router := gin.New()
 	group := router.Group("/api")
-	InitFSApi(group, root, 1024)
+	InitFSApi(group, root, 1024, 0, 0, 0)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/fs/file?path=main.ts&encoding=utf8", nil)
 	w := httptest.NewRecorder()
@@ -135,6 +151,88 @@ func TestFSHandler_GetFile_UTF8(t *testing.T) {
 	require.Equal(t, "main.ts", resp.Path)
 	require.Equal(t, "utf8", resp.Encoding)
 	require.Contains(t, resp.Content, "console.log")
+	require.Equal(t, "text/x-typescript", resp.ContentType)
+}
+
+func TestFSHandler_GetFile_LineRange(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	root := t.TempDir()
+	var fixture bytes.Buffer
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&fixture, "line %d\n", i)
+	}
+	fixture.WriteString("tail without newline")
+	require.NoError(t, os.WriteFile(filepath.Join(root, "big.txt"), fixture.Bytes(), 0o644))
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitFSApi(group, root, 1024, 0, 0, 0)
+
+	get := func(rawQuery string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/fs/file?path=big.txt&"+rawQuery, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("start_line=12&end_line=14")
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp models.GetFSFileResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Equal(t, "line 12\nline 13\nline 14\n", resp.Content)
+	require.Equal(t, 31, resp.TotalLines)
+	require.Equal(t, int64(fixture.Len()), resp.Size)
+
+	for rawQuery, want := range map[string]string{
+		"start_line=30":              "line 30\ntail without newline",
+		"end_line=2":                 "line 1\nline 2\n",
+		"start_line=31&end_line=100": "tail without newline",
+		"start_line=40":              "",
+	} {
+		w = get(rawQuery)
+		require.Equal(t, http.StatusOK, w.Code, rawQuery)
+		resp = models.GetFSFileResp{}
+		decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+		require.Equal(t, want, resp.Content, rawQuery)
+		require.Equal(t, 31, resp.TotalLines, rawQuery)
+	}
+
+	for _, rawQuery := range []string{"start_line=0", "start_line=5&end_line=4", "end_line=-1", "start_line=x", "start_line=1&encoding=base64"} {
+		requireFSError(t, get(rawQuery), http.StatusBadRequest, response.CodeInvalidArgument, rawQuery)
+	}
+}
+
+func TestFSHandler_GetFile_ContentType(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "config.json"), []byte(`{"a":1}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "main.py"), []byte("print(1)\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "blob"), []byte{0x00, 0xff, 0xfe}, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "page"), []byte("<html><body>hi</body></html>"), 0o644))
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitFSApi(group, root, 1024, 0, 0, 0)
+
+	for _, tc := range []struct {
+		query       string
+		contentType string
+	}{
+		{"path=config.json", "application/json"},
+		{"path=main.py", "text/x-python"},
+		// 无扩展名时按文件头探测，非 UTF-8 内容仍以 base64 返回
+		{"path=blob&encoding=base64", "application/octet-stream"},
+		{"path=page", "text/html; charset=utf-8"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/fs/file?"+tc.query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, tc.query)
+
+		var resp models.GetFSFileResp
+		decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+		require.Equal(t, tc.contentType, resp.ContentType, tc.query)
+	}
 }
 
 func TestFSHandler_GetFile_Base64(t *testing.T) {
@@ -145,7 +243,7 @@ func TestFSHandler_GetFile_Base64(t *testing.T) {
 
 	router := gin.New()
 	group := router.Group("/api")
-	InitFSApi(group, root, 1024)
+	InitFSApi(group, root, 1024, 0, 0, 0)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/fs/file?path=bin.dat&encoding=base64", nil)
 	w := httptest.NewRecorder()
@@ -164,13 +262,12 @@ func TestFSHandler_GetFile_RejectInvalidUTF8(t *testing.T) {
 
 	router := gin.New()
 	group := router.Group("/api")
-	InitFSApi(group, root, 1024)
+	InitFSApi(group, root, 1024, 0, 0, 0)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/fs/file?path=bin.dat&encoding=utf8", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
-	require.Equal(t, http.StatusBadRequest, w.Code)
-	require.Contains(t, w.Body.String(), `"msg":"Form Error"`)
+	requireFSError(t, w, http.StatusUnprocessableEntity, response.CodeNotUTF8)
 }
 
 func TestFSHandler_GetFile_TooLarge(t *testing.T) {
@@ -180,13 +277,12 @@ func TestFSHandler_GetFile_TooLarge(t *testing.T) {
 
 	router := gin.New()
 	group := router.Group("/api")
-	InitFSApi(group, root, 5)
+	InitFSApi(group, root, 5, 0, 0, 0)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/fs/file?path=big.txt", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
-	require.Equal(t, http.StatusBadRequest, w.Code)
-	require.Contains(t, w.Body.String(), `"msg":"Form Error"`)
+	requireFSError(t, w, http.StatusRequestEntityTooLarge, response.CodeTooLarge)
 }
 
 func TestFSHandler_WriteFile_UTF8(t *testing.T) {
@@ -197,7 +293,7 @@ func TestFSHandler_WriteFile_UTF8(t *testing.T) {
 
 	router := gin.New()
 	group := router.Group("/api")
-	InitFSApi(group, root, 1024)
+	InitFSApi(group, root, 1024, 0, 0, 0)
 
 	reqBody := models.WriteFSFileReq{
 		Path:     targetPath,
@@ -230,7 +326,7 @@ func TestFSHandler_WriteFile_RejectRelativeTraversal(t *testing.T) {
 
 	router := gin.New()
 	group := router.Group("/api")
-	InitFSApi(group, root, 1024)
+	InitFSApi(group, root, 1024, 0, 0, 0)
 
 	reqBody := models.WriteFSFileReq{
 		Path:    "../escape.txt",
@@ -243,14 +339,360 @@ func TestFSHandler_WriteFile_RejectRelativeTraversal(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
-	require.Equal(t, http.StatusForbidden, w.Code)
-	require.Contains(t, w.Body.String(), "path escapes workspace root")
+	requireFSError(t, w, http.StatusForbidden, response.CodePathEscape)
 
 	_, statErr := os.Stat(filepath.Join(base, "escape.txt"))
 	require.Error(t, statErr)
 	require.True(t, os.IsNotExist(statErr))
 }
 
+func TestFSHandler_WriteFile_TooLarge(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	root := t.TempDir()
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitFSApi(group, root, 5, 0, 0, 0)
+
+	bodyBytes, err := json.Marshal(models.WriteFSFileReq{Path: "big.txt", Content: "123456"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/fs/file", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	requireFSError(t, w, http.StatusRequestEntityTooLarge, response.CodeTooLarge)
+
+	_, statErr := os.Stat(filepath.Join(root, "big.txt"))
+	require.True(t, os.IsNotExist(statErr))
+}
+
+func TestFSHandler_WriteFile_Mode(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	root := t.TempDir()
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitFSApi(group, root, 1024, 0, 0, 0)
+
+	bodyBytes, err := json.Marshal(models.WriteFSFileReq{Path: "run.sh", Content: "#!/bin/sh\necho ok\n", Mode: "0755"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/fs/file", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.WriteFSFileResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Equal(t, "0755", resp.Mode)
+
+	info, err := os.Stat(filepath.Join(root, "run.sh"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+	require.NotZero(t, info.Mode().Perm()&0o111)
+
+	for _, mode := range []string{"4755", "2755", "0x755", "rwx"} {
+		bodyBytes, err := json.Marshal(models.WriteFSFileReq{Path: "bad.sh", Content: "x", Mode: mode})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/fs/file", bytes.NewReader(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		requireFSError(t, w, http.StatusBadRequest, response.CodeInvalidArgument, mode)
+	}
+	_, statErr := os.Stat(filepath.Join(root, "bad.sh"))
+	require.True(t, os.IsNotExist(statErr))
+}
+
+func TestFSHandler_WriteFile_IfMatchHash(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	root := t.TempDir()
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitFSApi(group, root, 1024, 0, 0, 0)
+
+	write := func(req models.WriteFSFileReq) *httptest.ResponseRecorder {
+		bodyBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/fs/file", bytes.NewReader(bodyBytes))
+		httpReq.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	w := write(models.WriteFSFileReq{Path: "notes.md", Content: "v1"})
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp models.WriteFSFileResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	v1Hash := resp.ContentHash
+	require.Equal(t, contentHash([]byte("v1")), v1Hash)
+
+	// 哈希匹配时写入成功并返回新内容的哈希，大小写不敏感
+	w = write(models.WriteFSFileReq{Path: "notes.md", Content: "v2", IfMatchHash: strings.ToUpper(v1Hash)})
+	require.Equal(t, http.StatusOK, w.Code)
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Equal(t, contentHash([]byte("v2")), resp.ContentHash)
+
+	// 使用过期的哈希写入被拒绝，文件内容保持不变
+	w = write(models.WriteFSFileReq{Path: "notes.md", Content: "stale", IfMatchHash: v1Hash})
+	requireFSError(t, w, http.StatusConflict, response.CodeHashMismatch)
+	data, err := os.ReadFile(filepath.Join(root, "notes.md"))
+	require.NoError(t, err)
+	require.Equal(t, "v2", string(data))
+
+	// 文件不存在时任何哈希都不匹配
+	w = write(models.WriteFSFileReq{Path: "missing.md", Content: "x", IfMatchHash: v1Hash})
+	requireFSError(t, w, http.StatusConflict, response.CodeHashMismatch)
+	_, statErr := os.Stat(filepath.Join(root, "missing.md"))
+	require.True(t, os.IsNotExist(statErr))
+}
+
+func TestFSHandler_DeleteFile(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("hello"), 0o644))
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitFSApi(group, root, 1024, 0, 0, 0)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/fs/file?path=a.txt", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.DeleteFSFileResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Equal(t, "a.txt", resp.Path)
+	require.Equal(t, "file", resp.Type)
+
+	_, statErr := os.Stat(filepath.Join(root, "a.txt"))
+	require.True(t, os.IsNotExist(statErr))
+}
+
+func TestFSHandler_DeleteFile_NonEmptyDirRequiresRecursive(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "dir", "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "dir", "sub", "a.txt"), []byte("hello"), 0o644))
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitFSApi(group, root, 1024, 0, 0, 0)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/fs/file?path=dir", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	requireFSError(t, w, http.StatusConflict, response.CodeDirectoryNotEmpty)
+	require.DirExists(t, filepath.Join(root, "dir"))
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/fs/file?path=dir&recursive=true", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.DeleteFSFileResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Equal(t, "dir", resp.Type)
+	require.NoDirExists(t, filepath.Join(root, "dir"))
+}
+
+func TestFSHandler_DeleteFile_RejectWorkspaceRootAndTraversal(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	base := t.TempDir()
+	root := filepath.Join(base, "workspace")
+	require.NoError(t, os.MkdirAll(root, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(base, "outside.txt"), []byte("keep"), 0o644))
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitFSApi(group, root, 1024, 0, 0, 0)
+
+	for _, path := range []string{".", "/", url.QueryEscape(root)} {
+		req := httptest.NewRequest(http.MethodDelete, "/api/fs/file?recursive=true&path="+path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		requireFSError(t, w, http.StatusForbidden, response.CodeProtectedPath, path)
+	}
+	require.DirExists(t, root)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/fs/file?path=../outside.txt", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	requireFSError(t, w, http.StatusForbidden, response.CodePathEscape)
+	require.FileExists(t, filepath.Join(base, "outside.txt"))
+}
+
+func TestFSHandler_Mkdir(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	root := t.TempDir()
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitFSApi(group, root, 1024, 0, 0, 0)
+
+	bodyBytes, err := json.Marshal(models.MkdirFSReq{Path: "a/b/c"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/fs/mkdir", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.MkdirFSResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Equal(t, "a/b/c", resp.Path)
+	require.DirExists(t, filepath.Join(root, "a", "b", "c"))
+}
+
+func TestFSHandler_Mkdir_RejectRelativeTraversal(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	base := t.TempDir()
+	root := filepath.Join(base, "workspace")
+	require.NoError(t, os.MkdirAll(root, 0o755))
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitFSApi(group, root, 1024, 0, 0, 0)
+
+	bodyBytes, err := json.Marshal(models.MkdirFSReq{Path: "../escape"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/fs/mkdir", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	requireFSError(t, w, http.StatusForbidden, response.CodePathEscape)
+	require.NoDirExists(t, filepath.Join(base, "escape"))
+}
+
+func postFSJSON(t *testing.T, router *gin.Engine, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	bodyBytes, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestFSHandler_MoveDir(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "src", "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src", "sub", "a.txt"), []byte("hello"), 0o644))
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitFSApi(group, root, 1024, 0, 0, 0)
+
+	w := postFSJSON(t, router, "/api/fs/move", models.MoveFSReq{Src: "src", Dst: "out/moved"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.MoveFSResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Equal(t, "src", resp.Src)
+	require.Equal(t, "out/moved", resp.Dst)
+
+	require.NoDirExists(t, filepath.Join(root, "src"))
+	data, err := os.ReadFile(filepath.Join(root, "out", "moved", "sub", "a.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(data))
+}
+
+func TestFSHandler_CopyDir_Recursive(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "src", "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src", "a.txt"), []byte("hello"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src", "sub", "b.txt"), []byte("world!"), 0o600))
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitFSApi(group, root, 1024, 0, 0, 0)
+
+	w := postFSJSON(t, router, "/api/fs/copy", models.CopyFSReq{Src: "src", Dst: "dst"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.CopyFSResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Equal(t, int64(11), resp.Bytes)
+
+	require.FileExists(t, filepath.Join(root, "src", "a.txt"))
+	data, err := os.ReadFile(filepath.Join(root, "dst", "sub", "b.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "world!", string(data))
+	info, err := os.Stat(filepath.Join(root, "dst", "sub", "b.txt"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestFSHandler_CopyFile_RejectExistingDstAndSelfNesting(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "src"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("a"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "b.txt"), []byte("b"), 0o644))
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitFSApi(group, root, 1024, 0, 0, 0)
+
+	w := postFSJSON(t, router, "/api/fs/copy", models.CopyFSReq{Src: "a.txt", Dst: "b.txt"})
+	requireFSError(t, w, http.StatusConflict, response.CodeAlreadyExists)
+	data, err := os.ReadFile(filepath.Join(root, "b.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "b", string(data))
+
+	w = postFSJSON(t, router, "/api/fs/copy", models.CopyFSReq{Src: "src", Dst: "src/nested"})
+	requireFSError(t, w, http.StatusBadRequest, response.CodeInvalidArgument)
+	require.NoDirExists(t, filepath.Join(root, "src", "nested"))
+
+	w = postFSJSON(t, router, "/api/fs/move", models.MoveFSReq{Src: "missing.txt", Dst: "c.txt"})
+	requireFSError(t, w, http.StatusNotFound, response.CodeNotFound)
+}
+
+func TestFSHandler_MoveCopy_RejectRelativeTraversal(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	base := t.TempDir()
+	root := filepath.Join(base, "workspace")
+	require.NoError(t, os.MkdirAll(root, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("a"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(base, "secret.txt"), []byte("s"), 0o644))
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitFSApi(group, root, 1024, 0, 0, 0)
+
+	cases := []struct {
+		path string
+		body interface{}
+		code response.APIErrorCode
+	}{
+		{"/api/fs/move", models.MoveFSReq{Src: "a.txt", Dst: "../escape.txt"}, response.CodePathEscape},
+		{"/api/fs/move", models.MoveFSReq{Src: "../secret.txt", Dst: "stolen.txt"}, response.CodePathEscape},
+		{"/api/fs/copy", models.CopyFSReq{Src: "a.txt", Dst: "../escape.txt"}, response.CodePathEscape},
+		{"/api/fs/copy", models.CopyFSReq{Src: "../secret.txt", Dst: "stolen.txt"}, response.CodePathEscape},
+		{"/api/fs/move", models.MoveFSReq{Src: ".", Dst: "moved-root"}, response.CodeProtectedPath},
+	}
+	for _, tc := range cases {
+		w := postFSJSON(t, router, tc.path, tc.body)
+		requireFSError(t, w, http.StatusForbidden, tc.code, tc.body)
+	}
+
+	require.NoFileExists(t, filepath.Join(base, "escape.txt"))
+	require.NoFileExists(t, filepath.Join(root, "stolen.txt"))
+	require.FileExists(t, filepath.Join(root, "a.txt"))
+}
+
 func TestFSHandler_UploadFile(t *testing.T) {
 	gin.SetMode(gin.ReleaseMode)
 	root := t.TempDir()
@@ -259,7 +701,7 @@ func TestFSHandler_UploadFile(t *testing.T) {
 
 	router := gin.New()
 	group := router.Group("/api")
-	InitFSApi(group, root, 1024)
+	InitFSApi(group, root, 1024, 0, 0, 0)
 
 	var body bytes.Buffer
 	writer := multipart.NewWriter(&body)
@@ -280,19 +722,53 @@ func TestFSHandler_UploadFile(t *testing.T) {
 	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
 	require.Equal(t, "dataset.csv", resp.SourcePath)
 	require.Equal(t, filepath.ToSlash(filepath.Clean(targetPath)), resp.TargetPath)
+	require.Equal(t, "0644", resp.Mode)
 
 	data, err := os.ReadFile(targetPath)
 	require.NoError(t, err)
 	require.Equal(t, "name,value\nalice,1\n", string(data))
 }
 
+func TestFSHandler_UploadFile_Mode(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	root := t.TempDir()
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitFSApi(group, root, 1024, 0, 0, 0)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "tool")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("#!/bin/sh\n"))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteField("target_file_path", "bin/tool"))
+	require.NoError(t, writer.WriteField("mode", "0750"))
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/fs/upload", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.UploadFSFileResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Equal(t, "0750", resp.Mode)
+
+	info, err := os.Stat(filepath.Join(root, "bin", "tool"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o750), info.Mode().Perm())
+}
+
 func TestFSHandler_UploadFile_RejectJSONBody(t *testing.T) {
 	gin.SetMode(gin.ReleaseMode)
 	root := t.TempDir()
 
 	router := gin.New()
 	group := router.Group("/api")
-	InitFSApi(group, root, 1024)
+	InitFSApi(group, root, 1024, 0, 0, 0)
 
 	reqBody := map[string]string{
 		"local_file_path":  "/tmp/a.csv",
@@ -305,8 +781,7 @@ func TestFSHandler_UploadFile_RejectJSONBody(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
-	require.Equal(t, http.StatusBadRequest, w.Code)
-	require.Contains(t, w.Body.String(), `"msg":"Form Error"`)
+	requireFSError(t, w, http.StatusBadRequest, response.CodeInvalidArgument)
 }
 
 func TestFSHandler_DownloadFile(t *testing.T) {
@@ -318,7 +793,7 @@ func TestFSHandler_DownloadFile(t *testing.T) {
 
 	router := gin.New()
 	group := router.Group("/api")
-	InitFSApi(group, root, 1024)
+	InitFSApi(group, root, 1024, 0, 0, 0)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/fs/download?path="+url.QueryEscape(sourcePath), nil)
 	w := httptest.NewRecorder()
@@ -328,3 +803,136 @@ func TestFSHandler_DownloadFile(t *testing.T) {
 	require.Contains(t, w.Header().Get("Content-Disposition"), "result.csv")
 	require.Equal(t, filepath.ToSlash(filepath.Clean(sourcePath)), w.Header().Get("X-Agentland-File-Path"))
 }
+
+func TestFSHandler_ConditionalRead_ETag(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	root := t.TempDir()
+	filePath := filepath.Join(root, "notes.txt")
+	require.NoError(t, os.WriteFile(filePath, []byte("v1"), 0o644))
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitFSApi(group, root, 1024, 0, 0, 0)
+
+	get := func(target, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, target := range []string{"/api/fs/file?path=notes.txt", "/api/fs/download?path=notes.txt"} {
+		w := get(target, "")
+		require.Equal(t, http.StatusOK, w.Code, target)
+		etag := w.Header().Get("ETag")
+		require.NotEmpty(t, etag, target)
+
+		w = get(target, etag)
+		require.Equal(t, http.StatusNotModified, w.Code, target)
+		require.Empty(t, w.Body.String(), target)
+		require.Equal(t, etag, w.Header().Get("ETag"), target)
+
+		// 列表中任一 ETag 命中（含弱比较）即视为未修改
+		w = get(target, `"other", W/`+etag)
+		require.Equal(t, http.StatusNotModified, w.Code, target)
+
+		w = get(target, `"stale"`)
+		require.Equal(t, http.StatusOK, w.Code, target)
+		require.NotEmpty(t, w.Body.String(), target)
+	}
+
+	w := get("/api/fs/file?path=notes.txt", "")
+	etag := w.Header().Get("ETag")
+	require.NoError(t, os.WriteFile(filePath, []byte("v2-longer"), 0o644))
+	w = get("/api/fs/file?path=notes.txt", etag)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	var resp models.GetFSFileResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Equal(t, "v2-longer", resp.Content)
+}
+
+func TestFSHandler_DownloadFile_RangePartial(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "data.bin"), []byte("0123456789"), 0o644))
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitFSApi(group, root, 1024, 0, 0, 0)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/fs/download?path=data.bin", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusPartialContent, w.Code)
+	require.Equal(t, "2345", w.Body.String())
+	require.Equal(t, "bytes 2-5/10", w.Header().Get("Content-Range"))
+	require.Equal(t, "application/octet-stream", w.Header().Get("Content-Type"))
+
+	req = httptest.NewRequest(http.MethodGet, "/api/fs/download?path=data.bin", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+}
+
+func TestFSHandler_DownloadFile_OversizedRequiresBoundedRange(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	root := t.TempDir()
+	sourcePath := filepath.Join(root, "big.bin")
+	require.NoError(t, os.WriteFile(sourcePath, []byte("0123456789"), 0o644))
+	info, err := os.Stat(sourcePath)
+	require.NoError(t, err)
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitFSApi(group, root, 4, 0, 0, 0)
+
+	download := func(headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/fs/download?path=big.bin", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := download(map[string]string{"Range": "bytes=6-9"})
+	require.Equal(t, http.StatusPartialContent, w.Code)
+	require.Equal(t, "6789", w.Body.String())
+
+	w = download(map[string]string{"Range": "bytes=-3"})
+	require.Equal(t, http.StatusPartialContent, w.Code)
+	require.Equal(t, "789", w.Body.String())
+
+	w = download(map[string]string{
+		"Range":    "bytes=0-3",
+		"If-Range": info.ModTime().UTC().Format(http.TimeFormat),
+	})
+	require.Equal(t, http.StatusPartialContent, w.Code)
+	require.Equal(t, "0123", w.Body.String())
+
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	w = download(map[string]string{"Range": "bytes=4-7", "If-Range": etag})
+	require.Equal(t, http.StatusPartialContent, w.Code)
+	require.Equal(t, "4567", w.Body.String())
+
+	for _, headers := range []map[string]string{
+		nil,
+		{"Range": "bytes=2-"},
+		{"Range": "bytes=0-4"},
+		{"Range": "bytes=0-1,4-5"},
+		{"Range": "bytes=0-3", "If-Range": "Mon, 02 Jan 2006 15:04:05 GMT"},
+		{"Range": "bytes=0-3", "If-Range": `"stale"`},
+	} {
+		w := download(headers)
+		requireFSError(t, w, http.StatusRequestEntityTooLarge, response.CodeTooLarge, headers)
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"fmt"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// uploadTargetPathsField 为批量上传时与 file 字段一一对应的目标路径字段
+	uploadTargetPathsField = "target_file_path[]"
+	// uploadMaxFiles 为单次批量上传允许的最大文件数
+	uploadMaxFiles = 256
+)
+
+// uploadFSFiles 处理一次 multipart 请求中的多个文件：先整体校验数量、路径、单文件与总大小上限及工作区配额，
+// 全部通过后依次写入；单个文件写入失败记录在对应结果中，不影响其余文件
+func (h *FSHandler) uploadFSFiles(c *gin.Context, targets []string) {
+	var files []*multipart.FileHeader
+	if c.Request.MultipartForm != nil {
+		files = c.Request.MultipartForm.File["file"]
+	}
+	if len(targets) == 0 || len(targets) > uploadMaxFiles {
+		response.APIErrorResponse(c, response.CodeInvalidArgument,
+			fmt.Sprintf("%s must contain 1-%d entries", uploadTargetPathsField, uploadMaxFiles))
+		return
+	}
+	if len(files) != len(targets) {
+		response.APIErrorResponse(c, response.CodeInvalidArgument,
+			fmt.Sprintf("got %d file parts for %d %s entries", len(files), len(targets), uploadTargetPathsField))
+		return
+	}
+	mode, hasMode, err := parseFileMode(c.PostForm("mode"))
+	if err != nil {
+		response.APIErrorResponse(c, response.CodeInvalidArgument, err.Error())
+		return
+	}
+
+	resolved := make([]string, len(targets))
+	cleaned := make([]string, len(targets))
+	writes := make(map[string]int64, len(targets))
+	var totalBytes int64
+	for i, target := range targets {
+		if strings.TrimSpace(target) == "" {
+			response.APIErrorResponse(c, response.CodeInvalidArgument, fmt.Sprintf("%s entry %d is empty", uploadTargetPathsField, i))
+			return
+		}
+		if h.maxFileBytes > 0 && files[i].Size > h.maxFileBytes {
+			writeFileTooLarge(c, files[i].Size, h.maxFileBytes)
+			return
+		}
+		resolved[i], cleaned[i], err = resolveWorkspacePath(h.workspaceRoot, target)
+		if err != nil {
+			writePathError(c, err)
+			return
+		}
+		if _, dup := writes[resolved[i]]; dup {
+			response.APIErrorResponse(c, response.CodeInvalidArgument, "duplicate target path: "+filepath.ToSlash(cleaned[i]))
+			return
+		}
+		writes[resolved[i]] = files[i].Size
+		totalBytes += files[i].Size
+	}
+	if h.maxUploadTotalBytes > 0 && totalBytes > h.maxUploadTotalBytes {
+		response.APIErrorResponse(c, response.CodeTooLarge,
+			fmt.Sprintf("upload total size %d exceeds limit %d", totalBytes, h.maxUploadTotalBytes))
+		return
+	}
+	if !h.checkWorkspaceQuotaAll(c, writes) {
+		return
+	}
+
+	resp := models.UploadFSFilesResp{Files: make([]models.UploadFSFileResult, len(files))}
+	for i, header := range files {
+		result := models.UploadFSFileResult{UploadFSFileResp: models.UploadFSFileResp{
+			SourcePath: header.Filename,
+			TargetPath: filepath.ToSlash(cleaned[i]),
+		}}
+		size, resultMode, err := writeUploadedPart(resolved[i], header, mode, hasMode)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Size = size
+			result.Mode = formatPerm(resultMode)
+			resp.TotalSize += size
+		}
+		resp.Files[i] = result
+	}
+	response.SuccessResponse(c, resp)
+}
+
+// writeUploadedPart 打开 multipart 文件并写入 resolvedPath
+func writeUploadedPart(resolvedPath string, header *multipart.FileHeader, mode os.FileMode, hasMode bool) (int64, os.FileMode, error) {
+	src, err := header.Open()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer src.Close()
+	return writeUploadedFile(resolvedPath, src, mode, hasMode)
+}
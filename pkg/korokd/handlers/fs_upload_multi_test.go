@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type uploadPart struct {
+	name    string
+	content string
+}
+
+func doMultiUpload(t *testing.T, router *gin.Engine, parts []uploadPart, targets []string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, p := range parts {
+		part, err := writer.CreateFormFile("file", p.name)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(p.content))
+		require.NoError(t, err)
+	}
+	for _, target := range targets {
+		require.NoError(t, writer.WriteField(uploadTargetPathsField, target))
+	}
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/fs/upload", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func newMultiUploadRouter(root string, maxFileBytes, maxTotalBytes int64) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	InitFSApi(router.Group("/api"), root, maxFileBytes, 0, 0, maxTotalBytes)
+	return router
+}
+
+func TestFSHandler_UploadFiles_Multiple(t *testing.T) {
+	root := t.TempDir()
+	router := newMultiUploadRouter(root, 1024, 0)
+
+	w := doMultiUpload(t, router,
+		[]uploadPart{{"main.py", "print('hi')\n"}, {"util.py", "X = 1\n"}, {"README.md", "# demo\n"}},
+		[]string{"src/main.py", "src/pkg/util.py", "README.md"},
+	)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.UploadFSFilesResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Len(t, resp.Files, 3)
+	require.Equal(t, int64(12+6+7), resp.TotalSize)
+	require.Equal(t, "util.py", resp.Files[1].SourcePath)
+	require.Equal(t, "src/pkg/util.py", resp.Files[1].TargetPath)
+	for _, f := range resp.Files {
+		require.Empty(t, f.Error)
+		require.Equal(t, "0644", f.Mode)
+	}
+
+	for path, want := range map[string]string{
+		"src/main.py":     "print('hi')\n",
+		"src/pkg/util.py": "X = 1\n",
+		"README.md":       "# demo\n",
+	} {
+		data, err := os.ReadFile(filepath.Join(root, path))
+		require.NoError(t, err)
+		require.Equal(t, want, string(data))
+	}
+}
+
+func TestFSHandler_UploadFiles_RejectsBeforeWriting(t *testing.T) {
+	root := t.TempDir()
+	router := newMultiUploadRouter(root, 8, 10)
+
+	// 单文件未超限，但总大小超过上限
+	w := doMultiUpload(t, router, []uploadPart{{"a", "123456"}, {"b", "123456"}}, []string{"a.txt", "b.txt"})
+	requireFSError(t, w, http.StatusRequestEntityTooLarge, response.CodeTooLarge)
+	_, err := os.Stat(filepath.Join(root, "a.txt"))
+	require.True(t, os.IsNotExist(err))
+
+	w = doMultiUpload(t, router, []uploadPart{{"a", "123456789"}}, []string{"a.txt"})
+	requireFSError(t, w, http.StatusRequestEntityTooLarge, response.CodeTooLarge)
+
+	w = doMultiUpload(t, router, []uploadPart{{"a", "1"}, {"b", "2"}}, []string{"a.txt"})
+	requireFSError(t, w, http.StatusBadRequest, response.CodeInvalidArgument)
+
+	w = doMultiUpload(t, router, []uploadPart{{"a", "1"}, {"b", "2"}}, []string{"a.txt", "./a.txt"})
+	requireFSError(t, w, http.StatusBadRequest, response.CodeInvalidArgument)
+
+	w = doMultiUpload(t, router, []uploadPart{{"a", "1"}}, []string{"../escape.txt"})
+	require.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestFSHandler_UploadFiles_TotalLimitIndependentOfArchiveLimit(t *testing.T) {
+	root := t.TempDir()
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	InitFSApi(router.Group("/api"), root, 1024, 4, 0, 0)
+
+	w := doMultiUpload(t, router, []uploadPart{{name: "a.txt", content: "hello"}, {name: "b.txt", content: "world"}}, []string{"a.txt", "b.txt"})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.FileExists(t, filepath.Join(root, "b.txt"))
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
+	"github.com/gin-gonic/gin"
+)
+
+// workspaceUsage 描述工作区普通文件的占用情况
+type workspaceUsage struct {
+	bytes int64
+	files int
+}
+
+// workspaceUsageTTL 为配额校验重新遍历工作区的间隔；期间经 fs 接口写入的字节按增量计入缓存，
+// 沙箱内代码直接产生的文件变化到下次遍历时才计入
+const workspaceUsageTTL = 10 * time.Second
+
+// workspaceUsageCache 缓存工作区普通文件总大小，配额校验通过后立即计入本次写入的增量，
+// 并发写请求因此不会同时占用同一份剩余配额
+type workspaceUsageCache struct {
+	mu         sync.Mutex
+	bytes      int64
+	computedAt time.Time
+	valid      bool
+}
+
+// invalidate 使缓存失效，下次配额校验时重新遍历工作区
+func (u *workspaceUsageCache) invalidate() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.valid = false
+}
+
+// UsageFS 返回工作区内普通文件的总大小与数量，以及单文件大小上限和工作区配额
+func (h *FSHandler) UsageFS(c *gin.Context) {
+	usage, err := computeWorkspaceUsage(h.workspaceRoot)
+	if err != nil {
+		writeStatError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, models.FSUsageResp{
+		Root:              filepath.ToSlash(filepath.Clean(h.workspaceRoot)),
+		TotalBytes:        usage.bytes,
+		FileCount:         usage.files,
+		MaxFileBytes:      max(h.maxFileBytes, 0),
+		MaxWorkspaceBytes: max(h.maxWorkspaceBytes, 0),
+	})
+}
+
+// computeWorkspaceUsage 遍历工作区统计普通文件的大小与数量；符号链接不跟随也不计入，不可读的子目录直接跳过
+func computeWorkspaceUsage(root string) (workspaceUsage, error) {
+	var usage workspaceUsage
+	root = filepath.Clean(root)
+	err := filepath.WalkDir(root, func(curr string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			if curr != root && d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return walkErr
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			// 遍历期间被删除的文件不计入
+			return nil
+		}
+		usage.bytes += info.Size()
+		usage.files++
+		return nil
+	})
+	return usage, err
+}
+
+// checkWorkspaceQuota 在向 targetPath 写入 size 字节前校验工作区配额，覆盖已有文件时扣除其原大小；
+// 超出配额或统计失败时写入错误响应并返回 false
+func (h *FSHandler) checkWorkspaceQuota(c *gin.Context, targetPath string, size int64) bool {
+	return h.checkWorkspaceQuotaAll(c, map[string]int64{targetPath: size})
+}
+
+// checkWorkspaceQuotaAll 同 checkWorkspaceQuota，一次校验写入多个目标路径（key 为路径，value 为写入字节数）后的总用量；
+// 校验通过时把增量计入缓存，写入失败导致的偏差在缓存过期重新遍历时修正
+func (h *FSHandler) checkWorkspaceQuotaAll(c *gin.Context, writes map[string]int64) bool {
+	if h.maxWorkspaceBytes <= 0 {
+		return true
+	}
+
+	h.usage.mu.Lock()
+	defer h.usage.mu.Unlock()
+	if !h.usage.valid || time.Since(h.usage.computedAt) >= workspaceUsageTTL {
+		usage, err := computeWorkspaceUsage(h.workspaceRoot)
+		if err != nil {
+			writeInternalError(c, err)
+			return false
+		}
+		h.usage.bytes = usage.bytes
+		h.usage.computedAt = time.Now()
+		h.usage.valid = true
+	}
+
+	var delta int64
+	for targetPath, size := range writes {
+		delta += size
+		if info, err := os.Lstat(targetPath); err == nil && info.Mode().IsRegular() {
+			delta -= info.Size()
+		}
+	}
+	projected := h.usage.bytes + delta
+	if projected > h.maxWorkspaceBytes {
+		response.APIErrorResponse(c, response.CodeQuotaExceeded,
+			fmt.Sprintf("workspace usage %d would exceed quota %d", projected, h.maxWorkspaceBytes))
+		return false
+	}
+	h.usage.bytes = projected
+	return true
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newUsageRouter(t *testing.T, root string, maxWorkspaceBytes int64) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	InitFSApi(router.Group("/api"), root, 1024, 0, maxWorkspaceBytes, 0)
+	return router
+}
+
+func doWriteFile(t *testing.T, router *gin.Engine, path, content string) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(models.WriteFSFileReq{Path: path, Content: content})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/api/fs/file", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestFSHandler_Usage_CountsRegularFiles(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "src", "pkg"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".cache"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "empty"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "README.md"), []byte("hello"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src", "main.py"), []byte("print(1)\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src", "pkg", "data.bin"), make([]byte, 100), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".cache", "index"), []byte("abc"), 0o644))
+	require.NoError(t, os.Symlink(filepath.Join(root, "src", "pkg", "data.bin"), filepath.Join(root, "link.bin")))
+	require.NoError(t, os.Symlink(filepath.Join(root, "src"), filepath.Join(root, "src-link")))
+
+	router := newUsageRouter(t, root, 4096)
+	req := httptest.NewRequest(http.MethodGet, "/api/fs/usage", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.FSUsageResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Equal(t, filepath.ToSlash(root), resp.Root)
+	require.Equal(t, int64(5+9+100+3), resp.TotalBytes)
+	require.Equal(t, 4, resp.FileCount)
+	require.Equal(t, int64(1024), resp.MaxFileBytes)
+	require.Equal(t, int64(4096), resp.MaxWorkspaceBytes)
+}
+
+func TestFSHandler_WriteFile_WorkspaceQuota(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("0123456789"), 0o644))
+
+	router := newUsageRouter(t, root, 16)
+
+	require.Equal(t, http.StatusOK, doWriteFile(t, router, "b.txt", "012345").Code)
+	requireFSError(t, doWriteFile(t, router, "c.txt", "x"), http.StatusInsufficientStorage, response.CodeQuotaExceeded)
+	_, err := os.Stat(filepath.Join(root, "c.txt"))
+	require.True(t, os.IsNotExist(err))
+
+	// 覆盖写只计算大小差值
+	require.Equal(t, http.StatusOK, doWriteFile(t, router, "a.txt", "0123456789").Code)
+	requireFSError(t, doWriteFile(t, router, "a.txt", "0123456789A"), http.StatusInsufficientStorage, response.CodeQuotaExceeded)
+	require.Equal(t, http.StatusOK, doWriteFile(t, router, "a.txt", "short").Code)
+	require.Equal(t, http.StatusOK, doWriteFile(t, router, "c.txt", "x").Code)
+}
+
+func TestFSHandler_Copy_WorkspaceQuota(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "src", "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src", "a.txt"), []byte("01234"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src", "sub", "b.txt"), []byte("56789"), 0o644))
+
+	router := newUsageRouter(t, root, 16)
+
+	w := postFSJSON(t, router, "/api/fs/copy", models.CopyFSReq{Src: "src", Dst: "dst"})
+	requireFSError(t, w, http.StatusInsufficientStorage, response.CodeQuotaExceeded)
+	require.NoDirExists(t, filepath.Join(root, "dst"))
+
+	w = postFSJSON(t, router, "/api/fs/copy", models.CopyFSReq{Src: "src/a.txt", Dst: "a-copy.txt"})
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestFSHandler_Extract_WorkspaceQuota(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("0123456789"), 0o644))
+
+	router := newUsageRouter(t, root, 16)
+
+	archive := buildTestZip(t, []testArchiveEntry{
+		{name: "out/b.txt", content: "0123"},
+		{name: "out/c.txt", content: "4567"},
+	})
+	requireFSError(t, postExtract(t, router, "bundle.zip", archive, "."), http.StatusInsufficientStorage, response.CodeQuotaExceeded)
+	require.NoDirExists(t, filepath.Join(root, "out"))
+
+	archive = buildTestZip(t, []testArchiveEntry{{name: "out/b.txt", content: "0123"}})
+	require.Equal(t, http.StatusOK, postExtract(t, router, "bundle.zip", archive, ".").Code)
+}
+
+func TestFSHandler_Delete_ReleasesWorkspaceQuota(t *testing.T) {
+	root := t.TempDir()
+	router := newUsageRouter(t, root, 16)
+
+	require.Equal(t, http.StatusOK, doWriteFile(t, router, "a.txt", "0123456789").Code)
+	requireFSError(t, doWriteFile(t, router, "b.txt", "0123456789"), http.StatusInsufficientStorage, response.CodeQuotaExceeded)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/fs/file?path=a.txt", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.Equal(t, http.StatusOK, doWriteFile(t, router, "b.txt", "0123456789").Code)
+}
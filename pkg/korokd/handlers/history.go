@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/audit"
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+)
+
+const (
+	// contextHistorySize 为每个 context 默认保留的执行记录条数（可配置）
+	contextHistorySize = 100
+	// contextHistoryPreviewBytes 为执行记录中保留的代码前缀长度
+	contextHistoryPreviewBytes = 200
+)
+
+// executionHistory 为 context 最近若干次执行的环形缓冲区，零值可直接使用
+type executionHistory struct {
+	mu      sync.Mutex
+	entries []models.ExecutionHistoryEntry
+	// next 为缓冲区写满后下一条记录覆盖的位置
+	next int
+}
+
+// add 追加一条记录，超过 size 条时覆盖最旧的记录
+func (h *executionHistory) add(entry models.ExecutionHistoryEntry, size int) {
+	if size <= 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.entries) < size {
+		h.entries = append(h.entries, entry)
+		return
+	}
+	h.entries[h.next] = entry
+	h.next = (h.next + 1) % len(h.entries)
+}
+
+// snapshot 按执行先后顺序返回记录副本
+func (h *executionHistory) snapshot() []models.ExecutionHistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	out := make([]models.ExecutionHistoryEntry, 0, len(h.entries))
+	out = append(out, h.entries[h.next:]...)
+	out = append(out, h.entries[:h.next]...)
+	return out
+}
+
+func (h *executionHistory) reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = nil
+	h.next = 0
+}
+
+// newHistoryEntry 根据执行结果生成记录，只保留代码哈希与截断后的前缀
+func newHistoryEntry(code string, startedAt time.Time, resp *models.ExecuteContextResp, err error) models.ExecutionHistoryEntry {
+	entry := models.ExecutionHistoryEntry{
+		CodeHash:    audit.HashCode(code),
+		CodePreview: codePreview(code, contextHistoryPreviewBytes),
+		StartedAt:   startedAt.UTC().Format(time.RFC3339),
+		DurationMs:  time.Since(startedAt).Milliseconds(),
+	}
+	if resp != nil {
+		entry.ExecutionCount = resp.ExecutionCount
+		entry.ExitCode = resp.ExitCode
+		entry.DurationMs = resp.DurationMs
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	return entry
+}
+
+func codePreview(code string, maxBytes int) string {
+	if len(code) <= maxBytes {
+		return code
+	}
+	// 避免截断在多字节字符中间
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(code[cut]) {
+		cut--
+	}
+	return code[:cut]
+}
+
+// history 返回 context 自上次 reset 以来的执行记录
+func (m *contextManager) history(contextID string) ([]models.ExecutionHistoryEntry, error) {
+	kctx := m.get(contextID)
+	if kctx == nil {
+		return nil, m.notFound(contextID)
+	}
+	return kctx.history.snapshot(), nil
+}
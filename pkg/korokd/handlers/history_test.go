@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/audit"
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutionHistory_KeepsMostRecentEntries(t *testing.T) {
+	var h executionHistory
+	for i := 1; i <= 5; i++ {
+		h.add(models.ExecutionHistoryEntry{ExecutionCount: int64(i)}, 3)
+	}
+
+	counts := make([]int64, 0, 3)
+	for _, entry := range h.snapshot() {
+		counts = append(counts, entry.ExecutionCount)
+	}
+	require.Equal(t, []int64{3, 4, 5}, counts)
+
+	h.reset()
+	require.Empty(t, h.snapshot())
+}
+
+func TestNewHistoryEntry_StoresPreviewNotFullCode(t *testing.T) {
+	code := strings.Repeat("a", contextHistoryPreviewBytes-1) + "中文"
+	entry := newHistoryEntry(code, time.Now(), &models.ExecuteContextResp{ExecutionCount: 2, ExitCode: 1, DurationMs: 7}, nil)
+
+	require.Equal(t, audit.HashCode(code), entry.CodeHash)
+	require.Equal(t, strings.Repeat("a", contextHistoryPreviewBytes-1), entry.CodePreview)
+	require.Equal(t, int64(2), entry.ExecutionCount)
+	require.Equal(t, int32(1), entry.ExitCode)
+	require.Equal(t, int64(7), entry.DurationMs)
+
+	entry = newHistoryEntry("x", time.Now(), nil, fmt.Errorf("kernel execute failed"))
+	require.Equal(t, "kernel execute failed", entry.Error)
+}
+
+func TestGetContextHistory_RecordsExecutionsAndClearsOnReset(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		if strings.Contains(code, "raise") {
+			return fakeKernelReply{Status: "error"}
+		}
+		return fakeKernelReply{Stdout: "ok\n"}
+	})
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-1", contextLanguagePython)
+
+	_, err := m.executeWithHooks(t.Context(), "ctx-1", "x = 1", 0, executeOptions{}, nil)
+	require.NoError(t, err)
+	_, err = m.executeWithHooks(t.Context(), "ctx-1", "raise ValueError()", 0, executeOptions{}, nil)
+	require.NoError(t, err)
+
+	router := gin.New()
+	h := &CodeInterpreterHandler{contexts: m}
+	router.GET("/contexts/:contextId/history", h.GetContextHistory)
+	router.POST("/contexts/:contextId/reset", h.ResetContext)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/contexts/ctx-1/history", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp models.ContextHistoryResp
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Equal(t, "ctx-1", resp.ContextID)
+	require.Len(t, resp.Entries, 2)
+	require.Equal(t, audit.HashCode("x = 1"), resp.Entries[0].CodeHash)
+	require.Equal(t, int32(0), resp.Entries[0].ExitCode)
+	require.Equal(t, "raise ValueError()", resp.Entries[1].CodePreview)
+	require.Equal(t, int32(1), resp.Entries[1].ExitCode)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/contexts/ctx-1/reset", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/contexts/ctx-1/history", nil))
+	resp = models.ContextHistoryResp{}
+	decodeFSSuccessData(t, w.Body.Bytes(), &resp)
+	require.Empty(t, resp.Entries)
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+)
+
+const (
+	contextInstallDefaultTimeoutMs = 120000
+	contextInstallMaxPackages      = 32
+	// pip 被超时终止后，等待其子进程释放输出管道的最长时间
+	contextInstallWaitDelay = 2 * time.Second
+)
+
+var (
+	errInvalidPackages = fmt.Errorf("invalid packages")
+	// 只接受 PEP 508 的子集：名称、可选 extras 与版本约束，不允许以 - 开头，避免被 pip 当作选项解析
+	packageRequirementPattern = regexp.MustCompile(
+		`^([A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?)` +
+			`(?:\[[A-Za-z0-9._-]+(?:,[A-Za-z0-9._-]+)*\])?` +
+			`(?:(?:==|!=|<=|>=|~=|<|>)[A-Za-z0-9.*+!_-]+(?:,(?:==|!=|<=|>=|~=|<|>)[A-Za-z0-9.*+!_-]+)*)?$`,
+	)
+	defaultPipCommand = []string{"python3", "-m", "pip"}
+)
+
+// installResult 为一次 pip install 的结果
+type installResult struct {
+	ExitCode   int32
+	DurationMs int64
+	Packages   []models.InstalledPackage
+}
+
+// validatePackages 校验待安装的包并返回对应的分发包名称（用于安装后查询版本）
+func validatePackages(packages []string) ([]string, error) {
+	if len(packages) == 0 {
+		return nil, fmt.Errorf("%w: packages is required", errInvalidPackages)
+	}
+	if len(packages) > contextInstallMaxPackages {
+		return nil, fmt.Errorf("%w: at most %d packages per request", errInvalidPackages, contextInstallMaxPackages)
+	}
+	names := make([]string, 0, len(packages))
+	for _, pkg := range packages {
+		match := packageRequirementPattern.FindStringSubmatch(pkg)
+		if match == nil {
+			return nil, fmt.Errorf("%w: %q", errInvalidPackages, pkg)
+		}
+		names = append(names, match[1])
+	}
+	return names, nil
+}
+
+// installPackages 在 context 的 cwd 中执行 pip install，输出通过 hooks 实时回传
+// 安装期间占用 context 的 busy 位，与代码执行互斥
+func (m *contextManager) installPackages(
+	ctx context.Context,
+	contextID string,
+	packages []string,
+	timeoutMs int,
+	hooks *executeStreamHooks,
+) (*installResult, error) {
+	kctx := m.get(contextID)
+	if kctx == nil {
+		return nil, m.notFound(contextID)
+	}
+	if kctx.Language != contextLanguagePython {
+		return nil, fmt.Errorf("%w: install requires a python context", errUnsupportedLanguage)
+	}
+	names, err := validatePackages(packages)
+	if err != nil {
+		return nil, err
+	}
+	if timeoutMs == 0 {
+		timeoutMs = contextInstallDefaultTimeoutMs
+	}
+	if timeoutMs < contextMinTimeoutMs || timeoutMs > contextMaxTimeoutMs {
+		return nil, fmt.Errorf("%w: timeout_ms must be between 100 and 300000", errInvalidTimeoutMS)
+	}
+
+	if !kctx.busy.CompareAndSwap(false, true) {
+		return nil, errContextBusy
+	}
+	defer kctx.busy.Store(false)
+
+	start := time.Now()
+	installCtx, cancel := context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
+	defer cancel()
+
+	args := append([]string{"install", "--disable-pip-version-check", "--no-input"}, packages...)
+	cmd := m.pipCmd(installCtx, kctx, args...)
+	if hooks != nil {
+		cmd.Stdout = streamWriter(hooks.OnStdout)
+		cmd.Stderr = streamWriter(hooks.OnStderr)
+	}
+	runErr := cmd.Run()
+	kctx.lastActiveUnix.Store(time.Now().UnixNano())
+
+	result := &installResult{}
+	var exitErr *exec.ExitError
+	switch {
+	case installCtx.Err() == context.DeadlineExceeded:
+		result.ExitCode = 124
+	case errors.As(runErr, &exitErr):
+		result.ExitCode = int32(exitErr.ExitCode())
+	case runErr != nil:
+		return nil, fmt.Errorf("run pip failed: %w", runErr)
+	}
+	if result.ExitCode == 0 {
+		result.Packages = m.installedVersions(ctx, kctx, names)
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// installedVersions 通过 pip show 查询安装后的版本，查询失败时返回空列表
+func (m *contextManager) installedVersions(ctx context.Context, kctx *kernelContext, names []string) []models.InstalledPackage {
+	showCtx, cancel := context.WithTimeout(ctx, contextCreateTimeout)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	cmd := m.pipCmd(showCtx, kctx, append([]string{"show", "--disable-pip-version-check"}, names...)...)
+	cmd.Stdout = &stdout
+	// pip show 对未找到的包返回非零，但仍会输出其余包的信息
+	_ = cmd.Run()
+	return parsePipShow(stdout.String())
+}
+
+func (m *contextManager) pipCmd(ctx context.Context, kctx *kernelContext, args ...string) *exec.Cmd {
+	pip := m.pipCommand
+	if len(pip) == 0 {
+		pip = defaultPipCommand
+	}
+	cmd := exec.CommandContext(ctx, pip[0], append(append([]string(nil), pip[1:]...), args...)...)
+	cmd.Dir = kctx.initCWD()
+	cmd.Env = os.Environ()
+	for key, value := range kctx.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	cmd.WaitDelay = contextInstallWaitDelay
+	return cmd
+}
+
+// parsePipShow 解析 pip show 输出中的 Name/Version 字段
+func parsePipShow(out string) []models.InstalledPackage {
+	packages := make([]models.InstalledPackage, 0)
+	var cur models.InstalledPackage
+	flush := func() {
+		if cur.Name != "" {
+			packages = append(packages, cur)
+		}
+		cur = models.InstalledPackage{}
+	}
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "---":
+			flush()
+		case strings.HasPrefix(line, "Name:"):
+			cur.Name = strings.TrimSpace(strings.TrimPrefix(line, "Name:"))
+		case strings.HasPrefix(line, "Version:"):
+			cur.Version = strings.TrimSpace(strings.TrimPrefix(line, "Version:"))
+		}
+	}
+	flush()
+	return packages
+}
+
+// streamWriter 将进程输出逐块转发给流式 hook
+type streamWriter func(text string)
+
+func (w streamWriter) Write(p []byte) (int, error) {
+	if w != nil {
+		w(string(p))
+	}
+	return len(p), nil
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePip 模拟 pip：install 回显参数与 FOO 环境变量并以 FAKE_PIP_EXIT 退出，show 输出固定版本
+const fakePip = `case "$1" in
+install)
+  shift
+  pwd
+  echo "env:$FOO"
+  for a in "$@"; do echo "arg:$a"; done
+  echo "deprecated" >&2
+  exit "${FAKE_PIP_EXIT:-0}"
+  ;;
+show)
+  shift 2
+  for n in "$@"; do echo "Name: $n"; echo "Version: 1.2.3"; echo "---"; done
+  ;;
+esac
+`
+
+func newInstallTestContext(t *testing.T, env map[string]string) (*contextManager, *kernelContext) {
+	t.Helper()
+
+	fj := newFakeJupyter(t, nil)
+	m := newTestContextManager(t, fj)
+	script := filepath.Join(t.TempDir(), "pip.sh")
+	require.NoError(t, os.WriteFile(script, []byte(fakePip), 0o600))
+	m.pipCommand = []string{"/bin/sh", script}
+
+	kctx := addTestContext(m, "ctx-pip", contextLanguagePython)
+	kctx.CWD = t.TempDir()
+	kctx.Env = env
+	return m, kctx
+}
+
+func installViaHandler(t *testing.T, m *contextManager, contextID, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.ReleaseMode)
+
+	router := gin.New()
+	h := &CodeInterpreterHandler{contexts: m}
+	router.POST("/contexts/:contextId/install", h.InstallPackages)
+
+	req := httptest.NewRequest(http.MethodPost, "/contexts/"+contextID+"/install", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestInstallPackages_StreamsOutputAndReportsVersions(t *testing.T) {
+	m, kctx := newInstallTestContext(t, map[string]string{"FOO": "bar"})
+
+	w := installViaHandler(t, m, "ctx-pip", `{"packages":["requests==2.32.3","pandas[excel]>=2.0"]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	events := decodeSSEEvents(t, w.Body.String())
+
+	var stdout, stderr strings.Builder
+	var complete *models.ExecuteStreamEvent
+	for i := range events {
+		switch events[i].Type {
+		case "stdout":
+			stdout.WriteString(events[i].Text)
+		case "stderr":
+			stderr.WriteString(events[i].Text)
+		case "install_complete":
+			complete = &events[i]
+		}
+	}
+	require.Contains(t, stdout.String(), kctx.CWD+"\n")
+	require.Contains(t, stdout.String(), "env:bar\n")
+	require.Contains(t, stdout.String(), "arg:--no-input\narg:requests==2.32.3\narg:pandas[excel]>=2.0\n")
+	require.Equal(t, "deprecated\n", stderr.String())
+
+	require.NotNil(t, complete)
+	require.Equal(t, int32(0), complete.ExitCode)
+	require.Equal(t, []models.InstalledPackage{
+		{Name: "requests", Version: "1.2.3"},
+		{Name: "pandas", Version: "1.2.3"},
+	}, complete.Packages)
+	require.False(t, kctx.busy.Load())
+}
+
+func TestInstallPackages_SurfacesPipExitCode(t *testing.T) {
+	m, _ := newInstallTestContext(t, map[string]string{"FAKE_PIP_EXIT": "1"})
+
+	result, err := m.installPackages(t.Context(), "ctx-pip", []string{"no-such-package"}, 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), result.ExitCode)
+	require.Empty(t, result.Packages)
+}
+
+func TestInstallPackages_RejectsUnsafeNames(t *testing.T) {
+	m, _ := newInstallTestContext(t, nil)
+
+	for _, body := range []string{
+		`{"packages":[]}`,
+		`{"packages":["--index-url=http://evil"]}`,
+		`{"packages":["requests; rm -rf /"]}`,
+		`{"packages":["git+https://example.com/x.git"]}`,
+		`{"packages":["requests"],"timeout_ms":10}`,
+	} {
+		w := installViaHandler(t, m, "ctx-pip", body)
+		require.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func TestInstallPackages_RequiresPythonContext(t *testing.T) {
+	m, _ := newInstallTestContext(t, nil)
+	addTestContext(m, "ctx-bash", contextLanguageBash)
+
+	_, err := m.installPackages(t.Context(), "ctx-bash", []string{"requests"}, 0, nil)
+	require.ErrorIs(t, err, errUnsupportedLanguage)
+}
+
+func TestParsePipShow(t *testing.T) {
+	out := "Name: requests\nVersion: 2.32.3\nSummary: HTTP\n---\nName: numpy\nVersion: 2.1.0\n"
+	require.Equal(t, []models.InstalledPackage{
+		{Name: "requests", Version: "2.32.3"},
+		{Name: "numpy", Version: "2.1.0"},
+	}, parsePipShow(out))
+}
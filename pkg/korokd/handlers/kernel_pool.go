@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// contextMaxParallelism 为单个 context 允许创建的 kernel 数上限
+const contextMaxParallelism = 4
+
+var errInvalidParallelism = fmt.Errorf("invalid parallelism")
+
+// poolKernel 为并行模式下主 kernel 之外的额外 kernel，各自持有独立的 busy 位
+// 额外 kernel 与主 kernel 使用相同的 cwd/env/limits 初始化，但命名空间彼此独立，
+// kernel 之间不会复制或同步命名空间快照，在某个 kernel 中定义的状态对其它 kernel 不可见
+type poolKernel struct {
+	SessionID string
+	KernelID  string
+	busy      atomic.Bool
+}
+
+// validateParallelism 校验创建 context 时请求的并行度，0 与 1 均表示串行
+func validateParallelism(parallelism int, language string) error {
+	if parallelism < 0 || parallelism > contextMaxParallelism {
+		return fmt.Errorf("%w: parallelism must be between 1 and %d", errInvalidParallelism, contextMaxParallelism)
+	}
+	if parallelism > 1 && language != contextLanguagePython {
+		return fmt.Errorf("%w: %w: parallelism requires a python context", errInvalidParallelism, errUnsupportedLanguage)
+	}
+	return nil
+}
+
+// parallelism 返回 context 持有的 kernel 总数
+func (k *kernelContext) parallelism() int {
+	return len(k.pool) + 1
+}
+
+// kernelIDs 返回主 kernel 与额外 kernel 的 ID，主 kernel 在首位
+func (k *kernelContext) kernelIDs() []string {
+	ids := make([]string, 0, k.parallelism())
+	ids = append(ids, k.KernelID)
+	for _, pk := range k.pool {
+		ids = append(ids, pk.KernelID)
+	}
+	return ids
+}
+
+// slotBusy 返回第 i 个 kernel 的 busy 位，0 为主 kernel
+func (k *kernelContext) slotBusy(i int) *atomic.Bool {
+	if i == 0 {
+		return &k.busy
+	}
+	return &k.pool[i-1].busy
+}
+
+// anyBusy 报告是否有任一 kernel 正在执行
+func (k *kernelContext) anyBusy() bool {
+	for i := 0; i < k.parallelism(); i++ {
+		if k.slotBusy(i).Load() {
+			return true
+		}
+	}
+	return false
+}
+
+// acquireKernel 占用一个空闲 kernel，返回其 ID 与释放函数
+// parallel 为 false 时只使用主 kernel，保持原有的串行语义；
+// 否则从轮转位置开始依次尝试各 kernel，全部忙碌时返回 false
+func (k *kernelContext) acquireKernel(parallel bool) (string, func(), bool) {
+	n := 1
+	start := 0
+	if parallel {
+		n = k.parallelism()
+		start = int(k.nextKernel.Add(1)-1) % n
+	}
+	for i := 0; i < n; i++ {
+		slot := (start + i) % n
+		busy := k.slotBusy(slot)
+		if busy.CompareAndSwap(false, true) {
+			return k.kernelIDs()[slot], func() { busy.Store(false) }, true
+		}
+	}
+	return "", nil, false
+}
+
+// acquireAllKernels 占用全部 kernel，任一忙碌时释放已占用的部分并返回 false
+func (k *kernelContext) acquireAllKernels() (func(), bool) {
+	for i := 0; i < k.parallelism(); i++ {
+		if !k.slotBusy(i).CompareAndSwap(false, true) {
+			for j := 0; j < i; j++ {
+				k.slotBusy(j).Store(false)
+			}
+			return nil, false
+		}
+	}
+	return func() {
+		for i := 0; i < k.parallelism(); i++ {
+			k.slotBusy(i).Store(false)
+		}
+	}, true
+}
+
+// createPoolKernels 为并行 context 创建额外的 Jupyter session，失败时回收已创建的 session
+// 新 kernel 以空命名空间启动，不继承主 kernel 已有的变量与导入
+func (m *contextManager) createPoolKernels(ctx context.Context, contextID, cwd, kernelName string, count int) ([]*poolKernel, error) {
+	pool := make([]*poolKernel, 0, count)
+	for i := 1; i <= count; i++ {
+		name := contextID + "-" + strconv.Itoa(i)
+		notebookPath, err := notebookPathForCWD(m.workspaceRoot, name, cwd)
+		if err != nil {
+			m.deletePoolSessions(pool)
+			return nil, err
+		}
+		sess, err := m.jupyter.CreateSession(ctx, name, notebookPath, kernelName)
+		if err != nil {
+			m.deletePoolSessions(pool)
+			return nil, fmt.Errorf("create jupyter session failed: %w", err)
+		}
+		kernelID := strings.TrimSpace(sess.Kernel.ID)
+		if kernelID == "" {
+			m.deletePoolSessions(pool)
+			return nil, fmt.Errorf("jupyter session created but kernel id is empty")
+		}
+		sessionID := strings.TrimSpace(sess.ID)
+		if sessionID == "" {
+			sessionID = name
+		}
+		pool = append(pool, &poolKernel{SessionID: sessionID, KernelID: kernelID})
+	}
+	return pool, nil
+}
+
+// deletePoolSessions 尽力回收额外 kernel 对应的 session
+func (m *contextManager) deletePoolSessions(pool []*poolKernel) {
+	if m.jupyter == nil || len(pool) == 0 {
+		return
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, pk := range pool {
+		_ = m.jupyter.DeleteSession(shutdownCtx, pk.SessionID)
+	}
+}
+
+// interruptBusyKernels 向所有正在执行的 kernel 发送中断
+func (m *contextManager) interruptBusyKernels(ctx context.Context, kctx *kernelContext) error {
+	ids := kctx.kernelIDs()
+	for i, id := range ids {
+		if !kctx.slotBusy(i).Load() {
+			continue
+		}
+		if err := m.jupyter.InterruptKernel(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
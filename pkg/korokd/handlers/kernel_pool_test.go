@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	"github.com/Fl0rencess720/agentland/pkg/korokd/pkgs/jupyter"
+	"github.com/stretchr/testify/require"
+)
+
+// newParallelTestContext 通过 create 流程创建一个 parallelism 个 kernel 的 python context
+func newParallelTestContext(t *testing.T, fj *fakeJupyter, parallelism int) (*contextManager, *kernelContext) {
+	t.Helper()
+
+	jc, err := jupyter.NewClient(fj.server.URL, "")
+	require.NoError(t, err)
+	m, err := newContextManagerWithClient(ContextManagerConfig{WorkspaceRoot: t.TempDir()}, t.TempDir(), jc)
+	require.NoError(t, err)
+	kctx, err := m.create(models.CreateContextReq{Language: contextLanguagePython, Parallelism: parallelism})
+	require.NoError(t, err)
+	return m, kctx
+}
+
+func TestValidateParallelism(t *testing.T) {
+	require.NoError(t, validateParallelism(0, contextLanguageBash))
+	require.NoError(t, validateParallelism(1, contextLanguageNode))
+	require.NoError(t, validateParallelism(contextMaxParallelism, contextLanguagePython))
+	require.ErrorIs(t, validateParallelism(-1, contextLanguagePython), errInvalidParallelism)
+	require.ErrorIs(t, validateParallelism(contextMaxParallelism+1, contextLanguagePython), errInvalidParallelism)
+	require.ErrorIs(t, validateParallelism(2, contextLanguageBash), errUnsupportedLanguage)
+}
+
+func TestExecute_ParallelContextRunsConcurrently(t *testing.T) {
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		started <- struct{}{}
+		<-release
+		return fakeKernelReply{Stdout: "ok\n"}
+	})
+	m, kctx := newParallelTestContext(t, fj, 2)
+	require.Equal(t, 2, kctx.info().Parallelism)
+
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := m.executeWithHooks(context.Background(), kctx.ID, "x = 1", 0, executeOptions{Parallel: true}, nil)
+			errs <- err
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Fatal("parallel executions did not run concurrently")
+		}
+	}
+
+	// 两个 kernel 都在执行时，新的执行无论是否 opt in 都返回 busy
+	_, err := m.executeWithHooks(context.Background(), kctx.ID, "x = 2", 0, executeOptions{Parallel: true}, nil)
+	require.ErrorIs(t, err, errContextBusy)
+	_, err = m.executeWithHooks(context.Background(), kctx.ID, "x = 2", 0, executeOptions{}, nil)
+	require.ErrorIs(t, err, errContextBusy)
+	require.True(t, kctx.info().Busy)
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, <-errs)
+	}
+	require.False(t, kctx.anyBusy())
+}
+
+func TestExecute_SerialByDefaultInParallelContext(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		started <- struct{}{}
+		<-release
+		return fakeKernelReply{}
+	})
+	m, kctx := newParallelTestContext(t, fj, 2)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := m.executeWithHooks(context.Background(), kctx.ID, "x = 1", 0, executeOptions{}, nil)
+		done <- err
+	}()
+	<-started
+
+	// 未 opt in 的执行只使用主 kernel
+	_, err := m.executeWithHooks(context.Background(), kctx.ID, "x = 2", 0, executeOptions{}, nil)
+	require.ErrorIs(t, err, errContextBusy)
+
+	close(release)
+	require.NoError(t, <-done)
+}
+
+func TestParallelContext_ResetAndRemoveCoverAllKernels(t *testing.T) {
+	fj := newFakeJupyter(t, nil)
+	m, kctx := newParallelTestContext(t, fj, 3)
+	ids := kctx.kernelIDs()
+	require.Len(t, ids, 3)
+
+	_, err := m.reset(context.Background(), kctx.ID, false)
+	require.NoError(t, err)
+	require.ElementsMatch(t, ids, fj.restartedKernels())
+
+	require.NoError(t, m.removeContext(kctx.ID, false))
+	fj.mu.Lock()
+	deleted := append([]string(nil), fj.deletedSessions...)
+	fj.mu.Unlock()
+	require.ElementsMatch(t, []string{kctx.ID, kctx.ID + "-1", kctx.ID + "-2"}, deleted)
+}
+
+func TestCreateContext_InvalidParallelism(t *testing.T) {
+	fj := newFakeJupyter(t, nil)
+	m := newTestContextManager(t, fj)
+
+	_, err := m.create(models.CreateContextReq{Language: contextLanguageBash, Parallelism: 2})
+	require.ErrorIs(t, err, errInvalidParallelism)
+	_, err = m.create(models.CreateContextReq{Language: contextLanguagePython, Parallelism: contextMaxParallelism + 1})
+	require.ErrorIs(t, err, errInvalidParallelism)
+	require.Empty(t, m.list())
+}
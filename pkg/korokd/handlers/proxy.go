@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/http"
@@ -15,8 +16,13 @@ import (
 
 type ProxyOptions struct {
 	Transport http.RoundTripper
+	// ReadyTimeout 为代理前等待目标端口可连接的最长时间，<=0 时不检查直接代理
+	ReadyTimeout time.Duration
 }
 
+// proxyReadyPollInterval 为等待目标端口就绪时两次拨号之间的间隔
+const proxyReadyPollInterval = 100 * time.Millisecond
+
 type ProxyHandler struct {
 	opts ProxyOptions
 }
@@ -50,6 +56,15 @@ func (h *ProxyHandler) ProxyByPort(c *gin.Context) {
 		Host:   net.JoinHostPort("127.0.0.1", strconv.Itoa(port)),
 	}
 
+	if !h.waitPortReady(c.Request.Context(), target.Host) {
+		c.Header("Retry-After", strconv.Itoa(retryAfterSeconds(h.opts.ReadyTimeout)))
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": fmt.Sprintf("service not ready on port %d", port),
+			"port":  port,
+		})
+		return
+	}
+
 	upstreamPath := c.Param("path")
 	if upstreamPath == "" {
 		upstreamPath = "/"
@@ -89,6 +104,39 @@ func (h *ProxyHandler) ProxyByPort(c *gin.Context) {
 	proxy.ServeHTTP(closeNotifySafeWriter{ResponseWriter: c.Writer}, c.Request)
 }
 
+// waitPortReady 在 ReadyTimeout 内反复拨号目标地址，可连接时返回 true；未开启检查时直接返回 true
+func (h *ProxyHandler) waitPortReady(ctx context.Context, addr string) bool {
+	if h.opts.ReadyTimeout <= 0 {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.opts.ReadyTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	for {
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err == nil {
+			_ = conn.Close()
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(proxyReadyPollInterval):
+		}
+	}
+}
+
+// retryAfterSeconds 将就绪等待时长换算为 Retry-After 秒数，至少为 1
+func retryAfterSeconds(d time.Duration) int {
+	seconds := int((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
 func (h *ProxyHandler) validatePort(port int) error {
 	if port < 1 || port > 65535 {
 		return fmt.Errorf("port must be between 1 and 65535")
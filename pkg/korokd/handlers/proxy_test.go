@@ -2,11 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
@@ -83,6 +87,55 @@ func TestProxyByPort_InvalidScheme(t *testing.T) {
 	require.Equal(t, http.StatusBadRequest, w.Code)
 }
 
+func TestProxyByPort_NotListeningReturns503(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	// 占用后立即释放一个端口，确保此时无人监听
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := lis.Addr().(*net.TCPAddr).Port
+	require.NoError(t, lis.Close())
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitProxyApi(group, ProxyOptions{
+		Transport:    &captureRoundTripper{statusCode: http.StatusOK},
+		ReadyTimeout: 300 * time.Millisecond,
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/proxy/by-port/"+strconv.Itoa(port), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, fmt.Sprintf("service not ready on port %d", port), resp["error"])
+	require.Equal(t, float64(port), resp["port"])
+}
+
+func TestProxyByPort_ReadyPortIsProxied(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = lis.Close() })
+	port := lis.Addr().(*net.TCPAddr).Port
+
+	router := gin.New()
+	group := router.Group("/api")
+	InitProxyApi(group, ProxyOptions{
+		Transport:    &captureRoundTripper{statusCode: http.StatusOK},
+		ReadyTimeout: time.Second,
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/proxy/by-port/"+strconv.Itoa(port)+"/health", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
 type captureRoundTripper struct {
 	statusCode int
 }
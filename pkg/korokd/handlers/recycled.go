@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+)
+
+// context 被自动回收的原因
+const (
+	recycleReasonTimeout = "timeout"
+	recycleReasonIdle    = "idle"
+)
+
+const (
+	// contextRecycledTTL 为回收原因的保留时长，过期后再访问只返回普通的 not found
+	contextRecycledTTL = 10 * time.Minute
+	// contextRecycledMax 为最多保留的回收记录数，超出时丢弃最早的记录
+	contextRecycledMax = 256
+)
+
+// recycledContext 记录一个被自动回收的 context 及原因
+type recycledContext struct {
+	reason string
+	detail string
+	at     time.Time
+}
+
+// recordRecycle 在自动回收 context 前记录原因，供后续请求解释 context 为何消失
+func (m *contextManager) recordRecycle(contextID, reason, detail string) {
+	now := time.Now()
+	m.recycledMu.Lock()
+	defer m.recycledMu.Unlock()
+	if m.recycled == nil {
+		m.recycled = make(map[string]recycledContext)
+	}
+	m.pruneRecycledLocked(now)
+	if _, exists := m.recycled[contextID]; !exists && len(m.recycled) >= contextRecycledMax {
+		oldestID := ""
+		for id, rc := range m.recycled {
+			if oldestID == "" || rc.at.Before(m.recycled[oldestID].at) {
+				oldestID = id
+			}
+		}
+		delete(m.recycled, oldestID)
+	}
+	m.recycled[contextID] = recycledContext{reason: reason, detail: detail, at: now}
+}
+
+// pruneRecycledLocked 清理过期的回收记录，调用方需持有 recycledMu
+func (m *contextManager) pruneRecycledLocked(now time.Time) {
+	for id, rc := range m.recycled {
+		if now.Sub(rc.at) > contextRecycledTTL {
+			delete(m.recycled, id)
+		}
+	}
+}
+
+// listRecycled 返回仍在保留期内的回收记录，按回收时间升序
+func (m *contextManager) listRecycled() []models.RecycledContextInfo {
+	m.recycledMu.Lock()
+	m.pruneRecycledLocked(time.Now())
+	out := make([]models.RecycledContextInfo, 0, len(m.recycled))
+	for id, rc := range m.recycled {
+		out = append(out, models.RecycledContextInfo{
+			ContextID:  id,
+			Reason:     rc.reason,
+			Detail:     rc.detail,
+			RecycledAt: rc.at.UTC().Format(time.RFC3339),
+		})
+	}
+	m.recycledMu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].RecycledAt == out[j].RecycledAt {
+			return out[i].ContextID < out[j].ContextID
+		}
+		return out[i].RecycledAt < out[j].RecycledAt
+	})
+	return out
+}
+
+// notFound 返回 context 不存在的错误；近期被自动回收时附带回收原因，仍可用 errors.Is 判断 errContextNotFound
+func (m *contextManager) notFound(contextID string) error {
+	m.recycledMu.Lock()
+	rc, ok := m.recycled[contextID]
+	m.recycledMu.Unlock()
+	if !ok || time.Since(rc.at) > contextRecycledTTL {
+		return errContextNotFound
+	}
+	return fmt.Errorf("%w: recycled (%s) at %s: %s", errContextNotFound, rc.reason, rc.at.UTC().Format(time.RFC3339), rc.detail)
+}
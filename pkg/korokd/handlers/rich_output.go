@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+	"github.com/Fl0rencess720/agentland/pkg/korokd/pkgs/jupyter"
+)
+
+// richOutputsFromBundle 将 Jupyter MIME bundle 转为统一 base64 编码的富输出列表
+// - 图片类（svg 除外）在 Jupyter 协议中本身即为 base64 字符串，直接透传
+// - 其余字符串值按原始字节编码；非字符串值（如 application/json）编码其 JSON 文本
+// - 单条解码后超过 maxBytes 时丢弃内容并标记 truncated，maxBytes<=0 表示不限制
+func richOutputsFromBundle(bundle jupyter.MimeBundle, maxBytes int64) []models.RichOutput {
+	mimeTypes := make([]string, 0, len(bundle))
+	for mimeType := range bundle {
+		mimeTypes = append(mimeTypes, mimeType)
+	}
+	sort.Strings(mimeTypes)
+
+	outputs := make([]models.RichOutput, 0, len(mimeTypes))
+	for _, mimeType := range mimeTypes {
+		raw := bundle[mimeType]
+
+		var data string
+		var size int64
+		var text string
+		if err := json.Unmarshal(raw, &text); err == nil {
+			if isBase64MimeType(mimeType) {
+				data = strings.TrimSpace(text)
+				size = int64(base64.StdEncoding.DecodedLen(len(data)))
+			} else {
+				data = base64.StdEncoding.EncodeToString([]byte(text))
+				size = int64(len(text))
+			}
+		} else {
+			data = base64.StdEncoding.EncodeToString(raw)
+			size = int64(len(raw))
+		}
+
+		if maxBytes > 0 && size > maxBytes {
+			outputs = append(outputs, models.RichOutput{MimeType: mimeType, Truncated: true})
+			continue
+		}
+		outputs = append(outputs, models.RichOutput{MimeType: mimeType, Data: data})
+	}
+	return outputs
+}
+
+func isBase64MimeType(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "image/") && mimeType != "image/svg+xml"
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/Fl0rencess720/agentland/pkg/common/models"
+)
+
+// syntaxCheckTimeout 为单次语法检查的最长耗时，检查只编译不执行，正常情况下远小于该值
+const syntaxCheckTimeout = 10 * time.Second
+
+// pythonSyntaxHelper 从 stdin 读取代码并 compile，不经过 kernel
+//
+//go:embed syntax_check.py
+var pythonSyntaxHelper string
+
+const (
+	syntaxCheckPythonCommand = "python3"
+	syntaxCheckBashCommand   = "bash"
+)
+
+// checkSyntax 只检查代码语法而不执行：python 使用 compile，bash 使用 bash -n
+// 诊断信息写入 Stderr，语法正确时 ExitCode 为 0，否则为 1；不占用 busy 位，也不增加 execution_count
+func (m *contextManager) checkSyntax(ctx context.Context, contextID, code string) (*models.ExecuteContextResp, error) {
+	kctx := m.get(contextID)
+	if kctx == nil {
+		return nil, m.notFound(contextID)
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, syntaxCheckTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	switch kctx.Language {
+	case contextLanguagePython:
+		cmd = exec.CommandContext(checkCtx, syntaxCheckPythonCommand, "-c", pythonSyntaxHelper)
+	case contextLanguageBash:
+		cmd = exec.CommandContext(checkCtx, syntaxCheckBashCommand, "-n")
+	default:
+		return nil, fmt.Errorf("%w: check_only supports python and bash contexts", errUnsupportedLanguage)
+	}
+
+	start := time.Now()
+	var stderr bytes.Buffer
+	cmd.Stdin = strings.NewReader(code)
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	resp := &models.ExecuteContextResp{
+		ContextID:      contextID,
+		ExecutionCount: kctx.executionCount.Load(),
+		Stderr:         stderr.String(),
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case checkCtx.Err() != nil:
+		return nil, fmt.Errorf("syntax check timed out: %w", checkCtx.Err())
+	case errors.As(runErr, &exitErr):
+		resp.ExitCode = 1
+	case runErr != nil:
+		return nil, fmt.Errorf("run syntax check failed: %w", runErr)
+	}
+	resp.DurationMs = time.Since(start).Milliseconds()
+	return resp, nil
+}
@@ -0,0 +1,36 @@
+"""korokd 语法检查辅助脚本：从 stdin 读取代码，仅编译不执行。
+
+编译失败时将诊断信息写入 stderr 并以 1 退出，成功时以 0 退出。
+"""
+
+import ast
+import re
+import sys
+import traceback
+
+# IPython magic（%pip、!ls 等）不是合法的 Python 语法，需先经 IPython 转换
+_MAGIC_LINE = re.compile(r"^\s*[%!]", re.MULTILINE)
+
+
+def _transform(src):
+    if not _MAGIC_LINE.search(src):
+        return src
+    try:
+        from IPython.core.inputtransformer2 import TransformerManager
+    except ImportError:
+        return src
+    return TransformerManager().transform_cell(src)
+
+
+def main():
+    src = sys.stdin.read()
+    try:
+        compile(_transform(src), "<cell>", "exec", flags=ast.PyCF_ALLOW_TOP_LEVEL_AWAIT, dont_inherit=True)
+    except (SyntaxError, ValueError) as exc:
+        sys.stderr.write("".join(traceback.format_exception_only(type(exc), exc)))
+        return 1
+    return 0
+
+
+if __name__ == "__main__":
+    sys.exit(main())
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSyntax(t *testing.T) {
+	fj := newFakeJupyter(t, nil)
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-py", contextLanguagePython)
+	addTestContext(m, "ctx-bash", contextLanguageBash)
+
+	cases := []struct {
+		name       string
+		contextID  string
+		code       string
+		exitCode   int32
+		diagnostic string
+	}{
+		{name: "python valid", contextID: "ctx-py", code: "x = 1\nprint(x)\n"},
+		{name: "python top-level await", contextID: "ctx-py", code: "import asyncio\nawait asyncio.sleep(0)\n"},
+		{name: "python invalid", contextID: "ctx-py", code: "def f(:\n    pass\n", exitCode: 1, diagnostic: "SyntaxError"},
+		{name: "bash valid", contextID: "ctx-bash", code: "for i in 1 2; do echo $i; done\n[[ -n $HOME ]]\n"},
+		{name: "bash invalid", contextID: "ctx-bash", code: "if true; then echo hi\n", exitCode: 1, diagnostic: "syntax error"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := m.checkSyntax(context.Background(), tc.contextID, tc.code)
+			require.NoError(t, err)
+			require.Equal(t, tc.exitCode, resp.ExitCode, resp.Stderr)
+			if tc.diagnostic == "" {
+				require.Empty(t, resp.Stderr)
+			} else {
+				require.Contains(t, resp.Stderr, tc.diagnostic)
+			}
+		})
+	}
+	require.Equal(t, int64(0), m.get("ctx-py").executionCount.Load())
+}
+
+func TestCheckSyntax_UnsupportedLanguage(t *testing.T) {
+	fj := newFakeJupyter(t, nil)
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-node", contextLanguageNode)
+
+	_, err := m.checkSyntax(context.Background(), "ctx-node", "let x = 1")
+	require.ErrorIs(t, err, errUnsupportedLanguage)
+}
+
+func TestExecuteInContext_CheckOnlyDoesNotRunKernel(t *testing.T) {
+	fj := newFakeJupyter(t, func(code string) fakeKernelReply {
+		t.Fatalf("kernel must not be called in check_only mode, got %q", code)
+		return fakeKernelReply{}
+	})
+	m := newTestContextManager(t, fj)
+	addTestContext(m, "ctx-1", contextLanguagePython)
+
+	events := executeViaHandler(t, m, "ctx-1", `{"code":"print(1","check_only":true}`)
+
+	var stderr string
+	for _, evt := range events {
+		if evt.Type == "stderr" {
+			stderr += evt.Text
+		}
+	}
+	require.Contains(t, stderr, "SyntaxError")
+
+	last := events[len(events)-1]
+	require.Equal(t, "execution_complete", last.Type)
+	require.Equal(t, int32(1), last.ExitCode)
+	require.Equal(t, int64(0), last.ExecutionCount)
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"github.com/Fl0rencess720/agentland/pkg/gateway/pkgs/response"
+	"github.com/Fl0rencess720/agentland/pkg/korokd/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// TokenVersionResp 为当前请求 token 绑定的会话与版本
+type TokenVersionResp struct {
+	SessionID string `json:"session_id"`
+	Version   int64  `json:"version"`
+}
+
+// InitTokenApi 注册 token 相关 HTTP 路由
+func InitTokenApi(group *gin.RouterGroup) {
+	group.GET("/token/version", GetTokenVersion)
+}
+
+// GetTokenVersion 返回请求 token 的会话与版本。鉴权中间件校验通过后已按该版本推进会话的最小版本，
+// 网关吊销 token 后以新版本调用此接口，使此前签发的 token 立即失效
+func GetTokenVersion(c *gin.Context) {
+	claims, ok := middleware.ClaimsFromContext(c)
+	if !ok {
+		response.ErrorResponse(c, response.ServerError)
+		return
+	}
+	response.SuccessResponse(c, TokenVersionResp{
+		SessionID: claims.SessionID,
+		Version:   claims.Version,
+	})
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Fl0rencess720/agentland/pkg/korokd/pkgs/jupyter"
+)
+
+const (
+	// contextVarsExpressionKey 为变量快照在 user_expressions 中的结果名
+	contextVarsExpressionKey = "agentland_vars"
+	// 单个变量 repr 的最大长度，超出部分截断
+	contextVarReprMaxLen = 256
+	// 单次返回的变量个数上限，按名称排序后截断
+	contextMaxReturnVars = 256
+)
+
+// pythonVarsSnippet 在独立命名空间中遍历用户 globals，生成 {name: repr} 的 JSON
+// - 跳过 _ 开头的名称（dunder、IPython 的 _i/_1 等历史变量、korokd 注入的 __agentland_*）以及模块与 IPython 内置对象
+// - repr 失败时记录异常类型，保证单个对象不会导致整体失败
+// - 返回对象的 repr 即 JSON 文本，使 text/plain 结果可直接解析
+var pythonVarsSnippet = strings.Join([]string{
+	"import json, types",
+	"def _safe_repr(v):",
+	"    try:",
+	"        s = repr(v)",
+	"    except BaseException as e:",
+	"        s = '<unrepresentable %s: %s>' % (type(v).__name__, type(e).__name__)",
+	fmt.Sprintf("    return s if len(s) <= %d else s[:%d] + '...'", contextVarReprMaxLen, contextVarReprMaxLen),
+	"_skip = {'In', 'Out', 'exit', 'quit', 'get_ipython'}",
+	"_names = sorted(k for k, v in list(g.items()) if isinstance(k, str) and not k.startswith('_') and k not in _skip and not isinstance(v, types.ModuleType))",
+	fmt.Sprintf("_out = {k: _safe_repr(g[k]) for k in _names[:%d]}", contextMaxReturnVars),
+	"class _JSON:",
+	"    def __init__(self, text):",
+	"        self.text = text",
+	"    def __repr__(self):",
+	"        return self.text",
+	"r = _JSON(json.dumps(_out))",
+}, "\n")
+
+// pythonVarsExpression 返回可作为 user_expression 求值的单个表达式，不会在用户命名空间中留下任何名称
+func pythonVarsExpression() string {
+	src, _ := json.Marshal(pythonVarsSnippet)
+	return "(lambda ns: (exec(" + string(src) + ", ns), ns['r'])[1])({'g': globals()})"
+}
+
+// variablesFromResult 从执行结果中解析变量快照，求值失败或结果不存在时返回 nil
+func variablesFromResult(result *jupyter.ExecuteResult) map[string]string {
+	if result == nil {
+		return nil
+	}
+	expr, ok := result.UserExpressions[contextVarsExpressionKey]
+	if !ok || expr.Status != "ok" {
+		return nil
+	}
+	raw, ok := expr.Data["text/plain"]
+	if !ok {
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		return nil
+	}
+	vars := make(map[string]string)
+	if err := json.Unmarshal([]byte(text), &vars); err != nil {
+		return nil
+	}
+	return vars
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -17,36 +19,58 @@ type tokenVerifier interface {
 	Verify(token string) (*utils.Claims, error)
 }
 
-func SandboxAuth(verifier tokenVerifier) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		if verifier == nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "sandbox auth verifier is not configured"})
-			return
-		}
+// authError 为鉴权失败的原因，status 为对应的 HTTP 状态码
+type authError struct {
+	status  int
+	message string
+}
 
-		token, err := utils.ParseBearerToken(c.GetHeader("Authorization"))
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid authorization header"})
-			return
-		}
+func (e *authError) Error() string {
+	return e.message
+}
 
-		claims, err := verifier.Verify(token)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid sandbox token"})
-			return
+// authenticate 校验 Authorization 与会话 header，HTTP 与 gRPC 共用
+func authenticate(ctx context.Context, verifier tokenVerifier, revocations utils.RevocationStore, authorization, sessionHeader string) (*utils.Claims, *authError) {
+	if verifier == nil {
+		return nil, &authError{http.StatusUnauthorized, "sandbox auth verifier is not configured"}
+	}
+	token, err := utils.ParseBearerToken(authorization)
+	if err != nil {
+		return nil, &authError{http.StatusUnauthorized, "missing or invalid authorization header"}
+	}
+	claims, err := verifier.Verify(token)
+	if err != nil {
+		return nil, &authError{http.StatusUnauthorized, "invalid sandbox token"}
+	}
+	sessionID := strings.TrimSpace(sessionHeader)
+	if sessionID == "" {
+		return nil, &authError{http.StatusForbidden, "missing x-agentland-session header"}
+	}
+	if claims.SessionID != sessionID {
+		return nil, &authError{http.StatusForbidden, "session header does not match sandbox token"}
+	}
+	if err := utils.CheckRevocation(ctx, revocations, claims); err != nil {
+		if errors.Is(err, utils.ErrTokenRevoked) {
+			return nil, &authError{http.StatusUnauthorized, "sandbox token has been revoked"}
 		}
+		return nil, &authError{http.StatusServiceUnavailable, "token revocation check failed"}
+	}
+	return claims, nil
+}
 
-		sessionID := strings.TrimSpace(c.GetHeader(sessionHeaderKey))
-		if sessionID == "" {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "missing x-agentland-session header"})
-			return
-		}
-		if claims.SessionID != sessionID {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "session header does not match sandbox token"})
+// SandboxAuth 校验沙箱 token；revocations 非空时拒绝版本低于会话最小版本的 token。
+func SandboxAuth(verifier tokenVerifier, revocations utils.RevocationStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, authErr := authenticate(c.Request.Context(), verifier, revocations,
+			c.GetHeader("Authorization"), c.GetHeader(sessionHeaderKey))
+		if authErr != nil {
+			c.AbortWithStatusJSON(authErr.status, gin.H{"error": authErr.message})
 			return
 		}
 
 		c.Set(claimsContextKey, claims)
+		// 同时写入请求 context，使 HTTP 与 gRPC 共用的执行逻辑可通过 SubjectFromContext 读取调用方身份
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), claimsKey{}, claims))
 		c.Next()
 	}
 }
@@ -59,3 +83,11 @@ func ClaimsFromContext(c *gin.Context) (*utils.Claims, bool) {
 	claims, ok := v.(*utils.Claims)
 	return claims, ok
 }
+
+// SubjectFromContext 返回鉴权通过的沙箱 token 中的调用方身份（sub claim），未鉴权时返回空串
+func SubjectFromContext(ctx context.Context) string {
+	if claims, ok := ClaimsFromGRPCContext(ctx); ok {
+		return claims.Subject
+	}
+	return ""
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -17,7 +19,7 @@ func TestSandboxAuth_RejectMissingToken(t *testing.T) {
 
 	_, verifier := newSignerAndVerifier(t)
 	router := gin.New()
-	router.Use(SandboxAuth(verifier))
+	router.Use(SandboxAuth(verifier, nil))
 	router.POST("/api/execute", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"ok": true})
 	})
@@ -34,7 +36,7 @@ func TestSandboxAuth_RejectInvalidToken(t *testing.T) {
 
 	_, verifier := newSignerAndVerifier(t)
 	router := gin.New()
-	router.Use(SandboxAuth(verifier))
+	router.Use(SandboxAuth(verifier, nil))
 	router.POST("/api/execute", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"ok": true})
 	})
@@ -51,12 +53,13 @@ func TestSandboxAuth_AcceptsValidToken(t *testing.T) {
 	gin.SetMode(gin.ReleaseMode)
 
 	signer, verifier := newSignerAndVerifier(t)
-	token, err := signer.Sign("session-1", "", 0)
+	token, err := signer.Sign("session-1", "alice", 0)
 	require.NoError(t, err)
 
 	router := gin.New()
-	router.Use(SandboxAuth(verifier))
+	router.Use(SandboxAuth(verifier, nil))
 	router.POST("/api/execute", func(c *gin.Context) {
+		require.Equal(t, "alice", SubjectFromContext(c.Request.Context()))
 		c.JSON(http.StatusOK, gin.H{"ok": true})
 	})
 
@@ -77,7 +80,7 @@ func TestSandboxAuth_RejectMissingSessionHeader(t *testing.T) {
 	require.NoError(t, err)
 
 	router := gin.New()
-	router.Use(SandboxAuth(verifier))
+	router.Use(SandboxAuth(verifier, nil))
 	router.POST("/api/execute", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"ok": true})
 	})
@@ -99,7 +102,7 @@ func TestSandboxAuth_RejectSessionHeaderMismatch(t *testing.T) {
 	require.NoError(t, err)
 
 	router := gin.New()
-	router.Use(SandboxAuth(verifier))
+	router.Use(SandboxAuth(verifier, nil))
 	router.POST("/api/execute", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"ok": true})
 	})
@@ -114,6 +117,100 @@ func TestSandboxAuth_RejectSessionHeaderMismatch(t *testing.T) {
 	require.Contains(t, w.Body.String(), "session header does not match sandbox token")
 }
 
+type staticRevocationStore struct {
+	minVersion int64
+	err        error
+}
+
+func (s staticRevocationStore) MinTokenVersion(_ context.Context, _ string) (int64, error) {
+	return s.minVersion, s.err
+}
+
+func TestSandboxAuth_RejectsRevokedTokenVersion(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	signer, verifier := newSignerAndVerifier(t)
+	revoked, err := signer.Sign("session-1", "", 1)
+	require.NoError(t, err)
+	current, err := signer.Sign("session-1", "", 2)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(SandboxAuth(verifier, staticRevocationStore{minVersion: 2}))
+	router.POST("/api/execute", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/execute", nil)
+	req.Header.Set("Authorization", "Bearer "+revoked)
+	req.Header.Set("x-agentland-session", "session-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Contains(t, w.Body.String(), "revoked")
+
+	req = httptest.NewRequest(http.MethodPost, "/api/execute", nil)
+	req.Header.Set("Authorization", "Bearer "+current)
+	req.Header.Set("x-agentland-session", "session-1")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSandboxAuth_WatermarkRevokesOlderTokens(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	signer, verifier := newSignerAndVerifier(t)
+	stale, err := signer.Sign("session-1", "", 0)
+	require.NoError(t, err)
+	current, err := signer.Sign("session-1", "", 1)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(SandboxAuth(verifier, utils.NewTokenVersionWatermark()))
+	router.POST("/api/execute", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	send := func(token string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/execute", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("x-agentland-session", "session-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusOK, send(stale))
+	// 网关递增版本后转发的请求推进沙箱侧的最小版本
+	require.Equal(t, http.StatusOK, send(current))
+	require.Equal(t, http.StatusUnauthorized, send(stale))
+}
+
+func TestSandboxAuth_RevocationStoreUnavailable(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	signer, verifier := newSignerAndVerifier(t)
+	token, err := signer.Sign("session-1", "", 0)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(SandboxAuth(verifier, staticRevocationStore{err: errors.New("redis down")}))
+	router.POST("/api/execute", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/execute", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("x-agentland-session", "session-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
 func newSignerAndVerifier(t *testing.T) (*utils.Signer, *utils.Verifier) {
 	t.Helper()
 
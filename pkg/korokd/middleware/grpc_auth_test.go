@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestSandboxAuthUnaryInterceptor(t *testing.T) {
+	signer, verifier := newSignerAndVerifier(t)
+	token, err := signer.Sign("session-1", "", 0)
+	require.NoError(t, err)
+	interceptor := SandboxAuthUnaryInterceptor(verifier, nil)
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		claims, ok := ClaimsFromGRPCContext(ctx)
+		require.True(t, ok)
+		return claims.SessionID, nil
+	}
+	call := func(md metadata.MD) (any, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		return interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	}
+
+	_, err = call(metadata.MD{})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = call(metadata.Pairs("authorization", "Bearer invalid.token.value", "x-agentland-session", "session-1"))
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = call(metadata.Pairs("authorization", "Bearer "+token))
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = call(metadata.Pairs("authorization", "Bearer "+token, "x-agentland-session", "session-2"))
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	out, err := call(metadata.Pairs("authorization", "Bearer "+token, "x-agentland-session", "session-1"))
+	require.NoError(t, err)
+	require.Equal(t, "session-1", out)
+}
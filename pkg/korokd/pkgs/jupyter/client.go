@@ -131,6 +131,11 @@ func (c *Client) InterruptKernel(ctx context.Context, kernelID string) error {
 	return c.doJSON(ctx, http.MethodPost, "api/kernels/"+url.PathEscape(kernelID)+"/interrupt", nil, nil)
 }
 
+// RestartKernel 原地重启 kernel，kernel ID 与所属 session 保持不变
+func (c *Client) RestartKernel(ctx context.Context, kernelID string) error {
+	return c.doJSON(ctx, http.MethodPost, "api/kernels/"+url.PathEscape(kernelID)+"/restart", nil, nil)
+}
+
 func (c *Client) KernelChannelsURL(kernelID string) (string, error) {
 	u := *c.baseURL
 	switch u.Scheme {
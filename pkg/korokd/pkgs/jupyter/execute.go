@@ -17,7 +17,29 @@ type ExecuteResult struct {
 	ExecutionCount int64
 	Stdout         string
 	Stderr         string
-	Duration       time.Duration
+	// StdoutTruncated/StderrTruncated 表示对应输出超过 MaxOutputBytes 被截断
+	StdoutTruncated bool
+	StderrTruncated bool
+	// Displays 按到达顺序保存 display_data/execute_result 的 MIME bundle
+	Displays []MimeBundle
+	// UserExpressions 为 execute_reply 中 user_expressions 的求值结果，仅在执行成功时由 kernel 填充
+	UserExpressions map[string]UserExpressionResult
+	Duration        time.Duration
+}
+
+// UserExpressionResult 为单个 user_expression 的求值结果
+type UserExpressionResult struct {
+	Status string     `json:"status"`
+	Data   MimeBundle `json:"data"`
+	EName  string     `json:"ename"`
+	EValue string     `json:"evalue"`
+}
+
+// MimeBundle 为 Jupyter 富输出的 data 字段，key 为 MIME 类型
+type MimeBundle map[string]json.RawMessage
+
+type displayContent struct {
+	Data MimeBundle `json:"data"`
 }
 
 type streamContent struct {
@@ -40,11 +62,21 @@ type executeInputContent struct {
 }
 
 type executeReplyContent struct {
-	Status         string   `json:"status"`
-	ExecutionCount int64    `json:"execution_count"`
-	EName          string   `json:"ename"`
-	EValue         string   `json:"evalue"`
-	Traceback      []string `json:"traceback"`
+	Status          string                          `json:"status"`
+	ExecutionCount  int64                           `json:"execution_count"`
+	EName           string                          `json:"ename"`
+	EValue          string                          `json:"evalue"`
+	Traceback       []string                        `json:"traceback"`
+	UserExpressions map[string]UserExpressionResult `json:"user_expressions"`
+}
+
+type inputRequestContent struct {
+	Prompt   string `json:"prompt"`
+	Password bool   `json:"password"`
+}
+
+type inputReplyContent struct {
+	Value string `json:"value"`
 }
 
 type executeRequestContent struct {
@@ -56,16 +88,27 @@ type executeRequestContent struct {
 	StopOnError     bool              `json:"stop_on_error"`
 }
 
+// ExecuteOptions 控制单次执行的可选行为
+type ExecuteOptions struct {
+	// Stdin 非 nil 时允许 kernel 发起 input_request，按行依次回复；为 nil 时保持 allow_stdin=false
+	Stdin *string
+	// UserExpressions 为执行结束后在用户命名空间中求值的表达式，key 为结果名
+	UserExpressions map[string]string
+	// MaxOutputBytes 为聚合的 stdout/stderr 各自的字节上限，<=0 表示不限制；回调仍收到完整输出
+	MaxOutputBytes int64
+}
+
 type ExecuteHooks struct {
 	OnStdout         func(text string)
 	OnStderr         func(text string)
 	OnStatus         func(state string)
 	OnExecutionCount func(count int64)
+	OnDisplay        func(bundle MimeBundle)
 }
 
 // Execute 通过 Jupyter Kernel Channels WebSocket 在指定 kernel 中执行代码并返回聚合结果
 // hooks 用于将 stdout stderr 状态与计数以回调形式实时输出
-func (c *Client) Execute(ctx context.Context, kernelID, code string, hooks ExecuteHooks) (*ExecuteResult, error) {
+func (c *Client) Execute(ctx context.Context, kernelID, code string, opts ExecuteOptions, hooks ExecuteHooks) (*ExecuteResult, error) {
 	// 通过 kernelID 计算 Jupyter 的 channels WebSocket 地址
 	wsURL, err := c.KernelChannelsURL(kernelID)
 	if err != nil {
@@ -89,15 +132,25 @@ func (c *Client) Execute(ctx context.Context, kernelID, code string, hooks Execu
 	now := time.Now()
 
 	// 组装 execute_request 的 content
+	userExpressions := opts.UserExpressions
+	if userExpressions == nil {
+		userExpressions = map[string]string{}
+	}
 	reqContent, _ := json.Marshal(&executeRequestContent{
 		Code:            code,
 		Silent:          false,
 		StoreHistory:    true,
-		UserExpressions: map[string]string{},
-		AllowStdin:      false,
+		UserExpressions: userExpressions,
+		AllowStdin:      opts.Stdin != nil,
 		StopOnError:     false,
 	})
 
+	// 将 stdin 拆成按行的队列，每个 input_request 消费一行
+	var stdinLines []string
+	if opts.Stdin != nil && *opts.Stdin != "" {
+		stdinLines = strings.SplitAfter(*opts.Stdin, "\n")
+	}
+
 	msg := &wireMessage{
 		Header: messageHeader{
 			MessageID:   reqID,
@@ -145,8 +198,10 @@ func (c *Client) Execute(ctx context.Context, kernelID, code string, hooks Execu
 	}()
 
 	// 主循环聚合 stdout stderr 并透传实时回调
-	var stdout strings.Builder
-	var stderr strings.Builder
+	stdout := newLimitedBuilder(opts.MaxOutputBytes)
+	stderr := newLimitedBuilder(opts.MaxOutputBytes)
+	var displays []MimeBundle
+	var userExprs map[string]UserExpressionResult
 	var execCount int64
 	hadError := false
 	replyStatus := ""
@@ -160,29 +215,39 @@ func (c *Client) Execute(ctx context.Context, kernelID, code string, hooks Execu
 			// 上层取消或超时则主动关闭连接并返回当前已聚合的输出
 			_ = conn.Close()
 			return &ExecuteResult{
-				Status:         "timeout",
-				ExecutionCount: execCount,
-				Stdout:         stdout.String(),
-				Stderr:         stderr.String(),
-				Duration:       time.Since(start),
+				Status:          "timeout",
+				ExecutionCount:  execCount,
+				Stdout:          stdout.String(),
+				Stderr:          stderr.String(),
+				StdoutTruncated: stdout.Truncated(),
+				StderrTruncated: stderr.Truncated(),
+				Displays:        displays,
+				Duration:        time.Since(start),
 			}, ctx.Err()
 		case r, ok := <-recvCh:
 			if !ok {
 				return &ExecuteResult{
-					Status:         statusFrom(hadError, replyStatus),
-					ExecutionCount: execCount,
-					Stdout:         stdout.String(),
-					Stderr:         stderr.String(),
-					Duration:       time.Since(start),
+					Status:          statusFrom(hadError, replyStatus),
+					ExecutionCount:  execCount,
+					Stdout:          stdout.String(),
+					Stderr:          stderr.String(),
+					StdoutTruncated: stdout.Truncated(),
+					StderrTruncated: stderr.Truncated(),
+					Displays:        displays,
+					UserExpressions: userExprs,
+					Duration:        time.Since(start),
 				}, nil
 			}
 			if r.err != nil {
 				return &ExecuteResult{
-					Status:         "error",
-					ExecutionCount: execCount,
-					Stdout:         stdout.String(),
-					Stderr:         stderr.String(),
-					Duration:       time.Since(start),
+					Status:          "error",
+					ExecutionCount:  execCount,
+					Stdout:          stdout.String(),
+					Stderr:          stderr.String(),
+					StdoutTruncated: stdout.Truncated(),
+					StderrTruncated: stderr.Truncated(),
+					Displays:        displays,
+					Duration:        time.Since(start),
 				}, fmt.Errorf("read kernel message failed: %w", r.err)
 			}
 
@@ -252,6 +317,7 @@ func (c *Client) Execute(ctx context.Context, kernelID, code string, hooks Execu
 					// execute_reply 给出最终状态与可能的 traceback
 					gotReply = true
 					replyStatus = rc.Status
+					userExprs = rc.UserExpressions
 					if rc.ExecutionCount > 0 {
 						execCount = rc.ExecutionCount
 						if hooks.OnExecutionCount != nil {
@@ -275,6 +341,44 @@ func (c *Client) Execute(ctx context.Context, kernelID, code string, hooks Execu
 						}
 					}
 				}
+			case "display_data", "execute_result":
+				var dc displayContent
+				if err := json.Unmarshal(r.msg.Content, &dc); err == nil && len(dc.Data) > 0 {
+					// 富输出（图片/HTML 等）整包保留，由上层决定如何编码与截断
+					displays = append(displays, dc.Data)
+					if hooks.OnDisplay != nil {
+						hooks.OnDisplay(dc.Data)
+					}
+				}
+			case "input_request":
+				var ir inputRequestContent
+				if err := json.Unmarshal(r.msg.Content, &ir); err == nil {
+					// 与终端行为一致，提示符写入 stdout
+					if ir.Prompt != "" {
+						stdout.WriteString(ir.Prompt)
+						if hooks.OnStdout != nil {
+							hooks.OnStdout(ir.Prompt)
+						}
+					}
+					// stdin 耗尽后回复空串，避免 kernel 一直阻塞等待输入
+					value := ""
+					if len(stdinLines) > 0 {
+						value = strings.TrimSuffix(stdinLines[0], "\n")
+						stdinLines = stdinLines[1:]
+					}
+					if err := sendInputReply(conn, r.msg.Header, value); err != nil {
+						return &ExecuteResult{
+							Status:          "error",
+							ExecutionCount:  execCount,
+							Stdout:          stdout.String(),
+							Stderr:          stderr.String(),
+							StdoutTruncated: stdout.Truncated(),
+							StderrTruncated: stderr.Truncated(),
+							Displays:        displays,
+							Duration:        time.Since(start),
+						}, fmt.Errorf("send input_reply failed: %w", err)
+					}
+				}
 			case "status":
 				var st statusContent
 				if err := json.Unmarshal(r.msg.Content, &st); err == nil {
@@ -292,17 +396,39 @@ func (c *Client) Execute(ctx context.Context, kernelID, code string, hooks Execu
 			// 同时看到 reply 与 idle 才认为本次执行已结束
 			if gotIdle && gotReply {
 				return &ExecuteResult{
-					Status:         statusFrom(hadError, replyStatus),
-					ExecutionCount: execCount,
-					Stdout:         stdout.String(),
-					Stderr:         stderr.String(),
-					Duration:       time.Since(start),
+					Status:          statusFrom(hadError, replyStatus),
+					ExecutionCount:  execCount,
+					Stdout:          stdout.String(),
+					Stderr:          stderr.String(),
+					StdoutTruncated: stdout.Truncated(),
+					StderrTruncated: stderr.Truncated(),
+					Displays:        displays,
+					UserExpressions: userExprs,
+					Duration:        time.Since(start),
 				}, nil
 			}
 		}
 	}
 }
 
+func sendInputReply(conn *websocket.Conn, parent messageHeader, value string) error {
+	content, _ := json.Marshal(&inputReplyContent{Value: value})
+	return websocket.JSON.Send(conn, &wireMessage{
+		Header: messageHeader{
+			MessageID:   uuid.NewString(),
+			Username:    "korokd",
+			Session:     parent.Session,
+			Date:        time.Now().Format(time.RFC3339),
+			MessageType: "input_reply",
+			Version:     "5.3",
+		},
+		ParentHeader: parent,
+		Metadata:     map[string]any{},
+		Content:      content,
+		Channel:      "stdin",
+	})
+}
+
 func statusFrom(hadError bool, replyStatus string) string {
 	if replyStatus == "error" || hadError {
 		return "error"
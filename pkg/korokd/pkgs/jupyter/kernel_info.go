@@ -0,0 +1,63 @@
+package jupyter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/net/websocket"
+)
+
+// KernelInfo 通过 kernel channels 发送 kernel_info_request 并等待对应的 kernel_info_reply，
+// 收到回复即表示 kernel 已就绪；与空代码执行不同，不会占用 execution_count
+func (c *Client) KernelInfo(ctx context.Context, kernelID string) error {
+	wsURL, err := c.KernelChannelsURL(kernelID)
+	if err != nil {
+		return err
+	}
+	cfg, err := websocket.NewConfig(wsURL, originForWSURL(wsURL))
+	if err != nil {
+		return fmt.Errorf("build websocket config failed: %w", err)
+	}
+	conn, err := cfg.DialContext(ctx)
+	if err != nil {
+		return fmt.Errorf("connect jupyter kernel channels failed: %w", err)
+	}
+	defer conn.Close()
+	// 上层取消或超时时关闭连接，使阻塞中的 Receive 立即返回
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	reqID := uuid.NewString()
+	msg := &wireMessage{
+		Header: messageHeader{
+			MessageID:   reqID,
+			Username:    "korokd",
+			Session:     uuid.NewString(),
+			Date:        time.Now().Format(time.RFC3339),
+			MessageType: "kernel_info_request",
+			Version:     "5.3",
+		},
+		ParentHeader: messageHeader{},
+		Metadata:     map[string]any{},
+		Content:      []byte("{}"),
+		Channel:      "shell",
+	}
+	if err := websocket.JSON.Send(conn, msg); err != nil {
+		return fmt.Errorf("send kernel_info_request failed: %w", err)
+	}
+
+	for {
+		var m wireMessage
+		if err := websocket.JSON.Receive(conn, &m); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("read kernel message failed: %w", err)
+		}
+		if m.ParentHeader.MessageID == reqID && m.Header.MessageType == "kernel_info_reply" {
+			return nil
+		}
+	}
+}
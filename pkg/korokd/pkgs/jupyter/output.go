@@ -0,0 +1,75 @@
+package jupyter
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// OutputLimiter 限制单个输出流累计的字节数，超出部分丢弃并在截断处追加一次提示
+type OutputLimiter struct {
+	// max<=0 表示不限制
+	max       int64
+	written   int64
+	truncated bool
+}
+
+func NewOutputLimiter(max int64) *OutputLimiter {
+	return &OutputLimiter{max: max}
+}
+
+// TruncationMarker 返回截断处追加的提示文本
+func TruncationMarker(max int64) string {
+	return fmt.Sprintf("\n[output truncated: exceeded %d bytes]\n", max)
+}
+
+// Take 返回 text 中仍在限额内的部分；首次超限时返回值末尾带截断提示，此后始终返回空串
+func (l *OutputLimiter) Take(text string) string {
+	if l == nil || l.max <= 0 {
+		return text
+	}
+	if l.truncated {
+		return ""
+	}
+	remaining := l.max - l.written
+	if int64(len(text)) <= remaining {
+		l.written += int64(len(text))
+		return text
+	}
+
+	// 避免截断在多字节字符中间
+	cut := int(remaining)
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	l.written = l.max
+	l.truncated = true
+	return text[:cut] + TruncationMarker(l.max)
+}
+
+// Truncated 报告是否发生过截断
+func (l *OutputLimiter) Truncated() bool {
+	return l != nil && l.truncated
+}
+
+// limitedBuilder 为带字节上限的 strings.Builder
+type limitedBuilder struct {
+	sb      strings.Builder
+	limiter *OutputLimiter
+}
+
+func newLimitedBuilder(max int64) *limitedBuilder {
+	return &limitedBuilder{limiter: NewOutputLimiter(max)}
+}
+
+func (b *limitedBuilder) WriteString(text string) {
+	b.sb.WriteString(b.limiter.Take(text))
+}
+
+func (b *limitedBuilder) String() string {
+	return b.sb.String()
+}
+
+func (b *limitedBuilder) Truncated() bool {
+	return b.limiter.Truncated()
+}
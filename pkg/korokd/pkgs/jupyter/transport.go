@@ -0,0 +1,132 @@
+package jupyter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	TransportTCP = "tcp"
+	TransportIPC = "ipc"
+
+	DefaultSignatureScheme = "hmac-sha256"
+	// DefaultIPCDir 尽量短，给 jupyter_client 追加的端口后缀留出 sun_path 余量
+	DefaultIPCDir = "/tmp/kk"
+
+	// maxUnixSocketPath 为 Linux sockaddr_un.sun_path 可容纳的最大路径字节数（不含结尾 NUL）
+	maxUnixSocketPath = 107
+	// ipcPortSuffixLen 为 jupyter_client 在 ipc 前缀后追加的 "-<port>" 的最大长度，端口按 65535 预留
+	ipcPortSuffixLen = len("-65535")
+	ipcSocketPrefix  = "kernel"
+	// ipcShortDirHashLen 为配置目录过长时回退目录名取用的哈希前缀长度
+	ipcShortDirHashLen = 12
+)
+
+var supportedSignatureSchemes = []string{"hmac-sha256", "hmac-sha384", "hmac-sha512"}
+
+// KernelTransportConfig 描述 Jupyter Server 启动内核时写入连接文件的传输方式与消息签名算法
+type KernelTransportConfig struct {
+	// Transport 为 tcp 或 ipc，为空时使用 tcp
+	Transport string
+	// IPCDir 为 ipc 模式下 Unix Domain Socket 所在目录，必须为绝对路径
+	IPCDir string
+	// SignatureScheme 为内核消息 HMAC 签名算法，为空时使用 hmac-sha256
+	SignatureScheme string
+}
+
+func (c KernelTransportConfig) normalized() KernelTransportConfig {
+	c.Transport = strings.ToLower(strings.TrimSpace(c.Transport))
+	if c.Transport == "" {
+		c.Transport = TransportTCP
+	}
+	c.IPCDir = strings.TrimSpace(c.IPCDir)
+	if c.IPCDir == "" {
+		c.IPCDir = DefaultIPCDir
+	}
+	c.SignatureScheme = strings.ToLower(strings.TrimSpace(c.SignatureScheme))
+	if c.SignatureScheme == "" {
+		c.SignatureScheme = DefaultSignatureScheme
+	}
+	return c
+}
+
+// EffectiveIPCDir 返回 ipc socket 实际所在目录。配置目录拼上 socket 名后会超出 sun_path 时，
+// 回退到 DefaultIPCDir 下以配置目录哈希命名的短目录，同一配置总是得到同一目录
+func (c KernelTransportConfig) EffectiveIPCDir() string {
+	dir := c.normalized().IPCDir
+	if ipcSocketPathLen(dir) <= maxUnixSocketPath {
+		return dir
+	}
+	sum := sha256.Sum256([]byte(dir))
+	return filepath.Join(DefaultIPCDir, hex.EncodeToString(sum[:])[:ipcShortDirHashLen])
+}
+
+// IPCSocketPrefix 返回 ipc 模式下传给 jupyter_client 的 socket 路径前缀，实际 socket 为 "<前缀>-<端口>"
+func (c KernelTransportConfig) IPCSocketPrefix() string {
+	return filepath.Join(c.EffectiveIPCDir(), ipcSocketPrefix)
+}
+
+// ipcSocketPathLen 返回目录 dir 下最长的 ipc socket 路径字节数
+func ipcSocketPathLen(dir string) int {
+	return len(filepath.Join(dir, ipcSocketPrefix)) + ipcPortSuffixLen
+}
+
+// Validate 校验传输方式与签名算法；ipc 模式下回退后的 socket 路径仍超出 sun_path 长度时直接报错，
+// 避免内核启动后才因 bind 失败而无法连接
+func (c KernelTransportConfig) Validate() error {
+	n := c.normalized()
+	switch n.Transport {
+	case TransportTCP:
+	case TransportIPC:
+		if !filepath.IsAbs(n.IPCDir) {
+			return fmt.Errorf("kernel ipc dir must be an absolute path: %q", n.IPCDir)
+		}
+		if l := ipcSocketPathLen(n.EffectiveIPCDir()); l > maxUnixSocketPath {
+			return fmt.Errorf("kernel ipc socket path too long: %s-<port> needs up to %d bytes, limit is %d; use a shorter ipc dir",
+				n.IPCSocketPrefix(), l, maxUnixSocketPath)
+		}
+	default:
+		return fmt.Errorf("unsupported kernel transport %q, expected %q or %q", c.Transport, TransportTCP, TransportIPC)
+	}
+	for _, scheme := range supportedSignatureSchemes {
+		if n.SignatureScheme == scheme {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported kernel signature scheme %q, expected one of %s",
+		c.SignatureScheme, strings.Join(supportedSignatureSchemes, ", "))
+}
+
+// Prepare 校验配置，ipc 模式下创建仅属主可访问的 socket 目录
+func (c KernelTransportConfig) Prepare() error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	n := c.normalized()
+	if n.Transport != TransportIPC {
+		return nil
+	}
+	if err := os.MkdirAll(n.EffectiveIPCDir(), 0o700); err != nil {
+		return fmt.Errorf("create kernel ipc dir: %w", err)
+	}
+	return nil
+}
+
+// ServerArgs 返回启动 Jupyter Server 时追加的命令行参数。
+// tcp 模式下内核只监听回环地址，并开启 cache_ports，由 jupyter_client 在同一进程内记录已分配端口，
+// 避免并发启动的内核拿到相同的临时端口
+func (c KernelTransportConfig) ServerArgs() []string {
+	n := c.normalized()
+	args := []string{
+		"--KernelManager.transport=" + n.Transport,
+		"--Session.signature_scheme=" + n.SignatureScheme,
+	}
+	if n.Transport == TransportIPC {
+		return append(args, "--KernelManager.ip="+n.IPCSocketPrefix())
+	}
+	return append(args, "--KernelManager.ip=127.0.0.1", "--KernelManager.cache_ports=True")
+}
@@ -0,0 +1,59 @@
+package jupyter
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKernelTransportConfig_Defaults(t *testing.T) {
+	cfg := KernelTransportConfig{}
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, []string{
+		"--KernelManager.transport=tcp",
+		"--Session.signature_scheme=hmac-sha256",
+		"--KernelManager.ip=127.0.0.1",
+		"--KernelManager.cache_ports=True",
+	}, cfg.ServerArgs())
+}
+
+func TestKernelTransportConfig_IPC(t *testing.T) {
+	dir := t.TempDir()
+	cfg := KernelTransportConfig{Transport: "IPC", IPCDir: filepath.Join(dir, "kernels"), SignatureScheme: "hmac-sha512"}
+	require.NoError(t, cfg.Prepare())
+	require.DirExists(t, filepath.Join(dir, "kernels"))
+	require.Equal(t, []string{
+		"--KernelManager.transport=ipc",
+		"--Session.signature_scheme=hmac-sha512",
+		"--KernelManager.ip=" + filepath.Join(dir, "kernels", "kernel"),
+	}, cfg.ServerArgs())
+}
+
+func TestKernelTransportConfig_IPCPathLengthGuard(t *testing.T) {
+	// "/<dir>/kernel-65535" 恰好 107 字节时直接使用配置目录
+	fits := "/" + strings.Repeat("a", maxUnixSocketPath-len("/kernel-65535")-1)
+	cfg := KernelTransportConfig{Transport: TransportIPC, IPCDir: fits}
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, fits, cfg.EffectiveIPCDir())
+	require.Len(t, cfg.IPCSocketPrefix()+"-65535", maxUnixSocketPath)
+
+	// 多一个字节即回退到 DefaultIPCDir 下的哈希短目录，且结果稳定
+	tooLong := KernelTransportConfig{Transport: TransportIPC, IPCDir: fits + "b"}
+	require.NoError(t, tooLong.Validate())
+	short := tooLong.EffectiveIPCDir()
+	require.Equal(t, DefaultIPCDir, filepath.Dir(short))
+	require.Len(t, filepath.Base(short), ipcShortDirHashLen)
+	require.Equal(t, short, tooLong.EffectiveIPCDir())
+	require.NotEqual(t, short, KernelTransportConfig{Transport: TransportIPC, IPCDir: fits + "c"}.EffectiveIPCDir())
+	require.LessOrEqual(t, ipcSocketPathLen(short), maxUnixSocketPath)
+
+	err := KernelTransportConfig{Transport: TransportIPC, IPCDir: "relative/dir"}.Validate()
+	require.ErrorContains(t, err, "absolute path")
+}
+
+func TestKernelTransportConfig_RejectsUnknownValues(t *testing.T) {
+	require.ErrorContains(t, KernelTransportConfig{Transport: "udp"}.Validate(), "unsupported kernel transport")
+	require.ErrorContains(t, KernelTransportConfig{SignatureScheme: "md5"}.Validate(), "unsupported kernel signature scheme")
+}
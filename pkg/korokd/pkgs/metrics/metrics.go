@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "korokd"
+
+// 执行结果的退出码分类：0 成功，124 超时，其余非零退出码统一归为 1
+const (
+	ExitClassSuccess = "0"
+	ExitClassError   = "1"
+	ExitClassTimeout = "124"
+)
+
+// Registry 为 korokd 专用的指标注册表，与进程内其他默认注册的指标隔离
+var Registry = prometheus.NewRegistry()
+
+var (
+	// ActiveContexts 当前存活的执行上下文数量
+	ActiveContexts = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_contexts",
+		Help:      "Number of active execution contexts.",
+	})
+
+	// Executions 按语言与退出码分类统计执行次数
+	Executions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "executions_total",
+		Help:      "Total number of code executions by language and exit code class.",
+	}, []string{"language", "exit_class"})
+
+	// ExecutionDuration 单次执行耗时，取自响应中的 DurationMs
+	ExecutionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "execution_duration_seconds",
+		Help:      "Duration of code executions.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"language"})
+
+	// GCReclaimed 因空闲超时被 GC 回收的上下文数量
+	GCReclaimed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gc_reclaimed_contexts_total",
+		Help:      "Total number of idle contexts reclaimed by GC.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		ActiveContexts,
+		Executions,
+		ExecutionDuration,
+		GCReclaimed,
+	)
+}
+
+// Handler 返回暴露 korokd 注册表的 /metrics 处理器
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveExecution 记录一次执行的退出码分类与耗时
+func ObserveExecution(language string, exitCode int32, durationMs int64) {
+	Executions.WithLabelValues(language, ExitClass(exitCode)).Inc()
+	ExecutionDuration.WithLabelValues(language).Observe((time.Duration(durationMs) * time.Millisecond).Seconds())
+}
+
+// ExitClass 将退出码归类为 0/1/124
+func ExitClass(exitCode int32) string {
+	switch exitCode {
+	case 0:
+		return ExitClassSuccess
+	case 124:
+		return ExitClassTimeout
+	default:
+		return ExitClassError
+	}
+}
@@ -21,6 +21,11 @@ func SetupSSEResponse(c *gin.Context) {
 }
 
 func WriteSSE(c *gin.Context, mu *sync.Mutex, evt models.ExecuteStreamEvent) bool {
+	return WriteSSEData(c, mu, evt)
+}
+
+// WriteSSEData 将任意可 JSON 序列化的事件写为一帧 SSE 并立即刷新，客户端断开或写失败时返回 false
+func WriteSSEData(c *gin.Context, mu *sync.Mutex, evt any) bool {
 	if c == nil {
 		return false
 	}
@@ -2,44 +2,86 @@ package korokd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"os/exec"
+	"strings"
 	"time"
 
+	"github.com/Fl0rencess720/agentland/pkg/common/audit"
+	"github.com/Fl0rencess720/agentland/pkg/common/health"
 	"github.com/Fl0rencess720/agentland/pkg/common/utils"
 	"github.com/Fl0rencess720/agentland/pkg/korokd/config"
 	"github.com/Fl0rencess720/agentland/pkg/korokd/handlers"
 	"github.com/Fl0rencess720/agentland/pkg/korokd/middleware"
+	"github.com/Fl0rencess720/agentland/pkg/korokd/pkgs/metrics"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 )
 
 type Server struct {
 	httpServer *http.Server
+	prober     *health.Prober
+	// metricsServer 在独立端口暴露 /metrics，未配置端口时为 nil
+	metricsServer *http.Server
+	// grpcServer 提供 ContextService，未配置端口时为 nil
+	grpcServer   *grpc.Server
+	grpcListener net.Listener
+	// codeInterpreter 持有全部 context，退出时统一回收
+	codeInterpreter *handlers.CodeInterpreterHandler
 }
 
+// contextShutdownTimeout 为退出时回收全部 context 的最长等待时间
+const contextShutdownTimeout = 10 * time.Second
+
 func NewServer(cfg *config.Config) (*Server, error) {
-	s := &Server{}
+	s := &Server{prober: health.NewProber(0)}
+	s.prober.AddCheck("process", checkProcessSpawn)
+	s.prober.AddCheck("workspace", func(ctx context.Context) error {
+		return checkWorkspaceWritable(cfg.WorkspaceRoot)
+	})
 
 	r := gin.New()
 	r.Use(gin.Recovery())
 	r.GET("/health", s.HealthHandler)
+	s.prober.Register(r)
 
-	verifier, err := utils.NewVerifierFromConfig(utils.VerifierConfig{
-		PublicKeyPath: cfg.SandboxJWTPublicPath,
-		Issuer:        cfg.SandboxJWTIssuer,
-		Audience:      cfg.SandboxJWTAudience,
-		ClockSkew:     cfg.SandboxJWTClockSkew,
-	})
+	verifier, err := buildVerifier(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("init sandbox token verifier failed: %w", err)
 	}
 
+	// 最小 token 版本由网关签发的 token 携带，沙箱只在进程内记录，不访问网关的会话存储
+	revocations := utils.NewTokenVersionWatermark()
+
+	auditSink, err := audit.NewSink(cfg.AuditLogPath)
+	if err != nil {
+		return nil, fmt.Errorf("init audit sink failed: %w", err)
+	}
+
 	api := r.Group("/api")
-	api.Use(middleware.SandboxAuth(verifier))
-	handlers.InitCodeInterpreterApi(api)
-	handlers.InitFSApi(api, cfg.WorkspaceRoot, cfg.MaxFileBytes)
-	handlers.InitProxyApi(api, handlers.ProxyOptions{})
+	api.Use(middleware.SandboxAuth(verifier, revocations))
+	// 后台 GC 与进程同生命周期，由 Serve 退出时的 Shutdown 停止
+	codeInterpreter := handlers.InitCodeInterpreterApi(context.Background(), api, handlers.ContextManagerConfig{
+		MaxCount:           cfg.ContextMaxCount,
+		IdleTTL:            cfg.ContextIdleTTL,
+		GCInterval:         cfg.ContextGCInterval,
+		DefaultTimeoutMs:   cfg.ContextDefaultTimeoutMs,
+		HistorySize:        cfg.ContextHistorySize,
+		MaxRichOutputBytes: cfg.MaxRichOutputBytes,
+		MaxOutputBytes:     cfg.MaxOutputBytes,
+		EnvAllowlist:       cfg.ContextEnvAllowlist,
+		WorkspaceRoot:      cfg.WorkspaceRoot,
+	}, audit.NewRecorder(auditSink, cfg.AuditIncludeCode))
+	s.codeInterpreter = codeInterpreter
+	handlers.InitFSApi(api, cfg.WorkspaceRoot, cfg.MaxFileBytes, cfg.MaxArchiveBytes, cfg.MaxWorkspaceBytes, cfg.MaxUploadTotalBytes)
+	handlers.InitProxyApi(api, handlers.ProxyOptions{ReadyTimeout: cfg.ProxyReadyTimeout})
+	handlers.InitTokenApi(api)
 
 	s.httpServer = &http.Server{
 		Addr:              ":" + cfg.Port,
@@ -47,23 +89,130 @@ func NewServer(cfg *config.Config) (*Server, error) {
 		ReadHeaderTimeout: 10 * time.Second,
 	}
 
+	if port := strings.TrimSpace(cfg.GRPCPort); port != "" {
+		lis, err := net.Listen("tcp", ":"+port)
+		if err != nil {
+			return nil, fmt.Errorf("listen grpc port failed: %w", err)
+		}
+		s.grpcListener = lis
+		s.grpcServer = grpc.NewServer(
+			grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+				MinTime:             5 * time.Second,
+				PermitWithoutStream: true,
+			}),
+			grpc.KeepaliveParams(keepalive.ServerParameters{
+				Time:    15 * time.Second,
+				Timeout: 5 * time.Second,
+			}),
+			grpc.ChainUnaryInterceptor(middleware.SandboxAuthUnaryInterceptor(verifier, revocations)),
+			grpc.ChainStreamInterceptor(middleware.SandboxAuthStreamInterceptor(verifier, revocations)),
+		)
+		handlers.RegisterContextService(s.grpcServer, codeInterpreter)
+	}
+
+	if port := strings.TrimSpace(cfg.MetricsPort); port != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		s.metricsServer = &http.Server{
+			Addr:              ":" + port,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+	}
+
 	return s, nil
 }
 
 func (s *Server) Serve(ctx context.Context) error {
+	shutdownDone := make(chan struct{})
 	go func() {
+		defer close(shutdownDone)
 		<-ctx.Done()
+		s.prober.SetDraining()
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 		if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
 			zap.L().Error("Korokd server shutdown error", zap.Error(err))
 		}
+		if s.metricsServer != nil {
+			if err := s.metricsServer.Shutdown(shutdownCtx); err != nil {
+				zap.L().Error("Korokd metrics server shutdown error", zap.Error(err))
+			}
+		}
+		if s.grpcServer != nil {
+			s.grpcServer.GracefulStop()
+		}
+		// 请求排空后再回收 kernel 与运行目录，避免 Pod 终止时遗留进程
+		contextsCtx, contextsCancel := context.WithTimeout(context.Background(), contextShutdownTimeout)
+		defer contextsCancel()
+		if err := s.codeInterpreter.Shutdown(contextsCtx); err != nil {
+			zap.L().Error("Korokd contexts shutdown error", zap.Error(err))
+		}
 	}()
 
+	if s.grpcServer != nil {
+		go func() {
+			zap.S().Infof("korokd grpc server listening on %s", s.grpcListener.Addr())
+			if err := s.grpcServer.Serve(s.grpcListener); err != nil {
+				zap.L().Error("Korokd grpc server error", zap.Error(err))
+			}
+		}()
+	}
+
+	if s.metricsServer != nil {
+		go func() {
+			zap.S().Infof("korokd metrics server listening on %s", s.metricsServer.Addr)
+			// 指标服务异常不影响主服务
+			if err := s.metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				zap.L().Error("Korokd metrics server error", zap.Error(err))
+			}
+		}()
+	}
+
 	zap.S().Infof("korokd http server listening on %s", s.httpServer.Addr)
-	return s.httpServer.ListenAndServe()
+	err := s.httpServer.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		// Shutdown 会让 ListenAndServe 立即返回，等待收尾完成后再交还给调用方
+		<-shutdownDone
+	}
+	return err
 }
 
 func (s *Server) HealthHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
+
+// checkProcessSpawn 确认仍能创建子进程，执行与安装都依赖该能力
+func checkProcessSpawn(ctx context.Context) error {
+	if err := exec.CommandContext(ctx, "sh", "-c", "exit 0").Run(); err != nil {
+		return fmt.Errorf("spawn probe process failed: %w", err)
+	}
+	return nil
+}
+
+// checkWorkspaceWritable 通过创建并删除临时文件确认工作区可写
+func checkWorkspaceWritable(root string) error {
+	f, err := os.CreateTemp(root, ".agentland-readyz-*")
+	if err != nil {
+		return fmt.Errorf("workspace is not writable: %w", err)
+	}
+	name := f.Name()
+	_ = f.Close()
+	return os.Remove(name)
+}
+
+func buildVerifier(cfg *config.Config) (*utils.Verifier, error) {
+	verifierCfg := utils.VerifierConfig{
+		PublicKeyPath: cfg.SandboxJWTPublicPath,
+		Issuer:        cfg.SandboxJWTIssuer,
+		Audience:      cfg.SandboxJWTAudience,
+		ClockSkew:     cfg.SandboxJWTClockSkew,
+		JWKSCacheTTL:  cfg.SandboxJWTJWKSCacheTTL,
+
+		KeyReloadInterval: cfg.SandboxJWTKeyReloadInterval,
+	}
+	if strings.TrimSpace(cfg.SandboxJWTJWKSURL) != "" {
+		return utils.NewVerifierFromJWKS(cfg.SandboxJWTJWKSURL, verifierCfg)
+	}
+	return utils.NewVerifierFromConfig(verifierCfg)
+}
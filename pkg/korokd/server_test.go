@@ -1,6 +1,9 @@
 package korokd
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -40,3 +43,61 @@ func (s *ServerSuite) TestNewServer() {
 	s.Equal(":1883", server.httpServer.Addr)
 	s.NotNil(server.httpServer.Handler)
 }
+
+func (s *ServerSuite) TestNewServer_MetricsPort() {
+	_, publicPath, err := testutil.WriteTestRSAKeys(s.T().TempDir())
+	s.Require().NoError(err)
+	cfg := &config.Config{
+		Port:                 "1883",
+		MetricsPort:          "9464",
+		SandboxJWTPublicPath: publicPath,
+		SandboxJWTIssuer:     "agentland-gateway",
+		SandboxJWTAudience:   "sandbox",
+	}
+	server, err := NewServer(cfg)
+	s.Require().NoError(err)
+	s.Require().NotNil(server.metricsServer)
+	s.Equal(":9464", server.metricsServer.Addr)
+
+	w := httptest.NewRecorder()
+	server.metricsServer.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	s.Equal(http.StatusOK, w.Code)
+	s.Contains(w.Body.String(), "korokd_active_contexts")
+
+	cfg.MetricsPort = ""
+	server, err = NewServer(cfg)
+	s.Require().NoError(err)
+	s.Nil(server.metricsServer)
+}
+
+func (s *ServerSuite) TestProbes() {
+	_, publicPath, err := testutil.WriteTestRSAKeys(s.T().TempDir())
+	s.Require().NoError(err)
+	cfg := &config.Config{
+		Port:                 "1883",
+		WorkspaceRoot:        s.T().TempDir(),
+		SandboxJWTPublicPath: publicPath,
+		SandboxJWTIssuer:     "agentland-gateway",
+		SandboxJWTAudience:   "sandbox",
+	}
+	server, err := NewServer(cfg)
+	s.Require().NoError(err)
+
+	// 探针不经过 sandbox token 鉴权
+	w := httptest.NewRecorder()
+	server.httpServer.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	s.Equal(http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	server.httpServer.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	s.Equal(http.StatusOK, w.Code)
+	s.JSONEq(`{"status":"ok","checks":{"process":"ok","workspace":"ok"}}`, w.Body.String())
+
+	cfg.WorkspaceRoot = filepath.Join(s.T().TempDir(), "missing")
+	server, err = NewServer(cfg)
+	s.Require().NoError(err)
+	w = httptest.NewRecorder()
+	server.httpServer.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	s.Equal(http.StatusServiceUnavailable, w.Code)
+	s.Contains(w.Body.String(), "workspace is not writable")
+}
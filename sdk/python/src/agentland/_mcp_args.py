@@ -26,6 +26,24 @@ def env_timeout() -> int:
         return DEFAULT_TIMEOUT_SECONDS
 
 
+def parse_tool_names(raw: str | None) -> list[str]:
+    """Split a comma-separated tool list, dropping blanks and duplicates."""
+    names: list[str] = []
+    for part in (raw or "").split(","):
+        name = part.strip()
+        if name and name not in names:
+            names.append(name)
+    return names
+
+
+def env_allowed_tools() -> list[str]:
+    return parse_tool_names(os.getenv("AGENTLAND_MCP_ALLOWED_TOOLS"))
+
+
+def env_denied_tools() -> list[str]:
+    return parse_tool_names(os.getenv("AGENTLAND_MCP_DENIED_TOOLS"))
+
+
 def add_mcp_arguments(
     parser: argparse.ArgumentParser,
     *,
@@ -49,3 +67,15 @@ def add_mcp_arguments(
         default=default_timeout,
         help="HTTP request timeout in seconds.",
     )
+    parser.add_argument(
+        "--allowed-tools",
+        type=parse_tool_names,
+        default=env_allowed_tools(),
+        help="Comma-separated tools to expose; all tools when empty. Defaults to AGENTLAND_MCP_ALLOWED_TOOLS.",
+    )
+    parser.add_argument(
+        "--denied-tools",
+        type=parse_tool_names,
+        default=env_denied_tools(),
+        help="Comma-separated tools to hide, applied after --allowed-tools. Defaults to AGENTLAND_MCP_DENIED_TOOLS.",
+    )
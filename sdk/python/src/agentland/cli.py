@@ -8,10 +8,23 @@ from typing import Sequence
 from ._mcp_args import DEFAULT_TIMEOUT_SECONDS, add_mcp_arguments, env_base_url
 
 
-def _run_mcp(*, transport: str, base_url: str, timeout: int) -> None:
+def _run_mcp(
+    *,
+    transport: str,
+    base_url: str,
+    timeout: int,
+    allowed_tools: Sequence[str] = (),
+    denied_tools: Sequence[str] = (),
+) -> None:
     from .mcp.__main__ import serve_mcp
 
-    serve_mcp(transport=transport, base_url=base_url, timeout=timeout)
+    serve_mcp(
+        transport=transport,
+        base_url=base_url,
+        timeout=timeout,
+        allowed_tools=allowed_tools,
+        denied_tools=denied_tools,
+    )
 
 
 def build_parser() -> argparse.ArgumentParser:
@@ -36,6 +49,8 @@ def main(argv: Sequence[str] | None = None) -> int:
             transport=args.transport,
             base_url=args.base_url,
             timeout=args.timeout,
+            allowed_tools=args.allowed_tools,
+            denied_tools=args.denied_tools,
         )
         return 0
 
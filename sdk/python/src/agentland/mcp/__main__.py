@@ -9,8 +9,20 @@ from .._mcp_args import add_mcp_arguments, env_base_url, env_timeout
 from .server import create_server
 
 
-def serve_mcp(*, transport: str, base_url: str, timeout: int) -> None:
-    mcp = create_server(base_url=base_url, timeout=timeout)
+def serve_mcp(
+    *,
+    transport: str,
+    base_url: str,
+    timeout: int,
+    allowed_tools: Sequence[str] = (),
+    denied_tools: Sequence[str] = (),
+) -> None:
+    mcp = create_server(
+        base_url=base_url,
+        timeout=timeout,
+        allowed_tools=allowed_tools,
+        denied_tools=denied_tools,
+    )
     if transport == "streamable-http":
         mcp.run(transport="streamable-http")
         return
@@ -33,6 +45,8 @@ def main(argv: Sequence[str] | None = None) -> int:
         transport=args.transport,
         base_url=args.base_url,
         timeout=args.timeout,
+        allowed_tools=args.allowed_tools,
+        denied_tools=args.denied_tools,
     )
     return 0
 
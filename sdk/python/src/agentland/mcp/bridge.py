@@ -2,11 +2,49 @@
 
 from __future__ import annotations
 
+import base64
+import binascii
+import functools
+import mimetypes
+import re
 import sys
 from threading import Thread
-from typing import Any
+from typing import Any, Callable, TypeVar
 
-from agentland.sandbox import SDKError, Sandbox
+from agentland.sandbox import SDKError, Sandbox, request_scope
+
+# Archives up to this size are returned inline as base64; larger ones as a download URL.
+MAX_INLINE_ARCHIVE_BYTES = 1024 * 1024
+
+# Kernel tracebacks carry terminal colour codes that only add noise for agents.
+_ANSI_ESCAPE = re.compile(r"\x1b\[[0-9;]*[A-Za-z]")
+# Last traceback line of a Python exception, e.g. "ZeroDivisionError: division by zero".
+_PYTHON_EXCEPTION_LINE = re.compile(r"^([A-Za-z_][\w.]*(?:Error|Exception|Exit|Interrupt|Warning)):?\s?(.*)$")
+
+# Downloaded files up to this size are returned inline as base64; larger ones as a download URL.
+MAX_INLINE_DOWNLOAD_BYTES = 1024 * 1024
+
+
+_ToolMethod = TypeVar("_ToolMethod", bound=Callable[..., Any])
+
+
+def _traced(method: _ToolMethod) -> _ToolMethod:
+    """Run a tool under one request ID and report it as request_id in the result or SDKError."""
+
+    @functools.wraps(method)
+    def wrapper(*args: Any, **kwargs: Any) -> Any:
+        with request_scope() as scope:
+            try:
+                out = method(*args, **kwargs)
+            except SDKError as exc:
+                if exc.request_id is None:
+                    exc.request_id = scope.request_id
+                raise
+        if isinstance(out, dict):
+            out["request_id"] = scope.request_id
+        return out
+
+    return wrapper  # type: ignore[return-value]
 
 
 class CodeInterpreterToolBridge:
@@ -22,6 +60,13 @@ class CodeInterpreterToolBridge:
             raise ValueError("sandbox_id is required")
         return sid
 
+    @staticmethod
+    def _require_context_id(context_id: str) -> str:
+        cid = context_id.strip()
+        if not cid:
+            raise ValueError("context_id is required")
+        return cid
+
     @staticmethod
     def _normalize_language(language: str | None) -> str:
         normalized = (language or "").strip().lower()
@@ -29,10 +74,37 @@ class CodeInterpreterToolBridge:
             return "python"
         return normalized
 
+    def sandbox_info(self) -> dict[str, Any]:
+        info = Sandbox.info()
+        info["max_inline_download_bytes"] = MAX_INLINE_DOWNLOAD_BYTES
+        info["max_inline_archive_bytes"] = MAX_INLINE_ARCHIVE_BYTES
+        return info
+
+    @_traced
     def sandbox_create(self) -> dict[str, Any]:
         sandbox = Sandbox.create()
         return {"sandbox_id": sandbox.sandbox_id}
 
+    @_traced
+    def sandbox_keepalive(self, *, sandbox_id: str) -> dict[str, Any]:
+        sid = self._require_sandbox_id(sandbox_id)
+        return Sandbox.connect(sid).keepalive()
+
+    @_traced
+    def sandbox_delete(self, *, sandbox_id: str) -> dict[str, Any]:
+        sid = self._require_sandbox_id(sandbox_id)
+        try:
+            Sandbox.connect(sid).delete()
+        except SDKError as exc:
+            raise SDKError(
+                f"failed to delete sandbox {sid}: {exc.args[0]}",
+                http_status=exc.http_status,
+                code=exc.code,
+                response_text=exc.response_text,
+            ) from exc
+        return {"sandbox_id": sid, "deleted": True}
+
+    @_traced
     def code_execute(
         self,
         *,
@@ -41,6 +113,7 @@ class CodeInterpreterToolBridge:
         language: str | None = None,
         cwd: str | None = None,
         timeout_ms: int = 0,
+        code_encoding: str = "",
     ) -> dict[str, Any]:
         sid = self._require_sandbox_id(sandbox_id)
         if not code.strip():
@@ -54,16 +127,133 @@ class CodeInterpreterToolBridge:
                 cwd=(cwd or "/workspace"),
             )
             timeout = timeout_ms if timeout_ms > 0 else 30000
-            out = context.exec(code, timeout_ms=timeout)
-            context_id = out.context_id.strip() or context.context_id
-            return {
-                "context_id": context_id,
-                "execution_count": out.execution_count,
-                "exit_code": out.exit_code,
-                "stdout": out.stdout,
-                "stderr": out.stderr,
-                "duration_ms": out.duration_ms,
-            }
+            out = self._exec(context, code, timeout_ms=timeout, code_encoding=code_encoding)
+            return self._execution_output(out, context.context_id)
+        finally:
+            if context is not None:
+                self._delete_context_async(context)
+
+    @staticmethod
+    def _exec(context: Any, code: str, *, timeout_ms: int, code_encoding: str) -> Any:
+        # A failing program still completes with exit_code/stderr; SDKError here means the
+        # code could not be run or its result not delivered (proxy, stream or decode failure).
+        try:
+            return context.exec(code, timeout_ms=timeout_ms, code_encoding=code_encoding)
+        except SDKError as exc:
+            raise SDKError(
+                f"execution infrastructure failure: {exc.args[0]}",
+                http_status=exc.http_status,
+                code=exc.code,
+                response_text=exc.response_text,
+            ) from exc
+
+    @staticmethod
+    def _execution_output(out: Any, context_id: str) -> dict[str, Any]:
+        result = {
+            "context_id": out.context_id.strip() or context_id,
+            "execution_count": out.execution_count,
+            "exit_code": out.exit_code,
+            "stdout": out.stdout,
+            "stderr": out.stderr,
+            "duration_ms": out.duration_ms,
+            "results": out.results,
+            "stdout_truncated": out.stdout_truncated,
+            "stderr_truncated": out.stderr_truncated,
+        }
+        if out.exit_code != 0:
+            result["error"] = _execution_error(out.stderr)
+        return result
+
+    @_traced
+    def context_create(
+        self,
+        *,
+        sandbox_id: str,
+        language: str | None = None,
+        cwd: str | None = None,
+    ) -> dict[str, Any]:
+        sid = self._require_sandbox_id(sandbox_id)
+        context = Sandbox.connect(sid).context.create(
+            language=self._normalize_language(language),
+            cwd=(cwd or "/workspace"),
+        )
+        return {"context_id": context.context_id}
+
+    @_traced
+    def context_execute(
+        self,
+        *,
+        sandbox_id: str,
+        context_id: str,
+        code: str,
+        timeout_ms: int = 0,
+        code_encoding: str = "",
+    ) -> dict[str, Any]:
+        sid = self._require_sandbox_id(sandbox_id)
+        cid = self._require_context_id(context_id)
+        if not code.strip():
+            raise ValueError("code is required")
+        context = Sandbox.connect(sid).context.connect(cid)
+        timeout = timeout_ms if timeout_ms > 0 else 30000
+        out = self._exec(context, code, timeout_ms=timeout, code_encoding=code_encoding)
+        return self._execution_output(out, cid)
+
+    @_traced
+    def context_list(self, *, sandbox_id: str) -> dict[str, Any]:
+        sid = self._require_sandbox_id(sandbox_id)
+        return {"contexts": Sandbox.connect(sid).context.list()}
+
+    @_traced
+    def context_delete(self, *, sandbox_id: str, context_id: str) -> dict[str, Any]:
+        sid = self._require_sandbox_id(sandbox_id)
+        cid = self._require_context_id(context_id)
+        Sandbox.connect(sid).context.connect(cid).delete()
+        return {"context_id": cid, "deleted": True}
+
+    @_traced
+    def code_execute_batch(
+        self,
+        *,
+        sandbox_id: str,
+        cells: list[dict[str, Any] | str],
+        language: str | None = None,
+        cwd: str | None = None,
+        stop_on_error: bool = False,
+    ) -> dict[str, Any]:
+        sid = self._require_sandbox_id(sandbox_id)
+        if not cells:
+            raise ValueError("cells is required")
+
+        sandbox = Sandbox.connect(sid)
+        context = None
+        try:
+            context = sandbox.context.create(
+                language=self._normalize_language(language),
+                cwd=(cwd or "/workspace"),
+            )
+            return context.exec_batch(cells, stop_on_error=stop_on_error)
+        finally:
+            if context is not None:
+                self._delete_context_async(context)
+
+    @_traced
+    def pip_install(
+        self,
+        *,
+        sandbox_id: str,
+        packages: list[str],
+        timeout_ms: int = 0,
+    ) -> dict[str, Any]:
+        sid = self._require_sandbox_id(sandbox_id)
+        if not packages:
+            raise ValueError("packages is required")
+
+        sandbox = Sandbox.connect(sid)
+        context = None
+        try:
+            context = sandbox.context.create(language="python", cwd="/workspace")
+            timeout = timeout_ms if timeout_ms > 0 else 120000
+            return context.install(packages, timeout_ms=timeout)
         finally:
             if context is not None:
                 self._delete_context_async(context)
@@ -86,6 +276,7 @@ class CodeInterpreterToolBridge:
 
         Thread(target=_run, daemon=True).start()
 
+    @_traced
     def fs_tree(
         self,
         *,
@@ -104,19 +295,67 @@ class CodeInterpreterToolBridge:
             kwargs["depth"] = depth
         return sandbox.fs.tree(**kwargs)
 
+    @_traced
+    def fs_stat(self, *, sandbox_id: str, path: str) -> dict[str, Any]:
+        sid = self._require_sandbox_id(sandbox_id)
+        if not path.strip():
+            raise ValueError("path is required")
+        sandbox = Sandbox.connect(sid)
+        return sandbox.fs.stat(path=path.strip())
+
+    @_traced
+    def fs_usage(self, *, sandbox_id: str) -> dict[str, Any]:
+        sid = self._require_sandbox_id(sandbox_id)
+        sandbox = Sandbox.connect(sid)
+        return sandbox.fs.usage()
+
+    @_traced
+    def fs_search(
+        self,
+        *,
+        sandbox_id: str,
+        query: str,
+        path: str = "",
+        glob: str = "",
+        includeHidden: bool = False,
+        maxResults: int = 0,
+    ) -> dict[str, Any]:
+        sid = self._require_sandbox_id(sandbox_id)
+        if not query:
+            raise ValueError("query is required")
+        sandbox = Sandbox.connect(sid)
+        kwargs: dict[str, Any] = {
+            "query": query,
+            "path": path.strip() or ".",
+            "glob": glob.strip(),
+            "include_hidden": includeHidden,
+        }
+        if maxResults > 0:
+            kwargs["max_results"] = maxResults
+        return sandbox.fs.search(**kwargs)
+
+    @_traced
     def fs_file_get(
         self,
         *,
         sandbox_id: str,
         path: str,
         encoding: str = "",
+        start_line: int = 0,
+        end_line: int = 0,
     ) -> dict[str, Any]:
         sid = self._require_sandbox_id(sandbox_id)
         sandbox = Sandbox.connect(sid)
+        kwargs: dict[str, Any] = {"path": path}
         if encoding.strip():
-            return sandbox.fs.read(path=path, encoding=encoding)
-        return sandbox.fs.read(path=path)
+            kwargs["encoding"] = encoding
+        if start_line > 0:
+            kwargs["start_line"] = start_line
+        if end_line > 0:
+            kwargs["end_line"] = end_line
+        return sandbox.fs.read(**kwargs)
 
+    @_traced
     def fs_file_write(
         self,
         *,
@@ -124,9 +363,127 @@ class CodeInterpreterToolBridge:
         path: str,
         content: str,
         encoding: str = "",
+        mode: str = "",
     ) -> dict[str, Any]:
         sid = self._require_sandbox_id(sandbox_id)
         sandbox = Sandbox.connect(sid)
+        kwargs: dict[str, Any] = {"path": path, "content": content}
         if encoding.strip():
-            return sandbox.fs.write(path=path, content=content, encoding=encoding)
-        return sandbox.fs.write(path=path, content=content)
+            kwargs["encoding"] = encoding
+        if mode.strip():
+            kwargs["mode"] = mode.strip()
+        return sandbox.fs.write(**kwargs)
+
+    @_traced
+    def fs_file_download(self, *, sandbox_id: str, path: str) -> dict[str, Any]:
+        sid = self._require_sandbox_id(sandbox_id)
+        remote = path.strip()
+        if not remote:
+            raise ValueError("path is required")
+        sandbox = Sandbox.connect(sid)
+
+        # Check the size first so large files are not pulled through the bridge only to be dropped.
+        stat = sandbox.fs.stat(path=remote)
+        if stat.get("isDir"):
+            raise ValueError("path is a directory; use fs_archive instead")
+        out: dict[str, Any] = {
+            "source_path": remote,
+            "file_name": remote.rstrip("/").rsplit("/", 1)[-1],
+            "mime_type": stat.get("mimeType", ""),
+            "size": int(stat.get("size", 0)),
+        }
+        if out["size"] <= MAX_INLINE_DOWNLOAD_BYTES:
+            downloaded = sandbox.fs.download(path=remote)
+            content = downloaded.pop("content", b"")
+            out.update({k: v for k, v in downloaded.items() if v})
+            # The file may have grown between stat and download.
+            if len(content) <= MAX_INLINE_DOWNLOAD_BYTES:
+                if not out["mime_type"]:
+                    out["mime_type"] = (
+                        mimetypes.guess_type(out["file_name"])[0] or "application/octet-stream"
+                    )
+                out["content_base64"] = base64.b64encode(content).decode("ascii")
+                return out
+
+        out["download_url"] = sandbox.fs.download_url(path=remote)
+        out["download_headers"] = {"x-agentland-session": sid}
+        return out
+
+    @_traced
+    def fs_upload(
+        self,
+        *,
+        sandbox_id: str,
+        target_path: str,
+        content: str,
+        mode: str = "",
+    ) -> dict[str, Any]:
+        sid = self._require_sandbox_id(sandbox_id)
+        target = target_path.strip()
+        if not target:
+            raise ValueError("target_path is required")
+        try:
+            data = base64.b64decode(content, validate=True)
+        except (binascii.Error, ValueError) as exc:
+            raise ValueError(f"content must be valid base64: {exc}") from exc
+        sandbox = Sandbox.connect(sid)
+        return sandbox.fs.upload_bytes(data, target, mode=mode.strip())
+
+    @_traced
+    def fs_move(
+        self,
+        *,
+        sandbox_id: str,
+        src: str,
+        dst: str,
+    ) -> dict[str, Any]:
+        sid = self._require_sandbox_id(sandbox_id)
+        sandbox = Sandbox.connect(sid)
+        return sandbox.fs.move(src=src, dst=dst)
+
+    @_traced
+    def fs_copy(
+        self,
+        *,
+        sandbox_id: str,
+        src: str,
+        dst: str,
+    ) -> dict[str, Any]:
+        sid = self._require_sandbox_id(sandbox_id)
+        sandbox = Sandbox.connect(sid)
+        return sandbox.fs.copy(src=src, dst=dst)
+
+    @_traced
+    def fs_archive(
+        self,
+        *,
+        sandbox_id: str,
+        path: str,
+        format: str = "zip",
+        includeHidden: bool = False,
+    ) -> dict[str, Any]:
+        sid = self._require_sandbox_id(sandbox_id)
+        sandbox = Sandbox.connect(sid)
+        out = sandbox.fs.archive(path=path, format=format, include_hidden=includeHidden)
+        content = out.pop("content", b"")
+        if len(content) <= MAX_INLINE_ARCHIVE_BYTES:
+            out["content_base64"] = base64.b64encode(content).decode("ascii")
+            return out
+        out["download_url"] = sandbox.fs.archive_url(
+            path=path,
+            format=format,
+            include_hidden=includeHidden,
+        )
+        out["download_headers"] = {"x-agentland-session": sid}
+        return out
+
+
+def _execution_error(stderr: str) -> dict[str, str]:
+    """Describe a failed run as {"name", "message", "traceback"}; name is set for Python exceptions."""
+    traceback = _ANSI_ESCAPE.sub("", stderr).strip()
+    lines = [line.strip() for line in traceback.splitlines() if line.strip()]
+    last = lines[-1] if lines else ""
+    match = _PYTHON_EXCEPTION_LINE.match(last)
+    if match:
+        return {"name": match.group(1), "message": match.group(2), "traceback": traceback}
+    return {"name": "", "message": last, "traceback": traceback}
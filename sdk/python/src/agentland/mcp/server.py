@@ -3,7 +3,8 @@
 from __future__ import annotations
 
 import asyncio
-from typing import TYPE_CHECKING
+import json
+from typing import TYPE_CHECKING, Any, Callable, Sequence
 
 from .bridge import CodeInterpreterToolBridge
 
@@ -19,25 +20,122 @@ def _require_fastmcp() -> type["FastMCP"]:
     return FastMCP
 
 
-def create_server(*, base_url: str, timeout: int = 30) -> "FastMCP":
-    """Create MCP server with tools aligned with gateway MCP."""
+def _download_result(sandbox_id: str, out: dict[str, Any]) -> list[Any]:
+    """Return the file metadata as text plus, when inlined, the bytes as an embedded blob resource."""
+    from mcp.types import BlobResourceContents, EmbeddedResource, TextContent
+
+    blob = out.pop("content_base64", None)
+    blocks: list[Any] = [TextContent(type="text", text=json.dumps(out, ensure_ascii=False))]
+    if blob is not None:
+        blocks.append(
+            EmbeddedResource(
+                type="resource",
+                resource=BlobResourceContents(
+                    uri=f"agentland://sandboxes/{sandbox_id.strip()}/fs/{out['source_path'].lstrip('/')}",
+                    mimeType=out["mime_type"],
+                    blob=blob,
+                ),
+            )
+        )
+    return blocks
+
+
+def _sandbox_guide(info: dict[str, Any], task: str) -> str:
+    timeout = info.get("execute_timeout_ms") or {}
+    max_upload = info.get("max_upload_bytes") or 0
+    lines = [
+        "You have an Agentland code sandbox.",
+        f"- Workspace root: {info.get('workspace_root', '/workspace')}; keep files under it.",
+        f"- Languages: {', '.join(info.get('languages') or ['python'])}.",
+        (
+            f"- Execution timeout_ms defaults to {timeout.get('default')} and must be between "
+            f"{timeout.get('min')} and {timeout.get('max')}."
+        ),
+        f"- Upload limit: {max_upload} bytes." if max_upload else "- Upload size is not limited by the gateway.",
+        (
+            f"- Files up to {info.get('max_inline_download_bytes')} bytes are returned inline by fs_file_download; "
+            "call fs_usage for per-sandbox file and workspace limits."
+        ),
+        "Start with sandbox_create, keep state with context_create/context_execute, and call sandbox_delete when done.",
+    ]
+    if task.strip():
+        lines.append("")
+        lines.append(f"Task: {task.strip()}")
+    return "\n".join(lines)
+
+
+def create_server(
+    *,
+    base_url: str,
+    timeout: int = 30,
+    allowed_tools: Sequence[str] = (),
+    denied_tools: Sequence[str] = (),
+) -> "FastMCP":
+    """Create MCP server with tools aligned with gateway MCP.
+
+    When allowed_tools is non-empty only those tools are registered; denied_tools are
+    then left out, e.g. to hide write/delete tools in read-only deployments. Unknown
+    tool names raise ValueError so a typo does not silently expose a tool.
+    """
     FastMCP = _require_fastmcp()
     mcp = FastMCP(
         "Agentland Code Runner",
         instructions=(
             "Use sandbox_create to create sandbox and keep sandbox_id. "
             "Use code_execute for one-shot execution. "
-            "Use fs_tree/fs_file_get/fs_file_write for filesystem operations."
+            "To keep interpreter state across calls, use context_create once, then context_execute repeatedly, "
+            "and context_delete when finished; context_list shows the contexts that are still open. "
+            "Use code_execute_batch to run several dependent cells in one round-trip. "
+            "Use pip_install to add Python packages to the sandbox. "
+            "Use sandbox_keepalive to keep an idle sandbox from being reclaimed between tool calls. "
+            "Use sandbox_delete when done to release the sandbox. "
+            "Use fs_tree/fs_stat/fs_usage/fs_search/fs_file_get/fs_file_write/fs_file_download/fs_upload/fs_move/fs_copy/fs_archive for filesystem operations."
         ),
     )
     bridge = CodeInterpreterToolBridge(base_url=base_url, timeout=timeout)
+    known_tools: list[str] = []
+
+    def tool() -> Callable[[Callable[..., Any]], Callable[..., Any]]:
+        def register(fn: Callable[..., Any]) -> Callable[..., Any]:
+            name = fn.__name__
+            known_tools.append(name)
+            if (allowed_tools and name not in allowed_tools) or name in denied_tools:
+                return fn
+            return mcp.tool()(fn)
+
+        return register
+
+    @mcp.resource("agentland://sandbox/info", mime_type="application/json")
+    async def sandbox_info() -> str:
+        """Sandbox conventions: workspace root, languages, execute timeout bounds (ms) and size limits (bytes, 0 = unlimited).
 
-    @mcp.tool()
+        Per-sandbox file limits (max_file_bytes, max_workspace_bytes) are reported by fs_usage.
+        """
+        info = await asyncio.to_thread(bridge.sandbox_info)
+        return json.dumps(info, ensure_ascii=False)
+
+    @mcp.prompt()
+    async def sandbox_guide(task: str = "") -> str:
+        """Guide for running a task in an Agentland sandbox using the current gateway limits."""
+        info = await asyncio.to_thread(bridge.sandbox_info)
+        return _sandbox_guide(info, task)
+
+    @tool()
     async def sandbox_create() -> dict:
         """Create a code runner sandbox session."""
         return await asyncio.to_thread(bridge.sandbox_create)
 
-    @mcp.tool()
+    @tool()
+    async def sandbox_keepalive(sandbox_id: str) -> dict:
+        """Extend an idle sandbox session without running code; returns the new expiry."""
+        return await asyncio.to_thread(bridge.sandbox_keepalive, sandbox_id=sandbox_id)
+
+    @tool()
+    async def sandbox_delete(sandbox_id: str) -> dict:
+        """Delete a sandbox session and all of its contexts; returns deleted=true on success."""
+        return await asyncio.to_thread(bridge.sandbox_delete, sandbox_id=sandbox_id)
+
+    @tool()
     async def code_execute(
         sandbox_id: str,
         code: str,
@@ -45,8 +143,15 @@ def create_server(*, base_url: str, timeout: int = 30) -> "FastMCP":
         language: str = "",
         cwd: str = "",
         timeout_ms: int = 0,
+        code_encoding: str = "",
     ) -> dict:
-        """Execute code once in a temporary context that is deleted asynchronously after execution."""
+        """Execute code once in a temporary context that is deleted asynchronously after execution.
+
+        language is one of python (default), bash or node. Set code_encoding to "base64" and pass
+        the base64-encoded UTF-8 source as code to avoid JSON escaping issues with large scripts.
+        Failing code is still a successful result: exit_code is non-zero and error holds the
+        exception name, message and traceback. A tool error means the sandbox could not run the code.
+        """
         return await asyncio.to_thread(
             bridge.code_execute,
             sandbox_id=sandbox_id,
@@ -54,9 +159,106 @@ def create_server(*, base_url: str, timeout: int = 30) -> "FastMCP":
             language=language,
             cwd=cwd,
             timeout_ms=timeout_ms,
+            code_encoding=code_encoding,
+        )
+
+    @tool()
+    async def context_create(
+        sandbox_id: str,
+        *,
+        language: str = "",
+        cwd: str = "",
+    ) -> dict:
+        """Create a persistent execution context and return its context_id.
+
+        Variables, imports and working directory survive across context_execute calls until
+        context_delete is called or the sandbox is deleted. language is one of python (default),
+        bash or node.
+        """
+        return await asyncio.to_thread(
+            bridge.context_create,
+            sandbox_id=sandbox_id,
+            language=language,
+            cwd=cwd,
+        )
+
+    @tool()
+    async def context_execute(
+        sandbox_id: str,
+        context_id: str,
+        code: str,
+        *,
+        timeout_ms: int = 0,
+        code_encoding: str = "",
+    ) -> dict:
+        """Execute code in a context from context_create, keeping its state for later calls.
+
+        The context is not deleted afterwards; call context_delete when done. Set code_encoding to
+        "base64" to pass base64-encoded UTF-8 source as code. As with code_execute, failing code
+        returns a non-zero exit_code and a structured error instead of a tool error.
+        """
+        return await asyncio.to_thread(
+            bridge.context_execute,
+            sandbox_id=sandbox_id,
+            context_id=context_id,
+            code=code,
+            timeout_ms=timeout_ms,
+            code_encoding=code_encoding,
+        )
+
+    @tool()
+    async def context_list(sandbox_id: str) -> dict:
+        """List the open contexts of a sandbox, for example to recover a context_id."""
+        return await asyncio.to_thread(bridge.context_list, sandbox_id=sandbox_id)
+
+    @tool()
+    async def context_delete(sandbox_id: str, context_id: str) -> dict:
+        """Delete a context created by context_create and release its kernel; returns deleted=true on success."""
+        return await asyncio.to_thread(
+            bridge.context_delete,
+            sandbox_id=sandbox_id,
+            context_id=context_id,
+        )
+
+    @tool()
+    async def code_execute_batch(
+        sandbox_id: str,
+        cells: list[dict],
+        *,
+        language: str = "",
+        cwd: str = "",
+        stop_on_error: bool = False,
+    ) -> dict:
+        """Execute ordered code cells serially in one temporary context, sharing state between cells.
+
+        Each cell is {"code": str, "timeout_ms": int (optional)}. With stop_on_error, cells after
+        the first failure are skipped.
+        """
+        return await asyncio.to_thread(
+            bridge.code_execute_batch,
+            sandbox_id=sandbox_id,
+            cells=cells,
+            language=language,
+            cwd=cwd,
+            stop_on_error=stop_on_error,
+        )
+
+    @tool()
+    async def pip_install(
+        sandbox_id: str,
+        packages: list[str],
+        *,
+        timeout_ms: int = 0,
+    ) -> dict:
+        """Install Python packages with pip; installed packages are visible to later python executions."""
+        return await asyncio.to_thread(
+            bridge.pip_install,
+            sandbox_id=sandbox_id,
+            packages=packages,
+            timeout_ms=timeout_ms,
         )
 
-    @mcp.tool()
+    @tool()
     async def fs_tree(
         sandbox_id: str,
         *,
@@ -73,36 +275,165 @@ def create_server(*, base_url: str, timeout: int = 30) -> "FastMCP":
             includeHidden=includeHidden,
         )
 
-    @mcp.tool()
+    @tool()
+    async def fs_stat(sandbox_id: str, path: str) -> dict:
+        """Get file or directory metadata (size, mode, modTime, isDir, isSymlink, mimeType) without reading content."""
+        return await asyncio.to_thread(
+            bridge.fs_stat,
+            sandbox_id=sandbox_id,
+            path=path,
+        )
+
+    @tool()
+    async def fs_usage(sandbox_id: str) -> dict:
+        """Report workspace disk usage (total_bytes, file_count) with max_file_bytes and max_workspace_bytes (0 = unlimited)."""
+        return await asyncio.to_thread(
+            bridge.fs_usage,
+            sandbox_id=sandbox_id,
+        )
+
+    @tool()
+    async def fs_search(
+        sandbox_id: str,
+        query: str,
+        *,
+        path: str = "",
+        glob: str = "",
+        includeHidden: bool = False,
+        maxResults: int = 0,
+    ) -> dict:
+        """Search text files under a path for a literal string; returns path, line number and line text."""
+        return await asyncio.to_thread(
+            bridge.fs_search,
+            sandbox_id=sandbox_id,
+            query=query,
+            path=path,
+            glob=glob,
+            includeHidden=includeHidden,
+            maxResults=maxResults,
+        )
+
+    @tool()
     async def fs_file_get(
         sandbox_id: str,
         path: str,
         *,
         encoding: str = "",
+        start_line: int = 0,
+        end_line: int = 0,
     ) -> dict:
-        """Read file content with utf8 or base64 encoding."""
+        """Read file content with utf8 or base64 encoding; content_type hints the file's MIME type.
+
+        Set start_line/end_line (1-based, inclusive, utf8 only) to read just that line range;
+        the result then includes total_lines.
+        """
         return await asyncio.to_thread(
             bridge.fs_file_get,
             sandbox_id=sandbox_id,
             path=path,
             encoding=encoding,
+            start_line=start_line,
+            end_line=end_line,
         )
 
-    @mcp.tool()
+    @tool()
     async def fs_file_write(
         sandbox_id: str,
         path: str,
         content: str,
         *,
         encoding: str = "",
+        mode: str = "",
     ) -> dict:
-        """Write file content with utf8 or base64 encoding."""
+        """Write file content with utf8 or base64 encoding; mode is optional octal permission bits such as 0755."""
         return await asyncio.to_thread(
             bridge.fs_file_write,
             sandbox_id=sandbox_id,
             path=path,
             content=content,
             encoding=encoding,
+            mode=mode,
+        )
+
+    @tool()
+    async def fs_file_download(sandbox_id: str, path: str) -> list:
+        """Download a file, including binary artifacts, as an embedded blob resource.
+
+        Files up to 1 MiB are returned inline; larger ones as download_url plus required headers.
+        """
+        out = await asyncio.to_thread(
+            bridge.fs_file_download,
+            sandbox_id=sandbox_id,
+            path=path,
+        )
+        return _download_result(sandbox_id, out)
+
+    @tool()
+    async def fs_upload(
+        sandbox_id: str,
+        target_path: str,
+        content: str,
+        *,
+        mode: str = "",
+    ) -> dict:
+        """Upload binary content, given as base64, to target_path; mode is optional octal permission bits such as 0755."""
+        return await asyncio.to_thread(
+            bridge.fs_upload,
+            sandbox_id=sandbox_id,
+            target_path=target_path,
+            content=content,
+            mode=mode,
+        )
+
+    @tool()
+    async def fs_move(
+        sandbox_id: str,
+        src: str,
+        dst: str,
+    ) -> dict:
+        """Move or rename a file or directory; dst must not exist."""
+        return await asyncio.to_thread(
+            bridge.fs_move,
+            sandbox_id=sandbox_id,
+            src=src,
+            dst=dst,
+        )
+
+    @tool()
+    async def fs_copy(
+        sandbox_id: str,
+        src: str,
+        dst: str,
+    ) -> dict:
+        """Copy a file or directory recursively; dst must not exist. Returns copied bytes."""
+        return await asyncio.to_thread(
+            bridge.fs_copy,
+            sandbox_id=sandbox_id,
+            src=src,
+            dst=dst,
+        )
+
+    @tool()
+    async def fs_archive(
+        sandbox_id: str,
+        path: str,
+        *,
+        format: str = "zip",
+        includeHidden: bool = False,
+    ) -> dict:
+        """Archive a directory as zip or targz.
+
+        Small archives are returned as content_base64; larger ones as download_url plus required headers.
+        """
+        return await asyncio.to_thread(
+            bridge.fs_archive,
+            sandbox_id=sandbox_id,
+            path=path,
+            format=format,
+            includeHidden=includeHidden,
         )
 
+    unknown = sorted(set(allowed_tools).union(denied_tools).difference(known_tools))
+    if unknown:
+        raise ValueError(f"unknown MCP tools in allow/deny list: {', '.join(unknown)}")
     return mcp
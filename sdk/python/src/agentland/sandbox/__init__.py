@@ -1,7 +1,16 @@
 """Sandbox SDK exports."""
 
+from ._http import RequestScope, request_scope
 from .errors import SDKError
 from .results import ExecutionResult, ExecutionStreamEvent
 from .sandbox import Context, Sandbox
 
-__all__ = ["Sandbox", "Context", "ExecutionResult", "ExecutionStreamEvent", "SDKError"]
+__all__ = [
+    "Sandbox",
+    "Context",
+    "ExecutionResult",
+    "ExecutionStreamEvent",
+    "SDKError",
+    "RequestScope",
+    "request_scope",
+]
@@ -2,18 +2,68 @@
 
 from __future__ import annotations
 
+import io
 import json
 import mimetypes
 import os
 import urllib.parse
+import uuid
+from contextlib import contextmanager
+from contextvars import ContextVar
 from dataclasses import dataclass
-from typing import IO, Any, Mapping
+from typing import IO, Any, Iterator, Mapping
 
 import httpx
 
 from .errors import SDKError
 
+try:  # OpenTelemetry is optional; without it only the request ID is propagated.
+    from opentelemetry import propagate as _otel_propagate
+except ImportError:  # pragma: no cover
+    _otel_propagate = None
+
 SESSION_HEADER = "x-agentland-session"
+API_KEY_HEADER = "x-agentland-api-key"
+REQUEST_ID_HEADER = "x-agentland-request-id"
+
+
+class RequestScope:
+    """Request ID shared by every gateway call made inside request_scope()."""
+
+    def __init__(self, request_id: str) -> None:
+        self.request_id = request_id
+
+
+_current_scope: ContextVar[RequestScope | None] = ContextVar("agentland_request_scope", default=None)
+
+
+@contextmanager
+def request_scope(request_id: str | None = None) -> Iterator[RequestScope]:
+    """Send one request ID (generated when omitted) and the active trace context on all calls in the block.
+
+    scope.request_id is updated to the ID echoed by the gateway.
+    """
+    scope = RequestScope((request_id or "").strip() or uuid.uuid4().hex)
+    token = _current_scope.set(scope)
+    try:
+        yield scope
+    finally:
+        _current_scope.reset(token)
+
+
+def _inject_request_headers(headers: dict[str, str]) -> None:
+    scope = _current_scope.get()
+    if scope is not None:
+        headers[REQUEST_ID_HEADER] = scope.request_id
+    if _otel_propagate is not None:
+        _otel_propagate.inject(headers)
+
+
+def _record_response_request_id(headers: Mapping[str, str]) -> None:
+    scope = _current_scope.get()
+    request_id = headers.get(REQUEST_ID_HEADER) if headers else None
+    if scope is not None and request_id:
+        scope.request_id = request_id
 
 
 @dataclass(slots=True)
@@ -35,12 +85,12 @@ def _decode_json_bytes(raw: bytes) -> Any:
         raise SDKError("response is not valid JSON", response_text=text) from exc
 
 
-def _extract_error_message(data: Any, fallback: str) -> tuple[str, int | None]:
+def _extract_error_message(data: Any, fallback: str) -> tuple[str, int | str | None]:
     if isinstance(data, dict):
         code = data.get("code")
         if isinstance(code, bool):
             code = None
-        if not isinstance(code, int):
+        if not isinstance(code, (int, str)):
             code = None
         msg = data.get("msg") or data.get("error") or fallback
         if not isinstance(msg, str):
@@ -50,12 +100,13 @@ def _extract_error_message(data: Any, fallback: str) -> tuple[str, int | None]:
 
 
 class _HTTPClient:
-    def __init__(self, *, base_url: str, timeout: int) -> None:
+    def __init__(self, *, base_url: str, timeout: int, api_key: str | None = None) -> None:
         normalized = base_url.strip().rstrip("/")
         if not normalized:
             raise SDKError("base_url is required")
         self.base_url = normalized
         self.timeout = timeout
+        self.api_key = api_key
 
     def _build_url(self, path: str, query: dict[str, Any] | None = None) -> str:
         url = f"{self.base_url}{path}"
@@ -97,8 +148,11 @@ class _HTTPClient:
         files: dict[str, tuple[str, IO[bytes], str]] | None = None,
     ) -> _Response:
         request_headers = {} if headers is None else dict(headers)
+        if self.api_key:
+            request_headers[API_KEY_HEADER] = self.api_key
         if session_id:
             request_headers[SESSION_HEADER] = session_id
+        _inject_request_headers(request_headers)
         try:
             resp = httpx.request(
                 method,
@@ -112,6 +166,7 @@ class _HTTPClient:
         except httpx.RequestError as exc:
             raise SDKError(f"http request failed: {exc}") from exc
 
+        _record_response_request_id(resp.headers)
         if resp.status_code >= 400:
             text = resp.text
             parsed = None
@@ -191,6 +246,9 @@ class _HTTPClient:
             headers["Content-Type"] = "application/json"
 
         headers[SESSION_HEADER] = session_id
+        if self.api_key:
+            headers[API_KEY_HEADER] = self.api_key
+        _inject_request_headers(headers)
 
         timeout = httpx.Timeout(
             connect=self.timeout,
@@ -207,6 +265,7 @@ class _HTTPClient:
                 json=json_body,
                 timeout=timeout,
             ) as resp:
+                _record_response_request_id(resp.headers)
                 if resp.status_code >= 400:
                     raw = resp.read()
                     text = raw.decode("utf-8", errors="replace")
@@ -249,15 +308,72 @@ class _HTTPClient:
         session_id: str,
         local_file: str,
         target_file_path: str,
+        mode: str = "",
+    ) -> dict[str, Any]:
+        with open(local_file, "rb") as fh:
+            return self._upload(
+                session_id=session_id,
+                file_name=os.path.basename(local_file),
+                fh=fh,
+                target_file_path=target_file_path,
+                mode=mode,
+            )
+
+    def upload_bytes(
+        self,
+        *,
+        session_id: str,
+        content: bytes,
+        file_name: str,
+        target_file_path: str,
+        mode: str = "",
+    ) -> dict[str, Any]:
+        return self._upload(
+            session_id=session_id,
+            file_name=file_name,
+            fh=io.BytesIO(content),
+            target_file_path=target_file_path,
+            mode=mode,
+        )
+
+    def _upload(
+        self,
+        *,
+        session_id: str,
+        file_name: str,
+        fh: IO[bytes],
+        target_file_path: str,
+        mode: str,
+    ) -> dict[str, Any]:
+        guessed_type = mimetypes.guess_type(file_name)[0] or "application/octet-stream"
+        form_data = {"target_file_path": target_file_path}
+        if mode:
+            form_data["mode"] = mode
+        resp = self._dispatch(
+            "POST",
+            "/api/code-runner/fs/upload",
+            session_id=session_id,
+            form_data=form_data,
+            files={"file": (file_name, fh, guessed_type)},
+        )
+        payload = _decode_json_bytes(resp.body)
+        return self._unwrap_json_result(payload)
+
+    def extract_archive(
+        self,
+        *,
+        session_id: str,
+        local_file: str,
+        target_dir: str,
     ) -> dict[str, Any]:
         file_name = os.path.basename(local_file)
         guessed_type = mimetypes.guess_type(file_name)[0] or "application/octet-stream"
         with open(local_file, "rb") as fh:
             resp = self._dispatch(
                 "POST",
-                "/api/code-runner/fs/upload",
+                "/api/code-runner/fs/extract",
                 session_id=session_id,
-                form_data={"target_file_path": target_file_path},
+                form_data={"target_dir": target_dir},
                 files={"file": (file_name, fh, guessed_type)},
             )
         payload = _decode_json_bytes(resp.body)
@@ -275,3 +391,35 @@ class _HTTPClient:
             session_id=session_id,
             query={"path": remote_path},
         )
+
+    def download_archive(
+        self,
+        *,
+        session_id: str,
+        remote_path: str,
+        archive_format: str,
+        include_hidden: bool,
+    ) -> _Response:
+        return self._request(
+            "GET",
+            "/api/code-runner/fs/archive",
+            session_id=session_id,
+            query=self.archive_query(remote_path, archive_format, include_hidden),
+        )
+
+    def download_url(self, remote_path: str) -> str:
+        return self._build_url("/api/code-runner/fs/download", {"path": remote_path})
+
+    def archive_url(self, remote_path: str, archive_format: str, include_hidden: bool) -> str:
+        return self._build_url(
+            "/api/code-runner/fs/archive",
+            self.archive_query(remote_path, archive_format, include_hidden),
+        )
+
+    @staticmethod
+    def archive_query(remote_path: str, archive_format: str, include_hidden: bool) -> dict[str, Any]:
+        return {
+            "path": remote_path,
+            "format": archive_format,
+            "includeHidden": "true" if include_hidden else "false",
+        }
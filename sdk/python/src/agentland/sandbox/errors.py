@@ -4,20 +4,27 @@ from __future__ import annotations
 
 
 class SDKError(Exception):
-    """Represents an HTTP or business-level SDK failure."""
+    """Represents an HTTP or business-level SDK failure.
+
+    ``code`` is the numeric gateway error code, or a machine-readable string such as
+    ``"NOT_FOUND"`` or ``"PATH_ESCAPE"`` for sandbox filesystem errors. ``request_id``
+    is the gateway request ID when the call ran inside ``request_scope()``.
+    """
 
     def __init__(
         self,
         message: str,
         *,
         http_status: int | None = None,
-        code: int | None = None,
+        code: int | str | None = None,
         response_text: str | None = None,
+        request_id: str | None = None,
     ) -> None:
         super().__init__(message)
         self.http_status = http_status
         self.code = code
         self.response_text = response_text
+        self.request_id = request_id
 
     def __str__(self) -> str:
         parts = [super().__str__()]
@@ -25,5 +32,7 @@ class SDKError(Exception):
             parts.append(f"http_status={self.http_status}")
         if self.code is not None:
             parts.append(f"code={self.code}")
+        if self.request_id is not None:
+            parts.append(f"request_id={self.request_id}")
         return ", ".join(parts)
 
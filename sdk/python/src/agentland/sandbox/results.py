@@ -2,7 +2,7 @@
 
 from __future__ import annotations
 
-from dataclasses import dataclass
+from dataclasses import dataclass, field
 from collections.abc import Mapping
 from typing import Any
 
@@ -26,6 +26,22 @@ def _as_int(value: Any, field_name: str) -> int:
         raise SDKError(f"{field_name} must be an integer") from exc
 
 
+def _as_variables(value: Any, field_name: str) -> dict[str, str]:
+    if value is None:
+        return {}
+    if not isinstance(value, Mapping):
+        raise SDKError(f"{field_name} must be an object")
+    return {str(k): str(v) for k, v in value.items()}
+
+
+def _as_outputs(value: Any, field_name: str) -> list[dict[str, Any]]:
+    if value is None:
+        return []
+    if not isinstance(value, list) or not all(isinstance(v, Mapping) for v in value):
+        raise SDKError(f"{field_name} must be a list of objects")
+    return [dict(v) for v in value]
+
+
 @dataclass(slots=True)
 class ExecutionResult:
     """Structured execution response."""
@@ -36,6 +52,13 @@ class ExecutionResult:
     stdout: str
     stderr: str
     duration_ms: int
+    # Rich outputs (plots, HTML, ...) as {"mime_type", "data" (base64), "truncated"?} dicts.
+    results: list[dict[str, Any]] = field(default_factory=list)
+    # Top-level variable name -> truncated repr(), only when return_vars was requested.
+    variables: dict[str, str] = field(default_factory=dict)
+    # True when the sandbox cut the stream at its output limit.
+    stdout_truncated: bool = False
+    stderr_truncated: bool = False
 
     @classmethod
     def from_payload(cls, payload: Mapping[str, Any]) -> "ExecutionResult":
@@ -48,6 +71,10 @@ class ExecutionResult:
             stdout=_as_str(payload.get("stdout", ""), "stdout"),
             stderr=_as_str(payload.get("stderr", ""), "stderr"),
             duration_ms=_as_int(payload.get("duration_ms", 0), "duration_ms"),
+            results=_as_outputs(payload.get("results"), "results"),
+            variables=_as_variables(payload.get("variables"), "variables"),
+            stdout_truncated=bool(payload.get("stdout_truncated", False)),
+            stderr_truncated=bool(payload.get("stderr_truncated", False)),
         )
 
     def to_dict(self) -> dict[str, Any]:
@@ -58,6 +85,10 @@ class ExecutionResult:
             "stdout": self.stdout,
             "stderr": self.stderr,
             "duration_ms": self.duration_ms,
+            "results": list(self.results),
+            "variables": dict(self.variables),
+            "stdout_truncated": self.stdout_truncated,
+            "stderr_truncated": self.stderr_truncated,
         }
 
 
@@ -72,6 +103,13 @@ class ExecutionStreamEvent:
     execution_count: int | None = None
     execution_time: int | None = None
     exit_code: int | None = None
+    outputs: list[dict[str, Any]] | None = None
+    # Installed {"name", "version"} dicts, only set on "install_complete".
+    packages: list[dict[str, Any]] | None = None
+    variables: dict[str, str] | None = None
+    # Only set on "execution_complete" when output hit the sandbox limit.
+    stdout_truncated: bool = False
+    stderr_truncated: bool = False
     result: ExecutionResult | None = None
     error: str | None = None
 
@@ -102,6 +140,15 @@ class ExecutionStreamEvent:
         exit_code_raw = payload.get("exit_code")
         exit_code = None if exit_code_raw is None else _as_int(exit_code_raw, "exit_code")
 
+        outputs_raw = payload.get("outputs")
+        outputs = None if outputs_raw is None else _as_outputs(outputs_raw, "outputs")
+
+        packages_raw = payload.get("packages")
+        packages = None if packages_raw is None else _as_outputs(packages_raw, "packages")
+
+        variables_raw = payload.get("variables")
+        variables = None if variables_raw is None else _as_variables(variables_raw, "variables")
+
         result_payload = payload.get("result")
         result = None
         if isinstance(result_payload, Mapping):
@@ -118,6 +165,11 @@ class ExecutionStreamEvent:
             execution_count=execution_count,
             execution_time=execution_time,
             exit_code=exit_code,
+            outputs=outputs,
+            packages=packages,
+            variables=variables,
+            stdout_truncated=bool(payload.get("stdout_truncated", False)),
+            stderr_truncated=bool(payload.get("stderr_truncated", False)),
             result=result,
             error=error,
         )
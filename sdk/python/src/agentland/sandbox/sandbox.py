@@ -4,6 +4,7 @@ from __future__ import annotations
 
 import os
 from dataclasses import dataclass
+from email.message import Message
 from typing import Any
 
 from ._http import _HTTPClient
@@ -15,8 +16,8 @@ DEFAULT_TIMEOUT_SECONDS = 30
 
 def _normalize_language(language: str) -> str:
     value = language.strip().lower()
-    if value not in {"python", "bash"}:
-        raise SDKError("language must be 'python' or 'bash'")
+    if value not in {"python", "bash", "node"}:
+        raise SDKError("language must be 'python', 'bash' or 'node'")
     return value
 
 
@@ -37,6 +38,7 @@ def _ensure_non_empty(name: str, value: str) -> str:
 class _SDKConfig:
     base_url: str | None = None
     timeout: int = DEFAULT_TIMEOUT_SECONDS
+    api_key: str | None = None
 
 
 class Sandbox:
@@ -46,9 +48,24 @@ class Sandbox:
 
     @classmethod
     def configure(
-        cls, *, base_url: str, timeout: int = DEFAULT_TIMEOUT_SECONDS
+        cls,
+        *,
+        base_url: str,
+        timeout: int = DEFAULT_TIMEOUT_SECONDS,
+        api_key: str | None = None,
     ) -> None:
-        cls._config = _SDKConfig(base_url=base_url.strip().rstrip("/"), timeout=timeout)
+        """Configure the gateway endpoint.
+
+        ``api_key`` defaults to the ``AGENTLAND_API_KEY`` environment variable
+        and is sent as the gateway client credential when set.
+        """
+        if api_key is None:
+            api_key = os.getenv("AGENTLAND_API_KEY")
+        cls._config = _SDKConfig(
+            base_url=base_url.strip().rstrip("/"),
+            timeout=timeout,
+            api_key=(api_key or "").strip() or None,
+        )
 
     @classmethod
     def _client(cls) -> _HTTPClient:
@@ -56,7 +73,11 @@ class Sandbox:
             raise SDKError(
                 "SDK is not configured. Call Sandbox.configure(base_url=...) first"
             )
-        return _HTTPClient(base_url=cls._config.base_url, timeout=cls._config.timeout)
+        return _HTTPClient(
+            base_url=cls._config.base_url,
+            timeout=cls._config.timeout,
+            api_key=cls._config.api_key,
+        )
 
     @classmethod
     def create(cls) -> Sandbox:
@@ -64,6 +85,11 @@ class Sandbox:
         sandbox_id = _ensure_non_empty("sandbox_id", str(out.get("sandbox_id", "")))
         return cls(sandbox_id=sandbox_id, _client=cls._client())
 
+    @classmethod
+    def info(cls) -> dict[str, Any]:
+        """Return gateway conventions: workspace root, languages, timeout bounds and upload limit."""
+        return cls._client().request_json("GET", "/api/code-runner/info")
+
     @classmethod
     def connect(cls, sandbox_id: str) -> Sandbox:
         # Connect does not call server-side lookup by design.
@@ -78,15 +104,45 @@ class Sandbox:
         self.context = _ContextService(self)
         self.fs = _FSService(self)
 
+    def keepalive(self) -> dict[str, Any]:
+        """Refresh the idle timer without running code; returns the new expires_at."""
+        return self._client_impl.request_json(
+            "POST", f"/api/code-runner/sandboxes/{self.sandbox_id}/keepalive"
+        )
+
+    def delete(self) -> dict[str, Any]:
+        """Delete the sandbox and release all of its contexts."""
+        return self._client_impl.request_json(
+            "DELETE",
+            f"/api/code-runner/sandboxes/{self.sandbox_id}",
+            session_id=self.sandbox_id,
+        )
+
 
 class _ContextService:
     def __init__(self, sandbox: Sandbox) -> None:
         self._sandbox = sandbox
 
-    def create(self, language: str = "python", cwd: str = "/workspace") -> Context:
+    def create(
+        self,
+        language: str = "python",
+        cwd: str = "/workspace",
+        mem_bytes: int | None = None,
+        cpu_millis: int | None = None,
+        env: dict[str, str] | None = None,
+        parallelism: int | None = None,
+    ) -> Context:
         payload: dict[str, Any] = {"language": _normalize_language(language)}
         if cwd.strip():
             payload["cwd"] = cwd.strip()
+        if mem_bytes is not None:
+            payload["mem_bytes"] = mem_bytes
+        if cpu_millis is not None:
+            payload["cpu_millis"] = cpu_millis
+        if env:
+            payload["env"] = dict(env)
+        if parallelism is not None:
+            payload["parallelism"] = parallelism
         out = self._sandbox._client_impl.request_json(
             "POST",
             "/api/code-runner/contexts",
@@ -96,6 +152,21 @@ class _ContextService:
         context_id = _ensure_non_empty("context_id", str(out.get("context_id", "")))
         return Context(sandbox=self._sandbox, context_id=context_id)
 
+    def connect(self, context_id: str) -> Context:
+        """Attach to an existing context by ID without issuing a request."""
+        return Context(sandbox=self._sandbox, context_id=context_id)
+
+    def list(self) -> list[dict[str, Any]]:
+        out = self._sandbox._client_impl.request_json(
+            "GET",
+            "/api/code-runner/contexts",
+            session_id=self._sandbox.sandbox_id,
+        )
+        contexts = out.get("contexts") or []
+        if not isinstance(contexts, list):
+            raise SDKError("contexts must be a list")
+        return contexts
+
 
 class Context:
     """Represents one execution context inside a sandbox."""
@@ -104,20 +175,40 @@ class Context:
         self._sandbox = sandbox
         self.context_id = _ensure_non_empty("context_id", context_id)
 
-    def exec(self, code: str, timeout_ms: int = 30000) -> ExecutionResult:
+    def exec(
+        self,
+        code: str,
+        timeout_ms: int = 30000,
+        stdin: str | None = None,
+        return_vars: bool = False,
+        check_only: bool = False,
+        code_encoding: str = "",
+        parallel: bool = False,
+    ) -> ExecutionResult:
         stdout_chunks: list[str] = []
         stderr_chunks: list[str] = []
+        outputs: list[dict[str, Any]] = []
         last_execution_count = 0
         last_exit_code = 0
         last_duration_ms = 0
 
-        for evt in self.exec_stream(code, timeout_ms=timeout_ms):
+        for evt in self.exec_stream(
+            code,
+            timeout_ms=timeout_ms,
+            stdin=stdin,
+            return_vars=return_vars,
+            check_only=check_only,
+            code_encoding=code_encoding,
+            parallel=parallel,
+        ):
             if evt.type == "error":
                 raise SDKError(evt.error or "execution failed")
             if evt.type == "stdout" and evt.text:
                 stdout_chunks.append(evt.text)
             if evt.type == "stderr" and evt.text:
                 stderr_chunks.append(evt.text)
+            if evt.type == "display" and evt.outputs:
+                outputs.extend(evt.outputs)
             if (
                 evt.type == "count"
                 and evt.execution_count is not None
@@ -125,6 +216,8 @@ class Context:
             ):
                 last_execution_count = evt.execution_count
             if evt.type == "execution_complete":
+                if evt.execution_count is not None and evt.execution_count > 0:
+                    last_execution_count = evt.execution_count
                 if evt.execution_time is not None and evt.execution_time >= 0:
                     last_duration_ms = evt.execution_time
                 if evt.exit_code is not None:
@@ -136,15 +229,38 @@ class Context:
                     stdout="".join(stdout_chunks),
                     stderr="".join(stderr_chunks),
                     duration_ms=last_duration_ms,
+                    results=outputs,
+                    variables=evt.variables or {},
+                    stdout_truncated=evt.stdout_truncated,
+                    stderr_truncated=evt.stderr_truncated,
                 )
 
         raise SDKError("execution stream ended without an execution_complete event")
 
-    def exec_stream(self, code: str, timeout_ms: int = 30000):
-        payload = {
+    def exec_stream(
+        self,
+        code: str,
+        timeout_ms: int = 30000,
+        stdin: str | None = None,
+        return_vars: bool = False,
+        check_only: bool = False,
+        code_encoding: str = "",
+        parallel: bool = False,
+    ):
+        payload: dict[str, Any] = {
             "code": _ensure_non_empty("code", code),
             "timeout_ms": _ensure_timeout(timeout_ms),
         }
+        if stdin is not None:
+            payload["stdin"] = stdin
+        if return_vars:
+            payload["return_vars"] = True
+        if check_only:
+            payload["check_only"] = True
+        if code_encoding:
+            payload["code_encoding"] = code_encoding
+        if parallel:
+            payload["parallel"] = True
         for raw_evt in self._sandbox._client_impl.stream_sse_json(
             "POST",
             f"/api/code-runner/contexts/{self.context_id}/execute",
@@ -153,6 +269,92 @@ class Context:
         ):
             yield ExecutionStreamEvent.from_payload(raw_evt)
 
+    def exec_batch(
+        self,
+        cells: list[dict[str, Any] | str],
+        stop_on_error: bool = False,
+    ) -> dict[str, Any]:
+        """Run cells serially in this context in one round-trip; kernel state is shared."""
+        payload_cells: list[dict[str, Any]] = []
+        for cell in cells:
+            if isinstance(cell, str):
+                cell = {"code": cell}
+            item: dict[str, Any] = {"code": _ensure_non_empty("code", str(cell.get("code", "")))}
+            timeout_ms = int(cell.get("timeout_ms") or 0)
+            if timeout_ms:
+                item["timeout_ms"] = _ensure_timeout(timeout_ms)
+            payload_cells.append(item)
+        if not payload_cells:
+            raise SDKError("cells is required")
+        payload: dict[str, Any] = {"cells": payload_cells}
+        if stop_on_error:
+            payload["stop_on_error"] = True
+        return self._sandbox._client_impl.request_json(
+            "POST",
+            f"/api/code-runner/contexts/{self.context_id}/execute-batch",
+            session_id=self._sandbox.sandbox_id,
+            json_body=payload,
+        )
+
+    def install(self, packages: list[str], timeout_ms: int = 120000) -> dict[str, Any]:
+        """pip install packages into the sandbox's Python environment."""
+        stdout_chunks: list[str] = []
+        stderr_chunks: list[str] = []
+        for evt in self.install_stream(packages, timeout_ms=timeout_ms):
+            if evt.type == "error":
+                raise SDKError(evt.error or "install failed")
+            if evt.type == "stdout" and evt.text:
+                stdout_chunks.append(evt.text)
+            if evt.type == "stderr" and evt.text:
+                stderr_chunks.append(evt.text)
+            if evt.type == "install_complete":
+                return {
+                    "context_id": self.context_id,
+                    "exit_code": evt.exit_code or 0,
+                    "stdout": "".join(stdout_chunks),
+                    "stderr": "".join(stderr_chunks),
+                    "duration_ms": evt.execution_time or 0,
+                    "packages": evt.packages or [],
+                }
+
+        raise SDKError("install stream ended without an install_complete event")
+
+    def install_stream(self, packages: list[str], timeout_ms: int = 120000):
+        cleaned = [_ensure_non_empty("package", pkg) for pkg in packages]
+        if not cleaned:
+            raise SDKError("packages is required")
+        payload = {"packages": cleaned, "timeout_ms": _ensure_timeout(timeout_ms)}
+        for raw_evt in self._sandbox._client_impl.stream_sse_json(
+            "POST",
+            f"/api/code-runner/contexts/{self.context_id}/install",
+            session_id=self._sandbox.sandbox_id,
+            json_body=payload,
+        ):
+            yield ExecutionStreamEvent.from_payload(raw_evt)
+
+    def interrupt(self) -> dict[str, Any]:
+        return self._sandbox._client_impl.request_json(
+            "POST",
+            f"/api/code-runner/contexts/{self.context_id}/interrupt",
+            session_id=self._sandbox.sandbox_id,
+        )
+
+    def reset(self, force: bool = False) -> dict[str, Any]:
+        return self._sandbox._client_impl.request_json(
+            "POST",
+            f"/api/code-runner/contexts/{self.context_id}/reset",
+            session_id=self._sandbox.sandbox_id,
+            query={"force": "true"} if force else None,
+        )
+
+    def history(self) -> dict[str, Any]:
+        """Recent executions since the last reset: code hash/preview, exit code and duration."""
+        return self._sandbox._client_impl.request_json(
+            "GET",
+            f"/api/code-runner/contexts/{self.context_id}/history",
+            session_id=self._sandbox.sandbox_id,
+        )
+
     def delete(self) -> dict[str, Any]:
         return self._sandbox._client_impl.request_json(
             "DELETE",
@@ -184,21 +386,93 @@ class _FSService:
             },
         )
 
-    def read(self, path: str, encoding: str = "utf8") -> dict[str, Any]:
+    def read(
+        self,
+        path: str,
+        encoding: str = "utf8",
+        start_line: int | None = None,
+        end_line: int | None = None,
+    ) -> dict[str, Any]:
+        """Read a file; start_line/end_line (1-based, inclusive) return only that line range plus total_lines."""
         clean_path = _ensure_non_empty("path", path)
+        if start_line is not None and start_line < 1:
+            raise SDKError("start_line must be >= 1")
+        if end_line is not None and end_line < (start_line or 1):
+            raise SDKError("end_line must be >= start_line")
         return self._sandbox._client_impl.request_json(
             "GET",
             "/api/code-runner/fs/file",
             session_id=self._sandbox.sandbox_id,
-            query={"path": clean_path, "encoding": encoding},
+            query={
+                "path": clean_path,
+                "encoding": encoding,
+                "start_line": start_line,
+                "end_line": end_line,
+            },
+        )
+
+    def stat(self, path: str) -> dict[str, Any]:
+        clean_path = _ensure_non_empty("path", path)
+        return self._sandbox._client_impl.request_json(
+            "GET",
+            "/api/code-runner/fs/stat",
+            session_id=self._sandbox.sandbox_id,
+            query={"path": clean_path},
+        )
+
+    def usage(self) -> dict[str, Any]:
+        """Report workspace disk usage plus the configured file size limit and quota."""
+        return self._sandbox._client_impl.request_json(
+            "GET",
+            "/api/code-runner/fs/usage",
+            session_id=self._sandbox.sandbox_id,
+        )
+
+    def search(
+        self,
+        query: str,
+        path: str = ".",
+        glob: str = "",
+        depth: int = 20,
+        include_hidden: bool = False,
+        max_results: int = 100,
+    ) -> dict[str, Any]:
+        if not query:
+            raise SDKError("query cannot be empty")
+        if depth < 1 or depth > 20:
+            raise SDKError("depth must be between 1 and 20")
+        if max_results < 1 or max_results > 1000:
+            raise SDKError("max_results must be between 1 and 1000")
+        params: dict[str, Any] = {
+            "q": query,
+            "path": path,
+            "depth": depth,
+            "includeHidden": "true" if include_hidden else "false",
+            "maxResults": max_results,
+        }
+        if glob:
+            params["glob"] = glob
+        return self._sandbox._client_impl.request_json(
+            "GET",
+            "/api/code-runner/fs/search",
+            session_id=self._sandbox.sandbox_id,
+            query=params,
         )
 
-    def write(self, path: str, content: str, encoding: str = "utf8") -> dict[str, Any]:
+    def write(
+        self,
+        path: str,
+        content: str,
+        encoding: str = "utf8",
+        mode: str = "",
+    ) -> dict[str, Any]:
         payload = {
             "path": _ensure_non_empty("path", path),
             "content": content,
             "encoding": encoding,
         }
+        if mode:
+            payload["mode"] = _normalize_file_mode(mode)
         return self._sandbox._client_impl.request_json(
             "POST",
             "/api/code-runner/fs/file",
@@ -206,7 +480,31 @@ class _FSService:
             json_body=payload,
         )
 
-    def upload(self, file: str, target_file_path: str) -> dict[str, Any]:
+    def move(self, src: str, dst: str) -> dict[str, Any]:
+        payload = {
+            "src": _ensure_non_empty("src", src),
+            "dst": _ensure_non_empty("dst", dst),
+        }
+        return self._sandbox._client_impl.request_json(
+            "POST",
+            "/api/code-runner/fs/move",
+            session_id=self._sandbox.sandbox_id,
+            json_body=payload,
+        )
+
+    def copy(self, src: str, dst: str) -> dict[str, Any]:
+        payload = {
+            "src": _ensure_non_empty("src", src),
+            "dst": _ensure_non_empty("dst", dst),
+        }
+        return self._sandbox._client_impl.request_json(
+            "POST",
+            "/api/code-runner/fs/copy",
+            session_id=self._sandbox.sandbox_id,
+            json_body=payload,
+        )
+
+    def upload(self, file: str, target_file_path: str, mode: str = "") -> dict[str, Any]:
         local_file = _ensure_non_empty("file", file)
         target = _ensure_non_empty("target_file_path", target_file_path)
         if not os.path.isfile(local_file):
@@ -215,35 +513,146 @@ class _FSService:
             session_id=self._sandbox.sandbox_id,
             local_file=local_file,
             target_file_path=target,
+            mode=_normalize_file_mode(mode) if mode else "",
+        )
+
+    def upload_bytes(
+        self, content: bytes, target_file_path: str, mode: str = ""
+    ) -> dict[str, Any]:
+        """Upload in-memory bytes to target_file_path without a local file."""
+        target = _ensure_non_empty("target_file_path", target_file_path)
+        return self._sandbox._client_impl.upload_bytes(
+            session_id=self._sandbox.sandbox_id,
+            content=content,
+            file_name=os.path.basename(target.rstrip("/")) or "upload",
+            target_file_path=target,
+            mode=_normalize_file_mode(mode) if mode else "",
+        )
+
+    def extract(self, file: str, target_dir: str) -> dict[str, Any]:
+        """Upload a local zip/tar.gz archive and unpack it under target_dir in the sandbox."""
+        local_file = _ensure_non_empty("file", file)
+        target = _ensure_non_empty("target_dir", target_dir)
+        if not os.path.isfile(local_file):
+            raise SDKError(f"file does not exist: {local_file}")
+        return self._sandbox._client_impl.extract_archive(
+            session_id=self._sandbox.sandbox_id,
+            local_file=local_file,
+            target_dir=target,
         )
 
-    def download(self, path: str, save_path: str) -> dict[str, Any]:
+    def download(self, path: str, save_path: str | None = None) -> dict[str, Any]:
+        """Download a single file.
+
+        When save_path is given the file is written there; otherwise the raw
+        bytes are returned under the "content" key.
+        """
         remote = _ensure_non_empty("path", path)
-        local = _ensure_non_empty("save_path", save_path)
         resp = self._sandbox._client_impl.download_file(
             session_id=self._sandbox.sandbox_id,
             remote_path=remote,
         )
 
+        out: dict[str, Any] = {
+            "source_path": resp.headers.get("X-Agentland-File-Path", remote),
+            "file_name": _attachment_file_name(resp.headers),
+            "size": len(resp.body),
+        }
+        if save_path is None:
+            out["content"] = resp.body
+            return out
+
+        local = _ensure_non_empty("save_path", save_path)
         parent = os.path.dirname(local)
         if parent:
             os.makedirs(parent, exist_ok=True)
         with open(local, "wb") as fh:
             fh.write(resp.body)
+        out["save_path"] = local
+        if not out["file_name"]:
+            out["file_name"] = os.path.basename(local)
+        return out
+
+    def download_url(self, path: str) -> str:
+        """Return the gateway URL of a file download; requests must carry the x-agentland-session header."""
+        return self._sandbox._client_impl.download_url(_ensure_non_empty("path", path))
+
+    def archive(
+        self,
+        path: str,
+        save_path: str | None = None,
+        format: str = "zip",
+        include_hidden: bool = False,
+    ) -> dict[str, Any]:
+        """Download a directory as a zip/targz archive.
 
-        file_name = ""
-        content_disposition = resp.headers.get("Content-Disposition", "")
-        marker = "filename="
-        if marker in content_disposition:
-            raw_name = content_disposition.split(marker, 1)[1].strip()
-            file_name = raw_name.strip('"')
-        if not file_name:
-            file_name = os.path.basename(local)
-
-        source_path = resp.headers.get("X-Agentland-File-Path", remote)
-        return {
-            "source_path": source_path,
-            "save_path": local,
-            "file_name": file_name,
+        When save_path is given the archive is written there; otherwise the raw
+        bytes are returned under the "content" key.
+        """
+        remote = _ensure_non_empty("path", path)
+        archive_format = _normalize_archive_format(format)
+        resp = self._sandbox._client_impl.download_archive(
+            session_id=self._sandbox.sandbox_id,
+            remote_path=remote,
+            archive_format=archive_format,
+            include_hidden=include_hidden,
+        )
+
+        out: dict[str, Any] = {
+            "source_path": resp.headers.get("X-Agentland-File-Path", remote),
+            "file_name": _attachment_file_name(resp.headers),
+            "format": archive_format,
             "size": len(resp.body),
         }
+        if save_path is None:
+            out["content"] = resp.body
+            return out
+
+        local = _ensure_non_empty("save_path", save_path)
+        parent = os.path.dirname(local)
+        if parent:
+            os.makedirs(parent, exist_ok=True)
+        with open(local, "wb") as fh:
+            fh.write(resp.body)
+        out["save_path"] = local
+        if not out["file_name"]:
+            out["file_name"] = os.path.basename(local)
+        return out
+
+    def archive_url(self, path: str, format: str = "zip", include_hidden: bool = False) -> str:
+        """Return the gateway URL of a directory archive; requests must carry the x-agentland-session header."""
+        return self._sandbox._client_impl.archive_url(
+            _ensure_non_empty("path", path),
+            _normalize_archive_format(format),
+            include_hidden,
+        )
+
+
+def _normalize_archive_format(archive_format: str) -> str:
+    normalized = (archive_format or "").strip().lower()
+    if normalized in ("", "zip"):
+        return "zip"
+    if normalized in ("targz", "tar.gz", "tgz"):
+        return "targz"
+    raise SDKError("format must be zip or targz")
+
+
+def _normalize_file_mode(mode: str) -> str:
+    normalized = (mode or "").strip()
+    try:
+        parsed = int(normalized, 8)
+    except ValueError:
+        raise SDKError("mode must be an octal string such as 0755") from None
+    if parsed < 0 or parsed > 0o777:
+        raise SDKError("mode must only contain permission bits within 0777")
+    return normalized
+
+
+def _attachment_file_name(headers: Any) -> str:
+    content_disposition = headers.get("Content-Disposition", "")
+    if not content_disposition:
+        return ""
+    # email.message handles quoted-string escapes and RFC 2231 filename* parameters.
+    msg = Message()
+    msg["Content-Disposition"] = content_disposition
+    return os.path.basename(msg.get_filename() or "")
@@ -29,6 +29,8 @@ class CLITests(unittest.TestCase):
             transport="stdio",
             base_url="http://127.0.0.1:18080",
             timeout=40,
+            allowed_tools=[],
+            denied_tools=[],
         )
 
     @mock.patch.dict("os.environ", {"AGENTLAND_BASE_URL": "http://127.0.0.1:19090"}, clear=False)
@@ -40,8 +42,19 @@ class CLITests(unittest.TestCase):
             transport="stdio",
             base_url="http://127.0.0.1:19090",
             timeout=30,
+            allowed_tools=[],
+            denied_tools=[],
         )
 
+    @mock.patch.dict("os.environ", {"AGENTLAND_MCP_DENIED_TOOLS": "fs_file_write"}, clear=False)
+    @mock.patch("agentland.cli._run_mcp")
+    def test_agentland_mcp_tool_filters(self, run_mcp: mock.Mock) -> None:
+        rc = cli.main(["mcp", "--allowed-tools", "fs_tree, fs_file_get,,fs_tree"])
+        self.assertEqual(0, rc)
+        kwargs = run_mcp.call_args.kwargs
+        self.assertEqual(["fs_tree", "fs_file_get"], kwargs["allowed_tools"])
+        self.assertEqual(["fs_file_write"], kwargs["denied_tools"])
+
 
 if __name__ == "__main__":
     unittest.main()
@@ -1,6 +1,8 @@
 from __future__ import annotations
 
+import base64
 import io
+import json
 import sys
 import unittest
 from pathlib import Path
@@ -9,14 +11,48 @@ from unittest import mock
 sys.path.insert(0, str(Path(__file__).resolve().parents[1] / "src"))
 
 from agentland.mcp.bridge import CodeInterpreterToolBridge
-from agentland.sandbox import ExecutionResult
+from agentland.mcp.server import _sandbox_guide
+from agentland.sandbox import ExecutionResult, SDKError
+
+
+class _FakeResponse:
+    def __init__(
+        self, *, status_code: int, body: bytes, headers: dict[str, str] | None = None
+    ) -> None:
+        self.status_code = status_code
+        self.content = body
+        self.headers = {} if headers is None else dict(headers)
+        self.text = body.decode("utf-8", errors="replace")
+
+
+class _FakeStream:
+    def __init__(self, *, status_code: int = 200, events: list[dict] | None = None, body: bytes = b"") -> None:
+        self.status_code = status_code
+        self.headers = {"Content-Type": "text/event-stream"}
+        self._lines = ["data: " + json.dumps(evt) for evt in events or []]
+        self._body = body
+
+    def __enter__(self) -> _FakeStream:
+        return self
+
+    def __exit__(self, exc_type, exc, tb):  # type: ignore[no-untyped-def]
+        return False
+
+    def iter_lines(self):  # type: ignore[no-untyped-def]
+        return iter(self._lines)
+
+    def read(self) -> bytes:
+        return self._body
 
 
 class _FakeContext:
     def __init__(self, *, context_id: str = "ctx-1") -> None:
         self.context_id = context_id
 
-    def exec(self, code: str, timeout_ms: int = 30000) -> ExecutionResult:
+    def exec(
+        self, code: str, timeout_ms: int = 30000, code_encoding: str = ""
+    ) -> ExecutionResult:
+        self.executed = (code, code_encoding)
         return ExecutionResult(
             context_id=self.context_id,
             execution_count=1,
@@ -26,11 +62,30 @@ class _FakeContext:
             duration_ms=5,
         )
 
+    def exec_batch(self, cells: list, stop_on_error: bool = False) -> dict:
+        self.batch = (list(cells), stop_on_error)
+        return {
+            "context_id": self.context_id,
+            "results": [{"index": i, "exit_code": 0} for i in range(len(cells))],
+            "stopped": False,
+        }
+
+    def install(self, packages: list[str], timeout_ms: int = 120000) -> dict:
+        self.installed = (list(packages), timeout_ms)
+        return {
+            "context_id": self.context_id,
+            "exit_code": 0,
+            "packages": [{"name": p, "version": "1.0"} for p in packages],
+        }
+
     def delete(self) -> dict:
+        self.deleted = True
         return {"context_id": self.context_id}
 
 
 class _FakeContextService:
+    connected: list[_FakeContext] = []
+
     def __init__(self) -> None:
         self.created = []
         self.ctx = _FakeContext()
@@ -39,8 +94,18 @@ class _FakeContextService:
         self.created.append({"language": language, "cwd": cwd})
         return self.ctx
 
+    def connect(self, context_id: str) -> _FakeContext:
+        ctx = _FakeContext(context_id=context_id)
+        _FakeContextService.connected.append(ctx)
+        return ctx
+
+    def list(self) -> list[dict]:
+        return [{"context_id": ctx.context_id} for ctx in _FakeContextService.connected]
+
 
 class _FakeFSService:
+    archive_content = b"PK"
+
     def __init__(self) -> None:
         self.calls = []
 
@@ -48,6 +113,18 @@ class _FakeFSService:
         self.calls.append(("tree", kwargs))
         return {"root": kwargs.get("path", "."), "nodes": []}
 
+    def stat(self, **kwargs) -> dict:
+        self.calls.append(("stat", kwargs))
+        return {"path": kwargs["path"], "size": 2147483648, "isDir": False}
+
+    def usage(self) -> dict:
+        self.calls.append(("usage", {}))
+        return {"root": "/workspace", "total_bytes": 2048, "file_count": 3, "max_file_bytes": 1048576, "max_workspace_bytes": 0}
+
+    def search(self, **kwargs) -> dict:
+        self.calls.append(("search", kwargs))
+        return {"root": kwargs["path"], "matches": [], "truncated": False}
+
     def read(self, **kwargs) -> dict:
         self.calls.append(("read", kwargs))
         return {
@@ -63,8 +140,30 @@ class _FakeFSService:
             "path": kwargs["path"],
             "size": len(kwargs["content"]),
             "encoding": kwargs.get("encoding", "utf8"),
+            "mode": kwargs.get("mode", "0644"),
         }
 
+    def archive(self, **kwargs) -> dict:
+        self.calls.append(("archive", kwargs))
+        return {
+            "source_path": kwargs["path"],
+            "file_name": "project.zip",
+            "format": kwargs["format"],
+            "size": len(self.archive_content),
+            "content": self.archive_content,
+        }
+
+    def archive_url(self, **kwargs) -> str:
+        return "http://127.0.0.1:8080/api/code-runner/fs/archive?path=" + kwargs["path"]
+
+    def move(self, **kwargs) -> dict:
+        self.calls.append(("move", kwargs))
+        return {"src": kwargs["src"], "dst": kwargs["dst"]}
+
+    def copy(self, **kwargs) -> dict:
+        self.calls.append(("copy", kwargs))
+        return {"src": kwargs["src"], "dst": kwargs["dst"], "bytes": 3}
+
 
 class _FakeSandbox:
     configured = None
@@ -76,6 +175,11 @@ class _FakeSandbox:
         self.sandbox_id = sandbox_id
         self.context = _FakeContextService()
         self.fs = _FakeFSService()
+        self.keepalive_calls = 0
+
+    def keepalive(self) -> dict:
+        self.keepalive_calls += 1
+        return {"sandbox_id": self.sandbox_id, "expires_at": "2026-01-01T00:15:00Z"}
 
     @classmethod
     def configure(cls, *, base_url: str, timeout: int) -> None:
@@ -108,14 +212,263 @@ class MCPBridgeTests(unittest.TestCase):
         _FakeSandbox.create_calls = 0
         _FakeSandbox.connect_calls = []
         _FakeSandbox.last = None
+        _FakeContextService.connected = []
 
     @mock.patch("agentland.mcp.bridge.Sandbox", _FakeSandbox)
     def test_sandbox_create(self) -> None:
         bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
         out = bridge.sandbox_create()
-        self.assertEqual({"sandbox_id": "session-created"}, out)
+        self.assertEqual({"sandbox_id": "session-created", "request_id": mock.ANY}, out)
         self.assertEqual(1, _FakeSandbox.create_calls)
 
+    @mock.patch("agentland.mcp.bridge.Sandbox", _FakeSandbox)
+    def test_sandbox_keepalive(self) -> None:
+        bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
+        out = bridge.sandbox_keepalive(sandbox_id=" session-1 ")
+        self.assertEqual("session-1", out["sandbox_id"])
+        self.assertEqual("2026-01-01T00:15:00Z", out["expires_at"])
+        self.assertEqual(["session-1"], _FakeSandbox.connect_calls)
+        self.assertEqual(1, _FakeSandbox.last.keepalive_calls)
+        with self.assertRaises(ValueError):
+            bridge.sandbox_keepalive(sandbox_id=" ")
+
+    @mock.patch("agentland.sandbox._http.httpx.stream")
+    def test_context_execute_returns_traceback_as_result(self, mock_stream: mock.Mock) -> None:
+        traceback = (
+            "\x1b[0;31m---------------------------------------------------------------------------\x1b[0m\n"
+            "\x1b[0;31mZeroDivisionError\x1b[0m                         Traceback (most recent call last)\n"
+            "Cell \x1b[0;32mIn[1], line 1\x1b[0m\n"
+            "\x1b[0;31mZeroDivisionError\x1b[0m: division by zero\n"
+        )
+        mock_stream.return_value = _FakeStream(
+            events=[
+                {"type": "stderr", "context_id": "ctx-1", "text": traceback},
+                {"type": "execution_complete", "context_id": "ctx-1", "execution_count": 1, "exit_code": 1},
+            ]
+        )
+        bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
+
+        out = bridge.context_execute(sandbox_id="session-1", context_id="ctx-1", code="1/0")
+
+        self.assertEqual(1, out["exit_code"])
+        self.assertEqual(traceback, out["stderr"])
+        self.assertEqual("ZeroDivisionError", out["error"]["name"])
+        self.assertEqual("division by zero", out["error"]["message"])
+        self.assertNotIn("\x1b", out["error"]["traceback"])
+        self.assertTrue(out["error"]["traceback"].endswith("ZeroDivisionError: division by zero"))
+
+        mock_stream.return_value = _FakeStream(
+            events=[{"type": "execution_complete", "context_id": "ctx-1", "execution_count": 2}]
+        )
+        out = bridge.context_execute(sandbox_id="session-1", context_id="ctx-1", code="x = 1")
+        self.assertEqual(0, out["exit_code"])
+        self.assertNotIn("error", out)
+
+    @mock.patch("agentland.sandbox._http.httpx.stream")
+    def test_context_execute_raises_on_infrastructure_failure(self, mock_stream: mock.Mock) -> None:
+        bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
+
+        mock_stream.return_value = _FakeStream(
+            events=[{"type": "error", "context_id": "ctx-1", "error": "kernel died"}]
+        )
+        with self.assertRaises(SDKError) as ctx:
+            bridge.context_execute(sandbox_id="session-1", context_id="ctx-1", code="1/0")
+        self.assertIn("execution infrastructure failure: kernel died", str(ctx.exception))
+
+        mock_stream.return_value = _FakeStream(status_code=502, body=b"sandbox unreachable")
+        with self.assertRaises(SDKError) as ctx:
+            bridge.context_execute(sandbox_id="session-1", context_id="ctx-1", code="1/0")
+        self.assertEqual(502, ctx.exception.http_status)
+        self.assertIn("execution infrastructure failure", str(ctx.exception))
+
+    @mock.patch("agentland.sandbox._http.httpx.request")
+    def test_tool_calls_propagate_request_id_and_trace_context(self, mock_request: mock.Mock) -> None:
+        sent: list[dict] = []
+
+        def _respond(method, url, **kwargs):  # type: ignore[no-untyped-def]
+            sent.append(dict(kwargs["headers"]))
+            if "/fs/stat?" in url:
+                return _FakeResponse(
+                    status_code=200,
+                    body=b'{"code":200,"msg":"success","data":{"path":"a.txt","size":1}}',
+                    headers={"x-agentland-request-id": kwargs["headers"]["x-agentland-request-id"]},
+                )
+            return _FakeResponse(
+                status_code=502,
+                body=b"sandbox unreachable",
+                headers={"x-agentland-request-id": "gw-req-2"},
+            )
+
+        class _Propagator:
+            @staticmethod
+            def inject(carrier):  # type: ignore[no-untyped-def]
+                carrier["traceparent"] = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+
+        mock_request.side_effect = _respond
+        bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
+
+        with mock.patch("agentland.sandbox._http._otel_propagate", _Propagator):
+            out = bridge.fs_stat(sandbox_id="session-1", path="a.txt")
+            self.assertEqual(sent[0]["x-agentland-request-id"], out["request_id"])
+            self.assertEqual(32, len(out["request_id"]))
+            self.assertIn("traceparent", sent[0])
+
+            bridge.fs_stat(sandbox_id="session-1", path="a.txt")
+            self.assertNotEqual(sent[0]["x-agentland-request-id"], sent[1]["x-agentland-request-id"])
+
+            with self.assertRaises(SDKError) as ctx:
+                bridge.fs_usage(sandbox_id="session-1")
+        # The ID echoed by the gateway wins so failures can be looked up in gateway logs.
+        self.assertEqual("gw-req-2", ctx.exception.request_id)
+        self.assertIn("request_id=gw-req-2", str(ctx.exception))
+
+    @mock.patch("agentland.sandbox._http.httpx.request")
+    def test_sandbox_info_resource(self, mock_request: mock.Mock) -> None:
+        gateway_info = {
+            "workspace_root": "/workspace",
+            "languages": ["python", "bash", "node"],
+            "execute_timeout_ms": {"default": 30000, "min": 100, "max": 120000},
+            "max_upload_bytes": 8388608,
+        }
+        mock_request.return_value = _FakeResponse(
+            status_code=200,
+            body=json.dumps({"code": 200, "msg": "success", "data": gateway_info}).encode("utf-8"),
+        )
+        bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
+
+        out = bridge.sandbox_info()
+
+        self.assertEqual(
+            {
+                **gateway_info,
+                "max_inline_download_bytes": 1024 * 1024,
+                "max_inline_archive_bytes": 1024 * 1024,
+            },
+            json.loads(json.dumps(out)),
+        )
+        args = mock_request.call_args
+        self.assertEqual(("GET", "http://127.0.0.1:8080/api/code-runner/info"), args.args)
+        self.assertNotIn("x-agentland-session", args.kwargs["headers"])
+
+        guide = _sandbox_guide(out, " plot a chart ")
+        self.assertIn("between 100 and 120000", guide)
+        self.assertIn("Languages: python, bash, node", guide)
+        self.assertTrue(guide.endswith("Task: plot a chart"))
+
+    @mock.patch("agentland.sandbox._http.httpx.request")
+    def test_sandbox_delete_forwards_session_header(self, mock_request: mock.Mock) -> None:
+        mock_request.return_value = _FakeResponse(
+            status_code=200,
+            body=b'{"code":200,"msg":"success","data":{"sandbox_id":"session-1"}}',
+        )
+        bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
+
+        out = bridge.sandbox_delete(sandbox_id=" session-1 ")
+
+        self.assertEqual({"sandbox_id": "session-1", "deleted": True, "request_id": mock.ANY}, out)
+        args = mock_request.call_args
+        self.assertEqual("DELETE", args.args[0])
+        self.assertEqual("http://127.0.0.1:8080/api/code-runner/sandboxes/session-1", args.args[1])
+        self.assertEqual("session-1", args.kwargs["headers"]["x-agentland-session"])
+
+        mock_request.return_value = _FakeResponse(
+            status_code=500,
+            body=b'{"code":500,"msg":"server error"}',
+        )
+        with self.assertRaises(SDKError) as ctx:
+            bridge.sandbox_delete(sandbox_id="session-1")
+        self.assertIn("failed to delete sandbox session-1", str(ctx.exception))
+        self.assertEqual(500, ctx.exception.http_status)
+
+    @mock.patch("agentland.sandbox._http.httpx.request")
+    def test_fs_file_download_inlines_binary(self, mock_request: mock.Mock) -> None:
+        fixture = b"\x89PNG\r\n\x1a\n" + bytes(range(256))
+
+        def _respond(method, url, **kwargs):  # type: ignore[no-untyped-def]
+            self.assertEqual("session-1", kwargs["headers"]["x-agentland-session"])
+            if "/fs/stat?" in url:
+                stat = {"path": "/workspace/out/plot.png", "size": len(fixture), "mimeType": "image/png"}
+                return _FakeResponse(
+                    status_code=200,
+                    body=json.dumps({"code": 200, "msg": "success", "data": stat}).encode("utf-8"),
+                )
+            self.assertIn("/api/code-runner/fs/download?path=%2Fworkspace%2Fout%2Fplot.png", url)
+            return _FakeResponse(
+                status_code=200,
+                body=fixture,
+                headers={
+                    "Content-Disposition": 'attachment; filename="plot \\"v2\\".png"',
+                    "X-Agentland-File-Path": "/workspace/out/plot.png",
+                },
+            )
+
+        mock_request.side_effect = _respond
+        bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
+
+        out = bridge.fs_file_download(sandbox_id="session-1", path=" /workspace/out/plot.png ")
+        self.assertEqual(fixture, base64.b64decode(out["content_base64"]))
+        self.assertEqual('plot "v2".png', out["file_name"])
+        self.assertEqual("image/png", out["mime_type"])
+        self.assertEqual(len(fixture), out["size"])
+        self.assertEqual("/workspace/out/plot.png", out["source_path"])
+        self.assertNotIn("download_url", out)
+
+        with mock.patch("agentland.mcp.bridge.MAX_INLINE_DOWNLOAD_BYTES", 16):
+            out = bridge.fs_file_download(sandbox_id="session-1", path="/workspace/out/plot.png")
+        self.assertNotIn("content_base64", out)
+        self.assertEqual(
+            "http://127.0.0.1:8080/api/code-runner/fs/download?path=%2Fworkspace%2Fout%2Fplot.png",
+            out["download_url"],
+        )
+        self.assertEqual({"x-agentland-session": "session-1"}, out["download_headers"])
+        # Over the cap only the metadata is fetched; the content is not downloaded.
+        self.assertEqual(3, mock_request.call_count)
+
+    @mock.patch("agentland.sandbox._http.httpx.request")
+    def test_fs_upload_posts_decoded_bytes_as_multipart(self, mock_request: mock.Mock) -> None:
+        fixture = b"\x00\xffbinary\x80payload"
+        captured: dict = {}
+
+        def _respond(method, url, **kwargs):  # type: ignore[no-untyped-def]
+            name, fh, content_type = kwargs["files"]["file"]
+            captured.update(
+                method=method,
+                url=url,
+                session=kwargs["headers"]["x-agentland-session"],
+                data=kwargs["data"],
+                name=name,
+                content=fh.read(),
+                content_type=content_type,
+            )
+            out = {"source_path": name, "target_path": "/workspace/bin/blob.dat", "size": len(fixture)}
+            return _FakeResponse(
+                status_code=200,
+                body=json.dumps({"code": 200, "msg": "success", "data": out}).encode("utf-8"),
+            )
+
+        mock_request.side_effect = _respond
+        bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
+
+        out = bridge.fs_upload(
+            sandbox_id="session-1",
+            target_path=" /workspace/bin/blob.dat ",
+            content=base64.b64encode(fixture).decode("ascii"),
+            mode="0755",
+        )
+
+        self.assertEqual(len(fixture), out["size"])
+        self.assertEqual("POST", captured["method"])
+        self.assertEqual("http://127.0.0.1:8080/api/code-runner/fs/upload", captured["url"])
+        self.assertEqual("session-1", captured["session"])
+        self.assertEqual({"target_file_path": "/workspace/bin/blob.dat", "mode": "0755"}, captured["data"])
+        self.assertEqual("blob.dat", captured["name"])
+        self.assertEqual("application/octet-stream", captured["content_type"])
+        self.assertEqual(fixture, captured["content"])
+
+        with self.assertRaises(ValueError):
+            bridge.fs_upload(sandbox_id="session-1", target_path="/workspace/a", content="not base64!")
+        self.assertEqual(1, mock_request.call_count)
+
     @mock.patch("agentland.mcp.bridge.Sandbox", _FakeSandbox)
     def test_code_execute_and_async_cleanup(self) -> None:
         bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
@@ -136,8 +489,72 @@ class MCPBridgeTests(unittest.TestCase):
 
         self.assertEqual(0, out["exit_code"])
         self.assertEqual("ctx-1", out["context_id"])
+        self.assertEqual([], out["results"])
         self.assertTrue(cleanup_called["ok"])
 
+    @mock.patch("agentland.mcp.bridge.Sandbox", _FakeSandbox)
+    def test_code_execute_forwards_code_encoding(self) -> None:
+        bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
+        script = 'msg = "it\'s \\"quoted\\""\nfor i in range(2):\n    print(msg, i)\n'
+        encoded = base64.b64encode(script.encode("utf-8")).decode("ascii")
+        with mock.patch.object(bridge, "_delete_context_async"):
+            bridge.code_execute(sandbox_id="session-1", code=encoded, code_encoding="base64")
+
+        self.assertEqual((encoded, "base64"), _FakeSandbox.last.context.ctx.executed)
+
+    @mock.patch("agentland.mcp.bridge.Sandbox", _FakeSandbox)
+    def test_context_lifecycle_create_execute_delete(self) -> None:
+        bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
+
+        created = bridge.context_create(sandbox_id="session-1", language="Python")
+        self.assertEqual({"context_id": "ctx-1", "request_id": mock.ANY}, created)
+        self.assertEqual([{"language": "python", "cwd": "/workspace"}], _FakeSandbox.last.context.created)
+
+        out = bridge.context_execute(sandbox_id="session-1", context_id=" ctx-1 ", code="x = 1")
+        self.assertEqual("ctx-1", out["context_id"])
+        self.assertEqual("ok\n", out["stdout"])
+        executed = _FakeContextService.connected[0]
+        self.assertEqual(("x = 1", ""), executed.executed)
+        self.assertFalse(hasattr(executed, "deleted"))
+
+        self.assertEqual(
+            {"contexts": [{"context_id": "ctx-1"}], "request_id": mock.ANY},
+            bridge.context_list(sandbox_id="session-1"),
+        )
+
+        out = bridge.context_delete(sandbox_id="session-1", context_id="ctx-1")
+        self.assertEqual({"context_id": "ctx-1", "deleted": True, "request_id": mock.ANY}, out)
+        self.assertTrue(_FakeContextService.connected[-1].deleted)
+        self.assertEqual(["session-1"] * 4, _FakeSandbox.connect_calls)
+
+        with self.assertRaises(ValueError):
+            bridge.context_execute(sandbox_id="session-1", context_id=" ", code="x")
+
+    @mock.patch("agentland.mcp.bridge.Sandbox", _FakeSandbox)
+    def test_code_execute_batch_uses_single_context(self) -> None:
+        bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
+        cells = [{"code": "x = 41"}, {"code": "print(x + 1)", "timeout_ms": 1000}]
+        with mock.patch.object(bridge, "_delete_context_async") as cleanup:
+            out = bridge.code_execute_batch(sandbox_id="session-1", cells=cells, stop_on_error=True)
+
+        self.assertEqual(2, len(out["results"]))
+        self.assertEqual([{"language": "python", "cwd": "/workspace"}], _FakeSandbox.last.context.created)
+        self.assertEqual((cells, True), _FakeSandbox.last.context.ctx.batch)
+        cleanup.assert_called_once()
+        with self.assertRaises(ValueError):
+            bridge.code_execute_batch(sandbox_id="session-1", cells=[])
+
+    @mock.patch("agentland.mcp.bridge.Sandbox", _FakeSandbox)
+    def test_pip_install_uses_python_context(self) -> None:
+        bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
+        with mock.patch.object(bridge, "_delete_context_async") as cleanup:
+            out = bridge.pip_install(sandbox_id="session-1", packages=["requests"])
+
+        self.assertEqual([{"name": "requests", "version": "1.0"}], out["packages"])
+        self.assertEqual([{"language": "python", "cwd": "/workspace"}], _FakeSandbox.last.context.created)
+        self.assertEqual((["requests"], 120000), _FakeSandbox.last.context.ctx.installed)
+        cleanup.assert_called_once()
+
     @mock.patch("agentland.mcp.bridge.Sandbox", _FakeSandbox)
     def test_fs_tree_optional_depth(self) -> None:
         bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
@@ -148,6 +565,83 @@ class MCPBridgeTests(unittest.TestCase):
         self.assertEqual(True, kwargs["include_hidden"])
         self.assertNotIn("depth", kwargs)
 
+    @mock.patch("agentland.mcp.bridge.Sandbox", _FakeSandbox)
+    def test_fs_search_optional_max_results(self) -> None:
+        bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
+        bridge.fs_search(sandbox_id="session-1", query="TODO", glob=" *.py ")
+        method, kwargs = _FakeSandbox.last.fs.calls[-1]
+        self.assertEqual("search", method)
+        self.assertEqual(".", kwargs["path"])
+        self.assertEqual("*.py", kwargs["glob"])
+        self.assertNotIn("max_results", kwargs)
+
+        with self.assertRaises(ValueError):
+            bridge.fs_search(sandbox_id="session-1", query="")
+
+    @mock.patch("agentland.mcp.bridge.Sandbox", _FakeSandbox)
+    def test_fs_file_write_optional_mode(self) -> None:
+        bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
+        bridge.fs_file_write(sandbox_id="session-1", path="a.txt", content="a")
+        _, kwargs = _FakeSandbox.last.fs.calls[-1]
+        self.assertNotIn("mode", kwargs)
+
+        out = bridge.fs_file_write(sandbox_id="session-1", path="run.sh", content="#!/bin/sh\n", mode=" 0755 ")
+        self.assertEqual("0755", out["mode"])
+
+    @mock.patch("agentland.mcp.bridge.Sandbox", _FakeSandbox)
+    def test_fs_file_get_line_range(self) -> None:
+        bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
+        bridge.fs_file_get(sandbox_id="session-1", path="big.py", start_line=500, end_line=520)
+        self.assertEqual(
+            ("read", {"path": "big.py", "start_line": 500, "end_line": 520}),
+            _FakeSandbox.last.fs.calls[-1],
+        )
+
+        bridge.fs_file_get(sandbox_id="session-1", path="big.py")
+        self.assertEqual(("read", {"path": "big.py"}), _FakeSandbox.last.fs.calls[-1])
+
+    @mock.patch("agentland.mcp.bridge.Sandbox", _FakeSandbox)
+    def test_fs_stat(self) -> None:
+        bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
+        out = bridge.fs_stat(sandbox_id="session-1", path=" data/big.csv ")
+        self.assertEqual(2147483648, out["size"])
+        self.assertEqual(("stat", {"path": "data/big.csv"}), _FakeSandbox.last.fs.calls[-1])
+
+        with self.assertRaises(ValueError):
+            bridge.fs_stat(sandbox_id="session-1", path=" ")
+
+    @mock.patch("agentland.mcp.bridge.Sandbox", _FakeSandbox)
+    def test_fs_usage(self) -> None:
+        bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
+        out = bridge.fs_usage(sandbox_id="session-1")
+        self.assertEqual(2048, out["total_bytes"])
+        self.assertEqual(("usage", {}), _FakeSandbox.last.fs.calls[-1])
+
+    @mock.patch("agentland.mcp.bridge.Sandbox", _FakeSandbox)
+    def test_fs_archive_inline_and_url(self) -> None:
+        bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
+        out = bridge.fs_archive(sandbox_id="session-1", path="project")
+        self.assertEqual("UEs=", out["content_base64"])
+        self.assertNotIn("content", out)
+        self.assertNotIn("download_url", out)
+
+        with mock.patch.object(_FakeFSService, "archive_content", b"x" * (1024 * 1024 + 1)):
+            out = bridge.fs_archive(sandbox_id="session-1", path="project", format="targz")
+        self.assertNotIn("content_base64", out)
+        self.assertIn("/api/code-runner/fs/archive", out["download_url"])
+        self.assertEqual({"x-agentland-session": "session-1"}, out["download_headers"])
+
+    @mock.patch("agentland.mcp.bridge.Sandbox", _FakeSandbox)
+    def test_fs_move_and_copy(self) -> None:
+        bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
+        out = bridge.fs_move(sandbox_id="session-1", src="a.txt", dst="b.txt")
+        self.assertEqual({"src": "a.txt", "dst": "b.txt", "request_id": mock.ANY}, out)
+        self.assertEqual(("move", {"src": "a.txt", "dst": "b.txt"}), _FakeSandbox.last.fs.calls[-1])
+
+        out = bridge.fs_copy(sandbox_id="session-1", src="data", dst="backup")
+        self.assertEqual(3, out["bytes"])
+        self.assertEqual(("copy", {"src": "data", "dst": "backup"}), _FakeSandbox.last.fs.calls[-1])
+
     @mock.patch("agentland.mcp.bridge.Sandbox", _FakeSandbox)
     def test_missing_sandbox_id(self) -> None:
         bridge = CodeInterpreterToolBridge(base_url="http://127.0.0.1:8080", timeout=30)
@@ -0,0 +1,63 @@
+from __future__ import annotations
+
+import sys
+import unittest
+from pathlib import Path
+from unittest import mock
+
+sys.path.insert(0, str(Path(__file__).resolve().parents[1] / "src"))
+
+from agentland.mcp import server
+
+
+class _FakeFastMCP:
+    def __init__(self, name: str, instructions: str = "") -> None:
+        self.name = name
+        self.tools: list[str] = []
+
+    def tool(self):  # type: ignore[no-untyped-def]
+        def register(fn):  # type: ignore[no-untyped-def]
+            self.tools.append(fn.__name__)
+            return fn
+
+        return register
+
+    def resource(self, uri: str, mime_type: str = ""):  # type: ignore[no-untyped-def]
+        return lambda fn: fn
+
+    def prompt(self):  # type: ignore[no-untyped-def]
+        return lambda fn: fn
+
+
+@mock.patch("agentland.mcp.server._require_fastmcp", lambda: _FakeFastMCP)
+class MCPServerTests(unittest.TestCase):
+    def test_all_tools_registered_by_default(self) -> None:
+        mcp = server.create_server(base_url="http://127.0.0.1:8080")
+        self.assertIn("fs_file_write", mcp.tools)
+        self.assertIn("sandbox_delete", mcp.tools)
+        self.assertIn("code_execute", mcp.tools)
+
+    def test_denied_tool_is_not_registered(self) -> None:
+        mcp = server.create_server(
+            base_url="http://127.0.0.1:8080",
+            denied_tools=["fs_file_write", "sandbox_delete"],
+        )
+        self.assertNotIn("fs_file_write", mcp.tools)
+        self.assertNotIn("sandbox_delete", mcp.tools)
+        self.assertIn("fs_file_get", mcp.tools)
+
+    def test_allowlist_limits_registered_tools(self) -> None:
+        mcp = server.create_server(
+            base_url="http://127.0.0.1:8080",
+            allowed_tools=["fs_tree", "fs_file_get", "code_execute"],
+            denied_tools=["code_execute"],
+        )
+        self.assertEqual(["fs_tree", "fs_file_get"], mcp.tools)
+
+    def test_unknown_tool_name_rejected(self) -> None:
+        with self.assertRaisesRegex(ValueError, "fs_write_file"):
+            server.create_server(base_url="http://127.0.0.1:8080", denied_tools=["fs_write_file"])
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -11,6 +11,7 @@ from unittest import mock
 sys.path.insert(0, str(Path(__file__).resolve().parents[1] / "src"))
 
 from agentland.sandbox import ExecutionResult, SDKError, Sandbox
+from agentland.sandbox.sandbox import Context
 
 
 class _FakeResponse:
@@ -69,6 +70,38 @@ class SandboxSDKTest(unittest.TestCase):
         sandbox = Sandbox.create()
         self.assertEqual("session-1", sandbox.sandbox_id)
 
+    @mock.patch("agentland.sandbox._http.httpx.request")
+    def test_api_key_sent_as_client_credential(self, mock_open: mock.Mock) -> None:
+        mock_open.return_value = _FakeResponse(
+            status_code=200,
+            body=json.dumps(
+                {"code": 200, "msg": "success", "data": {"sandbox_id": "session-1"}}
+            ).encode("utf-8"),
+        )
+
+        Sandbox.configure(base_url="http://127.0.0.1:8080", timeout=5, api_key=" key-123 ")
+        Sandbox.create()
+        headers = mock_open.call_args.kwargs["headers"]
+        self.assertEqual("key-123", headers["x-agentland-api-key"])
+
+        with mock.patch.dict(os.environ, {"AGENTLAND_API_KEY": "env-key"}):
+            Sandbox.configure(base_url="http://127.0.0.1:8080", timeout=5)
+        Sandbox.create()
+        headers = mock_open.call_args.kwargs["headers"]
+        self.assertEqual("env-key", headers["x-agentland-api-key"])
+
+    @mock.patch("agentland.sandbox._http.httpx.request")
+    def test_create_sandbox_rejected_without_credentials(self, mock_open: mock.Mock) -> None:
+        mock_open.return_value = _FakeResponse(
+            status_code=401,
+            body=b'{"code":"UNAUTHENTICATED","msg":"missing credentials"}',
+        )
+
+        with self.assertRaises(SDKError) as ctx:
+            Sandbox.create()
+        self.assertEqual(401, ctx.exception.http_status)
+        self.assertEqual("UNAUTHENTICATED", ctx.exception.code)
+
     @mock.patch("agentland.sandbox._http.httpx.request")
     def test_connect_does_not_issue_request(self, mock_open: mock.Mock) -> None:
         sandbox = Sandbox.connect("session-existing")
@@ -90,6 +123,7 @@ class SandboxSDKTest(unittest.TestCase):
                         "data: {\"type\":\"init\",\"timestamp\":1,\"context_id\":\"ctx-1\"}",
                         "data: {\"type\":\"stdout\",\"timestamp\":2,\"context_id\":\"ctx-1\",\"text\":\"ok\\n\"}",
                         "data: {\"type\":\"count\",\"timestamp\":2,\"context_id\":\"ctx-1\",\"execution_count\":1}",
+                        "data: {\"type\":\"display\",\"timestamp\":2,\"context_id\":\"ctx-1\",\"outputs\":[{\"mime_type\":\"image/png\",\"data\":\"iVBO\"}]}",
                         "data: {\"type\":\"execution_complete\",\"timestamp\":3,\"context_id\":\"ctx-1\",\"execution_time\":3,\"exit_code\":0}",
                     ],
                 )
@@ -119,6 +153,9 @@ class SandboxSDKTest(unittest.TestCase):
         self.assertEqual("ok\n", out.stdout)
         self.assertEqual("", out.stderr)
         self.assertEqual(3, out.duration_ms)
+        self.assertEqual([{"mime_type": "image/png", "data": "iVBO"}], out.results)
+        self.assertEqual({}, out.variables)
+        self.assertFalse(out.stdout_truncated)
         with self.assertRaises(TypeError):
             _ = out["stdout"]  # type: ignore[index]
         with self.assertRaises(AttributeError):
@@ -129,6 +166,51 @@ class SandboxSDKTest(unittest.TestCase):
         deleted = ctx.delete()
         self.assertEqual("ctx-1", deleted["context_id"])
 
+    def test_execution_result_truncation_flags(self) -> None:
+        out = ExecutionResult.from_payload(
+            {"context_id": "ctx-1", "stdout": "x" * 8, "stdout_truncated": True}
+        )
+        self.assertTrue(out.stdout_truncated)
+        self.assertFalse(out.stderr_truncated)
+        self.assertTrue(out.to_dict()["stdout_truncated"])
+
+    @mock.patch("agentland.sandbox._http.httpx.request")
+    def test_context_exec_batch(self, mock_open: mock.Mock) -> None:
+        mock_open.return_value = _FakeResponse(
+            status_code=200,
+            body=json.dumps(
+                {
+                    "code": 200,
+                    "msg": "success",
+                    "data": {
+                        "context_id": "ctx-1",
+                        "results": [
+                            {"index": 0, "exit_code": 0, "stdout": ""},
+                            {"index": 1, "exit_code": 0, "stdout": "42\n"},
+                        ],
+                        "stopped": False,
+                    },
+                }
+            ).encode("utf-8"),
+        )
+
+        sandbox = Sandbox.connect("session-1")
+        ctx = Context(sandbox=sandbox, context_id="ctx-1")
+        out = ctx.exec_batch(["x = 41", {"code": "print(x + 1)", "timeout_ms": 1000}], stop_on_error=True)
+
+        self.assertEqual("42\n", out["results"][1]["stdout"])
+        _, kwargs = mock_open.call_args
+        self.assertTrue(mock_open.call_args.args[1].endswith("/api/code-runner/contexts/ctx-1/execute-batch"))
+        self.assertEqual(
+            {
+                "cells": [{"code": "x = 41"}, {"code": "print(x + 1)", "timeout_ms": 1000}],
+                "stop_on_error": True,
+            },
+            json.loads(kwargs["content"]),
+        )
+        with self.assertRaises(SDKError):
+            ctx.exec_batch([])
+
     @mock.patch("agentland.sandbox._http.httpx.request")
     def test_upload_uses_local_path_and_multipart(self, mock_open: mock.Mock) -> None:
         captured_request: dict[str, object] = {}
@@ -177,6 +259,55 @@ class SandboxSDKTest(unittest.TestCase):
         data = kwargs["data"]
         self.assertEqual("/workspace/data.csv", data["target_file_path"])
 
+    @mock.patch("agentland.sandbox._http.httpx.request")
+    def test_upload_and_write_send_optional_mode(self, mock_open: mock.Mock) -> None:
+        mock_open.return_value = _FakeResponse(
+            status_code=200,
+            body=json.dumps(
+                {"code": 200, "msg": "success", "data": {"path": "run.sh", "mode": "0755"}}
+            ).encode("utf-8"),
+        )
+
+        sandbox = Sandbox.connect("session-1")
+        with tempfile.TemporaryDirectory() as td:
+            local_file = os.path.join(td, "run.sh")
+            Path(local_file).write_text("#!/bin/sh\n", encoding="utf-8")
+            sandbox.fs.upload(local_file, "/workspace/run.sh", mode="0755")
+        self.assertEqual(
+            {"target_file_path": "/workspace/run.sh", "mode": "0755"},
+            mock_open.call_args.kwargs["data"],
+        )
+
+        sandbox.fs.write("run.sh", "#!/bin/sh\n", mode="755")
+        self.assertEqual("755", json.loads(mock_open.call_args.kwargs["content"])["mode"])
+
+        with self.assertRaises(SDKError):
+            sandbox.fs.write("run.sh", "x", mode="4755")
+
+    @mock.patch("agentland.sandbox._http.httpx.request")
+    def test_extract_uploads_archive_with_target_dir(self, mock_open: mock.Mock) -> None:
+        mock_open.return_value = _FakeResponse(
+            status_code=200,
+            body=json.dumps(
+                {
+                    "code": 200,
+                    "msg": "success",
+                    "data": {"target_dir": "/workspace/project", "format": "zip", "files": 2, "bytes": 10},
+                }
+            ).encode("utf-8"),
+        )
+
+        sandbox = Sandbox.connect("session-1")
+        with tempfile.TemporaryDirectory() as td:
+            local_file = os.path.join(td, "project.zip")
+            Path(local_file).write_bytes(b"PK")
+            out = sandbox.fs.extract(local_file, "/workspace/project")
+
+        self.assertEqual(2, out["files"])
+        self.assertTrue(mock_open.call_args.args[1].endswith("/api/code-runner/fs/extract"))
+        self.assertEqual({"target_dir": "/workspace/project"}, mock_open.call_args.kwargs["data"])
+        self.assertEqual("project.zip", mock_open.call_args.kwargs["files"]["file"][0])
+
     @mock.patch("agentland.sandbox._http.httpx.request")
     def test_download_saves_local_file(self, mock_open: mock.Mock) -> None:
         mock_open.return_value = _FakeResponse(
@@ -199,6 +330,34 @@ class SandboxSDKTest(unittest.TestCase):
         self.assertEqual("result.csv", out["file_name"])
         self.assertGreater(out["size"], 0)
 
+    @mock.patch("agentland.sandbox._http.httpx.request")
+    def test_archive_returns_content_or_saves_file(self, mock_open: mock.Mock) -> None:
+        mock_open.return_value = _FakeResponse(
+            status_code=200,
+            body=b"\x1f\x8barchive",
+            headers={
+                "Content-Disposition": 'attachment; filename="project.tar.gz"',
+                "X-Agentland-File-Path": "/workspace/project",
+            },
+        )
+
+        sandbox = Sandbox.connect("session-1")
+        out = sandbox.fs.archive("/workspace/project", format="tar.gz")
+        self.assertEqual(b"\x1f\x8barchive", out["content"])
+        self.assertEqual("project.tar.gz", out["file_name"])
+        self.assertEqual("targz", out["format"])
+        self.assertIn("format=targz", mock_open.call_args.args[1])
+
+        with tempfile.TemporaryDirectory() as td:
+            save_path = os.path.join(td, "project.tar.gz")
+            out = sandbox.fs.archive("/workspace/project", save_path, format="targz")
+            self.assertEqual(b"\x1f\x8barchive", Path(save_path).read_bytes())
+        self.assertNotIn("content", out)
+        self.assertEqual(save_path, out["save_path"])
+
+        with self.assertRaises(SDKError):
+            sandbox.fs.archive("/workspace/project", format="rar")
+
     @mock.patch("agentland.sandbox._http.httpx.request")
     def test_http_error_raises_sdk_error(self, mock_open: mock.Mock) -> None:
         mock_open.return_value = _FakeResponse(
@@ -211,6 +370,20 @@ class SandboxSDKTest(unittest.TestCase):
         self.assertEqual(400, ctx.exception.http_status)
         self.assertEqual(1, ctx.exception.code)
 
+    @mock.patch("agentland.sandbox._http.httpx.request")
+    def test_fs_error_exposes_string_code(self, mock_open: mock.Mock) -> None:
+        mock_open.return_value = _FakeResponse(
+            status_code=404,
+            body=json.dumps({"code": "NOT_FOUND", "msg": "path not found"}).encode("utf-8"),
+        )
+
+        sandbox = Sandbox.connect("session-1")
+        with self.assertRaises(SDKError) as ctx:
+            sandbox.fs.stat("missing.txt")
+        self.assertEqual(404, ctx.exception.http_status)
+        self.assertEqual("NOT_FOUND", ctx.exception.code)
+        self.assertEqual("path not found", str(ctx.exception).split(",")[0])
+
 
 if __name__ == "__main__":
     unittest.main()